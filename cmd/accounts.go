@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var accountsCmd = &cobra.Command{
+	Use:   "accounts",
+	Short: "List the accounts the session can access",
+	Long: `List the accounts the session can access: your own and any shared or
+delegated to you. Each is shown with whether it is personal or read-only and
+the capabilities it is the primary account for; the active account is marked.
+
+Select an account for any command with --account <name|email|id>.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your credential command or the token it returns")
+		}
+
+		return formatter().Format(os.Stdout, c.Accounts())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(accountsCmd)
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+// argsWithoutAccountID drops the --account-id that commandArgsForServer adds.
+func argsWithoutAccountID(args []string) []string {
+	out := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--account-id" {
+			i++
+			continue
+		}
+		out = append(out, args[i])
+	}
+	return out
+}
+
+func TestAccounts_ListsSessionAccounts(t *testing.T) {
+	server := newJMAPMockServer(t, nil, nil, nil)
+
+	args := argsWithoutAccountID(commandArgsForServer(t, server.server.URL, "accounts"))
+	stdout, stderr, err := runCLICommand(t, args)
+	if err != nil {
+		t.Fatalf("expected success, got: %v\nstderr=%s", err, stderr)
+	}
+	for _, want := range []string{`"id": "A1"`, `"name": "test@example.com"`, `"active": true`, `"urn:ietf:params:jmap:mail"`} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("expected %s in output, got: %s", want, stdout)
+		}
+	}
+}
+
+func TestAccountFlag_SelectsByName(t *testing.T) {
+	server := newJMAPMockServer(t, nil, nil, nil)
+
+	args := argsWithoutAccountID(commandArgsForServer(t, server.server.URL, "--account", "TEST@example.com", "accounts"))
+	stdout, stderr, err := runCLICommand(t, args)
+	if err != nil {
+		t.Fatalf("expected success, got: %v\nstderr=%s", err, stderr)
+	}
+	if !strings.Contains(stdout, `"active": true`) {
+		t.Errorf("expected the named account to be active, got: %s", stdout)
+	}
+
+	args = argsWithoutAccountID(commandArgsForServer(t, server.server.URL, "--account", "other@example.com", "accounts"))
+	_, stderr, err = runCLICommand(t, args)
+	if err == nil || !strings.Contains(stderr, `no account named \"other@example.com\"`) {
+		t.Errorf("expected an unknown account error, got err=%v stderr=%s", err, stderr)
+	}
+}
+
+func TestAccountFlag_ConflictsWithAccountID(t *testing.T) {
+	server := newJMAPMockServer(t, nil, nil, nil)
+
+	args := commandArgsForServer(t, server.server.URL, "--account", "test@example.com", "accounts")
+	_, stderr, err := runCLICommand(t, args)
+	if err == nil || !strings.Contains(stderr, "cannot be combined") {
+		t.Errorf("expected a conflict error, got err=%v stderr=%s", err, stderr)
+	}
+}
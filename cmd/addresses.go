@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var addressesCmd = &cobra.Command{
+	Use:   "addresses [email-id...]",
+	Short: "List the distinct addresses in a set of emails",
+	Long: `List the distinct addresses in the From, To, or Cc fields of a set of emails,
+one per line, for building allowlists and contact imports. Addresses are
+compared case-insensitively and sorted by how often they appear.
+
+  fm addresses --mailbox inbox --after 2026-01-01 --field from
+  fm addresses --from-last --names --counts`,
+	Args:              cobra.ArbitraryArgs,
+	ValidArgsFunction: completeEmailIDs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		field, _ := cmd.Flags().GetString("field")
+		switch field {
+		case "from", "to", "cc", "all":
+		default:
+			return exitError("general_error", "invalid --field "+field,
+				"Use from, to, cc, or all")
+		}
+
+		if err := validateIDsOrFilters(cmd, args); err != nil {
+			return err
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your credential command or the token it returns")
+		}
+
+		ids, err := resolveEmailIDs(cmd, args, c)
+		if err != nil {
+			return err
+		}
+
+		result, notFound, err := c.AggregateAddresses(ids, field)
+		if err != nil {
+			return exitError("jmap_error", err.Error(), "")
+		}
+		for _, id := range notFound {
+			warn("not_found", "email "+id+" not found", "")
+		}
+
+		result.Names, _ = cmd.Flags().GetBool("names")
+		result.Counts, _ = cmd.Flags().GetBool("counts")
+		return formatter().Format(os.Stdout, result)
+	},
+}
+
+func init() {
+	addressesCmd.Flags().String("field", "all", "Address field to read: from, to, cc, or all")
+	addressesCmd.Flags().Bool("names", false, "Include display names in text output")
+	addressesCmd.Flags().Bool("counts", false, "Include counts in text output")
+	addFilterFlags(addressesCmd)
+	addFromLastFlag(addressesCmd)
+	rootCmd.AddCommand(addressesCmd)
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+func addressesMockServer(t *testing.T) *jmapMockServer {
+	t.Helper()
+	return newJMAPMockServer(t, nil,
+		[]map[string]any{
+			{
+				"id":   "M1",
+				"from": []map[string]any{{"name": "Alice", "email": "Alice@Example.com"}},
+				"to":   []map[string]any{{"email": "bob@example.com"}},
+			},
+			{
+				"id":   "M2",
+				"from": []map[string]any{{"email": "alice@example.com"}},
+				"cc":   []map[string]any{{"name": "Carol", "email": "carol@example.com"}},
+			},
+		},
+		nil,
+	)
+}
+
+func TestAddresses_FromField(t *testing.T) {
+	server := addressesMockServer(t)
+
+	args := commandArgsForServer(t, server.server.URL, "addresses", "M1", "M2", "--field", "from")
+	stdout, stderr, err := runCLICommand(t, args)
+	if err != nil {
+		t.Fatalf("addresses failed: %v\n%s", err, stderr)
+	}
+
+	var result types.AddressesResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, stdout)
+	}
+	if result.Field != "from" || result.Emails != 2 || len(result.Addresses) != 1 {
+		t.Fatalf("expected one sender in two emails, got %+v", result)
+	}
+	if a := result.Addresses[0]; a.Email != "alice@example.com" || a.Name != "Alice" || a.Count != 2 {
+		t.Errorf("unexpected address: %+v", a)
+	}
+}
+
+func TestAddresses_TextNamesAndCounts(t *testing.T) {
+	server := addressesMockServer(t)
+
+	args := commandArgsForServer(t, server.server.URL, "addresses", "M1", "M2", "--format", "text")
+	stdout, stderr, err := runCLICommand(t, args)
+	if err != nil {
+		t.Fatalf("addresses failed: %v\n%s", err, stderr)
+	}
+	if want := "alice@example.com\nbob@example.com\ncarol@example.com\n"; stdout != want {
+		t.Errorf("bare output:\ngot  %q\nwant %q", stdout, want)
+	}
+
+	args = commandArgsForServer(t, server.server.URL, "addresses", "M1", "M2", "--format", "text", "--names", "--counts")
+	stdout, stderr, err = runCLICommand(t, args)
+	if err != nil {
+		t.Fatalf("addresses failed: %v\n%s", err, stderr)
+	}
+	if !strings.Contains(stdout, "2  Alice <alice@example.com>") || !strings.Contains(stdout, "1  Carol <carol@example.com>") {
+		t.Errorf("expected names and counts, got:\n%s", stdout)
+	}
+}
+
+func TestAddresses_InvalidField(t *testing.T) {
+	_, stderr, err := runCLICommand(t, []string{"addresses", "M1", "--field", "bcc"})
+	if !errors.Is(err, ErrSilent) || !strings.Contains(stderr, "invalid --field bcc") {
+		t.Fatalf("expected an invalid field error, got: %v\n%s", err, stderr)
+	}
+}
+
+func TestAddresses_NotFoundIsAWarning(t *testing.T) {
+	server := newJMAPMockServer(t, nil,
+		[]map[string]any{{"id": "M1", "from": []map[string]any{{"email": "alice@example.com"}}}},
+		[]string{"M9"},
+	)
+
+	args := commandArgsForServer(t, server.server.URL, "--errors-to", "stdout", "addresses", "M1", "M9")
+	stdout, stderr, err := runCLICommand(t, args)
+	if err != nil {
+		t.Fatalf("addresses failed: %v\n%s", err, stderr)
+	}
+	env := decodeEnvelope(t, stdout)
+	if !env.OK || env.Error != nil {
+		t.Errorf("expected the run to succeed, got %s", stdout)
+	}
+	if len(env.Warnings) != 1 || env.Warnings[0].Error != "not_found" || !strings.Contains(env.Warnings[0].Message, "M9") {
+		t.Errorf("expected a not_found warning for M9, got %s", stdout)
+	}
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var agingCmd = &cobra.Command{
+	Use:   "aging",
+	Short: "Count the emails in a mailbox by age",
+	Long: `Count the emails in a mailbox by how long ago they arrived: today, this
+week (the past 7 days), this month (the past 30 days), and older, with the
+unread emails in each. Days start at local midnight. Only counts are
+fetched, so it is cheap enough to run daily and track an inbox-zero
+practice over time.
+
+  fm aging
+  fm aging --mailbox Archive --format text`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mailboxName, _ := cmd.Flags().GetString("mailbox")
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your credential command or the token it returns")
+		}
+
+		mailboxID, err := c.ResolveMailboxID(mailboxName)
+		if err != nil {
+			return exitError("not_found", err.Error(), mailboxHint(err))
+		}
+
+		result, err := c.AgingReport(mailboxID, time.Now())
+		if err != nil {
+			return exitError("jmap_error", err.Error(), "")
+		}
+		result.Mailbox = mailboxName
+
+		return formatter().Format(os.Stdout, result)
+	},
+}
+
+func init() {
+	agingCmd.Flags().StringP("mailbox", "m", "inbox", "mailbox to report on")
+	rootCmd.AddCommand(agingCmd)
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+func TestAging_CountsEachBucket(t *testing.T) {
+	server := newJMAPMockServer(t,
+		[]map[string]any{{"id": "mb-inbox", "name": "Inbox", "role": "inbox"}},
+		[]map[string]any{{"id": "M1"}, {"id": "M2"}},
+		nil,
+	)
+
+	stdout, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "aging"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n%s", err, stderr)
+	}
+
+	var result types.AgingResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, stdout)
+	}
+	if result.Mailbox != "inbox" {
+		t.Errorf("expected mailbox inbox, got %q", result.Mailbox)
+	}
+	// The mock matches every email in every query.
+	if len(result.Buckets) != 4 || result.Total != 8 || result.Unread != 8 {
+		t.Errorf("expected 4 buckets of 2 emails, got %+v", result)
+	}
+	if got := server.count("Email/query"); got != 8 {
+		t.Errorf("expected 8 queries, got %d", got)
+	}
+}
+
+func TestAging_UnknownMailbox(t *testing.T) {
+	server := newJMAPMockServer(t,
+		[]map[string]any{{"id": "mb-inbox", "name": "Inbox", "role": "inbox"}},
+		nil, nil,
+	)
+
+	_, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "aging", "--mailbox", "Nowhere"))
+	if !errors.Is(err, ErrSilent) || !strings.Contains(stderr, "not_found") {
+		t.Fatalf("expected not_found, got: %v\n%s", err, stderr)
+	}
+}
@@ -6,13 +6,24 @@ import (
 	"git.sr.ht/~rockorager/go-jmap/mail/mailbox"
 	"github.com/spf13/cobra"
 
+	"github.com/cboone/fm/internal/client"
 	"github.com/cboone/fm/internal/types"
 )
 
 var archiveCmd = &cobra.Command{
 	Use:   "archive [email-id...]",
 	Short: "Move emails to the Archive mailbox",
-	Args:  cobra.ArbitraryArgs,
+	Long: `Move emails, given by ID or selected with filter flags, to the Archive
+mailbox. Large selections are moved in chunks, with progress shown on stderr
+when it is a terminal.
+
+Each archive is recorded in the undo journal; fm undo puts the emails back
+where they were. To archive read inbox mail older than 90 days:
+
+  fm archive --mailbox inbox --read --older-than 90d --dry-run
+  fm archive --mailbox inbox --read --older-than 90d`,
+	Args:              cobra.ArbitraryArgs,
+	ValidArgsFunction: completeEmailIDs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if err := validateIDsOrFilters(cmd, args); err != nil {
 			return err
@@ -42,14 +53,27 @@ var archiveCmd = &cobra.Command{
 			})
 		}
 
-		succeeded, errors := c.MoveEmails(ids, archiveMB.ID)
+		states, err := c.GetEmailStates(ids)
+		if err != nil {
+			return exitError("jmap_error", err.Error(), "")
+		}
+		pending, noOps := client.SplitNoOps(ids, states, func(s client.EmailState) bool {
+			return s.OnlyIn(archiveMB.ID)
+		})
+
+		c.SetProgress(progressPrinter("Archiving"))
+		outcomes := recordOutcomes(cmd, c)
+		succeeded, errors := c.MoveEmails(pending, archiveMB.ID)
+		recordUndo(c, "archive", client.MailboxIDsOf(states), succeeded)
 
 		result := types.MoveResult{
 			Matched:   len(ids),
 			Processed: len(succeeded) + len(errors),
 			Failed:    len(errors),
+			NoOp:      len(noOps),
 			Archived:  succeeded,
 			Errors:    errors,
+			Results:   append(outcomes.results(succeeded, errors), outcomes.noOps(noOps)...),
 			Destination: &types.DestinationInfo{
 				ID:   string(archiveMB.ID),
 				Name: archiveMB.Name,
@@ -61,7 +85,7 @@ var archiveCmd = &cobra.Command{
 		}
 
 		if len(errors) > 0 {
-			return exitError("partial_failure", "one or more emails failed to archive", "")
+			return exitError("partial_failure", "one or more emails failed to archive", retryHint(cmd, errors))
 		}
 
 		return nil
@@ -70,6 +94,9 @@ var archiveCmd = &cobra.Command{
 
 func init() {
 	archiveCmd.Flags().BoolP("dry-run", "n", false, "preview affected emails without making changes")
+	addVerboseFlag(archiveCmd)
+	addIDsFileFlag(archiveCmd)
 	addFilterFlags(archiveCmd)
+	addFromLastFlag(archiveCmd)
 	rootCmd.AddCommand(archiveCmd)
 }
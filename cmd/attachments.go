@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/client"
+	"github.com/cboone/fm/internal/types"
+)
+
+// maxGrepAttachmentSize is the largest attachment attachments --grep
+// downloads; larger ones are skipped.
+const maxGrepAttachmentSize = 10 << 20
+
+// maxGrepLineRunes is where matching lines are cut short in the output.
+const maxGrepLineRunes = 300
+
+// grepTypes and grepExtensions are the attachments attachments --grep
+// searches: by MIME type, or by file name for those sent with a generic
+// type such as application/octet-stream.
+var (
+	grepTypes      = []string{"text/plain", "text/csv", "text/calendar", "application/json", "application/xml", "text/xml"}
+	grepExtensions = []string{".txt", ".csv", ".ics", ".json", ".xml"}
+)
+
+var attachmentsCmd = &cobra.Command{
+	Use:   "attachments [email-id...] --grep <regexp>",
+	Short: "Search the text attachments of emails",
+	Long: `Search the contents of text attachments (txt, csv, ics, json, and xml) of
+the emails given by ID or matched by filter flags, and print each matching
+line with its email and attachment. Other attachments, such as PDFs, are
+searched when the config file names a command that converts them to text,
+for example:
+
+  extractors:
+    pdf: pdftotext - -
+
+Attachments of other types, inline images, and attachments over 10 MB are
+skipped.
+
+The pattern is a Go regular expression (RE2 syntax), matched against each
+line of the attachment. With filter flags, only emails with attachments
+are considered, up to --limit of them.`,
+	Example: `  fm attachments --grep 'PO-7741' --mailbox receipts
+  fm attachments --grep 'invoice' -i --from billing@example.com --after 2026-01-01
+  fm attachments M1 M2 --grep 'DTSTART'`,
+	Args:              cobra.ArbitraryArgs,
+	ValidArgsFunction: completeEmailIDs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pattern, _ := cmd.Flags().GetString("grep")
+		if pattern == "" {
+			return exitError("general_error", "required flag \"grep\" not set",
+				"Give the text to look for with --grep <regexp>")
+		}
+		if ignoreCase, _ := cmd.Flags().GetBool("ignore-case"); ignoreCase {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return exitError("general_error", "invalid --grep pattern: "+err.Error(),
+				"Use RE2 syntax, for example --grep 'PO-\\d+'")
+		}
+		limit, _ := cmd.Flags().GetInt("limit")
+		if limit < 1 {
+			return exitError("general_error", "--limit must be at least 1", "")
+		}
+		if err := validateIDsOrFilters(cmd, args); err != nil {
+			return err
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your credential command or the token it returns")
+		}
+
+		if hasFilterFlags(cmd) && !cmd.Flags().Changed("has-attachment") {
+			_ = cmd.Flags().Set("has-attachment", "true")
+		}
+		ids, err := resolveEmailIDs(cmd, args, c)
+		if err != nil {
+			return err
+		}
+		if len(ids) > limit {
+			ids = ids[:limit]
+		}
+
+		emails, err := c.GetAttachments(ids)
+		if err != nil {
+			return exitError("jmap_error", err.Error(), "")
+		}
+
+		result := types.AttachmentGrepResult{
+			Pattern: re.String(),
+			Emails:  len(emails),
+			Matches: []types.AttachmentMatch{},
+		}
+		for _, e := range emails {
+			for _, a := range e.Attachments {
+				if a.Inline {
+					continue
+				}
+				if !grepable(a) {
+					result.Skipped++
+					continue
+				}
+				result.Searched++
+				matches, err := grepAttachment(c, a, re)
+				if err != nil {
+					_ = formatter().Format(os.Stdout, result)
+					var extractErr *extractError
+					if errors.As(err, &extractErr) {
+						return exitError("general_error", err.Error(), "Check the command under extractors in the config file")
+					}
+					return exitError("jmap_error", err.Error(), "")
+				}
+				for _, m := range matches {
+					m.EmailID = e.ID
+					m.Subject = e.Subject
+					m.From = e.From
+					result.Matches = append(result.Matches, m)
+				}
+			}
+		}
+
+		return formatter().Format(os.Stdout, result)
+	},
+}
+
+func init() {
+	attachmentsCmd.Flags().String("grep", "", "regular expression to search attachment contents for (required)")
+	attachmentsCmd.Flags().BoolP("ignore-case", "i", false, "match the pattern case-insensitively")
+	attachmentsCmd.Flags().Int("limit", 100, "maximum number of emails to search")
+	addFilterFlags(attachmentsCmd)
+	addFromLastFlag(attachmentsCmd)
+	rootCmd.AddCommand(attachmentsCmd)
+}
+
+// grepable reports whether attachments --grep searches a: whether it is
+// small enough, and text or convertible to text by an extractor.
+func grepable(a client.Attachment) bool {
+	if a.Size > maxGrepAttachmentSize {
+		return false
+	}
+	return isTextAttachment(a) || extractorFor(a) != ""
+}
+
+// isTextAttachment reports whether a is searched as it is, by its type or
+// its file extension.
+func isTextAttachment(a client.Attachment) bool {
+	mediaType, _, _ := strings.Cut(a.Type, ";")
+	return slices.Contains(grepTypes, strings.ToLower(strings.TrimSpace(mediaType))) ||
+		slices.Contains(grepExtensions, strings.ToLower(filepath.Ext(a.Name)))
+}
+
+// grepAttachment downloads a, converting it to text with its extractor if
+// it is not text already, and returns its lines that match re.
+func grepAttachment(c *client.Client, a client.Attachment, re *regexp.Regexp) ([]types.AttachmentMatch, error) {
+	body, err := c.DownloadAttachment(a)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	name := a.Name
+	if name == "" {
+		name = "part " + a.PartID
+	}
+
+	var text io.Reader = body
+	if !isTextAttachment(a) {
+		out, err := extractText(extractorFor(a), a, body)
+		if err != nil {
+			return nil, err
+		}
+		text = bytes.NewReader(out)
+	}
+
+	var matches []types.AttachmentMatch
+	scanner := bufio.NewScanner(text)
+	scanner.Buffer(make([]byte, 64*1024), maxGrepAttachmentSize)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if !re.MatchString(line) {
+			continue
+		}
+		matches = append(matches, types.AttachmentMatch{
+			Attachment: name,
+			Line:       n,
+			Text:       truncateLine(line, maxGrepLineRunes),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", name, err)
+	}
+	return matches, nil
+}
+
+// truncateLine cuts line to at most n runes, marking the cut with "...".
+func truncateLine(line string, n int) string {
+	if utf8.RuneCountInString(line) <= n {
+		return line
+	}
+	return string([]rune(line)[:n]) + "..."
+}
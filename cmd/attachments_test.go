@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cboone/fm/internal/client"
+	"github.com/cboone/fm/internal/types"
+)
+
+func TestAttachments_GrepFindsLines(t *testing.T) {
+	server := newJMAPMockServer(t, nil,
+		[]map[string]any{{
+			"id":      "M1",
+			"subject": "Invoice 1042",
+			"from":    []map[string]any{{"email": "billing@example.com"}},
+			"attachments": []map[string]any{
+				{"partId": "2", "blobId": "B-csv", "name": "invoice.csv", "type": "application/octet-stream", "size": 64},
+				{"partId": "3", "blobId": "B-pdf", "name": "invoice.pdf", "type": "application/pdf", "size": 2048},
+			},
+		}},
+		nil,
+	)
+	server.blobs = map[string]string{"B-csv": "item,po\r\nWidgets,PO-7741\r\nGadgets,PO-7742\r\n"}
+
+	args := commandArgsForServer(t, server.server.URL, "attachments", "M1", "--grep", "po-7741", "-i")
+	stdout, stderr, err := runCLICommand(t, args)
+	if err != nil {
+		t.Fatalf("attachments failed: %v\n%s", err, stderr)
+	}
+
+	var result types.AttachmentGrepResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, stdout)
+	}
+	if result.Emails != 1 || result.Searched != 1 || result.Skipped != 1 {
+		t.Errorf("expected one attachment searched and one skipped, got %+v", result)
+	}
+	if len(result.Matches) != 1 {
+		t.Fatalf("expected one match, got %+v", result.Matches)
+	}
+	m := result.Matches[0]
+	if m.EmailID != "M1" || m.Subject != "Invoice 1042" || m.Attachment != "invoice.csv" || m.Line != 2 || m.Text != "Widgets,PO-7741" {
+		t.Errorf("unexpected match: %+v", m)
+	}
+}
+
+func TestAttachments_RequiresGrep(t *testing.T) {
+	_, stderr, err := runCLICommand(t, []string{"attachments", "M1"})
+	if !errors.Is(err, ErrSilent) || !strings.Contains(stderr, "grep") {
+		t.Fatalf("expected a missing --grep error, got: %v\n%s", err, stderr)
+	}
+}
+
+func TestAttachments_InvalidPattern(t *testing.T) {
+	_, stderr, err := runCLICommand(t, []string{"attachments", "M1", "--grep", "("})
+	if !errors.Is(err, ErrSilent) || !strings.Contains(stderr, "invalid --grep pattern") {
+		t.Fatalf("expected an invalid pattern error, got: %v\n%s", err, stderr)
+	}
+}
+
+func TestGrepable(t *testing.T) {
+	tests := []struct {
+		att  client.Attachment
+		want bool
+	}{
+		{client.Attachment{Name: "notes.txt", Type: "text/plain; charset=utf-8"}, true},
+		{client.Attachment{Name: "invite.ics", Type: "application/octet-stream"}, true},
+		{client.Attachment{Name: "data", Type: "application/json"}, true},
+		{client.Attachment{Name: "scan.pdf", Type: "application/pdf"}, false},
+		{client.Attachment{Name: "huge.csv", Type: "text/csv", Size: maxGrepAttachmentSize + 1}, false},
+	}
+	for _, tt := range tests {
+		if got := grepable(tt.att); got != tt.want {
+			t.Errorf("grepable(%s, %s) = %v, want %v", tt.att.Name, tt.att.Type, got, tt.want)
+		}
+	}
+}
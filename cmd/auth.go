@@ -0,0 +1,14 @@
+package cmd
+
+import "github.com/spf13/cobra"
+
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Check the API token",
+	Long: `Check the API token fm gets from the credential command against the
+server.`,
+}
+
+func init() {
+	rootCmd.AddCommand(authCmd)
+}
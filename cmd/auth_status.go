@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var authStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Verify the API token against the session endpoint",
+	Long: `Run the credential command and fetch a fresh session with the token it
+returns, going to the server even when an fm daemon is running. Reports the
+account in use, the capabilities the session grants (which reflect the
+token's scopes), and, for tokens that carry them such as OAuth access tokens
+in JWT form, when the token was issued and when it expires.
+
+Exits non-zero when the credential command fails or the server rejects the
+token, so automations can check their credentials cheaply before running.`,
+	Example: `  fm auth status
+  fm auth status --format text`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		token, err := resolveToken()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your credential command or the token it returns")
+		}
+		issued, expires := tokenTimes(token)
+
+		c, err := newTokenClient(token)
+		if err != nil {
+			hint := "Check your credential command or the token it returns"
+			if expires != nil && expires.Before(time.Now()) {
+				hint = "The token expired at " + expires.Format(time.RFC3339) + "; get a new one"
+			}
+			return exitError("authentication_failed", err.Error(), hint)
+		}
+
+		status := c.AuthStatus()
+		status.TokenIssuedAt = issued
+		status.TokenExpiresAt = expires
+		if issued != nil {
+			age := int64(time.Since(*issued) / time.Second)
+			status.TokenAgeSeconds = &age
+		}
+		return formatter().Format(os.Stdout, status)
+	},
+}
+
+// tokenTimes returns the issue and expiry times of token when it is a JWT
+// carrying iat and exp claims, and nil for each it does not carry. The
+// signature is not checked; the server does that.
+func tokenTimes(token string) (issued, expires *time.Time) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, nil
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, nil
+	}
+	var claims struct {
+		IssuedAt  *float64 `json:"iat"`
+		ExpiresAt *float64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, nil
+	}
+	at := func(v *float64) *time.Time {
+		if v == nil {
+			return nil
+		}
+		t := time.Unix(int64(*v), 0).UTC()
+		return &t
+	}
+	return at(claims.IssuedAt), at(claims.ExpiresAt)
+}
+
+func init() {
+	authCmd.AddCommand(authStatusCmd)
+}
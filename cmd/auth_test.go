@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+func TestAuthStatus_ReportsSession(t *testing.T) {
+	server := newJMAPMockServer(t, nil, nil, nil)
+
+	stdout, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "auth", "status"))
+	if err != nil {
+		t.Fatalf("auth status failed: %v\n%s", err, stderr)
+	}
+	var status types.AuthStatus
+	if err := json.Unmarshal([]byte(stdout), &status); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, stdout)
+	}
+	if !status.Valid || status.Username != "test@example.com" || status.AccountID != "A1" || status.AccountName != "test@example.com" {
+		t.Errorf("unexpected status: %+v", status)
+	}
+	if strings.Join(status.Capabilities, " ") != "urn:ietf:params:jmap:core urn:ietf:params:jmap:mail" {
+		t.Errorf("expected the session capabilities, got %v", status.Capabilities)
+	}
+	if status.TokenIssuedAt != nil || status.TokenExpiresAt != nil || status.TokenAgeSeconds != nil {
+		t.Errorf("expected no token times for an opaque token, got %+v", status)
+	}
+}
+
+func TestAuthStatus_ReportsJWTTimes(t *testing.T) {
+	server := newJMAPMockServer(t, nil, nil, nil)
+	issued := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+	claims := fmt.Sprintf(`{"sub":"test","iat":%d,"exp":%d}`, issued.Unix(), issued.Add(24*time.Hour).Unix())
+	token := "eyJhbGciOiJIUzI1NiJ9." + base64.RawURLEncoding.EncodeToString([]byte(claims)) + ".c2ln"
+
+	args := commandArgsForServer(t, server.server.URL, "auth", "status", "--credential-command", "echo "+token)
+	stdout, stderr, err := runCLICommand(t, args)
+	if err != nil {
+		t.Fatalf("auth status failed: %v\n%s", err, stderr)
+	}
+	var status types.AuthStatus
+	if err := json.Unmarshal([]byte(stdout), &status); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, stdout)
+	}
+	if status.TokenIssuedAt == nil || !status.TokenIssuedAt.Equal(issued) {
+		t.Errorf("expected token_issued_at %s, got %v", issued, status.TokenIssuedAt)
+	}
+	if status.TokenExpiresAt == nil || !status.TokenExpiresAt.Equal(issued.Add(24*time.Hour)) {
+		t.Errorf("expected token_expires_at a day later, got %v", status.TokenExpiresAt)
+	}
+	if status.TokenAgeSeconds == nil || *status.TokenAgeSeconds < 7200 || *status.TokenAgeSeconds > 7260 {
+		t.Errorf("expected a token age of about two hours, got %v", status.TokenAgeSeconds)
+	}
+}
+
+func TestAuthStatus_FailsForInvalidCredentials(t *testing.T) {
+	server := newJMAPMockServer(t, nil, nil, nil)
+
+	for name, extra := range map[string][]string{
+		"credential command fails": {"--credential-command", "false"},
+		"session rejected":         {"--session-url", server.server.URL + "/nowhere"},
+	} {
+		args := commandArgsForServer(t, server.server.URL, append([]string{"auth", "status"}, extra...)...)
+		_, stderr, err := runCLICommand(t, args)
+		if !errors.Is(err, ErrSilent) || !strings.Contains(stderr, "authentication_failed") {
+			t.Errorf("%s: expected authentication_failed, got: %v\n%s", name, err, stderr)
+		}
+	}
+}
+
+func TestTokenTimes_IgnoresOpaqueTokens(t *testing.T) {
+	for _, token := range []string{"fmu1-abc123", "a.b.c", "a.!!!.c"} {
+		if issued, expires := tokenTimes(token); issued != nil || expires != nil {
+			t.Errorf("tokenTimes(%q) = %v, %v; want nil, nil", token, issued, expires)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/authcheck"
+	"github.com/cboone/fm/internal/types"
+)
+
+var authcheckCmd = &cobra.Command{
+	Use:   "authcheck [email-id...]",
+	Short: "Report SPF, DKIM, and DMARC verdicts for emails",
+	Long: `Parse the Authentication-Results, Received-SPF, and DKIM-Signature
+headers of each email and report its SPF, DKIM, and DMARC verdicts,
+flagging failures.
+
+Only the topmost Authentication-Results header is trusted, since it is the
+one added by the receiving server.
+
+Accepts email IDs as arguments, or use filter flags to select emails
+(e.g. --from sender@example.com).`,
+	Args:              cobra.ArbitraryArgs,
+	ValidArgsFunction: completeEmailIDs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateIDsOrFilters(cmd, args); err != nil {
+			return err
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your credential command or the token it returns")
+		}
+
+		ids, err := resolveEmailIDs(cmd, args, c)
+		if err != nil {
+			return err
+		}
+
+		emails, notFound, err := c.GetEmailHeaders(ids)
+		if err != nil {
+			return exitError("jmap_error", err.Error(), "")
+		}
+		if len(emails) == 0 {
+			return exitError("not_found", "no emails found", "")
+		}
+
+		result := types.AuthCheckResult{
+			Results:  make([]types.AuthVerdict, 0, len(emails)),
+			NotFound: notFound,
+		}
+		for _, e := range emails {
+			headers := make([]authcheck.Header, len(e.Headers))
+			for i, h := range e.Headers {
+				headers[i] = authcheck.Header{Name: h.Name, Value: h.Value}
+			}
+			v := authcheck.Parse(headers)
+
+			verdict := types.AuthVerdict{
+				EmailID:     e.Summary.ID,
+				From:        e.Summary.From,
+				Subject:     e.Summary.Subject,
+				ReceivedAt:  e.Summary.ReceivedAt,
+				AuthServID:  v.AuthServID,
+				SPF:         v.SPF,
+				DKIM:        v.DKIM,
+				DMARC:       v.DMARC,
+				DKIMDomains: v.DKIMDomains,
+				Failures:    v.Failures(),
+			}
+			if len(verdict.Failures) > 0 {
+				result.Failed++
+			}
+			result.Results = append(result.Results, verdict)
+		}
+		result.Checked = len(result.Results)
+
+		return formatter().Format(os.Stdout, result)
+	},
+}
+
+func init() {
+	addFilterFlags(authcheckCmd)
+	addFromLastFlag(authcheckCmd)
+	rootCmd.AddCommand(authcheckCmd)
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAuthcheck_FlagsFailures(t *testing.T) {
+	server := newJMAPMockServer(t,
+		nil,
+		[]map[string]any{
+			{
+				"id": "M1", "threadId": "T1", "subject": "Verify your account", "receivedAt": "2026-02-14T10:30:00Z",
+				"from": []map[string]any{{"email": "security@example.com"}},
+				"headers": []map[string]any{
+					{"name": "Authentication-Results", "value": " mx.example.net; spf=softfail smtp.mailfrom=example.com; dkim=none; dmarc=fail header.from=example.com"},
+				},
+			},
+		},
+		nil,
+	)
+
+	args := commandArgsForServer(t, server.server.URL, "authcheck", "M1")
+	stdout, stderr, err := runCLICommand(t, args)
+	if err != nil {
+		t.Fatalf("expected success, got: %v\nstderr=%s", err, stderr)
+	}
+	if !strings.Contains(stdout, `"failed": 1`) {
+		t.Fatalf("expected one failed email, got: %s", stdout)
+	}
+	if !strings.Contains(stdout, `"dmarc=fail"`) || !strings.Contains(stdout, `"spf=softfail"`) {
+		t.Fatalf("expected failures to be listed, got: %s", stdout)
+	}
+}
+
+func TestAuthcheck_RequiresIDsOrFilters(t *testing.T) {
+	_, stderr, err := runCLICommand(t, []string{"authcheck"})
+	if err == nil {
+		t.Fatal("expected error without IDs or filters")
+	}
+	if !strings.Contains(stderr, "no emails specified") {
+		t.Fatalf("expected missing selection error, got: %s", stderr)
+	}
+}
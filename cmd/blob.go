@@ -0,0 +1,16 @@
+package cmd
+
+import "github.com/spf13/cobra"
+
+var blobCmd = &cobra.Command{
+	Use:   "blob",
+	Short: "Fetch raw blobs by ID",
+	Long: `Low-level access to the blobs behind emails and their parts, fetched from the
+session's download URL. Blob IDs appear as blob_id in the attachments of
+fm read and the files of fm download, so scripts can fetch a part again
+without looking up its message.`,
+}
+
+func init() {
+	rootCmd.AddCommand(blobCmd)
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var blobGetCmd = &cobra.Command{
+	Use:   "get <blob-id>",
+	Short: "Write a blob's content to stdout",
+	Long: `Write the content of a blob, as stored, to stdout. With --output, the
+content is written to a file instead, replaced atomically.`,
+	Example: `  fm blob get G6b0a53c4 --output agenda.pdf
+  fm blob get G6b0a53c4 | file -`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your credential command or the token it returns")
+		}
+
+		body, err := c.DownloadBlob(args[0])
+		if err != nil {
+			return exitError(readErrorCode(err), err.Error(), "")
+		}
+		defer func() { _ = body.Close() }()
+
+		if _, err := io.Copy(os.Stdout, body); err != nil {
+			return exitError("general_error", fmt.Sprintf("cannot write blob %s: %v", args[0], err), "")
+		}
+		return nil
+	},
+}
+
+func init() {
+	blobCmd.AddCommand(blobGetCmd)
+}
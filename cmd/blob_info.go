@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var blobInfoCmd = &cobra.Command{
+	Use:   "info <blob-id>",
+	Short: "Show a blob's size, type, and SHA-256 digest",
+	Long: `Download a blob and report its size, its SHA-256 digest, and its media
+type. The download URL does not say what type a blob was stored with, so
+the type is sniffed from the first bytes of the content.`,
+	Example: `  fm blob info G6b0a53c4`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your credential command or the token it returns")
+		}
+
+		info, err := c.BlobInfo(args[0])
+		if err != nil {
+			return exitError(readErrorCode(err), err.Error(), "")
+		}
+		return formatter().Format(os.Stdout, info)
+	},
+}
+
+func init() {
+	blobCmd.AddCommand(blobInfoCmd)
+}
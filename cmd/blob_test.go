@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+func TestBlobGet_WritesContent(t *testing.T) {
+	server := newJMAPMockServer(t, nil, nil, nil)
+	server.blobs = map[string]string{"B1": "%PDF-1.7 agenda"}
+
+	stdout, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "blob", "get", "B1"))
+	if err != nil {
+		t.Fatalf("blob get failed: %v\n%s", err, stderr)
+	}
+	if stdout != "%PDF-1.7 agenda" {
+		t.Errorf("expected the blob content on stdout, got %q", stdout)
+	}
+
+	path := filepath.Join(t.TempDir(), "agenda.pdf")
+	stdout, stderr, err = runCLICommand(t, commandArgsForServer(t, server.server.URL, "--output", path, "blob", "get", "B1"))
+	if err != nil {
+		t.Fatalf("blob get --output failed: %v\n%s", err, stderr)
+	}
+	if stdout != "" {
+		t.Errorf("expected nothing on stdout with --output, got %q", stdout)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "%PDF-1.7 agenda" {
+		t.Errorf("expected the blob content in the output file, got %q (err %v)", data, err)
+	}
+}
+
+func TestBlobInfo_DescribesContent(t *testing.T) {
+	server := newJMAPMockServer(t, nil, nil, nil)
+	content := "%PDF-1.7 agenda"
+	server.blobs = map[string]string{"B1": content}
+
+	stdout, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "blob", "info", "B1"))
+	if err != nil {
+		t.Fatalf("blob info failed: %v\n%s", err, stderr)
+	}
+	var info types.BlobInfo
+	if err := json.Unmarshal([]byte(stdout), &info); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, stdout)
+	}
+	sum := sha256.Sum256([]byte(content))
+	want := types.BlobInfo{BlobID: "B1", Size: int64(len(content)), Type: "application/pdf", SHA256: hex.EncodeToString(sum[:])}
+	if info != want {
+		t.Errorf("got %+v, want %+v", info, want)
+	}
+}
+
+func TestBlob_UnknownBlob(t *testing.T) {
+	server := newJMAPMockServer(t, nil, nil, nil)
+
+	for _, sub := range []string{"get", "info"} {
+		_, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "blob", sub, "missing"))
+		if !errors.Is(err, ErrSilent) || !strings.Contains(stderr, "not_found") {
+			t.Errorf("blob %s: expected not_found, got: %v\n%s", sub, err, stderr)
+		}
+	}
+}
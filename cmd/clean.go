@@ -0,0 +1,16 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var cleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Find mail to unsubscribe from or filter away",
+	Long: `Find senders worth cleaning up. clean only reports and suggests commands;
+it never changes or deletes anything.`,
+}
+
+func init() {
+	rootCmd.AddCommand(cleanCmd)
+}
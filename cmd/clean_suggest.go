@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/client"
+	"github.com/cboone/fm/internal/types"
+)
+
+var cleanSuggestCmd = &cobra.Command{
+	Use:   "suggest",
+	Short: "Suggest unsubscribing from mailing lists you never read",
+	Long: `Find senders of mailing-list email (messages with a List-Unsubscribe
+header) whose messages from the last --months months have all gone unread,
+and suggest commands to deal with them: unsubscribe using the latest
+message, archive what is already in the mailbox, or create a sieve filter
+that archives future messages.
+
+Nothing is changed. Run a suggested command with --dry-run first to see
+what it would do.
+
+  fm clean suggest
+  fm clean suggest --months 6 --min-count 5`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mailboxName, _ := cmd.Flags().GetString("mailbox")
+		months, _ := cmd.Flags().GetInt("months")
+		minCount, _ := cmd.Flags().GetInt("min-count")
+		if months < 1 {
+			return exitError("general_error", "--months must be at least 1", "")
+		}
+		if minCount < 1 {
+			return exitError("general_error", "--min-count must be at least 1", "")
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your credential command or the token it returns")
+		}
+
+		mailboxID, err := c.ResolveMailboxID(mailboxName)
+		if err != nil {
+			return exitError("not_found", err.Error(), mailboxHint(err))
+		}
+
+		result, err := c.StaleListSenders(client.CleanOptions{
+			MailboxID: string(mailboxID),
+			Since:     time.Now().UTC().AddDate(0, -months, 0).Truncate(time.Second),
+			MinCount:  minCount,
+		})
+		if err != nil {
+			return exitError("jmap_error", err.Error(), "")
+		}
+		result.Mailbox = mailboxName
+		for i := range result.Suggestions {
+			addCleanCommands(&result.Suggestions[i], mailboxName)
+		}
+
+		return formatter().Format(os.Stdout, result)
+	},
+}
+
+func init() {
+	cleanSuggestCmd.Flags().StringP("mailbox", "m", "inbox", "mailbox name or ID")
+	cleanSuggestCmd.Flags().Int("months", 3, "how many months back to look for unread messages")
+	cleanSuggestCmd.Flags().Int("min-count", 2, "minimum unread messages from a sender to suggest it")
+	cleanCmd.AddCommand(cleanSuggestCmd)
+}
+
+// addCleanCommands fills in the suggested commands for s.
+func addCleanCommands(s *types.CleanSuggestion, mailbox string) {
+	addr := shellQuote(s.Email)
+	s.Unsubscribe = "fm unsubscribe " + shellQuote(s.LatestID)
+	s.Archive = "fm archive --mailbox " + shellQuote(mailbox) + " --from " + addr
+	s.ArchiveFilter = "fm sieve create --name " + shellQuote("Archive "+s.Email) +
+		" --from " + addr + " --action fileinto --fileinto Archive"
+}
+
+// shellQuote quotes s for a POSIX shell when it contains anything beyond
+// characters that are always safe.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789@%+=:,./_-") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/cache"
+	"github.com/cboone/fm/internal/types"
+)
+
+// completeEmailIDs offers the email IDs from the most recent list or search
+// for shell completion, described by subject and sender. Arguments starting
+// with % complete to short handles instead. Nothing is offered when there is
+// no cached result.
+func completeEmailIDs(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	path, err := lastResultPath()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	last, err := cache.LoadLast(path)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []cobra.Completion
+	for i, e := range last.Result.Emails {
+		choice := e.ID
+		if strings.HasPrefix(toComplete, "%") {
+			choice = fmt.Sprintf("%%%d", i+1)
+		}
+		if strings.HasPrefix(choice, toComplete) {
+			completions = append(completions, cobra.CompletionWithDesc(choice, completionHint(e)))
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeEmailID is completeEmailIDs for commands taking a single email.
+func completeEmailID(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeEmailIDs(cmd, args, toComplete)
+}
+
+// completeKeywordEmailIDs completes the email IDs after the keyword argument
+// of fm keyword set and clear.
+func completeKeywordEmailIDs(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeEmailIDs(cmd, args, toComplete)
+}
+
+// completionHint describes an email on one line as its subject and sender.
+func completionHint(e types.EmailSummary) string {
+	hint := e.Subject
+	if hint == "" {
+		hint = "(no subject)"
+	}
+	if len(e.From) > 0 {
+		from := e.From[0].Name
+		if from == "" {
+			from = e.From[0].Email
+		}
+		hint += " (" + from + ")"
+	}
+	// Shells split completions on tabs and newlines.
+	return strings.Join(strings.Fields(hint), " ")
+}
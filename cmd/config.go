@@ -0,0 +1,13 @@
+package cmd
+
+import "github.com/spf13/cobra"
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect fm's configuration",
+	Long:  `Inspect where fm reads its configuration from and keeps its caches.`,
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+}
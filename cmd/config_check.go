@@ -0,0 +1,253 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/cboone/fm/internal/client"
+	"github.com/cboone/fm/internal/textdiff"
+	"github.com/cboone/fm/internal/types"
+)
+
+var configCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Validate the config file and show where settings come from",
+	Long: `Validate the config file against the settings fm knows about. Unknown
+keys (with a suggestion for likely typos) and invalid values are reported as
+problems. Each effective setting is listed with the source it came from:
+flag, env, config, or default.
+
+Exits with config_error when the config file has problems.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		result, err := checkConfig(cmd)
+		if err != nil {
+			return exitError("config_error", err.Error(), configErrorHint())
+		}
+
+		if err := formatter().Format(os.Stdout, result); err != nil {
+			return err
+		}
+
+		if !result.Valid {
+			return exitError("config_error",
+				fmt.Sprintf("config file has %d problem(s)", len(result.Problems)),
+				"Fix or remove the reported keys in "+result.ConfigFile)
+		}
+		return nil
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configCheckCmd)
+}
+
+// configFlags maps config keys to the root flags bound to them.
+var configFlags = map[string]string{
+	"credential_command": "credential-command",
+	"session_url":        "session-url",
+	"format":             "format",
+	"account_id":         "account-id",
+	"account":            "account",
+	"ascii":              "ascii",
+	"hyperlinks":         "hyperlinks",
+	"redact":             "redact",
+}
+
+func checkConfig(cmd *cobra.Command) (types.ConfigCheckResult, error) {
+	result := types.ConfigCheckResult{
+		ConfigFile: viper.ConfigFileUsed(),
+		Problems:   []types.ConfigProblem{},
+	}
+
+	file := viper.New()
+	if result.ConfigFile != "" {
+		if _, err := os.Stat(result.ConfigFile); err == nil {
+			file.SetConfigFile(result.ConfigFile)
+			if err := file.ReadInConfig(); err != nil {
+				return result, err
+			}
+		}
+	}
+
+	known := make(map[string]configEnvVar)
+	for _, v := range configEnvVars {
+		if v.key != "" {
+			known[v.key] = v
+		}
+	}
+
+	var unknown []string
+	for _, key := range file.AllKeys() {
+		top, _, _ := strings.Cut(key, ".")
+		if _, ok := known[top]; !ok && top != "searches" && top != "extractors" {
+			unknown = append(unknown, top)
+		}
+	}
+	sort.Strings(unknown)
+	for i, key := range unknown {
+		if i > 0 && unknown[i-1] == key {
+			continue
+		}
+		p := types.ConfigProblem{Key: key, Message: "unknown key"}
+		if s := suggestConfigKey(key); s != "" {
+			p.Suggestion = s
+		}
+		result.Problems = append(result.Problems, p)
+	}
+
+	for _, v := range configEnvVars {
+		if v.key == "" {
+			continue
+		}
+		if file.IsSet(v.key) {
+			if msg := validateConfigValue(v.key, file.Get(v.key)); msg != "" {
+				result.Problems = append(result.Problems, types.ConfigProblem{Key: v.key, Message: msg})
+			}
+		}
+
+		setting := types.ConfigSetting{Key: v.key, Source: "default"}
+		switch {
+		case configFlags[v.key] != "" && cmd.Flags().Changed(configFlags[v.key]):
+			setting.Source = "flag"
+		case os.Getenv(v.envName()) != "":
+			setting.Source = "env"
+		case file.IsSet(v.key):
+			setting.Source = "config"
+		}
+		if !v.secret {
+			setting.Value = viper.GetString(v.key)
+		} else if viper.GetString(v.key) != "" {
+			setting.Value = "(hidden)"
+		}
+		result.Settings = append(result.Settings, setting)
+	}
+
+	result.Problems = append(result.Problems, checkSavedSearches(file)...)
+	result.Problems = append(result.Problems, checkExtractors(file)...)
+
+	result.Valid = len(result.Problems) == 0
+	return result, nil
+}
+
+// checkSavedSearches validates the saved searches under searches in the
+// config file.
+func checkSavedSearches(file *viper.Viper) []types.ConfigProblem {
+	if !file.IsSet("searches") {
+		return nil
+	}
+	searches, ok := file.Get("searches").(map[string]any)
+	if !ok {
+		return []types.ConfigProblem{{Key: "searches", Message: "expected a mapping of search names to filters"}}
+	}
+	var problems []types.ConfigProblem
+	for _, name := range slices.Sorted(maps.Keys(searches)) {
+		key := "searches." + name
+		search, ok := searches[name].(map[string]any)
+		if !ok {
+			problems = append(problems, types.ConfigProblem{Key: key, Message: "expected a mapping of filters"})
+			continue
+		}
+		if msg := validateSavedSearch(search); msg != "" {
+			problems = append(problems, types.ConfigProblem{Key: key, Message: msg})
+		}
+	}
+	return problems
+}
+
+// checkExtractors validates the attachment extractors under extractors in
+// the config file: each file extension must name a shell command.
+func checkExtractors(file *viper.Viper) []types.ConfigProblem {
+	if !file.IsSet("extractors") {
+		return nil
+	}
+	extractors, ok := file.Get("extractors").(map[string]any)
+	if !ok {
+		return []types.ConfigProblem{{Key: "extractors", Message: "expected a mapping of file extensions to commands"}}
+	}
+	var problems []types.ConfigProblem
+	for _, ext := range slices.Sorted(maps.Keys(extractors)) {
+		if command, ok := extractors[ext].(string); !ok || strings.TrimSpace(command) == "" {
+			problems = append(problems, types.ConfigProblem{Key: "extractors." + ext, Message: "expected a shell command"})
+		}
+	}
+	return problems
+}
+
+// validateConfigValue returns a description of what is wrong with a config
+// file value, or "" when it is valid.
+func validateConfigValue(key string, value any) string {
+	if key == "ascii" || key == "hyperlinks" || key == "redact" || key == "mark_read_thread" || key == "mailbox_write" {
+		if _, ok := value.(bool); !ok {
+			return fmt.Sprintf("expected true or false, got %T", value)
+		}
+		return ""
+	}
+	if key == "max_concurrent_requests" || key == "requests_per_second" {
+		return validateLimit(key, value)
+	}
+	s, ok := value.(string)
+	if !ok {
+		return fmt.Sprintf("expected a string, got %T", value)
+	}
+	switch key {
+	case "format":
+		if s != "json" && s != "text" {
+			return fmt.Sprintf("unsupported output format %q (supported: json, text)", s)
+		}
+	case "server":
+		if !slices.Contains(client.ServerKinds, s) {
+			return fmt.Sprintf("unknown server %q (supported: %s)", s, strings.Join(client.ServerKinds, ", "))
+		}
+	case "session_url":
+		u, err := url.Parse(s)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Sprintf("%q is not an http(s) URL", s)
+		}
+	}
+	return ""
+}
+
+// validateLimit checks a rate limit setting: a non-negative number, whole
+// for max_concurrent_requests.
+func validateLimit(key string, value any) string {
+	var n float64
+	switch v := value.(type) {
+	case int:
+		n = float64(v)
+	case float64:
+		if key == "max_concurrent_requests" && v != float64(int(v)) {
+			return fmt.Sprintf("expected a whole number, got %g", v)
+		}
+		n = v
+	default:
+		return fmt.Sprintf("expected a number, got %T", value)
+	}
+	if n < 0 {
+		return fmt.Sprintf("expected zero or greater, got %g", n)
+	}
+	return ""
+}
+
+// suggestConfigKey returns the known key closest to key when it is within
+// two edits, for catching typos such as sesion_url.
+func suggestConfigKey(key string) string {
+	best, bestDist := "", 3
+	for _, v := range configEnvVars {
+		if v.key == "" {
+			continue
+		}
+		if d := textdiff.Distance(key, v.key); d < bestDist {
+			best, bestDist = v.key, d
+		}
+	}
+	return best
+}
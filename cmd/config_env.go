@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+const envPrefix = "FM"
+
+// configEnvVar describes an environment variable fm reads. Variables with a
+// config key are FM_-prefixed and override that key; the rest are read
+// directly.
+type configEnvVar struct {
+	key         string
+	name        string
+	description string
+	secret      bool
+}
+
+var configEnvVars = []configEnvVar{
+	{key: "credential_command", description: "Shell command that prints the API token to stdout"},
+	{key: "session_url", description: "JMAP session endpoint"},
+	{key: "format", description: "Output format: json or text"},
+	{key: "account_id", description: "JMAP account ID override"},
+	{key: "account", description: "Account to use, by name, email, or ID"},
+	{key: "ascii", description: "Plain ASCII text output without color: true or false"},
+	{key: "hyperlinks", description: "Link subjects and mailbox names to the Fastmail web app: true or false"},
+	{key: "redact", description: "Mask email addresses, phone numbers, and tokens in output: true or false"},
+	{key: "mark_read_thread", description: "Make fm mark-read cover whole threads: true or false"},
+	{key: "mailbox_write", description: "Allow fm to create, rename, and move mailboxes, as with fm move --create-missing and fm mailboxes rename: true or false"},
+	{key: "max_concurrent_requests", description: "Most JMAP requests in flight at once; 0 uses the server's advertised limit"},
+	{key: "requests_per_second", description: "Most JMAP requests started per second; 0 for no limit"},
+	{key: "server", description: "JMAP server kind for quirk handling: auto, fastmail, cyrus, stalwart, or generic"},
+	{key: "remote_image_domains", description: "Domains fm read --save-images may download remote images from, separated by spaces or commas; empty allows any"},
+	{key: "webhook_secret", description: "HMAC key for signing fm watch --webhook requests", secret: true},
+	{name: "XDG_CONFIG_HOME", description: "Base directory for the config file (not used on Windows)"},
+	{name: "XDG_CACHE_HOME", description: "Base directory for caches and state files (not used on Windows)"},
+}
+
+func (v configEnvVar) envName() string {
+	if v.key == "" {
+		return v.name
+	}
+	return envPrefix + "_" + strings.ToUpper(v.key)
+}
+
+var configEnvCmd = &cobra.Command{
+	Use:   "env",
+	Short: "List the environment variables fm recognizes",
+	Long: `List every environment variable fm recognizes, the config key it
+overrides, and whether it is set. Secret values are not shown.
+
+FM_ variables override the matching config file keys.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return formatter().Format(os.Stdout, listConfigEnv())
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configEnvCmd)
+}
+
+func listConfigEnv() types.ConfigEnvResult {
+	result := types.ConfigEnvResult{Variables: make([]types.EnvVar, 0, len(configEnvVars))}
+	for _, v := range configEnvVars {
+		ev := types.EnvVar{
+			Name:        v.envName(),
+			ConfigKey:   v.key,
+			Description: v.description,
+		}
+		value, ok := os.LookupEnv(v.envName())
+		ev.Set = ok
+		if ok && !v.secret {
+			ev.Value = value
+		}
+		result.Variables = append(result.Variables, ev)
+	}
+	return result
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/paths"
+	"github.com/cboone/fm/internal/types"
+)
+
+var configPathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Show the resolved config and cache locations",
+	Long: `Show where fm looks for its config file and keeps its caches and state
+files.
+
+On Windows, config lives under %AppData%\fm and caches under
+%LocalAppData%\fm. Elsewhere, $XDG_CONFIG_HOME/fm and $XDG_CACHE_HOME/fm are
+used when set, falling back to ~/.config/fm and ~/.cache/fm. --config
+overrides the config file location.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		result, err := resolveConfigPaths()
+		if err != nil {
+			return exitError("general_error", err.Error(), "Set HOME, or pass --config explicitly")
+		}
+		return formatter().Format(os.Stdout, result)
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configPathCmd)
+}
+
+func resolveConfigPaths() (types.ConfigPathsResult, error) {
+	var result types.ConfigPathsResult
+	var err error
+
+	if result.ConfigDir, err = paths.ConfigDir(); err != nil {
+		return result, err
+	}
+	if result.CacheDir, err = paths.CacheDir(); err != nil {
+		return result, err
+	}
+
+	result.ConfigFile = cfgFile
+	if result.ConfigFile == "" {
+		if result.ConfigFile, err = paths.ConfigFile(); err != nil {
+			return result, err
+		}
+	}
+	_, statErr := os.Stat(result.ConfigFile)
+	result.ConfigExists = statErr == nil
+
+	if result.ResultCache, err = lastResultPath(); err != nil {
+		return result, err
+	}
+	if result.ExportState, err = exportStatePath(); err != nil {
+		return result, err
+	}
+	return result, nil
+}
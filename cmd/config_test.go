@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+func TestConfigPath_UsesXDGCacheHome(t *testing.T) {
+	cacheHome := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheHome)
+
+	args := commandArgsForServer(t, "http://unused.invalid", "config", "path")
+	stdout, stderr, err := runCLICommand(t, args)
+	if err != nil {
+		t.Fatalf("expected success, got: %v\nstderr=%s", err, stderr)
+	}
+
+	var result types.ConfigPathsResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, stdout)
+	}
+	if !result.ConfigExists {
+		t.Errorf("expected the --config file %s to be reported as existing", result.ConfigFile)
+	}
+	if want := filepath.Join(cacheHome, "fm"); result.CacheDir != want {
+		t.Errorf("expected cache dir %s, got %s", want, result.CacheDir)
+	}
+	if want := filepath.Join(cacheHome, "fm", "last.json"); result.ResultCache != want {
+		t.Errorf("expected result cache %s, got %s", want, result.ResultCache)
+	}
+}
+
+func TestListConfigEnv(t *testing.T) {
+	t.Setenv("FM_FORMAT", "text")
+	t.Setenv("FM_ACCOUNT_ID", "A9")
+	t.Setenv("FM_WEBHOOK_SECRET", "s3cret")
+
+	vars := make(map[string]types.EnvVar)
+	for _, v := range listConfigEnv().Variables {
+		vars[v.Name] = v
+	}
+
+	if v := vars["FM_FORMAT"]; !v.Set || v.Value != "text" || v.ConfigKey != "format" {
+		t.Errorf("unexpected FM_FORMAT entry: %+v", v)
+	}
+	if v := vars["FM_ACCOUNT_ID"]; !v.Set || v.Value != "A9" || v.ConfigKey != "account_id" {
+		t.Errorf("unexpected FM_ACCOUNT_ID entry: %+v", v)
+	}
+	if v := vars["FM_WEBHOOK_SECRET"]; !v.Set || v.Value != "" {
+		t.Errorf("expected the webhook secret to be reported set but hidden, got %+v", v)
+	}
+}
+
+func TestConfigCheck_ReportsTyposAndSources(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	config := "sesion_url: https://example.com/session\nformat: xml\naccount_id: A1\n"
+	if err := os.WriteFile(configPath, []byte(config), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	t.Setenv("FM_CREDENTIAL_COMMAND", "echo token")
+
+	stdout, _, err := runCLICommand(t, []string{"--config", configPath, "--format", "json", "config", "check"})
+	if !errors.Is(err, ErrSilent) {
+		t.Fatalf("expected config_error, got: %v", err)
+	}
+
+	var result types.ConfigCheckResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, stdout)
+	}
+	if result.Valid || len(result.Problems) != 2 {
+		t.Fatalf("expected two problems, got %+v", result.Problems)
+	}
+	if p := result.Problems[0]; p.Key != "sesion_url" || p.Suggestion != "session_url" {
+		t.Errorf("expected a session_url suggestion for sesion_url, got %+v", p)
+	}
+	if p := result.Problems[1]; p.Key != "format" {
+		t.Errorf("expected an invalid format problem, got %+v", p)
+	}
+
+	sources := make(map[string]string)
+	for _, s := range result.Settings {
+		sources[s.Key] = s.Source
+	}
+	want := map[string]string{
+		"format":             "flag",
+		"credential_command": "env",
+		"account_id":         "config",
+		"session_url":        "default",
+	}
+	for key, source := range want {
+		if sources[key] != source {
+			t.Errorf("expected %s from %s, got %s", key, source, sources[key])
+		}
+	}
+}
+
+func TestConfigCheck_ValidatesRateLimits(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	config := "max_concurrent_requests: 1.5\nrequests_per_second: -2\n"
+	if err := os.WriteFile(configPath, []byte(config), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	t.Setenv("FM_CREDENTIAL_COMMAND", "echo token")
+
+	stdout, _, err := runCLICommand(t, []string{"--config", configPath, "--format", "json", "config", "check"})
+	if !errors.Is(err, ErrSilent) {
+		t.Fatalf("expected config_error, got: %v", err)
+	}
+
+	var result types.ConfigCheckResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, stdout)
+	}
+	if len(result.Problems) != 2 {
+		t.Fatalf("expected two problems, got %+v", result.Problems)
+	}
+	if p := result.Problems[0]; p.Key != "max_concurrent_requests" || !strings.Contains(p.Message, "whole number") {
+		t.Errorf("expected a whole number problem, got %+v", p)
+	}
+	if p := result.Problems[1]; p.Key != "requests_per_second" || !strings.Contains(p.Message, "zero or greater") {
+		t.Errorf("expected a negative rate problem, got %+v", p)
+	}
+}
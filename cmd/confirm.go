@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// addYesFlag registers --yes, which answers confirm's question in advance.
+func addYesFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolP("yes", "y", false, "do not ask for confirmation")
+}
+
+// confirm asks on the terminal whether to go ahead with the change
+// described by question. It returns nil when the user agrees or --yes was
+// given. Without a terminal to answer on, such as when stdin is a pipe or
+// /dev/null, the change is refused with a hint to pass --yes.
+func confirm(cmd *cobra.Command, question string) error {
+	if yes, _ := cmd.Flags().GetBool("yes"); yes {
+		return nil
+	}
+	required := func() error {
+		return exitError("general_error", "confirmation required: "+question,
+			"Pass --yes to confirm without a prompt, or --dry-run to preview")
+	}
+	if !isTerminal(os.Stdin) {
+		return required()
+	}
+
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Fprintln(os.Stderr)
+		return required()
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	}
+	return exitError("general_error", "cancelled", "")
+}
@@ -0,0 +1,14 @@
+package cmd
+
+import "github.com/spf13/cobra"
+
+var contactsCmd = &cobra.Command{
+	Use:   "contacts",
+	Short: "Collect contacts sent by email",
+	Long: `Work with the contact cards (vCards) people send as attachments, so they
+can be imported into an address book in one go.`,
+}
+
+func init() {
+	rootCmd.AddCommand(contactsCmd)
+}
@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/client"
+	"github.com/cboone/fm/internal/types"
+	"github.com/cboone/fm/internal/vcard"
+)
+
+// maxVCardAttachmentSize is the largest attachment contacts extract
+// downloads; larger ones are skipped.
+const maxVCardAttachmentSize = 1 << 20
+
+// vcardTypes are the MIME types contacts extract reads as vCards, besides
+// attachments named *.vcf.
+var vcardTypes = []string{"text/vcard", "text/x-vcard", "text/directory"}
+
+var contactsExtractCmd = &cobra.Command{
+	Use:   "extract [email-id...] --output <file>",
+	Short: "Write the vCard attachments of emails to one file",
+	Long: `Find the vCard attachments (text/vcard, or files named *.vcf) of the emails
+given by ID or matched by filter flags, and write their cards to a single
+file ready to import into an address book. With filter flags, only emails
+with attachments are considered, up to --limit of them.
+
+Cards are deduplicated by email address: a card whose addresses all appear
+in a card already written is left out, so the first card found for a
+person wins. Cards without an address are kept unless an identical card
+was written. The file is replaced atomically.`,
+	Example: `  fm contacts extract --mailbox inbox --after 2026-01-01 --output contacts.vcf
+  fm contacts extract M1 M2 --output contacts.vcf`,
+	Args:              cobra.ArbitraryArgs,
+	ValidArgsFunction: completeEmailIDs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+		if output == "" {
+			return exitError("general_error", "required flag \"output\" not set",
+				"Provide a vCard file path with --output")
+		}
+		limit, _ := cmd.Flags().GetInt("limit")
+		if limit < 1 {
+			return exitError("general_error", "--limit must be at least 1", "")
+		}
+		if err := validateIDsOrFilters(cmd, args); err != nil {
+			return err
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your credential command or the token it returns")
+		}
+
+		if hasFilterFlags(cmd) && !cmd.Flags().Changed("has-attachment") {
+			_ = cmd.Flags().Set("has-attachment", "true")
+		}
+		ids, err := resolveEmailIDs(cmd, args, c)
+		if err != nil {
+			return err
+		}
+		if len(ids) > limit {
+			ids = ids[:limit]
+		}
+
+		emails, err := c.GetAttachments(ids)
+		if err != nil {
+			return exitError("jmap_error", err.Error(), "")
+		}
+
+		result := types.ContactsExtractResult{Output: output, Emails: len(emails)}
+		var cards []vcard.Card
+		for _, e := range emails {
+			for _, a := range e.Attachments {
+				if !isVCardAttachment(a) {
+					continue
+				}
+				found, err := readVCards(c, a)
+				if err != nil {
+					return exitError("jmap_error", err.Error(), "")
+				}
+				result.Attachments++
+				cards = append(cards, found...)
+			}
+		}
+
+		unique := dedupVCards(cards)
+		result.Contacts = len(unique)
+		result.Duplicates = len(cards) - len(unique)
+		if err := writeVCards(output, unique); err != nil {
+			return exitError("general_error", err.Error(), "")
+		}
+		return formatter().Format(os.Stdout, result)
+	},
+}
+
+func init() {
+	contactsExtractCmd.Flags().String("output", "", "vCard file to write (required)")
+	contactsExtractCmd.Flags().Int("limit", 100, "maximum number of emails to search")
+	addFilterFlags(contactsExtractCmd)
+	addFromLastFlag(contactsExtractCmd)
+	contactsCmd.AddCommand(contactsExtractCmd)
+}
+
+// isVCardAttachment reports whether contacts extract reads a: a vCard by
+// its type or file name, and small enough.
+func isVCardAttachment(a client.Attachment) bool {
+	if a.Size > maxVCardAttachmentSize {
+		return false
+	}
+	mediaType, _, _ := strings.Cut(a.Type, ";")
+	return slices.Contains(vcardTypes, strings.ToLower(strings.TrimSpace(mediaType))) ||
+		strings.EqualFold(filepath.Ext(a.Name), ".vcf")
+}
+
+// readVCards downloads a and returns its cards.
+func readVCards(c *client.Client, a client.Attachment) ([]vcard.Card, error) {
+	body, err := c.DownloadAttachment(a)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	cards, err := vcard.Split(body)
+	if err != nil {
+		return nil, fmt.Errorf("reading part %s: %w", a.PartID, err)
+	}
+	return cards, nil
+}
+
+// dedupVCards returns cards without those whose addresses all belong to an
+// earlier card, or, for cards without addresses, that repeat an earlier
+// card exactly.
+func dedupVCards(cards []vcard.Card) []vcard.Card {
+	seenEmails := make(map[string]bool)
+	seenRaw := make(map[string]bool)
+	var unique []vcard.Card
+	for _, card := range cards {
+		if len(card.Emails) == 0 {
+			if seenRaw[card.Raw] {
+				continue
+			}
+			seenRaw[card.Raw] = true
+			unique = append(unique, card)
+			continue
+		}
+		isNew := false
+		for _, e := range card.Emails {
+			if !seenEmails[strings.ToLower(e)] {
+				isNew = true
+			}
+		}
+		if !isNew {
+			continue
+		}
+		for _, e := range card.Emails {
+			seenEmails[strings.ToLower(e)] = true
+		}
+		unique = append(unique, card)
+	}
+	return unique
+}
+
+// writeVCards writes cards to a temporary file beside path and renames it
+// into place, so an interrupted run leaves the previous file intact.
+func writeVCards(path string, cards []vcard.Card) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return fmt.Errorf("cannot create vCard file: %w", err)
+	}
+	for _, card := range cards {
+		if _, err = io.WriteString(tmp, card.Raw); err != nil {
+			break
+		}
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("cannot write %s: %w", path, err)
+	}
+	return nil
+}
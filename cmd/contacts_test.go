@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+func TestContactsExtract_WritesDeduplicatedCards(t *testing.T) {
+	server := newJMAPMockServer(t, nil,
+		[]map[string]any{
+			{
+				"id": "M1",
+				"attachments": []map[string]any{
+					{"partId": "2", "blobId": "B-alice", "name": "alice.vcf", "type": "application/octet-stream", "size": 90},
+					{"partId": "3", "blobId": "B-pdf", "name": "cv.pdf", "type": "application/pdf", "size": 2048},
+				},
+			},
+			{
+				"id": "M2",
+				"attachments": []map[string]any{
+					{"partId": "2", "blobId": "B-team", "name": "team", "type": "text/vcard; charset=utf-8", "size": 200},
+				},
+			},
+		},
+		nil,
+	)
+	server.blobs = map[string]string{
+		"B-alice": "BEGIN:VCARD\r\nFN:Alice\r\nEMAIL:alice@example.com\r\nEND:VCARD\r\n",
+		"B-team": "BEGIN:VCARD\nFN:Alice Smith\nEMAIL:ALICE@example.com\nEND:VCARD\n" +
+			"BEGIN:VCARD\nFN:Bob\nEMAIL:bob@example.com\nEND:VCARD\n",
+	}
+
+	output := filepath.Join(t.TempDir(), "contacts.vcf")
+	stdout, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL,
+		"contacts", "extract", "M1", "M2", "--output", output))
+	if err != nil {
+		t.Fatalf("contacts extract failed: %v\n%s", err, stderr)
+	}
+
+	var result types.ContactsExtractResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, stdout)
+	}
+	if result.Emails != 2 || result.Attachments != 2 || result.Contacts != 2 || result.Duplicates != 1 {
+		t.Errorf("expected two contacts from two attachments with one duplicate, got %+v", result)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "BEGIN:VCARD\r\nFN:Alice\r\nEMAIL:alice@example.com\r\nEND:VCARD\r\n" +
+		"BEGIN:VCARD\r\nFN:Bob\r\nEMAIL:bob@example.com\r\nEND:VCARD\r\n"
+	if string(data) != want {
+		t.Errorf("unexpected vCard file:\n%q\nwant:\n%q", data, want)
+	}
+}
+
+func TestContactsExtract_RequiresOutput(t *testing.T) {
+	_, stderr, err := runCLICommand(t, []string{"contacts", "extract", "M1"})
+	if !errors.Is(err, ErrSilent) || !strings.Contains(stderr, "output") {
+		t.Fatalf("expected a missing --output error, got: %v\n%s", err, stderr)
+	}
+}
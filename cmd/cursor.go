@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/cache"
+	"github.com/cboone/fm/internal/paths"
+	"github.com/cboone/fm/internal/types"
+)
+
+// cursorPath returns the file holding the named --since-last-run cursors.
+func cursorPath() (string, error) {
+	return paths.CacheFile("cursors.json")
+}
+
+func addSinceLastRunFlag(cmd *cobra.Command) {
+	cmd.Flags().String("since-last-run", "", "only return emails newer than the last run with this cursor name")
+}
+
+// sinceLastRun is a --since-last-run job: the named cursor as it was before
+// this run, advanced as results are returned.
+type sinceLastRun struct {
+	name    string
+	path    string
+	cursors map[string]cache.Cursor
+	cursor  cache.Cursor
+	resumed bool // the cursor existed before this run
+}
+
+// loadSinceLastRun returns the job named by --since-last-run, or nil when
+// the flag is not set.
+func loadSinceLastRun(cmd *cobra.Command) (*sinceLastRun, error) {
+	name, _ := cmd.Flags().GetString("since-last-run")
+	if !cmd.Flags().Changed("since-last-run") {
+		return nil, nil
+	}
+	if name == "" {
+		return nil, exitError("general_error", "--since-last-run needs a cursor name", "For example --since-last-run inbox-cron")
+	}
+	for _, flag := range []string{"sort", "offset"} {
+		if cmd.Flags().Changed(flag) {
+			return nil, exitError("general_error", "cannot combine --since-last-run with --"+flag,
+				"--since-last-run returns the oldest new emails first so none are skipped")
+		}
+	}
+
+	path, err := cursorPath()
+	if err != nil {
+		return nil, exitError("general_error", "cannot locate cursor file: "+err.Error(), "")
+	}
+	cursors, err := cache.LoadCursors(path)
+	if err != nil {
+		return nil, exitError("general_error", err.Error(), "Delete "+path+" to reset every cursor")
+	}
+	cursor, ok := cursors[name]
+	return &sinceLastRun{
+		name:    name,
+		path:    path,
+		cursors: cursors,
+		cursor:  cursor,
+		resumed: ok && !cursor.ReceivedAt.IsZero(),
+	}, nil
+}
+
+// after narrows a query's lower receivedAt bound to the cursor, and reports
+// whether the query should run oldest first. On the first run there is no
+// bound and the usual newest-first order applies.
+func (j *sinceLastRun) after(userAfter *time.Time) (after *time.Time, oldestFirst bool) {
+	if !j.resumed {
+		return userAfter, false
+	}
+	if userAfter != nil && userAfter.After(j.cursor.ReceivedAt) {
+		return userAfter, true
+	}
+	t := j.cursor.ReceivedAt
+	return &t, true
+}
+
+// filter drops emails returned by earlier runs from result and advances the
+// cursor past the rest.
+func (j *sinceLastRun) filter(result *types.EmailListResult) {
+	kept := result.Emails[:0]
+	for _, e := range result.Emails {
+		if j.resumed && j.cursor.Seen(e.ID, e.ReceivedAt) {
+			if result.Total > 0 {
+				result.Total--
+			}
+			continue
+		}
+		kept = append(kept, e)
+	}
+	result.Emails = kept
+	for _, e := range kept {
+		j.cursor.Advance(e.ID, e.ReceivedAt)
+	}
+}
+
+// save records the advanced cursor. A first run that returned nothing
+// leaves no cursor, so the next run starts afresh.
+func (j *sinceLastRun) save() error {
+	if j.cursor.ReceivedAt.IsZero() {
+		return nil
+	}
+	j.cursor.SavedAt = time.Now().UTC()
+	j.cursors[j.name] = j.cursor
+	if err := cache.SaveCursors(j.path, j.cursors); err != nil {
+		return exitError("general_error", err.Error(), "The next run with this cursor may repeat emails")
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/cboone/fm/internal/client"
+	"github.com/cboone/fm/internal/paths"
+)
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Keep an authenticated session warm for fast invocations",
+	Long: `Run in the foreground, holding an authenticated session, and serve it to
+other fm invocations on this machine over a unix socket. While it runs, fm
+commands with the same session URL and credential command go through the
+daemon and skip the credential command and session discovery, which makes
+interactive use much faster.
+
+Responses that only get mailboxes or identities are cached for --cache-ttl;
+any change made through the daemon clears the cache. The socket is only
+accessible to the current user. If the daemon is not running, fm connects
+directly as usual.
+
+  fm daemon &
+  fm daemon status
+  fm daemon stop`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ttl, _ := cmd.Flags().GetDuration("cache-ttl")
+		if ttl < 0 {
+			return exitError("general_error", "--cache-ttl must be zero or greater", "")
+		}
+
+		socket, err := daemonSocket()
+		if err != nil {
+			return exitError("general_error", "cannot locate the daemon socket: "+err.Error(), "")
+		}
+		if _, err := client.DaemonStatus(socket); err == nil {
+			return exitError("general_error", "a daemon is already running on "+socket,
+				"Stop it with fm daemon stop")
+		}
+		// A socket left behind by a daemon that did not exit cleanly.
+		_ = os.Remove(socket)
+
+		c, err := newDirectClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your credential command or the token it returns")
+		}
+
+		if err := os.MkdirAll(filepath.Dir(socket), 0o700); err != nil {
+			return exitError("general_error", err.Error(), "")
+		}
+		ln, err := net.Listen("unix", socket)
+		if err != nil {
+			return exitError("general_error", "cannot listen: "+err.Error(), "")
+		}
+		if err := os.Chmod(socket, 0o600); err != nil {
+			_ = ln.Close()
+			return exitError("general_error", err.Error(), "")
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		d, err := client.NewDaemon(c, socket, ttl, stop)
+		if err != nil {
+			_ = ln.Close()
+			return exitError("jmap_error", err.Error(), "")
+		}
+		httpSrv := &http.Server{Handler: d, ReadHeaderTimeout: 10 * time.Second}
+
+		if err := formatter().Format(os.Stdout, d.Status()); err != nil {
+			_ = ln.Close()
+			return err
+		}
+
+		go func() {
+			<-ctx.Done()
+			// Let requests in flight finish, but not a held-open event
+			// source stream.
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := httpSrv.Shutdown(shutdownCtx); err != nil {
+				_ = httpSrv.Close()
+			}
+		}()
+
+		if err := httpSrv.Serve(ln); err != nil && err != http.ErrServerClosed {
+			return exitError("general_error", err.Error(), "")
+		}
+		return nil
+	},
+}
+
+var daemonStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether a daemon is running and what it has served",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		socket := runningDaemonSocket()
+		if socket == "" {
+			return exitError("not_found", "no daemon is running", "Start one with fm daemon")
+		}
+		status, err := client.DaemonStatus(socket)
+		if err != nil {
+			return exitError("not_found", "no daemon is running: "+err.Error(), "Start one with fm daemon")
+		}
+		return formatter().Format(os.Stdout, status)
+	},
+}
+
+var daemonStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop the running daemon",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		socket := runningDaemonSocket()
+		if socket == "" {
+			return exitError("not_found", "no daemon is running", "")
+		}
+		status, err := client.StopDaemon(socket)
+		if err != nil {
+			return exitError("not_found", "no daemon is running: "+err.Error(), "")
+		}
+		return formatter().Format(os.Stdout, status)
+	},
+}
+
+// daemonSocket returns the socket path of the daemon for the configured
+// session URL and credential command, so that daemons for different
+// accounts or servers do not answer for each other.
+func daemonSocket() (string, error) {
+	sum := sha256.Sum256([]byte(viper.GetString("session_url") + "\n" + viper.GetString("credential_command")))
+	return paths.CacheFile("daemon-" + hex.EncodeToString(sum[:6]) + ".sock")
+}
+
+// runningDaemonSocket returns the daemon socket path when the socket
+// exists, or "" when there is no daemon to use.
+func runningDaemonSocket() string {
+	socket, err := daemonSocket()
+	if err != nil {
+		return ""
+	}
+	info, err := os.Stat(socket)
+	if err != nil || info.Mode()&fs.ModeSocket == 0 {
+		return ""
+	}
+	return socket
+}
+
+func init() {
+	daemonCmd.Flags().Duration("cache-ttl", 30*time.Second, "how long to cache mailbox and identity responses (0 to disable)")
+	daemonCmd.AddCommand(daemonStatusCmd, daemonStopCmd)
+	rootCmd.AddCommand(daemonCmd)
+}
@@ -30,7 +30,7 @@ func TestCLIReferenceCoverage(t *testing.T) {
 			continue
 		}
 
-		cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		cmd.LocalFlags().VisitAll(func(f *pflag.Flag) {
 			if f.Name == "help" {
 				return // Cobra's built-in --help is never documented
 			}
@@ -69,7 +69,7 @@ func TestHelpTestCoverage(t *testing.T) {
 			continue
 		}
 
-		cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		cmd.LocalFlags().VisitAll(func(f *pflag.Flag) {
 			if f.Name == "help" {
 				return
 			}
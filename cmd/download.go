@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/client"
+	"github.com/cboone/fm/internal/types"
+)
+
+var downloadCmd = &cobra.Command{
+	Use:   "download <email-id>",
+	Short: "Save an email's attachments to files",
+	Long: `Save an email's attachments to files in a directory.
+
+Images shown inside the HTML body (such as screenshots pasted into a
+message) are inline parts rather than attachments and are skipped unless
+--inline is given. Inline images are named by their content ID, the name the
+HTML body refers to them by.
+
+With --extract, attachments that have an extractor under extractors in the
+config file, such as pdf: pdftotext - -, are also converted to text, saved
+beside them with .txt added to the name.
+
+  fm download M1 --dir ~/Downloads
+  fm download M1 --inline`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeEmailID,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ids, err := expandHandles(args)
+		if err != nil {
+			return err
+		}
+		if len(ids) != 1 {
+			return exitError("general_error", "download accepts exactly one email", "Use a single handle such as %3, not a range")
+		}
+		dir, _ := cmd.Flags().GetString("dir")
+		inline, _ := cmd.Flags().GetBool("inline")
+		extract, _ := cmd.Flags().GetBool("extract")
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your credential command or the token it returns")
+		}
+
+		atts, err := c.ListAttachments(ids[0])
+		if err != nil {
+			return exitError(readErrorCode(err), err.Error(), "")
+		}
+
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return exitError("general_error", fmt.Sprintf("cannot create download directory: %v", err), "")
+		}
+
+		result := types.DownloadResult{ID: ids[0], Dir: dir, Files: []types.DownloadedFile{}}
+		used := make(map[string]bool)
+		for _, a := range atts {
+			if a.Inline && !inline {
+				continue
+			}
+			name := uniqueFileName(downloadFileName(a), used)
+			if err := saveAttachment(c, a, filepath.Join(dir, name)); err != nil {
+				_ = formatter().Format(os.Stdout, result)
+				return exitError("general_error", err.Error(), "")
+			}
+			file := types.DownloadedFile{
+				File:      name,
+				Name:      a.Name,
+				Type:      a.Type,
+				Size:      a.Size,
+				BlobID:    a.BlobID,
+				ContentID: a.ContentID,
+				Inline:    a.Inline,
+			}
+			if command := extractorFor(a); extract && command != "" {
+				textName := uniqueFileName(name+".txt", used)
+				if err := saveExtractedText(command, a, filepath.Join(dir, name), filepath.Join(dir, textName)); err != nil {
+					result.Files = append(result.Files, file)
+					_ = formatter().Format(os.Stdout, result)
+					return exitError("general_error", err.Error(), "Check the command under extractors in the config file")
+				}
+				file.TextFile = textName
+			}
+			result.Files = append(result.Files, file)
+		}
+
+		return formatter().Format(os.Stdout, result)
+	},
+}
+
+func init() {
+	downloadCmd.Flags().String("dir", ".", "directory to save files in")
+	downloadCmd.Flags().Bool("inline", false, "also save inline images, named by content ID")
+	downloadCmd.Flags().Bool("extract", false, "also save the text of attachments that have an extractor in the config file")
+	rootCmd.AddCommand(downloadCmd)
+}
+
+func saveAttachment(c *client.Client, a client.Attachment, path string) error {
+	body, err := c.DownloadAttachment(a)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, body); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("cannot write %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+// saveExtractedText runs the extractor command on the saved attachment at
+// path and writes its text to textPath.
+func saveExtractedText(command string, a client.Attachment, path, textPath string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	text, err := extractText(command, a, f)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(textPath, text, 0o600)
+}
+
+// downloadFileName returns a safe file name for an attachment: its own name
+// for attachments and its content ID for inline images, with an extension
+// from its type when the name has none.
+func downloadFileName(a client.Attachment) string {
+	name := a.Name
+	if a.Inline && a.ContentID != "" {
+		name = a.ContentID
+	}
+	name = sanitizeFileName(name)
+	generated := name == ""
+	if generated {
+		name = "part-" + sanitizeFileName(a.PartID)
+	}
+	if generated || filepath.Ext(name) == "" {
+		if exts, _ := mime.ExtensionsByType(a.Type); len(exts) > 0 {
+			name += preferredExtension(a.Type, exts)
+		}
+	}
+	return name
+}
+
+// preferredExtension picks the common extension for well-known types, since
+// mime lists alternatives such as .jpe before .jpg.
+func preferredExtension(mediaType string, exts []string) string {
+	switch mediaType {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/svg+xml":
+		return ".svg"
+	case "text/plain":
+		return ".txt"
+	}
+	return exts[0]
+}
+
+// sanitizeFileName drops directory parts and replaces characters that are
+// unsafe in file names.
+func sanitizeFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	if name == "." || name == "/" || name == ".." {
+		return ""
+	}
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || strings.ContainsRune(`<>:"/\|?*`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	return strings.TrimLeft(name, ".")
+}
+
+// uniqueFileName returns name, or name with a numeric suffix when an
+// earlier file in the same download already uses it.
+func uniqueFileName(name string, used map[string]bool) string {
+	candidate := name
+	ext := filepath.Ext(name)
+	for i := 2; used[candidate]; i++ {
+		candidate = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(name, ext), i, ext)
+	}
+	used[candidate] = true
+	return candidate
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/cboone/fm/internal/client"
+)
+
+func TestDownloadFileName(t *testing.T) {
+	tests := []struct {
+		name string
+		att  client.Attachment
+		want string
+	}{
+		{"attachment name", client.Attachment{PartID: "2", Name: "agenda.pdf", Type: "application/pdf"}, "agenda.pdf"},
+		{"inline named by content id", client.Attachment{PartID: "3", Name: "image001.png", Type: "image/png", ContentID: "ii_m1abc", Inline: true}, "ii_m1abc.png"},
+		{"jpeg extension", client.Attachment{PartID: "4", Type: "image/jpeg", ContentID: "shot", Inline: true}, "shot.jpg"},
+		{"content id with a dot keeps it", client.Attachment{PartID: "5", Type: "image/gif", ContentID: "logo.gif@x", Inline: true}, "logo.gif@x"},
+		{"path parts dropped", client.Attachment{PartID: "6", Name: "../../etc/passwd", Type: "text/plain"}, "passwd.txt"},
+		{"unsafe characters replaced", client.Attachment{PartID: "7", Name: `a:b*c?.txt`, Type: "text/plain"}, "a_b_c_.txt"},
+		{"unnamed part", client.Attachment{PartID: "1.2", Type: "application/pdf"}, "part-1.2.pdf"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := downloadFileName(tt.att); got != tt.want {
+				t.Errorf("downloadFileName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUniqueFileName(t *testing.T) {
+	used := make(map[string]bool)
+	for _, want := range []string{"a.png", "a-2.png", "a-3.png"} {
+		if got := uniqueFileName("a.png", used); got != want {
+			t.Errorf("uniqueFileName() = %q, want %q", got, want)
+		}
+	}
+}
@@ -111,6 +111,9 @@ func init() {
 	draftCmd.Flags().String("reply-to", "", "email ID to reply to")
 	draftCmd.Flags().String("reply-all", "", "email ID to reply-all to")
 	draftCmd.Flags().String("forward", "", "email ID to forward")
+	for _, name := range []string{"reply-to", "reply-all", "forward"} {
+		_ = draftCmd.RegisterFlagCompletionFunc(name, completeEmailID)
+	}
 	draftCmd.Flags().Bool("html", false, "treat body as HTML")
 
 	rootCmd.AddCommand(draftCmd)
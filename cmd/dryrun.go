@@ -8,18 +8,21 @@ import (
 )
 
 func dryRunPreview(c *client.Client, ids []string, operation string, dest *types.DestinationInfo) error {
+	return dryRunPlan(c, ids, types.DryRunResult{Operation: operation, Destination: dest})
+}
+
+// dryRunPlan previews plan, which describes the operation, with the emails
+// in ids filled in.
+func dryRunPlan(c *client.Client, ids []string, plan types.DryRunResult) error {
 	summaries, notFound, err := c.GetEmailSummaries(ids)
 	if err != nil {
 		return exitError("jmap_error", err.Error(), "")
 	}
 
-	result := types.DryRunResult{
-		Operation:   operation,
-		Count:       len(summaries),
-		Emails:      summaries,
-		NotFound:    notFound,
-		Destination: dest,
-	}
+	result := plan
+	result.Count = len(summaries)
+	result.Emails = summaries
+	result.NotFound = notFound
 
 	if err := formatter().Format(os.Stdout, result); err != nil {
 		return err
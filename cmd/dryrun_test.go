@@ -4,17 +4,22 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"maps"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+
+	"github.com/cboone/fm/internal/types"
 )
 
 type jmapMockServer struct {
@@ -23,6 +28,10 @@ type jmapMockServer struct {
 	mailboxes []map[string]any
 	emails    []map[string]any
 	notFound  []string
+	// blobs holds the content served for each blob ID by the download URL.
+	blobs map[string]string
+	// destroyed lists the IDs Email/changes reports as destroyed.
+	destroyed []string
 
 	mu           sync.Mutex
 	methodCounts map[string]int
@@ -64,6 +73,15 @@ func newJMAPMockServer(t *testing.T, mailboxes []map[string]any, emails []map[st
 				"state":          "state-1",
 			})
 			return
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/download/"):
+			// The path is /download/{accountId}/{blobId}/{name}.
+			parts := strings.Split(r.URL.Path, "/")
+			if len(parts) < 5 || m.blobs[parts[3]] == "" {
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = io.WriteString(w, m.blobs[parts[3]])
+			return
 		case r.Method == http.MethodPost && r.URL.Path == "/api":
 			var req struct {
 				MethodCalls [][]json.RawMessage `json:"methodCalls"`
@@ -121,37 +139,137 @@ func newJMAPMockServer(t *testing.T, mailboxes []map[string]any, emails []map[st
 					}
 					resp.MethodResponses = append(resp.MethodResponses, []any{"Email/get", args, callID})
 				case "Email/query":
-					ids := make([]string, len(m.emails))
-					for i, e := range m.emails {
-						ids[i] = e["id"].(string)
+					// Every email matches except those in a mailbox the
+					// filter's inMailboxOtherThan leaves out.
+					var queryArgs struct {
+						Filter struct {
+							InMailboxOtherThan []string `json:"inMailboxOtherThan"`
+						} `json:"filter"`
+					}
+					_ = json.Unmarshal(call[1], &queryArgs)
+					ids := []string{}
+					for _, e := range m.emails {
+						mailboxIDs, _ := e["mailboxIds"].(map[string]bool)
+						if !slices.ContainsFunc(queryArgs.Filter.InMailboxOtherThan, func(id string) bool { return mailboxIDs[id] }) {
+							ids = append(ids, e["id"].(string))
+						}
 					}
 					resp.MethodResponses = append(resp.MethodResponses, []any{
 						"Email/query",
 						map[string]any{
 							"accountId":  "A1",
 							"queryState": "q-1",
-							"total":      uint64(len(m.emails)),
+							"total":      uint64(len(ids)),
 							"ids":        ids,
 							"position":   0,
 						},
 						callID,
 					})
+				case "Email/changes":
+					// Every email counts as created since any state except
+					// "expired", which the server no longer has changes for.
+					var changesArgs struct {
+						SinceState string `json:"sinceState"`
+					}
+					_ = json.Unmarshal(call[1], &changesArgs)
+					if changesArgs.SinceState == "expired" {
+						resp.MethodResponses = append(resp.MethodResponses, []any{
+							"error",
+							map[string]any{"type": "cannotCalculateChanges"},
+							callID,
+						})
+						break
+					}
+					created := []string{}
+					for _, e := range m.emails {
+						created = append(created, e["id"].(string))
+					}
+					resp.MethodResponses = append(resp.MethodResponses, []any{
+						"Email/changes",
+						map[string]any{
+							"accountId":      "A1",
+							"oldState":       changesArgs.SinceState,
+							"newState":       "state-2",
+							"hasMoreChanges": false,
+							"created":        created,
+							"updated":        []string{},
+							"destroyed":      append([]string{}, m.destroyed...),
+						},
+						callID,
+					})
+				case "Thread/get":
+					// Every requested thread holds the emails sharing its threadId.
+					var getArgs struct {
+						IDs []string `json:"ids"`
+					}
+					_ = json.Unmarshal(call[1], &getArgs)
+					list := []any{}
+					for _, tid := range getArgs.IDs {
+						var emailIDs []string
+						for _, e := range m.emails {
+							if e["threadId"] == tid {
+								emailIDs = append(emailIDs, e["id"].(string))
+							}
+						}
+						list = append(list, map[string]any{"id": tid, "emailIds": emailIDs})
+					}
+					resp.MethodResponses = append(resp.MethodResponses, []any{
+						"Thread/get",
+						map[string]any{"accountId": "A1", "state": "state-1", "list": list},
+						callID,
+					})
+				case "Mailbox/set":
+					// Create each requested mailbox, giving it the next free ID,
+					// and apply each update to the mailbox it names.
+					var setArgs struct {
+						Create map[string]map[string]any `json:"create"`
+						Update map[string]map[string]any `json:"update"`
+					}
+					_ = json.Unmarshal(call[1], &setArgs)
+					created := map[string]any{}
+					updated := map[string]any{}
+					m.mu.Lock()
+					for createID, mb := range setArgs.Create {
+						mb["id"] = fmt.Sprintf("mb-new-%d", len(m.mailboxes))
+						m.mailboxes = append(m.mailboxes, mb)
+						created[createID] = map[string]any{"id": mb["id"]}
+					}
+					for id, patch := range setArgs.Update {
+						for _, mb := range m.mailboxes {
+							if mb["id"] == id {
+								maps.Copy(mb, patch)
+								updated[id] = nil
+							}
+						}
+					}
+					m.mu.Unlock()
+					resp.MethodResponses = append(resp.MethodResponses, []any{
+						"Mailbox/set",
+						map[string]any{"accountId": "A1", "created": created, "updated": updated},
+						callID,
+					})
 				case "Email/set":
-					// Parse the request to extract IDs and mark them all as updated.
+					// Parse the request to extract IDs and mark them all as
+					// updated, except those listed as not found.
 					var setArgs map[string]json.RawMessage
 					_ = json.Unmarshal(call[1], &setArgs)
 					updated := map[string]any{}
+					notUpdated := map[string]any{}
 					if raw, ok := setArgs["update"]; ok {
 						var updateMap map[string]json.RawMessage
 						if json.Unmarshal(raw, &updateMap) == nil {
 							for id := range updateMap {
-								updated[id] = nil
+								if slices.Contains(m.notFound, id) {
+									notUpdated[id] = map[string]any{"type": "notFound", "description": "email not found"}
+								} else {
+									updated[id] = nil
+								}
 							}
 						}
 					}
 					resp.MethodResponses = append(resp.MethodResponses, []any{
 						"Email/set",
-						map[string]any{"accountId": "A1", "updated": updated},
+						map[string]any{"accountId": "A1", "updated": updated, "notUpdated": notUpdated},
 						callID,
 					})
 				default:
@@ -197,6 +315,11 @@ func resetCommandFlags(cmd *cobra.Command) {
 	resetFlagSet := func(fs *pflag.FlagSet) {
 		fs.VisitAll(func(f *pflag.Flag) {
 			f.Changed = false
+			// Setting a slice flag appends, so "[]" would become a value.
+			if sv, ok := f.Value.(pflag.SliceValue); ok {
+				_ = sv.Replace(nil)
+				return
+			}
 			_ = f.Value.Set(f.DefValue)
 		})
 	}
@@ -407,6 +530,9 @@ func TestFlagDryRunShortFlag_DoesNotCallMutation(t *testing.T) {
 // --- Filter-based action tests ---
 
 func TestArchiveWithFilters_QueriesAndMutates(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", "")
+
 	server := newJMAPMockServer(t,
 		[]map[string]any{
 			{"id": "mb-inbox", "name": "Inbox", "role": "inbox"},
@@ -474,6 +600,76 @@ func TestMarkReadWithFilters_DryRun(t *testing.T) {
 	}
 }
 
+func TestArchive_SkipsEmailsAlreadyArchived(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", "")
+
+	server := newJMAPMockServer(t,
+		[]map[string]any{
+			{"id": "mb-inbox", "name": "Inbox", "role": "inbox"},
+			{"id": "mb-archive", "name": "Archive", "role": "archive"},
+		},
+		[]map[string]any{
+			{"id": "M1", "mailboxIds": map[string]bool{"mb-archive": true}},
+			{"id": "M2", "mailboxIds": map[string]bool{"mb-inbox": true}},
+		},
+		nil,
+	)
+
+	args := commandArgsForServer(t, server.server.URL, "archive", "M1", "M2", "--verbose")
+	stdout, stderr, err := runCLICommand(t, args)
+	if err != nil {
+		t.Fatalf("archive failed: %v\nstderr=%s", err, stderr)
+	}
+
+	var result types.MoveResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, stdout)
+	}
+	if result.Matched != 2 || result.Processed != 1 || result.NoOp != 1 {
+		t.Errorf("expected one archived and one no-op, got %+v", result)
+	}
+	if len(result.Archived) != 1 || result.Archived[0] != "M2" {
+		t.Errorf("expected only M2 archived, got %v", result.Archived)
+	}
+	if len(result.Results) != 2 || result.Results[1] != (types.MessageOutcome{ID: "M1", Status: "noOp"}) {
+		t.Errorf("expected a noOp outcome for M1, got %+v", result.Results)
+	}
+
+	// Once everything is archived, a rerun sends no Email/set at all.
+	server.emails[1]["mailboxIds"] = map[string]bool{"mb-archive": true}
+	sets := server.count("Email/set")
+	if _, stderr, err := runCLICommand(t, args); err != nil {
+		t.Fatalf("archive rerun failed: %v\nstderr=%s", err, stderr)
+	}
+	if server.count("Email/set") != sets {
+		t.Error("expected the rerun to send no Email/set")
+	}
+}
+
+func TestMarkRead_SkipsEmailsAlreadyRead(t *testing.T) {
+	server := newJMAPMockServer(t, nil,
+		[]map[string]any{
+			{"id": "M1", "keywords": map[string]bool{"$seen": true}},
+			{"id": "M2", "keywords": map[string]bool{}},
+		},
+		nil,
+	)
+
+	stdout, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "mark-read", "M1", "M2"))
+	if err != nil {
+		t.Fatalf("mark-read failed: %v\nstderr=%s", err, stderr)
+	}
+
+	var result types.MoveResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, stdout)
+	}
+	if result.NoOp != 1 || len(result.MarkedAsRead) != 1 || result.MarkedAsRead[0] != "M2" {
+		t.Errorf("expected M2 marked read and M1 skipped, got %+v", result)
+	}
+}
+
 func TestArchive_IDsAndFiltersMutuallyExclusive(t *testing.T) {
 	server := newJMAPMockServer(t, nil, nil, nil)
 
@@ -525,3 +721,74 @@ func TestMoveWithFilters_StillRequiresToFlag(t *testing.T) {
 		t.Fatalf("expected 'required flag' message, got: %s", stderr)
 	}
 }
+
+func TestArchiveExplain_PrintsButDoesNotSendMutation(t *testing.T) {
+	server := newJMAPMockServer(t,
+		[]map[string]any{{"id": "mb-archive", "name": "Archive", "role": "archive"}},
+		[]map[string]any{{"id": "M1", "threadId": "T1", "subject": "Hello", "receivedAt": "2026-02-14T10:30:00Z"}},
+		nil,
+	)
+
+	args := commandArgsForServer(t, server.server.URL, "archive", "--explain", "M1")
+	_, stderr, err := runCLICommand(t, args)
+	if err == nil {
+		t.Fatal("expected the withheld mutation to be reported as a failure")
+	}
+	if !strings.Contains(stderr, `"Email/set"`) || !strings.Contains(stderr, "not sent: changes server state") {
+		t.Fatalf("expected the Email/set request on stderr, got: %s", stderr)
+	}
+	if server.count("Mailbox/get") != 1 {
+		t.Fatalf("expected Mailbox/get to be sent once, got %d", server.count("Mailbox/get"))
+	}
+	if server.count("Email/set") != 0 {
+		t.Fatalf("expected Email/set not to be sent, got %d", server.count("Email/set"))
+	}
+}
+
+func TestArchive_FiltersLeaveOutTrashAndJunk(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", "")
+
+	server := newJMAPMockServer(t,
+		[]map[string]any{
+			{"id": "mb-inbox", "name": "Inbox", "role": "inbox"},
+			{"id": "mb-archive", "name": "Archive", "role": "archive"},
+			{"id": "mb-trash", "name": "Trash", "role": "trash"},
+			{"id": "mb-junk", "name": "Junk", "role": "junk"},
+		},
+		[]map[string]any{
+			{"id": "M1", "mailboxIds": map[string]bool{"mb-inbox": true}},
+			{"id": "M2", "mailboxIds": map[string]bool{"mb-trash": true}},
+			{"id": "M3", "mailboxIds": map[string]bool{"mb-junk": true}},
+		},
+		nil,
+	)
+
+	tests := []struct {
+		name    string
+		extra   []string
+		matched int
+	}{
+		{"default", nil, 1},
+		{"include trash", []string{"--include-trash"}, 2},
+		{"include both", []string{"--include-trash", "--include-junk"}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := commandArgsForServer(t, server.server.URL,
+				append([]string{"archive", "--from", "alice@example.com"}, tt.extra...)...)
+			stdout, stderr, err := runCLICommand(t, args)
+			if err != nil {
+				t.Fatalf("archive failed: %v\nstderr=%s", err, stderr)
+			}
+
+			var result types.MoveResult
+			if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+				t.Fatalf("decode output: %v\n%s", err, stdout)
+			}
+			if result.Matched != tt.matched {
+				t.Errorf("expected %d matched, got %+v", tt.matched, result)
+			}
+		})
+	}
+}
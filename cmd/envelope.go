@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/cboone/fm/internal/output"
+)
+
+// envelope collects the result and error of the running command for
+// --errors-to stdout, or is nil.
+var envelope *output.Envelope
+
+// startEnvelope validates --errors-to and, for stdout, makes formatter()
+// and exitError collect into an envelope written when the command
+// finishes. Errors raised before this point still go to stderr.
+func startEnvelope(cmd *cobra.Command) error {
+	switch errorsTo() {
+	case "stderr":
+		return nil
+	case "stdout":
+	default:
+		return exitError("general_error", fmt.Sprintf("unsupported --errors-to value: %q", errorsTo()),
+			"supported values: stderr, stdout")
+	}
+	if viper.GetString("format") != "json" {
+		return exitError("general_error", "--errors-to stdout writes JSON and cannot be used with --format "+viper.GetString("format"),
+			"Use --format json, the default")
+	}
+	if cmd == watchCmd || cmd == mockServerCmd || cmd == serveCmd || cmd == daemonCmd {
+		return exitError("general_error", "--errors-to stdout cannot be used with fm "+cmd.Name(),
+			"fm "+cmd.Name()+" runs until interrupted, so it has no single result")
+	}
+
+	var f output.Formatter = &output.JSONFormatter{}
+	if viper.GetBool("redact") {
+		f = output.Redacted(f, redactor)
+	}
+	envelope = output.NewEnvelope(f)
+	return nil
+}
+
+// finishEnvelope writes the envelope to stdout. It runs before
+// finishOutputFile, so that --output receives the envelope too.
+func finishEnvelope() {
+	e := envelope
+	if e == nil {
+		return
+	}
+	envelope = nil
+	if err := e.Write(os.Stdout); err != nil {
+		outputErr = exitError("general_error", "cannot write output: "+err.Error(), "")
+	}
+}
+
+// envelopeUsageError reports an argument or flag error from cobra, which
+// happens before startEnvelope, in an envelope of its own when
+// --errors-to stdout was given.
+func envelopeUsageError(err error) error {
+	if errorsTo() != "stdout" {
+		return err
+	}
+	e := output.NewEnvelope(&output.JSONFormatter{})
+	_ = e.FormatError(nil, "general_error", err.Error(), "Run the command with --help to see its usage")
+	if werr := e.Write(os.Stdout); werr != nil {
+		return err
+	}
+	return ErrSilent
+}
+
+// errorsTo returns the --errors-to setting.
+func errorsTo() string {
+	return rootCmd.PersistentFlags().Lookup("errors-to").Value.String()
+}
+
+// warn reports a problem that does not stop the command. It is written
+// like an error, or added to the envelope's warnings with --errors-to
+// stdout.
+func warn(code string, message string, hint string) {
+	if envelope != nil {
+		envelope.Warn(code, message, hint)
+		return
+	}
+	_ = exitError(code, message, hint)
+}
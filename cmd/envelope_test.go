@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+func decodeEnvelope(t *testing.T, stdout string) types.Envelope {
+	t.Helper()
+	var env types.Envelope
+	if err := json.Unmarshal([]byte(stdout), &env); err != nil {
+		t.Fatalf("stdout is not one JSON document: %v\n%s", err, stdout)
+	}
+	return env
+}
+
+func TestErrorsToStdout_Success(t *testing.T) {
+	server := newJMAPMockServer(t, nil, nil, nil)
+
+	args := commandArgsForServer(t, server.server.URL, "--errors-to", "stdout", "accounts")
+	stdout, stderr, err := runCLICommand(t, args)
+	if err != nil {
+		t.Fatalf("expected success, got: %v\nstderr=%s", err, stderr)
+	}
+	env := decodeEnvelope(t, stdout)
+	if !env.OK || env.Error != nil {
+		t.Errorf("expected ok, got %s", stdout)
+	}
+	if _, isList := env.Result.([]any); !isList {
+		t.Errorf("expected the accounts in result, got %s", stdout)
+	}
+}
+
+func TestErrorsToStdout_Failure(t *testing.T) {
+	stdout, stderr, err := runCLICommand(t, []string{"list", "--flagged", "--unflagged", "--errors-to", "stdout"})
+	if !errors.Is(err, ErrSilent) {
+		t.Fatalf("expected ErrSilent, got %v", err)
+	}
+	if stderr != "" {
+		t.Errorf("expected nothing on stderr, got: %s", stderr)
+	}
+	env := decodeEnvelope(t, stdout)
+	if env.OK || env.Result != nil {
+		t.Errorf("expected a failure without a result, got %s", stdout)
+	}
+	if env.Error == nil || env.Error.Error != "general_error" || !strings.Contains(env.Error.Message, "mutually exclusive") {
+		t.Errorf("expected the general_error, got %s", stdout)
+	}
+}
+
+func TestErrorsToStdout_RejectsText(t *testing.T) {
+	stdout, stderr, err := runCLICommand(t, []string{"accounts", "--errors-to", "stdout", "--format", "text"})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if stdout != "" || !strings.Contains(stderr, "cannot be used with --format text") {
+		t.Errorf("expected the error on stderr, got stdout=%q stderr=%q", stdout, stderr)
+	}
+}
+
+func TestErrorsToStdout_RejectsLongRunningCommands(t *testing.T) {
+	for _, name := range []string{"serve", "daemon"} {
+		for _, args := range [][]string{
+			{name, "--errors-to", "stdout"},
+			{name, "--output", filepath.Join(t.TempDir(), "out.json")},
+		} {
+			_, stderr, err := runCLICommand(t, args)
+			if err == nil || !strings.Contains(stderr, "cannot be used with fm "+name) {
+				t.Errorf("%v: expected %s to be rejected, got err=%v stderr=%s", args, name, err, stderr)
+			}
+		}
+	}
+}
+
+func TestErrorsToStdout_RejectsUnknownValue(t *testing.T) {
+	_, stderr, err := runCLICommand(t, []string{"accounts", "--errors-to", "file"})
+	if err == nil || !strings.Contains(stderr, "unsupported --errors-to value") {
+		t.Errorf("expected an unsupported value error, got err=%v stderr=%s", err, stderr)
+	}
+}
+
+func TestEnvelopeUsageError(t *testing.T) {
+	resetCommandFlags(rootCmd)
+	usageErr := errors.New("accepts 1 arg(s), received 0")
+	if got := envelopeUsageError(usageErr); got != usageErr {
+		t.Errorf("expected the error unchanged without --errors-to stdout, got %v", got)
+	}
+
+	if err := rootCmd.PersistentFlags().Set("errors-to", "stdout"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resetCommandFlags(rootCmd) })
+	tmp, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldStdout := os.Stdout
+	os.Stdout = tmp
+	got := envelopeUsageError(usageErr)
+	os.Stdout = oldStdout
+	if !errors.Is(got, ErrSilent) {
+		t.Errorf("expected ErrSilent, got %v", got)
+	}
+	data, _ := os.ReadFile(tmp.Name())
+	stdout := string(data)
+	env := decodeEnvelope(t, stdout)
+	if env.OK || env.Error == nil || env.Error.Message != usageErr.Error() {
+		t.Errorf("expected the usage error in the envelope, got %s", stdout)
+	}
+}
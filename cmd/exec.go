@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+var execCmd = &cobra.Command{
+	Use:   "exec [email-id...] [flags] -- <command> [args...]",
+	Short: "Run a command once per matched email",
+	Long: `Run a command once for each selected email, with message fields exposed
+as environment variables:
+
+  FM_ID       email ID
+  FM_FROM     sender address
+  FM_SUBJECT  subject line
+  FM_DATE     received time (RFC 3339, UTC)
+
+The command is run directly, not through a shell; use sh -c to expand the
+variables in arguments. Each run's stdout, stderr, and exit code are
+collected into the result, in the order the emails were matched.
+
+Accepts email IDs as arguments, or use filter flags to select emails
+(e.g. --from sender@example.com).
+
+  fm exec --mailbox inbox --unread -- sh -c 'notify-send "$FM_SUBJECT"'
+  fm exec --from billing@example.com -j 4 -- ./file-receipt.sh`,
+	Args: cobra.ArbitraryArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dash := cmd.ArgsLenAtDash()
+		if dash < 0 || dash == len(args) {
+			return exitError("general_error", "no command given",
+				"Put the command after --, e.g. fm exec --unread -- sh -c 'echo $FM_SUBJECT'")
+		}
+		ids, command := args[:dash], args[dash:]
+
+		parallel, _ := cmd.Flags().GetInt("parallel")
+		if parallel < 1 {
+			return exitError("general_error", "--parallel must be at least 1", "")
+		}
+
+		if err := validateIDsOrFilters(cmd, ids); err != nil {
+			return err
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your credential command or the token it returns")
+		}
+
+		ids, err = resolveEmailIDs(cmd, ids, c)
+		if err != nil {
+			return err
+		}
+
+		emails, notFound, err := c.GetEmailSummaries(ids)
+		if err != nil {
+			return exitError("jmap_error", err.Error(), "")
+		}
+		if len(emails) == 0 {
+			return exitError("not_found", "no emails found", "")
+		}
+
+		runs := runExec(command, emails, parallel)
+		result := types.ExecResult{
+			Command:  command,
+			Matched:  len(emails),
+			Runs:     runs,
+			NotFound: notFound,
+		}
+		for _, run := range runs {
+			if run.ExitCode == 0 && run.Error == "" {
+				result.Succeeded++
+			} else {
+				result.Failed++
+			}
+		}
+
+		if err := formatter().Format(os.Stdout, result); err != nil {
+			return err
+		}
+
+		if result.Failed > 0 {
+			return exitError("partial_failure", "the command failed for one or more emails", "")
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	execCmd.Flags().IntP("parallel", "j", 1, "number of commands to run at once")
+	addFilterFlags(execCmd)
+	addFromLastFlag(execCmd)
+	rootCmd.AddCommand(execCmd)
+}
+
+// runExec runs command once per email with at most parallel runs at a time.
+// Results are returned in the order of emails.
+func runExec(command []string, emails []types.EmailSummary, parallel int) []types.ExecRun {
+	runs := make([]types.ExecRun, len(emails))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+
+	for i, e := range emails {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			runs[i] = runOne(command, e)
+		}()
+	}
+	wg.Wait()
+	return runs
+}
+
+func runOne(command []string, e types.EmailSummary) types.ExecRun {
+	var stdout, stderr bytes.Buffer
+	c := exec.Command(command[0], command[1:]...)
+	c.Env = append(os.Environ(), execEnv(e)...)
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+
+	run := types.ExecRun{EmailID: e.ID}
+	err := c.Run()
+	run.Stdout = stdout.String()
+	run.Stderr = stderr.String()
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case errors.As(err, &exitErr) && exitErr.Exited():
+		run.ExitCode = exitErr.ExitCode()
+	default:
+		run.ExitCode = -1
+		run.Error = err.Error()
+	}
+	return run
+}
+
+// execEnv returns the FM_* variables describing e.
+func execEnv(e types.EmailSummary) []string {
+	var from string
+	if len(e.From) > 0 {
+		from = e.From[0].Email
+	}
+	return []string{
+		"FM_ID=" + e.ID,
+		"FM_FROM=" + from,
+		"FM_SUBJECT=" + e.Subject,
+		"FM_DATE=" + e.ReceivedAt.UTC().Format(time.RFC3339),
+	}
+}
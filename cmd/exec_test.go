@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+func TestExec_RunsCommandPerEmail(t *testing.T) {
+	server := newJMAPMockServer(t,
+		nil,
+		[]map[string]any{
+			{
+				"id": "M1", "threadId": "T1", "subject": "Invoice 42", "receivedAt": "2026-02-14T10:30:00Z",
+				"from": []map[string]any{{"email": "billing@example.com"}},
+			},
+		},
+		nil,
+	)
+
+	args := commandArgsForServer(t, server.server.URL, "exec", "M1", "--", "sh", "-c", `echo "$FM_ID|$FM_FROM|$FM_SUBJECT|$FM_DATE"`)
+	stdout, stderr, err := runCLICommand(t, args)
+	if err != nil {
+		t.Fatalf("expected success, got: %v\nstderr=%s", err, stderr)
+	}
+	if !strings.Contains(stdout, `M1|billing@example.com|Invoice 42|2026-02-14T10:30:00Z\n`) {
+		t.Fatalf("expected environment to be exposed, got: %s", stdout)
+	}
+	if !strings.Contains(stdout, `"succeeded": 1`) {
+		t.Fatalf("expected one successful run, got: %s", stdout)
+	}
+}
+
+func TestExec_RequiresCommand(t *testing.T) {
+	_, stderr, err := runCLICommand(t, []string{"exec", "M1"})
+	if err == nil {
+		t.Fatal("expected error without a command")
+	}
+	if !strings.Contains(stderr, "no command given") {
+		t.Fatalf("expected missing command error, got: %s", stderr)
+	}
+}
+
+func TestRunExec_PreservesOrderAndExitCodes(t *testing.T) {
+	emails := make([]types.EmailSummary, 5)
+	for i := range emails {
+		emails[i] = types.EmailSummary{ID: string(rune('A' + i)), ReceivedAt: time.Now()}
+	}
+
+	runs := runExec([]string{"sh", "-c", `echo $FM_ID; [ "$FM_ID" != C ]`}, emails, 3)
+
+	for i, run := range runs {
+		if run.EmailID != emails[i].ID || run.Stdout != emails[i].ID+"\n" {
+			t.Errorf("runs[%d]: expected output for %s, got %+v", i, emails[i].ID, run)
+		}
+	}
+	if runs[2].ExitCode != 1 || runs[0].ExitCode != 0 {
+		t.Errorf("expected only C to fail, got exit codes %d and %d", runs[0].ExitCode, runs[2].ExitCode)
+	}
+}
+
+func TestRunExec_MissingCommand(t *testing.T) {
+	runs := runExec([]string{"fm-test-no-such-command"}, []types.EmailSummary{{ID: "M1"}}, 1)
+	if runs[0].Error == "" || runs[0].ExitCode != -1 {
+		t.Errorf("expected start error, got %+v", runs[0])
+	}
+}
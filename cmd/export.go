@@ -0,0 +1,14 @@
+package cmd
+
+import "github.com/spf13/cobra"
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export mail state for local tools",
+	Long: `Export mail state in formats understood by local mail tools, so local
+indexers and archives stay in sync with the server.`,
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+}
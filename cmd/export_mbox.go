@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/cache"
+	"github.com/cboone/fm/internal/client"
+	"github.com/cboone/fm/internal/mbox"
+	"github.com/cboone/fm/internal/paths"
+	"github.com/cboone/fm/internal/types"
+)
+
+var exportMboxCmd = &cobra.Command{
+	Use:   "mbox --output <file> [--incremental]",
+	Short: "Export raw emails to an mbox file",
+	Long: `Export the raw messages in a mailbox (or the whole account) to an mbox
+file, oldest first.
+
+Every export remembers the server's Email state for its target file. With
+--incremental, later runs ask the server for emails created since that state
+and append only those, so a nightly cron job becomes a cheap continuous
+archive. With --mailbox, emails moved into the mailbox since are appended
+too, each only once. When no state has been recorded for the file, or the file no longer
+exists, --incremental falls back to a full export.
+
+  fm export mbox --mailbox Archive --output archive.mbox
+  fm export mbox --mailbox Archive --output archive.mbox --incremental`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+		mailboxName, _ := cmd.Flags().GetString("mailbox")
+		incremental, _ := cmd.Flags().GetBool("incremental")
+
+		if output == "" {
+			return exitError("general_error", "required flag \"output\" not set",
+				"Provide an mbox file path with --output")
+		}
+		target, err := filepath.Abs(output)
+		if err != nil {
+			return exitError("general_error", err.Error(), "")
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your credential command or the token it returns")
+		}
+
+		var mailboxID string
+		if mailboxName != "" {
+			id, err := c.ResolveMailboxID(mailboxName)
+			if err != nil {
+				return exitError("not_found", err.Error(), mailboxHint(err))
+			}
+			mailboxID = string(id)
+		}
+
+		statePath, err := exportStatePath()
+		if err != nil {
+			return exitError("general_error", "cannot locate export state: "+err.Error(), "")
+		}
+		states, err := cache.LoadExportStates(statePath)
+		if err != nil {
+			return exitError("general_error", err.Error(), "")
+		}
+
+		prev, hasState := states[target]
+		if _, statErr := os.Stat(target); statErr != nil {
+			hasState = false
+		}
+		if incremental && hasState && prev.MailboxID != mailboxID {
+			return exitError("general_error",
+				fmt.Sprintf("export state for %s was recorded for a different mailbox", output),
+				"Run without --incremental to rewrite the file")
+		}
+
+		result := types.ExportResult{Output: output}
+		var emails []client.ExportEmail
+		var state string
+		var exported []string
+
+		if incremental && hasState {
+			var seen map[string]bool
+			if prev.Exported != nil {
+				seen = make(map[string]bool, len(prev.Exported))
+				for _, id := range prev.Exported {
+					seen[id] = true
+				}
+				exported = prev.Exported
+			}
+			emails, state, err = c.ExportEmailChanges(prev.State, mailboxID, seen)
+			if err != nil {
+				return exitError("jmap_error", err.Error(),
+					"If the server cannot calculate changes, run without --incremental")
+			}
+			if err := appendMbox(c, target, emails); err != nil {
+				return exitError("general_error", err.Error(), "")
+			}
+			result.Incremental = true
+		} else {
+			emails, state, err = c.ListExportEmails(mailboxID)
+			if err != nil {
+				return exitError("jmap_error", err.Error(), "")
+			}
+			if err := rewriteMbox(c, target, emails); err != nil {
+				return exitError("general_error", err.Error(), "")
+			}
+			if mailboxID != "" {
+				exported = []string{}
+			}
+		}
+
+		if exported != nil {
+			for _, e := range emails {
+				exported = append(exported, e.ID)
+			}
+		}
+		states[target] = cache.ExportState{
+			State:     state,
+			MailboxID: mailboxID,
+			Exported:  exported,
+			SavedAt:   time.Now().UTC(),
+		}
+		if err := cache.SaveExportStates(statePath, states); err != nil {
+			return exitError("general_error", err.Error(),
+				"The mbox file was written; the next --incremental run will re-export everything")
+		}
+
+		result.Exported = len(emails)
+		result.State = state
+		return formatter().Format(os.Stdout, result)
+	},
+}
+
+func init() {
+	exportMboxCmd.Flags().String("output", "", "mbox file to write (required)")
+	exportMboxCmd.Flags().StringP("mailbox", "m", "", "only export emails in this mailbox (default: all mail)")
+	exportMboxCmd.Flags().Bool("incremental", false, "append only emails created since the last export to this file")
+	exportCmd.AddCommand(exportMboxCmd)
+}
+
+// exportStatePath returns the file recording the Email state of each export
+// target.
+func exportStatePath() (string, error) {
+	return paths.CacheFile("export-state.json")
+}
+
+// rewriteMbox writes emails to a temporary file beside path and renames it
+// into place, so an interrupted export leaves the previous file intact.
+func rewriteMbox(c *client.Client, path string, emails []client.ExportEmail) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return fmt.Errorf("cannot create mbox file: %w", err)
+	}
+	err = writeMbox(c, tmp, emails)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// appendMbox appends emails to the mbox file at path.
+func appendMbox(c *client.Client, path string, emails []client.ExportEmail) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("cannot open mbox file: %w", err)
+	}
+	err = writeMbox(c, f, emails)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func writeMbox(c *client.Client, w io.Writer, emails []client.ExportEmail) error {
+	for _, e := range emails {
+		if e.BlobID == "" {
+			return errors.New("email " + e.ID + " has no raw message blob")
+		}
+		body, err := c.OpenBlob(e.BlobID)
+		if err != nil {
+			return fmt.Errorf("downloading email %s: %w", e.ID, err)
+		}
+		err = mbox.WriteMessage(w, e.From, e.ReceivedAt, body)
+		_ = body.Close()
+		if err != nil {
+			return fmt.Errorf("writing email %s: %w", e.ID, err)
+		}
+	}
+	return nil
+}
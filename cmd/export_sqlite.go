@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/sqlexport"
+	"github.com/cboone/fm/internal/types"
+)
+
+var exportSqliteCmd = &cobra.Command{
+	Use:   "sqlite --output <file> [flags]",
+	Short: "Export emails to a SQLite database",
+	Long: `Export the headers, keywords, and mailboxes of matching emails (all mail
+when no filter flags are given) into a SQLite database for ad-hoc SQL
+analysis. With --bodies, the text body of each email is stored as well.
+
+The database is written by the sqlite3 command-line shell, which must be on
+PATH. Alternatively, --sql prints the SQL script to stdout instead. Exporting
+into an existing database replaces the rows of emails it already holds.
+
+The schema is described under "export sqlite" in docs/CLI-REFERENCE.md.
+
+  fm export sqlite --output mail.db
+  fm export sqlite --output receipts.db --mailbox Receipts --bodies
+  sqlite3 mail.db "SELECT from_email, count(*) FROM emails GROUP BY 1 ORDER BY 2 DESC"`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+		sqlOnly, _ := cmd.Flags().GetBool("sql")
+		bodies, _ := cmd.Flags().GetBool("bodies")
+
+		if output == "" && !sqlOnly {
+			return exitError("general_error", "required flag \"output\" not set",
+				"Provide a database path with --output, or use --sql to print the SQL script")
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your credential command or the token it returns")
+		}
+
+		opts, err := parseFilterOptions(cmd, c)
+		if err != nil {
+			return err
+		}
+		if err := excludeTrashAndJunk(cmd, c, &opts); err != nil {
+			return err
+		}
+
+		ids, err := c.QueryEmailIDs(opts)
+		if err != nil {
+			return exitError("jmap_error", err.Error(), "")
+		}
+		messages, err := c.GetExportMessages(ids, bodies)
+		if err != nil {
+			return exitError("jmap_error", err.Error(), "")
+		}
+		mailboxes, err := c.GetAllMailboxes()
+		if err != nil {
+			return exitError("jmap_error", err.Error(), "")
+		}
+
+		rows := make([]sqlexport.Mailbox, 0, len(mailboxes))
+		for _, mb := range mailboxes {
+			rows = append(rows, sqlexport.Mailbox{
+				ID:       string(mb.ID),
+				Name:     mb.Name,
+				Role:     string(mb.Role),
+				ParentID: string(mb.ParentID),
+			})
+		}
+
+		var script bytes.Buffer
+		if err := sqlexport.Write(&script, rows, messages); err != nil {
+			return exitError("general_error", err.Error(), "")
+		}
+
+		if sqlOnly {
+			_, err := os.Stdout.Write(script.Bytes())
+			return err
+		}
+
+		if err := runSqlite(output, &script); err != nil {
+			if errors.Is(err, exec.ErrNotFound) {
+				return exitError("general_error", "sqlite3 not found on PATH",
+					"Install the sqlite3 command-line shell, or use --sql and load the script yourself")
+			}
+			return exitError("general_error", err.Error(), "")
+		}
+
+		return formatter().Format(os.Stdout, types.SQLiteExportResult{
+			Output:   output,
+			Exported: len(messages),
+			Bodies:   bodies,
+		})
+	},
+}
+
+func init() {
+	exportSqliteCmd.Flags().String("output", "", "SQLite database file to write (required unless --sql)")
+	exportSqliteCmd.Flags().Bool("sql", false, "print the SQL script to stdout instead of running sqlite3")
+	exportSqliteCmd.Flags().Bool("bodies", false, "include the text body of each email")
+	addFilterFlags(exportSqliteCmd)
+	exportCmd.AddCommand(exportSqliteCmd)
+}
+
+// runSqlite feeds script to the sqlite3 shell for the database at path.
+func runSqlite(path string, script *bytes.Buffer) error {
+	var stderr bytes.Buffer
+	c := exec.Command("sqlite3", "-bail", path)
+	c.Stdin = script
+	c.Stderr = &stderr
+	if err := c.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return errors.New("sqlite3: " + msg)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"bufio"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/client"
+	"github.com/cboone/fm/internal/notmuch"
+)
+
+var exportTagsCmd = &cobra.Command{
+	Use:   "tags --format notmuch",
+	Short: "Export a tag dump derived from keywords and mailboxes",
+	Long: `Write a tag dump for every email to stdout, mapping JMAP keywords and
+mailboxes to local tags, keyed by Message-ID.
+
+The notmuch format is the batch-tag format read by 'notmuch restore', which
+replaces each message's tags with the dumped ones. Unread emails are tagged
+unread; $flagged, $answered, $draft, $forwarded, and $junk become flagged,
+replied, draft, passed, and spam; other keywords are used as-is without a
+leading $. Inbox, Sent, Drafts, Junk, and Trash become inbox, sent, draft,
+spam, and deleted; other mailboxes are tagged by name. Emails without a
+Message-ID are skipped.
+
+  fm export tags --format notmuch > fastmail.tags
+  notmuch restore --input=fastmail.tags`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		mailboxName, _ := cmd.Flags().GetString("mailbox")
+
+		if format != "notmuch" {
+			return exitError("general_error", "unsupported tag format: "+format,
+				"Supported formats: notmuch")
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your credential command or the token it returns")
+		}
+
+		var opts client.ExportTagsOptions
+		if mailboxName != "" {
+			mailboxID, err := c.ResolveMailboxID(mailboxName)
+			if err != nil {
+				return exitError("not_found", err.Error(), mailboxHint(err))
+			}
+			opts.MailboxID = string(mailboxID)
+		}
+
+		emails, err := c.ExportTags(opts)
+		if err != nil {
+			return exitError("jmap_error", err.Error(), "")
+		}
+
+		w := bufio.NewWriter(os.Stdout)
+		if err := notmuch.WriteDump(w, emails); err != nil {
+			return exitError("general_error", err.Error(), "")
+		}
+		return w.Flush()
+	},
+}
+
+func init() {
+	exportTagsCmd.Flags().String("format", "notmuch", "tag dump format: notmuch")
+	exportTagsCmd.Flags().StringP("mailbox", "m", "", "only export emails in this mailbox (default: all mail)")
+	exportCmd.AddCommand(exportTagsCmd)
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/cboone/fm/internal/client"
+)
+
+// extractTimeout is the longest an extractor may run on one attachment.
+const extractTimeout = 60 * time.Second
+
+// extractorFor returns the shell command configured under extractors for
+// a's file extension, or for an extension of its MIME type when its name
+// has none that is configured. It returns "" when no extractor applies.
+func extractorFor(a client.Attachment) string {
+	extractors := viper.GetStringMapString("extractors")
+	if len(extractors) == 0 {
+		return ""
+	}
+	exts := []string{filepath.Ext(a.Name)}
+	mediaType, _, _ := strings.Cut(a.Type, ";")
+	if byType, err := mime.ExtensionsByType(strings.TrimSpace(mediaType)); err == nil {
+		exts = append(exts, byType...)
+	}
+	for _, ext := range exts {
+		if command := extractors[strings.ToLower(strings.TrimPrefix(ext, "."))]; command != "" {
+			return command
+		}
+	}
+	return ""
+}
+
+// extractError is an extractor command that failed.
+type extractError struct {
+	err error
+}
+
+func (e *extractError) Error() string { return e.err.Error() }
+
+func (e *extractError) Unwrap() error { return e.err }
+
+// extractText runs the extractor command with sh, feeding it content on
+// stdin, and returns what it prints as the attachment's text. The command
+// also sees the attachment's name and type in FM_ATTACHMENT_NAME and
+// FM_ATTACHMENT_TYPE.
+func extractText(command string, a client.Attachment, content io.Reader) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), extractTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdin = content
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.Env = append(os.Environ(), "FM_ATTACHMENT_NAME="+a.Name, "FM_ATTACHMENT_TYPE="+a.Type)
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("extractor for %s timed out after %s", a.Name, extractTimeout)
+		} else if detail := strings.TrimSpace(stderr.String()); detail != "" {
+			err = fmt.Errorf("extractor for %s failed: %w\n%s", a.Name, err, detail)
+		} else {
+			err = fmt.Errorf("extractor for %s failed: %w", a.Name, err)
+		}
+		return nil, &extractError{err: err}
+	}
+	return stdout.Bytes(), nil
+}
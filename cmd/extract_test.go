@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+
+	"github.com/cboone/fm/internal/client"
+	"github.com/cboone/fm/internal/types"
+)
+
+func TestExtractorFor(t *testing.T) {
+	viper.Set("extractors", map[string]any{"pdf": "pdftotext - -", "docx": "pandoc -t plain"})
+	t.Cleanup(func() { viper.Set("extractors", nil) })
+
+	tests := []struct {
+		att  client.Attachment
+		want string
+	}{
+		{client.Attachment{Name: "Invoice.PDF", Type: "application/octet-stream"}, "pdftotext - -"},
+		{client.Attachment{Name: "scan", Type: "application/pdf"}, "pdftotext - -"},
+		{client.Attachment{Name: "notes.docx", Type: "application/octet-stream"}, "pandoc -t plain"},
+		{client.Attachment{Name: "photo.jpg", Type: "image/jpeg"}, ""},
+	}
+	for _, tt := range tests {
+		if got := extractorFor(tt.att); got != tt.want {
+			t.Errorf("extractorFor(%s, %s) = %q, want %q", tt.att.Name, tt.att.Type, got, tt.want)
+		}
+	}
+}
+
+func TestExtractText_PassesAttachmentDetails(t *testing.T) {
+	a := client.Attachment{Name: "report.pdf", Type: "application/pdf"}
+	out, err := extractText(`tr a-z A-Z; echo "$FM_ATTACHMENT_NAME"`, a, strings.NewReader("po-7741\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "PO-7741\nreport.pdf\n" {
+		t.Errorf("unexpected output: %q", out)
+	}
+
+	_, err = extractText("echo broken >&2; exit 3", a, strings.NewReader(""))
+	var extractErr *extractError
+	if !errors.As(err, &extractErr) || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("expected an extractError with stderr, got %v", err)
+	}
+}
+
+func TestAttachments_GrepUsesExtractor(t *testing.T) {
+	server := newJMAPMockServer(t, nil,
+		[]map[string]any{{
+			"id":      "M1",
+			"subject": "Purchase order",
+			"attachments": []map[string]any{
+				{"partId": "2", "blobId": "B-pdf", "name": "order.pdf", "type": "application/pdf", "size": 64},
+			},
+		}},
+		nil,
+	)
+	server.blobs = map[string]string{"B-pdf": "%PDF binary po-7741"}
+
+	args := commandArgsForServer(t, server.server.URL, "attachments", "M1", "--grep", "PO-7741")
+	config := "extractors:\n  pdf: tr a-z A-Z\n"
+	if err := os.WriteFile(args[1], []byte(config), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	stdout, stderr, err := runCLICommand(t, args)
+	if err != nil {
+		t.Fatalf("attachments failed: %v\n%s", err, stderr)
+	}
+
+	var result types.AttachmentGrepResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, stdout)
+	}
+	if result.Searched != 1 || len(result.Matches) != 1 || result.Matches[0].Text != "%PDF BINARY PO-7741" {
+		t.Errorf("expected the extracted text to match, got %+v", result)
+	}
+}
+
+func TestDownload_ExtractSavesText(t *testing.T) {
+	server := newJMAPMockServer(t, nil,
+		[]map[string]any{{
+			"id": "M1",
+			"attachments": []map[string]any{
+				{"partId": "2", "blobId": "B-pdf", "name": "order.pdf", "type": "application/pdf", "size": 5},
+			},
+		}},
+		nil,
+	)
+	server.blobs = map[string]string{"B-pdf": "hello"}
+	dir := t.TempDir()
+
+	args := commandArgsForServer(t, server.server.URL, "download", "M1", "--dir", dir, "--extract")
+	if err := os.WriteFile(args[1], []byte("extractors:\n  pdf: tr a-z A-Z\n"), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	stdout, stderr, err := runCLICommand(t, args)
+	if err != nil {
+		t.Fatalf("download failed: %v\n%s", err, stderr)
+	}
+
+	var result types.DownloadResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, stdout)
+	}
+	if len(result.Files) != 1 || result.Files[0].TextFile != "order.pdf.txt" {
+		t.Fatalf("expected the text file to be reported, got %+v", result.Files)
+	}
+	if text, _ := os.ReadFile(filepath.Join(dir, "order.pdf.txt")); string(text) != "HELLO" {
+		t.Errorf("expected the extracted text, got %q", text)
+	}
+}
+
+func TestConfigCheck_ReportsBadExtractor(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, []byte("extractors:\n  pdf: pdftotext - -\n  docx: 3\n"), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	stdout, _, err := runCLICommand(t, []string{"--config", configPath, "--format", "json", "config", "check"})
+	if !errors.Is(err, ErrSilent) {
+		t.Fatalf("expected config_error, got: %v", err)
+	}
+	var result types.ConfigCheckResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, stdout)
+	}
+	if len(result.Problems) != 1 || result.Problems[0].Key != "extractors.docx" {
+		t.Errorf("expected one problem for extractors.docx, got %+v", result.Problems)
+	}
+}
@@ -1,44 +1,89 @@
 package cmd
 
 import (
+	"strconv"
 	"strings"
 	"time"
 
+	"git.sr.ht/~rockorager/go-jmap/mail/mailbox"
 	"github.com/spf13/cobra"
 
 	"github.com/cboone/fm/internal/client"
 )
 
-const recipientToUsage = "filter by recipient address/name"
+const (
+	senderFromUsage  = "filter by sender address/name"
+	recipientToUsage = "filter by recipient address/name"
+)
 
 // filterFlagNames lists all flags that addFilterFlags may register.
 var filterFlagNames = []string{
 	"mailbox", "from", "to", "subject",
-	"before", "after", "has-attachment",
-	"unread", "flagged", "unflagged",
+	"before", "after", "older-than", "has-attachment",
+	"unread", "read", "flagged", "unflagged",
 }
 
 // addFilterFlags registers shared search/filter flags on an action command.
-// It skips --to if the command already defines that flag (e.g. move).
+// It skips --from and --to if the command already defines them (e.g. move,
+// keyword migrate).
 func addFilterFlags(cmd *cobra.Command) {
 	cmd.Flags().StringP("mailbox", "m", "", "restrict to a specific mailbox")
-	cmd.Flags().String("from", "", "filter by sender address/name")
+	if cmd.Flags().Lookup("from") == nil {
+		cmd.Flags().String("from", "", senderFromUsage)
+	}
 	if cmd.Flags().Lookup("to") == nil {
 		cmd.Flags().String("to", "", recipientToUsage)
 	}
 	cmd.Flags().String("subject", "", "filter by subject text")
 	cmd.Flags().String("before", "", "emails received before this date (RFC 3339 or YYYY-MM-DD)")
 	cmd.Flags().String("after", "", "emails received after this date (RFC 3339 or YYYY-MM-DD)")
+	cmd.Flags().String("older-than", "", "emails received more than this long ago (e.g. 90d, 2w)")
 	cmd.Flags().Bool("has-attachment", false, "only emails with attachments")
 	cmd.Flags().BoolP("unread", "u", false, "only unread messages")
+	cmd.Flags().Bool("read", false, "only read messages")
 	cmd.Flags().BoolP("flagged", "f", false, "only flagged messages")
 	cmd.Flags().Bool("unflagged", false, "only unflagged messages")
+	addIncludeTrashFlags(cmd)
+}
+
+// addIncludeTrashFlags registers --include-trash and --include-junk, which
+// bring Trash and Junk back into a search that names no mailbox.
+func addIncludeTrashFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("include-trash", false, "include Trash when no --mailbox is given")
+	cmd.Flags().Bool("include-junk", false, "include Junk when no --mailbox is given")
+}
+
+// excludeTrashAndJunk leaves the Trash and Junk mailboxes out of a search
+// that names no mailbox, unless --include-trash or --include-junk asks for
+// them, so that filter-based actions never touch deleted or junked mail by
+// accident. A --mailbox that names Trash or Junk searches it as usual.
+func excludeTrashAndJunk(cmd *cobra.Command, c *client.Client, opts *client.SearchOptions) error {
+	if opts.MailboxID != "" {
+		return nil
+	}
+	var roles []mailbox.Role
+	if includeTrash, _ := cmd.Flags().GetBool("include-trash"); !includeTrash {
+		roles = append(roles, mailbox.RoleTrash)
+	}
+	if includeJunk, _ := cmd.Flags().GetBool("include-junk"); !includeJunk {
+		roles = append(roles, mailbox.RoleJunk)
+	}
+	if len(roles) == 0 {
+		return nil
+	}
+
+	ids, err := c.MailboxIDsByRole(roles...)
+	if err != nil {
+		return exitError("jmap_error", err.Error(), "")
+	}
+	opts.ExcludeMailboxIDs = ids
+	return nil
 }
 
 // hasFilterFlags returns true if any filter flag has an effective value.
 // It ignores no-op values such as --unread=false and --subject "".
-// It also skips --to on commands where it is the destination flag
-// (e.g. move) instead of a recipient filter.
+// It also skips --from and --to on commands where they mean something else
+// (e.g. the destination of move) instead of a sender or recipient filter.
 func hasFilterFlags(cmd *cobra.Command) bool {
 	for _, name := range filterFlagNames {
 		f := cmd.Flags().Lookup(name)
@@ -47,15 +92,15 @@ func hasFilterFlags(cmd *cobra.Command) bool {
 		}
 
 		switch name {
-		case "mailbox", "from", "to", "subject", "before", "after":
-			if name == "to" && !isRecipientToFilterFlag(cmd) {
+		case "mailbox", "from", "to", "subject", "before", "after", "older-than":
+			if (name == "from" && !isSenderFromFilterFlag(cmd)) || (name == "to" && !isRecipientToFilterFlag(cmd)) {
 				continue
 			}
 			value, _ := cmd.Flags().GetString(name)
 			if strings.TrimSpace(value) != "" {
 				return true
 			}
-		case "has-attachment", "unread", "flagged", "unflagged":
+		case "has-attachment", "unread", "read", "flagged", "unflagged":
 			value, _ := cmd.Flags().GetBool(name)
 			if value {
 				return true
@@ -65,6 +110,26 @@ func hasFilterFlags(cmd *cobra.Command) bool {
 	return false
 }
 
+// addFromLastFlag registers --from-last, which selects the emails from the most
+// recent list or search instead of IDs or filters.
+func addFromLastFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("from-last", false, "act on the emails from the most recent list or search")
+}
+
+// usesFromLast reports whether --from-last is registered and set.
+func usesFromLast(cmd *cobra.Command) bool {
+	if cmd.Flags().Lookup("from-last") == nil {
+		return false
+	}
+	fromLast, _ := cmd.Flags().GetBool("from-last")
+	return fromLast
+}
+
+func isSenderFromFilterFlag(cmd *cobra.Command) bool {
+	f := cmd.Flags().Lookup("from")
+	return f != nil && f.Usage == senderFromUsage
+}
+
 func isRecipientToFilterFlag(cmd *cobra.Command) bool {
 	f := cmd.Flags().Lookup("to")
 	return f != nil && f.Usage == recipientToUsage
@@ -74,8 +139,10 @@ func isRecipientToFilterFlag(cmd *cobra.Command) bool {
 func parseFilterOptions(cmd *cobra.Command, c *client.Client) (client.SearchOptions, error) {
 	opts := client.SearchOptions{}
 
-	if from, _ := cmd.Flags().GetString("from"); strings.TrimSpace(from) != "" {
-		opts.From = from
+	if isSenderFromFilterFlag(cmd) {
+		if from, _ := cmd.Flags().GetString("from"); strings.TrimSpace(from) != "" {
+			opts.From = from
+		}
 	}
 	if isRecipientToFilterFlag(cmd) {
 		if to, _ := cmd.Flags().GetString("to"); strings.TrimSpace(to) != "" {
@@ -87,11 +154,12 @@ func parseFilterOptions(cmd *cobra.Command, c *client.Client) (client.SearchOpti
 	}
 	opts.HasAttachment, _ = cmd.Flags().GetBool("has-attachment")
 	opts.UnreadOnly, _ = cmd.Flags().GetBool("unread")
+	opts.ReadOnly, _ = cmd.Flags().GetBool("read")
 	opts.FlaggedOnly, _ = cmd.Flags().GetBool("flagged")
 	opts.UnflaggedOnly, _ = cmd.Flags().GetBool("unflagged")
 
-	if opts.FlaggedOnly && opts.UnflaggedOnly {
-		return client.SearchOptions{}, exitError("general_error", "--flagged and --unflagged are mutually exclusive", "")
+	if err := checkExclusiveFilters(cmd); err != nil {
+		return client.SearchOptions{}, err
 	}
 
 	if beforeStr, _ := cmd.Flags().GetString("before"); strings.TrimSpace(beforeStr) != "" {
@@ -104,6 +172,15 @@ func parseFilterOptions(cmd *cobra.Command, c *client.Client) (client.SearchOpti
 		opts.Before = &t
 	}
 
+	if age, _ := cmd.Flags().GetString("older-than"); strings.TrimSpace(age) != "" {
+		t, err := parseDateOrAge(strings.TrimSpace(age), time.Now())
+		if err != nil {
+			return client.SearchOptions{}, exitError("general_error", "invalid --older-than age: "+err.Error(),
+				"Use a number of days or weeks (e.g. 90d, 2w) or a date")
+		}
+		opts.Before = &t
+	}
+
 	if afterStr, _ := cmd.Flags().GetString("after"); strings.TrimSpace(afterStr) != "" {
 		afterStr = strings.TrimSpace(afterStr)
 		t, err := parseDate(afterStr)
@@ -118,7 +195,7 @@ func parseFilterOptions(cmd *cobra.Command, c *client.Client) (client.SearchOpti
 		mailboxName = strings.TrimSpace(mailboxName)
 		mailboxID, err := c.ResolveMailboxID(mailboxName)
 		if err != nil {
-			return client.SearchOptions{}, exitError("not_found", err.Error(), "")
+			return client.SearchOptions{}, exitError("not_found", err.Error(), mailboxHint(err))
 		}
 		opts.MailboxID = string(mailboxID)
 	}
@@ -126,12 +203,29 @@ func parseFilterOptions(cmd *cobra.Command, c *client.Client) (client.SearchOpti
 	return opts, nil
 }
 
-// validateIDsOrFilters ensures exactly one of email IDs or filter flags is provided.
-// It also checks for mutually exclusive filter flags early, before authentication.
+// validateIDsOrFilters ensures exactly one of email IDs, filter flags, or
+// --from-last is provided. It also checks for mutually exclusive filter flags
+// early, before authentication.
 func validateIDsOrFilters(cmd *cobra.Command, args []string) error {
 	hasIDs := len(args) > 0
 	hasFilters := hasFilterFlags(cmd)
 
+	if idsFile(cmd) != "" {
+		if hasIDs || hasFilters || usesFromLast(cmd) {
+			return exitError("general_error", "cannot combine --ids-file with email IDs, filter flags, or --from-last",
+				"Use --ids-file on its own to act on the emails listed in the file")
+		}
+		return nil
+	}
+
+	if usesFromLast(cmd) {
+		if hasIDs || hasFilters {
+			return exitError("general_error", "cannot combine --from-last with email IDs or filter flags",
+				"Use --from-last on its own to act on the most recent list or search")
+		}
+		return nil
+	}
+
 	if hasIDs && hasFilters {
 		return exitError("general_error", "cannot combine email IDs with filter flags",
 			"Use either email IDs or filter flags, not both")
@@ -142,25 +236,54 @@ func validateIDsOrFilters(cmd *cobra.Command, args []string) error {
 	}
 
 	// Check mutually exclusive flags early (before client creation).
-	flagged, _ := cmd.Flags().GetBool("flagged")
-	unflagged, _ := cmd.Flags().GetBool("unflagged")
-	if flagged && unflagged {
-		return exitError("general_error", "--flagged and --unflagged are mutually exclusive", "")
-	}
+	return checkExclusiveFilters(cmd)
+}
 
+// checkExclusiveFilters rejects filter flags that cannot be combined.
+func checkExclusiveFilters(cmd *cobra.Command) error {
+	for _, pair := range [][2]string{{"flagged", "unflagged"}, {"unread", "read"}} {
+		a, _ := cmd.Flags().GetBool(pair[0])
+		b, _ := cmd.Flags().GetBool(pair[1])
+		if a && b {
+			return exitError("general_error", "--"+pair[0]+" and --"+pair[1]+" are mutually exclusive", "")
+		}
+	}
+	before, _ := cmd.Flags().GetString("before")
+	olderThan, _ := cmd.Flags().GetString("older-than")
+	if strings.TrimSpace(before) != "" && strings.TrimSpace(olderThan) != "" {
+		return exitError("general_error", "--before and --older-than are mutually exclusive",
+			"--older-than 90d is the same as --before with the date 90 days ago")
+	}
 	return nil
 }
 
-// resolveEmailIDs returns email IDs from args or queries them using filter flags.
+// resolveEmailIDs returns email IDs from the cached result set (--from-last),
+// from args (expanding %N handles), or by querying with filter flags.
 func resolveEmailIDs(cmd *cobra.Command, args []string, c *client.Client) ([]string, error) {
+	if path := idsFile(cmd); path != "" {
+		return readIDsFile(path)
+	}
+	if usesFromLast(cmd) {
+		last, err := loadLastResult()
+		if err != nil {
+			return nil, err
+		}
+		if len(last.Result.Emails) == 0 {
+			return nil, exitError("not_found", "the most recent list or search returned no emails", "")
+		}
+		return last.IDs(), nil
+	}
 	if len(args) > 0 {
-		return args, nil
+		return expandHandles(args)
 	}
 
 	opts, err := parseFilterOptions(cmd, c)
 	if err != nil {
 		return nil, err
 	}
+	if err := excludeTrashAndJunk(cmd, c, &opts); err != nil {
+		return nil, err
+	}
 
 	ids, err := c.QueryEmailIDs(opts)
 	if err != nil {
@@ -174,9 +297,13 @@ func resolveEmailIDs(cmd *cobra.Command, args []string, c *client.Client) ([]str
 	return ids, nil
 }
 
-// resolveFirstEmailID returns a single email ID from args or queries the most
-// recent match using filter flags.
+// resolveFirstEmailID returns a single email ID from args (expanding %N handles)
+// or queries the most recent match using filter flags.
 func resolveFirstEmailID(cmd *cobra.Command, args []string, c *client.Client) (string, error) {
+	args, err := expandHandles(args)
+	if err != nil {
+		return "", err
+	}
 	if len(args) > 1 {
 		return "", exitError("general_error", "multiple email IDs provided", "Provide exactly one email ID or use filter flags")
 	}
@@ -188,6 +315,9 @@ func resolveFirstEmailID(cmd *cobra.Command, args []string, c *client.Client) (s
 	if err != nil {
 		return "", err
 	}
+	if err := excludeTrashAndJunk(cmd, c, &opts); err != nil {
+		return "", err
+	}
 
 	id, err := c.QueryFirstEmailID(opts)
 	if err != nil {
@@ -201,6 +331,22 @@ func resolveFirstEmailID(cmd *cobra.Command, args []string, c *client.Client) (s
 	return id, nil
 }
 
+// parseDateOrAge accepts anything parseDate does, plus a relative age such as
+// 90d or 2w, measured back from now.
+func parseDateOrAge(s string, now time.Time) (time.Time, error) {
+	if n := len(s); n >= 2 {
+		if count, err := strconv.Atoi(s[:n-1]); err == nil && count >= 0 {
+			switch s[n-1] {
+			case 'd':
+				return now.AddDate(0, 0, -count), nil
+			case 'w':
+				return now.AddDate(0, 0, -7*count), nil
+			}
+		}
+	}
+	return parseDate(s)
+}
+
 // parseDate parses a date string in RFC 3339 format or as a bare date (YYYY-MM-DD).
 // Bare dates are treated as midnight UTC on that day.
 func parseDate(s string) (time.Time, error) {
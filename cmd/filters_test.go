@@ -3,6 +3,7 @@ package cmd
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -101,6 +102,46 @@ func TestParseFilterOptions_RecipientToFilterStillWorks(t *testing.T) {
 	}
 }
 
+func TestParseFilterOptions_ReadOlderThan(t *testing.T) {
+	cmd := newFilterTestCommand(false)
+
+	if err := cmd.Flags().Set("read", "true"); err != nil {
+		t.Fatalf("set --read: %v", err)
+	}
+	if err := cmd.Flags().Set("older-than", "90d"); err != nil {
+		t.Fatalf("set --older-than: %v", err)
+	}
+
+	opts, err := parseFilterOptions(cmd, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !opts.ReadOnly {
+		t.Fatal("expected ReadOnly=true")
+	}
+	want := time.Now().AddDate(0, 0, -90)
+	if opts.Before == nil || opts.Before.Sub(want).Abs() > time.Minute {
+		t.Fatalf("expected Before about 90 days ago, got %v", opts.Before)
+	}
+}
+
+func TestValidateIDsOrFilters_ExclusivePairsRejected(t *testing.T) {
+	for _, pair := range [][2]string{{"unread", "read"}, {"before", "older-than"}} {
+		cmd := newFilterTestCommand(false)
+		values := map[string]string{"unread": "true", "read": "true", "before": "2026-01-01", "older-than": "90d"}
+		for _, name := range pair {
+			if err := cmd.Flags().Set(name, values[name]); err != nil {
+				t.Fatalf("set --%s: %v", name, err)
+			}
+		}
+
+		if err := validateIDsOrFilters(cmd, nil); !errors.Is(err, ErrSilent) {
+			t.Errorf("expected --%s with --%s to be rejected, got %v", pair[0], pair[1], err)
+		}
+	}
+}
+
 func TestResolveFirstEmailID_ReturnsSingleArg(t *testing.T) {
 	cmd := newFilterTestCommand(false)
 	id, err := resolveFirstEmailID(cmd, []string{"M1"}, nil)
@@ -119,3 +160,29 @@ func TestResolveFirstEmailID_RejectsMultipleArgs(t *testing.T) {
 		t.Fatal("expected error for multiple args, got nil")
 	}
 }
+
+func TestParseDateOrAge(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{in: "90d", want: time.Date(2026, 7, 17, 12, 0, 0, 0, time.UTC)},
+		{in: "2w", want: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)},
+		{in: "2026-01-15", want: time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := parseDateOrAge(tt.in, now)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.in, err)
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.in, tt.want, got)
+		}
+	}
+
+	if _, err := parseDateOrAge("90x", now); err == nil {
+		t.Error("expected error for unknown unit")
+	}
+}
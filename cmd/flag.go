@@ -12,9 +12,10 @@ import (
 )
 
 var flagCmd = &cobra.Command{
-	Use:   "flag [email-id...]",
-	Short: "Flag emails (set the $flagged keyword)",
-	Args:  cobra.ArbitraryArgs,
+	Use:               "flag [email-id...]",
+	Short:             "Flag emails (set the $flagged keyword)",
+	Args:              cobra.ArbitraryArgs,
+	ValidArgsFunction: completeEmailIDs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if err := validateIDsOrFilters(cmd, args); err != nil {
 			return err
@@ -48,6 +49,8 @@ var flagCmd = &cobra.Command{
 			return dryRunPreview(c, ids, "flag", nil)
 		}
 
+		c.SetProgress(progressPrinter("Flagging"))
+		outcomes := recordOutcomes(cmd, c)
 		var succeeded, errors []string
 		if color != nil {
 			succeeded, errors = c.SetFlaggedWithColor(ids, *color)
@@ -61,6 +64,7 @@ var flagCmd = &cobra.Command{
 			Failed:    len(errors),
 			Flagged:   succeeded,
 			Errors:    errors,
+			Results:   outcomes.results(succeeded, errors),
 		}
 
 		if err := formatter().Format(os.Stdout, result); err != nil {
@@ -68,7 +72,7 @@ var flagCmd = &cobra.Command{
 		}
 
 		if len(errors) > 0 {
-			return exitError("partial_failure", "one or more emails failed to flag", "")
+			return exitError("partial_failure", "one or more emails failed to flag", retryHint(cmd, errors))
 		}
 
 		return nil
@@ -78,6 +82,9 @@ var flagCmd = &cobra.Command{
 func init() {
 	flagCmd.Flags().StringP("color", "c", "", "flag color: red, orange, yellow, green, blue, purple, gray")
 	flagCmd.Flags().BoolP("dry-run", "n", false, "preview affected emails without making changes")
+	addVerboseFlag(flagCmd)
+	addIDsFileFlag(flagCmd)
 	addFilterFlags(flagCmd)
+	addFromLastFlag(flagCmd)
 	rootCmd.AddCommand(flagCmd)
 }
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cboone/fm/internal/cache"
+	"github.com/cboone/fm/internal/paths"
+	"github.com/cboone/fm/internal/types"
+)
+
+// lastResultPath returns the location of the result cache backing short
+// numeric handles (%1, %2-%5).
+func lastResultPath() (string, error) {
+	return paths.CacheFile("last.json")
+}
+
+// rememberResult records a list/search result so later commands can refer to
+// it by handle, replay it with fm last, or act on it with --from-last. The
+// cache is a convenience, so failures are ignored.
+func rememberResult(command string, result types.EmailListResult) {
+	path, err := lastResultPath()
+	if err != nil {
+		return
+	}
+	_ = cache.SaveLast(path, cache.LastResult{
+		Command: command,
+		SavedAt: time.Now().UTC(),
+		Result:  result,
+	})
+}
+
+// loadLastResult reads the cached result set, converting failures into
+// structured errors.
+func loadLastResult() (cache.LastResult, error) {
+	path, err := lastResultPath()
+	if err != nil {
+		return cache.LastResult{}, exitError("general_error", "cannot locate result cache: "+err.Error(), "")
+	}
+	last, err := cache.LoadLast(path)
+	if err != nil {
+		if errors.Is(err, cache.ErrNoResults) {
+			return cache.LastResult{}, exitError("not_found", err.Error(), lastResultHint)
+		}
+		return cache.LastResult{}, exitError("general_error", err.Error(), "")
+	}
+	return last, nil
+}
+
+const lastResultHint = "Handles, fm last, and --from-last refer to the most recent fm list or fm search output"
+
+// expandHandles replaces %N and %N-%M handles in args with the email IDs from
+// the most recent list or search. Args without handles are returned unchanged
+// and the cache is not read.
+func expandHandles(args []string) ([]string, error) {
+	if !cache.HasHandles(args) {
+		return args, nil
+	}
+
+	last, err := loadLastResult()
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := cache.ExpandHandles(args, last.IDs())
+	if err != nil {
+		return nil, exitError("general_error", err.Error(), lastResultHint)
+	}
+	return ids, nil
+}
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestHandles_ListThenArchiveByHandle(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", "")
+
+	server := newJMAPMockServer(t,
+		[]map[string]any{
+			{"id": "mb-inbox", "name": "Inbox", "role": "inbox"},
+			{"id": "mb-archive", "name": "Archive", "role": "archive"},
+		},
+		[]map[string]any{
+			{"id": "M1", "threadId": "T1", "subject": "First", "receivedAt": "2026-02-14T10:30:00Z", "keywords": map[string]bool{}},
+			{"id": "M2", "threadId": "T2", "subject": "Second", "receivedAt": "2026-02-14T09:30:00Z", "keywords": map[string]bool{}},
+		},
+		nil,
+	)
+
+	if _, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "list")); err != nil {
+		t.Fatalf("list failed: %v\nstderr=%s", err, stderr)
+	}
+
+	args := commandArgsForServer(t, server.server.URL, "archive", "--dry-run", "%1-%2")
+	stdout, stderr, err := runCLICommand(t, args)
+	if err != nil {
+		t.Fatalf("expected success, got: %v\nstderr=%s", err, stderr)
+	}
+	if !strings.Contains(stdout, `"count": 2`) {
+		t.Fatalf("expected both handles to expand, got: %s", stdout)
+	}
+}
+
+func TestHandles_WithoutCachedResults(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", "")
+
+	server := newJMAPMockServer(t, nil, nil, nil)
+
+	args := commandArgsForServer(t, server.server.URL, "read", "%1")
+	_, stderr, err := runCLICommand(t, args)
+	if !errors.Is(err, ErrSilent) {
+		t.Fatalf("expected ErrSilent, got: %v", err)
+	}
+	if !strings.Contains(stderr, "no cached results") {
+		t.Fatalf("expected missing cache error, got: %s", stderr)
+	}
+	if server.count("Email/get") != 0 {
+		t.Fatalf("expected no Email/get call, got %d", server.count("Email/get"))
+	}
+}
+
+func TestHandles_ReadRejectsRange(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", "")
+
+	server := newJMAPMockServer(t,
+		[]map[string]any{{"id": "mb-inbox", "name": "Inbox", "role": "inbox"}},
+		[]map[string]any{
+			{"id": "M1", "threadId": "T1", "subject": "First", "receivedAt": "2026-02-14T10:30:00Z", "keywords": map[string]bool{}},
+			{"id": "M2", "threadId": "T2", "subject": "Second", "receivedAt": "2026-02-14T09:30:00Z", "keywords": map[string]bool{}},
+		},
+		nil,
+	)
+
+	if _, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "list")); err != nil {
+		t.Fatalf("list failed: %v\nstderr=%s", err, stderr)
+	}
+
+	_, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "read", "%1-%2"))
+	if !errors.Is(err, ErrSilent) {
+		t.Fatalf("expected ErrSilent, got: %v", err)
+	}
+	if !strings.Contains(stderr, "exactly one email") {
+		t.Fatalf("expected single-email error, got: %s", stderr)
+	}
+}
+
+func TestLast_ReplaysCachedIDs(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", "")
+
+	server := newJMAPMockServer(t,
+		[]map[string]any{{"id": "mb-inbox", "name": "Inbox", "role": "inbox"}},
+		[]map[string]any{
+			{"id": "M1", "threadId": "T1", "subject": "First", "receivedAt": "2026-02-14T10:30:00Z", "keywords": map[string]bool{}},
+			{"id": "M2", "threadId": "T2", "subject": "Second", "receivedAt": "2026-02-14T09:30:00Z", "keywords": map[string]bool{}},
+		},
+		nil,
+	)
+
+	if _, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "list")); err != nil {
+		t.Fatalf("list failed: %v\nstderr=%s", err, stderr)
+	}
+	before := server.count("Email/query")
+
+	stdout, stderr, err := runCLICommand(t, []string{"last", "--quiet"})
+	if err != nil {
+		t.Fatalf("expected success, got: %v\nstderr=%s", err, stderr)
+	}
+	if stdout != "M1\nM2\n" {
+		t.Fatalf("expected cached IDs, got: %q", stdout)
+	}
+	if server.count("Email/query") != before {
+		t.Fatal("expected last not to query the server")
+	}
+}
+
+func TestFromLast_MarkReadDryRun(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", "")
+
+	server := newJMAPMockServer(t,
+		[]map[string]any{{"id": "mb-inbox", "name": "Inbox", "role": "inbox"}},
+		[]map[string]any{
+			{"id": "M1", "threadId": "T1", "subject": "First", "receivedAt": "2026-02-14T10:30:00Z", "keywords": map[string]bool{}},
+			{"id": "M2", "threadId": "T2", "subject": "Second", "receivedAt": "2026-02-14T09:30:00Z", "keywords": map[string]bool{}},
+		},
+		nil,
+	)
+
+	if _, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "list")); err != nil {
+		t.Fatalf("list failed: %v\nstderr=%s", err, stderr)
+	}
+
+	args := commandArgsForServer(t, server.server.URL, "mark-read", "--from-last", "--dry-run")
+	stdout, stderr, err := runCLICommand(t, args)
+	if err != nil {
+		t.Fatalf("expected success, got: %v\nstderr=%s", err, stderr)
+	}
+	if !strings.Contains(stdout, `"count": 2`) {
+		t.Fatalf("expected both cached emails, got: %s", stdout)
+	}
+	if server.count("Email/set") != 0 {
+		t.Fatalf("expected no Email/set call in dry-run, got %d", server.count("Email/set"))
+	}
+}
+
+func TestFromLast_RejectsExplicitIDs(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", "")
+
+	server := newJMAPMockServer(t, nil, nil, nil)
+
+	args := commandArgsForServer(t, server.server.URL, "archive", "--from-last", "M1")
+	_, stderr, err := runCLICommand(t, args)
+	if !errors.Is(err, ErrSilent) {
+		t.Fatalf("expected ErrSilent, got: %v", err)
+	}
+	if !strings.Contains(stderr, "cannot combine --from-last") {
+		t.Fatalf("expected combination error, got: %s", stderr)
+	}
+}
+
+func TestCompletion_OffersCachedEmailIDs(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", "")
+
+	server := newJMAPMockServer(t,
+		[]map[string]any{{"id": "mb-inbox", "name": "Inbox", "role": "inbox"}},
+		[]map[string]any{
+			{"id": "M1", "threadId": "T1", "subject": "First\tone", "from": []map[string]any{{"name": "Alice", "email": "alice@example.com"}}, "receivedAt": "2026-02-14T10:30:00Z", "keywords": map[string]bool{}},
+			{"id": "M2", "threadId": "T2", "subject": "Second", "receivedAt": "2026-02-14T09:30:00Z", "keywords": map[string]bool{}},
+		},
+		nil,
+	)
+
+	if _, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "list")); err != nil {
+		t.Fatalf("list failed: %v\nstderr=%s", err, stderr)
+	}
+
+	stdout, _, err := runCLICommand(t, []string{"__complete", "read", "M"})
+	if err != nil {
+		t.Fatalf("completion failed: %v", err)
+	}
+	if !strings.Contains(stdout, "M1\tFirst one (Alice)\n") || !strings.Contains(stdout, "M2\tSecond\n") {
+		t.Errorf("expected cached IDs with subject hints, got: %q", stdout)
+	}
+
+	stdout, _, _ = runCLICommand(t, []string{"__complete", "archive", "M1", "%"})
+	if !strings.Contains(stdout, "%1\tFirst one (Alice)\n") || !strings.Contains(stdout, "%2\tSecond\n") {
+		t.Errorf("expected handles, got: %q", stdout)
+	}
+
+	stdout, _, _ = runCLICommand(t, []string{"__complete", "read", "M1", ""})
+	if strings.Contains(stdout, "M2") {
+		t.Errorf("expected no completions after read's single argument, got: %q", stdout)
+	}
+
+	stdout, _, _ = runCLICommand(t, []string{"__complete", "keyword", "set", ""})
+	if strings.Contains(stdout, "M1") {
+		t.Errorf("expected no email IDs for the keyword argument, got: %q", stdout)
+	}
+}
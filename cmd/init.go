@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/cboone/fm/internal/client"
+	"github.com/cboone/fm/internal/paths"
+	"github.com/cboone/fm/internal/types"
+)
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Set up fm: store a token, verify it, and write the config file",
+	Long: `Set up fm in one step. init asks for a Fastmail API token (create one
+under Settings > Privacy & Security > Integrations > API tokens), offers to
+store it in the OS keychain or asks for a credential command that prints it,
+verifies the session, detects the account ID, writes the config file, and
+lists the inbox as a smoke test.
+
+Prompts are written to stderr and answers read from stdin. When a credential
+command is already configured (for example with --credential-command), the
+token is read from it and no questions are asked.
+
+An existing config file is not replaced unless --force is given.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		force, _ := cmd.Flags().GetBool("force")
+
+		configFile := cfgFile
+		if configFile == "" {
+			var err error
+			if configFile, err = paths.ConfigFile(); err != nil {
+				return exitError("general_error", err.Error(), "Set HOME, or pass --config explicitly")
+			}
+		}
+		if _, err := os.Stat(configFile); err == nil && !force {
+			return exitError("general_error", "config file "+configFile+" already exists",
+				"Use --force to replace it, or edit it directly")
+		}
+
+		in := bufio.NewReader(cmd.InOrStdin())
+		prompts := cmd.ErrOrStderr()
+
+		credCmd := viper.GetString("credential_command")
+		keychain := false
+		var token string
+		var err error
+		if credCmd != "" {
+			if token, err = runCredentialCommand(credCmd); err != nil {
+				return exitError("authentication_failed", err.Error(),
+					"Check your credential command or the token it returns")
+			}
+		} else {
+			if token, credCmd, keychain, err = promptCredentials(in, prompts); err != nil {
+				return err
+			}
+		}
+
+		sessionURL := viper.GetString("session_url")
+		c, err := client.New(sessionURL, token, viper.GetString("account_id"))
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check that the token is valid and has not been revoked")
+		}
+
+		if keychain {
+			if err := storeInKeychain(token); err != nil {
+				return exitError("general_error", "cannot store token in keychain: "+err.Error(),
+					"Run fm init again and answer n to use a credential command instead")
+			}
+		}
+		settings := map[string]any{"account_id": string(c.AccountID())}
+		if credCmd != "" {
+			settings["credential_command"] = credCmd
+		}
+		if sessionURL != defaultSessionURL {
+			settings["session_url"] = sessionURL
+		}
+		if err := writeInitConfig(configFile, settings); err != nil {
+			return exitError("general_error", err.Error(), "")
+		}
+
+		inbox, err := c.ListEmails(client.ListOptions{
+			MailboxNameOrID: "inbox",
+			Limit:           5,
+			SortField:       "receivedAt",
+		})
+		if err != nil {
+			return exitError("jmap_error", "config written, but listing the inbox failed: "+err.Error(), "")
+		}
+
+		result := types.InitResult{
+			ConfigFile:        configFile,
+			Username:          c.Session().Username,
+			AccountID:         string(c.AccountID()),
+			Keychain:          keychain,
+			CredentialCommand: credCmd,
+			InboxTotal:        inbox.Total,
+		}
+		return formatter().Format(os.Stdout, result)
+	},
+}
+
+func init() {
+	initCmd.Flags().Bool("force", false, "replace an existing config file")
+	rootCmd.AddCommand(initCmd)
+}
+
+// promptCredentials asks for a token and how fm should retrieve it later:
+// from the OS keychain, or from a credential command.
+func promptCredentials(in *bufio.Reader, prompts io.Writer) (token, credCmd string, keychain bool, err error) {
+	_, _ = fmt.Fprint(prompts, "Fastmail API token: ")
+	token, err = readSecret(in)
+	_, _ = fmt.Fprintln(prompts)
+	if err != nil {
+		return "", "", false, exitError("general_error", "cannot read token: "+err.Error(), "")
+	}
+	if token == "" {
+		return "", "", false, exitError("general_error", "no token entered",
+			"Create a token under Settings > Privacy & Security > Integrations > API tokens")
+	}
+
+	if defaultCredentialCommand() != "" {
+		answer, err := prompt(in, prompts, "Store the token in the OS keychain? [Y/n] ")
+		if err != nil {
+			return "", "", false, exitError("general_error", "cannot read answer: "+err.Error(), "")
+		}
+		if answer == "" || strings.HasPrefix(strings.ToLower(answer), "y") {
+			return token, "", true, nil
+		}
+	}
+
+	credCmd, err = prompt(in, prompts, "Credential command that prints the token (e.g. op read op://Private/Fastmail/token): ")
+	if err != nil {
+		return "", "", false, exitError("general_error", "cannot read credential command: "+err.Error(), "")
+	}
+	if credCmd == "" {
+		return "", "", false, exitError("general_error", "a credential command is required without the keychain",
+			"fm never stores the token in its config file")
+	}
+	got, err := runCredentialCommand(credCmd)
+	if err != nil {
+		return "", "", false, exitError("authentication_failed", err.Error(), "Check the credential command")
+	}
+	if got != token {
+		return "", "", false, exitError("authentication_failed", "credential command printed a different token",
+			"Check that the command prints the token you entered")
+	}
+	return token, credCmd, false, nil
+}
+
+// prompt writes question and returns the trimmed line answered.
+func prompt(in *bufio.Reader, prompts io.Writer, question string) (string, error) {
+	_, _ = fmt.Fprint(prompts, question)
+	line, err := in.ReadString('\n')
+	if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// readSecret reads a line without echoing it when stdin is a terminal.
+func readSecret(in *bufio.Reader) (string, error) {
+	if isTerminal(os.Stdin) && runtime.GOOS != "windows" {
+		if err := stty("-echo"); err == nil {
+			defer func() { _ = stty("echo") }()
+		}
+	}
+	line, err := in.ReadString('\n')
+	if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+func stty(arg string) error {
+	c := exec.Command("stty", arg)
+	c.Stdin = os.Stdin
+	return c.Run()
+}
+
+// storeInKeychain saves token where defaultCredentialCommand looks for it.
+func storeInKeychain(token string) error {
+	c, err := keychainCommand(runtime.GOOS, token)
+	if err != nil {
+		return err
+	}
+	if out, err := c.CombinedOutput(); err != nil {
+		if detail := strings.TrimSpace(string(out)); detail != "" {
+			return fmt.Errorf("%w: %s", err, detail)
+		}
+		return err
+	}
+	return nil
+}
+
+// keychainCommand returns the command that stores token in the keychain on
+// goos. The token goes in on stdin, never in the arguments, where other
+// users could see it in the process list.
+func keychainCommand(goos, token string) (*exec.Cmd, error) {
+	switch goos {
+	case "darwin":
+		// With -w last and no value, security prompts for the password
+		// and then again to confirm it, reading both from stdin.
+		c := exec.Command("security", "add-generic-password", "-U", "-s", "fm", "-a", "fastmail", "-w")
+		c.Stdin = strings.NewReader(token + "\n" + token + "\n")
+		return c, nil
+	case "linux":
+		c := exec.Command("secret-tool", "store", "--label", "fm", "service", "fm")
+		c.Stdin = strings.NewReader(token)
+		return c, nil
+	}
+	return nil, fmt.Errorf("no keychain support on %s", goos)
+}
+
+// writeInitConfig writes settings to path as YAML, creating its directory.
+func writeInitConfig(path string, settings map[string]any) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("cannot create config directory: %w", err)
+	}
+	v := viper.New()
+	v.SetConfigType("yaml")
+	for key, value := range settings {
+		v.Set(key, value)
+	}
+	if err := v.WriteConfigAs(path); err != nil {
+		return fmt.Errorf("cannot write %s: %w", path, err)
+	}
+	return nil
+}
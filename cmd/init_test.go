@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+func initTestServer(t *testing.T) *jmapMockServer {
+	t.Helper()
+	return newJMAPMockServer(t,
+		[]map[string]any{{"id": "mb-inbox", "name": "Inbox", "role": "inbox"}},
+		[]map[string]any{{
+			"id":         "M1",
+			"threadId":   "T1",
+			"from":       []map[string]any{{"email": "alice@example.com"}},
+			"subject":    "Welcome",
+			"receivedAt": "2026-02-14T10:30:00Z",
+			"keywords":   map[string]bool{},
+		}},
+		nil,
+	)
+}
+
+func TestInit_WritesConfig(t *testing.T) {
+	server := initTestServer(t)
+
+	args := commandArgsForServer(t, server.server.URL, "init")
+	configPath := args[1]
+
+	_, stderr, err := runCLICommand(t, args)
+	if err == nil || !strings.Contains(stderr, "already exists") {
+		t.Fatalf("expected init to refuse an existing config, got err=%v stderr=%s", err, stderr)
+	}
+
+	stdout, stderr, err := runCLICommand(t, append(args, "--force"))
+	if err != nil {
+		t.Fatalf("init failed: %v\nstderr: %s", err, stderr)
+	}
+
+	var result types.InitResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode result: %v\n%s", err, stdout)
+	}
+	if result.AccountID != "A1" || result.Username != "test@example.com" || result.InboxTotal != 1 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"account_id: A1", "credential_command: echo test-token", "session_url: " + server.server.URL} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected %q in config, got:\n%s", want, data)
+		}
+	}
+}
+
+func TestInit_PromptsForCredentialCommand(t *testing.T) {
+	server := initTestServer(t)
+	configPath := filepath.Join(t.TempDir(), "fm", "config.yaml")
+
+	answers := "test-token\n"
+	if defaultCredentialCommand() != "" {
+		answers += "n\n"
+	}
+	answers += "echo test-token\n"
+	rootCmd.SetIn(strings.NewReader(answers))
+	defer rootCmd.SetIn(nil)
+
+	_, stderr, err := runCLICommand(t, []string{
+		"--config", configPath,
+		"--session-url", server.server.URL + "/session",
+		"--credential-command", "",
+		"init",
+	})
+	if err != nil {
+		t.Fatalf("init failed: %v\nstderr: %s", err, stderr)
+	}
+	if !strings.Contains(stderr, "Fastmail API token:") {
+		t.Errorf("expected token prompt on stderr, got: %s", stderr)
+	}
+	if runtime.GOOS == "linux" && !strings.Contains(stderr, "OS keychain") {
+		t.Errorf("expected keychain offer on stderr, got: %s", stderr)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "credential_command: echo test-token") {
+		t.Errorf("expected credential command in config, got:\n%s", data)
+	}
+}
+
+func TestKeychainCommand_KeepsTokenOutOfArguments(t *testing.T) {
+	for _, goos := range []string{"darwin", "linux"} {
+		c, err := keychainCommand(goos, "secret-token")
+		if err != nil {
+			t.Fatalf("%s: %v", goos, err)
+		}
+		if slices.ContainsFunc(c.Args, func(a string) bool { return strings.Contains(a, "secret-token") }) {
+			t.Errorf("%s: token in arguments %v", goos, c.Args)
+		}
+		stdin, _ := io.ReadAll(c.Stdin)
+		if !strings.HasPrefix(string(stdin), "secret-token") {
+			t.Errorf("%s: expected the token on stdin, got %q", goos, stdin)
+		}
+	}
+	if _, err := keychainCommand("plan9", "secret-token"); err == nil {
+		t.Error("expected an error without keychain support")
+	}
+}
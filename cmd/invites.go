@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"os"
+	"time"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/mailbox"
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+var invitesCmd = &cobra.Command{
+	Use:   "invites",
+	Short: "List calendar invitations and your response to each",
+	Long: `List the calendar invitations among the 500 most recent emails in a mailbox
+that name you as an attendee, soonest first, with your response to each:
+needs-action, accepted, declined, tentative, or delegated. Your addresses are
+the account username and your sending identities.
+
+With --pending, only the invitations you have not responded to are listed,
+so meeting requests do not get lost in mail triage. An invitation counts as
+answered when a calendar reply to it is in the Sent mailbox, or, shown as
+replied, when its email is marked as answered.
+
+Of several emails about the same event, the latest version counts, and
+events cancelled by a later email are left out. Events that have ended are
+left out unless --include-past is given.`,
+	Example: `  fm invites --pending
+  fm invites --mailbox Calendar --include-past`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mailboxName, _ := cmd.Flags().GetString("mailbox")
+		pending, _ := cmd.Flags().GetBool("pending")
+		includePast, _ := cmd.Flags().GetBool("include-past")
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your credential command or the token it returns")
+		}
+
+		mb, err := c.ResolveMailbox(mailboxName)
+		if err != nil {
+			return exitError("not_found", err.Error(), mailboxHint(err))
+		}
+		var sentID jmap.ID
+		if sent, err := c.GetMailboxByRole(mailbox.RoleSent); err == nil {
+			sentID = sent.ID
+		}
+
+		invitations, err := c.Invitations(mb.ID, sentID)
+		if err != nil {
+			return exitError("jmap_error", err.Error(), "")
+		}
+
+		now := time.Now()
+		result := types.InvitesResult{Mailbox: mb.Name, Pending: pending, Invitations: []types.Invitation{}}
+		for _, inv := range invitations {
+			if pending && inv.Status != "needs-action" {
+				continue
+			}
+			if !includePast && invitationEnded(inv, now) {
+				continue
+			}
+			result.Invitations = append(result.Invitations, inv)
+		}
+		result.Total = len(result.Invitations)
+		return formatter().Format(os.Stdout, result)
+	},
+}
+
+// invitationEnded reports whether the event of inv is over by now. An
+// all-day event without an end lasts its whole day.
+func invitationEnded(inv types.Invitation, now time.Time) bool {
+	end := inv.Start
+	switch {
+	case inv.End != nil:
+		end = *inv.End
+	case inv.AllDay:
+		end = inv.Start.AddDate(0, 0, 1)
+	}
+	return !end.After(now)
+}
+
+func init() {
+	invitesCmd.Flags().StringP("mailbox", "m", "inbox", "mailbox to look for invitations in")
+	invitesCmd.Flags().Bool("pending", false, "only list invitations you have not responded to")
+	invitesCmd.Flags().Bool("include-past", false, "include events that have ended")
+	rootCmd.AddCommand(invitesCmd)
+}
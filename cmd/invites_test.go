@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+// calendarData is an iMIP message for one event of uid, starting at start,
+// with test@example.com, the mock server's user, attending with partStat.
+func calendarData(method, uid, start, partStat string) string {
+	return "BEGIN:VCALENDAR\r\nMETHOD:" + method + "\r\nBEGIN:VEVENT\r\n" +
+		"UID:" + uid + "\r\nSUMMARY:Event " + uid + "\r\nDTSTART:" + start + "\r\n" +
+		"ORGANIZER;CN=Alice:mailto:alice@example.com\r\n" +
+		"ATTENDEE;PARTSTAT=" + partStat + ":mailto:test@example.com\r\n" +
+		"END:VEVENT\r\nEND:VCALENDAR\r\n"
+}
+
+func TestInvites_PendingListsUnansweredInvitations(t *testing.T) {
+	invite := func(id string) map[string]any {
+		return map[string]any{
+			"id":         id,
+			"threadId":   "T-" + id,
+			"subject":    "Invitation " + id,
+			"receivedAt": "2026-02-04T10:30:00Z",
+			"attachments": []map[string]any{
+				{"partId": "2", "blobId": "B-" + id, "name": "invite.ics", "type": "text/calendar; method=REQUEST", "size": 300},
+			},
+		}
+	}
+	server := newJMAPMockServer(t,
+		[]map[string]any{
+			{"id": "mb-inbox", "name": "Inbox", "role": "inbox"},
+			{"id": "mb-sent", "name": "Sent", "role": "sent"},
+		},
+		[]map[string]any{invite("M1"), invite("M2"), invite("M3"), invite("M4"), invite("M5"), invite("M6"), invite("M7"), invite("M8")},
+		nil,
+	)
+	server.blobs = map[string]string{
+		"B-M1": calendarData("REQUEST", "later", "20990302T090000Z", "NEEDS-ACTION"),
+		"B-M2": calendarData("REQUEST", "sooner", "20990301T090000Z", "NEEDS-ACTION"),
+		"B-M3": calendarData("REQUEST", "accepted", "20990303T090000Z", "ACCEPTED"),
+		"B-M4": calendarData("REQUEST", "cancelled", "20990304T090000Z", "NEEDS-ACTION"),
+		"B-M5": calendarData("CANCEL", "cancelled", "20990304T090000Z", "NEEDS-ACTION"),
+		"B-M6": calendarData("REQUEST", "past", "20200101T090000Z", "NEEDS-ACTION"),
+		"B-M7": calendarData("REPLY", "replied", "20990305T090000Z", "DECLINED"),
+		"B-M8": calendarData("REQUEST", "replied", "20990305T090000Z", "NEEDS-ACTION"),
+	}
+
+	stdout, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "invites", "--pending"))
+	if err != nil {
+		t.Fatalf("invites failed: %v\n%s", err, stderr)
+	}
+
+	var result types.InvitesResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, stdout)
+	}
+	if result.Mailbox != "Inbox" || !result.Pending || result.Total != 2 || len(result.Invitations) != 2 {
+		t.Fatalf("expected two pending invitations in Inbox, got %+v", result)
+	}
+	if got := result.Invitations[0]; got.UID != "sooner" || got.EmailID != "M2" || got.Summary != "Event sooner" || got.Organizer.Email != "alice@example.com" {
+		t.Errorf("expected the soonest invitation first, got %+v", got)
+	}
+	if got := result.Invitations[1]; got.UID != "later" {
+		t.Errorf("expected the later invitation second, got %+v", got)
+	}
+
+	stdout, stderr, err = runCLICommand(t, commandArgsForServer(t, server.server.URL, "invites", "--include-past"))
+	if err != nil {
+		t.Fatalf("invites failed: %v\n%s", err, stderr)
+	}
+	result = types.InvitesResult{}
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, stdout)
+	}
+	statuses := map[string]string{}
+	for _, inv := range result.Invitations {
+		statuses[inv.UID] = inv.Status
+	}
+	want := map[string]string{
+		"past": "needs-action", "sooner": "needs-action", "later": "needs-action",
+		"accepted": "accepted", "replied": "declined",
+	}
+	if len(statuses) != len(want) {
+		t.Errorf("expected invitations %v, got %v", want, statuses)
+	}
+	for uid, status := range want {
+		if statuses[uid] != status {
+			t.Errorf("expected %s to be %s, got %q", uid, status, statuses[uid])
+		}
+	}
+}
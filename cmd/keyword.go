@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/client"
+	"github.com/cboone/fm/internal/types"
+)
+
+var keywordCmd = &cobra.Command{
+	Use:   "keyword",
+	Short: "Set, clear, and inspect email keywords",
+	Long: `Work with JMAP keywords directly. Keywords are the per-email tags behind
+read state ($seen), flags ($flagged), mutes ($muted), and Fastmail labels.`,
+}
+
+// runKeyword sets or clears the keyword named by args[0] on the emails given
+// by the remaining args or by filter flags.
+func runKeyword(cmd *cobra.Command, args []string, set bool) error {
+	kw, ids := args[0], args[1:]
+	if err := client.ValidateKeyword(kw); err != nil {
+		return exitError("general_error", err.Error(),
+			"Keywords are 1-255 printable ASCII characters without spaces or ( ) { ] % * \" \\")
+	}
+	if err := validateIDsOrFilters(cmd, ids); err != nil {
+		return err
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return exitError("authentication_failed", err.Error(),
+			"Check your credential command or the token it returns")
+	}
+
+	ids, err = resolveEmailIDs(cmd, ids, c)
+	if err != nil {
+		return err
+	}
+
+	action := "keyword clear"
+	if set {
+		action = "keyword set"
+	}
+
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	if dryRun {
+		return dryRunPreview(c, ids, action, nil)
+	}
+
+	c.SetProgress(progressPrinter("Updating keywords"))
+	var succeeded, errors []string
+	if set {
+		succeeded, errors = c.SetKeyword(ids, kw)
+	} else {
+		succeeded, errors = c.ClearKeyword(ids, kw)
+	}
+
+	result := types.MoveResult{
+		Matched:   len(ids),
+		Processed: len(succeeded) + len(errors),
+		Failed:    len(errors),
+		Keyword:   kw,
+		Errors:    errors,
+	}
+	if set {
+		result.KeywordSet = succeeded
+	} else {
+		result.KeywordClear = succeeded
+	}
+
+	if err := formatter().Format(os.Stdout, result); err != nil {
+		return err
+	}
+
+	if len(errors) > 0 {
+		return exitError("partial_failure", "one or more emails failed to update", retryHint(cmd, errors, kw))
+	}
+
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(keywordCmd)
+}
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var keywordClearCmd = &cobra.Command{
+	Use:               "clear <keyword> [email-id...]",
+	Short:             "Remove a keyword from emails",
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeKeywordEmailIDs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runKeyword(cmd, args, false)
+	},
+}
+
+func init() {
+	keywordClearCmd.Flags().BoolP("dry-run", "n", false, "preview affected emails without making changes")
+	addVerboseFlag(keywordClearCmd)
+	addIDsFileFlag(keywordClearCmd)
+	addFilterFlags(keywordClearCmd)
+	addFromLastFlag(keywordClearCmd)
+	keywordCmd.AddCommand(keywordClearCmd)
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+var keywordListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List keywords in use and how many emails carry each",
+	Long: `Scan every email in a mailbox (or the whole account) and report each
+keyword in use with the number of emails carrying it, most common first.
+Useful for finding orphaned labels that no longer show up in Fastmail's UI.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mailboxName, _ := cmd.Flags().GetString("mailbox")
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your credential command or the token it returns")
+		}
+
+		var mailboxID string
+		if mailboxName != "" {
+			id, err := c.ResolveMailboxID(mailboxName)
+			if err != nil {
+				return exitError("not_found", err.Error(), mailboxHint(err))
+			}
+			mailboxID = string(id)
+		}
+
+		counts, scanned, err := c.CountKeywords(mailboxID)
+		if err != nil {
+			return exitError("jmap_error", err.Error(), "")
+		}
+
+		result := types.KeywordListResult{
+			Mailbox:  mailboxName,
+			Scanned:  scanned,
+			Keywords: []types.KeywordCount{},
+		}
+		for kw, n := range counts {
+			result.Keywords = append(result.Keywords, types.KeywordCount{Keyword: kw, Count: n})
+		}
+		sort.Slice(result.Keywords, func(i, j int) bool {
+			if result.Keywords[i].Count != result.Keywords[j].Count {
+				return result.Keywords[i].Count > result.Keywords[j].Count
+			}
+			return result.Keywords[i].Keyword < result.Keywords[j].Keyword
+		})
+
+		return formatter().Format(os.Stdout, result)
+	},
+}
+
+func init() {
+	keywordListCmd.Flags().StringP("mailbox", "m", "", "only scan this mailbox (default: all mail)")
+	keywordCmd.AddCommand(keywordListCmd)
+}
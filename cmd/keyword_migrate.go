@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/client"
+	"github.com/cboone/fm/internal/types"
+)
+
+var keywordMigrateCmd = &cobra.Command{
+	Use:   "migrate --from <keyword> --to <keyword>",
+	Short: "Replace one keyword with another on every email that has it",
+	Long: `Replace the keyword given by --from with the one given by --to on every email
+that has it, for renaming a label across more mail than Fastmail's UI
+handles well. Each email loses the old keyword and gains the new one in the
+same update, in chunks of the server's maxObjectsInSet.
+
+Filter flags narrow the emails to those matching; without them every email
+with the keyword is migrated, except those in Trash and Junk.
+
+  fm keyword migrate --from clients-acme --to acme --dry-run
+  fm keyword migrate --from clients-acme --to acme --mailbox archive`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		from, _ := cmd.Flags().GetString("from")
+		to, _ := cmd.Flags().GetString("to")
+		if from == "" || to == "" {
+			return exitError("general_error", "--from and --to are required",
+				"Name the keyword to replace and the one to set, e.g. --from old --to new")
+		}
+		for _, kw := range []string{from, to} {
+			if err := client.ValidateKeyword(kw); err != nil {
+				return exitError("general_error", err.Error(),
+					"Keywords are 1-255 printable ASCII characters without spaces or ( ) { ] % * \" \\")
+			}
+		}
+		if strings.EqualFold(from, to) {
+			return exitError("general_error", "--from and --to name the same keyword",
+				"Servers compare keywords case-insensitively")
+		}
+		if idsFile(cmd) != "" && hasFilterFlags(cmd) {
+			return exitError("general_error", "cannot combine --ids-file with filter flags",
+				"Use --ids-file on its own to act on the emails listed in the file")
+		}
+		if err := checkExclusiveFilters(cmd); err != nil {
+			return err
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your credential command or the token it returns")
+		}
+
+		var ids []string
+		if path := idsFile(cmd); path != "" {
+			ids, err = readIDsFile(path)
+			if err != nil {
+				return err
+			}
+		} else {
+			opts, err := parseFilterOptions(cmd, c)
+			if err != nil {
+				return err
+			}
+			if err := excludeTrashAndJunk(cmd, c, &opts); err != nil {
+				return err
+			}
+			opts.Keyword = from
+			ids, err = c.QueryEmailIDs(opts)
+			if err != nil {
+				return exitError("jmap_error", err.Error(), "")
+			}
+			if len(ids) == 0 {
+				return exitError("not_found", "no emails have keyword "+from, "")
+			}
+		}
+
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		if dryRun {
+			return dryRunPreview(c, ids, "keyword migrate", nil)
+		}
+
+		c.SetProgress(progressPrinter("Migrating keywords"))
+		outcomes := recordOutcomes(cmd, c)
+		succeeded, errors := c.MigrateKeyword(ids, from, to)
+
+		result := types.MoveResult{
+			Matched:         len(ids),
+			Processed:       len(succeeded) + len(errors),
+			Failed:          len(errors),
+			Keyword:         from,
+			NewKeyword:      to,
+			KeywordMigrated: succeeded,
+			Errors:          errors,
+			Results:         outcomes.results(succeeded, errors),
+		}
+
+		if err := formatter().Format(os.Stdout, result); err != nil {
+			return err
+		}
+
+		if len(errors) > 0 {
+			return exitError("partial_failure", "one or more emails failed to update", retryHint(cmd, errors))
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	keywordMigrateCmd.Flags().String("from", "", "keyword to replace (required)")
+	keywordMigrateCmd.Flags().String("to", "", "keyword to set in its place (required)")
+	keywordMigrateCmd.Flags().BoolP("dry-run", "n", false, "preview affected emails without making changes")
+	addVerboseFlag(keywordMigrateCmd)
+	addIDsFileFlag(keywordMigrateCmd)
+	addFilterFlags(keywordMigrateCmd)
+	keywordCmd.AddCommand(keywordMigrateCmd)
+}
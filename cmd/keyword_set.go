@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var keywordSetCmd = &cobra.Command{
+	Use:               "set <keyword> [email-id...]",
+	Short:             "Set a keyword on emails",
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeKeywordEmailIDs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runKeyword(cmd, args, true)
+	},
+}
+
+func init() {
+	keywordSetCmd.Flags().BoolP("dry-run", "n", false, "preview affected emails without making changes")
+	addVerboseFlag(keywordSetCmd)
+	addIDsFileFlag(keywordSetCmd)
+	addFilterFlags(keywordSetCmd)
+	addFromLastFlag(keywordSetCmd)
+	keywordCmd.AddCommand(keywordSetCmd)
+}
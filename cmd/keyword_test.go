@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestKeywordSet_UpdatesEmails(t *testing.T) {
+	server := newJMAPMockServer(t,
+		nil,
+		[]map[string]any{{
+			"id":         "M1",
+			"threadId":   "T1",
+			"subject":    "Keyword test",
+			"receivedAt": "2026-02-14T10:30:00Z",
+			"keywords":   map[string]bool{},
+		}},
+		nil,
+	)
+
+	args := commandArgsForServer(t, server.server.URL, "keyword", "set", "project-x", "M1")
+	stdout, stderr, err := runCLICommand(t, args)
+	if err != nil {
+		t.Fatalf("expected success, got: %v\nstderr=%s", err, stderr)
+	}
+	if !strings.Contains(stdout, `"keyword": "project-x"`) || !strings.Contains(stdout, `"keyword_set"`) {
+		t.Fatalf("expected keyword_set output, got: %s", stdout)
+	}
+	if server.count("Email/set") != 1 {
+		t.Fatalf("expected Email/set once, got %d", server.count("Email/set"))
+	}
+}
+
+func TestKeywordSet_RejectsInvalidKeyword(t *testing.T) {
+	server := newJMAPMockServer(t, nil, nil, nil)
+
+	args := commandArgsForServer(t, server.server.URL, "keyword", "clear", "two words", "M1")
+	_, stderr, err := runCLICommand(t, args)
+	if err == nil {
+		t.Fatal("expected an error for a keyword with a space")
+	}
+	if !strings.Contains(stderr, "general_error") {
+		t.Fatalf("expected general_error, got: %s", stderr)
+	}
+	if server.count("Email/set") != 0 {
+		t.Fatalf("expected Email/set not to be called, got %d", server.count("Email/set"))
+	}
+}
+
+func TestKeywordList_CountsKeywords(t *testing.T) {
+	server := newJMAPMockServer(t,
+		nil,
+		[]map[string]any{
+			{"id": "M1", "threadId": "T1", "receivedAt": "2026-02-14T10:30:00Z",
+				"keywords": map[string]bool{"$seen": true, "project-x": true}},
+			{"id": "M2", "threadId": "T2", "receivedAt": "2026-02-14T11:30:00Z",
+				"keywords": map[string]bool{"$seen": true}},
+		},
+		nil,
+	)
+
+	args := commandArgsForServer(t, server.server.URL, "keyword", "list")
+	stdout, stderr, err := runCLICommand(t, args)
+	if err != nil {
+		t.Fatalf("expected success, got: %v\nstderr=%s", err, stderr)
+	}
+
+	var result struct {
+		Scanned  int `json:"scanned"`
+		Keywords []struct {
+			Keyword string `json:"keyword"`
+			Count   int    `json:"count"`
+		} `json:"keywords"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout)
+	}
+	if result.Scanned != 2 || len(result.Keywords) != 2 {
+		t.Fatalf("expected 2 scanned and 2 keywords, got %+v", result)
+	}
+	if result.Keywords[0].Keyword != "$seen" || result.Keywords[0].Count != 2 {
+		t.Errorf("expected $seen (2) first, got %+v", result.Keywords[0])
+	}
+}
+
+func TestKeywordMigrate_ReplacesKeyword(t *testing.T) {
+	server := newJMAPMockServer(t,
+		nil,
+		[]map[string]any{
+			{"id": "M1", "keywords": map[string]bool{"clients-acme": true}},
+			{"id": "M2", "keywords": map[string]bool{"clients-acme": true}},
+		},
+		nil,
+	)
+
+	args := commandArgsForServer(t, server.server.URL, "keyword", "migrate", "--from", "clients-acme", "--to", "acme")
+	stdout, stderr, err := runCLICommand(t, args)
+	if err != nil {
+		t.Fatalf("expected success, got: %v\nstderr=%s", err, stderr)
+	}
+
+	var result struct {
+		Keyword         string   `json:"keyword"`
+		NewKeyword      string   `json:"new_keyword"`
+		KeywordMigrated []string `json:"keyword_migrated"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, stdout)
+	}
+	if result.Keyword != "clients-acme" || result.NewKeyword != "acme" || len(result.KeywordMigrated) != 2 {
+		t.Errorf("expected both emails migrated from clients-acme to acme, got %+v", result)
+	}
+	if server.count("Email/set") != 1 {
+		t.Errorf("expected Email/set once, got %d", server.count("Email/set"))
+	}
+}
+
+func TestKeywordMigrate_RejectsSameKeyword(t *testing.T) {
+	server := newJMAPMockServer(t, nil, nil, nil)
+
+	args := commandArgsForServer(t, server.server.URL, "keyword", "migrate", "--from", "Acme", "--to", "acme")
+	_, stderr, err := runCLICommand(t, args)
+	if err == nil || !strings.Contains(stderr, "--from and --to name the same keyword") {
+		t.Fatalf("expected a same-keyword error, got: %v\nstderr=%s", err, stderr)
+	}
+	if server.count("Email/set") != 0 {
+		t.Error("expected no Email/set")
+	}
+}
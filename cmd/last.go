@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var lastCmd = &cobra.Command{
+	Use:   "last",
+	Short: "Re-emit the most recent list or search result",
+	Long: `Re-emit the result set from the most recent list or search without
+contacting the server. Use --quiet to print only the email IDs, one per line.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		last, err := loadLastResult()
+		if err != nil {
+			return err
+		}
+
+		quiet, _ := cmd.Flags().GetBool("quiet")
+		if quiet {
+			for _, id := range last.IDs() {
+				fmt.Fprintln(os.Stdout, id)
+			}
+			return nil
+		}
+
+		return formatter().Format(os.Stdout, last.Result)
+	},
+}
+
+func init() {
+	lastCmd.Flags().BoolP("quiet", "q", false, "print only email IDs, one per line")
+	rootCmd.AddCommand(lastCmd)
+}
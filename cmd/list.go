@@ -1,18 +1,49 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 
+	"git.sr.ht/~rockorager/go-jmap/mail/mailbox"
 	"github.com/spf13/cobra"
 
 	"github.com/cboone/fm/internal/client"
+	"github.com/cboone/fm/internal/notification"
+	"github.com/cboone/fm/internal/receipt"
+	"github.com/cboone/fm/internal/types"
 )
 
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List emails in a mailbox",
+	Long: `List emails in a mailbox, newest first.
+
+With --drafts, the unsent drafts in the Drafts mailbox are listed with their
+recipients. fm read shows a draft with its attachments; fm never sends one.
+
+With --since-last-run <name>, only emails newer than those returned by the
+previous run with the same cursor name are listed, oldest first, and the
+cursor is saved for the next run, so a cron job sees each email once. The
+first run lists the newest emails and starts the cursor there.
+
+With --changed-since <state>, only emails created or updated since an Email
+state string are listed, along with the IDs of emails destroyed since, and
+the new state to pass next time. fm state prints the current state. Every
+change is listed, since the new state covers them all, so --limit does not
+apply.
+
+With --parse notifications, each GitHub or GitLab notification email gets a
+notification field with its provider, repository, kind of item (issue,
+pull_request, merge_request, ...), number, and reason, read from headers
+such as X-GitHub-Reason, List-Id, and X-GitLab-Project-Path.
+
+With --parse receipts, each email whose HTML body carries schema.org Order
+or Invoice data, as many receipts and order confirmations do, gets a receipt
+field with the merchant, total, currency, and order ID. Give both modes
+separated by a comma to get both fields.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		mailboxName, _ := cmd.Flags().GetString("mailbox")
 		limit, _ := cmd.Flags().GetUint64("limit")
@@ -26,6 +57,8 @@ var listCmd = &cobra.Command{
 		unread, _ := cmd.Flags().GetBool("unread")
 		flagged, _ := cmd.Flags().GetBool("flagged")
 		unflagged, _ := cmd.Flags().GetBool("unflagged")
+		forwarded, _ := cmd.Flags().GetBool("forwarded")
+		noDrafts, _ := cmd.Flags().GetBool("no-drafts")
 		if flagged && unflagged {
 			return exitError("general_error", "--flagged and --unflagged are mutually exclusive", "")
 		}
@@ -37,6 +70,46 @@ var listCmd = &cobra.Command{
 		}
 
 		subject, _ := cmd.Flags().GetString("subject")
+		snoozed, _ := cmd.Flags().GetBool("snoozed")
+		includeMuted, _ := cmd.Flags().GetBool("include-muted")
+		if snoozed && cmd.Flags().Changed("mailbox") {
+			return exitError("general_error", "cannot combine --snoozed with --mailbox",
+				"--snoozed always lists the Snoozed mailbox")
+		}
+		drafts, _ := cmd.Flags().GetBool("drafts")
+		if drafts {
+			for _, name := range []string{"mailbox", "snoozed", "no-drafts"} {
+				if cmd.Flags().Changed(name) {
+					return exitError("general_error", "cannot combine --drafts with --"+name,
+						"--drafts always lists the Drafts mailbox")
+				}
+			}
+		}
+
+		if err := validateParse(cmd); err != nil {
+			return err
+		}
+
+		job, err := loadSinceLastRun(cmd)
+		if err != nil {
+			return err
+		}
+		if job != nil && snoozed {
+			return exitError("general_error", "cannot combine --since-last-run with --snoozed", "")
+		}
+
+		changedSince, _ := cmd.Flags().GetString("changed-since")
+		if cmd.Flags().Changed("changed-since") {
+			if changedSince == "" {
+				return exitError("general_error", "--changed-since needs a state string",
+					"Run fm state to get the current Email state")
+			}
+			for _, name := range []string{"since-last-run", "snoozed", "subject", "limit", "offset", "sort"} {
+				if cmd.Flags().Changed(name) {
+					return exitError("general_error", "cannot combine --changed-since with --"+name, "")
+				}
+			}
+		}
 
 		c, err := newClient()
 		if err != nil {
@@ -44,7 +117,16 @@ var listCmd = &cobra.Command{
 				"Check your credential command or the token it returns")
 		}
 
-		result, err := c.ListEmails(client.ListOptions{
+		if drafts {
+			mb, err := c.GetMailboxByRole(mailbox.RoleDrafts)
+			if err != nil {
+				return exitError("not_found", "no drafts mailbox found",
+					"Run fm mailboxes --roles-only to see the mailboxes with roles")
+			}
+			mailboxName = string(mb.ID)
+		}
+
+		opts := client.ListOptions{
 			MailboxNameOrID: mailboxName,
 			Subject:         subject,
 			Limit:           limit,
@@ -52,14 +134,67 @@ var listCmd = &cobra.Command{
 			UnreadOnly:      unread,
 			FlaggedOnly:     flagged,
 			UnflaggedOnly:   unflagged,
+			ForwardedOnly:   forwarded,
+			ExcludeDrafts:   noDrafts,
+			ExcludeMuted:    !includeMuted,
 			SortField:       sortField,
 			SortAsc:         sortAsc,
-		})
+		}
+		if job != nil {
+			var oldestFirst bool
+			if opts.After, oldestFirst = job.after(nil); oldestFirst {
+				opts.SortField, opts.SortAsc = "receivedAt", true
+			}
+		}
+
+		var result types.EmailListResult
+		if changedSince != "" {
+			result, err = c.ListEmailChanges(opts, changedSince)
+			if errors.Is(err, client.ErrCannotCalculateChanges) {
+				return exitError("jmap_error", err.Error(),
+					"The state is too old; run fm state for a fresh one and list the mailbox in full")
+			}
+		} else if snoozed {
+			if _, err := c.GetMailboxByRole(client.RoleSnoozed); err != nil {
+				return exitError("not_found", "no snoozed mailbox found",
+					"Snoozing is a Fastmail feature; the mailbox appears after the first email is snoozed")
+			}
+			result, err = c.ListSnoozedEmails(opts)
+		} else {
+			result, err = c.ListEmails(opts)
+		}
+		if errors.Is(err, client.ErrMailboxNotFound) {
+			return exitError("not_found", err.Error(), mailboxHint(err))
+		}
 		if err != nil {
+			return exitError("jmap_error", err.Error(), unsupportedHint(err))
+		}
+		if changedSince == "" && !snoozed {
+			warnWindowed(c, "--limit", len(result.Emails), result.Total)
+		}
+
+		result.Highlight = strings.Fields(opts.Subject)
+		if !snoozed {
+			// The mailboxes are cached by now, so this sends no request.
+			if mb, err := c.ResolveMailbox(mailboxName); err == nil {
+				result.ShowRecipients = mb.Role == mailbox.RoleSent || mb.Role == mailbox.RoleDrafts
+			}
+		}
+		if job != nil {
+			job.filter(&result)
+		}
+		if err := parseListResult(cmd, c, &result); err != nil {
 			return exitError("jmap_error", err.Error(), "")
 		}
 
-		return formatter().Format(os.Stdout, result)
+		rememberResult("list", result)
+		if err := formatter().Format(os.Stdout, result); err != nil {
+			return err
+		}
+		if job != nil {
+			return job.save()
+		}
+		return nil
 	},
 }
 
@@ -71,10 +206,135 @@ func init() {
 	listCmd.Flags().BoolP("flagged", "f", false, "only show flagged messages")
 	listCmd.Flags().Bool("unflagged", false, "only show unflagged messages")
 	listCmd.Flags().String("subject", "", "filter by subject text")
+	listCmd.Flags().Bool("snoozed", false, "list snoozed emails with their wake-up times")
+	listCmd.Flags().Bool("forwarded", false, "only show forwarded messages")
+	listCmd.Flags().Bool("no-drafts", false, "leave out drafts")
+	listCmd.Flags().Bool("drafts", false, "list unsent drafts from the Drafts mailbox")
+	listCmd.Flags().Bool("include-muted", false, "include threads muted with fm mute")
+	addSinceLastRunFlag(listCmd)
+	listCmd.Flags().String("changed-since", "", "only list emails created or updated since this Email state")
 	listCmd.Flags().StringP("sort", "s", "receivedAt desc", "sort order (receivedAt, sentAt, from, subject) with asc/desc")
+	listCmd.Flags().String("parse", "", "add fields read from each email: notifications, receipts, or both comma-separated")
 	rootCmd.AddCommand(listCmd)
 }
 
+// parseModes are the values --parse of list and search accepts.
+var parseModes = []string{"notifications", "receipts"}
+
+// parseModesOf returns the modes given to --parse, which takes several
+// separated by commas.
+func parseModesOf(cmd *cobra.Command) map[string]bool {
+	value, _ := cmd.Flags().GetString("parse")
+	modes := make(map[string]bool)
+	for _, m := range strings.Split(value, ",") {
+		if m = strings.TrimSpace(m); m != "" {
+			modes[m] = true
+		}
+	}
+	return modes
+}
+
+// validateParse checks the --parse modes of list and search.
+func validateParse(cmd *cobra.Command) error {
+	for mode := range parseModesOf(cmd) {
+		if !slices.Contains(parseModes, mode) {
+			return exitError("general_error", fmt.Sprintf("unknown --parse mode %q", mode),
+				"Use --parse "+strings.Join(parseModes, ", or --parse "))
+		}
+	}
+	return nil
+}
+
+// warnWindowed warns when the fetched emails, up to the limit set by flag,
+// took several requests because there were more than the server's
+// maxObjectsInGet, with how many were fetched of how many match. A large
+// limit that few emails match fits in one request and is not warned about.
+func warnWindowed(c *client.Client, flag string, fetched int, total uint64) {
+	size := c.MaxObjectsInGet()
+	if size == 0 || fetched <= size {
+		return
+	}
+	warn("large_result",
+		fmt.Sprintf("fetched %d of %d matching emails in windows of %d, the server's maxObjectsInGet", fetched, total, size),
+		"Narrow the filters or lower "+flag+" to fetch fewer emails at once")
+}
+
+// parseListResult adds the fields --parse asks for to the emails of
+// result.
+func parseListResult(cmd *cobra.Command, c *client.Client, result *types.EmailListResult) error {
+	modes := parseModesOf(cmd)
+	if len(modes) == 0 || len(result.Emails) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(result.Emails))
+	for i, e := range result.Emails {
+		ids[i] = e.ID
+	}
+	if modes["notifications"] {
+		if err := parseNotifications(c, ids, result); err != nil {
+			return err
+		}
+	}
+	if modes["receipts"] {
+		if err := parseReceipts(c, ids, result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseReceipts sets the receipt of each of result's emails whose HTML
+// body carries schema.org order data.
+func parseReceipts(c *client.Client, ids []string, result *types.EmailListResult) error {
+	bodies, err := c.GetHTMLBodies(ids)
+	if err != nil {
+		return err
+	}
+	for i, e := range result.Emails {
+		if r, ok := receipt.Parse(bodies[e.ID]); ok {
+			result.Emails[i].Receipt = &types.Receipt{
+				Merchant:  r.Merchant,
+				OrderID:   r.OrderID,
+				Total:     r.Total,
+				Currency:  r.Currency,
+				OrderDate: r.OrderDate,
+			}
+		}
+	}
+	return nil
+}
+
+// parseNotifications sets the notification of each of result's emails
+// that GitHub or GitLab sent, reading their headers.
+func parseNotifications(c *client.Client, ids []string, result *types.EmailListResult) error {
+	emails, _, err := c.GetEmailHeaders(ids)
+	if err != nil {
+		return err
+	}
+
+	found := make(map[string]*types.Notification)
+	for _, e := range emails {
+		headers := make([]notification.Header, len(e.Headers))
+		for i, h := range e.Headers {
+			headers[i] = notification.Header{Name: h.Name, Value: h.Value}
+		}
+		if n, ok := notification.Parse(headers); ok {
+			found[e.Summary.ID] = &types.Notification{
+				Provider: n.Provider,
+				Repo:     n.Repo,
+				Kind:     n.Kind,
+				Number:   n.Number,
+				Reason:   n.Reason,
+			}
+		}
+	}
+	for i := range result.Emails {
+		result.Emails[i].Notification = found[result.Emails[i].ID]
+	}
+	return nil
+}
+
 var validSortFields = map[string]string{
 	"receivedat": "receivedAt",
 	"sentat":     "sentAt",
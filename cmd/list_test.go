@@ -1,6 +1,15 @@
 package cmd
 
-import "testing"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cboone/fm/internal/types"
+)
 
 func TestParseSort_Default(t *testing.T) {
 	field, asc, err := parseSort("receivedAt desc")
@@ -145,3 +154,431 @@ func TestSearchCmd_FlaggedAndUnflaggedMutuallyExclusive(t *testing.T) {
 		t.Fatal("expected error when both --flagged and --unflagged are set")
 	}
 }
+
+func TestList_SnoozedShowsWakeUpTimes(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", "")
+
+	server := newJMAPMockServer(t,
+		[]map[string]any{
+			{"id": "mb-inbox", "name": "Inbox", "role": "inbox"},
+			{"id": "mb-snoozed", "name": "Snoozed", "role": "snoozed", "totalEmails": 1},
+		},
+		[]map[string]any{{
+			"id": "M1", "threadId": "T1", "subject": "Later", "receivedAt": "2026-02-14T10:30:00Z",
+			"keywords": map[string]bool{},
+			"snoozed":  map[string]any{"until": "2026-02-20T09:00:00Z"},
+		}},
+		nil,
+	)
+
+	stdout, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "list", "--snoozed"))
+	if err != nil {
+		t.Fatalf("expected success, got: %v\nstderr=%s", err, stderr)
+	}
+	if !strings.Contains(stdout, `"snoozed_until": "2026-02-20T09:00:00Z"`) {
+		t.Fatalf("expected wake-up time in output, got: %s", stdout)
+	}
+}
+
+func TestList_SnoozedRejectsMailbox(t *testing.T) {
+	_, stderr, err := runCLICommand(t, []string{"list", "--snoozed", "--mailbox", "Archive"})
+	if err == nil {
+		t.Fatal("expected error when combining --snoozed and --mailbox")
+	}
+	if !strings.Contains(stderr, "cannot combine --snoozed with --mailbox") {
+		t.Fatalf("expected combination error, got: %s", stderr)
+	}
+}
+
+func TestList_SinceLastRun(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", "")
+
+	server := newJMAPMockServer(t,
+		[]map[string]any{{"id": "mb-inbox", "name": "Inbox", "role": "inbox"}},
+		[]map[string]any{
+			{"id": "M1", "threadId": "T1", "subject": "First", "receivedAt": "2026-02-14T10:30:00Z", "keywords": map[string]bool{}},
+			{"id": "M2", "threadId": "T2", "subject": "Second", "receivedAt": "2026-02-14T09:30:00Z", "keywords": map[string]bool{}},
+		},
+		nil,
+	)
+
+	// The mock server ignores the after filter, so every run sees every
+	// email and the cursor alone decides what is new.
+	run := func() []string {
+		t.Helper()
+		stdout, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "list", "--since-last-run", "job"))
+		if err != nil {
+			t.Fatalf("list failed: %v\nstderr=%s", err, stderr)
+		}
+		var result types.EmailListResult
+		if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+			t.Fatalf("decode output: %v\n%s", err, stdout)
+		}
+		var ids []string
+		for _, e := range result.Emails {
+			ids = append(ids, e.ID)
+		}
+		return ids
+	}
+
+	if got := run(); len(got) != 2 {
+		t.Fatalf("expected the first run to list both emails, got %v", got)
+	}
+	if got := run(); len(got) != 0 {
+		t.Fatalf("expected the second run to list nothing, got %v", got)
+	}
+
+	server.emails = append(server.emails, map[string]any{
+		"id": "M3", "threadId": "T3", "subject": "Third", "receivedAt": "2026-02-14T11:00:00Z", "keywords": map[string]bool{},
+	})
+	if got := run(); len(got) != 1 || got[0] != "M3" {
+		t.Fatalf("expected only the new email, got %v", got)
+	}
+}
+
+func TestList_ChangedSince(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", "")
+
+	server := newJMAPMockServer(t,
+		[]map[string]any{
+			{"id": "mb-inbox", "name": "Inbox", "role": "inbox"},
+			{"id": "mb-archive", "name": "Archive", "role": "archive"},
+		},
+		[]map[string]any{
+			{"id": "M1", "threadId": "T1", "subject": "Older", "receivedAt": "2026-02-14T09:30:00Z", "mailboxIds": map[string]bool{"mb-inbox": true}, "keywords": map[string]bool{}},
+			{"id": "M2", "threadId": "T2", "subject": "Archived", "receivedAt": "2026-02-14T10:00:00Z", "mailboxIds": map[string]bool{"mb-archive": true}, "keywords": map[string]bool{}},
+			{"id": "M3", "threadId": "T3", "subject": "Newer", "receivedAt": "2026-02-14T10:30:00Z", "mailboxIds": map[string]bool{"mb-inbox": true}, "keywords": map[string]bool{"$seen": true}},
+		},
+		nil,
+	)
+	server.destroyed = []string{"M0"}
+
+	stdout, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "list", "--changed-since", "state-1"))
+	if err != nil {
+		t.Fatalf("list failed: %v\nstderr=%s", err, stderr)
+	}
+	var result types.EmailListResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, stdout)
+	}
+	if len(result.Emails) != 2 || result.Emails[0].ID != "M3" || result.Emails[1].ID != "M1" {
+		t.Fatalf("expected the changed inbox emails newest first, got %+v", result.Emails)
+	}
+	if result.State != "state-2" || len(result.Destroyed) != 1 || result.Destroyed[0] != "M0" {
+		t.Errorf("expected the new state and destroyed IDs, got state %q destroyed %v", result.State, result.Destroyed)
+	}
+	if server.count("Email/query") != 0 {
+		t.Error("expected no Email/query with --changed-since")
+	}
+
+	stdout, stderr, err = runCLICommand(t, commandArgsForServer(t, server.server.URL, "list", "--changed-since", "state-1", "--unread"))
+	if err != nil {
+		t.Fatalf("list failed: %v\nstderr=%s", err, stderr)
+	}
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, stdout)
+	}
+	if len(result.Emails) != 1 || result.Emails[0].ID != "M1" {
+		t.Fatalf("expected only the unread email, got %+v", result.Emails)
+	}
+}
+
+func TestList_ChangedSinceListsEveryChange(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", "")
+
+	var emails []map[string]any
+	for i := range 30 {
+		emails = append(emails, map[string]any{
+			"id": fmt.Sprintf("M%02d", i), "threadId": fmt.Sprintf("T%02d", i), "subject": "Change",
+			"receivedAt": fmt.Sprintf("2026-02-14T10:%02d:00Z", i),
+			"mailboxIds": map[string]bool{"mb-inbox": true}, "keywords": map[string]bool{},
+		})
+	}
+	server := newJMAPMockServer(t, []map[string]any{{"id": "mb-inbox", "name": "Inbox", "role": "inbox"}}, emails, nil)
+
+	stdout, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "list", "--changed-since", "state-1"))
+	if err != nil {
+		t.Fatalf("list failed: %v\nstderr=%s", err, stderr)
+	}
+	var result types.EmailListResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, stdout)
+	}
+	if result.Total != 30 || len(result.Emails) != 30 {
+		t.Fatalf("expected all 30 changes alongside the new state, got %d of %d", len(result.Emails), result.Total)
+	}
+
+	_, stderr, err = runCLICommand(t, commandArgsForServer(t, server.server.URL, "list", "--changed-since", "state-1", "--limit", "10"))
+	if err == nil || !strings.Contains(stderr, "cannot combine --changed-since with --limit") {
+		t.Fatalf("expected --limit to be refused, got err=%v stderr=%s", err, stderr)
+	}
+}
+
+func TestList_ChangedSinceExpiredState(t *testing.T) {
+	server := newJMAPMockServer(t,
+		[]map[string]any{{"id": "mb-inbox", "name": "Inbox", "role": "inbox"}},
+		nil,
+		nil,
+	)
+
+	_, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "list", "--changed-since", "expired"))
+	if err == nil {
+		t.Fatal("expected an error for a state the server has no changes for")
+	}
+	if !strings.Contains(stderr, "cannot calculate changes") || !strings.Contains(stderr, "fm state") {
+		t.Fatalf("expected a cannot-calculate-changes error with a hint, got: %s", stderr)
+	}
+}
+
+func TestList_ChangedSinceRejectsSort(t *testing.T) {
+	_, stderr, err := runCLICommand(t, []string{"list", "--changed-since", "s1", "--sort", "subject asc"})
+	if err == nil || !strings.Contains(stderr, "cannot combine --changed-since with --sort") {
+		t.Fatalf("expected a combination error, got: %v\n%s", err, stderr)
+	}
+}
+
+func TestList_UnknownMailboxSuggestsNames(t *testing.T) {
+	server := newJMAPMockServer(t,
+		[]map[string]any{
+			{"id": "mb-inbox", "name": "Inbox", "role": "inbox"},
+			{"id": "mb-receipts", "name": "Receipts"},
+			{"id": "mb-recipes", "name": "Recipes"},
+		},
+		nil,
+		nil,
+	)
+
+	_, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "list", "--mailbox", "Recipts"))
+	if err == nil {
+		t.Fatal("expected an unknown mailbox to fail")
+	}
+	if !strings.Contains(stderr, "not_found") || !strings.Contains(stderr, "Did you mean: Receipts, Recipes?") {
+		t.Fatalf("expected not_found with suggestions, got: %s", stderr)
+	}
+	if server.count("Email/query") != 0 {
+		t.Errorf("expected no Email/query, got %d", server.count("Email/query"))
+	}
+}
+
+func TestList_DraftsListsTheDraftsMailbox(t *testing.T) {
+	server := newJMAPMockServer(t,
+		[]map[string]any{
+			{"id": "mb-inbox", "name": "Inbox", "role": "inbox"},
+			{"id": "mb-drafts", "name": "Brouillons", "role": "drafts"},
+		},
+		nil,
+		nil,
+	)
+
+	_, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "list", "--drafts", "--explain"))
+	if err != nil {
+		t.Fatalf("list failed: %v\nstderr=%s", err, stderr)
+	}
+	if !strings.Contains(stderr, `"inMailbox": "mb-drafts"`) {
+		t.Errorf("expected a query of the Drafts mailbox, got: %s", stderr)
+	}
+}
+
+func TestList_DraftsRejectsMailbox(t *testing.T) {
+	_, stderr, err := runCLICommand(t, []string{"list", "--drafts", "--mailbox", "Archive"})
+	if err == nil {
+		t.Fatal("expected error when combining --drafts and --mailbox")
+	}
+	if !strings.Contains(stderr, "cannot combine --drafts with --mailbox") {
+		t.Fatalf("expected combination error, got: %s", stderr)
+	}
+}
+
+func TestSearch_LeavesOutDraftsUnlessMailboxGiven(t *testing.T) {
+	server := newJMAPMockServer(t,
+		[]map[string]any{{"id": "mb-drafts", "name": "Drafts", "role": "drafts"}},
+		nil,
+		nil,
+	)
+
+	tests := []struct {
+		name string
+		args []string
+		want bool
+	}{
+		{"all mailboxes", []string{"search", "invoice"}, true},
+		{"a mailbox", []string{"search", "invoice", "--mailbox", "drafts"}, false},
+		{"included", []string{"search", "invoice", "--no-drafts=false"}, false},
+		{"mailbox and excluded", []string{"search", "invoice", "--mailbox", "drafts", "--no-drafts"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append(commandArgsForServer(t, server.server.URL, tt.args...), "--explain")
+			_, stderr, err := runCLICommand(t, args)
+			if err != nil {
+				t.Fatalf("search failed: %v\nstderr=%s", err, stderr)
+			}
+			if got := strings.Contains(stderr, `"notKeyword": "$draft"`); got != tt.want {
+				t.Errorf("expected drafts left out: %v, got query: %s", tt.want, stderr)
+			}
+		})
+	}
+}
+
+func TestList_ParseNotifications(t *testing.T) {
+	server := newJMAPMockServer(t,
+		[]map[string]any{{"id": "mb-inbox", "name": "Inbox", "role": "inbox"}},
+		[]map[string]any{
+			{
+				"id":         "M1",
+				"mailboxIds": map[string]bool{"mb-inbox": true},
+				"subject":    "Re: [acme/widgets] Fix the widget (PR #1234)",
+				"receivedAt": "2026-02-04T10:30:00Z",
+				"headers": []map[string]any{
+					{"name": "List-ID", "value": " acme/widgets <widgets.acme.github.com>"},
+					{"name": "X-GitHub-Reason", "value": " review_requested"},
+					{"name": "Message-ID", "value": " <acme/widgets/pull/1234/c1@github.com>"},
+				},
+			},
+			{
+				"id":         "M2",
+				"mailboxIds": map[string]bool{"mb-inbox": true},
+				"subject":    "Lunch?",
+				"receivedAt": "2026-02-04T09:30:00Z",
+			},
+		},
+		nil,
+	)
+
+	stdout, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "list", "--parse", "notifications"))
+	if err != nil {
+		t.Fatalf("list failed: %v\nstderr=%s", err, stderr)
+	}
+	var result struct {
+		Emails []types.EmailSummary `json:"emails"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, stdout)
+	}
+	if len(result.Emails) != 2 {
+		t.Fatalf("expected two emails, got %+v", result.Emails)
+	}
+	want := types.Notification{Provider: "github", Repo: "acme/widgets", Kind: "pull_request", Number: 1234, Reason: "review_requested"}
+	if n := result.Emails[0].Notification; n == nil || *n != want {
+		t.Errorf("expected notification %+v, got %+v", want, n)
+	}
+	if n := result.Emails[1].Notification; n != nil {
+		t.Errorf("expected no notification for other mail, got %+v", n)
+	}
+}
+
+func TestList_ParseRejectsUnknownMode(t *testing.T) {
+	_, stderr, err := runCLICommand(t, []string{"list", "--parse", "notifications,invoices"})
+	if err == nil || !strings.Contains(stderr, `unknown --parse mode \"invoices\"`) {
+		t.Fatalf("expected an unknown mode error, got: %v\n%s", err, stderr)
+	}
+}
+
+func TestSearch_ParseReceipts(t *testing.T) {
+	html := `<script type="application/ld+json">{"@type": "Order", "merchant": {"name": "Acme Books"},
+		"orderNumber": "A-1042", "price": "42.50", "priceCurrency": "USD"}</script><p>Thanks for your order</p>`
+	server := newJMAPMockServer(t, nil,
+		[]map[string]any{{
+			"id":         "M1",
+			"subject":    "Your order A-1042",
+			"receivedAt": "2026-02-04T10:30:00Z",
+			"htmlBody":   []map[string]any{{"partId": "1", "type": "text/html"}},
+			"bodyValues": map[string]any{"1": map[string]any{"value": html}},
+		}},
+		nil,
+	)
+
+	stdout, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "search", "order", "--parse", "receipts"))
+	if err != nil {
+		t.Fatalf("search failed: %v\nstderr=%s", err, stderr)
+	}
+	var result struct {
+		Emails []types.EmailSummary `json:"emails"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, stdout)
+	}
+	want := types.Receipt{Merchant: "Acme Books", OrderID: "A-1042", Total: "42.50", Currency: "USD"}
+	if len(result.Emails) != 1 || result.Emails[0].Receipt == nil || *result.Emails[0].Receipt != want {
+		t.Fatalf("expected receipt %+v, got %s", want, stdout)
+	}
+	if result.Emails[0].Notification != nil {
+		t.Errorf("expected no notification without --parse notifications, got %+v", result.Emails[0].Notification)
+	}
+}
+
+func TestSearch_CountOnly(t *testing.T) {
+	from := func(addr string) []map[string]any { return []map[string]any{{"email": addr}} }
+	server := newJMAPMockServer(t,
+		[]map[string]any{
+			{"id": "mb-inbox", "name": "Inbox", "role": "inbox"},
+			{"id": "mb-archive", "name": "Archive", "role": "archive"},
+		},
+		[]map[string]any{
+			{"id": "M1", "from": from("Ann@Example.com"), "receivedAt": "2026-02-14T11:00:00Z", "mailboxIds": map[string]bool{"mb-inbox": true}},
+			{"id": "M2", "from": from("ann@example.com"), "receivedAt": "2026-02-14T11:30:00Z", "mailboxIds": map[string]bool{"mb-inbox": true, "mb-archive": true}},
+			{"id": "M3", "from": from("bob@example.org"), "receivedAt": "2026-02-12T11:00:00Z", "mailboxIds": map[string]bool{"mb-archive": true}},
+		},
+		nil,
+	)
+
+	count := func(args ...string) types.SearchCountResult {
+		t.Helper()
+		stdout, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, append([]string{"search", "--count-only"}, args...)...))
+		if err != nil {
+			t.Fatalf("search %v failed: %v\nstderr=%s", args, err, stderr)
+		}
+		var result types.SearchCountResult
+		if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+			t.Fatalf("decode output: %v\n%s", err, stdout)
+		}
+		return result
+	}
+
+	if got := count(); got.Total != 3 || got.Groups != nil {
+		t.Errorf("expected a total of 3 and no groups, got %+v", got)
+	}
+
+	tests := []struct {
+		groupBy string
+		want    []types.GroupCount
+	}{
+		{"sender", []types.GroupCount{{Key: "ann@example.com", Count: 2}, {Key: "bob@example.org", Count: 1}}},
+		{"domain", []types.GroupCount{{Key: "example.com", Count: 2}, {Key: "example.org", Count: 1}}},
+		{"mailbox", []types.GroupCount{{Key: "Archive", Count: 2}, {Key: "Inbox", Count: 2}}},
+		{"day", []types.GroupCount{{Key: "2026-02-12", Count: 1}, {Key: "2026-02-14", Count: 2}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.groupBy, func(t *testing.T) {
+			got := count("--group-by", tt.groupBy)
+			if got.Total != 3 || got.GroupBy != tt.groupBy || !reflect.DeepEqual(got.Groups, tt.want) {
+				t.Errorf("expected groups %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSearch_CountOnlyRejectsConflicts(t *testing.T) {
+	server := newJMAPMockServer(t, nil, nil, nil)
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--group-by", "sender"}, "--group-by requires --count-only"},
+		{[]string{"--count-only", "--group-by", "subject"}, "unknown --group-by field"},
+		{[]string{"--count-only", "--limit", "5"}, "cannot combine --count-only with --limit"},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			_, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, append([]string{"search"}, tt.args...)...))
+			if !errors.Is(err, ErrSilent) || !strings.Contains(stderr, tt.want) {
+				t.Fatalf("expected %q, got: %v\n%s", tt.want, err, stderr)
+			}
+		})
+	}
+}
@@ -2,8 +2,11 @@ package cmd
 
 import (
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/client"
 )
 
 var mailboxesCmd = &cobra.Command{
@@ -30,3 +33,12 @@ func init() {
 	mailboxesCmd.Flags().Bool("roles-only", false, "only show mailboxes with a defined role")
 	rootCmd.AddCommand(mailboxesCmd)
 }
+
+// mailboxHint suggests the mailboxes probably meant when err is a
+// mailbox-not-found error.
+func mailboxHint(err error) string {
+	if suggestions := client.MailboxSuggestions(err); len(suggestions) > 0 {
+		return "Did you mean: " + strings.Join(suggestions, ", ") + "?"
+	}
+	return "Run fm mailboxes to list the mailbox names"
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"git.sr.ht/~rockorager/go-jmap/mail/mailbox"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/cboone/fm/internal/client"
+	"github.com/cboone/fm/internal/types"
+)
+
+var mailboxesRenameCmd = &cobra.Command{
+	Use:   "rename <mailbox> [new-name]",
+	Short: "Rename a mailbox or move it under another",
+	Long: `Rename a mailbox (by name, path, or ID), move it under another with
+--parent, or both. --parent "" moves it to the top level. The emails and
+child mailboxes inside it move with it.
+
+Mailboxes with a role, such as Inbox and Trash, cannot be renamed or moved,
+and nothing is moved into Trash. Changing mailboxes must be allowed with
+mailbox_write: true in the config file. fm asks for confirmation on the
+terminal; pass --yes to skip the question, as scripts must.`,
+	Example: `  fm mailboxes rename "Clients/Acme" "Acme 2025"
+  fm mailboxes rename "Acme 2025" --parent Archive/Clients --yes
+  fm mailboxes rename Receipts --parent "" --dry-run`,
+	Args:              cobra.RangeArgs(1, 2),
+	ValidArgsFunction: cobra.NoFileCompletions,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reparent := cmd.Flags().Changed("parent")
+		if len(args) == 1 && !reparent {
+			return exitError("general_error", "nothing to change",
+				"Give a new name, --parent, or both")
+		}
+		newName := ""
+		if len(args) == 2 {
+			newName = strings.TrimSpace(args[1])
+			if newName == "" || strings.Contains(newName, "/") {
+				return exitError("general_error", fmt.Sprintf("invalid mailbox name %q", args[1]),
+					"A name cannot be empty or contain /; use --parent to move the mailbox")
+			}
+		}
+
+		if !viper.GetBool("mailbox_write") {
+			return exitError("forbidden_operation", "changing mailboxes is not enabled",
+				"Set mailbox_write: true in the config file or FM_MAILBOX_WRITE=true to allow fm mailboxes rename")
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your credential command or the token it returns")
+		}
+
+		mb, err := findMailbox(c, args[0])
+		if err != nil {
+			return err
+		}
+		if newName == "" {
+			newName = mb.Name
+		}
+
+		parent, err := currentParent(c, mb)
+		if err != nil {
+			return exitError("jmap_error", err.Error(), "")
+		}
+		if reparent {
+			parent = nil
+			if parentName, _ := cmd.Flags().GetString("parent"); strings.TrimSpace(parentName) != "" {
+				if parent, err = findMailbox(c, strings.TrimSpace(parentName)); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := c.ValidateMailboxRename(mb, newName, parent); err != nil {
+			var forbidden *client.ErrForbidden
+			if errors.As(err, &forbidden) {
+				return exitError("forbidden_operation", err.Error(), "")
+			}
+			return exitError("general_error", err.Error(), "")
+		}
+
+		result := types.MailboxRenameResult{
+			ID:           string(mb.ID),
+			Name:         newName,
+			Path:         newName,
+			PreviousPath: c.MailboxPath(mb),
+		}
+		if parent != nil {
+			result.ParentID = string(parent.ID)
+			result.Path = c.MailboxPath(parent) + "/" + newName
+		}
+
+		if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+			result.DryRun = true
+			return formatter().Format(os.Stdout, result)
+		}
+
+		if err := confirm(cmd, fmt.Sprintf("Rename mailbox %q to %q?", result.PreviousPath, result.Path)); err != nil {
+			return err
+		}
+
+		if err := c.RenameMailbox(mb, newName, parent); err != nil {
+			return exitError("jmap_error", err.Error(), "")
+		}
+
+		return formatter().Format(os.Stdout, result)
+	},
+}
+
+// findMailbox resolves a mailbox by role, name, or ID, then as a mailbox
+// path, failing with not_found when it does not exist.
+func findMailbox(c *client.Client, nameOrPath string) (*mailbox.Mailbox, error) {
+	mb, missing, err := findMoveTarget(c, nameOrPath)
+	if err != nil {
+		return nil, exitError("not_found", err.Error(), "")
+	}
+	if len(missing) > 0 {
+		_, notFound := c.GetMailboxByNameOrID(nameOrPath)
+		return nil, exitError("not_found", fmt.Sprintf("mailbox not found: %q", nameOrPath), mailboxHint(notFound))
+	}
+	return mb, nil
+}
+
+// currentParent returns the mailbox mb is in, or nil at the top level.
+func currentParent(c *client.Client, mb *mailbox.Mailbox) (*mailbox.Mailbox, error) {
+	if mb.ParentID == "" {
+		return nil, nil
+	}
+	return c.GetMailboxByNameOrID(string(mb.ParentID))
+}
+
+func init() {
+	mailboxesRenameCmd.Flags().String("parent", "", `move the mailbox under this mailbox (name, path, or ID); "" for the top level`)
+	mailboxesRenameCmd.Flags().BoolP("dry-run", "n", false, "preview without making changes")
+	addYesFlag(mailboxesRenameCmd)
+	mailboxesCmd.AddCommand(mailboxesRenameCmd)
+}
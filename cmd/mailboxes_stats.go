@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var mailboxesStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show how much space each mailbox takes",
+	Long: `Show how many emails each mailbox holds and their total size, largest
+mailbox first, to find which folder is eating the storage quota. Every
+email in the account is scanned, fetching only its size and mailboxes, so
+this takes a while on large accounts. An email in several mailboxes counts
+toward each of them, but only once toward the account total.`,
+	Example: `  fm mailboxes stats
+  fm mailboxes stats --format text`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your credential command or the token it returns")
+		}
+
+		result, err := c.MailboxSizes()
+		if err != nil {
+			return exitError("jmap_error", err.Error(), "")
+		}
+		return formatter().Format(os.Stdout, result)
+	},
+}
+
+func init() {
+	mailboxesCmd.AddCommand(mailboxesStatsCmd)
+}
@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+func renameTestServer(t *testing.T) *jmapMockServer {
+	t.Helper()
+	return newJMAPMockServer(t,
+		[]map[string]any{
+			{"id": "mb-inbox", "name": "Inbox", "role": "inbox"},
+			{"id": "mb-clients", "name": "Clients"},
+			{"id": "mb-acme", "name": "Acme", "parentId": "mb-clients"},
+			{"id": "mb-archive", "name": "Archive", "role": "archive"},
+		},
+		nil, nil,
+	)
+}
+
+func renameArgs(t *testing.T, url string, args ...string) []string {
+	t.Helper()
+	full := commandArgsForServer(t, url, append([]string{"mailboxes", "rename"}, args...)...)
+	if err := os.WriteFile(full[1], []byte("mailbox_write: true\n"), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return full
+}
+
+func TestMailboxesRename_RenamesAndReparents(t *testing.T) {
+	server := renameTestServer(t)
+
+	args := renameArgs(t, server.server.URL, "Clients/Acme", "Acme 2025", "--parent", "archive", "--yes")
+	stdout, stderr, err := runCLICommand(t, args)
+	if err != nil {
+		t.Fatalf("rename failed: %v\n%s", err, stderr)
+	}
+
+	var result types.MailboxRenameResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, stdout)
+	}
+	if result.PreviousPath != "Clients/Acme" || result.Path != "Archive/Acme 2025" || result.ParentID != "mb-archive" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if mb := server.mailboxes[2]; mb["name"] != "Acme 2025" || mb["parentId"] != "mb-archive" {
+		t.Errorf("expected the mailbox to be renamed under Archive, got %v", mb)
+	}
+}
+
+func TestMailboxesRename_TopLevel(t *testing.T) {
+	server := renameTestServer(t)
+
+	args := renameArgs(t, server.server.URL, "Clients/Acme", "--parent", "", "--yes")
+	if _, stderr, err := runCLICommand(t, args); err != nil {
+		t.Fatalf("rename failed: %v\n%s", err, stderr)
+	}
+	if mb := server.mailboxes[2]; mb["name"] != "Acme" || mb["parentId"] != nil {
+		t.Errorf("expected Acme at the top level, got %v", mb)
+	}
+}
+
+func TestMailboxesRename_NeedsMailboxWrite(t *testing.T) {
+	server := renameTestServer(t)
+
+	args := commandArgsForServer(t, server.server.URL, "mailboxes", "rename", "Clients", "Customers", "--yes")
+	_, stderr, err := runCLICommand(t, args)
+	if !errors.Is(err, ErrSilent) || !strings.Contains(stderr, "forbidden_operation") {
+		t.Fatalf("expected forbidden_operation, got: %v\n%s", err, stderr)
+	}
+	if server.count("Mailbox/set") != 0 {
+		t.Errorf("expected no Mailbox/set, got %d", server.count("Mailbox/set"))
+	}
+}
+
+func TestMailboxesRename_RefusesRoleMailbox(t *testing.T) {
+	server := renameTestServer(t)
+
+	args := renameArgs(t, server.server.URL, "inbox", "Incoming", "--yes")
+	_, stderr, err := runCLICommand(t, args)
+	if !errors.Is(err, ErrSilent) || !strings.Contains(stderr, "forbidden_operation") {
+		t.Fatalf("expected forbidden_operation, got: %v\n%s", err, stderr)
+	}
+	if server.count("Mailbox/set") != 0 {
+		t.Errorf("expected no Mailbox/set, got %d", server.count("Mailbox/set"))
+	}
+}
+
+func TestMailboxesRename_RequiresConfirmation(t *testing.T) {
+	server := renameTestServer(t)
+
+	args := renameArgs(t, server.server.URL, "Clients", "Customers")
+	_, stderr, err := runCLICommand(t, args)
+	if !errors.Is(err, ErrSilent) || !strings.Contains(stderr, "confirmation required") {
+		t.Fatalf("expected a confirmation error, got: %v\n%s", err, stderr)
+	}
+	if server.count("Mailbox/set") != 0 {
+		t.Errorf("expected no Mailbox/set, got %d", server.count("Mailbox/set"))
+	}
+}
+
+func TestMailboxesRename_DryRun(t *testing.T) {
+	server := renameTestServer(t)
+
+	args := renameArgs(t, server.server.URL, "Clients", "Customers", "--dry-run")
+	stdout, stderr, err := runCLICommand(t, args)
+	if err != nil {
+		t.Fatalf("dry run failed: %v\n%s", err, stderr)
+	}
+	if !strings.Contains(stdout, `"dry_run": true`) || !strings.Contains(stdout, `"path": "Customers"`) {
+		t.Errorf("expected a dry run preview, got %s", stdout)
+	}
+	if server.count("Mailbox/set") != 0 {
+		t.Errorf("expected no Mailbox/set, got %d", server.count("Mailbox/set"))
+	}
+}
+
+func TestMailboxesVerify_ReportsDiscrepancies(t *testing.T) {
+	// The mock server's Email/query finds both emails in every mailbox.
+	server := newJMAPMockServer(t,
+		[]map[string]any{
+			{"id": "mb-inbox", "name": "Inbox", "role": "inbox", "totalEmails": 2, "unreadEmails": 2},
+			{"id": "mb-archive", "name": "Archive", "role": "archive", "totalEmails": 5, "unreadEmails": 2},
+		},
+		[]map[string]any{{"id": "M1"}, {"id": "M2"}},
+		nil,
+	)
+
+	args := commandArgsForServer(t, server.server.URL, "mailboxes", "verify")
+	stdout, stderr, err := runCLICommand(t, args)
+	if !errors.Is(err, ErrSilent) || !strings.Contains(stderr, "count_mismatch") {
+		t.Fatalf("expected count_mismatch, got: %v\n%s", err, stderr)
+	}
+
+	var result types.MailboxVerifyResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, stdout)
+	}
+	if result.Checked != 2 || result.Discrepancies != 1 || len(result.Mailboxes) != 1 {
+		t.Fatalf("expected one discrepancy of two, got %+v", result)
+	}
+	if got := result.Mailboxes[0]; got.Name != "Archive" || got.ReportedTotal != 5 || got.ActualTotal != 2 || got.Match {
+		t.Errorf("unexpected check: %+v", got)
+	}
+	if server.count("Email/query") != 4 {
+		t.Errorf("expected two queries per mailbox, got %d", server.count("Email/query"))
+	}
+}
+
+func TestMailboxesVerify_SingleMailboxMatches(t *testing.T) {
+	server := newJMAPMockServer(t,
+		[]map[string]any{
+			{"id": "mb-inbox", "name": "Inbox", "role": "inbox", "totalEmails": 1, "unreadEmails": 1},
+			{"id": "mb-archive", "name": "Archive", "role": "archive", "totalEmails": 5},
+		},
+		[]map[string]any{{"id": "M1"}},
+		nil,
+	)
+
+	args := commandArgsForServer(t, server.server.URL, "mailboxes", "verify", "--mailbox", "inbox", "--all")
+	stdout, stderr, err := runCLICommand(t, args)
+	if err != nil {
+		t.Fatalf("verify failed: %v\n%s", err, stderr)
+	}
+	var result types.MailboxVerifyResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, stdout)
+	}
+	if result.Checked != 1 || result.Discrepancies != 0 || len(result.Mailboxes) != 1 || !result.Mailboxes[0].Match {
+		t.Errorf("expected the inbox to match, got %+v", result)
+	}
+}
+
+func TestMailboxesStats_SumsSizesLargestFirst(t *testing.T) {
+	server := newJMAPMockServer(t,
+		[]map[string]any{
+			{"id": "mb-inbox", "name": "Inbox", "role": "inbox"},
+			{"id": "mb-archive", "name": "Archive", "role": "archive"},
+			{"id": "mb-empty", "name": "Empty"},
+		},
+		[]map[string]any{
+			{"id": "M1", "size": 1000, "mailboxIds": map[string]bool{"mb-inbox": true}},
+			{"id": "M2", "size": 5000, "mailboxIds": map[string]bool{"mb-archive": true}},
+			{"id": "M3", "size": 3000, "mailboxIds": map[string]bool{"mb-archive": true, "mb-inbox": true}},
+		},
+		nil,
+	)
+
+	stdout, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "mailboxes", "stats"))
+	if err != nil {
+		t.Fatalf("mailboxes stats failed: %v\n%s", err, stderr)
+	}
+	var result types.MailboxStatsResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, stdout)
+	}
+
+	if result.Scanned != 3 || result.TotalSize != 9000 {
+		t.Errorf("expected 3 emails of 9000 bytes, got %d of %d", result.Scanned, result.TotalSize)
+	}
+	want := []types.MailboxSize{
+		{ID: "mb-archive", Name: "Archive", Role: "archive", Emails: 2, Size: 8000},
+		{ID: "mb-inbox", Name: "Inbox", Role: "inbox", Emails: 2, Size: 4000},
+		{ID: "mb-empty", Name: "Empty"},
+	}
+	if len(result.Mailboxes) != len(want) {
+		t.Fatalf("expected %d mailboxes, got %+v", len(want), result.Mailboxes)
+	}
+	for i, m := range want {
+		if result.Mailboxes[i] != m {
+			t.Errorf("mailbox %d: expected %+v, got %+v", i, m, result.Mailboxes[i])
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+var mailboxesVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check mailbox counts against the emails actually in them",
+	Long: `Compare the total and unread counts the server reports for each mailbox
+with the number of emails a query finds in it, and report the mailboxes
+where they differ. A difference points to a sync bug or count drift on the
+server. Unread emails are those with neither $seen nor $draft, as the
+counts are defined by JMAP.
+
+Exits with count_mismatch when any mailbox differs.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your credential command or the token it returns")
+		}
+
+		var ids []jmap.ID
+		if name, _ := cmd.Flags().GetString("mailbox"); strings.TrimSpace(name) != "" {
+			id, err := c.ResolveMailboxID(strings.TrimSpace(name))
+			if err != nil {
+				return exitError("not_found", err.Error(), mailboxHint(err))
+			}
+			ids = []jmap.ID{id}
+		}
+
+		checks, err := c.VerifyMailboxCounts(ids)
+		if err != nil {
+			return exitError("jmap_error", err.Error(), "")
+		}
+
+		all, _ := cmd.Flags().GetBool("all")
+		result := types.MailboxVerifyResult{Checked: len(checks), Mailboxes: []types.MailboxCountCheck{}}
+		for _, check := range checks {
+			if !check.Match {
+				result.Discrepancies++
+			}
+			if all || !check.Match {
+				result.Mailboxes = append(result.Mailboxes, check)
+			}
+		}
+
+		if err := formatter().Format(os.Stdout, result); err != nil {
+			return err
+		}
+
+		if result.Discrepancies > 0 {
+			return exitError("count_mismatch",
+				fmt.Sprintf("%d mailbox(es) report counts that differ from their emails", result.Discrepancies),
+				"Run again to rule out mail that arrived meanwhile; a lasting difference is a server-side count problem")
+		}
+		return nil
+	},
+}
+
+func init() {
+	mailboxesVerifyCmd.Flags().StringP("mailbox", "m", "", "verify only this mailbox")
+	mailboxesVerifyCmd.Flags().Bool("all", false, "list every mailbox checked, not only those that differ")
+	mailboxesCmd.AddCommand(mailboxesVerifyCmd)
+}
@@ -4,14 +4,23 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 
+	"github.com/cboone/fm/internal/client"
 	"github.com/cboone/fm/internal/types"
 )
 
 var markReadCmd = &cobra.Command{
 	Use:   "mark-read [email-id...]",
 	Short: "Mark emails as read (set the $seen keyword)",
-	Args:  cobra.ArbitraryArgs,
+	Long: `Mark emails as read by setting the $seen keyword.
+
+With --thread, every email in the conversation of each matched email is
+marked read, as the Fastmail web app does when a conversation is opened.
+Set mark_read_thread: true in the config file (or FM_MARK_READ_THREAD=true)
+to make that the default; --thread=false then marks single emails.`,
+	Args:              cobra.ArbitraryArgs,
+	ValidArgsFunction: completeEmailIDs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if err := validateIDsOrFilters(cmd, args); err != nil {
 			return err
@@ -28,19 +37,40 @@ var markReadCmd = &cobra.Command{
 			return err
 		}
 
+		var notFound []string
+		if markReadThread(cmd) {
+			ids, notFound, err = c.ThreadMemberIDs(ids)
+			if err != nil {
+				return exitError("jmap_error", err.Error(), "")
+			}
+		}
+
 		dryRun, _ := cmd.Flags().GetBool("dry-run")
 		if dryRun {
 			return dryRunPreview(c, ids, "mark-read", nil)
 		}
 
-		succeeded, errors := c.MarkAsRead(ids)
+		states, err := c.GetEmailStates(ids)
+		if err != nil {
+			return exitError("jmap_error", err.Error(), "")
+		}
+		pending, noOps := client.SplitNoOps(ids, states, client.HasKeywords("$seen"))
+
+		c.SetProgress(progressPrinter("Marking read"))
+		outcomes := recordOutcomes(cmd, c)
+		succeeded, errors := c.MarkAsRead(pending)
+		for _, id := range notFound {
+			errors = append(errors, id+": not found")
+		}
 
 		result := types.MoveResult{
 			Matched:      len(ids),
 			Processed:    len(succeeded) + len(errors),
 			Failed:       len(errors),
+			NoOp:         len(noOps),
 			MarkedAsRead: succeeded,
 			Errors:       errors,
+			Results:      append(outcomes.results(succeeded, errors), outcomes.noOps(noOps)...),
 		}
 
 		if err := formatter().Format(os.Stdout, result); err != nil {
@@ -48,7 +78,7 @@ var markReadCmd = &cobra.Command{
 		}
 
 		if len(errors) > 0 {
-			return exitError("partial_failure", "one or more emails failed to mark as read", "")
+			return exitError("partial_failure", "one or more emails failed to mark as read", retryHint(cmd, errors))
 		}
 
 		return nil
@@ -57,6 +87,20 @@ var markReadCmd = &cobra.Command{
 
 func init() {
 	markReadCmd.Flags().BoolP("dry-run", "n", false, "preview affected emails without making changes")
+	addVerboseFlag(markReadCmd)
+	addIDsFileFlag(markReadCmd)
+	markReadCmd.Flags().Bool("thread", false, "mark every email in the matched emails' threads read (default from mark_read_thread)")
 	addFilterFlags(markReadCmd)
+	addFromLastFlag(markReadCmd)
 	rootCmd.AddCommand(markReadCmd)
 }
+
+// markReadThread reports whether mark-read should cover whole threads: the
+// --thread flag when given, otherwise the mark_read_thread setting.
+func markReadThread(cmd *cobra.Command) bool {
+	if cmd.Flags().Changed("thread") {
+		thread, _ := cmd.Flags().GetBool("thread")
+		return thread
+	}
+	return viper.GetBool("mark_read_thread")
+}
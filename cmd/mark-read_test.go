@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+func TestMarkRead_ThreadFromConfigAndFlag(t *testing.T) {
+	server := newJMAPMockServer(t,
+		[]map[string]any{{"id": "mb-inbox", "name": "Inbox", "role": "inbox"}},
+		[]map[string]any{
+			{"id": "M1", "threadId": "T1", "mailboxIds": map[string]bool{"mb-inbox": true}},
+			{"id": "M2", "threadId": "T1", "mailboxIds": map[string]bool{"mb-inbox": true}},
+		},
+		nil,
+	)
+
+	markRead := func(extra ...string) []string {
+		t.Helper()
+		args := commandArgsForServer(t, server.server.URL, append([]string{"mark-read", "M1"}, extra...)...)
+		if err := os.WriteFile(args[1], []byte("mark_read_thread: true\n"), 0o600); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+		stdout, stderr, err := runCLICommand(t, args)
+		if err != nil {
+			t.Fatalf("mark-read failed: %v\n%s", err, stderr)
+		}
+		var result types.MoveResult
+		if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+			t.Fatalf("decode output: %v\n%s", err, stdout)
+		}
+		return result.MarkedAsRead
+	}
+
+	if got := markRead(); !reflect.DeepEqual(got, []string{"M1", "M2"}) {
+		t.Errorf("expected mark_read_thread to mark the whole thread, got %v", got)
+	}
+	if got := markRead("--thread=false"); !reflect.DeepEqual(got, []string{"M1"}) {
+		t.Errorf("expected --thread=false to mark only M1, got %v", got)
+	}
+}
@@ -0,0 +1,16 @@
+package cmd
+
+import "github.com/spf13/cobra"
+
+var maskedCmd = &cobra.Command{
+	Use:   "masked",
+	Short: "Manage Fastmail masked email addresses",
+	Long: `Manage Fastmail masked email addresses.
+
+Use 'masked find' to look up the addresses issued to a site and 'masked
+rotate' to replace a compromised address with a new one.`,
+}
+
+func init() {
+	rootCmd.AddCommand(maskedCmd)
+}
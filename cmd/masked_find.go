@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var maskedFindCmd = &cobra.Command{
+	Use:   "find",
+	Short: "Find the masked emails issued to a site",
+	Long: `Find the masked emails whose domain is the given site or one of its
+subdomains. Deleted masked emails are not shown.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		domain, _ := cmd.Flags().GetString("domain")
+		if strings.TrimSpace(domain) == "" {
+			return exitError("general_error", "--domain is required", "")
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your credential command or the token it returns")
+		}
+
+		result, err := c.FindMaskedEmails(domain)
+		if err != nil {
+			return exitError("jmap_error", err.Error(), unsupportedHint(err))
+		}
+
+		return formatter().Format(os.Stdout, result)
+	},
+}
+
+func init() {
+	maskedFindCmd.Flags().String("domain", "", "site domain to search for, e.g. shop.example (required)")
+	maskedCmd.AddCommand(maskedFindCmd)
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+var maskedRotateCmd = &cobra.Command{
+	Use:   "rotate <address>",
+	Short: "Replace a masked email with a new address",
+	Long: `Create a new masked email for the same site and description, then
+disable the old address. The replacement is created first, so a failure never
+leaves the site without a working address. The old address is disabled, not
+deleted.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your credential command or the token it returns")
+		}
+
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		if dryRun {
+			current, err := c.FindMaskedEmailByAddress(args[0])
+			if err != nil {
+				return exitError(readErrorCode(err), err.Error(), unsupportedHint(err))
+			}
+			return formatter().Format(os.Stdout, types.MaskedEmailDryRunResult{
+				Operation:   "rotate",
+				MaskedEmail: current,
+			})
+		}
+
+		result, err := c.RotateMaskedEmail(args[0])
+		if err != nil {
+			return exitError(readErrorCode(err), err.Error(), unsupportedHint(err))
+		}
+
+		return formatter().Format(os.Stdout, result)
+	},
+}
+
+func init() {
+	maskedRotateCmd.Flags().BoolP("dry-run", "n", false, "preview without making changes")
+	maskedCmd.AddCommand(maskedRotateCmd)
+}
@@ -0,0 +1,16 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMaskedFind_RequiresDomain(t *testing.T) {
+	_, stderr, err := runCLICommand(t, []string{"masked", "find"})
+	if err == nil {
+		t.Fatal("expected error without --domain")
+	}
+	if !strings.Contains(stderr, "--domain is required") {
+		t.Fatalf("expected missing domain error, got: %s", stderr)
+	}
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/mockserver"
+	"github.com/cboone/fm/internal/types"
+)
+
+var mockServerCmd = &cobra.Command{
+	Use:   "mock-server --fixtures <dir> [flags]",
+	Short: "Serve a fake JMAP account from fixture files",
+	Long: `Serve an in-memory JMAP account built from fixture files, for testing
+fm and scripts built on it without touching a real account. The fixtures
+directory holds mailboxes.json and emails.json, each a JSON array of JMAP
+Mailbox or Email objects. Emails need at least id, threadId, mailboxIds,
+and receivedAt.
+
+Mailbox/get, Email/query, Email/get, Email/set, Thread/get, and
+SearchSnippet/get are supported. Changes are kept in memory until the server
+stops; the fixture files are never modified. Any token is accepted.
+
+  fm mock-server --fixtures testdata/mail --addr 127.0.0.1:8765
+  fm --session-url http://127.0.0.1:8765/jmap/session \
+     --credential-command 'echo mock' list --unread`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fixtures, _ := cmd.Flags().GetString("fixtures")
+		addr, _ := cmd.Flags().GetString("addr")
+
+		if fixtures == "" {
+			return exitError("general_error", "required flag \"fixtures\" not set",
+				"Provide a directory holding mailboxes.json and emails.json")
+		}
+
+		srv, err := mockserver.LoadFixtures(fixtures)
+		if err != nil {
+			return exitError("general_error", err.Error(), "")
+		}
+
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			return exitError("general_error", "cannot listen: "+err.Error(), "")
+		}
+		httpSrv := &http.Server{Handler: srv, ReadHeaderTimeout: 10 * time.Second}
+
+		mailboxes, emails := srv.Counts()
+		info := types.MockServerInfo{
+			SessionURL: "http://" + ln.Addr().String() + mockserver.SessionPath,
+			AccountID:  mockserver.AccountID,
+			Mailboxes:  mailboxes,
+			Emails:     emails,
+		}
+		if err := formatter().Format(os.Stdout, info); err != nil {
+			_ = ln.Close()
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		go func() {
+			<-ctx.Done()
+			_ = httpSrv.Close()
+		}()
+
+		if err := httpSrv.Serve(ln); err != nil && err != http.ErrServerClosed {
+			return exitError("general_error", err.Error(), "")
+		}
+		return nil
+	},
+}
+
+func init() {
+	mockServerCmd.Flags().String("fixtures", "", "directory holding mailboxes.json and emails.json (required)")
+	mockServerCmd.Flags().String("addr", "127.0.0.1:8765", "address to listen on")
+	rootCmd.AddCommand(mockServerCmd)
+}
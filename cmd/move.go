@@ -1,9 +1,15 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
+	"slices"
+	"strings"
 
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/mailbox"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 
 	"github.com/cboone/fm/internal/client"
 	"github.com/cboone/fm/internal/types"
@@ -12,18 +18,46 @@ import (
 var moveCmd = &cobra.Command{
 	Use:   "move [email-id...] --to <mailbox>",
 	Short: "Move emails to a specified mailbox",
-	Long: `Move one or more emails to a target mailbox (by name or ID).
-Moving to Trash or Deleted Items is not permitted.`,
-	Args: cobra.ArbitraryArgs,
+	Long: `Move one or more emails to a target mailbox (by name, path, or ID).
+Moving to Trash or Deleted Items is not permitted.
+
+An email can be in several mailboxes at once. --to replaces all of them;
+--add-to and --remove-from, each repeatable, change only the mailboxes they
+name, so mailboxes can be used like labels:
+
+  fm move M1 --add-to Receipts --add-to "Tax 2026" --remove-from inbox
+
+A path such as "Clients/Acme 2025" names a mailbox by its parents. With
+--create-missing, a destination that does not exist is created first,
+along with any missing parents. Creating mailboxes must be allowed with
+mailbox_write: true in the config file.`,
+	Args:              cobra.ArbitraryArgs,
+	ValidArgsFunction: completeEmailIDs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if err := validateIDsOrFilters(cmd, args); err != nil {
 			return err
 		}
 
 		target, _ := cmd.Flags().GetString("to")
-		if target == "" {
+		addTo, _ := cmd.Flags().GetStringArray("add-to")
+		removeFrom, _ := cmd.Flags().GetStringArray("remove-from")
+		labels := len(addTo) > 0 || len(removeFrom) > 0
+		if target != "" && labels {
+			return exitError("general_error", "cannot combine --to with --add-to or --remove-from",
+				"--to replaces all of an email's mailboxes; --add-to and --remove-from change only the ones they name")
+		}
+		if target == "" && !labels {
 			return exitError("general_error", "required flag \"to\" not set",
-				"Specify the destination mailbox with --to <mailbox>")
+				"Specify the destination mailbox with --to <mailbox>, or use --add-to and --remove-from")
+		}
+
+		createMissing, _ := cmd.Flags().GetBool("create-missing")
+		if createMissing && labels {
+			return exitError("general_error", "--create-missing only works with --to", "")
+		}
+		if createMissing && !viper.GetBool("mailbox_write") {
+			return exitError("forbidden_operation", "creating mailboxes is not enabled",
+				"Set mailbox_write: true in the config file or FM_MAILBOX_WRITE=true to allow --create-missing")
 		}
 
 		c, err := newClient()
@@ -32,15 +66,30 @@ Moving to Trash or Deleted Items is not permitted.`,
 				"Check your credential command or the token it returns")
 		}
 
-		targetMB, err := c.GetMailboxByNameOrID(target)
+		if labels {
+			return updateMailboxes(cmd, c, args, addTo, removeFrom)
+		}
+
+		targetMB, missing, err := findMoveTarget(c, target)
 		if err != nil {
 			return exitError("not_found", err.Error(), "")
 		}
+		if len(missing) > 0 && !createMissing {
+			// The mailboxes are cached, so this only recovers the suggestions.
+			_, notFound := c.GetMailboxByNameOrID(target)
+			hint := "Pass --create-missing to create it"
+			if suggestions := client.MailboxSuggestions(notFound); len(suggestions) > 0 {
+				hint = "Did you mean: " + strings.Join(suggestions, ", ") + "? Or pass --create-missing to create it"
+			}
+			return exitError("not_found", fmt.Sprintf("mailbox not found: %q", target), hint)
+		}
 
-		// Safety check: refuse to move to trash.
-		if err := client.ValidateTargetMailbox(targetMB); err != nil {
-			return exitError("forbidden_operation", err.Error(),
-				"Deletion is not permitted by this tool")
+		// Safety check: refuse to move to trash, or to create a mailbox in it.
+		if targetMB != nil {
+			if err := client.ValidateTargetMailbox(targetMB); err != nil {
+				return exitError("forbidden_operation", err.Error(),
+					"Deletion is not permitted by this tool")
+			}
 		}
 
 		ids, err := resolveEmailIDs(cmd, args, c)
@@ -50,20 +99,42 @@ Moving to Trash or Deleted Items is not permitted.`,
 
 		dryRun, _ := cmd.Flags().GetBool("dry-run")
 		if dryRun {
-			return dryRunPreview(c, ids, "move", &types.DestinationInfo{
-				ID:   string(targetMB.ID),
-				Name: targetMB.Name,
-			})
+			// A destination still to be created has no ID yet.
+			dest := &types.DestinationInfo{Name: target}
+			if len(missing) == 0 {
+				dest = &types.DestinationInfo{ID: string(targetMB.ID), Name: targetMB.Name}
+			}
+			return dryRunPreview(c, ids, "move", dest)
+		}
+
+		var created []string
+		if len(missing) > 0 {
+			if targetMB, created, err = c.CreateMailboxPath(target); err != nil {
+				return exitError("jmap_error", err.Error(), "")
+			}
+		}
+
+		states, err := c.GetEmailStates(ids)
+		if err != nil {
+			return exitError("jmap_error", err.Error(), "")
 		}
+		pending, noOps := client.SplitNoOps(ids, states, func(s client.EmailState) bool {
+			return s.OnlyIn(targetMB.ID)
+		})
 
-		succeeded, errors := c.MoveEmails(ids, targetMB.ID)
+		c.SetProgress(progressPrinter("Moving"))
+		outcomes := recordOutcomes(cmd, c)
+		succeeded, errors := c.MoveEmails(pending, targetMB.ID)
 
 		result := types.MoveResult{
-			Matched:   len(ids),
-			Processed: len(succeeded) + len(errors),
-			Failed:    len(errors),
-			Moved:     succeeded,
-			Errors:    errors,
+			Matched:          len(ids),
+			Processed:        len(succeeded) + len(errors),
+			Failed:           len(errors),
+			NoOp:             len(noOps),
+			Moved:            succeeded,
+			Errors:           errors,
+			Results:          append(outcomes.results(succeeded, errors), outcomes.noOps(noOps)...),
+			CreatedMailboxes: created,
 			Destination: &types.DestinationInfo{
 				ID:   string(targetMB.ID),
 				Name: targetMB.Name,
@@ -75,16 +146,127 @@ Moving to Trash or Deleted Items is not permitted.`,
 		}
 
 		if len(errors) > 0 {
-			return exitError("partial_failure", "one or more emails failed to move", "")
+			return exitError("partial_failure", "one or more emails failed to move", retryHint(cmd, errors))
 		}
 
 		return nil
 	},
 }
 
+// updateMailboxes adds the selected emails to the addTo mailboxes and
+// removes them from the removeFrom mailboxes, leaving their other
+// mailboxes alone.
+func updateMailboxes(cmd *cobra.Command, c *client.Client, args, addTo, removeFrom []string) error {
+	add, err := resolveMailboxes(c, addTo)
+	if err != nil {
+		return err
+	}
+	for _, mb := range add {
+		if err := client.ValidateTargetMailbox(mb); err != nil {
+			return exitError("forbidden_operation", err.Error(), "Deletion is not permitted by this tool")
+		}
+	}
+	remove, err := resolveMailboxes(c, removeFrom)
+	if err != nil {
+		return err
+	}
+	for _, a := range add {
+		for _, r := range remove {
+			if a.ID == r.ID {
+				return exitError("general_error",
+					fmt.Sprintf("mailbox %s is in both --add-to and --remove-from", a.Name), "")
+			}
+		}
+	}
+
+	ids, err := resolveEmailIDs(cmd, args, c)
+	if err != nil {
+		return err
+	}
+
+	addIDs, addInfo := mailboxDestinations(add)
+	removeIDs, removeInfo := mailboxDestinations(remove)
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		return dryRunPlan(c, ids, types.DryRunResult{Operation: "move", AddTo: addInfo, RemoveFrom: removeInfo})
+	}
+
+	states, err := c.GetEmailStates(ids)
+	if err != nil {
+		return exitError("jmap_error", err.Error(), "")
+	}
+	pending, noOps := client.SplitNoOps(ids, states, func(s client.EmailState) bool {
+		return !slices.ContainsFunc(addIDs, func(id jmap.ID) bool { return !s.In(id) }) &&
+			!slices.ContainsFunc(removeIDs, s.In)
+	})
+
+	c.SetProgress(progressPrinter("Moving"))
+	outcomes := recordOutcomes(cmd, c)
+	succeeded, errors := c.UpdateMailboxes(pending, addIDs, removeIDs)
+
+	result := types.MoveResult{
+		Matched:     len(ids),
+		Processed:   len(succeeded) + len(errors),
+		Failed:      len(errors),
+		NoOp:        len(noOps),
+		Moved:       succeeded,
+		Errors:      errors,
+		Results:     append(outcomes.results(succeeded, errors), outcomes.noOps(noOps)...),
+		AddedTo:     addInfo,
+		RemovedFrom: removeInfo,
+	}
+	if err := formatter().Format(os.Stdout, result); err != nil {
+		return err
+	}
+	if len(errors) > 0 {
+		return exitError("partial_failure", "one or more emails failed to move", retryHint(cmd, errors))
+	}
+	return nil
+}
+
+// resolveMailboxes resolves each of names as --mailbox does.
+func resolveMailboxes(c *client.Client, names []string) ([]*mailbox.Mailbox, error) {
+	mailboxes := make([]*mailbox.Mailbox, 0, len(names))
+	for _, name := range names {
+		mb, err := c.ResolveMailbox(name)
+		if err != nil {
+			return nil, exitError("not_found", err.Error(), mailboxHint(err))
+		}
+		mailboxes = append(mailboxes, mb)
+	}
+	return mailboxes, nil
+}
+
+// mailboxDestinations returns the IDs of mailboxes and how they are
+// reported.
+func mailboxDestinations(mailboxes []*mailbox.Mailbox) ([]jmap.ID, []types.DestinationInfo) {
+	ids := make([]jmap.ID, len(mailboxes))
+	info := make([]types.DestinationInfo, len(mailboxes))
+	for i, mb := range mailboxes {
+		ids[i] = mb.ID
+		info[i] = types.DestinationInfo{ID: string(mb.ID), Name: mb.Name}
+	}
+	return ids, info
+}
+
+// findMoveTarget resolves a move destination by role, name, or ID, then as a
+// mailbox path. When the path does not fully exist it returns the deepest
+// existing mailbox on it, or nil, and the names still to be created.
+func findMoveTarget(c *client.Client, target string) (*mailbox.Mailbox, []string, error) {
+	if mb, err := c.ResolveMailbox(target); err == nil {
+		return mb, nil, nil
+	}
+	return c.ResolveMailboxPath(target)
+}
+
 func init() {
-	moveCmd.Flags().String("to", "", "target mailbox name or ID (required)")
+	moveCmd.Flags().String("to", "", "target mailbox name or ID, replacing all of an email's mailboxes")
+	moveCmd.Flags().StringArray("add-to", nil, "add emails to this mailbox, keeping their others (repeatable)")
+	moveCmd.Flags().StringArray("remove-from", nil, "remove emails from this mailbox, keeping their others (repeatable)")
 	moveCmd.Flags().BoolP("dry-run", "n", false, "preview affected emails without making changes")
+	addVerboseFlag(moveCmd)
+	addIDsFileFlag(moveCmd)
+	moveCmd.Flags().Bool("create-missing", false, "create the destination mailbox and its parents if they do not exist")
 	addFilterFlags(moveCmd)
+	addFromLastFlag(moveCmd)
 	rootCmd.AddCommand(moveCmd)
 }
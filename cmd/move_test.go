@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+func TestMove_CreateMissingCreatesPath(t *testing.T) {
+	server := newJMAPMockServer(t,
+		[]map[string]any{
+			{"id": "mb-inbox", "name": "Inbox", "role": "inbox"},
+			{"id": "mb-clients", "name": "Clients"},
+		},
+		[]map[string]any{{"id": "M1", "threadId": "T1", "mailboxIds": map[string]bool{"mb-inbox": true}}},
+		nil,
+	)
+
+	args := commandArgsForServer(t, server.server.URL, "move", "M1", "--to", "Clients/Acme 2025/Invoices", "--create-missing")
+	if err := os.WriteFile(args[1], []byte("mailbox_write: true\n"), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	stdout, stderr, err := runCLICommand(t, args)
+	if err != nil {
+		t.Fatalf("move failed: %v\n%s", err, stderr)
+	}
+
+	var result types.MoveResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, stdout)
+	}
+	if want := []string{"Clients/Acme 2025", "Clients/Acme 2025/Invoices"}; !reflect.DeepEqual(result.CreatedMailboxes, want) {
+		t.Errorf("expected created mailboxes %v, got %v", want, result.CreatedMailboxes)
+	}
+	if result.Destination == nil || result.Destination.Name != "Invoices" || !reflect.DeepEqual(result.Moved, []string{"M1"}) {
+		t.Errorf("expected M1 moved to Invoices, got %+v", result)
+	}
+	if server.count("Mailbox/set") != 2 {
+		t.Errorf("expected one Mailbox/set per missing mailbox, got %d", server.count("Mailbox/set"))
+	}
+	if parent := server.mailboxes[3]["parentId"]; parent != server.mailboxes[2]["id"] {
+		t.Errorf("expected Invoices to be created under Acme 2025, got parent %v", parent)
+	}
+}
+
+func TestMove_CreateMissingNeedsMailboxWrite(t *testing.T) {
+	server := newJMAPMockServer(t, nil, nil, nil)
+
+	args := commandArgsForServer(t, server.server.URL, "move", "M1", "--to", "Clients/Acme 2025", "--create-missing")
+	_, stderr, err := runCLICommand(t, args)
+	if !errors.Is(err, ErrSilent) || !strings.Contains(stderr, "forbidden_operation") {
+		t.Fatalf("expected forbidden_operation, got: %v\n%s", err, stderr)
+	}
+	if server.count("Mailbox/set") != 0 {
+		t.Errorf("expected no Mailbox/set, got %d", server.count("Mailbox/set"))
+	}
+}
+
+func TestMove_MissingTargetWithoutCreateMissing(t *testing.T) {
+	server := newJMAPMockServer(t, []map[string]any{{"id": "mb-inbox", "name": "Inbox", "role": "inbox"}}, nil, nil)
+
+	args := commandArgsForServer(t, server.server.URL, "move", "M1", "--to", "Clients/Acme 2025")
+	_, stderr, err := runCLICommand(t, args)
+	if !errors.Is(err, ErrSilent) || !strings.Contains(stderr, "--create-missing") {
+		t.Fatalf("expected not_found suggesting --create-missing, got: %v\n%s", err, stderr)
+	}
+}
+
+func TestMove_AddToAndRemoveFrom(t *testing.T) {
+	server := newJMAPMockServer(t,
+		[]map[string]any{
+			{"id": "mb-inbox", "name": "Inbox", "role": "inbox"},
+			{"id": "mb-receipts", "name": "Receipts"},
+		},
+		[]map[string]any{
+			{"id": "M1", "threadId": "T1", "mailboxIds": map[string]bool{"mb-inbox": true}},
+			{"id": "M2", "threadId": "T2", "mailboxIds": map[string]bool{"mb-receipts": true}},
+		},
+		nil,
+	)
+
+	args := commandArgsForServer(t, server.server.URL, "move", "M1", "M2", "--add-to", "Receipts", "--remove-from", "inbox")
+	stdout, stderr, err := runCLICommand(t, args)
+	if err != nil {
+		t.Fatalf("move failed: %v\n%s", err, stderr)
+	}
+
+	var result types.MoveResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, stdout)
+	}
+	if !reflect.DeepEqual(result.Moved, []string{"M1"}) || result.NoOp != 1 {
+		t.Errorf("expected M1 moved and M2 skipped, got %+v", result)
+	}
+	if len(result.AddedTo) != 1 || result.AddedTo[0].Name != "Receipts" ||
+		len(result.RemovedFrom) != 1 || result.RemovedFrom[0].ID != "mb-inbox" || result.Destination != nil {
+		t.Errorf("expected Receipts added and Inbox removed, got %+v", result)
+	}
+
+	_, stderr, _ = runCLICommand(t, append(args, "--explain"))
+	for _, want := range []string{`"mailboxIds/mb-receipts": true`, `"mailboxIds/mb-inbox": null`} {
+		if !strings.Contains(stderr, want) {
+			t.Errorf("expected %s in the patch, got: %s", want, stderr)
+		}
+	}
+}
+
+func TestMove_AddToRejectsConflicts(t *testing.T) {
+	server := newJMAPMockServer(t,
+		[]map[string]any{
+			{"id": "mb-inbox", "name": "Inbox", "role": "inbox"},
+			{"id": "mb-trash", "name": "Trash", "role": "trash"},
+		},
+		nil,
+		nil,
+	)
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"move", "M1", "--to", "inbox", "--add-to", "inbox"}, "cannot combine --to with --add-to or --remove-from"},
+		{[]string{"move", "M1", "--add-to", "inbox", "--remove-from", "Inbox"}, "mailbox Inbox is in both --add-to and --remove-from"},
+		{[]string{"move", "M1", "--add-to", "trash"}, "forbidden_operation"},
+		{[]string{"move", "M1", "--add-to", "inbox", "--create-missing"}, "--create-missing only works with --to"},
+	}
+	for _, tt := range tests {
+		_, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, tt.args...))
+		if !errors.Is(err, ErrSilent) || !strings.Contains(stderr, tt.want) {
+			t.Errorf("%v: expected %q, got: %v\n%s", tt.args, tt.want, err, stderr)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+var muteCmd = &cobra.Command{
+	Use:               "mute <thread-or-email-id>...",
+	Short:             "Mute threads so list and search hide them",
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeEmailIDs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runMute(cmd, args, true)
+	},
+}
+
+// runMute sets or clears the $muted keyword on every email in the threads
+// named by args, which may be thread IDs or IDs of emails in them.
+func runMute(cmd *cobra.Command, args []string, mute bool) error {
+	c, err := newClient()
+	if err != nil {
+		return exitError("authentication_failed", err.Error(),
+			"Check your credential command or the token it returns")
+	}
+
+	ids, notFound, err := c.ThreadMemberIDs(args)
+	if err != nil {
+		return exitError("jmap_error", err.Error(), "")
+	}
+
+	action := "unmute"
+	if mute {
+		action = "mute"
+	}
+
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	if dryRun {
+		return dryRunPreview(c, ids, action, nil)
+	}
+
+	outcomes := recordOutcomes(cmd, c)
+	var succeeded, errors []string
+	if mute {
+		succeeded, errors = c.SetMuted(ids)
+	} else {
+		succeeded, errors = c.SetUnmuted(ids)
+	}
+	for _, id := range notFound {
+		errors = append(errors, id+": not found")
+	}
+
+	result := types.MoveResult{
+		Matched:   len(ids),
+		Processed: len(succeeded) + len(errors),
+		Failed:    len(errors),
+		Errors:    errors,
+		Results:   outcomes.results(succeeded, errors),
+	}
+	if mute {
+		result.Muted = succeeded
+	} else {
+		result.Unmuted = succeeded
+	}
+
+	if err := formatter().Format(os.Stdout, result); err != nil {
+		return err
+	}
+
+	if len(errors) > 0 {
+		return exitError("partial_failure", "one or more threads failed to "+action, "")
+	}
+
+	return nil
+}
+
+func init() {
+	muteCmd.Flags().BoolP("dry-run", "n", false, "preview affected emails without making changes")
+	addVerboseFlag(muteCmd)
+	rootCmd.AddCommand(muteCmd)
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// outputFile is the pending --output file of the running command, if any.
+var outputFile *atomicOutput
+
+// outputErr records a failure to put the --output file in place, which
+// happens after the command has returned.
+var outputErr error
+
+// atomicOutput redirects stdout to a temporary file beside path, renamed
+// into place when the command finishes so that readers of path never see
+// a partial result.
+type atomicOutput struct {
+	path   string
+	tmp    *os.File
+	stdout *os.File
+}
+
+// startOutputFile begins redirecting stdout for the global --output flag.
+// Commands with their own --output flag shadow the global one and are left
+// alone, as is --output -.
+func startOutputFile(cmd *cobra.Command) error {
+	flag := rootCmd.PersistentFlags().Lookup("output")
+	if !flag.Changed || flag.Value.String() == "-" {
+		return nil
+	}
+	if cmd == watchCmd || cmd == mockServerCmd || cmd == serveCmd || cmd == daemonCmd {
+		return exitError("general_error", "--output cannot be used with fm "+cmd.Name(),
+			"Redirect the output stream instead")
+	}
+
+	path := flag.Value.String()
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return exitError("general_error", "cannot create output file: "+err.Error(), "")
+	}
+	if info, err := os.Stat(path); err == nil {
+		_ = tmp.Chmod(info.Mode().Perm())
+	}
+
+	outputFile = &atomicOutput{path: path, tmp: tmp, stdout: os.Stdout}
+	os.Stdout = tmp
+	return nil
+}
+
+// finishOutputFile restores stdout and renames the --output file into
+// place. A command that wrote nothing, such as one that failed before
+// printing a result, leaves any existing file untouched.
+func finishOutputFile() {
+	a := outputFile
+	if a == nil {
+		return
+	}
+	outputFile = nil
+	os.Stdout = a.stdout
+
+	info, err := a.tmp.Stat()
+	if closeErr := a.tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && info.Size() == 0 {
+		_ = os.Remove(a.tmp.Name())
+		return
+	}
+	if err == nil {
+		err = os.Rename(a.tmp.Name(), a.path)
+	}
+	if err != nil {
+		_ = os.Remove(a.tmp.Name())
+		outputErr = exitError("general_error", fmt.Sprintf("cannot write %s: %v", a.path, err), "")
+	}
+}
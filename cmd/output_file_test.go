@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOutputFlag_WritesResultToFile(t *testing.T) {
+	server := newJMAPMockServer(t, nil, nil, nil)
+	path := filepath.Join(t.TempDir(), "accounts.json")
+
+	args := commandArgsForServer(t, server.server.URL, "--output", path, "accounts")
+	stdout, stderr, err := runCLICommand(t, args)
+	if err != nil {
+		t.Fatalf("expected success, got: %v\nstderr=%s", err, stderr)
+	}
+	if stdout != "" {
+		t.Errorf("expected nothing on stdout, got: %s", stdout)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read output file: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "[") {
+		t.Errorf("expected a JSON array in the output file, got: %s", data)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("expected only the output file, found %d entries", len(entries))
+	}
+}
+
+func TestOutputFlag_FailureKeepsExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "list.json")
+	if err := os.WriteFile(path, []byte("previous"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err := runCLICommand(t, []string{"list", "--flagged", "--unflagged", "--output", path})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "previous" {
+		t.Errorf("expected the previous file to be kept, got %q (err %v)", data, err)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("expected the temporary file to be removed, found %d entries", len(entries))
+	}
+}
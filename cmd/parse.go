@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/client"
+)
+
+var parseCmd = &cobra.Command{
+	Use:   "parse <file.eml>",
+	Short: "Read a local .eml file as if it were an email in the account",
+	Long: `Read a message saved as a .eml file, such as an email forwarded as an
+attachment (saved with fm download) or one from a local archive. The file is
+uploaded and parsed by the server with Email/parse, then shown like fm read
+output. Use - to read the message from stdin.
+
+The message is not added to any mailbox. A parsed message has no email ID,
+and its date is taken from its Date header.
+
+  fm parse forwarded.eml --format text
+  fm download M1 --dir /tmp/fwd && fm parse /tmp/fwd/original.eml`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		preferHTML, _ := cmd.Flags().GetBool("html")
+		rawHeaders, _ := cmd.Flags().GetBool("raw-headers")
+		rawCharset, _ := cmd.Flags().GetBool("raw-charset")
+		includeInline, _ := cmd.Flags().GetBool("include-inline")
+
+		var message io.Reader = os.Stdin
+		if args[0] != "-" {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return exitError("general_error", err.Error(), "")
+			}
+			defer f.Close()
+			message = f
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your credential command or the token it returns")
+		}
+
+		c.SetRawCharset(rawCharset)
+		detail, err := c.ParseEmail(message, preferHTML, rawHeaders)
+		if errors.Is(err, client.ErrNotParsable) {
+			return exitError("general_error", args[0]+": "+err.Error(), "Check that the file is a complete .eml message")
+		}
+		if err != nil {
+			return exitError("jmap_error", err.Error(), "")
+		}
+		if !includeInline {
+			hideInlineParts(&detail)
+		}
+
+		return formatter().Format(os.Stdout, detail)
+	},
+}
+
+func init() {
+	parseCmd.Flags().Bool("html", false, "prefer HTML body (default: plain text)")
+	parseCmd.Flags().Bool("raw-headers", false, "include all raw headers")
+	parseCmd.Flags().Bool("include-inline", false, "list inline parts such as signature logos with the attachments")
+	parseCmd.Flags().Bool("raw-charset", false, "show bodies as the server decoded them, without charset repair")
+	rootCmd.AddCommand(parseCmd)
+}
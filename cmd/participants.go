@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var participantsCmd = &cobra.Command{
+	Use:   "participants [thread-or-email-id...]",
+	Short: "List the addresses in a conversation with counts",
+	Long: `List every address in the From, To, and Cc fields of a set of emails, with
+the number of emails each appears in, sorted by count descending.
+
+Thread IDs and email IDs cover every email in their threads. Filter flags
+or --from-last cover only the matching emails.
+
+  fm participants T1
+  fm participants --subject "Offsite planning" --after 2026-09-01`,
+	Args:              cobra.ArbitraryArgs,
+	ValidArgsFunction: completeEmailIDs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateIDsOrFilters(cmd, args); err != nil {
+			return err
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your credential command or the token it returns")
+		}
+
+		var ids []string
+		if len(args) > 0 {
+			expanded, err := expandHandles(args)
+			if err != nil {
+				return err
+			}
+			var notFound []string
+			ids, notFound, err = c.ThreadMemberIDs(expanded)
+			if err != nil {
+				return exitError("jmap_error", err.Error(), "")
+			}
+			for _, id := range notFound {
+				warn("not_found", "thread or email "+id+" not found", "")
+			}
+			if len(ids) == 0 {
+				return exitError("not_found", "no emails found for the given IDs", "")
+			}
+		} else {
+			ids, err = resolveEmailIDs(cmd, args, c)
+			if err != nil {
+				return err
+			}
+		}
+
+		result, _, err := c.AggregateParticipants(ids)
+		if err != nil {
+			return exitError("jmap_error", err.Error(), "")
+		}
+
+		return formatter().Format(os.Stdout, result)
+	},
+}
+
+func init() {
+	addFilterFlags(participantsCmd)
+	addFromLastFlag(participantsCmd)
+	rootCmd.AddCommand(participantsCmd)
+}
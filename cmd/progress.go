@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// progressThreshold is the number of emails an action must affect before a
+// progress bar is shown; smaller actions finish too quickly to need one.
+const progressThreshold = 100
+
+// progressBarWidth is the number of cells in the bar itself.
+const progressBarWidth = 24
+
+// progressNow is the clock used for rates and ETAs; tests replace it.
+var progressNow = time.Now
+
+// progressPrinter returns a progress callback that draws a bar with the
+// chunks completed, the rate, and an ETA on stderr, or nil when stderr is
+// not a terminal or --no-progress is set, so that logs and pipes stay clean.
+func progressPrinter(label string) func(done, total int) {
+	if noProgress, _ := rootCmd.PersistentFlags().GetBool("no-progress"); noProgress || !isTerminal(os.Stderr) {
+		return nil
+	}
+	return newProgressBar(os.Stderr, label).update
+}
+
+// progressBar redraws one stderr line as chunks of a bulk action complete.
+type progressBar struct {
+	w         io.Writer
+	label     string
+	start     time.Time
+	chunks    int
+	chunkSize int
+}
+
+func newProgressBar(w io.Writer, label string) *progressBar {
+	return &progressBar{w: w, label: label, start: progressNow()}
+}
+
+func (p *progressBar) update(done, total int) {
+	if total <= progressThreshold {
+		return
+	}
+	p.chunks++
+	if p.chunkSize == 0 {
+		// Every chunk but the last is as large as the first.
+		p.chunkSize = max(done, 1)
+	}
+	totalChunks := (total + p.chunkSize - 1) / p.chunkSize
+
+	filled := progressBarWidth * done / total
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", progressBarWidth-filled)
+	line := fmt.Sprintf("%s [%s] %d/%d, chunk %d/%d", p.label, bar, done, total, p.chunks, totalChunks)
+
+	if elapsed := progressNow().Sub(p.start).Seconds(); elapsed > 0 {
+		rate := float64(done) / elapsed
+		line += fmt.Sprintf(", %.0f/s", rate)
+		if done < total && rate > 0 {
+			eta := time.Duration(float64(total-done) / rate * float64(time.Second))
+			line += ", ETA " + eta.Round(time.Second).String()
+		}
+	}
+
+	// \x1b[K clears what is left of a longer previous line.
+	fmt.Fprintf(p.w, "\r%s\x1b[K", line)
+	if done >= total {
+		fmt.Fprintln(p.w)
+	}
+}
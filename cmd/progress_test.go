@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestProgressBar(t *testing.T) {
+	now := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	orig := progressNow
+	t.Cleanup(func() { progressNow = orig })
+	progressNow = func() time.Time { return now }
+
+	var buf bytes.Buffer
+	p := newProgressBar(&buf, "Archiving")
+
+	now = now.Add(2 * time.Second)
+	p.update(100, 250)
+	want := "\rArchiving [=========               ] 100/250, chunk 1/3, 50/s, ETA 3s\x1b[K"
+	if buf.String() != want {
+		t.Errorf("first update:\n got %q\nwant %q", buf.String(), want)
+	}
+
+	now = now.Add(2 * time.Second)
+	p.update(200, 250)
+	buf.Reset()
+	now = now.Add(time.Second)
+	p.update(250, 250)
+	want = "\rArchiving [========================] 250/250, chunk 3/3, 50/s\x1b[K\n"
+	if buf.String() != want {
+		t.Errorf("final update:\n got %q\nwant %q", buf.String(), want)
+	}
+}
+
+func TestProgressBar_SmallActionsStaySilent(t *testing.T) {
+	var buf bytes.Buffer
+	p := newProgressBar(&buf, "Archiving")
+	p.update(50, 50)
+	if buf.Len() != 0 {
+		t.Errorf("expected no output for %d emails, got %q", 50, buf.String())
+	}
+}
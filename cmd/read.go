@@ -2,33 +2,145 @@ package cmd
 
 import (
 	"errors"
+	"io"
 	"os"
+	"strings"
+	"unicode"
 
 	"github.com/cboone/fm/internal/client"
+	"github.com/cboone/fm/internal/mimebody"
+	"github.com/cboone/fm/internal/remoteimg"
+	"github.com/cboone/fm/internal/signature"
+	"github.com/cboone/fm/internal/types"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 var readCmd = &cobra.Command{
 	Use:   "read <email-id>",
 	Short: "Read the full content of an email",
-	Args:  cobra.ExactArgs(1),
+	Long: `Read the full content of an email.
+
+With --html and --output, the HTML body is written as sent to a file (or to
+stdout with "-") instead of being formatted, for messages whose layout
+matters such as tickets and boarding passes. --inline-images replaces cid:
+references to inline images with data: URIs so the file renders on its own.
+
+  fm read M1 --html --output ticket.html --inline-images
+
+fm never loads the remote images an HTML body references, since fetching
+them tells the sender that, when, and where the email was read.
+--block-remote adds a Content-Security-Policy to the file --output writes
+so a browser opening it does not load them either. --save-images downloads
+them into a directory when they are actually needed; with
+remote_image_domains set in the config, only images on those domains (and
+their subdomains) are fetched.
+
+  fm read M1 --save-images ./images
+
+Bodies longer than --max-body-bytes (1 MiB by default) are cut short by the
+server and marked as truncated. --full, or --max-body-bytes 0, reads the
+whole body.
+
+Bodies declared in a legacy charset (ISO-8859-*, Windows-1252, Shift_JIS,
+GBK, ...) are decoded to UTF-8 from the raw part; --raw-charset shows the
+body as the server decoded it instead.
+
+Inline parts shown inside the HTML body, such as signature logos, are left
+out of the attachment list; --include-inline lists them too.
+
+--verify checks a multipart/signed or S/MIME signed message: S/MIME with
+openssl against the system trust store, PGP with gpg against your keyring.
+The result is reported as a Signature line, or the signature field in JSON.
+
+--decrypt pipes a PGP/MIME encrypted message through gpg and shows the
+decrypted content in place of the body. The plaintext is kept in memory
+only; --decrypt --output writes the decrypted message to a file.
+
+  fm read M1 --decrypt --verify`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeEmailID,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		c, err := newClient()
+		ids, err := expandHandles(args)
 		if err != nil {
-			return exitError("authentication_failed", err.Error(),
-				"Check your credential command or the token it returns")
+			return err
+		}
+		if len(ids) != 1 {
+			return exitError("general_error", "read accepts exactly one email", "Use a single handle such as %3, not a range")
 		}
 
-		emailID := args[0]
+		emailID := ids[0]
 		preferHTML, _ := cmd.Flags().GetBool("html")
 		rawHeaders, _ := cmd.Flags().GetBool("raw-headers")
 		showThread, _ := cmd.Flags().GetBool("thread")
+		output, _ := cmd.Flags().GetString("output")
+		inlineImages, _ := cmd.Flags().GetBool("inline-images")
+		maxBodyBytes, _ := cmd.Flags().GetInt("max-body-bytes")
+		full, _ := cmd.Flags().GetBool("full")
+		rawCharset, _ := cmd.Flags().GetBool("raw-charset")
+		includeInline, _ := cmd.Flags().GetBool("include-inline")
+		verify, _ := cmd.Flags().GetBool("verify")
+		decrypt, _ := cmd.Flags().GetBool("decrypt")
+		saveImages, _ := cmd.Flags().GetString("save-images")
+		blockRemote, _ := cmd.Flags().GetBool("block-remote")
+
+		if output != "" && !preferHTML && !decrypt {
+			return exitError("general_error", "--output requires --html or --decrypt",
+				"--output writes the HTML body as sent, or the decrypted message")
+		}
+		if output != "" && showThread {
+			return exitError("general_error", "cannot combine --output with --thread", "")
+		}
+		if verify && output != "" {
+			return exitError("general_error", "cannot combine --verify with --output", "")
+		}
+		if inlineImages && output == "" {
+			return exitError("general_error", "--inline-images requires --output", "")
+		}
+		if blockRemote && (output == "" || decrypt) {
+			return exitError("general_error", "--block-remote requires --html --output", "")
+		}
+		if saveImages != "" && (output != "" || showThread || verify || decrypt) {
+			return exitError("general_error", "cannot combine --save-images with --output, --thread, --verify, or --decrypt", "")
+		}
+		if maxBodyBytes < 0 {
+			return exitError("general_error", "--max-body-bytes must be zero or greater", "")
+		}
+		if full {
+			maxBodyBytes = 0
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your credential command or the token it returns")
+		}
+
+		if output != "" && decrypt {
+			return writeDecrypted(c, emailID, output)
+		}
+		if output != "" {
+			return writeHTMLBody(c, emailID, output, inlineImages, blockRemote)
+		}
+		if saveImages != "" {
+			return saveRemoteImages(c, emailID, saveImages)
+		}
+		c.SetMaxBodyBytes(uint64(maxBodyBytes))
+		c.SetRawCharset(rawCharset)
 
 		if showThread {
 			tv, err := c.ReadThread(emailID, preferHTML, rawHeaders)
 			if err != nil {
 				return exitError(readErrorCode(err), err.Error(), "")
 			}
+			if verify || decrypt {
+				if err := checkRawMessage(c, &tv.Email, preferHTML, verify, decrypt); err != nil {
+					return err
+				}
+			}
+			if !includeInline {
+				hideInlineParts(&tv.Email)
+			}
 			return formatter().Format(os.Stdout, tv)
 		}
 
@@ -36,11 +148,193 @@ var readCmd = &cobra.Command{
 		if err != nil {
 			return exitError(readErrorCode(err), err.Error(), "")
 		}
+		if verify || decrypt {
+			if err := checkRawMessage(c, &detail, preferHTML, verify, decrypt); err != nil {
+				return err
+			}
+		}
+		if !includeInline {
+			hideInlineParts(&detail)
+		}
 
 		return formatter().Format(os.Stdout, detail)
 	},
 }
 
+// writeHTMLBody writes an email's HTML body to output, or to stdout when
+// output is "-".
+func writeHTMLBody(c *client.Client, emailID, output string, inlineImages, blockRemote bool) error {
+	html, err := c.ReadHTMLBody(emailID, inlineImages)
+	if errors.Is(err, client.ErrNoHTMLBody) {
+		return exitError("not_found", err.Error(), "Read the plain-text body without --output")
+	}
+	if err != nil {
+		return exitError(readErrorCode(err), err.Error(), "")
+	}
+	if blockRemote {
+		html = remoteimg.Block(html)
+	}
+
+	if output == "-" {
+		_, err := io.WriteString(os.Stdout, html)
+		return err
+	}
+	if err := os.WriteFile(output, []byte(html), 0o600); err != nil {
+		return exitError("general_error", err.Error(), "")
+	}
+	return formatter().Format(os.Stdout, types.HTMLBodyResult{
+		ID:            emailID,
+		Output:        output,
+		Bytes:         len(html),
+		InlineImages:  inlineImages,
+		RemoteBlocked: blockRemote,
+	})
+}
+
+// saveRemoteImages downloads the remote images an email's HTML body
+// references into dir, skipping hosts outside remote_image_domains.
+func saveRemoteImages(c *client.Client, emailID, dir string) error {
+	html, err := c.ReadHTMLBody(emailID, false)
+	if errors.Is(err, client.ErrNoHTMLBody) {
+		return exitError("not_found", err.Error(), "Only HTML bodies reference remote images")
+	}
+	if err != nil {
+		return exitError(readErrorCode(err), err.Error(), "")
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return exitError("general_error", err.Error(), "")
+	}
+
+	domains := remoteImageDomains()
+	result := types.SavedImagesResult{
+		ID:      emailID,
+		Dir:     dir,
+		Saved:   []types.SavedImage{},
+		Blocked: []string{},
+		Failed:  []types.ImageFailure{},
+	}
+	for i, u := range remoteimg.Find(html) {
+		if !remoteimg.Allowed(u, domains) {
+			result.Blocked = append(result.Blocked, u)
+			continue
+		}
+		file, n, err := remoteimg.Save(u, domains, dir, remoteimg.FileName(i+1, u))
+		if err != nil {
+			result.Failed = append(result.Failed, types.ImageFailure{URL: u, Error: err.Error()})
+			continue
+		}
+		result.Saved = append(result.Saved, types.SavedImage{URL: u, File: file, Bytes: n})
+	}
+	return formatter().Format(os.Stdout, result)
+}
+
+// remoteImageDomains returns the domains in remote_image_domains, which
+// may be separated by spaces or commas.
+func remoteImageDomains() []string {
+	return strings.FieldsFunc(viper.GetString("remote_image_domains"), func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+}
+
+// checkRawMessage downloads the raw message for d and applies --decrypt
+// and --verify to it. A decrypted message replaces the body and
+// attachments of d, and its signature is the one checked: either the
+// signature gpg found while decrypting or a signed part inside.
+func checkRawMessage(c *client.Client, d *types.EmailDetail, preferHTML, verify, decrypt bool) error {
+	raw, err := downloadRawEmail(c, d.ID)
+	if err != nil {
+		return exitError(readErrorCode(err), err.Error(), "")
+	}
+
+	signed := raw
+	if decrypt {
+		plaintext, inner, err := signature.Decrypt(raw)
+		switch {
+		case errors.Is(err, signature.ErrNotEncrypted):
+			// Nothing to decrypt; the body is already readable.
+		case errors.Is(err, signature.ErrNoGPG):
+			return exitError("general_error", err.Error(), "Install GnuPG to decrypt PGP/MIME messages")
+		case err != nil:
+			return exitError("general_error", err.Error(), "Check that the secret key is in your gpg keyring")
+		default:
+			body, err := mimebody.Render(plaintext, preferHTML)
+			if err != nil {
+				return exitError("general_error", err.Error(), "")
+			}
+			d.Body, d.Attachments = body.Text, body.Attachments
+			d.BodyTruncated = false
+			d.Decrypted = true
+			if verify && inner.Status != "" {
+				d.Signature = &inner
+				return nil
+			}
+			signed = plaintext
+		}
+	}
+	if verify {
+		sig := signature.Verify(signed)
+		d.Signature = &sig
+	}
+	return nil
+}
+
+// downloadRawEmail returns the raw RFC 5322 message of an email.
+func downloadRawEmail(c *client.Client, emailID string) ([]byte, error) {
+	body, err := c.DownloadRawEmail(emailID)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
+
+// writeDecrypted decrypts a PGP/MIME message and writes the decrypted MIME
+// entity to output, or to stdout when output is "-". This is the only way
+// fm stores decrypted content.
+func writeDecrypted(c *client.Client, emailID, output string) error {
+	raw, err := downloadRawEmail(c, emailID)
+	if err != nil {
+		return exitError(readErrorCode(err), err.Error(), "")
+	}
+	plaintext, _, err := signature.Decrypt(raw)
+	switch {
+	case errors.Is(err, signature.ErrNotEncrypted):
+		return exitError("general_error", "email "+emailID+" is "+err.Error(), "")
+	case errors.Is(err, signature.ErrNoGPG):
+		return exitError("general_error", err.Error(), "Install GnuPG to decrypt PGP/MIME messages")
+	case err != nil:
+		return exitError("general_error", err.Error(), "Check that the secret key is in your gpg keyring")
+	}
+
+	if output == "-" {
+		_, err := os.Stdout.Write(plaintext)
+		return err
+	}
+	if err := os.WriteFile(output, plaintext, 0o600); err != nil {
+		return exitError("general_error", err.Error(), "")
+	}
+	return formatter().Format(os.Stdout, types.DecryptedMessageResult{
+		ID:     emailID,
+		Output: output,
+		Bytes:  len(plaintext),
+	})
+}
+
+// hideInlineParts drops inline parts such as signature logos from the
+// attachments of d, counting them in HiddenInline.
+func hideInlineParts(d *types.EmailDetail) {
+	kept := make([]types.Attachment, 0, len(d.Attachments))
+	for _, a := range d.Attachments {
+		if a.Inline {
+			d.HiddenInline++
+			continue
+		}
+		kept = append(kept, a)
+	}
+	d.Attachments = kept
+}
+
 // readErrorCode returns "not_found" for missing-email errors and "jmap_error" for others.
 func readErrorCode(err error) string {
 	if errors.Is(err, client.ErrNotFound) {
@@ -53,5 +347,15 @@ func init() {
 	readCmd.Flags().Bool("html", false, "prefer HTML body (default: plain text)")
 	readCmd.Flags().Bool("raw-headers", false, "include all raw headers")
 	readCmd.Flags().Bool("thread", false, "show all emails in the same thread")
+	readCmd.Flags().String("output", "", "write the HTML body as sent, or with --decrypt the decrypted message, to this file (\"-\" for stdout)")
+	readCmd.Flags().Bool("inline-images", false, "with --output, embed cid: images as data: URIs")
+	readCmd.Flags().Bool("block-remote", false, "with --output, stop browsers from loading remote images and other remote content")
+	readCmd.Flags().String("save-images", "", "download the remote images of the HTML body into this directory")
+	readCmd.Flags().Int("max-body-bytes", 1<<20, "truncate bodies longer than this many bytes (0 for no limit)")
+	readCmd.Flags().Bool("full", false, "read the whole body, ignoring --max-body-bytes")
+	readCmd.Flags().Bool("include-inline", false, "list inline parts such as signature logos with the attachments")
+	readCmd.Flags().Bool("decrypt", false, "decrypt a PGP/MIME encrypted message with gpg")
+	readCmd.Flags().Bool("verify", false, "check the S/MIME or PGP signature (needs openssl or gpg)")
+	readCmd.Flags().Bool("raw-charset", false, "show bodies as the server decoded them, without charset repair")
 	rootCmd.AddCommand(readCmd)
 }
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+func TestRead_SaveImagesHonorsAllowlist(t *testing.T) {
+	images := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/gif")
+		_, _ = w.Write([]byte("GIF89a"))
+	}))
+	defer images.Close()
+
+	server := newJMAPMockServer(t,
+		[]map[string]any{{"id": "mb-inbox", "name": "Inbox", "role": "inbox"}},
+		[]map[string]any{{
+			"id":       "M1",
+			"htmlBody": []map[string]any{{"partId": "1", "type": "text/html"}},
+			"bodyValues": map[string]any{"1": map[string]any{
+				"value": `<img src="` + images.URL + `/logo.gif"><img src="https://tracker.invalid/open.gif">`,
+			}},
+		}},
+		nil,
+	)
+	t.Setenv("FM_REMOTE_IMAGE_DOMAINS", "127.0.0.1")
+	dir := t.TempDir()
+
+	stdout, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "read", "M1", "--save-images", dir))
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n%s", err, stderr)
+	}
+
+	var result types.SavedImagesResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, stdout)
+	}
+	if len(result.Saved) != 1 || result.Saved[0].Bytes != 6 {
+		t.Fatalf("expected the allowed image saved, got %+v", result)
+	}
+	if data, _ := os.ReadFile(result.Saved[0].File); string(data) != "GIF89a" {
+		t.Errorf("unexpected image contents %q", data)
+	}
+	if len(result.Blocked) != 1 || result.Blocked[0] != "https://tracker.invalid/open.gif" {
+		t.Errorf("expected the tracker blocked, got %v", result.Blocked)
+	}
+}
@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/mailbox"
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/cache"
+	"github.com/cboone/fm/internal/client"
+	"github.com/cboone/fm/internal/paths"
+	"github.com/cboone/fm/internal/types"
+)
+
+var reportPhishingCmd = &cobra.Command{
+	Use:   "report-phishing [email-id...]",
+	Short: "Move emails to Junk and mark them as phishing",
+	Long: `Move emails to the Junk mailbox and set the $junk and $phishing
+keywords in one step.
+
+With --evidence-dir, the raw message of each email is saved there as
+<email-id>.eml before anything is changed. If any message cannot be saved,
+no emails are moved.
+
+Every report is also appended, as one line of JSON, to phishing-audit.jsonl
+in the cache directory: when, which account, and the emails reported, failed,
+and saved as evidence. The log is only ever appended to.`,
+	Args:              cobra.ArbitraryArgs,
+	ValidArgsFunction: completeEmailIDs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var ids []string
+		var err error
+		if path := idsFile(cmd); path != "" {
+			if len(args) > 0 {
+				return exitError("general_error", "cannot combine email IDs with --ids-file",
+					"Use either email IDs or --ids-file, not both")
+			}
+			ids, err = readIDsFile(path)
+		} else {
+			if len(args) == 0 {
+				return exitError("general_error", "no emails specified",
+					"Provide email IDs as arguments or use --ids-file")
+			}
+			ids, err = expandHandles(args)
+		}
+		if err != nil {
+			return err
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your credential command or the token it returns")
+		}
+
+		junkMB, err := c.GetMailboxByRole(mailbox.RoleJunk)
+		if err != nil {
+			return exitError("not_found", "junk mailbox not found: "+err.Error(), "")
+		}
+		dest := &types.DestinationInfo{
+			ID:   string(junkMB.ID),
+			Name: junkMB.Name,
+		}
+
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		if dryRun {
+			return dryRunPreview(c, ids, "report-phishing", dest)
+		}
+
+		var evidence []string
+		if dir, _ := cmd.Flags().GetString("evidence-dir"); dir != "" {
+			evidence, err = saveEvidence(c, dir, ids)
+			if err != nil {
+				return err
+			}
+		}
+
+		c.SetProgress(progressPrinter("Reporting"))
+		outcomes := recordOutcomes(cmd, c)
+		succeeded, errors := c.ReportPhishing(ids, junkMB.ID)
+		recordPhishingAudit(c, junkMB.ID, succeeded, errors, evidence)
+
+		result := types.MoveResult{
+			Matched:     len(ids),
+			Processed:   len(succeeded) + len(errors),
+			Failed:      len(errors),
+			Phishing:    succeeded,
+			Evidence:    evidence,
+			Destination: dest,
+			Errors:      errors,
+			Results:     outcomes.results(succeeded, errors),
+		}
+
+		if err := formatter().Format(os.Stdout, result); err != nil {
+			return err
+		}
+
+		if len(errors) > 0 {
+			return exitError("partial_failure", "one or more emails failed to be reported as phishing", retryHint(cmd, errors))
+		}
+
+		return nil
+	},
+}
+
+// phishingAuditPath returns the append-only log of report-phishing runs.
+func phishingAuditPath() (string, error) {
+	return paths.CacheFile("phishing-audit.jsonl")
+}
+
+// recordPhishingAudit appends a record of the emails reported as phishing
+// to the audit log. As with the undo journal, failing to write it does not
+// fail the command, since the emails have already been moved; it is
+// reported as a warning.
+func recordPhishingAudit(c *client.Client, junkID jmap.ID, reported []string, failed []string, evidence []string) {
+	if len(reported) == 0 && len(failed) == 0 {
+		return
+	}
+	entry := cache.AuditEntry{
+		Command:     "report-phishing",
+		AccountID:   string(c.AccountID()),
+		At:          time.Now().UTC(),
+		Destination: string(junkID),
+		Reported:    append([]string{}, reported...),
+		Failed:      failed,
+		Evidence:    evidence,
+	}
+	path, err := phishingAuditPath()
+	if err == nil {
+		err = cache.AppendAudit(path, entry)
+	}
+	if err != nil {
+		warn("general_error", "could not record phishing audit log: "+err.Error(),
+			"The emails were reported; only the audit record is missing")
+	}
+}
+
+// saveEvidence writes the raw message of each email to dir as <id>.eml and
+// returns the paths written.
+func saveEvidence(c *client.Client, dir string, ids []string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, exitError("general_error", "cannot create evidence directory: "+err.Error(), "")
+	}
+
+	paths := make([]string, 0, len(ids))
+	for _, id := range ids {
+		body, err := c.DownloadRawEmail(id)
+		if err != nil {
+			return nil, exitError(readErrorCode(err), err.Error(), "No emails were moved")
+		}
+
+		path := filepath.Join(dir, id+".eml")
+		err = writeEvidenceFile(path, body)
+		_ = body.Close()
+		if err != nil {
+			return nil, exitError("general_error", fmt.Sprintf("cannot save evidence for %s: %v", id, err), "No emails were moved")
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+func writeEvidenceFile(path string, body io.Reader) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, body); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func init() {
+	reportPhishingCmd.Flags().BoolP("dry-run", "n", false, "preview affected emails without making changes")
+	addVerboseFlag(reportPhishingCmd)
+	addIDsFileFlag(reportPhishingCmd)
+	reportPhishingCmd.Flags().String("evidence-dir", "", "save the raw .eml of each email to this directory first")
+	rootCmd.AddCommand(reportPhishingCmd)
+}
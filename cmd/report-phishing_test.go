@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cboone/fm/internal/cache"
+)
+
+func TestReportPhishing_DryRunDoesNotMutate(t *testing.T) {
+	server := newJMAPMockServer(t,
+		[]map[string]any{{"id": "mb-junk", "name": "Junk", "role": "junk"}},
+		[]map[string]any{{
+			"id": "M1", "threadId": "T1", "subject": "Verify your account",
+			"receivedAt": "2026-02-14T10:30:00Z", "keywords": map[string]bool{},
+		}},
+		nil,
+	)
+
+	args := commandArgsForServer(t, server.server.URL, "report-phishing", "--dry-run", "M1")
+	stdout, stderr, err := runCLICommand(t, args)
+	if err != nil {
+		t.Fatalf("expected success, got: %v\nstderr=%s", err, stderr)
+	}
+	if !strings.Contains(stdout, `"operation": "report-phishing"`) {
+		t.Fatalf("expected dry-run operation in stdout, got: %s", stdout)
+	}
+	if server.count("Email/set") != 0 {
+		t.Fatalf("expected Email/set not to be called, got %d", server.count("Email/set"))
+	}
+}
+
+func TestReportPhishing_MovesToJunk(t *testing.T) {
+	cacheDir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheDir)
+	server := newJMAPMockServer(t,
+		[]map[string]any{{"id": "mb-junk", "name": "Junk", "role": "junk"}},
+		nil,
+		nil,
+	)
+
+	args := commandArgsForServer(t, server.server.URL, "report-phishing", "M1")
+	stdout, stderr, err := runCLICommand(t, args)
+	if err != nil {
+		t.Fatalf("expected success, got: %v\nstderr=%s", err, stderr)
+	}
+	if !strings.Contains(stdout, `"reported_as_phishing"`) {
+		t.Fatalf("expected reported_as_phishing in stdout, got: %s", stdout)
+	}
+	if server.count("Email/set") != 1 {
+		t.Fatalf("expected Email/set once, got %d", server.count("Email/set"))
+	}
+
+	data, err := os.ReadFile(filepath.Join(cacheDir, "fm", "phishing-audit.jsonl"))
+	if err != nil {
+		t.Fatalf("expected an audit log: %v", err)
+	}
+	var entry cache.AuditEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		t.Fatalf("decode audit log %q: %v", data, err)
+	}
+	if entry.Command != "report-phishing" || entry.Destination != "mb-junk" ||
+		len(entry.Reported) != 1 || entry.Reported[0] != "M1" {
+		t.Errorf("unexpected audit entry %+v", entry)
+	}
+}
+
+func TestReportPhishing_RequiresEmailID(t *testing.T) {
+	if _, _, err := runCLICommand(t, []string{"report-phishing"}); err == nil {
+		t.Fatal("expected error without an email ID")
+	}
+}
+
+func TestReportPhishing_AuditFailureIsAWarning(t *testing.T) {
+	// A file where the cache directory should be makes the log unwritable.
+	blocked := filepath.Join(t.TempDir(), "cache")
+	if err := os.WriteFile(blocked, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_CACHE_HOME", blocked)
+	server := newJMAPMockServer(t,
+		[]map[string]any{{"id": "mb-junk", "name": "Junk", "role": "junk"}},
+		nil,
+		nil,
+	)
+
+	args := commandArgsForServer(t, server.server.URL, "--errors-to", "stdout", "report-phishing", "M1")
+	stdout, stderr, err := runCLICommand(t, args)
+	if err != nil {
+		t.Fatalf("expected success, got: %v\nstderr=%s", err, stderr)
+	}
+	env := decodeEnvelope(t, stdout)
+	if !env.OK || env.Error != nil {
+		t.Errorf("expected the report to succeed, got %s", stdout)
+	}
+	if len(env.Warnings) != 1 || !strings.Contains(env.Warnings[0].Message, "audit log") {
+		t.Errorf("expected an audit log warning, got %s", stdout)
+	}
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/cboone/fm/internal/cache"
+	"github.com/cboone/fm/internal/paths"
+)
+
+// addIDsFileFlag registers --ids-file, which reads the emails to act on
+// from a file such as the retry file a partly failed bulk action writes.
+func addIDsFileFlag(cmd *cobra.Command) {
+	cmd.Flags().String("ids-file", "", "act on the email IDs listed in this file, one per line (- for stdin)")
+}
+
+// idsFile returns the --ids-file value, or "" when the flag is not
+// registered or not set.
+func idsFile(cmd *cobra.Command) string {
+	if cmd.Flags().Lookup("ids-file") == nil {
+		return ""
+	}
+	path, _ := cmd.Flags().GetString("ids-file")
+	return path
+}
+
+// readIDsFile reads email IDs from path, one per line, skipping blank lines
+// and lines starting with #.
+func readIDsFile(path string) ([]string, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, exitError("general_error", "cannot read --ids-file: "+err.Error(), "")
+		}
+		defer func() { _ = f.Close() }()
+		r = f
+	}
+
+	var ids []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		ids = append(ids, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, exitError("general_error", "cannot read --ids-file: "+err.Error(), "")
+	}
+	if len(ids) == 0 {
+		return nil, exitError("not_found", "no email IDs in "+path, "")
+	}
+	return ids, nil
+}
+
+// retryHint writes the IDs of the emails that failed in a bulk action to a
+// retry file in the cache directory and returns a hint with the command
+// that retries just those emails. args are the positional arguments the
+// command needs besides email IDs, such as the keyword of keyword set.
+// It returns "" when the file cannot be written.
+func retryHint(cmd *cobra.Command, errors []string, args ...string) string {
+	var failed []string
+	for _, e := range errors {
+		id, _, _ := strings.Cut(e, ": ")
+		if !slices.Contains(failed, id) {
+			failed = append(failed, id)
+		}
+	}
+	if len(failed) == 0 {
+		return ""
+	}
+
+	name := strings.ReplaceAll(strings.TrimPrefix(cmd.CommandPath(), rootCmd.Name()+" "), " ", "-")
+	path, err := paths.CacheFile("failed-" + name + ".txt")
+	if err != nil {
+		return ""
+	}
+	if err := cache.SaveRetry(path, failed); err != nil {
+		return ""
+	}
+
+	return fmt.Sprintf("Retry the %d failed email(s) with: %s", len(failed), retryCommand(cmd, args, path))
+}
+
+// retryCommand rebuilds the command line that was run, with its email
+// selection replaced by --ids-file path. Of the global flags only the
+// account selection is kept; the rest come from the environment or config
+// file on the next run as they did on this one.
+func retryCommand(cmd *cobra.Command, args []string, path string) string {
+	parts := []string{cmd.CommandPath()}
+	for _, arg := range args {
+		parts = append(parts, shellQuote(arg))
+	}
+	add := func(f *pflag.Flag) {
+		if f.Value.Type() == "bool" {
+			parts = append(parts, "--"+f.Name)
+		} else {
+			parts = append(parts, "--"+f.Name, shellQuote(f.Value.String()))
+		}
+	}
+	for _, name := range []string{"account", "account-id"} {
+		if f := rootCmd.PersistentFlags().Lookup(name); f != nil && f.Changed {
+			add(f)
+		}
+	}
+	cmd.LocalNonPersistentFlags().VisitAll(func(f *pflag.Flag) {
+		if f.Changed && !isSelectionFlag(cmd, f.Name) {
+			add(f)
+		}
+	})
+	parts = append(parts, "--ids-file", shellQuote(path))
+	return strings.Join(parts, " ")
+}
+
+// isSelectionFlag reports whether the flag name selects the emails to act
+// on, rather than saying what to do with them.
+func isSelectionFlag(cmd *cobra.Command, name string) bool {
+	switch name {
+	case "from-last", "ids-file":
+		return true
+	case "from":
+		return isSenderFromFilterFlag(cmd)
+	case "to":
+		return isRecipientToFilterFlag(cmd)
+	}
+	return slices.Contains(filterFlagNames, name)
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMove_PartialFailureWritesRetryFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", "")
+
+	server := newJMAPMockServer(t,
+		[]map[string]any{
+			{"id": "mb-inbox", "name": "Inbox", "role": "inbox"},
+			{"id": "mb-receipts", "name": "Receipts"},
+		},
+		[]map[string]any{
+			{"id": "M1", "threadId": "T1", "mailboxIds": map[string]bool{"mb-inbox": true}},
+		},
+		[]string{"M2"},
+	)
+
+	args := commandArgsForServer(t, server.server.URL, "move", "--to", "Receipts", "M1", "M2")
+	_, stderr, err := runCLICommand(t, args)
+	if err == nil {
+		t.Fatal("expected a partial failure")
+	}
+
+	path := filepath.Join(os.Getenv("HOME"), ".cache", "fm", "failed-move.txt")
+	data, readErr := os.ReadFile(path)
+	if readErr != nil {
+		t.Fatalf("expected a retry file: %v", readErr)
+	}
+	if string(data) != "M2\n" {
+		t.Errorf("retry file = %q, want only M2", data)
+	}
+	if !strings.Contains(stderr, "fm move --account-id A1 --to Receipts --ids-file "+path) {
+		t.Errorf("expected a retry hint, got: %s", stderr)
+	}
+
+	server.notFound = nil
+	stdout, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "move", "--to", "Receipts", "--ids-file", path))
+	if err != nil {
+		t.Fatalf("retry failed: %v\nstderr=%s", err, stderr)
+	}
+	if !strings.Contains(stdout, `"matched": 1`) || !strings.Contains(stdout, `"M2"`) {
+		t.Errorf("expected the retry to move M2 only, got: %s", stdout)
+	}
+}
+
+func TestIDsFile_CannotCombineWithIDs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ids.txt")
+	if err := os.WriteFile(path, []byte("M1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, stderr, err := runCLICommand(t, []string{"archive", "--ids-file", path, "M2"})
+	if err == nil || !strings.Contains(stderr, "cannot combine --ids-file") {
+		t.Errorf("expected a combination error, got %v: %s", err, stderr)
+	}
+}
+
+func TestReadIDsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ids.txt")
+	if err := os.WriteFile(path, []byte("# failed archive\nM1\n\n  M2  \n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ids, err := readIDsFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(ids, ",") != "M1,M2" {
+		t.Errorf("ids = %v, want [M1 M2]", ids)
+	}
+}
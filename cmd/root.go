@@ -5,9 +5,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"runtime"
 	"strings"
 	"time"
@@ -17,6 +17,7 @@ import (
 
 	"github.com/cboone/fm/internal/client"
 	"github.com/cboone/fm/internal/output"
+	"github.com/cboone/fm/internal/paths"
 )
 
 // ErrSilent is returned by exitError to indicate the error has already been printed.
@@ -40,25 +41,49 @@ Sending and deleting email are structurally disallowed.`,
 	}
 )
 
+// defaultSessionURL is the Fastmail JMAP session endpoint.
+const defaultSessionURL = "https://api.fastmail.com/jmap/session"
+
 // Execute runs the root command.
 func Execute() error {
-	return rootCmd.Execute()
+	outputErr = nil
+	if err := rootCmd.Execute(); err != nil {
+		if !errors.Is(err, ErrSilent) {
+			return envelopeUsageError(err)
+		}
+		return err
+	}
+	return outputErr
 }
 
 func init() {
 	cobra.OnInitialize(initConfig)
+	cobra.OnFinalize(finishEnvelope, finishOutputFile, finishTimings)
 
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default: ~/.config/fm/config.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default: see fm config path)")
 	rootCmd.PersistentFlags().String("credential-command", "", "shell command that prints the API token to stdout (default: OS keychain on macOS/Linux)")
-	rootCmd.PersistentFlags().String("session-url", "https://api.fastmail.com/jmap/session", "Fastmail session endpoint")
+	rootCmd.PersistentFlags().String("session-url", defaultSessionURL, "Fastmail session endpoint")
 	rootCmd.PersistentFlags().String("format", "json", "output format: json or text")
 	rootCmd.PersistentFlags().String("account-id", "", "Fastmail account ID (auto-detected if blank)")
+	rootCmd.PersistentFlags().String("account", "", "account to use, by name, email, or ID (see fm accounts)")
+	rootCmd.PersistentFlags().Bool("explain", false, "print each JMAP request to stderr; requests that change server state are not sent")
+	rootCmd.PersistentFlags().Bool("timings", false, "print the run time and counts of requests, retries, rate limits, and server errors to stderr")
+	rootCmd.PersistentFlags().Bool("ascii", false, "plain ASCII text output without color")
+	rootCmd.PersistentFlags().Bool("hyperlinks", false, "link subjects and mailbox names to the Fastmail web app in text output on terminals")
+	rootCmd.PersistentFlags().Bool("no-progress", false, "do not show progress bars for bulk actions on stderr")
+	rootCmd.PersistentFlags().Bool("redact", false, "mask email addresses, phone numbers, and long tokens or IDs in output")
+	rootCmd.PersistentFlags().String("output", "", "write the result to this file, replacing it atomically")
+	rootCmd.PersistentFlags().String("errors-to", "stderr", "where errors go: stderr, or stdout to print one JSON document {ok, result, error}")
 
 	for _, bind := range []struct{ key, flag string }{
 		{"credential_command", "credential-command"},
 		{"session_url", "session-url"},
 		{"format", "format"},
 		{"account_id", "account-id"},
+		{"account", "account"},
+		{"ascii", "ascii"},
+		{"hyperlinks", "hyperlinks"},
+		{"redact", "redact"},
 	} {
 		if err := viper.BindPFlag(bind.key, rootCmd.PersistentFlags().Lookup(bind.flag)); err != nil {
 			panic(fmt.Sprintf("failed to bind flag %q: %v", bind.flag, err))
@@ -66,7 +91,13 @@ func init() {
 	}
 
 	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
-		if initConfigErr != nil {
+		startTimings()
+		if err := startEnvelope(cmd); err != nil {
+			return err
+		}
+		// fm init creates the file --config names.
+		missingForInit := cmd == initCmd && errors.Is(initConfigErr, fs.ErrNotExist)
+		if initConfigErr != nil && !missingForInit {
 			return exitError("config_error", "failed to read config: "+initConfigErr.Error(), configErrorHint())
 		}
 		format := viper.GetString("format")
@@ -75,7 +106,14 @@ func init() {
 				fmt.Sprintf("unsupported output format: %q", format),
 				"supported formats: json, text")
 		}
-		return nil
+		if viper.GetString("account") != "" && viper.GetString("account_id") != "" {
+			return exitError("general_error", "--account and --account-id cannot be combined",
+				"Use --account with a name, email, or ID")
+		}
+		if err := applySavedSearch(cmd); err != nil {
+			return err
+		}
+		return startOutputFile(cmd)
 	}
 }
 
@@ -85,20 +123,22 @@ func initConfig() {
 	if cfgFile != "" {
 		viper.SetConfigFile(cfgFile)
 	} else {
-		home, err := os.UserHomeDir()
+		configDir, err := paths.ConfigDir()
 		if err == nil {
-			configDir := filepath.Join(home, ".config", "fm")
 			viper.AddConfigPath(configDir)
 			viper.SetConfigName("config")
 			viper.SetConfigType("yaml")
 		}
 	}
 
-	viper.SetEnvPrefix("FM")
+	viper.SetEnvPrefix(envPrefix)
 	viper.AutomaticEnv()
 
-	viper.SetDefault("session_url", "https://api.fastmail.com/jmap/session")
+	viper.SetDefault("session_url", defaultSessionURL)
 	viper.SetDefault("format", "json")
+	viper.SetDefault("server", client.ServerAuto)
+	viper.SetDefault("max_concurrent_requests", 0)
+	viper.SetDefault("requests_per_second", 0)
 
 	if err := viper.ReadInConfig(); err != nil {
 		var notFound viper.ConfigFileNotFoundError
@@ -113,7 +153,10 @@ func configErrorHint() string {
 	if cfgFile != "" {
 		return "Fix the syntax in " + cfgFile + " or choose another file with --config"
 	}
-	return "Fix the syntax in ~/.config/fm/config.yaml or use --config"
+	if path, err := paths.ConfigFile(); err == nil {
+		return "Fix the syntax in " + path + " or use --config"
+	}
+	return "Fix the syntax in your config file or use --config"
 }
 
 // defaultCredentialCommand returns a platform-specific credential command
@@ -141,7 +184,11 @@ func resolveToken() (string, error) {
 	if credCmd == "" {
 		return "", fmt.Errorf("no credential command configured; set FM_CREDENTIAL_COMMAND, --credential-command, or credential_command in config file")
 	}
+	return runCredentialCommand(credCmd)
+}
 
+// runCredentialCommand runs credCmd with sh and returns the token it prints.
+func runCredentialCommand(credCmd string) (string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), credentialTimeout)
 	defer cancel()
 
@@ -168,24 +215,99 @@ func resolveToken() (string, error) {
 }
 
 // newClient creates an authenticated JMAP client from the current config.
+// When an fm daemon is running for the same session URL and credential
+// command, the client goes through it.
 func newClient() (*client.Client, error) {
+	if socket := runningDaemonSocket(); socket != "" {
+		if c, err := client.NewViaDaemon(socket, viper.GetString("account_id")); err == nil {
+			return configureClient(c)
+		}
+	}
+	return newDirectClient()
+}
+
+// newDirectClient creates a client that talks to the server itself,
+// without going through a daemon.
+func newDirectClient() (*client.Client, error) {
 	token, err := resolveToken()
 	if err != nil {
 		return nil, err
 	}
+	return newTokenClient(token)
+}
+
+// newTokenClient creates a client that talks to the server itself with
+// token.
+func newTokenClient(token string) (*client.Client, error) {
 	sessionURL := viper.GetString("session_url")
 	accountID := viper.GetString("account_id")
 
-	return client.New(sessionURL, token, accountID)
+	c, err := client.New(sessionURL, token, accountID)
+	if err != nil {
+		return nil, err
+	}
+	return configureClient(c)
+}
+
+// configureClient applies the server, rate limit, account, and explain
+// settings to c, and counts its requests for --timings.
+func configureClient(c *client.Client) (*client.Client, error) {
+	trackClient(c)
+	if err := c.SetServer(viper.GetString("server")); err != nil {
+		return nil, err
+	}
+	if err := c.SetRateLimit(viper.GetInt("max_concurrent_requests"), viper.GetFloat64("requests_per_second")); err != nil {
+		return nil, err
+	}
+	if account := viper.GetString("account"); account != "" {
+		if err := c.SelectAccount(account); err != nil {
+			return nil, err
+		}
+	}
+	if explain, _ := rootCmd.PersistentFlags().GetBool("explain"); explain {
+		c.SetExplain(os.Stderr)
+	}
+	return c, nil
 }
 
-// formatter returns the configured output formatter.
+// formatter returns the configured output formatter. Text output is
+// colored when stdout is a terminal, NO_COLOR is unset, and ascii is off;
+// hyperlinks additionally need the hyperlinks setting. With redact, output
+// passes through a Redactor shared by the whole run, so placeholders stay
+// consistent between results and errors. With --errors-to stdout, results
+// and errors are collected into the envelope instead.
 func formatter() output.Formatter {
-	return output.New(viper.GetString("format"))
+	if envelope != nil {
+		return envelope
+	}
+	f := output.New(viper.GetString("format"))
+	if tf, ok := f.(*output.TextFormatter); ok {
+		tf.ASCII = viper.GetBool("ascii")
+		tf.Color = !tf.ASCII && colorEnabled(os.Stdout)
+		tf.Hyperlinks = !tf.ASCII && viper.GetBool("hyperlinks") && isTerminal(os.Stdout)
+	}
+	if viper.GetBool("redact") {
+		return output.Redacted(f, redactor)
+	}
+	return f
+}
+
+var redactor = output.NewRedactor()
+
+// colorEnabled reports whether ANSI colors should be written to out.
+func colorEnabled(out *os.File) bool {
+	return os.Getenv("NO_COLOR") == "" && isTerminal(out)
+}
+
+// isTerminal reports whether out is a terminal rather than a file or pipe.
+func isTerminal(out *os.File) bool {
+	info, err := out.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
 }
 
-// exitError writes a structured error to stderr and returns ErrSilent
-// to signal that the error has already been printed.
+// exitError writes a structured error to stderr, or records it in the
+// envelope with --errors-to stdout, and returns ErrSilent to signal that
+// the error has already been handled.
 func exitError(code string, message string, hint string) error {
 	if err := formatter().FormatError(os.Stderr, code, message, hint); err != nil {
 		fmt.Fprintf(os.Stderr, "error [%s]: %s\n", code, message)
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// savedSearchFlags maps the keys of a saved search in the config file to
+// the filter flags they set.
+var savedSearchFlags = map[string]string{
+	"mailbox":        "mailbox",
+	"from":           "from",
+	"to":             "to",
+	"subject":        "subject",
+	"before":         "before",
+	"after":          "after",
+	"older_than":     "older-than",
+	"has_attachment": "has-attachment",
+	"unread":         "unread",
+	"read":           "read",
+	"flagged":        "flagged",
+	"unflagged":      "unflagged",
+}
+
+// savedSearchBoolKeys are the saved search keys that take true or false.
+var savedSearchBoolKeys = []string{"has_attachment", "unread", "read", "flagged", "unflagged"}
+
+// applySavedSearch expands --mailbox @name into the filter flags of the
+// saved search searches.name from the config file. Flags given on the
+// command line take precedence over the saved search. Commands without a
+// --mailbox flag are left alone.
+func applySavedSearch(cmd *cobra.Command) error {
+	mb := cmd.Flags().Lookup("mailbox")
+	if mb == nil || !strings.HasPrefix(mb.Value.String(), "@") {
+		return nil
+	}
+	name := strings.TrimPrefix(mb.Value.String(), "@")
+
+	search, err := savedSearch(name)
+	if err != nil {
+		return err
+	}
+	if problem := validateSavedSearch(search); problem != "" {
+		return exitError("config_error", fmt.Sprintf("saved search @%s: %s", name, problem),
+			"Fix searches."+name+" in the config file; fm config check lists the problems")
+	}
+
+	mailboxName, _ := search["mailbox"].(string)
+	if mailboxName == "" && mb.DefValue != "" {
+		return exitError("general_error",
+			fmt.Sprintf("saved search @%s has no mailbox, which %s needs", name, cmd.CommandPath()),
+			"Add a mailbox to searches."+name+" in the config file, or use fm search --mailbox @"+name)
+	}
+	if err := mb.Value.Set(mailboxName); err != nil {
+		return exitError("config_error", fmt.Sprintf("saved search @%s: %v", name, err), "")
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(search)) {
+		if key == "mailbox" {
+			continue
+		}
+		flagName := savedSearchFlags[key]
+		f := cmd.Flags().Lookup(flagName)
+		if f == nil || (flagName == "to" && !isRecipientToFilterFlag(cmd)) {
+			return exitError("general_error",
+				fmt.Sprintf("saved search @%s filters by %s, which %s does not support", name, key, cmd.CommandPath()),
+				"Use fm search --mailbox @"+name)
+		}
+		if f.Changed {
+			continue
+		}
+		if err := cmd.Flags().Set(flagName, savedSearchValue(search[key])); err != nil {
+			return exitError("config_error", fmt.Sprintf("saved search @%s: invalid %s: %v", name, key, err), "")
+		}
+	}
+	return nil
+}
+
+// savedSearch returns the saved search called name from the config file.
+func savedSearch(name string) (map[string]any, error) {
+	searches := viper.GetStringMap("searches")
+	if raw, ok := searches[strings.ToLower(name)]; ok {
+		if search, ok := raw.(map[string]any); ok {
+			return search, nil
+		}
+		return nil, exitError("config_error", fmt.Sprintf("saved search @%s is not a set of filters", name),
+			"Define it as a mapping, for example searches: {"+name+": {flagged: true, mailbox: inbox}}")
+	}
+
+	hint := "Define saved searches under searches in the config file"
+	if len(searches) > 0 {
+		hint = "Saved searches: @" + strings.Join(slices.Sorted(maps.Keys(searches)), ", @")
+	}
+	return nil, exitError("not_found", fmt.Sprintf("no saved search named @%s", name), hint)
+}
+
+// validateSavedSearch returns a description of what is wrong with a saved
+// search, or "" when it is valid.
+func validateSavedSearch(search map[string]any) string {
+	for _, key := range slices.Sorted(maps.Keys(search)) {
+		if _, ok := savedSearchFlags[key]; !ok {
+			return fmt.Sprintf("unknown filter %q (supported: %s)", key,
+				strings.Join(slices.Sorted(maps.Keys(savedSearchFlags)), ", "))
+		}
+		_, isBool := search[key].(bool)
+		if wantBool := slices.Contains(savedSearchBoolKeys, key); wantBool != isBool {
+			if wantBool {
+				return fmt.Sprintf("%s must be true or false", key)
+			}
+			return fmt.Sprintf("%s must be a string", key)
+		}
+		if s, _ := search[key].(string); key == "mailbox" && strings.HasPrefix(s, "@") {
+			return "mailbox cannot refer to another saved search"
+		}
+	}
+	return ""
+}
+
+// savedSearchValue formats a saved search value as a flag value. YAML reads
+// unquoted dates such as 2026-01-15 as timestamps, so those are turned back
+// into dates.
+func savedSearchValue(v any) string {
+	if t, ok := v.(time.Time); ok {
+		if t.Equal(t.Truncate(24 * time.Hour)) {
+			return t.Format("2006-01-02")
+		}
+		return t.Format(time.RFC3339)
+	}
+	return fmt.Sprint(v)
+}
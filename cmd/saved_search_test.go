@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+const savedSearchConfig = `searches:
+  urgent:
+    mailbox: inbox
+    flagged: true
+  recent:
+    after: 2026-01-15
+    from: alice@example.com
+`
+
+func TestSavedSearch_ExpandsIntoFilters(t *testing.T) {
+	server := newJMAPMockServer(t,
+		[]map[string]any{{"id": "mb-inbox", "name": "Inbox", "role": "inbox"}},
+		nil,
+		nil,
+	)
+	args := commandArgsForServer(t, server.server.URL, "search", "--mailbox", "@urgent", "--explain")
+	args[1] = writeSavedSearchConfig(t)
+
+	_, stderr, err := runCLICommand(t, args)
+	if err != nil {
+		t.Fatalf("search failed: %v\nstderr=%s", err, stderr)
+	}
+	if !strings.Contains(stderr, `"inMailbox": "mb-inbox"`) || !strings.Contains(stderr, `"hasKeyword": "$flagged"`) {
+		t.Errorf("expected the saved search filters in the query, got: %s", stderr)
+	}
+}
+
+func TestSavedSearch_DatesAndCommandLinePrecedence(t *testing.T) {
+	server := newJMAPMockServer(t, nil, nil, nil)
+	args := commandArgsForServer(t, server.server.URL, "search", "--mailbox", "@recent", "--from", "bob@example.com", "--explain")
+	args[1] = writeSavedSearchConfig(t)
+
+	_, stderr, err := runCLICommand(t, args)
+	if err != nil {
+		t.Fatalf("search failed: %v\nstderr=%s", err, stderr)
+	}
+	if !strings.Contains(stderr, `"after": "2026-01-15T00:00:00Z"`) {
+		t.Errorf("expected the saved after date, got: %s", stderr)
+	}
+	if !strings.Contains(stderr, `"from": "bob@example.com"`) || strings.Contains(stderr, "alice@example.com") {
+		t.Errorf("expected --from to override the saved search, got: %s", stderr)
+	}
+}
+
+func TestSavedSearch_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"unknown search", []string{"search", "--mailbox", "@nope"}, "Saved searches: @recent, @urgent"},
+		{"no mailbox for list", []string{"list", "--mailbox", "@recent"}, "saved search @recent has no mailbox"},
+		{"unsupported filter", []string{"size", "--mailbox", "@recent"}, "filters by after, which fm size does not support"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"--config", writeSavedSearchConfig(t), "--format", "json"}, tt.args...)
+			_, stderr, err := runCLICommand(t, args)
+			if !errors.Is(err, ErrSilent) || !strings.Contains(stderr, tt.want) {
+				t.Errorf("expected an error containing %q, got %v: %s", tt.want, err, stderr)
+			}
+		})
+	}
+}
+
+func TestConfigCheck_ReportsBadSavedSearch(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	config := "searches:\n  urgent:\n    flaged: true\n  ok:\n    unread: true\n"
+	if err := os.WriteFile(configPath, []byte(config), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	stdout, _, err := runCLICommand(t, []string{"--config", configPath, "--format", "json", "config", "check"})
+	if !errors.Is(err, ErrSilent) {
+		t.Fatalf("expected config_error, got: %v", err)
+	}
+	var result types.ConfigCheckResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, stdout)
+	}
+	if len(result.Problems) != 1 || result.Problems[0].Key != "searches.urgent" ||
+		!strings.Contains(result.Problems[0].Message, `unknown filter "flaged"`) {
+		t.Errorf("expected one problem for searches.urgent, got %+v", result.Problems)
+	}
+}
+
+func writeSavedSearchConfig(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(savedSearchConfig), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return path
+}
@@ -1,19 +1,40 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
+	"slices"
+	"strings"
 
+	"git.sr.ht/~rockorager/go-jmap/mail/mailbox"
 	"github.com/spf13/cobra"
 
 	"github.com/cboone/fm/internal/client"
+	"github.com/cboone/fm/internal/types"
 )
 
 var searchCmd = &cobra.Command{
 	Use:   "search [query]",
 	Short: "Search emails by text and filters",
 	Long: `Search emails using full-text search and/or structured filters.
-The optional [query] argument searches across subject, from, to, and body.
-If omitted, only the provided flags/filters are used for matching.`,
+The optional [query] argument searches across subject, from, to, and body;
+with --in header:<name> it searches only that header instead.
+If omitted, only the provided flags/filters are used for matching.
+
+Unsent drafts are left out unless --mailbox is given; use --no-drafts=false
+to include them.
+
+--since-last-run <name> keeps a named cursor as list does: each run returns
+only matches received after those of the previous run with that name.
+
+--parse notifications and --parse receipts add notification and receipt
+fields read from each email, as list does.
+
+--count-only returns the number of matching emails instead of the emails,
+using the server's total without fetching any. Add --group-by sender,
+domain, mailbox, or day to count them per group, for dashboards and quick
+histograms; each matching email is then fetched with only the property the
+grouping needs.`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		opts := client.SearchOptions{}
@@ -22,6 +43,18 @@ If omitted, only the provided flags/filters are used for matching.`,
 			opts.Text = args[0]
 		}
 
+		if scope, _ := cmd.Flags().GetString("in"); scope != "" {
+			header, ok := strings.CutPrefix(scope, "header:")
+			if !ok || header == "" {
+				return exitError("general_error", "invalid --in scope: "+scope,
+					"Use header:<name>, e.g. --in header:Received")
+			}
+			if opts.Text == "" {
+				return exitError("general_error", "--in requires a [query] argument", "")
+			}
+			opts.TextHeader = header
+		}
+
 		opts.From, _ = cmd.Flags().GetString("from")
 		opts.To, _ = cmd.Flags().GetString("to")
 		opts.Subject, _ = cmd.Flags().GetString("subject")
@@ -29,6 +62,11 @@ If omitted, only the provided flags/filters are used for matching.`,
 		opts.UnreadOnly, _ = cmd.Flags().GetBool("unread")
 		opts.FlaggedOnly, _ = cmd.Flags().GetBool("flagged")
 		opts.UnflaggedOnly, _ = cmd.Flags().GetBool("unflagged")
+		opts.ForwardedOnly, _ = cmd.Flags().GetBool("forwarded")
+		opts.ExcludeDrafts, _ = cmd.Flags().GetBool("no-drafts")
+		opts.Highlights, _ = cmd.Flags().GetBool("highlight-json")
+		includeMuted, _ := cmd.Flags().GetBool("include-muted")
+		opts.ExcludeMuted = !includeMuted
 		if opts.FlaggedOnly && opts.UnflaggedOnly {
 			return exitError("general_error", "--flagged and --unflagged are mutually exclusive", "")
 		}
@@ -50,6 +88,10 @@ If omitted, only the provided flags/filters are used for matching.`,
 		opts.SortAsc = sortAsc
 
 		mailboxName, _ := cmd.Flags().GetString("mailbox")
+		if mailboxName != "" && !cmd.Flags().Changed("no-drafts") {
+			// Searching a mailbox, such as Drafts itself, shows everything in it.
+			opts.ExcludeDrafts = false
+		}
 
 		if beforeStr, _ := cmd.Flags().GetString("before"); beforeStr != "" {
 			t, err := parseDate(beforeStr)
@@ -69,29 +111,118 @@ If omitted, only the provided flags/filters are used for matching.`,
 			opts.After = &t
 		}
 
+		if err := validateParse(cmd); err != nil {
+			return err
+		}
+		countOnly, _ := cmd.Flags().GetBool("count-only")
+		groupBy, _ := cmd.Flags().GetString("group-by")
+		if err := validateCountOnly(cmd, countOnly, groupBy); err != nil {
+			return err
+		}
+
+		job, err := loadSinceLastRun(cmd)
+		if err != nil {
+			return err
+		}
+		if job != nil {
+			var oldestFirst bool
+			if opts.After, oldestFirst = job.after(opts.After); oldestFirst {
+				opts.SortField, opts.SortAsc = "receivedAt", true
+			}
+		}
+
 		c, err := newClient()
 		if err != nil {
 			return exitError("authentication_failed", err.Error(),
 				"Check your credential command or the token it returns")
 		}
 
+		sent := false
 		if mailboxName != "" {
-			mailboxID, err := c.ResolveMailboxID(mailboxName)
+			mb, err := c.ResolveMailbox(mailboxName)
 			if err != nil {
-				return exitError("not_found", err.Error(), "")
+				return exitError("not_found", err.Error(), mailboxHint(err))
 			}
-			opts.MailboxID = string(mailboxID)
+			opts.MailboxID = string(mb.ID)
+			sent = mb.Role == mailbox.RoleSent
+		}
+		if err := excludeTrashAndJunk(cmd, c, &opts); err != nil {
+			return err
+		}
+
+		if countOnly {
+			return searchCount(c, opts, groupBy)
 		}
 
 		result, err := c.SearchEmails(opts)
 		if err != nil {
+			return exitError("jmap_error", err.Error(), unsupportedHint(err))
+		}
+		warnWindowed(c, "--limit", len(result.Emails), result.Total)
+		result.ShowRecipients = sent
+
+		if opts.TextHeader == "" {
+			result.Highlight = append(strings.Fields(opts.Text), strings.Fields(opts.Subject)...)
+		} else {
+			result.Highlight = strings.Fields(opts.Subject)
+		}
+
+		if job != nil {
+			job.filter(&result)
+		}
+		if err := parseListResult(cmd, c, &result); err != nil {
 			return exitError("jmap_error", err.Error(), "")
 		}
 
-		return formatter().Format(os.Stdout, result)
+		rememberResult("search", result)
+		if err := formatter().Format(os.Stdout, result); err != nil {
+			return err
+		}
+		if job != nil {
+			return job.save()
+		}
+		return nil
 	},
 }
 
+// validateCountOnly checks --group-by and the flags --count-only cannot be
+// combined with, since they shape a list of emails it does not return.
+func validateCountOnly(cmd *cobra.Command, countOnly bool, groupBy string) error {
+	if !countOnly {
+		if groupBy != "" {
+			return exitError("general_error", "--group-by requires --count-only", "")
+		}
+		return nil
+	}
+	for _, name := range []string{"limit", "offset", "sort", "parse", "highlight-json", "since-last-run"} {
+		if cmd.Flags().Changed(name) {
+			return exitError("general_error", "cannot combine --count-only with --"+name, "")
+		}
+	}
+	if groupBy != "" && !slices.Contains(client.GroupByFields, groupBy) {
+		return exitError("general_error", fmt.Sprintf("unknown --group-by field %q", groupBy),
+			"Use one of: "+strings.Join(client.GroupByFields, ", "))
+	}
+	return nil
+}
+
+// searchCount writes the number of emails matching opts, per group when
+// groupBy is set.
+func searchCount(c *client.Client, opts client.SearchOptions, groupBy string) error {
+	if groupBy == "" {
+		total, err := c.CountEmails(opts)
+		if err != nil {
+			return exitError("jmap_error", err.Error(), unsupportedHint(err))
+		}
+		return formatter().Format(os.Stdout, types.SearchCountResult{Total: total})
+	}
+	result, err := c.GroupEmails(opts, groupBy)
+	if err != nil {
+		return exitError("jmap_error", err.Error(), unsupportedHint(err))
+	}
+	return formatter().Format(os.Stdout, result)
+}
+
 func init() {
 	searchCmd.Flags().StringP("mailbox", "m", "", "restrict search to a specific mailbox")
 	searchCmd.Flags().Uint64P("limit", "l", 25, "maximum results")
@@ -99,12 +230,22 @@ func init() {
 	searchCmd.Flags().BoolP("unread", "u", false, "only show unread messages")
 	searchCmd.Flags().BoolP("flagged", "f", false, "only show flagged messages")
 	searchCmd.Flags().Bool("unflagged", false, "only show unflagged messages")
+	searchCmd.Flags().Bool("forwarded", false, "only show forwarded messages")
+	searchCmd.Flags().Bool("no-drafts", true, "leave out drafts unless --mailbox is given")
 	searchCmd.Flags().StringP("sort", "s", "receivedAt desc", "sort order (receivedAt, sentAt, from, subject) with asc/desc")
+	searchCmd.Flags().String("parse", "", "add fields read from each email: notifications, receipts, or both comma-separated")
 	searchCmd.Flags().String("from", "", "filter by sender address/name")
 	searchCmd.Flags().String("to", "", "filter by recipient address/name")
 	searchCmd.Flags().String("subject", "", "filter by subject text")
 	searchCmd.Flags().String("before", "", "emails received before this date (RFC 3339 or YYYY-MM-DD)")
 	searchCmd.Flags().String("after", "", "emails received after this date (RFC 3339 or YYYY-MM-DD)")
 	searchCmd.Flags().Bool("has-attachment", false, "only emails with attachments")
+	searchCmd.Flags().Bool("include-muted", false, "include threads muted with fm mute")
+	addIncludeTrashFlags(searchCmd)
+	searchCmd.Flags().Bool("highlight-json", false, "add the offsets of matches in subjects and snippets to JSON output")
+	searchCmd.Flags().String("in", "", "scope [query] to one header: header:<name>")
+	searchCmd.Flags().Bool("count-only", false, "return the number of matching emails instead of the emails")
+	searchCmd.Flags().String("group-by", "", "with --count-only, count per group: sender, domain, mailbox, or day")
+	addSinceLastRunFlag(searchCmd)
 	rootCmd.AddCommand(searchCmd)
 }
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/client"
+)
+
+var sendersCmd = &cobra.Command{
+	Use:   "senders",
+	Short: "Report correspondent frequency for a mailbox",
+	Long: `Aggregate emails by sender address and report per-sender counts,
+unread ratios, and last-seen dates, sorted by volume descending. Useful for
+deciding what to unsubscribe from or filter.
+
+--after accepts RFC 3339, a bare date (YYYY-MM-DD), or a relative age such
+as 90d or 12w.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mailboxName, _ := cmd.Flags().GetString("mailbox")
+		afterStr, _ := cmd.Flags().GetString("after")
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		if limit < 0 {
+			return exitError("general_error", "--limit must be zero or greater", "")
+		}
+
+		var after *time.Time
+		if afterStr = strings.TrimSpace(afterStr); afterStr != "" {
+			t, err := parseDateOrAge(afterStr, time.Now().UTC())
+			if err != nil {
+				return exitError("general_error", "invalid --after date: "+err.Error(),
+					"Use RFC 3339 format (e.g. 2026-01-15T00:00:00Z), a bare date (e.g. 2026-01-15), or an age (e.g. 90d)")
+			}
+			after = &t
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your credential command or the token it returns")
+		}
+
+		mailboxID, err := c.ResolveMailboxID(mailboxName)
+		if err != nil {
+			return exitError("not_found", err.Error(), mailboxHint(err))
+		}
+
+		result, err := c.AggregateSenders(client.SendersOptions{
+			MailboxID: string(mailboxID),
+			After:     after,
+			Limit:     limit,
+		})
+		if err != nil {
+			return exitError("jmap_error", err.Error(), "")
+		}
+
+		return formatter().Format(os.Stdout, result)
+	},
+}
+
+func init() {
+	sendersCmd.Flags().StringP("mailbox", "m", "inbox", "mailbox name or ID")
+	sendersCmd.Flags().String("after", "", "only count emails received after this date or age (e.g. 90d)")
+	sendersCmd.Flags().IntP("limit", "l", 0, "maximum number of senders to show (0 for all)")
+	rootCmd.AddCommand(sendersCmd)
+}
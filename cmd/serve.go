@@ -0,0 +1,431 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"git.sr.ht/~rockorager/go-jmap/mail/mailbox"
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/client"
+	"github.com/cboone/fm/internal/types"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Answer JSON requests on stdin or a unix socket, for editor plugins",
+	Long: `Answer newline-delimited JSON requests, one per line, with one JSON
+response per line, so that editor plugins and other long-lived programs can
+embed fm without starting it for every action. Requests are read from stdin
+and answered on stdout; with --socket, fm listens on a unix socket instead
+and serves each connection the same way. Through a running fm daemon, the
+session is already warm.
+
+A request names a method and its params; the response carries the same id
+and either a result, shaped like the JSON output of the matching command,
+or an error with code, message, and hint:
+
+  {"id": 1, "method": "list", "params": {"mailbox": "inbox", "limit": 10}}
+  {"id": 1, "result": {"total": 42, "offset": 0, "emails": [...]}}
+
+Methods: list, search, read, and act (archive, spam, mark-read, flag,
+unflag, or move a set of emails).`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		socket, _ := cmd.Flags().GetString("socket")
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your credential command or the token it returns")
+		}
+		s := &protocolServer{c: c}
+
+		if socket == "" {
+			return s.serve(os.Stdin, os.Stdout)
+		}
+
+		// Only a stale socket is replaced; any other file at the path is
+		// left alone.
+		if fi, err := os.Lstat(socket); err == nil {
+			if fi.Mode()&os.ModeSocket == 0 {
+				return exitError("general_error", socket+" exists and is not a socket",
+					"Choose another --socket path or remove the file")
+			}
+			_ = os.Remove(socket)
+		}
+		ln, err := net.Listen("unix", socket)
+		if err != nil {
+			return exitError("general_error", "cannot listen: "+err.Error(), "")
+		}
+		defer func() { _ = ln.Close() }()
+		if err := os.Chmod(socket, 0o600); err != nil {
+			return exitError("general_error", err.Error(), "")
+		}
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return exitError("general_error", err.Error(), "")
+			}
+			go func() {
+				defer func() { _ = conn.Close() }()
+				_ = s.serve(conn, conn)
+			}()
+		}
+	},
+}
+
+// protocolRequest is one line of input to fm serve.
+type protocolRequest struct {
+	ID     any             `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+// protocolServer answers requests with one client. Requests are handled
+// one at a time, since the client's caches are not safe for concurrent use.
+type protocolServer struct {
+	mu sync.Mutex
+	c  *client.Client
+}
+
+// serve answers each request line read from r with a response line on w
+// until r is exhausted.
+func (s *protocolServer) serve(r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	enc := json.NewEncoder(w)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if err := enc.Encode(s.handle(line)); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func (s *protocolServer) handle(line []byte) types.ProtocolResponse {
+	var req protocolRequest
+	if err := json.Unmarshal(line, &req); err != nil {
+		return types.ProtocolResponse{Error: &types.ProtocolError{
+			Code:    "general_error",
+			Message: "cannot parse request: " + err.Error(),
+			Hint:    "Send one JSON object per line",
+		}}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result any
+	var err error
+	switch req.Method {
+	case "list":
+		result, err = s.list(req.Params)
+	case "search":
+		result, err = s.search(req.Params)
+	case "read":
+		result, err = s.read(req.Params)
+	case "act":
+		result, err = s.act(req.Params)
+	default:
+		err = &types.ProtocolError{
+			Code:    "general_error",
+			Message: fmt.Sprintf("unknown method %q", req.Method),
+			Hint:    "Methods: list, search, read, act",
+		}
+	}
+	if err != nil {
+		var perr *types.ProtocolError
+		if !errors.As(err, &perr) {
+			perr = &types.ProtocolError{Code: "jmap_error", Message: err.Error()}
+		}
+		return types.ProtocolResponse{ID: req.ID, Error: perr}
+	}
+	return types.ProtocolResponse{ID: req.ID, Result: result}
+}
+
+// decodeParams decodes a request's params into v; missing params leave v
+// at its defaults.
+func decodeParams(raw json.RawMessage, v any) error {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return &types.ProtocolError{Code: "general_error", Message: "invalid params: " + err.Error()}
+	}
+	return nil
+}
+
+func protocolError(code, message, hint string) error {
+	return &types.ProtocolError{Code: code, Message: message, Hint: hint}
+}
+
+type listParams struct {
+	Mailbox      string `json:"mailbox"`
+	Limit        uint64 `json:"limit"`
+	Offset       int64  `json:"offset"`
+	Unread       bool   `json:"unread"`
+	Flagged      bool   `json:"flagged"`
+	IncludeMuted bool   `json:"include_muted"`
+}
+
+// options returns the list options for p, hiding muted threads unless
+// include_muted is set, as fm list does.
+func (p listParams) options() client.ListOptions {
+	return client.ListOptions{
+		MailboxNameOrID: p.Mailbox,
+		Limit:           p.Limit,
+		Offset:          p.Offset,
+		UnreadOnly:      p.Unread,
+		FlaggedOnly:     p.Flagged,
+		ExcludeMuted:    !p.IncludeMuted,
+	}
+}
+
+func (s *protocolServer) list(raw json.RawMessage) (any, error) {
+	p := listParams{Mailbox: "inbox"}
+	if err := decodeParams(raw, &p); err != nil {
+		return nil, err
+	}
+	result, err := s.c.ListEmails(p.options())
+	if errors.Is(err, client.ErrNotFound) {
+		return nil, protocolError("not_found", err.Error(), "")
+	}
+	return result, err
+}
+
+type searchParams struct {
+	Text         string `json:"text"`
+	Mailbox      string `json:"mailbox"`
+	From         string `json:"from"`
+	To           string `json:"to"`
+	Subject      string `json:"subject"`
+	Before       string `json:"before"`
+	After        string `json:"after"`
+	Unread       bool   `json:"unread"`
+	Flagged      bool   `json:"flagged"`
+	IncludeTrash bool   `json:"include_trash"`
+	IncludeJunk  bool   `json:"include_junk"`
+	Limit        uint64 `json:"limit"`
+	Offset       int64  `json:"offset"`
+}
+
+func (s *protocolServer) search(raw json.RawMessage) (any, error) {
+	var p searchParams
+	if err := decodeParams(raw, &p); err != nil {
+		return nil, err
+	}
+	opts := client.SearchOptions{
+		Text:        p.Text,
+		From:        p.From,
+		To:          p.To,
+		Subject:     p.Subject,
+		UnreadOnly:  p.Unread,
+		FlaggedOnly: p.Flagged,
+		Limit:       p.Limit,
+		Offset:      p.Offset,
+	}
+	for _, d := range []struct {
+		name  string
+		value string
+		dst   **time.Time
+	}{{"before", p.Before, &opts.Before}, {"after", p.After, &opts.After}} {
+		if d.value == "" {
+			continue
+		}
+		t, err := parseDate(d.value)
+		if err != nil {
+			return nil, protocolError("general_error", "invalid "+d.name+": "+err.Error(), "Use RFC 3339 or YYYY-MM-DD")
+		}
+		*d.dst = &t
+	}
+
+	if p.Mailbox != "" {
+		id, err := s.c.ResolveMailboxID(p.Mailbox)
+		if err != nil {
+			return nil, protocolError("not_found", err.Error(), mailboxHint(err))
+		}
+		opts.MailboxID = string(id)
+	} else {
+		var roles []mailbox.Role
+		if !p.IncludeTrash {
+			roles = append(roles, mailbox.RoleTrash)
+		}
+		if !p.IncludeJunk {
+			roles = append(roles, mailbox.RoleJunk)
+		}
+		if len(roles) > 0 {
+			ids, err := s.c.MailboxIDsByRole(roles...)
+			if err != nil {
+				return nil, err
+			}
+			opts.ExcludeMailboxIDs = ids
+		}
+	}
+	return s.c.SearchEmails(opts)
+}
+
+type readParams struct {
+	ID         string `json:"id"`
+	HTML       bool   `json:"html"`
+	RawHeaders bool   `json:"raw_headers"`
+	Thread     bool   `json:"thread"`
+	Full       bool   `json:"full"`
+}
+
+func (s *protocolServer) read(raw json.RawMessage) (any, error) {
+	var p readParams
+	if err := decodeParams(raw, &p); err != nil {
+		return nil, err
+	}
+	if p.ID == "" {
+		return nil, protocolError("general_error", "read needs an id", "")
+	}
+
+	// The cap is set for this request only, under s.mu, and put back
+	// afterwards so that full does not carry over to other connections.
+	var maxBodyBytes uint64 = 1 << 20
+	if p.Full {
+		maxBodyBytes = 0
+	}
+	prev := s.c.MaxBodyBytes()
+	s.c.SetMaxBodyBytes(maxBodyBytes)
+	defer s.c.SetMaxBodyBytes(prev)
+
+	if p.Thread {
+		tv, err := s.c.ReadThread(p.ID, p.HTML, p.RawHeaders)
+		if err != nil {
+			return nil, protocolError(readErrorCode(err), err.Error(), "")
+		}
+		hideInlineParts(&tv.Email)
+		return tv, nil
+	}
+	detail, err := s.c.ReadEmail(p.ID, p.HTML, p.RawHeaders)
+	if err != nil {
+		return nil, protocolError(readErrorCode(err), err.Error(), "")
+	}
+	hideInlineParts(&detail)
+	return detail, nil
+}
+
+type actParams struct {
+	Action  string   `json:"action"`
+	IDs     []string `json:"ids"`
+	Mailbox string   `json:"mailbox"`
+}
+
+// act applies one triage action to a set of emails, skipping those already
+// in its end state, the way the matching command does.
+func (s *protocolServer) act(raw json.RawMessage) (any, error) {
+	var p actParams
+	if err := decodeParams(raw, &p); err != nil {
+		return nil, err
+	}
+	if len(p.IDs) == 0 {
+		return nil, protocolError("general_error", "act needs ids", "")
+	}
+	c := s.c
+
+	var dest *mailbox.Mailbox
+	var err error
+	switch p.Action {
+	case "archive":
+		dest, err = c.GetMailboxByRole(mailbox.RoleArchive)
+	case "spam":
+		dest, err = c.GetMailboxByRole(mailbox.RoleJunk)
+	case "move":
+		if p.Mailbox == "" {
+			return nil, protocolError("general_error", "move needs a mailbox", "")
+		}
+		dest, err = c.ResolveMailbox(p.Mailbox)
+		if err == nil {
+			if verr := client.ValidateTargetMailbox(dest); verr != nil {
+				return nil, protocolError("forbidden_operation", verr.Error(), "Deletion is not permitted by this tool")
+			}
+		}
+	case "mark-read", "flag", "unflag":
+	default:
+		return nil, protocolError("general_error", fmt.Sprintf("unknown action %q", p.Action),
+			"Actions: archive, spam, mark-read, flag, unflag, move")
+	}
+	if err != nil {
+		return nil, protocolError("not_found", err.Error(), "")
+	}
+
+	states, err := c.GetEmailStates(p.IDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var done func(client.EmailState) bool
+	var apply func([]string) ([]string, []string)
+	switch p.Action {
+	case "archive", "move":
+		done = func(s client.EmailState) bool { return s.OnlyIn(dest.ID) }
+		apply = func(ids []string) ([]string, []string) { return c.MoveEmails(ids, dest.ID) }
+	case "spam":
+		isJunk := client.HasKeywords("$junk")
+		done = func(s client.EmailState) bool { return s.OnlyIn(dest.ID) && isJunk(s) }
+		apply = func(ids []string) ([]string, []string) { return c.MarkAsSpam(ids, dest.ID) }
+	case "mark-read":
+		done = client.HasKeywords("$seen")
+		apply = c.MarkAsRead
+	case "flag":
+		done = client.HasKeywords("$flagged")
+		apply = c.SetFlagged
+	case "unflag":
+		done = func(s client.EmailState) bool { return !s.Keywords["$flagged"] }
+		apply = c.SetUnflagged
+	}
+
+	pending, noOps := client.SplitNoOps(p.IDs, states, done)
+	succeeded, failed := apply(pending)
+	if p.Action == "archive" {
+		recordUndo(c, "archive", client.MailboxIDsOf(states), succeeded)
+	}
+
+	result := types.MoveResult{
+		Matched:   len(p.IDs),
+		Processed: len(succeeded) + len(failed),
+		Failed:    len(failed),
+		NoOp:      len(noOps),
+		Errors:    failed,
+	}
+	switch p.Action {
+	case "archive":
+		result.Archived = succeeded
+	case "move":
+		result.Moved = succeeded
+	case "spam":
+		result.MarkedSpam = succeeded
+	case "mark-read":
+		result.MarkedAsRead = succeeded
+	case "flag":
+		result.Flagged = succeeded
+	case "unflag":
+		result.Unflagged = succeeded
+	}
+	if dest != nil {
+		result.Destination = &types.DestinationInfo{ID: string(dest.ID), Name: dest.Name}
+	}
+	return result, nil
+}
+
+func init() {
+	serveCmd.Flags().String("socket", "", "listen on this unix socket instead of reading stdin")
+	rootCmd.AddCommand(serveCmd)
+}
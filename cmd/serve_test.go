@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cboone/fm/internal/client"
+	"github.com/cboone/fm/internal/types"
+)
+
+func TestServe_AnswersEachRequestLine(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	server := newJMAPMockServer(t,
+		[]map[string]any{
+			{"id": "mb-inbox", "name": "Inbox", "role": "inbox"},
+			{"id": "mb-archive", "name": "Archive", "role": "archive"},
+		},
+		[]map[string]any{
+			{"id": "M1", "threadId": "T1", "subject": "Hello", "receivedAt": "2026-02-14T10:00:00Z",
+				"mailboxIds": map[string]bool{"mb-inbox": true}},
+		},
+		nil,
+	)
+	c, err := client.New(server.server.URL+"/session", "test-token", "")
+	if err != nil {
+		t.Fatalf("client.New: %v", err)
+	}
+
+	in := strings.Join([]string{
+		`{"id": 1, "method": "list", "params": {"mailbox": "inbox"}}`,
+		``,
+		`{"id": "two", "method": "act", "params": {"action": "archive", "ids": ["M1"]}}`,
+		`{"id": 3, "method": "act", "params": {"action": "delete", "ids": ["M1"]}}`,
+		`{"id": 4, "method": "launch"}`,
+		`not json`,
+	}, "\n")
+	var out bytes.Buffer
+	if err := (&protocolServer{c: c}).serve(strings.NewReader(in), &out); err != nil {
+		t.Fatalf("serve: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("expected 5 responses, got %d:\n%s", len(lines), out.String())
+	}
+	type response struct {
+		ID     any                  `json:"id"`
+		Result json.RawMessage      `json:"result"`
+		Error  *types.ProtocolError `json:"error"`
+	}
+	var responses []response
+	for _, line := range lines {
+		var r response
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("decode %q: %v", line, err)
+		}
+		responses = append(responses, r)
+	}
+
+	var list types.EmailListResult
+	if err := json.Unmarshal(responses[0].Result, &list); err != nil || responses[0].ID != float64(1) || len(list.Emails) != 1 {
+		t.Errorf("unexpected list response %s (%v)", lines[0], err)
+	}
+
+	var act types.MoveResult
+	if err := json.Unmarshal(responses[1].Result, &act); err != nil || responses[1].ID != "two" ||
+		len(act.Archived) != 1 || act.Destination == nil || act.Destination.Name != "Archive" {
+		t.Errorf("unexpected act response %s (%v)", lines[1], err)
+	}
+	if server.count("Email/set") != 1 {
+		t.Errorf("expected one Email/set, got %d", server.count("Email/set"))
+	}
+
+	for i, want := range []string{`unknown action "delete"`, `unknown method "launch"`, "cannot parse request"} {
+		r := responses[i+2]
+		if r.Error == nil || r.Error.Code != "general_error" || !strings.Contains(r.Error.Message, want) {
+			t.Errorf("response %d: expected a general_error about %q, got %s", i+2, want, lines[i+2])
+		}
+	}
+}
+
+func TestServe_ListHidesMutedThreadsByDefault(t *testing.T) {
+	var p listParams
+	if !p.options().ExcludeMuted {
+		t.Error("expected list to hide muted threads by default")
+	}
+	p.IncludeMuted = true
+	if p.options().ExcludeMuted {
+		t.Error("expected include_muted to show muted threads")
+	}
+}
+
+func TestServe_SocketRefusesToReplaceAFile(t *testing.T) {
+	server := newJMAPMockServer(t, nil, nil, nil)
+	path := filepath.Join(t.TempDir(), "fm.sock")
+	if err := os.WriteFile(path, []byte("keep me"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "serve", "--socket", path))
+	if err == nil || !strings.Contains(stderr, "is not a socket") {
+		t.Fatalf("expected serve to refuse the path, got err=%v stderr=%s", err, stderr)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "keep me" {
+		t.Errorf("expected the file to be left alone, got %q (%v)", data, err)
+	}
+}
+
+func TestServe_ReadFullDoesNotCarryOver(t *testing.T) {
+	server := newJMAPMockServer(t, nil,
+		[]map[string]any{{"id": "M1", "threadId": "T1", "subject": "Hello"}},
+		nil,
+	)
+	c, err := client.New(server.server.URL+"/session", "test-token", "")
+	if err != nil {
+		t.Fatalf("client.New: %v", err)
+	}
+	c.SetMaxBodyBytes(4096)
+
+	s := &protocolServer{c: c}
+	s.handle([]byte(`{"id": 1, "method": "read", "params": {"id": "M1", "full": true}}`))
+	if got := c.MaxBodyBytes(); got != 4096 {
+		t.Errorf("expected the body cap to be put back after a full read, got %d", got)
+	}
+}
@@ -1,14 +1,24 @@
 package cmd
 
 import (
+	"errors"
 	"os"
 
 	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/client"
 )
 
 var sessionCmd = &cobra.Command{
 	Use:   "session",
 	Short: "Display JMAP session info (verify connectivity and auth)",
+	Long: `Display JMAP session info (verify connectivity and auth).
+
+With --capabilities, show every capability the server advertises, its
+collection limits, and which fm features it supports. fm keeps its requests
+within those limits, and commands needing a capability the server lacks
+(such as sieve or masked on servers other than Fastmail) fail with a
+message naming it.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		c, err := newClient()
 		if err != nil {
@@ -16,11 +26,25 @@ var sessionCmd = &cobra.Command{
 				"Check your credential command or the token it returns")
 		}
 
+		if capabilities, _ := cmd.Flags().GetBool("capabilities"); capabilities {
+			return formatter().Format(os.Stdout, c.Capabilities())
+		}
+
 		info := c.SessionInfo()
 		return formatter().Format(os.Stdout, info)
 	},
 }
 
 func init() {
+	sessionCmd.Flags().Bool("capabilities", false, "show server capabilities, limits, and supported fm features")
 	rootCmd.AddCommand(sessionCmd)
 }
+
+// unsupportedHint points at fm session --capabilities when err is due to a
+// capability the server does not advertise.
+func unsupportedHint(err error) string {
+	if errors.Is(err, client.ErrUnsupported) {
+		return "Run fm session --capabilities to see what this server supports"
+	}
+	return ""
+}
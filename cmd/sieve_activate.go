@@ -34,7 +34,7 @@ deactivates any currently active script.`,
 
 		result, err := c.ActivateSieveScript(args[0])
 		if err != nil {
-			return exitError("jmap_error", err.Error(), "")
+			return exitError("jmap_error", err.Error(), unsupportedHint(err))
 		}
 
 		return formatter().Format(os.Stdout, result)
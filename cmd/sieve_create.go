@@ -71,7 +71,7 @@ script deactivates the currently active one.`,
 
 		result, err := c.CreateSieveScript(name, content, activate)
 		if err != nil {
-			return exitError("jmap_error", err.Error(), "")
+			return exitError("jmap_error", err.Error(), unsupportedHint(err))
 		}
 
 		return formatter().Format(os.Stdout, result)
@@ -29,7 +29,7 @@ var sieveDeactivateCmd = &cobra.Command{
 
 		result, err := c.DeactivateSieveScript()
 		if err != nil {
-			return exitError("jmap_error", err.Error(), "")
+			return exitError("jmap_error", err.Error(), unsupportedHint(err))
 		}
 
 		return formatter().Format(os.Stdout, result)
@@ -38,7 +38,7 @@ Active scripts cannot be deleted. Use 'sieve deactivate' first.`,
 				return exitError("forbidden_operation", err.Error(),
 					"Use 'fm sieve deactivate' before deleting")
 			}
-			return exitError("jmap_error", err.Error(), "")
+			return exitError("jmap_error", err.Error(), unsupportedHint(err))
 		}
 
 		return formatter().Format(os.Stdout, result)
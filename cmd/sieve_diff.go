@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/textdiff"
+	"github.com/cboone/fm/internal/types"
+)
+
+// sieveFileExt is the extension for sieve scripts kept in a local directory.
+const sieveFileExt = ".sieve"
+
+var sieveDiffCmd = &cobra.Command{
+	Use:   "diff [--local <dir>]",
+	Short: "Compare server sieve scripts with local files",
+	Long: `Compare the sieve scripts on the server with a local directory of
+script files and print a unified diff for each script that differs.
+
+Each script is matched to <dir>/<name>.sieve, so a directory kept under
+version control can serve as the source of truth for your filters. Path
+separators in script names are replaced with underscores. Scripts that exist
+only on the server or only locally are reported as well.
+
+Diffs run from the server version to the local version:
+  fm sieve diff --local ~/mail/sieve`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, _ := cmd.Flags().GetString("local")
+
+		local, err := readLocalSieveScripts(dir)
+		if err != nil {
+			return exitError("general_error", err.Error(),
+				"Check that --local points to a directory of .sieve files")
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your credential command or the token it returns")
+		}
+
+		scripts, err := c.GetAllSieveScripts()
+		if err != nil {
+			return exitError("jmap_error", err.Error(), unsupportedHint(err))
+		}
+
+		return formatter().Format(os.Stdout, diffSieveScripts(dir, scripts, local))
+	},
+}
+
+func init() {
+	sieveDiffCmd.Flags().String("local", ".", "directory of <name>.sieve files to compare against")
+	sieveCmd.AddCommand(sieveDiffCmd)
+}
+
+// sieveFileName returns the local file name for a sieve script.
+func sieveFileName(name string) string {
+	r := strings.NewReplacer("/", "_", "\\", "_")
+	return r.Replace(name) + sieveFileExt
+}
+
+// readLocalSieveScripts reads every .sieve file in dir, keyed by file name.
+func readLocalSieveScripts(dir string) (map[string]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	scripts := make(map[string]string)
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != sieveFileExt {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		scripts[e.Name()] = string(data)
+	}
+	return scripts, nil
+}
+
+// diffSieveScripts compares server scripts with local files. Server scripts
+// come first in server order, followed by local-only files sorted by name.
+func diffSieveScripts(dir string, server []types.SieveScriptDetail, local map[string]string) types.SieveDiffResult {
+	result := types.SieveDiffResult{Local: dir, Scripts: []types.SieveScriptDiff{}}
+	seen := make(map[string]bool)
+
+	for _, s := range server {
+		file := sieveFileName(s.Name)
+		seen[file] = true
+		entry := types.SieveScriptDiff{Name: s.Name, ID: s.ID, File: file}
+
+		content, ok := local[file]
+		switch {
+		case !ok:
+			entry.Status = "server_only"
+			entry.Diff = textdiff.Unified("server/"+file, "/dev/null", s.Content, "")
+		case content == s.Content:
+			entry.Status = "same"
+		default:
+			entry.Status = "changed"
+			entry.Diff = textdiff.Unified("server/"+file, filepath.Join(dir, file), s.Content, content)
+		}
+		result.Scripts = append(result.Scripts, entry)
+	}
+
+	var localOnly []string
+	for file := range local {
+		if !seen[file] {
+			localOnly = append(localOnly, file)
+		}
+	}
+	sort.Strings(localOnly)
+	for _, file := range localOnly {
+		result.Scripts = append(result.Scripts, types.SieveScriptDiff{
+			Name:   strings.TrimSuffix(file, sieveFileExt),
+			File:   file,
+			Status: "local_only",
+			Diff:   textdiff.Unified("/dev/null", filepath.Join(dir, file), "", local[file]),
+		})
+	}
+
+	for _, s := range result.Scripts {
+		if s.Status != "same" {
+			result.Changed++
+		}
+	}
+	return result
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+// sieveManifestFile is written alongside exported scripts to record which
+// script was active.
+const sieveManifestFile = "manifest.json"
+
+var sieveExportCmd = &cobra.Command{
+	Use:   "export --output <dir>",
+	Short: "Download all sieve scripts to a directory",
+	Long: `Download every sieve script to a directory as <name>.sieve files, along
+with a manifest.json recording script IDs and which script is active.
+
+The export is meant for backups: the directory can be committed to version
+control, checked for drift with 'fm sieve diff --local <dir>', and restored
+with 'fm sieve create --script-stdin'.
+
+  fm sieve export --output ~/backup/sieve`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, _ := cmd.Flags().GetString("output")
+		if dir == "" {
+			return exitError("general_error", "required flag \"output\" not set",
+				"Provide a directory with --output")
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your credential command or the token it returns")
+		}
+
+		scripts, err := c.GetAllSieveScripts()
+		if err != nil {
+			return exitError("jmap_error", err.Error(), unsupportedHint(err))
+		}
+
+		result, err := exportSieveScripts(dir, scripts, time.Now().UTC())
+		if err != nil {
+			return exitError("general_error", err.Error(), "")
+		}
+
+		return formatter().Format(os.Stdout, result)
+	},
+}
+
+func init() {
+	sieveExportCmd.Flags().String("output", "", "directory to write scripts and manifest.json to (required)")
+	sieveCmd.AddCommand(sieveExportCmd)
+}
+
+// exportSieveScripts writes each script and the manifest into dir.
+func exportSieveScripts(dir string, scripts []types.SieveScriptDetail, now time.Time) (types.SieveExportResult, error) {
+	result := types.SieveExportResult{
+		Output:     dir,
+		ExportedAt: now,
+		Scripts:    make([]types.SieveExportEntry, 0, len(scripts)),
+	}
+
+	files := make(map[string]string, len(scripts))
+	for _, s := range scripts {
+		file := sieveFileName(s.Name)
+		if other, ok := files[file]; ok {
+			return types.SieveExportResult{}, fmt.Errorf("sieve scripts %q and %q both map to %s", other, s.Name, file)
+		}
+		files[file] = s.Name
+
+		result.Scripts = append(result.Scripts, types.SieveExportEntry{
+			ID:       s.ID,
+			Name:     s.Name,
+			File:     file,
+			IsActive: s.IsActive,
+		})
+		if s.IsActive {
+			result.Active = s.Name
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return types.SieveExportResult{}, fmt.Errorf("cannot create export directory: %w", err)
+	}
+
+	for i, s := range scripts {
+		path := filepath.Join(dir, result.Scripts[i].File)
+		if err := os.WriteFile(path, []byte(s.Content), 0o600); err != nil {
+			return types.SieveExportResult{}, fmt.Errorf("cannot write %s: %w", path, err)
+		}
+	}
+
+	manifest, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return types.SieveExportResult{}, err
+	}
+	path := filepath.Join(dir, sieveManifestFile)
+	if err := os.WriteFile(path, append(manifest, '\n'), 0o600); err != nil {
+		return types.SieveExportResult{}, fmt.Errorf("cannot write %s: %w", path, err)
+	}
+
+	return result, nil
+}
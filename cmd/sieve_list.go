@@ -19,7 +19,7 @@ var sieveListCmd = &cobra.Command{
 
 		result, err := c.ListSieveScripts()
 		if err != nil {
-			return exitError("jmap_error", err.Error(), "")
+			return exitError("jmap_error", err.Error(), unsupportedHint(err))
 		}
 
 		return formatter().Format(os.Stdout, result)
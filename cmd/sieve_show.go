@@ -23,7 +23,7 @@ var sieveShowCmd = &cobra.Command{
 			if strings.Contains(err.Error(), "not found") {
 				return exitError("not_found", err.Error(), "")
 			}
-			return exitError("jmap_error", err.Error(), "")
+			return exitError("jmap_error", err.Error(), unsupportedHint(err))
 		}
 
 		return formatter().Format(os.Stdout, result)
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+func TestSieveFileName(t *testing.T) {
+	if got := sieveFileName("Lists/Newsletters"); got != "Lists_Newsletters.sieve" {
+		t.Errorf("expected Lists_Newsletters.sieve, got %q", got)
+	}
+}
+
+func TestDiffSieveScripts(t *testing.T) {
+	server := []types.SieveScriptDetail{
+		{ID: "S1", Name: "main", Content: "keep;\n"},
+		{ID: "S2", Name: "vacation", Content: "require \"vacation\";\nvacation \"away\";\n"},
+		{ID: "S3", Name: "legacy", Content: "discard;\n"},
+	}
+	local := map[string]string{
+		"main.sieve":     "keep;\n",
+		"vacation.sieve": "require \"vacation\";\nvacation \"back soon\";\n",
+		"new.sieve":      "stop;\n",
+	}
+
+	result := diffSieveScripts("filters", server, local)
+
+	if result.Changed != 3 {
+		t.Errorf("expected 3 changed scripts, got %d", result.Changed)
+	}
+	want := []string{"same", "changed", "server_only", "local_only"}
+	if len(result.Scripts) != len(want) {
+		t.Fatalf("expected %d entries, got %d", len(want), len(result.Scripts))
+	}
+	for i, status := range want {
+		if result.Scripts[i].Status != status {
+			t.Errorf("scripts[%d]: expected status %s, got %s", i, status, result.Scripts[i].Status)
+		}
+	}
+	if result.Scripts[0].Diff != "" {
+		t.Errorf("expected no diff for unchanged script, got %q", result.Scripts[0].Diff)
+	}
+	if !strings.Contains(result.Scripts[1].Diff, "-vacation \"away\";\n+vacation \"back soon\";\n") {
+		t.Errorf("unexpected diff for changed script:\n%s", result.Scripts[1].Diff)
+	}
+	if result.Scripts[3].Name != "new" || result.Scripts[3].ID != "" {
+		t.Errorf("expected local-only script named new without ID, got %+v", result.Scripts[3])
+	}
+}
+
+func TestExportSieveScripts(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "sieve")
+	scripts := []types.SieveScriptDetail{
+		{ID: "S1", Name: "main", Content: "keep;\n", IsActive: true},
+		{ID: "S2", Name: "Lists/News", Content: "stop;\n"},
+	}
+
+	result, err := exportSieveScripts(dir, scripts, time.Date(2026, 2, 4, 10, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("exportSieveScripts: %v", err)
+	}
+	if result.Active != "main" {
+		t.Errorf("expected active script main, got %q", result.Active)
+	}
+
+	local, err := readLocalSieveScripts(dir)
+	if err != nil {
+		t.Fatalf("readLocalSieveScripts: %v", err)
+	}
+	if diff := diffSieveScripts(dir, scripts, local); diff.Changed != 0 {
+		t.Errorf("expected exported directory to match server, got %+v", diff.Scripts)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, sieveManifestFile))
+	if err != nil {
+		t.Fatalf("reading manifest: %v", err)
+	}
+	var manifest types.SieveExportResult
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatalf("decoding manifest: %v", err)
+	}
+	if manifest.Active != "main" || len(manifest.Scripts) != 2 || manifest.Scripts[1].File != "Lists_News.sieve" {
+		t.Errorf("unexpected manifest: %+v", manifest)
+	}
+}
+
+func TestExportSieveScripts_FileNameCollision(t *testing.T) {
+	scripts := []types.SieveScriptDetail{
+		{ID: "S1", Name: "a/b"},
+		{ID: "S2", Name: "a_b"},
+	}
+	if _, err := exportSieveScripts(t.TempDir(), scripts, time.Now()); err == nil {
+		t.Fatal("expected error for colliding file names")
+	}
+}
@@ -29,7 +29,7 @@ Provide the script content via --script or --script-stdin.`,
 
 		result, err := c.ValidateSieveScript(content)
 		if err != nil {
-			return exitError("jmap_error", err.Error(), "")
+			return exitError("jmap_error", err.Error(), unsupportedHint(err))
 		}
 
 		return formatter().Format(os.Stdout, result)
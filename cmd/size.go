@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/client"
+)
+
+var sizeCmd = &cobra.Command{
+	Use:   "size",
+	Short: "List the largest emails",
+	Long: `List the largest emails, biggest first, with sender, subject, date, size,
+and attachment count. The server sorts by size, so only the listed emails
+are fetched. Useful for finding what to clean up when nearing the storage
+quota.
+
+  fm size --top 10
+  fm size --mailbox Archive`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mailboxName, _ := cmd.Flags().GetString("mailbox")
+		top, _ := cmd.Flags().GetUint64("top")
+		if top == 0 {
+			return exitError("general_error", "--top must be at least 1", "")
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your credential command or the token it returns")
+		}
+
+		opts := client.SizeOptions{Limit: top}
+		if mailboxName != "" {
+			mailboxID, err := c.ResolveMailboxID(mailboxName)
+			if err != nil {
+				return exitError("not_found", err.Error(), mailboxHint(err))
+			}
+			opts.MailboxID = string(mailboxID)
+		}
+
+		result, err := c.LargestEmails(opts)
+		if err != nil {
+			return exitError("jmap_error", err.Error(), unsupportedHint(err))
+		}
+		warnWindowed(c, "--top", len(result.Emails), result.Total)
+		result.Mailbox = mailboxName
+
+		return formatter().Format(os.Stdout, result)
+	},
+}
+
+func init() {
+	sizeCmd.Flags().StringP("mailbox", "m", "", "only list emails in this mailbox (default: all mail)")
+	sizeCmd.Flags().Uint64("top", 25, "number of emails to list")
+	rootCmd.AddCommand(sizeCmd)
+}
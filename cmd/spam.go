@@ -6,13 +6,15 @@ import (
 	"git.sr.ht/~rockorager/go-jmap/mail/mailbox"
 	"github.com/spf13/cobra"
 
+	"github.com/cboone/fm/internal/client"
 	"github.com/cboone/fm/internal/types"
 )
 
 var spamCmd = &cobra.Command{
-	Use:   "spam [email-id...]",
-	Short: "Move emails to the Junk/Spam mailbox",
-	Args:  cobra.ArbitraryArgs,
+	Use:               "spam [email-id...]",
+	Short:             "Move emails to the Junk/Spam mailbox",
+	Args:              cobra.ArbitraryArgs,
+	ValidArgsFunction: completeEmailIDs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if err := validateIDsOrFilters(cmd, args); err != nil {
 			return err
@@ -42,14 +44,27 @@ var spamCmd = &cobra.Command{
 			})
 		}
 
-		succeeded, errors := c.MarkAsSpam(ids, junkMB.ID)
+		states, err := c.GetEmailStates(ids)
+		if err != nil {
+			return exitError("jmap_error", err.Error(), "")
+		}
+		isJunk := client.HasKeywords("$junk")
+		pending, noOps := client.SplitNoOps(ids, states, func(s client.EmailState) bool {
+			return s.OnlyIn(junkMB.ID) && isJunk(s)
+		})
+
+		c.SetProgress(progressPrinter("Marking spam"))
+		outcomes := recordOutcomes(cmd, c)
+		succeeded, errors := c.MarkAsSpam(pending, junkMB.ID)
 
 		result := types.MoveResult{
 			Matched:    len(ids),
 			Processed:  len(succeeded) + len(errors),
 			Failed:     len(errors),
+			NoOp:       len(noOps),
 			MarkedSpam: succeeded,
 			Errors:     errors,
+			Results:    append(outcomes.results(succeeded, errors), outcomes.noOps(noOps)...),
 			Destination: &types.DestinationInfo{
 				ID:   string(junkMB.ID),
 				Name: junkMB.Name,
@@ -61,7 +76,7 @@ var spamCmd = &cobra.Command{
 		}
 
 		if len(errors) > 0 {
-			return exitError("partial_failure", "one or more emails failed to mark as spam", "")
+			return exitError("partial_failure", "one or more emails failed to mark as spam", retryHint(cmd, errors))
 		}
 
 		return nil
@@ -70,6 +85,9 @@ var spamCmd = &cobra.Command{
 
 func init() {
 	spamCmd.Flags().BoolP("dry-run", "n", false, "preview affected emails without making changes")
+	addVerboseFlag(spamCmd)
+	addIDsFileFlag(spamCmd)
 	addFilterFlags(spamCmd)
+	addFromLastFlag(spamCmd)
 	rootCmd.AddCommand(spamCmd)
 }
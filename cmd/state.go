@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var stateCmd = &cobra.Command{
+	Use:   "state",
+	Short: "Print the current JMAP state strings",
+	Long: `Print the current session state and the Email, Mailbox, and Thread state
+strings of the account. A state changes whenever objects of its type
+change, so it marks a point to ask for changes since, as with
+fm list --changed-since.
+
+  STATE=$(fm state | jq -r .email)
+  fm list --changed-since "$STATE"`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your credential command or the token it returns")
+		}
+
+		result, err := c.States()
+		if err != nil {
+			return exitError("jmap_error", err.Error(), "")
+		}
+
+		return formatter().Format(os.Stdout, result)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(stateCmd)
+}
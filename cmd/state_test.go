@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+func TestState_PrintsEveryState(t *testing.T) {
+	server := newJMAPMockServer(t,
+		[]map[string]any{{"id": "mb-inbox", "name": "Inbox", "role": "inbox"}},
+		[]map[string]any{{"id": "M1", "threadId": "T1"}},
+		nil,
+	)
+
+	stdout, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "state"))
+	if err != nil {
+		t.Fatalf("state failed: %v\n%s", err, stderr)
+	}
+
+	var result types.StateResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, stdout)
+	}
+	want := types.StateResult{Session: "state-1", Email: "state-1", Mailbox: "state-1", Thread: "state-1"}
+	if result != want {
+		t.Errorf("got %+v, want %+v", result, want)
+	}
+	if server.count("Email/query") != 1 || server.count("Thread/get") != 1 {
+		t.Errorf("expected the states in a single request, got %d queries and %d thread gets",
+			server.count("Email/query"), server.count("Thread/get"))
+	}
+}
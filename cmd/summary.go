@@ -2,10 +2,14 @@ package cmd
 
 import (
 	"os"
+	"time"
 
+	"git.sr.ht/~rockorager/go-jmap"
 	"github.com/spf13/cobra"
 
 	"github.com/cboone/fm/internal/client"
+	"github.com/cboone/fm/internal/output"
+	"github.com/cboone/fm/internal/types"
 )
 
 var summaryCmd = &cobra.Command{
@@ -13,7 +17,24 @@ var summaryCmd = &cobra.Command{
 	Short: "Show inbox triage summary with sender and domain aggregation",
 	Args:  cobra.NoArgs,
 	Long: `Aggregate emails by sender and domain, count unread messages, and optionally
-detect newsletters. Provides a single-pass triage overview of a mailbox.`,
+detect newsletters. Provides a single-pass triage overview of a mailbox.
+
+With --html, the summary is written as a self-contained HTML report instead,
+with the flagged emails listed as notable messages, ready to send by mail
+from cron:
+
+  fm summary --html | mail -a 'Content-Type: text/html' -s 'Inbox' me@example.com
+
+With --unanswered-threads, the summary lists the conversations you may be
+dropping instead: those whose last message came from someone else more than
+--days days ago and that you have not replied to, longest waiting first.
+Your addresses are the account username and your sending identities.
+
+With --keywords, the summary counts the flagged, answered, and forwarded
+emails and the emails carrying each custom keyword instead, for the whole
+account and each mailbox, including the flagged emails never answered: the
+backlog of flagged mail not yet handled. Every email is scanned; give
+--mailbox to count a single mailbox.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		mailboxName, _ := cmd.Flags().GetString("mailbox")
 		subject, _ := cmd.Flags().GetString("subject")
@@ -23,7 +44,30 @@ detect newsletters. Provides a single-pass triage overview of a mailbox.`,
 		limit, _ := cmd.Flags().GetInt("limit")
 		subjects, _ := cmd.Flags().GetBool("subjects")
 		newsletters, _ := cmd.Flags().GetBool("newsletters")
+		html, _ := cmd.Flags().GetBool("html")
+		unanswered, _ := cmd.Flags().GetBool("unanswered-threads")
+		days, _ := cmd.Flags().GetInt("days")
+		keywords, _ := cmd.Flags().GetBool("keywords")
 
+		if keywords {
+			for _, name := range []string{"subject", "unread", "flagged", "unflagged", "limit", "subjects", "newsletters", "html", "unanswered-threads", "days"} {
+				if cmd.Flags().Changed(name) {
+					return exitError("general_error", "cannot combine --keywords with --"+name, "")
+				}
+			}
+		}
+		if unanswered {
+			for _, name := range []string{"subject", "unread", "flagged", "unflagged", "subjects", "newsletters", "html"} {
+				if cmd.Flags().Changed(name) {
+					return exitError("general_error", "cannot combine --unanswered-threads with --"+name, "")
+				}
+			}
+		} else if cmd.Flags().Changed("days") {
+			return exitError("general_error", "--days requires --unanswered-threads", "")
+		}
+		if days < 0 {
+			return exitError("general_error", "--days must be zero or greater", "")
+		}
 		if flagged && unflagged {
 			return exitError("general_error", "--flagged and --unflagged are mutually exclusive", "")
 		}
@@ -37,9 +81,40 @@ detect newsletters. Provides a single-pass triage overview of a mailbox.`,
 				"Check your credential command or the token it returns")
 		}
 
-		mailboxID, err := c.ResolveMailboxID(mailboxName)
+		if keywords {
+			var mailboxID jmap.ID
+			if cmd.Flags().Changed("mailbox") {
+				mb, err := c.ResolveMailbox(mailboxName)
+				if err != nil {
+					return exitError("not_found", err.Error(), mailboxHint(err))
+				}
+				mailboxID = mb.ID
+			}
+			result, err := c.KeywordSummary(mailboxID)
+			if err != nil {
+				return exitError("jmap_error", err.Error(), "")
+			}
+			return formatter().Format(os.Stdout, result)
+		}
+
+		mb, err := c.ResolveMailbox(mailboxName)
 		if err != nil {
-			return exitError("not_found", err.Error(), "")
+			return exitError("not_found", err.Error(), mailboxHint(err))
+		}
+		mailboxID := mb.ID
+
+		if unanswered {
+			now := time.Now()
+			threads, err := c.UnansweredThreads(mailboxID, now.AddDate(0, 0, -days), now)
+			if err != nil {
+				return exitError("jmap_error", err.Error(), "")
+			}
+			return formatter().Format(os.Stdout, types.UnansweredThreadsResult{
+				Mailbox: mb.Name,
+				Days:    days,
+				Total:   len(threads),
+				Threads: threads[:min(limit, len(threads))],
+			})
 		}
 
 		result, err := c.AggregateSummary(client.SummaryOptions{
@@ -56,7 +131,29 @@ detect newsletters. Provides a single-pass triage overview of a mailbox.`,
 			return exitError("jmap_error", err.Error(), "")
 		}
 
-		return formatter().Format(os.Stdout, result)
+		result.Snoozed, err = c.CountSnoozed()
+		if err != nil {
+			return exitError("jmap_error", err.Error(), "")
+		}
+
+		if !html {
+			return formatter().Format(os.Stdout, result)
+		}
+
+		notable, err := c.SearchEmails(client.SearchOptions{
+			MailboxID:   string(mailboxID),
+			FlaggedOnly: true,
+			Limit:       uint64(limit),
+		})
+		if err != nil {
+			return exitError("jmap_error", err.Error(), "")
+		}
+		return output.WriteSummaryHTML(os.Stdout, output.SummaryReport{
+			Mailbox:     mb.Name,
+			GeneratedAt: time.Now(),
+			Summary:     result,
+			Notable:     notable.Emails,
+		})
 	},
 }
 
@@ -66,8 +163,12 @@ func init() {
 	summaryCmd.Flags().BoolP("unread", "u", false, "only count unread messages")
 	summaryCmd.Flags().BoolP("flagged", "f", false, "only count flagged messages")
 	summaryCmd.Flags().Bool("unflagged", false, "only count unflagged messages")
-	summaryCmd.Flags().IntP("limit", "l", 10, "number of top senders/domains to show")
+	summaryCmd.Flags().IntP("limit", "l", 10, "number of top senders/domains, or unanswered conversations, to show")
 	summaryCmd.Flags().Bool("subjects", false, "include sample subjects per sender")
 	summaryCmd.Flags().Bool("newsletters", false, "detect newsletters via List-Id/List-Unsubscribe headers")
+	summaryCmd.Flags().Bool("html", false, "write a self-contained HTML report instead of JSON or text")
+	summaryCmd.Flags().Bool("unanswered-threads", false, "list conversations whose last message from someone else awaits your reply")
+	summaryCmd.Flags().Int("days", 3, "with --unanswered-threads, only conversations waiting longer than this many days")
+	summaryCmd.Flags().Bool("keywords", false, "count flagged, answered, forwarded, and custom keyword emails per mailbox across the account")
 	rootCmd.AddCommand(summaryCmd)
 }
@@ -1,6 +1,13 @@
 package cmd
 
-import "testing"
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cboone/fm/internal/types"
+)
 
 func TestSummaryCmd_FlaggedAndUnflaggedMutuallyExclusive(t *testing.T) {
 	rootCmd.SetArgs([]string{"summary", "--flagged", "--unflagged"})
@@ -25,3 +32,87 @@ func TestSummaryCmd_NoPositionalArgs(t *testing.T) {
 		t.Fatal("expected error when positional args are provided")
 	}
 }
+
+func TestSummaryCmd_UnansweredThreadsFlagConflicts(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"summary", "--unanswered-threads", "--html"}, "cannot combine --unanswered-threads with --html"},
+		{[]string{"summary", "--days", "7"}, "--days requires --unanswered-threads"},
+		{[]string{"summary", "--unanswered-threads", "--days", "-1"}, "--days must be zero or greater"},
+	}
+	for _, tt := range tests {
+		_, stderr, err := runCLICommand(t, tt.args)
+		if err == nil {
+			t.Errorf("%v: expected an error", tt.args)
+			continue
+		}
+		if !strings.Contains(stderr, tt.want) {
+			t.Errorf("%v: expected %q, got: %s", tt.args, tt.want, stderr)
+		}
+	}
+}
+
+func TestSummaryCmd_KeywordsCountsPerMailbox(t *testing.T) {
+	email := func(id string, mailboxes []string, keywords ...string) map[string]any {
+		in := map[string]bool{}
+		for _, mb := range mailboxes {
+			in[mb] = true
+		}
+		kw := map[string]bool{}
+		for _, k := range keywords {
+			kw[k] = true
+		}
+		return map[string]any{"id": id, "mailboxIds": in, "keywords": kw}
+	}
+	server := newJMAPMockServer(t,
+		[]map[string]any{
+			{"id": "mb-inbox", "name": "Inbox", "role": "inbox"},
+			{"id": "mb-work", "name": "Work"},
+		},
+		[]map[string]any{
+			email("M1", []string{"mb-inbox"}, "$seen", "$flagged"),
+			email("M2", []string{"mb-inbox"}, "$flagged", "$answered"),
+			email("M3", []string{"mb-inbox", "mb-work"}, "$forwarded", "project-x"),
+			email("M4", []string{"mb-work"}, "$flagged", "project-x", "$label1"),
+		},
+		nil,
+	)
+
+	stdout, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "summary", "--keywords"))
+	if err != nil {
+		t.Fatalf("summary --keywords failed: %v\n%s", err, stderr)
+	}
+	var result types.KeywordSummaryResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, stdout)
+	}
+
+	wantAccount := types.KeywordStats{
+		Total: 4, Flagged: 3, Answered: 1, Forwarded: 1, FlaggedUnanswered: 2,
+		Keywords: []types.KeywordCount{{Keyword: "project-x", Count: 2}, {Keyword: "$label1", Count: 1}},
+	}
+	if !reflect.DeepEqual(result.Account, wantAccount) || result.Scanned != 4 {
+		t.Errorf("account = %+v, scanned %d; want %+v, 4", result.Account, result.Scanned, wantAccount)
+	}
+	if len(result.Mailboxes) != 2 {
+		t.Fatalf("expected two mailboxes, got %+v", result.Mailboxes)
+	}
+	inbox, work := result.Mailboxes[0], result.Mailboxes[1]
+	if inbox.Mailbox != "Inbox" || inbox.Total != 3 || inbox.Flagged != 2 || inbox.FlaggedUnanswered != 1 || inbox.Forwarded != 1 {
+		t.Errorf("unexpected Inbox counts: %+v", inbox)
+	}
+	if work.Mailbox != "Work" || work.Total != 2 || work.FlaggedUnanswered != 1 || len(work.Keywords) != 2 {
+		t.Errorf("unexpected Work counts: %+v", work)
+	}
+}
+
+func TestSummaryCmd_KeywordsFlagConflicts(t *testing.T) {
+	for _, flag := range []string{"--unanswered-threads", "--html", "--unread"} {
+		_, stderr, err := runCLICommand(t, []string{"summary", "--keywords", flag})
+		if err == nil || !strings.Contains(stderr, "cannot combine --keywords with "+flag) {
+			t.Errorf("%s: expected a conflict error, got: %v\n%s", flag, err, stderr)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/cboone/fm/internal/client"
+	"github.com/cboone/fm/internal/types"
+)
+
+// timings collects the clients of the running command for --timings, or
+// is nil.
+var timings *runTimings
+
+// runTimings is what --timings reports on: when the command started and the
+// clients it created.
+type runTimings struct {
+	start   time.Time
+	clients []*client.Client
+}
+
+// startTimings begins collecting for --timings when it is set.
+func startTimings() {
+	timings = nil
+	if on, _ := rootCmd.PersistentFlags().GetBool("timings"); on {
+		timings = &runTimings{start: time.Now()}
+	}
+}
+
+// trackClient adds c to the clients --timings reports on.
+func trackClient(c *client.Client) {
+	if timings != nil {
+		timings.clients = append(timings.clients, c)
+	}
+}
+
+// finishTimings writes the --timings summary to stderr once the command
+// has finished, whether or not it succeeded.
+func finishTimings() {
+	t := timings
+	if t == nil {
+		return
+	}
+	timings = nil
+	var total types.RequestStats
+	for _, c := range t.clients {
+		s := c.RequestStats()
+		total.Requests += s.Requests
+		total.Retries += s.Retries
+		total.RateLimited += s.RateLimited
+		total.ServerErrors += s.ServerErrors
+	}
+	writeTimings(os.Stderr, time.Since(t.start), total)
+}
+
+// writeTimings writes one line summarizing a run that took d.
+func writeTimings(w io.Writer, d time.Duration, s types.RequestStats) {
+	_, _ = fmt.Fprintf(w, "timings: %s, %d requests, %d retries, %d rate limited (429), %d server errors (5xx)\n",
+		d.Round(time.Millisecond), s.Requests, s.Retries, s.RateLimited, s.ServerErrors)
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTimings_ReportsRequestsOnStderr(t *testing.T) {
+	server := newJMAPMockServer(t, []map[string]any{{"id": "mb-inbox", "name": "Inbox", "role": "inbox"}}, nil, nil)
+
+	stdout, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "mailboxes", "--timings"))
+	if err != nil {
+		t.Fatalf("mailboxes failed: %v\n%s", err, stderr)
+	}
+	if strings.Contains(stdout, "timings:") {
+		t.Errorf("expected the timings on stderr only, got stdout:\n%s", stdout)
+	}
+	if !strings.Contains(stderr, "timings: ") || !strings.Contains(stderr, "0 retries, 0 rate limited (429), 0 server errors (5xx)") {
+		t.Errorf("expected a timings line, got stderr:\n%s", stderr)
+	}
+
+	_, stderr, err = runCLICommand(t, commandArgsForServer(t, server.server.URL, "mailboxes"))
+	if err != nil {
+		t.Fatalf("mailboxes failed: %v\n%s", err, stderr)
+	}
+	if strings.Contains(stderr, "timings:") {
+		t.Errorf("expected no timings without --timings, got stderr:\n%s", stderr)
+	}
+}
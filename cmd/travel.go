@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+// tripGap is how soon after the end of one reservation the next has to
+// start to belong to the same trip.
+const tripGap = 24 * time.Hour
+
+var travelCmd = &cobra.Command{
+	Use:   "travel",
+	Short: "Summarize upcoming trips from flight and hotel confirmations",
+	Long: `Summarize the upcoming trips booked in email. Flight and hotel reservations
+are read from the schema.org markup (FlightReservation and LodgingReservation)
+that airlines, hotels, and travel agents embed in their confirmation emails,
+looking at the 200 most recent booking emails received in the year before
+--after.
+
+Of several emails about the same booking, the newest counts, so changed
+flights show their new times, and cancelled bookings are left out.
+Reservations are grouped into trips: one that starts within a day of the end
+of the ones before it belongs to the same trip. Trips that have ended by
+--after are left out.
+
+--after is "today" (the default), a date (YYYY-MM-DD), or an RFC 3339 time.`,
+	Example: `  fm travel
+  fm travel --after 2026-12-01
+  fm travel --format text`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		afterFlag, _ := cmd.Flags().GetString("after")
+		after, err := parseTravelAfter(strings.TrimSpace(afterFlag), time.Now())
+		if err != nil {
+			return exitError("general_error", "invalid --after date: "+err.Error(),
+				"Use today, RFC 3339 format (e.g. 2026-01-15T00:00:00Z), or a bare date (e.g. 2026-01-15)")
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your credential command or the token it returns")
+		}
+
+		reservations, err := c.TravelReservations(after.AddDate(-1, 0, 0))
+		if err != nil {
+			return exitError("jmap_error", err.Error(), "")
+		}
+
+		trips := groupTrips(reservations)
+		result := types.TravelResult{After: after, Trips: []types.Trip{}}
+		for _, trip := range trips {
+			if trip.End.After(after) {
+				result.Trips = append(result.Trips, trip)
+			}
+		}
+		result.Total = len(result.Trips)
+		return formatter().Format(os.Stdout, result)
+	},
+}
+
+// parseTravelAfter reads the --after flag: "today", meaning the start of
+// the current local day, or a value parseDate accepts.
+func parseTravelAfter(s string, now time.Time) (time.Time, error) {
+	if strings.EqualFold(s, "today") {
+		y, m, d := now.Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, now.Location()), nil
+	}
+	return parseDate(s)
+}
+
+// groupTrips sorts reservations by start and groups them into trips, each
+// reservation joining the trip before it when it starts within tripGap of
+// that trip's end.
+func groupTrips(reservations []types.TravelReservation) []types.Trip {
+	sort.SliceStable(reservations, func(i, j int) bool {
+		return reservations[i].Start.Before(reservations[j].Start)
+	})
+	var trips []types.Trip
+	for _, r := range reservations {
+		end := r.Start
+		if r.End != nil && r.End.After(end) {
+			end = *r.End
+		}
+		if n := len(trips); n > 0 && !r.Start.After(trips[n-1].End.Add(tripGap)) {
+			trip := &trips[n-1]
+			trip.Reservations = append(trip.Reservations, r)
+			if end.After(trip.End) {
+				trip.End = end
+			}
+			continue
+		}
+		trips = append(trips, types.Trip{Start: r.Start, End: end, Reservations: []types.TravelReservation{r}})
+	}
+	return trips
+}
+
+func init() {
+	travelCmd.Flags().String("after", "today", `leave out trips that have ended by this time: "today" or a date`)
+	rootCmd.AddCommand(travelCmd)
+}
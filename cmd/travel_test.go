@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+// bookingEmail is an email whose HTML body carries the JSON-LD markup.
+func bookingEmail(id, markup string) map[string]any {
+	html := `<script type="application/ld+json">` + markup + `</script><p>Your booking is confirmed</p>`
+	return map[string]any{
+		"id":         id,
+		"subject":    "Booking " + id,
+		"receivedAt": "2026-02-04T10:30:00Z",
+		"htmlBody":   []map[string]any{{"partId": "1", "type": "text/html"}},
+		"bodyValues": map[string]any{"1": map[string]any{"value": html}},
+	}
+}
+
+// flightMarkup is a FlightReservation for flight departing at departure.
+func flightMarkup(number, flight, departure, arrival string) string {
+	return `{"@type": "FlightReservation", "reservationNumber": "` + number + `",
+		"reservationFor": {"@type": "Flight", "flightNumber": "` + flight + `",
+		"airline": {"name": "United", "iataCode": "UA"},
+		"departureAirport": {"iataCode": "SFO"}, "arrivalAirport": {"iataCode": "JFK"},
+		"departureTime": "` + departure + `", "arrivalTime": "` + arrival + `"}}`
+}
+
+// hotelMarkup is a LodgingReservation from checkin to checkout.
+func hotelMarkup(number, status, checkin, checkout string) string {
+	return `{"@type": "LodgingReservation", "reservationNumber": "` + number + `",
+		"reservationStatus": "` + status + `",
+		"reservationFor": {"@type": "Hotel", "name": "Harbor Hotel", "address": "1 Pier Rd, Boston"},
+		"checkinDate": "` + checkin + `", "checkoutDate": "` + checkout + `"}`
+}
+
+func TestTravel_GroupsUpcomingReservationsIntoTrips(t *testing.T) {
+	// The mock server returns emails in the order given, as the newest
+	// first.
+	server := newJMAPMockServer(t, nil,
+		[]map[string]any{
+			bookingEmail("M1", flightMarkup("RXJ34P", "110", "2099-03-04T20:15:00Z", "2099-03-05T04:30:00Z")),
+			bookingEmail("M2", flightMarkup("RXJ34P", "110", "2099-03-03T20:15:00Z", "2099-03-04T04:30:00Z")),
+			bookingEmail("M3", hotelMarkup("H-1", "http://schema.org/ReservationConfirmed", "2099-03-05", "2099-03-08")),
+			bookingEmail("M4", hotelMarkup("H-2", "http://schema.org/ReservationCancelled", "2099-06-01", "2099-06-03")),
+			bookingEmail("M5", hotelMarkup("H-2", "http://schema.org/ReservationConfirmed", "2099-06-01", "2099-06-03")),
+			bookingEmail("M6", flightMarkup("OLD1", "9", "2020-01-01T08:00:00Z", "2020-01-01T10:00:00Z")),
+			bookingEmail("M7", flightMarkup("ZZ9", "UA 200", "2099-09-01T08:00:00Z", "2099-09-01T14:00:00Z")),
+		},
+		nil,
+	)
+
+	stdout, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "travel"))
+	if err != nil {
+		t.Fatalf("travel failed: %v\n%s", err, stderr)
+	}
+	var result types.TravelResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, stdout)
+	}
+	if result.Total != 2 || len(result.Trips) != 2 {
+		t.Fatalf("expected two trips, got %s", stdout)
+	}
+
+	first := result.Trips[0]
+	if len(first.Reservations) != 2 {
+		t.Fatalf("expected the flight and hotel in the first trip, got %+v", first.Reservations)
+	}
+	flight := first.Reservations[0]
+	if flight.EmailID != "M1" || flight.Kind != "flight" || flight.FlightNumber != "UA 110" ||
+		flight.From != "SFO" || flight.To != "JFK" || flight.Provider != "United" ||
+		!flight.Start.Equal(time.Date(2099, 3, 4, 20, 15, 0, 0, time.UTC)) {
+		t.Errorf("expected the newest version of the flight, got %+v", flight)
+	}
+	if hotel := first.Reservations[1]; hotel.EmailID != "M3" || hotel.Kind != "lodging" || hotel.Name != "Harbor Hotel" || hotel.End == nil {
+		t.Errorf("expected the hotel stay, got %+v", hotel)
+	}
+	if !first.Start.Equal(flight.Start) || !first.End.Equal(*first.Reservations[1].End) {
+		t.Errorf("expected the trip to span the flight and the stay, got %s to %s", first.Start, first.End)
+	}
+
+	if second := result.Trips[1]; len(second.Reservations) != 1 || second.Reservations[0].EmailID != "M7" ||
+		second.Reservations[0].FlightNumber != "UA 200" {
+		t.Errorf("expected the September flight alone in the second trip, got %+v", second.Reservations)
+	}
+}
+
+func TestTravel_RejectsInvalidAfter(t *testing.T) {
+	server := newJMAPMockServer(t, nil, nil, nil)
+
+	_, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "travel", "--after", "soon"))
+	if err == nil || !strings.Contains(stderr, "invalid --after date") {
+		t.Fatalf("expected an invalid date error, got: %v\n%s", err, stderr)
+	}
+}
+
+func TestParseTravelAfter(t *testing.T) {
+	now := time.Date(2026, 10, 15, 14, 30, 0, 0, time.UTC)
+	got, err := parseTravelAfter("today", now)
+	if err != nil || !got.Equal(time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("parseTravelAfter(today) = %v, %v; want the start of the day", got, err)
+	}
+	got, err = parseTravelAfter("2026-12-01", now)
+	if err != nil || !got.Equal(time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("parseTravelAfter(2026-12-01) = %v, %v", got, err)
+	}
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"os"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/cache"
+	"github.com/cboone/fm/internal/client"
+	"github.com/cboone/fm/internal/paths"
+	"github.com/cboone/fm/internal/types"
+)
+
+// journalPath returns the file holding the undo journal.
+func journalPath() (string, error) {
+	return paths.CacheFile("journal.json")
+}
+
+// recordUndo appends an undo journal entry for the emails of previous that
+// the command moved. Failing to save the journal does not fail the command,
+// since the move itself has already happened; it is reported as a warning.
+func recordUndo(c *client.Client, command string, previous map[string][]string, moved []string) {
+	if len(moved) == 0 {
+		return
+	}
+	entry := cache.JournalEntry{
+		Command:   command,
+		AccountID: string(c.AccountID()),
+		At:        time.Now().UTC(),
+		Previous:  make(map[string][]string, len(moved)),
+	}
+	for _, id := range moved {
+		if mailboxIDs, ok := previous[id]; ok {
+			entry.Previous[id] = mailboxIDs
+		}
+	}
+
+	path, err := journalPath()
+	if err == nil {
+		var entries []cache.JournalEntry
+		if entries, err = cache.LoadJournal(path); err == nil {
+			err = cache.SaveJournal(path, append(entries, entry))
+		}
+	}
+	if err != nil {
+		warn("general_error", "could not record undo journal: "+err.Error(),
+			"The "+command+" succeeded, but fm undo cannot reverse it")
+	}
+}
+
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Put back the emails moved by the last archive",
+	Long: `Reverse the most recent operation recorded in the undo journal, moving each
+email back to the mailboxes it was in before. The journal keeps the last 20
+archive operations; run undo again to step further back.
+
+Undo never moves an email into Trash. Emails that cannot be restored stay in
+the journal so that undo can be retried.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your credential command or the token it returns")
+		}
+
+		path, err := journalPath()
+		if err != nil {
+			return exitError("general_error", "cannot locate undo journal: "+err.Error(), "")
+		}
+		entries, err := cache.LoadJournal(path)
+		if err != nil {
+			return exitError("general_error", err.Error(), "Delete "+path+" to clear the undo journal")
+		}
+		if len(entries) == 0 {
+			return exitError("not_found", "nothing to undo", "Only archive records undo information")
+		}
+		entry := entries[len(entries)-1]
+		if account := string(c.AccountID()); entry.AccountID != account {
+			return exitError("general_error",
+				"the last "+entry.Command+" was in account "+entry.AccountID+", not "+account,
+				"Run fm undo with --account-id "+entry.AccountID)
+		}
+
+		ids := make([]string, 0, len(entry.Previous))
+		for id := range entry.Previous {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		if dryRun {
+			return dryRunPreview(c, ids, "undo "+entry.Command, nil)
+		}
+
+		c.SetProgress(progressPrinter("Restoring"))
+		outcomes := recordOutcomes(cmd, c)
+		succeeded, errors := c.RestoreMailboxes(entry.Previous)
+
+		for _, id := range succeeded {
+			delete(entry.Previous, id)
+		}
+		entries = entries[:len(entries)-1]
+		if len(entry.Previous) > 0 {
+			entries = append(entries, entry)
+		}
+		if err := cache.SaveJournal(path, entries); err != nil {
+			warn("general_error", err.Error(), "")
+		}
+
+		result := types.MoveResult{
+			Matched:   len(ids),
+			Processed: len(succeeded) + len(errors),
+			Failed:    len(errors),
+			Restored:  succeeded,
+			Errors:    errors,
+			Results:   outcomes.results(succeeded, errors),
+		}
+
+		if err := formatter().Format(os.Stdout, result); err != nil {
+			return err
+		}
+
+		if len(errors) > 0 {
+			return exitError("partial_failure", "one or more emails could not be restored", "")
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	undoCmd.Flags().BoolP("dry-run", "n", false, "preview affected emails without making changes")
+	addVerboseFlag(undoCmd)
+	rootCmd.AddCommand(undoCmd)
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cboone/fm/internal/cache"
+)
+
+func TestArchiveThenUndo_RestoresPreviousMailboxes(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", "")
+
+	server := newJMAPMockServer(t,
+		[]map[string]any{
+			{"id": "mb-inbox", "name": "Inbox", "role": "inbox"},
+			{"id": "mb-archive", "name": "Archive", "role": "archive"},
+		},
+		[]map[string]any{{
+			"id":         "M1",
+			"threadId":   "T1",
+			"subject":    "Old news",
+			"receivedAt": "2026-02-14T10:30:00Z",
+			"keywords":   map[string]bool{"$seen": true},
+			"mailboxIds": map[string]bool{"mb-inbox": true},
+		}},
+		nil,
+	)
+
+	args := commandArgsForServer(t, server.server.URL, "archive", "--mailbox", "inbox", "--read", "--older-than", "90d")
+	if _, stderr, err := runCLICommand(t, args); err != nil {
+		t.Fatalf("archive failed: %v\nstderr=%s", err, stderr)
+	}
+
+	path, err := journalPath()
+	if err != nil {
+		t.Fatalf("journalPath: %v", err)
+	}
+	entries, err := cache.LoadJournal(path)
+	if err != nil {
+		t.Fatalf("LoadJournal: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Command != "archive" || entries[0].AccountID != "A1" {
+		t.Fatalf("expected one archive entry for A1, got %+v", entries)
+	}
+	if mbs := entries[0].Previous["M1"]; len(mbs) != 1 || mbs[0] != "mb-inbox" {
+		t.Fatalf("expected M1 to be recorded in mb-inbox, got %v", entries[0].Previous)
+	}
+
+	stdout, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "undo"))
+	if err != nil {
+		t.Fatalf("undo failed: %v\nstderr=%s", err, stderr)
+	}
+	if !strings.Contains(stdout, `"restored"`) || !strings.Contains(stdout, `"M1"`) {
+		t.Fatalf("expected M1 to be restored, got: %s", stdout)
+	}
+	if server.count("Email/set") != 2 {
+		t.Fatalf("expected Email/set for the archive and the undo, got %d", server.count("Email/set"))
+	}
+
+	entries, err = cache.LoadJournal(path)
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("expected the undone entry to be removed, got %+v, %v", entries, err)
+	}
+}
+
+func TestUndo_RefusesTrashAndKeepsEntry(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", "")
+
+	server := newJMAPMockServer(t,
+		[]map[string]any{{"id": "mb-trash", "name": "Trash", "role": "trash"}},
+		nil,
+		nil,
+	)
+	path, err := journalPath()
+	if err != nil {
+		t.Fatalf("journalPath: %v", err)
+	}
+	if err := cache.SaveJournal(path, []cache.JournalEntry{{
+		Command:   "archive",
+		AccountID: "A1",
+		Previous:  map[string][]string{"M1": {"mb-trash"}},
+	}}); err != nil {
+		t.Fatalf("SaveJournal: %v", err)
+	}
+
+	_, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "undo"))
+	if !errors.Is(err, ErrSilent) || !strings.Contains(stderr, "partial_failure") {
+		t.Fatalf("expected partial_failure, got: %v\nstderr=%s", err, stderr)
+	}
+	if server.count("Email/set") != 0 {
+		t.Fatalf("expected no Email/set, got %d", server.count("Email/set"))
+	}
+	entries, err := cache.LoadJournal(path)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("expected the entry to stay in the journal, got %+v, %v", entries, err)
+	}
+}
+
+func TestUndo_EmptyJournal(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", "")
+
+	server := newJMAPMockServer(t, nil, nil, nil)
+
+	_, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "undo"))
+	if !errors.Is(err, ErrSilent) || !strings.Contains(stderr, "nothing to undo") {
+		t.Fatalf("expected nothing to undo, got: %v\nstderr=%s", err, stderr)
+	}
+}
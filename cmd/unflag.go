@@ -9,9 +9,10 @@ import (
 )
 
 var unflagCmd = &cobra.Command{
-	Use:   "unflag [email-id...]",
-	Short: "Unflag emails (remove the $flagged keyword)",
-	Args:  cobra.ArbitraryArgs,
+	Use:               "unflag [email-id...]",
+	Short:             "Unflag emails (remove the $flagged keyword)",
+	Args:              cobra.ArbitraryArgs,
+	ValidArgsFunction: completeEmailIDs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if err := validateIDsOrFilters(cmd, args); err != nil {
 			return err
@@ -35,6 +36,8 @@ var unflagCmd = &cobra.Command{
 			return dryRunPreview(c, ids, "unflag", nil)
 		}
 
+		c.SetProgress(progressPrinter("Unflagging"))
+		outcomes := recordOutcomes(cmd, c)
 		var succeeded, errors []string
 		if colorOnly {
 			succeeded, errors = c.ClearFlagColor(ids)
@@ -48,6 +51,7 @@ var unflagCmd = &cobra.Command{
 			Failed:    len(errors),
 			Unflagged: succeeded,
 			Errors:    errors,
+			Results:   outcomes.results(succeeded, errors),
 		}
 
 		if err := formatter().Format(os.Stdout, result); err != nil {
@@ -55,7 +59,7 @@ var unflagCmd = &cobra.Command{
 		}
 
 		if len(errors) > 0 {
-			return exitError("partial_failure", "one or more emails failed to unflag", "")
+			return exitError("partial_failure", "one or more emails failed to unflag", retryHint(cmd, errors))
 		}
 
 		return nil
@@ -65,6 +69,9 @@ var unflagCmd = &cobra.Command{
 func init() {
 	unflagCmd.Flags().BoolP("color", "c", false, "remove flag color only (keep the email flagged)")
 	unflagCmd.Flags().BoolP("dry-run", "n", false, "preview affected emails without making changes")
+	addVerboseFlag(unflagCmd)
+	addIDsFileFlag(unflagCmd)
 	addFilterFlags(unflagCmd)
+	addFromLastFlag(unflagCmd)
 	rootCmd.AddCommand(unflagCmd)
 }
@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var unmuteCmd = &cobra.Command{
+	Use:               "unmute <thread-or-email-id>...",
+	Short:             "Unmute threads muted with fm mute",
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeEmailIDs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runMute(cmd, args, false)
+	},
+}
+
+func init() {
+	unmuteCmd.Flags().BoolP("dry-run", "n", false, "preview affected emails without making changes")
+	addVerboseFlag(unmuteCmd)
+	rootCmd.AddCommand(unmuteCmd)
+}
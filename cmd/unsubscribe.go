@@ -22,7 +22,8 @@ With --draft, creates a draft email for mailto-based unsubscribe.
 
 Accepts a single email ID as argument, or use filter flags to select
 an email (e.g. --from sender@example.com).`,
-	Args: cobra.MaximumNArgs(1),
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeEmailID,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if err := validateIDsOrFilters(cmd, args); err != nil {
 			return err
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/client"
+	"github.com/cboone/fm/internal/types"
+)
+
+// addVerboseFlag adds --verbose to a bulk action command.
+func addVerboseFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("verbose", false, "report the outcome for each email instead of only the totals")
+}
+
+// outcomeRecorder collects the per-email outcomes c reports during a bulk
+// action.
+type outcomeRecorder struct {
+	byID map[string]types.MessageOutcome
+}
+
+// recordOutcomes starts recording outcomes on c when --verbose is set. It
+// returns nil otherwise; results on a nil recorder returns nil, leaving
+// results out of the output.
+func recordOutcomes(cmd *cobra.Command, c *client.Client) *outcomeRecorder {
+	if verbose, _ := cmd.Flags().GetBool("verbose"); !verbose {
+		return nil
+	}
+	r := &outcomeRecorder{byID: map[string]types.MessageOutcome{}}
+	c.SetOutcomeObserver(func(o types.MessageOutcome) { r.byID[o.ID] = o })
+	return r
+}
+
+// noOps returns a noOp outcome for each email the action skipped because
+// it was already done.
+func (r *outcomeRecorder) noOps(ids []string) []types.MessageOutcome {
+	if r == nil {
+		return nil
+	}
+	out := make([]types.MessageOutcome, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, types.MessageOutcome{ID: id, Status: "noOp"})
+	}
+	return out
+}
+
+// results returns an outcome for every email in succeeded and then in
+// errors. Errors fm raised itself, without asking the server, are
+// reported as notFound or failed.
+func (r *outcomeRecorder) results(succeeded, errors []string) []types.MessageOutcome {
+	if r == nil {
+		return nil
+	}
+	out := make([]types.MessageOutcome, 0, len(succeeded)+len(errors))
+	for _, id := range succeeded {
+		out = append(out, types.MessageOutcome{ID: id, Status: "ok"})
+	}
+	for _, e := range errors {
+		id, reason, _ := strings.Cut(e, ": ")
+		if o, ok := r.byID[id]; ok && o.Status != "ok" {
+			out = append(out, o)
+			continue
+		}
+		status := "failed"
+		if reason == "not found" {
+			status = "notFound"
+		}
+		out = append(out, types.MessageOutcome{ID: id, Status: status, Reason: reason})
+	}
+	return out
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+func TestOutcomeRecorder_Results(t *testing.T) {
+	r := &outcomeRecorder{byID: map[string]types.MessageOutcome{
+		"M1": {ID: "M1", Status: "ok"},
+		"M2": {ID: "M2", Status: "forbidden", Reason: "mailbox is read-only"},
+	}}
+
+	got := r.results([]string{"M1"}, []string{"M2: mailbox is read-only", "T9: not found", "M3: no mailboxes recorded"})
+	want := []types.MessageOutcome{
+		{ID: "M1", Status: "ok"},
+		{ID: "M2", Status: "forbidden", Reason: "mailbox is read-only"},
+		{ID: "T9", Status: "notFound", Reason: "not found"},
+		{ID: "M3", Status: "failed", Reason: "no mailboxes recorded"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("results = %+v, want %+v", got, want)
+	}
+}
+
+func TestOutcomeRecorder_NilWithoutVerbose(t *testing.T) {
+	var r *outcomeRecorder
+	if got := r.results([]string{"M1"}, nil); got != nil {
+		t.Errorf("expected no results without --verbose, got %+v", got)
+	}
+}
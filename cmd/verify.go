@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"github.com/spf13/cobra"
+
+	"github.com/cboone/fm/internal/client"
+	"github.com/cboone/fm/internal/types"
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify <plan.json|->",
+	Short: "Check that a dry-run plan is reflected on the server",
+	Long: `Read a plan saved from a --dry-run, query the emails it lists, and report
+which of the planned changes the server now reflects. Run it after the
+action the plan previewed, by hand or from cron, to catch emails a long
+pipeline silently left behind. Use - to read the plan from stdin.
+
+  fm archive --mailbox inbox --read --older-than 90d --dry-run > plan.json
+  fm archive --mailbox inbox --read --older-than 90d
+  fm verify plan.json
+
+Plans from archive, move, spam, report-phishing, mark-read, flag, unflag,
+mute, and unmute can be verified. Exits with not_applied when any planned
+change is missing or an email is no longer found.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		plan, err := readPlan(args[0])
+		if err != nil {
+			return err
+		}
+
+		done, err := planDone(plan)
+		if err != nil {
+			return err
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your credential command or the token it returns")
+		}
+
+		ids := make([]string, len(plan.Emails))
+		for i, e := range plan.Emails {
+			ids[i] = e.ID
+		}
+		states, err := c.GetEmailStates(ids)
+		if err != nil {
+			return exitError("jmap_error", err.Error(), "")
+		}
+
+		result := types.PlanVerifyResult{
+			Operation:   plan.Operation,
+			Destination: plan.Destination,
+			Checked:     len(ids),
+			Applied:     []string{},
+			NotApplied:  []string{},
+		}
+		for _, id := range ids {
+			s, ok := states[id]
+			switch {
+			case !ok:
+				result.Missing = append(result.Missing, id)
+			case done(s):
+				result.Applied = append(result.Applied, id)
+			default:
+				result.NotApplied = append(result.NotApplied, id)
+			}
+		}
+
+		if err := formatter().Format(os.Stdout, result); err != nil {
+			return err
+		}
+
+		if len(result.NotApplied) > 0 || len(result.Missing) > 0 {
+			return exitError("not_applied",
+				fmt.Sprintf("%d of %d planned change(s) not reflected on the server",
+					len(result.NotApplied)+len(result.Missing), result.Checked),
+				"Re-run the action the plan previewed")
+		}
+		return nil
+	},
+}
+
+// readPlan reads a dry-run result from path, or from stdin when path is -.
+func readPlan(path string) (types.DryRunResult, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return types.DryRunResult{}, exitError("general_error", "cannot read plan: "+err.Error(), "")
+		}
+		defer func() { _ = f.Close() }()
+		r = f
+	}
+
+	var plan types.DryRunResult
+	if err := json.NewDecoder(r).Decode(&plan); err != nil {
+		return types.DryRunResult{}, exitError("general_error", "cannot parse plan: "+err.Error(),
+			"Save the plan from a --dry-run with JSON output")
+	}
+	if plan.Operation == "" {
+		return types.DryRunResult{}, exitError("general_error", "plan has no operation",
+			"Save the plan from a --dry-run with JSON output")
+	}
+	return plan, nil
+}
+
+// planDone returns the test for whether an email is in the state the
+// plan's operation leaves it in.
+func planDone(plan types.DryRunResult) (func(client.EmailState) bool, error) {
+	inDestination := func(client.EmailState) bool { return false }
+	if plan.Destination != nil {
+		dest := jmap.ID(plan.Destination.ID)
+		inDestination = func(s client.EmailState) bool { return s.OnlyIn(dest) }
+	}
+
+	switch plan.Operation {
+	case "archive", "move":
+		if plan.Operation == "move" && (len(plan.AddTo) > 0 || len(plan.RemoveFrom) > 0) {
+			return func(s client.EmailState) bool {
+				for _, mb := range plan.AddTo {
+					if !s.In(jmap.ID(mb.ID)) {
+						return false
+					}
+				}
+				for _, mb := range plan.RemoveFrom {
+					if s.In(jmap.ID(mb.ID)) {
+						return false
+					}
+				}
+				return true
+			}, nil
+		}
+		if plan.Destination == nil {
+			break
+		}
+		return inDestination, nil
+	case "spam", "report-phishing":
+		if plan.Destination == nil {
+			break
+		}
+		keywords := client.HasKeywords("$junk")
+		if plan.Operation == "report-phishing" {
+			keywords = client.HasKeywords("$junk", "$phishing")
+		}
+		return func(s client.EmailState) bool { return inDestination(s) && keywords(s) }, nil
+	case "mark-read":
+		return client.HasKeywords("$seen"), nil
+	case "flag":
+		return client.HasKeywords("$flagged"), nil
+	case "unflag":
+		return func(s client.EmailState) bool { return !s.Keywords["$flagged"] }, nil
+	case "mute":
+		return client.HasKeywords(client.MutedKeyword), nil
+	case "unmute":
+		return func(s client.EmailState) bool { return !s.Keywords[client.MutedKeyword] }, nil
+	default:
+		return nil, exitError("general_error", "cannot verify a plan for "+plan.Operation,
+			"Plans from archive, move, spam, report-phishing, mark-read, flag, unflag, mute, and unmute can be verified")
+	}
+	return nil, exitError("general_error", plan.Operation+" plan has no destination",
+		"Save the plan again with --dry-run")
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+}
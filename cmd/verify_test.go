@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+func writePlan(t *testing.T, plan types.DryRunResult) string {
+	t.Helper()
+	data, err := json.Marshal(plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "plan.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestVerify_ReportsChangesNotApplied(t *testing.T) {
+	server := newJMAPMockServer(t,
+		[]map[string]any{
+			{"id": "mb-inbox", "name": "Inbox", "role": "inbox"},
+			{"id": "mb-archive", "name": "Archive", "role": "archive"},
+		},
+		[]map[string]any{
+			{"id": "M1", "mailboxIds": map[string]bool{"mb-archive": true}},
+			{"id": "M2", "mailboxIds": map[string]bool{"mb-inbox": true}},
+		},
+		nil,
+	)
+
+	path := writePlan(t, types.DryRunResult{
+		Operation:   "archive",
+		Count:       3,
+		Emails:      []types.EmailSummary{{ID: "M1"}, {ID: "M2"}, {ID: "M3"}},
+		Destination: &types.DestinationInfo{ID: "mb-archive", Name: "Archive"},
+	})
+
+	stdout, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "verify", path))
+	if !errors.Is(err, ErrSilent) || !strings.Contains(stderr, "not_applied") {
+		t.Fatalf("expected not_applied, got: %v\n%s", err, stderr)
+	}
+
+	var result types.PlanVerifyResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, stdout)
+	}
+	if result.Checked != 3 {
+		t.Errorf("expected 3 checked, got %d", result.Checked)
+	}
+	if len(result.Applied) != 1 || result.Applied[0] != "M1" {
+		t.Errorf("expected M1 applied, got %v", result.Applied)
+	}
+	if len(result.NotApplied) != 1 || result.NotApplied[0] != "M2" {
+		t.Errorf("expected M2 not applied, got %v", result.NotApplied)
+	}
+	if len(result.Missing) != 1 || result.Missing[0] != "M3" {
+		t.Errorf("expected M3 missing, got %v", result.Missing)
+	}
+}
+
+func TestVerify_SucceedsWhenKeywordPlanApplied(t *testing.T) {
+	server := newJMAPMockServer(t,
+		[]map[string]any{{"id": "mb-inbox", "name": "Inbox", "role": "inbox"}},
+		[]map[string]any{
+			{"id": "M1", "mailboxIds": map[string]bool{"mb-inbox": true}, "keywords": map[string]bool{"$seen": true}},
+		},
+		nil,
+	)
+
+	path := writePlan(t, types.DryRunResult{
+		Operation: "mark-read",
+		Count:     1,
+		Emails:    []types.EmailSummary{{ID: "M1"}},
+	})
+
+	stdout, stderr, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "verify", path, "--format", "text"))
+	if err != nil {
+		t.Fatalf("verify failed: %v\nstderr=%s", err, stderr)
+	}
+	if !strings.Contains(stdout, "Verified mark-read: 1 of 1 planned change(s) applied") {
+		t.Errorf("unexpected output:\n%s", stdout)
+	}
+}
+
+func TestVerify_ChecksAddAndRemoveMailboxes(t *testing.T) {
+	server := newJMAPMockServer(t,
+		[]map[string]any{
+			{"id": "mb-inbox", "name": "Inbox", "role": "inbox"},
+			{"id": "mb-receipts", "name": "Receipts"},
+			{"id": "mb-tax", "name": "Tax"},
+		},
+		[]map[string]any{
+			{"id": "M1", "mailboxIds": map[string]bool{"mb-receipts": true, "mb-tax": true}},
+			{"id": "M2", "mailboxIds": map[string]bool{"mb-inbox": true, "mb-receipts": true}},
+		},
+		nil,
+	)
+
+	path := writePlan(t, types.DryRunResult{
+		Operation:  "move",
+		Count:      2,
+		Emails:     []types.EmailSummary{{ID: "M1"}, {ID: "M2"}},
+		AddTo:      []types.DestinationInfo{{ID: "mb-receipts", Name: "Receipts"}},
+		RemoveFrom: []types.DestinationInfo{{ID: "mb-inbox", Name: "Inbox"}},
+	})
+
+	stdout, _, err := runCLICommand(t, commandArgsForServer(t, server.server.URL, "verify", path))
+	if !errors.Is(err, ErrSilent) {
+		t.Fatalf("expected not_applied, got: %v", err)
+	}
+	var result types.PlanVerifyResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, stdout)
+	}
+	if len(result.Applied) != 1 || result.Applied[0] != "M1" || len(result.NotApplied) != 1 || result.NotApplied[0] != "M2" {
+		t.Errorf("expected M1 applied and M2 not, got %+v", result)
+	}
+}
+
+func TestVerify_RejectsUnsupportedPlan(t *testing.T) {
+	path := writePlan(t, types.DryRunResult{Operation: "undo archive"})
+
+	_, stderr, err := runCLICommand(t, []string{"verify", path})
+	if !errors.Is(err, ErrSilent) || !strings.Contains(stderr, "cannot verify a plan for undo archive") {
+		t.Fatalf("expected unsupported plan error, got: %v\n%s", err, stderr)
+	}
+}
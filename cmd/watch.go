@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/cboone/fm/internal/client"
+	"github.com/cboone/fm/internal/metrics"
+	"github.com/cboone/fm/internal/types"
+	"github.com/cboone/fm/internal/webhook"
+)
+
+// minWatchInterval keeps watch from polling the server too aggressively.
+const minWatchInterval = 10 * time.Second
+
+var watchCmd = &cobra.Command{
+	Use:   "watch [flags]",
+	Short: "Report new emails as they arrive",
+	Long: `Poll the server for new emails matching the filter flags and print an
+event for each one until interrupted. Emails already present when watch
+starts are not reported.
+
+With --webhook, each event is also POSTed as JSON to the given URL. When
+webhook_secret is set in the config file (or FM_WEBHOOK_SECRET in the
+environment), requests carry an X-FM-Signature header holding
+"sha256=" followed by the hex HMAC-SHA256 of the body. Failed deliveries are
+reported on stderr and watching continues.
+
+With --metrics-addr, a Prometheus /metrics endpoint is served on that
+address with the unread count for the filter, new-email events, poll and
+webhook errors, JMAP request latency, and HTTP retries, rate limits (429),
+and server errors (5xx).
+
+  fm watch --mailbox inbox --from alerts@example.com
+  fm watch --unread --webhook https://hooks.example.com/fm`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		interval, _ := cmd.Flags().GetDuration("interval")
+		hookURL, _ := cmd.Flags().GetString("webhook")
+		metricsAddr, _ := cmd.Flags().GetString("metrics-addr")
+
+		if interval < minWatchInterval {
+			return exitError("general_error", "--interval must be at least "+minWatchInterval.String(), "")
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return exitError("authentication_failed", err.Error(),
+				"Check your credential command or the token it returns")
+		}
+
+		m := newWatchMetrics()
+		c.SetRequestObserver(m.observeRequest)
+
+		opts, err := parseFilterOptions(cmd, c)
+		if err != nil {
+			return err
+		}
+		if err := excludeTrashAndJunk(cmd, c, &opts); err != nil {
+			return err
+		}
+		unreadOpts := opts
+		unreadOpts.UnreadOnly = true
+
+		start := time.Now().UTC()
+		if opts.After == nil || opts.After.Before(start) {
+			opts.After = &start
+		}
+
+		// The first poll only records what already matches. Later polls
+		// fetch just the changes to the query where the server allows it.
+		query := c.NewStandingQuery(opts)
+		seen := make(map[string]bool)
+		if _, err := pollNewEmails(c, query, seen); err != nil {
+			return exitError("jmap_error", err.Error(), "")
+		}
+
+		if metricsAddr != "" {
+			ln, err := net.Listen("tcp", metricsAddr)
+			if err != nil {
+				return exitError("general_error", "cannot serve metrics: "+err.Error(), "")
+			}
+			mux := http.NewServeMux()
+			mux.Handle("/metrics", m.registry.Handler())
+			srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+			go func() { _ = srv.Serve(ln) }()
+			defer func() { _ = srv.Close() }()
+			m.updateUnread(c, unreadOpts)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		secret := viper.GetString("webhook_secret")
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+
+			m.polls.Inc()
+			emails, err := pollNewEmails(c, query, seen)
+			m.updateRequestStats(c)
+			if err != nil {
+				m.pollErrors.Inc()
+				_ = exitError("jmap_error", err.Error(), "Watching continues; the next poll will retry")
+				continue
+			}
+			if metricsAddr != "" {
+				m.updateUnread(c, unreadOpts)
+			}
+			for _, e := range emails {
+				m.newEmails.Inc()
+				event := types.WatchEvent{
+					Event:      "new_email",
+					DetectedAt: time.Now().UTC(),
+					Email:      e,
+				}
+				if err := formatter().Format(os.Stdout, event); err != nil {
+					return err
+				}
+				if hookURL != "" {
+					if err := webhook.Post(hookURL, secret, event); err != nil {
+						m.webhookErrors.Inc()
+						_ = exitError("network_error", err.Error(), "Watching continues; this event will not be retried")
+					}
+				}
+			}
+		}
+	},
+}
+
+func init() {
+	watchCmd.Flags().Duration("interval", time.Minute, "how often to poll for new emails")
+	watchCmd.Flags().String("webhook", "", "POST each new email as JSON to this URL")
+	watchCmd.Flags().String("metrics-addr", "", "serve Prometheus metrics at /metrics on this address (e.g. :9464)")
+	addFilterFlags(watchCmd)
+	rootCmd.AddCommand(watchCmd)
+}
+
+// watchMetrics are the Prometheus metrics exposed by watch.
+type watchMetrics struct {
+	registry      *metrics.Registry
+	unread        *metrics.Gauge
+	newEmails     *metrics.Counter
+	polls         *metrics.Counter
+	pollErrors    *metrics.Counter
+	webhookErrors *metrics.Counter
+	requests      *metrics.Histogram
+	requestErrors *metrics.Counter
+	retries       *metrics.Counter
+	rateLimited   *metrics.Counter
+	serverErrors  *metrics.Counter
+
+	// counted is the client's RequestStats as of the last update.
+	counted types.RequestStats
+}
+
+func newWatchMetrics() *watchMetrics {
+	r := metrics.NewRegistry()
+	return &watchMetrics{
+		registry:      r,
+		unread:        r.Gauge("fm_unread_emails", "Unread emails matching the watch filter."),
+		newEmails:     r.Counter("fm_new_email_events_total", "New emails reported by watch."),
+		polls:         r.Counter("fm_polls_total", "Polls for new emails."),
+		pollErrors:    r.Counter("fm_poll_errors_total", "Polls that failed."),
+		webhookErrors: r.Counter("fm_webhook_errors_total", "Webhook deliveries that failed."),
+		requests:      r.Histogram("fm_jmap_request_duration_seconds", "JMAP request latency.", metrics.DefaultLatencyBuckets),
+		requestErrors: r.Counter("fm_jmap_request_errors_total", "JMAP requests that failed at the transport level."),
+		retries:       r.Counter("fm_http_retries_total", "HTTP requests retried after a 429 or 503 response."),
+		rateLimited:   r.Counter("fm_http_rate_limited_total", "HTTP responses with status 429."),
+		serverErrors:  r.Counter("fm_http_server_errors_total", "HTTP responses with a 5xx status."),
+	}
+}
+
+func (m *watchMetrics) observeRequest(d time.Duration, err error) {
+	m.requests.Observe(d.Seconds())
+	if err != nil {
+		m.requestErrors.Inc()
+	}
+}
+
+// updateRequestStats adds the retries and error responses c has seen since
+// the last update to their counters.
+func (m *watchMetrics) updateRequestStats(c *client.Client) {
+	s := c.RequestStats()
+	m.retries.Add(float64(s.Retries - m.counted.Retries))
+	m.rateLimited.Add(float64(s.RateLimited - m.counted.RateLimited))
+	m.serverErrors.Add(float64(s.ServerErrors - m.counted.ServerErrors))
+	m.counted = s
+}
+
+// updateUnread refreshes the unread gauge. Failures leave the previous value
+// in place; they are already counted as request errors.
+func (m *watchMetrics) updateUnread(c *client.Client, opts client.SearchOptions) {
+	if n, err := c.CountEmails(opts); err == nil {
+		m.unread.Set(float64(n))
+	}
+}
+
+// pollNewEmails refreshes query and returns summaries of the emails that
+// joined it and are not already in seen, oldest first, recording them in seen.
+func pollNewEmails(c *client.Client, query *client.StandingQuery, seen map[string]bool) ([]types.EmailSummary, error) {
+	ids, err := query.Refresh()
+	if err != nil {
+		return nil, err
+	}
+
+	var fresh []string
+	for _, id := range ids {
+		if !seen[id] {
+			fresh = append(fresh, id)
+		}
+	}
+	if len(fresh) == 0 {
+		return nil, nil
+	}
+
+	emails, _, err := c.GetEmailSummaries(fresh)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range fresh {
+		seen[id] = true
+	}
+	sort.SliceStable(emails, func(i, j int) bool {
+		return emails[i].ReceivedAt.Before(emails[j].ReceivedAt)
+	})
+	return emails, nil
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/cboone/fm/internal/client"
+)
+
+func TestPollNewEmails_ReportsOnlyUnseen(t *testing.T) {
+	server := newJMAPMockServer(t,
+		nil,
+		[]map[string]any{
+			{"id": "M2", "threadId": "T2", "subject": "Later", "receivedAt": "2026-02-14T11:00:00Z"},
+			{"id": "M1", "threadId": "T1", "subject": "Earlier", "receivedAt": "2026-02-14T10:00:00Z"},
+		},
+		nil,
+	)
+
+	c, err := client.New(server.server.URL+"/session", "test-token", "")
+	if err != nil {
+		t.Fatalf("client.New: %v", err)
+	}
+
+	query := c.NewStandingQuery(client.SearchOptions{})
+	seen := make(map[string]bool)
+	emails, err := pollNewEmails(c, query, seen)
+	if err != nil {
+		t.Fatalf("pollNewEmails: %v", err)
+	}
+	if len(emails) != 2 || emails[0].ID != "M1" || emails[1].ID != "M2" {
+		t.Fatalf("expected M1 then M2, oldest first, got %+v", emails)
+	}
+	if !seen["M1"] || !seen["M2"] {
+		t.Error("expected both emails to be recorded as seen")
+	}
+
+	emails, err = pollNewEmails(c, query, seen)
+	if err != nil {
+		t.Fatalf("pollNewEmails: %v", err)
+	}
+	if len(emails) != 0 {
+		t.Errorf("expected no new emails on second poll, got %+v", emails)
+	}
+}
+
+func TestWatch_RejectsShortInterval(t *testing.T) {
+	_, stderr, err := runCLICommand(t, []string{"watch", "--interval", "1s"})
+	if err == nil {
+		t.Fatal("expected error for short interval")
+	}
+	if !strings.Contains(stderr, "--interval must be at least") {
+		t.Fatalf("expected interval error, got: %s", stderr)
+	}
+}
+
+func TestWatchMetrics_RecordsRequestsAndUnread(t *testing.T) {
+	server := newJMAPMockServer(t,
+		nil,
+		[]map[string]any{
+			{"id": "M1", "threadId": "T1", "subject": "One", "receivedAt": "2026-02-14T10:00:00Z"},
+			{"id": "M2", "threadId": "T2", "subject": "Two", "receivedAt": "2026-02-14T11:00:00Z"},
+		},
+		nil,
+	)
+
+	c, err := client.New(server.server.URL+"/session", "test-token", "")
+	if err != nil {
+		t.Fatalf("client.New: %v", err)
+	}
+
+	m := newWatchMetrics()
+	c.SetRequestObserver(m.observeRequest)
+	m.updateUnread(c, client.SearchOptions{UnreadOnly: true})
+
+	var buf bytes.Buffer
+	if err := m.registry.Write(&buf); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "fm_unread_emails 2\n") {
+		t.Errorf("expected unread gauge of 2, got:\n%s", out)
+	}
+	if !strings.Contains(out, "fm_jmap_request_duration_seconds_count 1\n") {
+		t.Errorf("expected one observed request, got:\n%s", out)
+	}
+}
@@ -0,0 +1,159 @@
+// Package authcheck extracts SPF, DKIM, and DMARC verdicts from the
+// Authentication-Results (RFC 8601), Received-SPF (RFC 7208), and
+// DKIM-Signature (RFC 6376) headers of a message.
+package authcheck
+
+import (
+	"strings"
+)
+
+// ResultNone is reported for a mechanism with no verdict in the headers.
+const ResultNone = "none"
+
+// Header is a single raw header field, in message order (topmost first).
+type Header struct {
+	Name  string
+	Value string
+}
+
+// Verdicts holds the authentication outcome for one message.
+type Verdicts struct {
+	AuthServID  string
+	SPF         string
+	DKIM        string
+	DMARC       string
+	DKIMDomains []string
+}
+
+// Failures lists the mechanisms whose verdict indicates a failure, formatted
+// as method=result (e.g. "dmarc=fail").
+func (v Verdicts) Failures() []string {
+	var failures []string
+	for _, m := range []struct{ name, result string }{
+		{"spf", v.SPF},
+		{"dkim", v.DKIM},
+		{"dmarc", v.DMARC},
+	} {
+		if IsFailure(m.result) {
+			failures = append(failures, m.name+"="+m.result)
+		}
+	}
+	return failures
+}
+
+// IsFailure reports whether result is a failing verdict. "none" and
+// "neutral" are not failures: they mean the mechanism made no assertion.
+func IsFailure(result string) bool {
+	switch result {
+	case "fail", "softfail", "permerror", "temperror", "policy":
+		return true
+	}
+	return false
+}
+
+// Parse reads verdicts from headers. Only the topmost Authentication-Results
+// header is trusted, since it is the one added by the receiving server;
+// lower ones may have been forged by the sender. Received-SPF is used as a
+// fallback when Authentication-Results has no SPF result.
+func Parse(headers []Header) Verdicts {
+	v := Verdicts{SPF: ResultNone, DKIM: ResultNone, DMARC: ResultNone}
+
+	var authResults, receivedSPF string
+	var haveAuthResults, haveReceivedSPF bool
+	domains := make(map[string]bool)
+
+	for _, h := range headers {
+		switch strings.ToLower(h.Name) {
+		case "authentication-results":
+			if !haveAuthResults {
+				authResults = h.Value
+				haveAuthResults = true
+			}
+		case "received-spf":
+			if !haveReceivedSPF {
+				receivedSPF = h.Value
+				haveReceivedSPF = true
+			}
+		case "dkim-signature":
+			if d := tagValue(h.Value, "d"); d != "" && !domains[d] {
+				domains[d] = true
+				v.DKIMDomains = append(v.DKIMDomains, d)
+			}
+		}
+	}
+
+	if haveAuthResults {
+		parseAuthResults(stripComments(authResults), &v)
+	}
+	if v.SPF == ResultNone && haveReceivedSPF {
+		if fields := strings.Fields(stripComments(receivedSPF)); len(fields) > 0 {
+			v.SPF = strings.ToLower(fields[0])
+		}
+	}
+
+	return v
+}
+
+// parseAuthResults fills v from an Authentication-Results value with
+// comments removed. When several DKIM signatures were evaluated, any pass
+// wins; otherwise the first result is reported.
+func parseAuthResults(value string, v *Verdicts) {
+	parts := strings.Split(value, ";")
+	v.AuthServID = strings.TrimSpace(firstField(parts[0]))
+
+	dkimSeen := false
+	for _, part := range parts[1:] {
+		method, result, ok := strings.Cut(firstField(part), "=")
+		if !ok {
+			continue
+		}
+		result = strings.ToLower(result)
+		switch strings.ToLower(method) {
+		case "spf":
+			v.SPF = result
+		case "dmarc":
+			v.DMARC = result
+		case "dkim":
+			if !dkimSeen || result == "pass" {
+				v.DKIM = result
+			}
+			dkimSeen = true
+		}
+	}
+}
+
+// tagValue returns the value of a tag=value pair in a DKIM-Signature header.
+func tagValue(header, tag string) string {
+	for _, part := range strings.Split(header, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		if ok && strings.TrimSpace(name) == tag {
+			return strings.ToLower(strings.Join(strings.Fields(value), ""))
+		}
+	}
+	return ""
+}
+
+// stripComments removes RFC 5322 parenthesized comments, which may nest.
+func stripComments(s string) string {
+	var b strings.Builder
+	depth := 0
+	for _, r := range s {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')' && depth > 0:
+			depth--
+		case depth == 0:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func firstField(s string) string {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
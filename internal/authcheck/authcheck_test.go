@@ -0,0 +1,55 @@
+package authcheck
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse_AuthenticationResults(t *testing.T) {
+	v := Parse([]Header{
+		{Name: "Authentication-Results", Value: `mx.messagingengine.com;
+    dkim=fail (bad signature) header.d=example.com header.i=@example.com;
+    dkim=pass (2048-bit rsa key) header.d=esp.example header.i=@esp.example;
+    spf=softfail smtp.mailfrom=bounce@example.com;
+    dmarc=fail policy.published-domain-policy=reject header.from=example.com`},
+		{Name: "Authentication-Results", Value: "forged.example; spf=pass; dkim=pass; dmarc=pass"},
+		{Name: "DKIM-Signature", Value: "v=1; a=rsa-sha256; d=Example.com; s=s1; b=abc"},
+		{Name: "DKIM-Signature", Value: "v=1; a=rsa-sha256; d=esp.example; s=s1; b=def"},
+	})
+
+	if v.AuthServID != "mx.messagingengine.com" {
+		t.Errorf("expected authserv-id from topmost header, got %q", v.AuthServID)
+	}
+	if v.SPF != "softfail" || v.DKIM != "pass" || v.DMARC != "fail" {
+		t.Errorf("expected spf=softfail dkim=pass dmarc=fail, got %+v", v)
+	}
+	if !reflect.DeepEqual(v.DKIMDomains, []string{"example.com", "esp.example"}) {
+		t.Errorf("expected DKIM domains, got %v", v.DKIMDomains)
+	}
+	if !reflect.DeepEqual(v.Failures(), []string{"spf=softfail", "dmarc=fail"}) {
+		t.Errorf("expected failures, got %v", v.Failures())
+	}
+}
+
+func TestParse_ReceivedSPFFallback(t *testing.T) {
+	v := Parse([]Header{
+		{Name: "Received-SPF", Value: "Fail (domain of example.com does not designate 192.0.2.1) client-ip=192.0.2.1"},
+	})
+
+	if v.SPF != "fail" {
+		t.Errorf("expected spf=fail from Received-SPF, got %q", v.SPF)
+	}
+	if v.DKIM != ResultNone || v.DMARC != ResultNone {
+		t.Errorf("expected missing verdicts to be none, got %+v", v)
+	}
+}
+
+func TestParse_NoHeaders(t *testing.T) {
+	v := Parse(nil)
+	if v.SPF != ResultNone || v.DKIM != ResultNone || v.DMARC != ResultNone {
+		t.Errorf("expected all none, got %+v", v)
+	}
+	if len(v.Failures()) != 0 {
+		t.Errorf("expected no failures, got %v", v.Failures())
+	}
+}
@@ -0,0 +1,46 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// AuditEntry records one command that reported emails, such as fm
+// report-phishing, for the audit log.
+type AuditEntry struct {
+	Command     string    `json:"command"`
+	AccountID   string    `json:"account_id"`
+	At          time.Time `json:"at"`
+	Destination string    `json:"destination,omitempty"`
+	Reported    []string  `json:"reported"`
+	Failed      []string  `json:"failed,omitempty"`
+	Evidence    []string  `json:"evidence,omitempty"`
+}
+
+// AppendAudit appends entry to the audit log at path as one line of JSON.
+// Unlike the undo journal, the log is never rewritten or trimmed, so
+// earlier entries survive a later failed or interrupted write.
+func AppendAudit(path string, entry AuditEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encoding audit log entry: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("writing audit log: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("writing audit log: %w", err)
+	}
+	_, err = f.Write(append(data, '\n'))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("writing audit log: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package cache
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAppendAudit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "audit.jsonl")
+	at := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	for _, id := range []string{"M1", "M2"} {
+		entry := AuditEntry{Command: "report-phishing", AccountID: "A1", At: at, Reported: []string{id}}
+		if err := AppendAudit(path, entry); err != nil {
+			t.Fatalf("AppendAudit: %v", err)
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	var reported []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("decode %q: %v", scanner.Text(), err)
+		}
+		if entry.Command != "report-phishing" || !entry.At.Equal(at) {
+			t.Errorf("unexpected entry %+v", entry)
+		}
+		reported = append(reported, entry.Reported...)
+	}
+	if len(reported) != 2 || reported[0] != "M1" || reported[1] != "M2" {
+		t.Errorf("expected both entries in order, got %v", reported)
+	}
+
+	if fi, err := os.Stat(path); err != nil || fi.Mode().Perm() != 0o600 {
+		t.Errorf("expected a 0600 file, got %v (%v)", fi.Mode(), err)
+	}
+}
@@ -0,0 +1,93 @@
+// Package cache persists small pieces of local state between fm invocations,
+// such as the result set returned by the most recent list or search.
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+// ErrNoResults indicates that no cached result set is available.
+var ErrNoResults = errors.New("no cached results; run list or search first")
+
+// LastResult records the result set returned by the most recent list or
+// search. Handle %1 refers to the first email in Result.
+type LastResult struct {
+	Command string                `json:"command"`
+	SavedAt time.Time             `json:"saved_at"`
+	Result  types.EmailListResult `json:"result"`
+}
+
+// IDs returns the cached email IDs in display order.
+func (r LastResult) IDs() []string {
+	ids := make([]string, len(r.Result.Emails))
+	for i, e := range r.Result.Emails {
+		ids[i] = e.ID
+	}
+	return ids
+}
+
+// SaveLast writes r to path, creating parent directories as needed. The file
+// is written to a temporary name and renamed into place so concurrent readers
+// never observe a partial file.
+func SaveLast(path string, r LastResult) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("encoding result cache: %w", err)
+	}
+	if err := writeAtomic(path, data); err != nil {
+		return fmt.Errorf("writing result cache: %w", err)
+	}
+	return nil
+}
+
+// writeAtomic writes data to a temporary file beside path and renames it into
+// place, creating parent directories as needed.
+func writeAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// LoadLast reads the cached result set from path. It returns ErrNoResults
+// when the file does not exist.
+func LoadLast(path string) (LastResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return LastResult{}, ErrNoResults
+		}
+		return LastResult{}, fmt.Errorf("reading result cache: %w", err)
+	}
+
+	var r LastResult
+	if err := json.Unmarshal(data, &r); err != nil {
+		return LastResult{}, fmt.Errorf("decoding result cache: %w", err)
+	}
+	return r, nil
+}
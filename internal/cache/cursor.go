@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+)
+
+// Cursor records the newest email a named --since-last-run job has returned.
+// IDs lists the returned emails received exactly at ReceivedAt, since the
+// server's after filter includes that instant.
+type Cursor struct {
+	ReceivedAt time.Time `json:"received_at"`
+	IDs        []string  `json:"ids"`
+	SavedAt    time.Time `json:"saved_at"`
+}
+
+// Seen reports whether an email received at receivedAt with the given ID was
+// returned by an earlier run.
+func (c Cursor) Seen(id string, receivedAt time.Time) bool {
+	if receivedAt.Before(c.ReceivedAt) {
+		return true
+	}
+	return receivedAt.Equal(c.ReceivedAt) && slices.Contains(c.IDs, id)
+}
+
+// Advance moves the cursor past an email returned by this run.
+func (c *Cursor) Advance(id string, receivedAt time.Time) {
+	switch {
+	case receivedAt.After(c.ReceivedAt):
+		c.ReceivedAt = receivedAt
+		c.IDs = []string{id}
+	case receivedAt.Equal(c.ReceivedAt) && !slices.Contains(c.IDs, id):
+		c.IDs = append(c.IDs, id)
+	}
+}
+
+// LoadCursors reads the cursors at path, keyed by name. A missing file
+// yields an empty map.
+func LoadCursors(path string) (map[string]Cursor, error) {
+	cursors := make(map[string]Cursor)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cursors, nil
+		}
+		return nil, fmt.Errorf("reading cursors: %w", err)
+	}
+	if err := json.Unmarshal(data, &cursors); err != nil {
+		return nil, fmt.Errorf("decoding cursors: %w", err)
+	}
+	return cursors, nil
+}
+
+// SaveCursors writes cursors to path atomically.
+func SaveCursors(path string, cursors map[string]Cursor) error {
+	data, err := json.MarshalIndent(cursors, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding cursors: %w", err)
+	}
+	if err := writeAtomic(path, data); err != nil {
+		return fmt.Errorf("writing cursors: %w", err)
+	}
+	return nil
+}
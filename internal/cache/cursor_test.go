@@ -0,0 +1,53 @@
+package cache
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCursorSeenAndAdvance(t *testing.T) {
+	t1 := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Minute)
+
+	var c Cursor
+	c.Advance("M1", t1)
+	c.Advance("M2", t2)
+	c.Advance("M3", t2)
+	if !c.ReceivedAt.Equal(t2) || len(c.IDs) != 2 {
+		t.Fatalf("expected the cursor at %v with M2 and M3, got %+v", t2, c)
+	}
+
+	if !c.Seen("M1", t1) || !c.Seen("M2", t2) {
+		t.Error("expected M1 and M2 to be seen")
+	}
+	if c.Seen("M4", t2) {
+		t.Error("expected an unseen email received at the cursor time to be new")
+	}
+	if c.Seen("M5", t2.Add(time.Second)) {
+		t.Error("expected an email received after the cursor to be new")
+	}
+}
+
+func TestSaveAndLoadCursors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cursors.json")
+
+	cursors, err := LoadCursors(path)
+	if err != nil || len(cursors) != 0 {
+		t.Fatalf("expected no cursors from a missing file, got %v, %v", cursors, err)
+	}
+
+	received := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	cursors["inbox-cron"] = Cursor{ReceivedAt: received, IDs: []string{"M1"}}
+	if err := SaveCursors(path, cursors); err != nil {
+		t.Fatalf("SaveCursors: %v", err)
+	}
+
+	loaded, err := LoadCursors(path)
+	if err != nil {
+		t.Fatalf("LoadCursors: %v", err)
+	}
+	if c := loaded["inbox-cron"]; !c.ReceivedAt.Equal(received) || len(c.IDs) != 1 || c.IDs[0] != "M1" {
+		t.Errorf("unexpected cursor after round trip: %+v", c)
+	}
+}
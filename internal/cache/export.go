@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// ExportState records where an export to a target file left off, so the
+// next incremental run can ask the server for changes since State. For a
+// mailbox export, Exported lists the IDs already written to the file, so
+// emails moved into the mailbox later can be appended exactly once; it is
+// nil for whole-account exports and for states recorded before it existed.
+type ExportState struct {
+	State     string    `json:"state"`
+	MailboxID string    `json:"mailbox_id,omitempty"`
+	Exported  []string  `json:"exported"`
+	SavedAt   time.Time `json:"saved_at"`
+}
+
+// LoadExportStates reads the export states at path, keyed by absolute target
+// file path. A missing file yields an empty map.
+func LoadExportStates(path string) (map[string]ExportState, error) {
+	states := make(map[string]ExportState)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return states, nil
+		}
+		return nil, fmt.Errorf("reading export state: %w", err)
+	}
+	if err := json.Unmarshal(data, &states); err != nil {
+		return nil, fmt.Errorf("decoding export state: %w", err)
+	}
+	return states, nil
+}
+
+// SaveExportStates writes states to path atomically.
+func SaveExportStates(path string, states map[string]ExportState) error {
+	data, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding export state: %w", err)
+	}
+	if err := writeAtomic(path, data); err != nil {
+		return fmt.Errorf("writing export state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package cache
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveAndLoadExportStates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "export-state.json")
+
+	states, err := LoadExportStates(path)
+	if err != nil {
+		t.Fatalf("LoadExportStates on missing file: %v", err)
+	}
+	if len(states) != 0 {
+		t.Fatalf("expected no states, got %v", states)
+	}
+
+	states["/backup/inbox.mbox"] = ExportState{State: "s42", MailboxID: "mb-inbox"}
+	if err := SaveExportStates(path, states); err != nil {
+		t.Fatalf("SaveExportStates: %v", err)
+	}
+
+	loaded, err := LoadExportStates(path)
+	if err != nil {
+		t.Fatalf("LoadExportStates: %v", err)
+	}
+	if got := loaded["/backup/inbox.mbox"]; got.State != "s42" || got.MailboxID != "mb-inbox" {
+		t.Errorf("expected state s42 for mb-inbox, got %+v", got)
+	}
+}
@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// IsHandle reports whether arg uses the short handle syntax (%N or %N-%M).
+func IsHandle(arg string) bool {
+	return strings.HasPrefix(arg, "%")
+}
+
+// HasHandles reports whether any of args uses the short handle syntax.
+func HasHandles(args []string) bool {
+	for _, a := range args {
+		if IsHandle(a) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExpandHandles replaces handle arguments with the corresponding IDs from ids.
+// Handles are 1-based: %1 is ids[0]. Ranges (%4-%7 or %4-7) are inclusive.
+// Arguments that are not handles are passed through unchanged.
+func ExpandHandles(args []string, ids []string) ([]string, error) {
+	out := make([]string, 0, len(args))
+	for _, arg := range args {
+		if !IsHandle(arg) {
+			out = append(out, arg)
+			continue
+		}
+
+		start, end, err := parseHandle(arg)
+		if err != nil {
+			return nil, err
+		}
+		if end > len(ids) {
+			return nil, fmt.Errorf("handle %s is out of range: the last result set has %d email(s)", arg, len(ids))
+		}
+		out = append(out, ids[start-1:end]...)
+	}
+	return out, nil
+}
+
+// parseHandle parses %N or %N-%M (the second % is optional) into an inclusive
+// 1-based range.
+func parseHandle(arg string) (int, int, error) {
+	spec := strings.TrimPrefix(arg, "%")
+	lo, hi, isRange := strings.Cut(spec, "-")
+
+	start, err := strconv.Atoi(lo)
+	if err != nil || start < 1 {
+		return 0, 0, fmt.Errorf("invalid handle %q: expected %%N or %%N-%%M with N >= 1", arg)
+	}
+	if !isRange {
+		return start, start, nil
+	}
+
+	end, err := strconv.Atoi(strings.TrimPrefix(hi, "%"))
+	if err != nil || end < start {
+		return 0, 0, fmt.Errorf("invalid handle range %q: end must be a number not less than start", arg)
+	}
+	return start, end, nil
+}
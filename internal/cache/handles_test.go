@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+func TestExpandHandles(t *testing.T) {
+	ids := []string{"M1", "M2", "M3", "M4", "M5", "M6", "M7"}
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{name: "single handle", args: []string{"%3"}, want: []string{"M3"}},
+		{name: "range", args: []string{"%4-%7"}, want: []string{"M4", "M5", "M6", "M7"}},
+		{name: "range without second percent", args: []string{"%1-2"}, want: []string{"M1", "M2"}},
+		{name: "mixed handles", args: []string{"%1", "%4-%5"}, want: []string{"M1", "M4", "M5"}},
+		{name: "raw IDs pass through", args: []string{"Mraw", "%2"}, want: []string{"Mraw", "M2"}},
+		{name: "single-element range", args: []string{"%6-%6"}, want: []string{"M6"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandHandles(tt.args, ids)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestExpandHandles_Errors(t *testing.T) {
+	ids := []string{"M1", "M2", "M3"}
+
+	for _, arg := range []string{"%0", "%", "%x", "%3-%1", "%2-%x", "%4", "%2-%9"} {
+		t.Run(arg, func(t *testing.T) {
+			if _, err := ExpandHandles([]string{arg}, ids); err == nil {
+				t.Errorf("expected error for %q", arg)
+			}
+		})
+	}
+}
+
+func TestHasHandles(t *testing.T) {
+	if HasHandles([]string{"M1", "M2"}) {
+		t.Error("expected no handles in raw IDs")
+	}
+	if !HasHandles([]string{"M1", "%2"}) {
+		t.Error("expected handle to be detected")
+	}
+}
+
+func TestSaveAndLoadLast(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "last.json")
+	saved := LastResult{
+		Command: "list",
+		SavedAt: time.Date(2026, 2, 4, 10, 30, 0, 0, time.UTC),
+		Result: types.EmailListResult{
+			Total:  2,
+			Emails: []types.EmailSummary{{ID: "M1"}, {ID: "M2"}},
+		},
+	}
+
+	if err := SaveLast(path, saved); err != nil {
+		t.Fatalf("SaveLast: %v", err)
+	}
+
+	loaded, err := LoadLast(path)
+	if err != nil {
+		t.Fatalf("LoadLast: %v", err)
+	}
+	if !reflect.DeepEqual(loaded.IDs(), []string{"M1", "M2"}) {
+		t.Errorf("expected IDs [M1 M2], got %v", loaded.IDs())
+	}
+	if loaded.Command != "list" || loaded.Result.Total != 2 {
+		t.Errorf("expected command=list total=2, got %+v", loaded)
+	}
+}
+
+func TestLoadLast_Missing(t *testing.T) {
+	_, err := LoadLast(filepath.Join(t.TempDir(), "missing.json"))
+	if !errors.Is(err, ErrNoResults) {
+		t.Errorf("expected ErrNoResults, got %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// maxJournalEntries is how many operations the undo journal keeps.
+const maxJournalEntries = 20
+
+// JournalEntry records the mailboxes of the emails one command moved, as
+// they were before the move, so fm undo can put them back.
+type JournalEntry struct {
+	Command   string              `json:"command"`
+	AccountID string              `json:"account_id"`
+	At        time.Time           `json:"at"`
+	Previous  map[string][]string `json:"previous"`
+}
+
+// LoadJournal reads the undo journal at path, oldest entry first. A missing
+// file yields an empty journal.
+func LoadJournal(path string) ([]JournalEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []JournalEntry{}, nil
+		}
+		return nil, fmt.Errorf("reading undo journal: %w", err)
+	}
+	var entries []JournalEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("decoding undo journal: %w", err)
+	}
+	return entries, nil
+}
+
+// SaveJournal writes entries to path atomically, keeping only the newest
+// maxJournalEntries.
+func SaveJournal(path string, entries []JournalEntry) error {
+	if len(entries) > maxJournalEntries {
+		entries = entries[len(entries)-maxJournalEntries:]
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("encoding undo journal: %w", err)
+	}
+	if err := writeAtomic(path, data); err != nil {
+		return fmt.Errorf("writing undo journal: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSaveAndLoadJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.json")
+
+	entries, err := LoadJournal(path)
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("expected an empty journal from a missing file, got %v, %v", entries, err)
+	}
+
+	at := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	entries = append(entries, JournalEntry{
+		Command:   "archive",
+		AccountID: "A1",
+		At:        at,
+		Previous:  map[string][]string{"M1": {"mb-inbox"}},
+	})
+	if err := SaveJournal(path, entries); err != nil {
+		t.Fatalf("SaveJournal: %v", err)
+	}
+
+	loaded, err := LoadJournal(path)
+	if err != nil {
+		t.Fatalf("LoadJournal: %v", err)
+	}
+	if len(loaded) != 1 || loaded[0].Command != "archive" || !loaded[0].At.Equal(at) ||
+		len(loaded[0].Previous["M1"]) != 1 || loaded[0].Previous["M1"][0] != "mb-inbox" {
+		t.Errorf("unexpected journal after round trip: %+v", loaded)
+	}
+}
+
+func TestSaveJournalKeepsNewest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.json")
+
+	var entries []JournalEntry
+	for i := range maxJournalEntries + 5 {
+		entries = append(entries, JournalEntry{Command: fmt.Sprintf("archive-%d", i)})
+	}
+	if err := SaveJournal(path, entries); err != nil {
+		t.Fatalf("SaveJournal: %v", err)
+	}
+
+	loaded, err := LoadJournal(path)
+	if err != nil {
+		t.Fatalf("LoadJournal: %v", err)
+	}
+	if len(loaded) != maxJournalEntries {
+		t.Fatalf("expected %d entries, got %d", maxJournalEntries, len(loaded))
+	}
+	if loaded[0].Command != "archive-5" || loaded[len(loaded)-1].Command != fmt.Sprintf("archive-%d", maxJournalEntries+4) {
+		t.Errorf("expected the oldest entries to be dropped, got %s..%s", loaded[0].Command, loaded[len(loaded)-1].Command)
+	}
+}
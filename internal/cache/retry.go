@@ -0,0 +1,15 @@
+package cache
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SaveRetry writes the IDs of emails a bulk action failed on to path, one
+// per line, in the format --ids-file reads.
+func SaveRetry(path string, ids []string) error {
+	if err := writeAtomic(path, []byte(strings.Join(ids, "\n")+"\n")); err != nil {
+		return fmt.Errorf("writing retry file: %w", err)
+	}
+	return nil
+}
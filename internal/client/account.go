@@ -0,0 +1,95 @@
+package client
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"git.sr.ht/~rockorager/go-jmap"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+// SelectAccount makes the account identified by nameOrID active. It matches
+// an account ID exactly, or an account name (usually its email address)
+// case-insensitively.
+func (c *Client) SelectAccount(nameOrID string) error {
+	s := c.jmap.Session
+	if _, ok := s.Accounts[jmap.ID(nameOrID)]; ok {
+		c.useAccount(jmap.ID(nameOrID))
+		return nil
+	}
+
+	var matches []jmap.ID
+	for id, acct := range s.Accounts {
+		if strings.EqualFold(acct.Name, nameOrID) {
+			matches = append(matches, id)
+		}
+	}
+	switch len(matches) {
+	case 1:
+		c.useAccount(matches[0])
+		return nil
+	case 0:
+		return fmt.Errorf("no account named %q (run fm accounts to list them)", nameOrID)
+	default:
+		ids := make([]string, len(matches))
+		for i, id := range matches {
+			ids[i] = string(id)
+		}
+		sort.Strings(ids)
+		return fmt.Errorf("account name %q is ambiguous; use one of the IDs %s", nameOrID, strings.Join(ids, ", "))
+	}
+}
+
+// useAccount switches to account id, dropping caches of the previous one.
+func (c *Client) useAccount(id jmap.ID) {
+	c.accountID = id
+	c.mailboxCache = nil
+	c.identityCache = nil
+}
+
+// Accounts lists the accounts in the session, personal accounts first, with
+// the capabilities each is the primary account for.
+func (c *Client) Accounts() []types.AccountEntry {
+	s := c.jmap.Session
+	primaryFor := make(map[jmap.ID][]string)
+	for uri, id := range s.PrimaryAccounts {
+		primaryFor[id] = append(primaryFor[id], string(uri))
+	}
+
+	accounts := make([]types.AccountEntry, 0, len(s.Accounts))
+	for id, acct := range s.Accounts {
+		caps := make([]string, 0, len(acct.RawCapabilities))
+		for uri := range acct.RawCapabilities {
+			caps = append(caps, string(uri))
+		}
+		sort.Strings(caps)
+		primary := primaryFor[id]
+		sort.Strings(primary)
+		if primary == nil {
+			primary = []string{}
+		}
+
+		accounts = append(accounts, types.AccountEntry{
+			ID:           string(id),
+			Name:         acct.Name,
+			IsPersonal:   acct.IsPersonal,
+			IsReadOnly:   acct.IsReadOnly,
+			Active:       id == c.accountID,
+			PrimaryFor:   primary,
+			Capabilities: caps,
+		})
+	}
+
+	sort.Slice(accounts, func(i, j int) bool {
+		if accounts[i].IsPersonal != accounts[j].IsPersonal {
+			return accounts[i].IsPersonal
+		}
+		if accounts[i].Name != accounts[j].Name {
+			return accounts[i].Name < accounts[j].Name
+		}
+		return accounts[i].ID < accounts[j].ID
+	})
+	return accounts
+}
@@ -0,0 +1,86 @@
+package client
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail"
+	"git.sr.ht/~rockorager/go-jmap/mail/mailbox"
+)
+
+func accountsTestClient() *Client {
+	return &Client{
+		accountID: "u1",
+		jmap: &jmap.Client{Session: &jmap.Session{
+			Accounts: map[jmap.ID]jmap.Account{
+				"u1": {Name: "me@example.com", IsPersonal: true, RawCapabilities: map[jmap.URI]json.RawMessage{
+					mail.URI: json.RawMessage("{}"), jmap.CoreURI: json.RawMessage("{}"),
+				}},
+				"s1": {Name: "Team@Example.com", IsReadOnly: true, RawCapabilities: map[jmap.URI]json.RawMessage{
+					mail.URI: json.RawMessage("{}"),
+				}},
+				"s2": {Name: "shared@example.com"},
+				"s3": {Name: "shared@example.com"},
+			},
+			PrimaryAccounts: map[jmap.URI]jmap.ID{mail.URI: "u1", jmap.CoreURI: "u1"},
+		}},
+		mailboxCache: []*mailbox.Mailbox{{ID: "mb1"}},
+	}
+}
+
+func TestSelectAccount(t *testing.T) {
+	c := accountsTestClient()
+	if err := c.SelectAccount("team@example.com"); err != nil {
+		t.Fatalf("select by name: %v", err)
+	}
+	if c.AccountID() != "s1" {
+		t.Errorf("expected s1, got %s", c.AccountID())
+	}
+	if c.mailboxCache != nil {
+		t.Error("expected the mailbox cache to be dropped")
+	}
+
+	if err := c.SelectAccount("u1"); err != nil || c.AccountID() != "u1" {
+		t.Errorf("select by ID: got %s, err %v", c.AccountID(), err)
+	}
+
+	err := c.SelectAccount("nobody@example.com")
+	if err == nil || !strings.Contains(err.Error(), "no account named") {
+		t.Errorf("expected a not-found error, got %v", err)
+	}
+
+	err = c.SelectAccount("shared@example.com")
+	if err == nil || !strings.Contains(err.Error(), "s2, s3") {
+		t.Errorf("expected an ambiguity error listing both IDs, got %v", err)
+	}
+	if c.AccountID() != "u1" {
+		t.Errorf("failed selection changed the account to %s", c.AccountID())
+	}
+}
+
+func TestAccounts(t *testing.T) {
+	accounts := accountsTestClient().Accounts()
+	if len(accounts) != 4 {
+		t.Fatalf("expected 4 accounts, got %d", len(accounts))
+	}
+
+	me := accounts[0]
+	if me.ID != "u1" || !me.Active || !me.IsPersonal {
+		t.Errorf("expected the active personal account first, got %+v", me)
+	}
+	if len(me.PrimaryFor) != 2 || me.PrimaryFor[0] != string(jmap.CoreURI) || me.PrimaryFor[1] != string(mail.URI) {
+		t.Errorf("unexpected primary_for %v", me.PrimaryFor)
+	}
+
+	if accounts[1].ID != "s1" || accounts[1].Active || !accounts[1].IsReadOnly {
+		t.Errorf("expected the read-only shared account next, got %+v", accounts[1])
+	}
+	if accounts[1].PrimaryFor == nil || len(accounts[1].PrimaryFor) != 0 {
+		t.Errorf("expected empty primary_for, got %v", accounts[1].PrimaryFor)
+	}
+	if accounts[2].ID != "s2" || accounts[3].ID != "s3" {
+		t.Errorf("expected same-name accounts ordered by ID, got %s, %s", accounts[2].ID, accounts[3].ID)
+	}
+}
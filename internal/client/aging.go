@@ -0,0 +1,80 @@
+package client
+
+import (
+	"fmt"
+	"time"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+// agingBuckets returns the age buckets for now, newest first: today since
+// local midnight, this week and this month reaching back 7 and 30 days
+// including today, and everything older. Since and Before bound each bucket
+// as receivedAt >= Since and receivedAt < Before.
+func agingBuckets(now time.Time) []types.AgingBucket {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	week := today.AddDate(0, 0, -6)
+	month := today.AddDate(0, 0, -29)
+	return []types.AgingBucket{
+		{Name: "today", Since: &today},
+		{Name: "this_week", Since: &week, Before: &today},
+		{Name: "this_month", Since: &month, Before: &week},
+		{Name: "older", Before: &month},
+	}
+}
+
+// AgingReport counts the emails in mailboxID by age as of now, with the
+// unread ones (no $seen) in each bucket. All counts come from one request,
+// so they add up even while mail arrives.
+func (c *Client) AgingReport(mailboxID jmap.ID, now time.Time) (types.AgingResult, error) {
+	result := types.AgingResult{Buckets: agingBuckets(now)}
+
+	req := &jmap.Request{}
+	totalCallIDs := make(map[string]int, len(result.Buckets))
+	unreadCallIDs := make(map[string]int, len(result.Buckets))
+	for i, b := range result.Buckets {
+		cond := email.FilterCondition{InMailbox: mailboxID, After: b.Since, Before: b.Before}
+		total := cond
+		totalCallIDs[req.Invoke(&email.Query{
+			Account:        c.accountID,
+			Filter:         &total,
+			Limit:          1,
+			CalculateTotal: true,
+		})] = i
+		unread := cond
+		unread.NotKeyword = "$seen"
+		unreadCallIDs[req.Invoke(&email.Query{
+			Account:        c.accountID,
+			Filter:         &unread,
+			Limit:          1,
+			CalculateTotal: true,
+		})] = i
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return types.AgingResult{}, fmt.Errorf("email/query: %w", err)
+	}
+
+	for _, inv := range resp.Responses {
+		switch r := inv.Args.(type) {
+		case *email.QueryResponse:
+			if i, ok := totalCallIDs[inv.CallID]; ok {
+				result.Buckets[i].Total = r.Total
+			} else if i, ok := unreadCallIDs[inv.CallID]; ok {
+				result.Buckets[i].Unread = r.Total
+			}
+		case *jmap.MethodError:
+			return types.AgingResult{}, fmt.Errorf("email/query: %s", r.Error())
+		}
+	}
+
+	for _, b := range result.Buckets {
+		result.Total += b.Total
+		result.Unread += b.Unread
+	}
+	return result, nil
+}
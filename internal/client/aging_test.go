@@ -0,0 +1,81 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+)
+
+func TestAgingReport(t *testing.T) {
+	loc := time.FixedZone("EST", -5*3600)
+	now := time.Date(2026, 3, 31, 15, 30, 0, 0, loc)
+	totals := []uint64{3, 10, 20, 100}
+	unread := []uint64{2, 4, 1, 0}
+
+	var queries []*email.Query
+	c := &Client{
+		accountID: "test-account",
+		doFunc: func(req *jmap.Request) (*jmap.Response, error) {
+			resp := &jmap.Response{}
+			for i, call := range req.Calls {
+				queries = append(queries, call.Args.(*email.Query))
+				n := totals[i/2]
+				if i%2 == 1 {
+					n = unread[i/2]
+				}
+				resp.Responses = append(resp.Responses, &jmap.Invocation{
+					Name: "Email/query", CallID: call.CallID, Args: &email.QueryResponse{Total: n},
+				})
+			}
+			return resp, nil
+		},
+	}
+
+	result, err := c.AgingReport("mb-inbox", now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(queries) != 8 {
+		t.Fatalf("expected 8 queries in one request, got %d", len(queries))
+	}
+	if result.Total != 133 || result.Unread != 7 {
+		t.Errorf("expected 133 total and 7 unread, got %d and %d", result.Total, result.Unread)
+	}
+
+	midnight := time.Date(2026, 3, 31, 0, 0, 0, 0, loc)
+	want := []struct {
+		name          string
+		since, before *time.Time
+	}{
+		{"today", &midnight, nil},
+		{"this_week", ptr(midnight.AddDate(0, 0, -6)), &midnight},
+		{"this_month", ptr(time.Date(2026, 3, 2, 0, 0, 0, 0, loc)), ptr(midnight.AddDate(0, 0, -6))},
+		{"older", nil, ptr(time.Date(2026, 3, 2, 0, 0, 0, 0, loc))},
+	}
+	for i, w := range want {
+		b := result.Buckets[i]
+		if b.Name != w.name || !sameTime(b.Since, w.since) || !sameTime(b.Before, w.before) {
+			t.Errorf("bucket %d: got %s [%v, %v), want %s [%v, %v)", i, b.Name, b.Since, b.Before, w.name, w.since, w.before)
+		}
+		if b.Total != totals[i] || b.Unread != unread[i] {
+			t.Errorf("bucket %s: got %d/%d, want %d/%d", b.Name, b.Total, b.Unread, totals[i], unread[i])
+		}
+
+		fc := queries[2*i+1].Filter.(*email.FilterCondition)
+		if fc.InMailbox != "mb-inbox" || fc.NotKeyword != "$seen" || !sameTime(fc.After, w.since) || !sameTime(fc.Before, w.before) {
+			t.Errorf("bucket %s: unexpected unread filter %+v", b.Name, fc)
+		}
+	}
+}
+
+func ptr(t time.Time) *time.Time { return &t }
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
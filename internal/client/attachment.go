@@ -0,0 +1,190 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+
+	"github.com/cboone/fm/internal/mimeword"
+	"github.com/cboone/fm/internal/types"
+)
+
+// Attachment is a downloadable part of an email. Inline is set for parts
+// the HTML body shows in place through a cid: reference, such as
+// screenshots pasted into a message or signature logos; ContentID names
+// them.
+type Attachment struct {
+	PartID    string
+	BlobID    string
+	Name      string
+	Type      string
+	Size      uint64
+	ContentID string
+	Inline    bool
+}
+
+// ListAttachments returns the attachments and inline images of an email.
+func (c *Client) ListAttachments(emailID string) ([]Attachment, error) {
+	req := &jmap.Request{}
+	req.Invoke(&email.Get{
+		Account:        c.accountID,
+		IDs:            []jmap.ID{jmap.ID(emailID)},
+		Properties:     []string{"id", "attachments"},
+		BodyProperties: []string{"partId", "blobId", "size", "name", "type", "cid", "disposition"},
+	})
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("email/get: %w", err)
+	}
+
+	for _, inv := range resp.Responses {
+		switch r := inv.Args.(type) {
+		case *email.GetResponse:
+			if len(r.NotFound) > 0 || len(r.List) == 0 {
+				return nil, fmt.Errorf("email %s: %w", emailID, ErrNotFound)
+			}
+			return convertAttachments(r.List[0].Attachments), nil
+		case *jmap.MethodError:
+			return nil, fmt.Errorf("email/get: %s", r.Error())
+		}
+	}
+
+	return nil, fmt.Errorf("email/get: unexpected response")
+}
+
+// EmailAttachments is the attachments of one email, with the fields needed
+// to tell which email they came from.
+type EmailAttachments struct {
+	ID          string
+	Subject     string
+	From        []types.Address
+	ReceivedAt  time.Time
+	Attachments []Attachment
+}
+
+// GetAttachments returns the attachments and inline images of each of ids,
+// in the order given. Emails that do not exist are left out.
+func (c *Client) GetAttachments(ids []string) ([]EmailAttachments, error) {
+	byID := make(map[string]EmailAttachments, len(ids))
+	size := c.maxGetSize()
+	for start := 0; start < len(ids); start += size {
+		batch := ids[start:min(start+size, len(ids))]
+		jmapIDs := make([]jmap.ID, len(batch))
+		for i, id := range batch {
+			jmapIDs[i] = jmap.ID(id)
+		}
+
+		req := &jmap.Request{}
+		req.Invoke(&email.Get{
+			Account:        c.accountID,
+			IDs:            jmapIDs,
+			Properties:     []string{"id", "subject", "from", "receivedAt", "attachments"},
+			BodyProperties: []string{"partId", "blobId", "size", "name", "type", "cid", "disposition"},
+		})
+
+		resp, err := c.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("email/get: %w", err)
+		}
+
+		for _, inv := range resp.Responses {
+			switch r := inv.Args.(type) {
+			case *email.GetResponse:
+				for _, e := range r.List {
+					ea := EmailAttachments{
+						ID:          string(e.ID),
+						Subject:     e.Subject,
+						From:        convertAddresses(e.From),
+						Attachments: convertAttachments(e.Attachments),
+					}
+					if e.ReceivedAt != nil {
+						ea.ReceivedAt = *e.ReceivedAt
+					}
+					byID[ea.ID] = ea
+				}
+			case *jmap.MethodError:
+				return nil, fmt.Errorf("email/get: %s", r.Error())
+			}
+		}
+	}
+
+	result := make([]EmailAttachments, 0, len(byID))
+	for _, id := range ids {
+		if ea, ok := byID[id]; ok {
+			result = append(result, ea)
+		}
+	}
+	return result, nil
+}
+
+// convertAttachments turns the attachment parts of an email into
+// Attachments.
+func convertAttachments(parts []*email.BodyPart) []Attachment {
+	var atts []Attachment
+	for _, part := range parts {
+		atts = append(atts, Attachment{
+			PartID:    part.PartID,
+			BlobID:    string(part.BlobID),
+			Name:      mimeword.Decode(part.Name),
+			Type:      part.Type,
+			Size:      part.Size,
+			ContentID: contentID(part),
+			Inline:    isInlinePart(part),
+		})
+	}
+	return atts
+}
+
+// DownloadAttachment returns the content of an attachment. The caller must
+// close the returned reader.
+func (c *Client) DownloadAttachment(a Attachment) (io.ReadCloser, error) {
+	body, err := c.Download(c.accountID, jmap.ID(a.BlobID))
+	if err != nil {
+		return nil, fmt.Errorf("downloading part %s: %w", a.PartID, err)
+	}
+	return body, nil
+}
+
+// isInlinePart reports whether part is shown inside the body rather than
+// offered as a file: an inline image, or any part with a Content-ID whose
+// Content-Disposition is inline. Parts marked inline without a Content-ID,
+// as some mail clients send every attachment, still count as attachments.
+func isInlinePart(part *email.BodyPart) bool {
+	if isInlineImage(part) {
+		return true
+	}
+	return contentID(part) != "" && strings.EqualFold(part.Disposition, "inline")
+}
+
+// attachmentBytes sums the sizes of parts that are not inline.
+func attachmentBytes(parts []*email.BodyPart) uint64 {
+	var total uint64
+	for _, part := range parts {
+		if !isInlinePart(part) {
+			total += part.Size
+		}
+	}
+	return total
+}
+
+// countAttachments counts the parts that are not inline.
+func countAttachments(parts []*email.BodyPart) int {
+	n := 0
+	for _, part := range parts {
+		if !isInlinePart(part) {
+			n++
+		}
+	}
+	return n
+}
+
+// isInlineImage reports whether part is an image with a Content-ID, which
+// HTML bodies reference as a cid: URL.
+func isInlineImage(part *email.BodyPart) bool {
+	return contentID(part) != "" && strings.HasPrefix(part.Type, "image/")
+}
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"testing"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+)
+
+func TestIsInlinePart(t *testing.T) {
+	tests := []struct {
+		name string
+		part *email.BodyPart
+		want bool
+	}{
+		{"attachment", &email.BodyPart{Type: "application/pdf", Disposition: "attachment"}, false},
+		{"image with content ID", &email.BodyPart{Type: "image/png", CID: "<logo>"}, true},
+		{"inline part with content ID", &email.BodyPart{Type: "application/octet-stream", Disposition: "inline", CID: "<sig>"}, true},
+		{"inline part without content ID", &email.BodyPart{Type: "application/pdf", Disposition: "inline", Name: "report.pdf"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isInlinePart(tt.part); got != tt.want {
+				t.Errorf("isInlinePart() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAttachmentTotals_SkipInline(t *testing.T) {
+	parts := []*email.BodyPart{
+		{Type: "application/pdf", Size: 2400},
+		{Type: "image/png", CID: "<logo>", Size: 800},
+	}
+	if got := attachmentBytes(parts); got != 2400 {
+		t.Errorf("attachmentBytes() = %d, want 2400", got)
+	}
+	if got := countAttachments(parts); got != 1 {
+		t.Errorf("countAttachments() = %d, want 1", got)
+	}
+}
+
+func TestListAttachments(t *testing.T) {
+	e := &email.Email{
+		ID: "M1",
+		Attachments: []*email.BodyPart{
+			{PartID: "2", BlobID: "B-pdf", Name: "agenda.pdf", Type: "application/pdf", Size: 2400},
+			{PartID: "3", BlobID: "B-shot", Name: "image001.png", Type: "image/png", Size: 800, CID: "<ii_m1abc>"},
+		},
+	}
+	var downloads []jmap.ID
+	atts, err := htmlTestClient(e, &downloads).ListAttachments("M1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(atts) != 2 {
+		t.Fatalf("expected 2 attachments, got %d", len(atts))
+	}
+	if atts[0].Inline || atts[0].Name != "agenda.pdf" || atts[0].BlobID != "B-pdf" {
+		t.Errorf("expected agenda.pdf as a regular attachment, got %+v", atts[0])
+	}
+	if !atts[1].Inline || atts[1].ContentID != "ii_m1abc" {
+		t.Errorf("expected an inline image with content ID ii_m1abc, got %+v", atts[1])
+	}
+}
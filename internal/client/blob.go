@@ -0,0 +1,59 @@
+package client
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"git.sr.ht/~rockorager/go-jmap"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+// DownloadBlob returns the content of the blob blobID from the session's
+// download URL. The caller must close the returned reader.
+func (c *Client) DownloadBlob(blobID string) (io.ReadCloser, error) {
+	body, err := c.Download(c.accountID, jmap.ID(blobID))
+	if err != nil {
+		// go-jmap reports HTTP failures only as text.
+		if strings.HasPrefix(err.Error(), "HTTP 404") {
+			return nil, fmt.Errorf("blob %s: %w", blobID, ErrNotFound)
+		}
+		return nil, fmt.Errorf("downloading blob %s: %w", blobID, err)
+	}
+	return body, nil
+}
+
+// BlobInfo downloads the blob blobID and describes it: its size, SHA-256
+// digest, and media type as sniffed from its first bytes, since the
+// download URL does not report the type the blob was stored with.
+func (c *Client) BlobInfo(blobID string) (types.BlobInfo, error) {
+	body, err := c.DownloadBlob(blobID)
+	if err != nil {
+		return types.BlobInfo{}, err
+	}
+	defer func() { _ = body.Close() }()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(body, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return types.BlobInfo{}, fmt.Errorf("downloading blob %s: %w", blobID, err)
+	}
+	head = head[:n]
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, io.MultiReader(bytes.NewReader(head), body))
+	if err != nil {
+		return types.BlobInfo{}, fmt.Errorf("downloading blob %s: %w", blobID, err)
+	}
+	return types.BlobInfo{
+		BlobID: blobID,
+		Size:   size,
+		Type:   http.DetectContentType(head),
+		SHA256: hex.EncodeToString(hash.Sum(nil)),
+	}, nil
+}
@@ -0,0 +1,162 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/core"
+	"git.sr.ht/~rockorager/go-jmap/mail"
+
+	"github.com/cboone/fm/internal/jmap/maskedemail"
+	"github.com/cboone/fm/internal/jmap/sieve"
+	"github.com/cboone/fm/internal/types"
+)
+
+// ErrUnsupported indicates that the server does not advertise a capability
+// a command needs.
+var ErrUnsupported = errors.New("not supported by this server")
+
+// maxScanPageSize is the page size for scans that fetch each page of
+// Email/query results with one Email/get.
+const maxScanPageSize = 500
+
+// unsupportedError reports a missing capability, or with no capability a
+// missing optional feature such as a sort property. It matches
+// ErrUnsupported.
+type unsupportedError struct {
+	feature    string
+	capability jmap.URI
+}
+
+func (e *unsupportedError) Error() string {
+	if e.capability == "" {
+		return fmt.Sprintf("server does not support %s", e.feature)
+	}
+	return fmt.Sprintf("server does not support %s (missing %s capability)", e.feature, e.capability)
+}
+
+func (e *unsupportedError) Is(target error) bool { return target == ErrUnsupported }
+
+// featureCapabilities are the fm features that need a capability beyond
+// core. Servers other than Fastmail usually lack the last two.
+var featureCapabilities = []struct {
+	name       string
+	capability jmap.URI
+	commands   []string
+}{
+	{"mail", mail.URI, []string{"list", "search", "read", "archive", "move", "draft"}},
+	{"sieve scripts", sieve.URI, []string{"sieve"}},
+	{"masked email", maskedemail.URI, []string{"masked"}},
+}
+
+// hasCapability reports whether the session advertises uri.
+func (c *Client) hasCapability(uri jmap.URI) bool {
+	if c.jmap == nil || c.jmap.Session == nil {
+		return false
+	}
+	_, ok := c.jmap.Session.RawCapabilities[uri]
+	return ok
+}
+
+// requireCapability returns an error matching ErrUnsupported when the
+// session does not advertise uri.
+func (c *Client) requireCapability(feature string, uri jmap.URI) error {
+	if !c.hasCapability(uri) {
+		return &unsupportedError{feature: feature, capability: uri}
+	}
+	return nil
+}
+
+// coreLimits returns the server's core capability, or nil when it is not
+// known.
+func (c *Client) coreLimits() *core.Core {
+	if c == nil || c.jmap == nil || c.jmap.Session == nil {
+		return nil
+	}
+	coreCap, _ := c.jmap.Session.Capabilities[jmap.CoreURI].(*core.Core)
+	return coreCap
+}
+
+// maxCallsInRequest returns the server's MaxCallsInRequest, falling back to
+// 16, the smallest limit RFC 8620 recommends, when unavailable.
+func (c *Client) maxCallsInRequest() int {
+	if l := c.coreLimits(); l != nil && l.MaxCallsInRequest > 0 {
+		return int(l.MaxCallsInRequest)
+	}
+	return 16
+}
+
+// maxGetSize returns the server's MaxObjectsInGet, falling back to
+// defaultBatchSize when unavailable.
+func (c *Client) maxGetSize() int {
+	if l := c.coreLimits(); l != nil && l.MaxObjectsInGet > 0 {
+		return int(l.MaxObjectsInGet)
+	}
+	return defaultBatchSize
+}
+
+// MaxObjectsInGet returns the server's MaxObjectsInGet, or 0 when the
+// server does not advertise one.
+func (c *Client) MaxObjectsInGet() int {
+	if l := c.coreLimits(); l != nil {
+		return int(l.MaxObjectsInGet)
+	}
+	return 0
+}
+
+// scanPageSize returns the page size for full scans: maxScanPageSize, or
+// the server's MaxObjectsInGet when that is smaller.
+func (c *Client) scanPageSize() uint64 {
+	if l := c.coreLimits(); l != nil && l.MaxObjectsInGet > 0 && l.MaxObjectsInGet < maxScanPageSize {
+		return l.MaxObjectsInGet
+	}
+	return maxScanPageSize
+}
+
+// Capabilities reports what the server advertises: every capability URI,
+// the core collection limits, and which optional fm features are usable,
+// along with the identified server and the quirks fm works around.
+func (c *Client) Capabilities() types.CapabilitiesResult {
+	result := types.CapabilitiesResult{
+		Server:       c.Server(),
+		Capabilities: []string{},
+		Features:     []types.FeatureSupport{},
+		SortOptions:  []string{},
+		HeaderFilter: c.headerFilter(),
+	}
+	if c.jmap == nil || c.jmap.Session == nil {
+		return result
+	}
+	if opts := c.sortOptions(); opts != nil {
+		result.SortOptions = opts
+	}
+
+	for uri := range c.jmap.Session.RawCapabilities {
+		result.Capabilities = append(result.Capabilities, string(uri))
+	}
+	sort.Strings(result.Capabilities)
+
+	if l := c.coreLimits(); l != nil {
+		result.Limits = types.ServerLimits{
+			MaxSizeUpload:         l.MaxSizeUpload,
+			MaxConcurrentUpload:   l.MaxConcurrentUpload,
+			MaxSizeRequest:        l.MaxSizeRequest,
+			MaxConcurrentRequests: l.MaxConcurrentRequests,
+			MaxCallsInRequest:     l.MaxCallsInRequest,
+			MaxObjectsInGet:       l.MaxObjectsInGet,
+			MaxObjectsInSet:       l.MaxObjectsInSet,
+		}
+	}
+
+	for _, f := range featureCapabilities {
+		result.Features = append(result.Features, types.FeatureSupport{
+			Feature:    f.name,
+			Capability: string(f.capability),
+			Available:  c.hasCapability(f.capability),
+			Commands:   f.commands,
+		})
+	}
+	return result
+}
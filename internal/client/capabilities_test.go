@@ -0,0 +1,99 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/core"
+	"git.sr.ht/~rockorager/go-jmap/mail"
+
+	"github.com/cboone/fm/internal/jmap/maskedemail"
+	"github.com/cboone/fm/internal/jmap/sieve"
+)
+
+// stalwartLikeClient advertises core and mail only, with small limits.
+func stalwartLikeClient() *Client {
+	return &Client{jmap: &jmap.Client{
+		Session: &jmap.Session{
+			Capabilities: map[jmap.URI]jmap.Capability{
+				jmap.CoreURI: &core.Core{MaxObjectsInGet: 200, MaxObjectsInSet: 20, MaxCallsInRequest: 16},
+			},
+			RawCapabilities: map[jmap.URI]json.RawMessage{
+				jmap.CoreURI: json.RawMessage("{}"),
+				mail.URI:     json.RawMessage("{}"),
+			},
+		},
+	}}
+}
+
+func TestScanPageSize(t *testing.T) {
+	if got := (&Client{}).scanPageSize(); got != maxScanPageSize {
+		t.Errorf("no session: scanPageSize() = %d, want %d", got, maxScanPageSize)
+	}
+	if got := stalwartLikeClient().scanPageSize(); got != 200 {
+		t.Errorf("small maxObjectsInGet: scanPageSize() = %d, want 200", got)
+	}
+
+	c := &Client{jmap: &jmap.Client{Session: &jmap.Session{
+		Capabilities: map[jmap.URI]jmap.Capability{
+			jmap.CoreURI: &core.Core{MaxObjectsInGet: 4096},
+		},
+	}}}
+	if got := c.scanPageSize(); got != maxScanPageSize {
+		t.Errorf("large maxObjectsInGet: scanPageSize() = %d, want %d", got, maxScanPageSize)
+	}
+}
+
+func TestMaxGetSize(t *testing.T) {
+	if got := (&Client{}).maxGetSize(); got != defaultBatchSize {
+		t.Errorf("no session: maxGetSize() = %d, want %d", got, defaultBatchSize)
+	}
+	if got := stalwartLikeClient().maxGetSize(); got != 200 {
+		t.Errorf("maxGetSize() = %d, want 200", got)
+	}
+}
+
+func TestRequireCapability_MatchesErrUnsupported(t *testing.T) {
+	c := stalwartLikeClient()
+	err := c.requireSieve()
+	if !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("requireSieve() = %v, want ErrUnsupported", err)
+	}
+	if want := "server does not support sieve scripts (missing " + string(sieve.URI) + " capability)"; err.Error() != want {
+		t.Errorf("error = %q, want %q", err.Error(), want)
+	}
+	if err := c.requireMaskedEmail(); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("requireMaskedEmail() = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	got := stalwartLikeClient().Capabilities()
+
+	if len(got.Capabilities) != 2 || got.Capabilities[0] != string(jmap.CoreURI) || got.Capabilities[1] != string(mail.URI) {
+		t.Errorf("Capabilities = %v, want sorted core and mail", got.Capabilities)
+	}
+	if got.Limits.MaxObjectsInGet != 200 || got.Limits.MaxObjectsInSet != 20 || got.Limits.MaxCallsInRequest != 16 {
+		t.Errorf("Limits = %+v", got.Limits)
+	}
+
+	available := map[string]bool{}
+	for _, f := range got.Features {
+		available[f.Capability] = f.Available
+	}
+	if !available[string(mail.URI)] {
+		t.Error("mail should be available")
+	}
+	if available[string(sieve.URI)] || available[string(maskedemail.URI)] {
+		t.Errorf("sieve and masked email should be unavailable, got %v", available)
+	}
+}
+
+func TestCapabilities_NoSession(t *testing.T) {
+	got := (&Client{}).Capabilities()
+	if got.Capabilities == nil || got.Features == nil {
+		t.Errorf("expected empty non-nil slices, got %+v", got)
+	}
+}
@@ -0,0 +1,162 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+// ErrCannotCalculateChanges means the server no longer has the changes
+// since a state, usually because the state is too old or came from another
+// account, so the caller has to start again from a full listing.
+var ErrCannotCalculateChanges = errors.New("server cannot calculate changes since this state")
+
+// EmailChanges is the result of Email/changes followed to the end.
+type EmailChanges struct {
+	Created   []string
+	Updated   []string
+	Destroyed []string
+	NewState  string
+}
+
+// EmailChangesSince returns the emails created, updated, and destroyed since
+// sinceState, following hasMoreChanges until the server is caught up.
+func (c *Client) EmailChangesSince(sinceState string) (EmailChanges, error) {
+	changes := EmailChanges{NewState: sinceState}
+	for {
+		req := &jmap.Request{}
+		req.Invoke(&email.Changes{
+			Account:    c.accountID,
+			SinceState: changes.NewState,
+		})
+
+		resp, err := c.Do(req)
+		if err != nil {
+			return EmailChanges{}, fmt.Errorf("email/changes: %w", err)
+		}
+
+		more := false
+		for _, inv := range resp.Responses {
+			switch r := inv.Args.(type) {
+			case *email.ChangesResponse:
+				changes.Created = append(changes.Created, idStrings(r.Created)...)
+				changes.Updated = append(changes.Updated, idStrings(r.Updated)...)
+				changes.Destroyed = append(changes.Destroyed, idStrings(r.Destroyed)...)
+				changes.NewState = r.NewState
+				more = r.HasMoreChanges
+			case *jmap.MethodError:
+				if r.Type == "cannotCalculateChanges" {
+					return EmailChanges{}, ErrCannotCalculateChanges
+				}
+				return EmailChanges{}, fmt.Errorf("email/changes: %s", r.Error())
+			}
+		}
+		if !more {
+			return changes, nil
+		}
+	}
+}
+
+// ListEmailChanges lists the emails in opts.MailboxNameOrID that were
+// created or updated since sinceState, newest first. It honors the
+// UnreadOnly, FlaggedOnly, UnflaggedOnly, ForwardedOnly, and ExcludeDrafts
+// options but not Limit: State covers every change, so the list has to as
+// well or the rest would be skipped next time. Total counts every changed
+// email in the mailbox; State is the state to pass next time, and Destroyed
+// lists the IDs of destroyed emails, wherever they were.
+func (c *Client) ListEmailChanges(opts ListOptions, sinceState string) (types.EmailListResult, error) {
+	mailboxID, err := c.ResolveMailboxID(opts.MailboxNameOrID)
+	if err != nil {
+		return types.EmailListResult{}, err
+	}
+
+	changes, err := c.EmailChangesSince(sinceState)
+	if err != nil {
+		return types.EmailListResult{}, err
+	}
+
+	// An email can be both created and updated within the window.
+	ids := dedup(append(changes.Created, changes.Updated...))
+	props := append(append([]string{}, summaryProperties...), "mailboxIds")
+
+	matched := []types.EmailSummary{}
+	size := c.maxGetSize()
+	for start := 0; start < len(ids); start += size {
+		batch := ids[start:min(start+size, len(ids))]
+		jmapIDs := make([]jmap.ID, len(batch))
+		for i, id := range batch {
+			jmapIDs[i] = jmap.ID(id)
+		}
+
+		req := &jmap.Request{}
+		req.Invoke(&email.Get{
+			Account:    c.accountID,
+			IDs:        jmapIDs,
+			Properties: props,
+		})
+
+		resp, err := c.Do(req)
+		if err != nil {
+			return types.EmailListResult{}, fmt.Errorf("email/get: %w", err)
+		}
+
+		for _, inv := range resp.Responses {
+			switch r := inv.Args.(type) {
+			case *email.GetResponse:
+				for _, e := range r.List {
+					if !e.MailboxIDs[mailboxID] || !matchesKeywordOptions(e.Keywords, opts) {
+						continue
+					}
+					matched = append(matched, convertSummaries([]*email.Email{e})...)
+				}
+			case *jmap.MethodError:
+				return types.EmailListResult{}, fmt.Errorf("email/get: %s", r.Error())
+			}
+		}
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].ReceivedAt.After(matched[j].ReceivedAt)
+	})
+
+	return types.EmailListResult{
+		Total:     uint64(len(matched)),
+		Emails:    matched,
+		State:     changes.NewState,
+		Destroyed: changes.Destroyed,
+	}, nil
+}
+
+// matchesKeywordOptions reports whether an email with keywords passes the
+// read, flag, forwarded, and draft filters of opts.
+func matchesKeywordOptions(keywords map[string]bool, opts ListOptions) bool {
+	if opts.UnreadOnly && keywords["$seen"] {
+		return false
+	}
+	if opts.FlaggedOnly && !keywords["$flagged"] {
+		return false
+	}
+	if opts.UnflaggedOnly && keywords["$flagged"] {
+		return false
+	}
+	if opts.ForwardedOnly && !keywords["$forwarded"] {
+		return false
+	}
+	if opts.ExcludeDrafts && keywords["$draft"] {
+		return false
+	}
+	return true
+}
+
+func idStrings(ids []jmap.ID) []string {
+	out := make([]string, len(ids))
+	for i, id := range ids {
+		out[i] = string(id)
+	}
+	return out
+}
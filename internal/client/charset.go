@@ -0,0 +1,98 @@
+package client
+
+import (
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// SetRawCharset turns off the charset repair ReadEmail and ParseEmail
+// apply to bodies declared in legacy charsets, leaving the body values as
+// the server decoded them.
+func (c *Client) SetRawCharset(raw bool) {
+	c.rawCharset = raw
+}
+
+// legacyEncoding returns the decoder for a body part's declared charset,
+// or nil when the charset is UTF-8, ASCII, missing, or unknown.
+func legacyEncoding(charset string) encoding.Encoding {
+	switch strings.ToLower(strings.TrimSpace(charset)) {
+	case "", "utf-8", "utf8", "us-ascii", "ascii":
+		return nil
+	}
+	enc, err := htmlindex.Get(charset)
+	if err != nil {
+		return nil
+	}
+	if name, _ := htmlindex.Name(enc); name == "utf-8" {
+		return nil
+	}
+	return enc
+}
+
+// repairCharsets replaces the body values of text parts declared in a
+// legacy charset (ISO-8859-*, Windows-125x, Shift_JIS, GBK, ...) with the
+// part's raw octets decoded from that charset. Servers that ignore the
+// declared charset otherwise hand back mojibake. Parts that cannot be
+// downloaded or decoded keep the server's value.
+func (c *Client) repairCharsets(e *email.Email) {
+	if c.rawCharset {
+		return
+	}
+	seen := make(map[string]bool)
+	for _, part := range append(append([]*email.BodyPart{}, e.TextBody...), e.HTMLBody...) {
+		if seen[part.PartID] || part.BlobID == "" {
+			continue
+		}
+		seen[part.PartID] = true
+		bv, ok := e.BodyValues[part.PartID]
+		if !ok {
+			continue
+		}
+		enc := legacyEncoding(part.Charset)
+		if enc == nil {
+			continue
+		}
+		text, err := c.decodePart(part, enc)
+		if err != nil {
+			continue
+		}
+		// The download is the whole part, so apply the body limit here.
+		truncated := c.maxBodyBytes > 0 && uint64(len(text)) > c.maxBodyBytes
+		if truncated {
+			text = truncateUTF8(text, int(c.maxBodyBytes))
+		}
+		bv.Value = text
+		bv.IsTruncated = truncated
+		bv.IsEncodingProblem = false
+	}
+}
+
+// decodePart downloads a body part and decodes it with enc.
+func (c *Client) decodePart(part *email.BodyPart, enc encoding.Encoding) (string, error) {
+	body, err := c.Download(c.accountID, part.BlobID)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+	data, err := io.ReadAll(enc.NewDecoder().Reader(body))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// truncateUTF8 cuts s to at most n bytes without splitting a character.
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
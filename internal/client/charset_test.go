@@ -0,0 +1,103 @@
+package client
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+)
+
+func TestLegacyEncoding(t *testing.T) {
+	for _, cs := range []string{"", "UTF-8", "utf8", "us-ascii", "x-unknown"} {
+		if enc := legacyEncoding(cs); enc != nil {
+			t.Errorf("legacyEncoding(%q) = %v, want nil", cs, enc)
+		}
+	}
+	for _, cs := range []string{"ISO-8859-1", "iso-8859-15", "windows-1252", "Shift_JIS", "GBK"} {
+		if enc := legacyEncoding(cs); enc == nil {
+			t.Errorf("legacyEncoding(%q) = nil, want a decoder", cs)
+		}
+	}
+}
+
+// charsetEmail returns an email whose text part is declared in charset and
+// whose server-decoded value is mojibake.
+func charsetEmail(charset string) *email.Email {
+	return &email.Email{
+		ID:         "M1",
+		TextBody:   []*email.BodyPart{{PartID: "1", BlobID: "B1", Type: "text/plain", Charset: charset}},
+		BodyValues: map[string]*email.BodyValue{"1": {Value: "caf�", IsEncodingProblem: true}},
+	}
+}
+
+func charsetClient(raw []byte, downloads *int) *Client {
+	return &Client{
+		accountID: "test-account",
+		downloadFunc: func(_ jmap.ID, blobID jmap.ID) (io.ReadCloser, error) {
+			*downloads++
+			return io.NopCloser(bytes.NewReader(raw)), nil
+		},
+	}
+}
+
+func TestRepairCharsets_DecodesLegacyCharsets(t *testing.T) {
+	tests := []struct {
+		charset string
+		raw     []byte
+		want    string
+	}{
+		{"ISO-8859-1", []byte("caf\xe9"), "café"},
+		{"windows-1252", []byte("\x93quoted\x94"), "“quoted”"},
+		{"Shift_JIS", []byte("\x82\xb1\x82\xf1\x82\xc9\x82\xbf\x82\xcd"), "こんにちは"},
+		{"GBK", []byte("\xc4\xe3\xba\xc3"), "你好"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.charset, func(t *testing.T) {
+			var downloads int
+			c := charsetClient(tt.raw, &downloads)
+			e := charsetEmail(tt.charset)
+			c.repairCharsets(e)
+			if got := e.BodyValues["1"].Value; got != tt.want {
+				t.Errorf("body = %q, want %q", got, tt.want)
+			}
+			if downloads != 1 {
+				t.Errorf("expected 1 download, got %d", downloads)
+			}
+		})
+	}
+}
+
+func TestRepairCharsets_SkipsUTF8(t *testing.T) {
+	var downloads int
+	c := charsetClient(nil, &downloads)
+	e := charsetEmail("utf-8")
+	c.repairCharsets(e)
+	if downloads != 0 {
+		t.Errorf("expected no download for a UTF-8 part, got %d", downloads)
+	}
+}
+
+func TestRepairCharsets_RawCharset(t *testing.T) {
+	var downloads int
+	c := charsetClient([]byte("caf\xe9"), &downloads)
+	c.SetRawCharset(true)
+	e := charsetEmail("ISO-8859-1")
+	c.repairCharsets(e)
+	if downloads != 0 || e.BodyValues["1"].Value != "caf�" {
+		t.Errorf("expected the server value to be kept, got %q after %d downloads", e.BodyValues["1"].Value, downloads)
+	}
+}
+
+func TestRepairCharsets_AppliesBodyLimit(t *testing.T) {
+	var downloads int
+	c := charsetClient([]byte("caf\xe9 au lait"), &downloads)
+	c.SetMaxBodyBytes(4)
+	e := charsetEmail("ISO-8859-1")
+	c.repairCharsets(e)
+	bv := e.BodyValues["1"]
+	if bv.Value != "caf" || !bv.IsTruncated {
+		t.Errorf("expected a truncated body cut before the é, got %q (truncated=%v)", bv.Value, bv.IsTruncated)
+	}
+}
@@ -0,0 +1,122 @@
+package client
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+
+	"github.com/cboone/fm/internal/mimeword"
+	"github.com/cboone/fm/internal/types"
+)
+
+// CleanOptions holds parameters for finding unread mailing-list senders.
+type CleanOptions struct {
+	MailboxID string
+	Since     time.Time
+	MinCount  int
+}
+
+// cleanProperties are the minimal Email/get properties for finding unread
+// list senders.
+var cleanProperties = []string{"id", "from", "keywords", "receivedAt"}
+
+// StaleListSenders returns senders of emails with a List-Unsubscribe header
+// received since opts.Since of which none has been read, sorted by volume
+// descending. Senders with fewer than opts.MinCount such emails are left
+// out. Only the sender fields of each suggestion are filled in.
+func (c *Client) StaleListSenders(opts CleanOptions) (types.CleanSuggestResult, error) {
+	since := opts.Since
+	fc := &email.FilterCondition{
+		InMailbox: jmap.ID(opts.MailboxID),
+		After:     &since,
+	}
+	props := cleanProperties
+	headerFilter := c.headerFilter()
+	if headerFilter {
+		fc.Header = []string{"List-Unsubscribe"}
+	} else {
+		// Check for List-Unsubscribe in the fetched headers instead.
+		props = append(slices.Clone(cleanProperties), "headers")
+	}
+
+	type senderAcc struct {
+		count    int
+		seen     bool
+		name     string
+		last     time.Time
+		latestID string
+	}
+	accum := make(map[string]*senderAcc)
+	scan, err := c.scanQuery(fc, []*email.SortComparator{{Property: "receivedAt", IsAscending: false}}, props, func(_ []jmap.ID, emails []*email.Email) {
+		for _, e := range emails {
+			if len(e.From) == 0 || e.From[0].Email == "" {
+				continue
+			}
+			if !headerFilter && !hasListUnsubscribe(e.Headers) {
+				continue
+			}
+			key := strings.ToLower(e.From[0].Email)
+			acc, ok := accum[key]
+			if !ok {
+				acc = &senderAcc{}
+				accum[key] = acc
+			}
+			acc.count++
+			if e.Keywords["$seen"] {
+				acc.seen = true
+			}
+			if e.From[0].Name != "" && acc.name == "" {
+				acc.name = mimeword.Decode(e.From[0].Name)
+			}
+			if received := safeTime(e.ReceivedAt); acc.latestID == "" || received.After(acc.last) {
+				acc.last = received
+				acc.latestID = string(e.ID)
+			}
+		}
+	})
+	if err != nil {
+		return types.CleanSuggestResult{}, fmt.Errorf("clean query: %w", err)
+	}
+
+	suggestions := []types.CleanSuggestion{}
+	for addr, acc := range accum {
+		if acc.seen || acc.count < opts.MinCount {
+			continue
+		}
+		suggestions = append(suggestions, types.CleanSuggestion{
+			Email:        addr,
+			Name:         acc.name,
+			Count:        acc.count,
+			LastReceived: acc.last,
+			LatestID:     acc.latestID,
+		})
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Count != suggestions[j].Count {
+			return suggestions[i].Count > suggestions[j].Count
+		}
+		return suggestions[i].Email < suggestions[j].Email
+	})
+
+	return types.CleanSuggestResult{
+		Since:       opts.Since,
+		Scanned:     scan.total,
+		Suggestions: suggestions,
+	}, nil
+}
+
+// hasListUnsubscribe reports whether headers include List-Unsubscribe.
+func hasListUnsubscribe(headers []*email.Header) bool {
+	for _, h := range headers {
+		if strings.EqualFold(h.Name, "List-Unsubscribe") {
+			return true
+		}
+	}
+	return false
+}
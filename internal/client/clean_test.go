@@ -0,0 +1,99 @@
+package client
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+)
+
+func TestStaleListSenders(t *testing.T) {
+	since := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	older := time.Date(2026, 8, 1, 9, 0, 0, 0, time.UTC)
+	newer := time.Date(2026, 9, 1, 9, 0, 0, 0, time.UTC)
+	from := func(addr string) []*mail.Address { return []*mail.Address{{Email: addr}} }
+
+	var query *email.Query
+	c := &Client{
+		accountID: "test-account",
+		doFunc: func(req *jmap.Request) (*jmap.Response, error) {
+			query = req.Calls[0].Args.(*email.Query)
+			return &jmap.Response{Responses: []*jmap.Invocation{
+				{Name: "Email/query", CallID: "0", Args: &email.QueryResponse{Total: 6, IDs: []jmap.ID{"M1", "M2", "M3", "M4", "M5", "M6"}}},
+				{Name: "Email/get", CallID: "1", Args: &email.GetResponse{List: []*email.Email{
+					{ID: "M1", From: []*mail.Address{{Email: "News@Example.com", Name: "Example News"}}, ReceivedAt: &newer},
+					{ID: "M2", From: from("news@example.com"), ReceivedAt: &older},
+					{ID: "M3", From: from("news@example.com"), ReceivedAt: &older},
+					{ID: "M4", From: from("digest@example.org"), ReceivedAt: &newer},
+					{ID: "M5", From: from("digest@example.org"), ReceivedAt: &older, Keywords: map[string]bool{"$seen": true}},
+					{ID: "M6", From: from("once@example.net"), ReceivedAt: &older},
+				}}},
+			}}, nil
+		},
+	}
+
+	result, err := c.StaleListSenders(CleanOptions{MailboxID: "mb-inbox", Since: since, MinCount: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fc, ok := query.Filter.(*email.FilterCondition)
+	if !ok || fc.InMailbox != "mb-inbox" || fc.After == nil || !fc.After.Equal(since) {
+		t.Fatalf("expected inMailbox and after filters, got %+v", query.Filter)
+	}
+	if len(fc.Header) != 1 || fc.Header[0] != "List-Unsubscribe" {
+		t.Errorf("expected a List-Unsubscribe header filter, got %v", fc.Header)
+	}
+
+	if result.Scanned != 6 || len(result.Suggestions) != 1 {
+		t.Fatalf("expected one stale sender out of 6 emails, got %+v", result)
+	}
+	s := result.Suggestions[0]
+	if s.Email != "news@example.com" || s.Name != "Example News" || s.Count != 3 {
+		t.Errorf("unexpected sender: %+v", s)
+	}
+	if s.LatestID != "M1" || !s.LastReceived.Equal(newer) {
+		t.Errorf("expected latest email M1 at %v, got %s at %v", newer, s.LatestID, s.LastReceived)
+	}
+}
+
+func TestStaleListSenders_NoHeaderFilter(t *testing.T) {
+	listHeaders := []*email.Header{{Name: "list-unsubscribe", Value: "<mailto:u@example.com>"}}
+	from := func(addr string) []*mail.Address { return []*mail.Address{{Email: addr}} }
+
+	var query *email.Query
+	var get *email.Get
+	c := &Client{
+		accountID: "test-account",
+		server:    ServerStalwart,
+		doFunc: func(req *jmap.Request) (*jmap.Response, error) {
+			query = req.Calls[0].Args.(*email.Query)
+			get = req.Calls[1].Args.(*email.Get)
+			return &jmap.Response{Responses: []*jmap.Invocation{
+				{Name: "Email/query", CallID: "0", Args: &email.QueryResponse{Total: 3, IDs: []jmap.ID{"M1", "M2", "M3"}}},
+				{Name: "Email/get", CallID: "1", Args: &email.GetResponse{List: []*email.Email{
+					{ID: "M1", From: from("news@example.com"), Headers: listHeaders},
+					{ID: "M2", From: from("news@example.com"), Headers: listHeaders},
+					{ID: "M3", From: from("friend@example.com")},
+				}}},
+			}}, nil
+		},
+	}
+
+	result, err := c.StaleListSenders(CleanOptions{MailboxID: "mb-inbox", MinCount: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fc := query.Filter.(*email.FilterCondition); len(fc.Header) != 0 {
+		t.Errorf("expected no header filter, got %v", fc.Header)
+	}
+	if !slices.Contains(get.Properties, "headers") {
+		t.Errorf("expected headers to be fetched, got %v", get.Properties)
+	}
+	if len(result.Suggestions) != 1 || result.Suggestions[0].Email != "news@example.com" {
+		t.Errorf("expected only the list sender, got %+v", result.Suggestions)
+	}
+}
@@ -1,15 +1,20 @@
 package client
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
 	"sort"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"git.sr.ht/~rockorager/go-jmap"
-	"git.sr.ht/~rockorager/go-jmap/core"
 	"git.sr.ht/~rockorager/go-jmap/mail"
 	"git.sr.ht/~rockorager/go-jmap/mail/identity"
 	"git.sr.ht/~rockorager/go-jmap/mail/mailbox"
@@ -20,6 +25,15 @@ import (
 // ErrNotFound indicates that a requested resource was not found.
 var ErrNotFound = fmt.Errorf("not found")
 
+// ErrNotSent is returned for requests that would change server state while
+// explain mode is on.
+var ErrNotSent = fmt.Errorf("request changes server state and was not sent (--explain)")
+
+// ErrSubmission is returned for any request that calls an EmailSubmission
+// method. fm saves drafts but never sends email, so such a request is
+// refused before it reaches the server.
+var ErrSubmission = fmt.Errorf("fm does not send email; EmailSubmission requests are refused")
+
 const maxRetries = 3
 const defaultBatchSize = 50
 
@@ -32,12 +46,26 @@ type Client struct {
 	doFunc        func(*jmap.Request) (*jmap.Response, error)
 	uploadFunc    func(jmap.ID, io.Reader) (*jmap.UploadResponse, error)
 	downloadFunc  func(jmap.ID, jmap.ID) (io.ReadCloser, error)
+	observer      func(time.Duration, error)
+	limiter       *rateLimitTransport
+	transport     *retryTransport
+	explain       io.Writer
+	server        string
+	progress      func(done, total int)
+	outcome       func(types.MessageOutcome)
+	maxBodyBytes  uint64
+	rawCharset    bool
 }
 
 // New creates a Client, authenticates, and discovers the session.
 func New(sessionURL, token, accountID string) (*Client, error) {
+	// The limiter sits below the retry loop so that retries count too. The
+	// token is added below both rather than with jmap.Client's
+	// WithAccessToken, which would replace the HTTP client and bypass them.
+	limiter := &rateLimitTransport{base: &bearerTransport{base: http.DefaultTransport, token: token}}
+	transport := &retryTransport{base: limiter}
 	httpClient := &http.Client{
-		Transport: &retryTransport{base: http.DefaultTransport},
+		Transport: transport,
 		Timeout:   30 * time.Second,
 	}
 
@@ -45,13 +73,19 @@ func New(sessionURL, token, accountID string) (*Client, error) {
 		SessionEndpoint: sessionURL,
 		HttpClient:      httpClient,
 	}
-	jc.WithAccessToken(token)
 
 	if err := jc.Authenticate(); err != nil {
 		return nil, fmt.Errorf("authentication failed: %w", err)
 	}
+	return newSessionClient(jc, sessionURL, accountID, transport, limiter)
+}
 
-	c := &Client{jmap: jc}
+// newSessionClient wraps an authenticated jmap.Client and selects the
+// account to use.
+func newSessionClient(jc *jmap.Client, sessionURL, accountID string, transport *retryTransport, limiter *rateLimitTransport) (*Client, error) {
+	normalizeSessionURLs(jc.Session, sessionURL)
+	c := &Client{jmap: jc, server: identifyServer(jc.Session, transport.serverHeader), limiter: limiter, transport: transport}
+	_ = c.SetRateLimit(0, 0)
 
 	if accountID != "" {
 		c.accountID = jmap.ID(accountID)
@@ -76,12 +110,169 @@ func (c *Client) Session() *jmap.Session {
 	return c.jmap.Session
 }
 
+// SetRequestObserver registers fn to be called after every JMAP request with
+// its latency and transport error, for metrics in long-running modes.
+func (c *Client) SetRequestObserver(fn func(time.Duration, error)) {
+	c.observer = fn
+}
+
+// RequestStats returns how many HTTP requests the client has made and how
+// many of them the server rate limited, failed with a server error, or had
+// to be retried.
+func (c *Client) RequestStats() types.RequestStats {
+	if c.transport == nil {
+		return types.RequestStats{}
+	}
+	return c.transport.stats()
+}
+
+// SetProgress registers fn to be called after each batch of a bulk update
+// with the number of emails handled so far and the total.
+func (c *Client) SetProgress(fn func(done, total int)) {
+	c.progress = fn
+}
+
+// SetOutcomeObserver registers fn to be called with the outcome of each
+// email in a bulk update.
+func (c *Client) SetOutcomeObserver(fn func(types.MessageOutcome)) {
+	c.outcome = fn
+}
+
+func (c *Client) reportOutcome(id, status, reason string) {
+	if c.outcome != nil {
+		c.outcome(types.MessageOutcome{ID: id, Status: status, Reason: reason})
+	}
+}
+
+func (c *Client) reportProgress(done, total int) {
+	if c.progress != nil {
+		c.progress(done, total)
+	}
+}
+
+// MaxBodyBytes returns the cap set by SetMaxBodyBytes, or 0 for none.
+func (c *Client) MaxBodyBytes() uint64 {
+	return c.maxBodyBytes
+}
+
+// SetMaxBodyBytes caps the size of each body value fetched by ReadEmail.
+// Zero, the default, fetches bodies in full.
+func (c *Client) SetMaxBodyBytes(n uint64) {
+	c.maxBodyBytes = n
+}
+
+// SetExplain turns on explain mode: every JMAP request is written to w as
+// JSON before it is sent, and requests with mutating methods (/set, /copy,
+// /import) are written but not sent, failing with ErrNotSent.
+func (c *Client) SetExplain(w io.Writer) {
+	c.explain = w
+}
+
+// refuseSubmission fails with ErrSubmission when req calls an
+// EmailSubmission method.
+func refuseSubmission(req *jmap.Request) error {
+	for _, call := range req.Calls {
+		if isSubmissionMethod(call.Name) {
+			return ErrSubmission
+		}
+	}
+	return nil
+}
+
+func isSubmissionMethod(name string) bool {
+	return strings.HasPrefix(name, "EmailSubmission/")
+}
+
+// explainRequest writes req in explain mode and reports whether it may be
+// sent.
+func (c *Client) explainRequest(req *jmap.Request) error {
+	if c.explain == nil {
+		return nil
+	}
+	mutates := false
+	for _, call := range req.Calls {
+		if strings.HasSuffix(call.Name, "/set") || strings.HasSuffix(call.Name, "/copy") ||
+			strings.HasSuffix(call.Name, "/import") {
+			mutates = true
+		}
+	}
+
+	body, err := json.MarshalIndent(req, "", "  ")
+	if err != nil {
+		return err
+	}
+	note := "sent"
+	if mutates {
+		note = "not sent: changes server state"
+	}
+	_, _ = fmt.Fprintf(c.explain, "# JMAP request (%s)\n%s\n", note, body)
+
+	if mutates {
+		return ErrNotSent
+	}
+	return nil
+}
+
 // Do executes a JMAP request.
 func (c *Client) Do(req *jmap.Request) (*jmap.Response, error) {
+	if err := refuseSubmission(req); err != nil {
+		return nil, err
+	}
+	if err := c.explainRequest(req); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	var resp *jmap.Response
+	var err error
 	if c.doFunc != nil {
-		return c.doFunc(req)
+		resp, err = c.doFunc(req)
+	} else {
+		resp, err = c.jmap.Do(req)
+	}
+	if c.observer != nil {
+		c.observer(time.Since(start), err)
+	}
+	return resp, err
+}
+
+// doRaw sends req and returns the undecoded methodResponses. It is used for
+// properties that go-jmap's typed responses do not model, since the method
+// registry decodes every Email/get response into email.GetResponse.
+func (c *Client) doRaw(req *jmap.Request) ([][3]json.RawMessage, error) {
+	if c.jmap == nil || c.jmap.Session == nil {
+		return nil, fmt.Errorf("no JMAP session")
+	}
+	if !slices.Contains(req.Using, jmap.CoreURI) {
+		req.Using = append(req.Using, jmap.CoreURI)
+	}
+	if err := refuseSubmission(req); err != nil {
+		return nil, err
+	}
+	if err := c.explainRequest(req); err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	httpResp, err := c.jmap.HttpClient.Post(c.jmap.Session.APIURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected HTTP status %s", httpResp.Status)
+	}
+
+	var resp struct {
+		MethodResponses [][3]json.RawMessage `json:"methodResponses"`
+	}
+	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
 	}
-	return c.jmap.Do(req)
+	return resp.MethodResponses, nil
 }
 
 // Upload sends binary data to the server and returns the blob metadata.
@@ -103,13 +294,8 @@ func (c *Client) Download(accountID jmap.ID, blobID jmap.ID) (io.ReadCloser, err
 // maxBatchSize returns the server's MaxObjectsInSet from the JMAP session
 // capabilities, falling back to defaultBatchSize when unavailable.
 func (c *Client) maxBatchSize() int {
-	if c == nil || c.jmap == nil || c.jmap.Session == nil {
-		return defaultBatchSize
-	}
-	if capability, ok := c.jmap.Session.Capabilities[jmap.CoreURI]; ok {
-		if coreCap, ok := capability.(*core.Core); ok && coreCap != nil && coreCap.MaxObjectsInSet > 0 {
-			return int(coreCap.MaxObjectsInSet)
-		}
+	if l := c.coreLimits(); l != nil && l.MaxObjectsInSet > 0 {
+		return int(l.MaxObjectsInSet)
 	}
 	return defaultBatchSize
 }
@@ -125,8 +311,8 @@ func (c *Client) SessionInfo() types.SessionInfo {
 		}
 	}
 
-	caps := make([]string, 0, len(s.Capabilities))
-	for uri := range s.Capabilities {
+	caps := make([]string, 0, len(s.RawCapabilities))
+	for uri := range s.RawCapabilities {
 		caps = append(caps, string(uri))
 	}
 	sort.Strings(caps)
@@ -138,9 +324,79 @@ func (c *Client) SessionInfo() types.SessionInfo {
 	}
 }
 
-// retryTransport wraps an http.RoundTripper to retry on 429 and 503.
+// AuthStatus describes what the session grants the token: the session's
+// capabilities and the active account with its own capabilities. Token
+// times are left for the caller, which holds the token.
+func (c *Client) AuthStatus() types.AuthStatus {
+	s := c.jmap.Session
+	status := types.AuthStatus{
+		Valid:               true,
+		SessionURL:          c.jmap.SessionEndpoint,
+		Username:            s.Username,
+		AccountID:           string(c.accountID),
+		Capabilities:        make([]string, 0, len(s.RawCapabilities)),
+		AccountCapabilities: []string{},
+	}
+	for uri := range s.RawCapabilities {
+		status.Capabilities = append(status.Capabilities, string(uri))
+	}
+	sort.Strings(status.Capabilities)
+	if acct, ok := s.Accounts[c.accountID]; ok {
+		status.AccountName = acct.Name
+		status.ReadOnly = acct.IsReadOnly
+		for uri := range acct.RawCapabilities {
+			status.AccountCapabilities = append(status.AccountCapabilities, string(uri))
+		}
+		sort.Strings(status.AccountCapabilities)
+	}
+	return status
+}
+
+// bearerTransport wraps an http.RoundTripper to authenticate every request
+// with a bearer token.
+type bearerTransport struct {
+	base  http.RoundTripper
+	token string
+}
+
+func (t *bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+t.token)
+	return t.base.RoundTrip(req)
+}
+
+// retryTransport wraps an http.RoundTripper to retry on 429 and 503. It
+// also keeps the Server header of the first response it returns (the
+// session response) for server identification, and counts requests,
+// retries, and error responses for RequestStats.
 type retryTransport struct {
-	base http.RoundTripper
+	base         http.RoundTripper
+	serverOnce   sync.Once
+	serverHeader string
+
+	requests     atomic.Int64
+	retries      atomic.Int64
+	rateLimited  atomic.Int64
+	serverErrors atomic.Int64
+}
+
+func (t *retryTransport) stats() types.RequestStats {
+	return types.RequestStats{
+		Requests:     int(t.requests.Load()),
+		Retries:      int(t.retries.Load()),
+		RateLimited:  int(t.rateLimited.Load()),
+		ServerErrors: int(t.serverErrors.Load()),
+	}
+}
+
+// count records the response to one attempt at a request.
+func (t *retryTransport) count(resp *http.Response) {
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		t.rateLimited.Add(1)
+	case resp.StatusCode >= 500:
+		t.serverErrors.Add(1)
+	}
 }
 
 func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
@@ -154,13 +410,17 @@ func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 			if err != nil {
 				return nil, err
 			}
+			t.retries.Add(1)
 		}
 
+		t.requests.Add(1)
 		resp, err = t.base.RoundTrip(attemptReq)
 		if err != nil {
 			return nil, err
 		}
+		t.count(resp)
 		if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+			t.serverOnce.Do(func() { t.serverHeader = resp.Header.Get("Server") })
 			return resp, nil
 		}
 		if attempt == maxRetries {
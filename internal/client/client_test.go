@@ -2,14 +2,20 @@ package client
 
 import (
 	"bytes"
+	"errors"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
 	"git.sr.ht/~rockorager/go-jmap"
 	"git.sr.ht/~rockorager/go-jmap/core"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+	"git.sr.ht/~rockorager/go-jmap/mail/emailsubmission"
+
+	"github.com/cboone/fm/internal/types"
 )
 
 type roundTripFunc func(*http.Request) (*http.Response, error)
@@ -121,6 +127,35 @@ func (r *oneShotReader) Read(p []byte) (int, error) {
 	return n, nil
 }
 
+func TestRetryTransport_CountsRetriesAndErrorResponses(t *testing.T) {
+	statuses := []int{http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusInternalServerError}
+	var calls int
+	rt := &retryTransport{base: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		status := statuses[calls]
+		calls++
+		return &http.Response{
+			StatusCode: status,
+			Header:     http.Header{"Retry-After": []string{"0"}},
+			Body:       io.NopCloser(strings.NewReader("")),
+		}, nil
+	})}
+
+	req, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("roundtrip failed: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	want := types.RequestStats{Requests: 3, Retries: 2, RateLimited: 1, ServerErrors: 2}
+	if got := rt.stats(); got != want {
+		t.Errorf("stats() = %+v, want %+v", got, want)
+	}
+}
+
 func TestRetryTransport_FailsForNonRewindableBody(t *testing.T) {
 	var calls int
 
@@ -216,3 +251,180 @@ func TestDefaultBatchSizeConstant(t *testing.T) {
 		t.Errorf("expected defaultBatchSize=50, got %d", defaultBatchSize)
 	}
 }
+
+func TestDo_NotifiesRequestObserver(t *testing.T) {
+	wantErr := errors.New("connection reset")
+	c := &Client{
+		doFunc: func(req *jmap.Request) (*jmap.Response, error) {
+			return nil, wantErr
+		},
+	}
+
+	var calls int
+	var gotErr error
+	c.SetRequestObserver(func(d time.Duration, err error) {
+		calls++
+		gotErr = err
+	})
+
+	_, _ = c.Do(&jmap.Request{})
+	if calls != 1 || !errors.Is(gotErr, wantErr) {
+		t.Errorf("expected one observation of %v, got %d calls with %v", wantErr, calls, gotErr)
+	}
+}
+
+func TestDo_ExplainWritesRequestsAndWithholdsMutations(t *testing.T) {
+	var sent []string
+	c := &Client{
+		accountID: "A1",
+		doFunc: func(req *jmap.Request) (*jmap.Response, error) {
+			sent = append(sent, req.Calls[0].Name)
+			return &jmap.Response{}, nil
+		},
+	}
+	var buf bytes.Buffer
+	c.SetExplain(&buf)
+
+	query := &jmap.Request{}
+	query.Invoke(&email.Query{Account: "A1", Filter: &email.FilterCondition{From: "alice@example.com"}})
+	if _, err := c.Do(query); err != nil {
+		t.Fatalf("expected the query to be sent, got %v", err)
+	}
+
+	set := &jmap.Request{}
+	set.Invoke(&email.Set{Account: "A1", Update: map[jmap.ID]jmap.Patch{"M1": {"keywords/$seen": true}}})
+	if _, err := c.Do(set); !errors.Is(err, ErrNotSent) {
+		t.Fatalf("expected ErrNotSent for Email/set, got %v", err)
+	}
+
+	if len(sent) != 1 || sent[0] != "Email/query" {
+		t.Errorf("expected only Email/query to be sent, got %v", sent)
+	}
+	out := buf.String()
+	for _, want := range []string{`"from": "alice@example.com"`, "# JMAP request (sent)", "# JMAP request (not sent: changes server state)", `"keywords/$seen": true`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected explain output to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestDo_RefusesEmailSubmission(t *testing.T) {
+	sent := false
+	c := &Client{
+		accountID: "A1",
+		doFunc: func(req *jmap.Request) (*jmap.Response, error) {
+			sent = true
+			return &jmap.Response{}, nil
+		},
+	}
+
+	req := &jmap.Request{}
+	req.Invoke(&email.Get{Account: "A1", IDs: []jmap.ID{"M1"}})
+	req.Invoke(&emailsubmission.Set{Account: "A1", Create: map[jmap.ID]*emailsubmission.EmailSubmission{
+		"send": {EmailID: "M1"},
+	}})
+	if _, err := c.Do(req); !errors.Is(err, ErrSubmission) {
+		t.Fatalf("expected ErrSubmission, got %v", err)
+	}
+	if sent {
+		t.Error("expected the request not to be sent")
+	}
+}
+
+// newTestServer serves a JMAP session, answering /api with api. The session
+// is served after the error statuses in sessionStatuses, with server as its
+// Server header. Every request must carry the bearer token "test-token".
+func newTestServer(t *testing.T, server string, sessionStatuses []int, api http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer test-token" {
+			t.Errorf("%s: expected the bearer token, got Authorization %q", r.URL.Path, got)
+		}
+		switch r.URL.Path {
+		case "/session":
+			if len(sessionStatuses) > 0 {
+				status := sessionStatuses[0]
+				sessionStatuses = sessionStatuses[1:]
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(status)
+				return
+			}
+			if server != "" {
+				w.Header().Set("Server", server)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = io.WriteString(w, `{
+				"capabilities": {"urn:ietf:params:jmap:core": {}, "urn:ietf:params:jmap:mail": {}},
+				"accounts": {"A1": {"name": "test@example.com", "isPersonal": true}},
+				"primaryAccounts": {"urn:ietf:params:jmap:mail": "A1"},
+				"username": "test@example.com",
+				"apiUrl": "`+srv.URL+`/api",
+				"state": "s1"
+			}`)
+		case "/api":
+			api(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestNew_SendsRequestsThroughRetryAndLimiter(t *testing.T) {
+	srv := newTestServer(t, "", []int{http.StatusTooManyRequests}, nil)
+
+	c, err := New(srv.URL+"/session", "test-token", "")
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if c.limiter == nil || c.transport == nil {
+		t.Fatal("expected the client to keep its limiter and retry transports")
+	}
+	if got := c.RequestStats(); got.Requests != 2 || got.Retries != 1 {
+		t.Errorf("expected the rate limited session request to be retried, got %+v", got)
+	}
+}
+
+func TestNew_CountsRateLimitsAndServerErrors(t *testing.T) {
+	statuses := []int{http.StatusTooManyRequests, http.StatusServiceUnavailable}
+	srv := newTestServer(t, "", nil, func(w http.ResponseWriter, r *http.Request) {
+		if len(statuses) > 0 {
+			status := statuses[0]
+			statuses = statuses[1:]
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"methodResponses": [], "sessionState": "s1"}`)
+	})
+
+	c, err := New(srv.URL+"/session", "test-token", "")
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	req := &jmap.Request{}
+	req.Invoke(&email.Query{Account: "A1"})
+	if _, err := c.Do(req); err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+
+	want := types.RequestStats{Requests: 4, Retries: 2, RateLimited: 1, ServerErrors: 1}
+	if got := c.RequestStats(); got != want {
+		t.Errorf("RequestStats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestNew_IdentifiesServerFromSessionResponse(t *testing.T) {
+	srv := newTestServer(t, "Stalwart/0.11", []int{http.StatusServiceUnavailable}, nil)
+
+	c, err := New(srv.URL+"/session", "test-token", "")
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if got := c.Server(); got != ServerStalwart {
+		t.Errorf("Server() = %q, want %q", got, ServerStalwart)
+	}
+}
@@ -0,0 +1,107 @@
+package client
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+// GroupByFields are the fields GroupEmails can group by.
+var GroupByFields = []string{"sender", "domain", "mailbox", "day"}
+
+// groupByProperties are the Email/get properties each grouping needs.
+var groupByProperties = map[string][]string{
+	"sender":  {"id", "from"},
+	"domain":  {"id", "from"},
+	"mailbox": {"id", "mailboxIds"},
+	"day":     {"id", "receivedAt"},
+}
+
+// GroupEmails counts the emails matching opts by groupBy, one of
+// GroupByFields, ignoring Limit, Offset, and sort. Every matching email is
+// fetched with only the properties the grouping needs and counted under
+// its sender address, sender domain, mailbox names, or local receive date.
+// Groups are ordered by count, most first, except days, which are in date
+// order. An email in several mailboxes counts under each; emails without a
+// sender count under "". Use CountEmails for the total alone.
+func (c *Client) GroupEmails(opts SearchOptions, groupBy string) (types.SearchCountResult, error) {
+	filter := buildSearchFilter(opts)
+	result := types.SearchCountResult{GroupBy: groupBy}
+	props, ok := groupByProperties[groupBy]
+	if !ok {
+		return types.SearchCountResult{}, fmt.Errorf("unknown group %q", groupBy)
+	}
+	var names map[jmap.ID]string
+	if groupBy == "mailbox" {
+		mailboxes, err := c.GetAllMailboxes()
+		if err != nil {
+			return types.SearchCountResult{}, err
+		}
+		names = make(map[jmap.ID]string, len(mailboxes))
+		for _, mb := range mailboxes {
+			names[mb.ID] = mb.Name
+		}
+	}
+
+	counts := make(map[string]int)
+	scan, err := c.scanQuery(filter, []*email.SortComparator{{Property: "receivedAt", IsAscending: false}}, props, func(_ []jmap.ID, emails []*email.Email) {
+		for _, e := range emails {
+			for _, key := range groupKeys(e, groupBy, names) {
+				counts[key]++
+			}
+		}
+	})
+	if err != nil {
+		return types.SearchCountResult{}, fmt.Errorf("count query: %w", err)
+	}
+	result.Total = scan.total
+
+	result.Groups = make([]types.GroupCount, 0, len(counts))
+	for key, n := range counts {
+		result.Groups = append(result.Groups, types.GroupCount{Key: key, Count: n})
+	}
+	sort.Slice(result.Groups, func(i, j int) bool {
+		a, b := result.Groups[i], result.Groups[j]
+		if groupBy != "day" && a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Key < b.Key
+	})
+	return result, nil
+}
+
+// groupKeys returns the groups e counts under for groupBy.
+func groupKeys(e *email.Email, groupBy string, names map[jmap.ID]string) []string {
+	switch groupBy {
+	case "sender", "domain":
+		var addr string
+		if len(e.From) > 0 {
+			addr = strings.ToLower(e.From[0].Email)
+		}
+		if groupBy == "domain" {
+			_, addr, _ = strings.Cut(addr, "@")
+		}
+		return []string{addr}
+	case "mailbox":
+		var keys []string
+		for id, in := range e.MailboxIDs {
+			if !in {
+				continue
+			}
+			name := names[id]
+			if name == "" {
+				name = string(id)
+			}
+			keys = append(keys, name)
+		}
+		return keys
+	case "day":
+		return []string{safeTime(e.ReceivedAt).Local().Format("2006-01-02")}
+	}
+	return nil
+}
@@ -0,0 +1,361 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"git.sr.ht/~rockorager/go-jmap"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+// daemonBase is the base URL of requests to a daemon. The socket, not the
+// host name, decides where they go.
+const daemonBase = "http://fm-daemon"
+
+// hotMethods are the methods whose responses a daemon caches: they read
+// state that every invocation needs and that rarely changes.
+var hotMethods = map[string]bool{"Mailbox/get": true, "Identity/get": true}
+
+// Daemon serves the session and proxies API, download, upload, and event
+// source requests to the server through an authenticated Client, so that
+// fm invocations on the same machine skip the credential command and
+// session discovery. Responses to requests that only get mailboxes or
+// identities are cached for a time; any request that changes server state
+// clears the cache.
+type Daemon struct {
+	c        *Client
+	socket   string
+	ttl      time.Duration
+	started  time.Time
+	shutdown func()
+
+	mu        sync.Mutex
+	session   []byte
+	state     string
+	cache     map[[sha256.Size]byte]cachedResponse
+	requests  int
+	cacheHits int
+}
+
+type cachedResponse struct {
+	body    []byte
+	expires time.Time
+}
+
+// NewDaemon returns a Daemon proxying through c that reports socket in its
+// status, caches hot responses for ttl, and calls shutdown when asked to
+// stop.
+func NewDaemon(c *Client, socket string, ttl time.Duration, shutdown func()) (*Daemon, error) {
+	d := &Daemon{
+		c:        c,
+		socket:   socket,
+		ttl:      ttl,
+		started:  time.Now(),
+		shutdown: shutdown,
+		cache:    make(map[[sha256.Size]byte]cachedResponse),
+	}
+	if err := d.refreshSession(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// refreshSession fetches the session and rewrites its URLs to point at the
+// daemon.
+func (d *Daemon) refreshSession() error {
+	resp, err := d.c.jmap.HttpClient.Get(d.c.jmap.SessionEndpoint)
+	if err != nil {
+		return fmt.Errorf("fetching session: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetching session: unexpected HTTP status %s", resp.Status)
+	}
+
+	var session map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&session); err != nil {
+		return fmt.Errorf("decoding session: %w", err)
+	}
+	session["apiUrl"] = "/api"
+	session["downloadUrl"] = "/download/{accountId}/{blobId}/{name}?type={type}"
+	session["uploadUrl"] = "/upload/{accountId}"
+	session["eventSourceUrl"] = "/eventsource?types={types}&closeafter={closeafter}&ping={ping}"
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	state, _ := session["state"].(string)
+
+	d.mu.Lock()
+	d.session, d.state = data, state
+	d.mu.Unlock()
+	return nil
+}
+
+// Status reports the daemon's socket, uptime, and counters.
+func (d *Daemon) Status() types.DaemonStatus {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return types.DaemonStatus{
+		Socket:          d.socket,
+		PID:             os.Getpid(),
+		Username:        d.c.jmap.Session.Username,
+		Server:          d.c.Server(),
+		StartedAt:       d.started,
+		CacheTTLSeconds: d.ttl.Seconds(),
+		Requests:        d.requests,
+		CacheHits:       d.cacheHits,
+		Upstream:        d.c.RequestStats(),
+	}
+}
+
+func (d *Daemon) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/session":
+		d.mu.Lock()
+		session := d.session
+		d.mu.Unlock()
+		// The server kind rides along for identifyServer.
+		w.Header().Set("Server", "fm-daemon ("+d.c.Server()+")")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(session)
+	case r.Method == http.MethodPost && r.URL.Path == "/api":
+		d.serveAPI(w, r)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/download/"):
+		parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/download/"), "/", 3)
+		if len(parts) != 3 {
+			http.NotFound(w, r)
+			return
+		}
+		d.forward(w, r, strings.NewReplacer(
+			"{accountId}", url.PathEscape(parts[0]),
+			"{blobId}", url.PathEscape(parts[1]),
+			"{name}", url.PathEscape(parts[2]),
+			"{type}", url.QueryEscape(r.URL.Query().Get("type")),
+		).Replace(d.c.jmap.Session.DownloadURL))
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/upload/"):
+		d.forward(w, r, strings.ReplaceAll(d.c.jmap.Session.UploadURL,
+			"{accountId}", url.PathEscape(strings.TrimPrefix(r.URL.Path, "/upload/"))))
+	case r.Method == http.MethodGet && r.URL.Path == "/eventsource":
+		q := r.URL.Query()
+		d.forward(w, r, strings.NewReplacer(
+			"{types}", url.QueryEscape(q.Get("types")),
+			"{closeafter}", url.QueryEscape(q.Get("closeafter")),
+			"{ping}", url.QueryEscape(q.Get("ping")),
+		).Replace(d.c.jmap.Session.EventSourceURL))
+	case r.Method == http.MethodGet && r.URL.Path == "/status":
+		writeDaemonJSON(w, d.Status())
+	case r.Method == http.MethodPost && r.URL.Path == "/stop":
+		writeDaemonJSON(w, d.Status())
+		if d.shutdown != nil {
+			go d.shutdown()
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// serveAPI answers a JMAP API request from the cache when it only gets hot
+// state, and forwards it otherwise.
+func (d *Daemon) serveAPI(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var req struct {
+		MethodCalls [][3]json.RawMessage `json:"methodCalls"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	hot, mutates := len(req.MethodCalls) > 0, false
+	for _, call := range req.MethodCalls {
+		var name string
+		_ = json.Unmarshal(call[0], &name)
+		if isSubmissionMethod(name) {
+			http.Error(w, ErrSubmission.Error(), http.StatusForbidden)
+			return
+		}
+		hot = hot && hotMethods[name]
+		if strings.HasSuffix(name, "/set") || strings.HasSuffix(name, "/copy") ||
+			strings.HasSuffix(name, "/import") {
+			mutates = true
+		}
+	}
+
+	key := sha256.Sum256(body)
+	d.mu.Lock()
+	d.requests++
+	if mutates {
+		clear(d.cache)
+	}
+	cached, ok := d.cache[key]
+	if ok && hot && time.Now().Before(cached.expires) {
+		d.cacheHits++
+		d.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(cached.body)
+		return
+	}
+	d.mu.Unlock()
+
+	resp, err := d.c.jmap.HttpClient.Post(d.c.jmap.Session.APIURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer func() { _ = resp.Body.Close() }()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	if resp.StatusCode == http.StatusOK {
+		var result struct {
+			SessionState string `json:"sessionState"`
+		}
+		_ = json.Unmarshal(respBody, &result)
+		d.mu.Lock()
+		stale := result.SessionState != "" && result.SessionState != d.state
+		if hot && d.ttl > 0 {
+			d.cache[key] = cachedResponse{body: respBody, expires: time.Now().Add(d.ttl)}
+		}
+		d.mu.Unlock()
+		if stale {
+			_ = d.refreshSession()
+		}
+	}
+
+	w.Header().Set("Content-Type", resp.Header.Get("Content-Type"))
+	w.WriteHeader(resp.StatusCode)
+	_, _ = w.Write(respBody)
+}
+
+// forward sends r to target on the server and streams the response back.
+func (d *Daemon) forward(w http.ResponseWriter, r *http.Request, target string) {
+	req, err := http.NewRequestWithContext(r.Context(), r.Method, target, r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	for _, h := range []string{"Content-Type", "Accept", "Last-Event-ID"} {
+		if v := r.Header.Get(h); v != "" {
+			req.Header.Set(h, v)
+		}
+	}
+
+	d.mu.Lock()
+	d.requests++
+	d.mu.Unlock()
+
+	resp, err := d.c.jmap.HttpClient.Do(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	for _, h := range []string{"Content-Type", "Content-Length", "Content-Disposition", "Cache-Control"} {
+		if v := resp.Header.Get(h); v != "" {
+			w.Header().Set(h, v)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+
+	// Flush as data arrives so event source pushes are not held back.
+	flusher, _ := w.(http.Flusher)
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := resp.Body.Read(buf)
+		if n > 0 {
+			if _, werr := w.Write(buf[:n]); werr != nil {
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func writeDaemonJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// daemonTransport sends every request to the daemon on socket.
+func daemonTransport(socket string) *http.Transport {
+	return &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", socket)
+		},
+	}
+}
+
+// NewViaDaemon creates a Client that reaches the server through the daemon
+// listening on socket, which holds the credentials and the session.
+func NewViaDaemon(socket, accountID string) (*Client, error) {
+	limiter := &rateLimitTransport{base: daemonTransport(socket)}
+	transport := &retryTransport{base: limiter}
+	jc := &jmap.Client{
+		SessionEndpoint: daemonBase + "/session",
+		HttpClient: &http.Client{
+			Transport: transport,
+			Timeout:   30 * time.Second,
+		},
+	}
+	if err := jc.Authenticate(); err != nil {
+		return nil, fmt.Errorf("connecting to daemon: %w", err)
+	}
+	return newSessionClient(jc, daemonBase+"/session", accountID, transport, limiter)
+}
+
+// DaemonStatus asks the daemon on socket for its status.
+func DaemonStatus(socket string) (types.DaemonStatus, error) {
+	return daemonCall(socket, http.MethodGet, "/status")
+}
+
+// StopDaemon asks the daemon on socket to shut down and returns its final
+// status.
+func StopDaemon(socket string) (types.DaemonStatus, error) {
+	return daemonCall(socket, http.MethodPost, "/stop")
+}
+
+func daemonCall(socket, method, path string) (types.DaemonStatus, error) {
+	req, err := http.NewRequest(method, daemonBase+path, nil)
+	if err != nil {
+		return types.DaemonStatus{}, err
+	}
+	resp, err := (&http.Client{Transport: daemonTransport(socket), Timeout: 5 * time.Second}).Do(req)
+	if err != nil {
+		return types.DaemonStatus{}, fmt.Errorf("connecting to daemon: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return types.DaemonStatus{}, fmt.Errorf("daemon: unexpected HTTP status %s", resp.Status)
+	}
+	var status types.DaemonStatus
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return types.DaemonStatus{}, fmt.Errorf("decoding daemon status: %w", err)
+	}
+	return status, nil
+}
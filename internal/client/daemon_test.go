@@ -0,0 +1,95 @@
+package client
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cboone/fm/internal/mockserver"
+)
+
+func TestDaemon_ProxiesAndCachesHotState(t *testing.T) {
+	mock := mockserver.New(
+		[]map[string]any{{"id": "mb-inbox", "name": "Inbox", "role": "inbox"}},
+		[]map[string]any{{"id": "M1", "threadId": "T1", "subject": "Hello", "receivedAt": "2026-02-14T10:00:00Z",
+			"mailboxIds": map[string]bool{"mb-inbox": true}, "keywords": map[string]bool{}}},
+	)
+	var apiCalls atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			apiCalls.Add(1)
+		}
+		mock.ServeHTTP(w, r)
+	}))
+	defer upstream.Close()
+
+	direct, err := New(upstream.URL+mockserver.SessionPath, "mock-token", "")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	socket := filepath.Join(t.TempDir(), "d.sock")
+	ln, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	d, err := NewDaemon(direct, socket, time.Minute, nil)
+	if err != nil {
+		t.Fatalf("NewDaemon: %v", err)
+	}
+	srv := &http.Server{Handler: d}
+	go func() { _ = srv.Serve(ln) }()
+	defer func() { _ = srv.Close() }()
+
+	// Each invocation gets a fresh client, as separate fm processes do.
+	viaDaemon := func() *Client {
+		t.Helper()
+		c, err := NewViaDaemon(socket, "")
+		if err != nil {
+			t.Fatalf("NewViaDaemon: %v", err)
+		}
+		if c.AccountID() != mockserver.AccountID {
+			t.Fatalf("expected account %s, got %s", mockserver.AccountID, c.AccountID())
+		}
+		return c
+	}
+
+	for range 2 {
+		if _, err := viaDaemon().GetAllMailboxes(); err != nil {
+			t.Fatalf("GetAllMailboxes: %v", err)
+		}
+	}
+	if got := apiCalls.Load(); got != 1 {
+		t.Errorf("expected the second mailbox fetch from the cache, got %d API calls", got)
+	}
+
+	c := viaDaemon()
+	if _, failed := c.MarkAsRead([]string{"M1"}); len(failed) > 0 {
+		t.Fatalf("MarkAsRead failed for %v", failed)
+	}
+	calls := apiCalls.Load()
+	if _, err := viaDaemon().GetAllMailboxes(); err != nil {
+		t.Fatalf("GetAllMailboxes: %v", err)
+	}
+	if got := apiCalls.Load(); got != calls+1 {
+		t.Errorf("expected a change to clear the cache, got %d API calls after %d", got, calls)
+	}
+
+	status, err := DaemonStatus(socket)
+	if err != nil {
+		t.Fatalf("DaemonStatus: %v", err)
+	}
+	if status.Username != "mock@example.com" || status.CacheHits != 1 || status.Requests < 4 {
+		t.Errorf("unexpected status %+v", status)
+	}
+}
+
+func TestNewViaDaemon_NoDaemon(t *testing.T) {
+	if _, err := NewViaDaemon(filepath.Join(t.TempDir(), "missing.sock"), ""); err == nil {
+		t.Fatal("expected an error without a daemon")
+	}
+}
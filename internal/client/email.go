@@ -2,6 +2,7 @@ package client
 
 import (
 	"fmt"
+	"io"
 	"sort"
 	"strings"
 	"time"
@@ -12,6 +13,8 @@ import (
 	"git.sr.ht/~rockorager/go-jmap/mail/searchsnippet"
 	"git.sr.ht/~rockorager/go-jmap/mail/thread"
 
+	"github.com/cboone/fm/internal/htmltext"
+	"github.com/cboone/fm/internal/mimeword"
 	"github.com/cboone/fm/internal/types"
 )
 
@@ -25,7 +28,8 @@ func (m *searchSnippetGet) Name() string { return "SearchSnippet/get" }
 
 func (m *searchSnippetGet) Requires() []jmap.URI { return []jmap.URI{mail.URI} }
 
-// batchSetEmails executes Email/set in server-aware batches.
+// batchSetEmails executes Email/set in server-aware batches, reporting
+// progress after each one.
 // patchFn builds the jmap.Patch for a single email ID.
 func (c *Client) batchSetEmails(emailIDs []string, patchFn func(string) jmap.Patch) (succeeded, errors []string) {
 	size := c.maxBatchSize()
@@ -54,7 +58,9 @@ func (c *Client) batchSetEmails(emailIDs []string, patchFn func(string) jmap.Pat
 		if err != nil {
 			for _, id := range batch {
 				errors = append(errors, fmt.Sprintf("%s: %v", id, err))
+				c.reportOutcome(id, "serverError", err.Error())
 			}
+			c.reportProgress(end, len(emailIDs))
 			continue
 		}
 
@@ -65,48 +71,71 @@ func (c *Client) batchSetEmails(emailIDs []string, patchFn func(string) jmap.Pat
 					jid := jmap.ID(idStr)
 					if _, ok := r.Updated[jid]; ok {
 						succeeded = append(succeeded, idStr)
+						c.reportOutcome(idStr, "ok", "")
 					} else if setErr, ok := r.NotUpdated[jid]; ok {
 						desc := "unknown error"
 						if setErr.Description != nil {
 							desc = *setErr.Description
 						}
 						errors = append(errors, fmt.Sprintf("%s: %s", idStr, desc))
+						status := setErr.Type
+						if status == "" {
+							status = "serverError"
+						}
+						c.reportOutcome(idStr, status, desc)
 					} else {
 						errors = append(errors, fmt.Sprintf("%s: no status returned by server", idStr))
+						c.reportOutcome(idStr, "serverError", "no status returned by server")
 					}
 				}
 			case *jmap.MethodError:
 				for _, id := range batch {
 					errors = append(errors, fmt.Sprintf("%s: %s", id, r.Error()))
+					c.reportOutcome(id, "serverError", r.Error())
 				}
 			}
 		}
+		c.reportProgress(end, len(emailIDs))
 	}
 	return succeeded, errors
 }
 
-// summaryProperties are the Email/get properties used for list and search results.
+// summaryProperties are the Email/get properties used for list and search
+// results. They must stay free of body properties: listings fetch hundreds of
+// emails at a time, and bodies make them several times slower.
 var summaryProperties = []string{
-	"id", "threadId", "mailboxIds", "from", "to", "cc",
+	"id", "threadId", "from", "to", "cc",
 	"subject", "receivedAt", "size", "keywords", "preview",
 }
 
 // detailProperties are the Email/get properties used for full email reads.
+// headers is always fetched since List-Unsubscribe is read from it.
 var detailProperties = []string{
-	"id", "threadId", "mailboxIds", "from", "to", "cc", "bcc",
-	"replyTo", "subject", "sentAt", "receivedAt", "size", "keywords",
+	"id", "threadId", "from", "to", "cc", "bcc",
+	"replyTo", "subject", "sentAt", "receivedAt", "keywords",
 	"bodyValues", "textBody", "htmlBody", "attachments", "headers",
 }
 
+// threadEmailProperties are the Email/get properties used for the other
+// emails of a thread. Their attachments are only counted, so body parts are
+// fetched with just their sizes.
+var threadEmailProperties = []string{
+	"id", "from", "to", "cc", "subject", "receivedAt", "preview", "keywords", "attachments",
+}
+
 // ListOptions holds parameters for listing emails in a mailbox.
 type ListOptions struct {
 	MailboxNameOrID string
 	Subject         string
+	After           *time.Time
 	Limit           uint64
 	Offset          int64
 	UnreadOnly      bool
 	FlaggedOnly     bool
 	UnflaggedOnly   bool
+	ForwardedOnly   bool
+	ExcludeDrafts   bool
+	ExcludeMuted    bool
 	SortField       string
 	SortAsc         bool
 }
@@ -119,6 +148,9 @@ func (c *Client) ListEmails(opts ListOptions) (types.EmailListResult, error) {
 	if opts.Limit == 0 {
 		opts.Limit = 25
 	}
+	if err := c.requireSort(opts.SortField); err != nil {
+		return types.EmailListResult{}, err
+	}
 
 	mailboxID, err := c.ResolveMailboxID(opts.MailboxNameOrID)
 	if err != nil {
@@ -131,6 +163,10 @@ func (c *Client) ListEmails(opts ListOptions) (types.EmailListResult, error) {
 	if opts.Subject != "" {
 		fc.Subject = opts.Subject
 	}
+	fc.After = opts.After
+	if opts.ExcludeMuted {
+		fc.NoneInThreadHaveKeyword = MutedKeyword
+	}
 	if opts.UnreadOnly {
 		fc.NotKeyword = "$seen"
 	}
@@ -153,42 +189,57 @@ func (c *Client) ListEmails(opts ListOptions) (types.EmailListResult, error) {
 			fc.NotKeyword = "$flagged"
 		}
 	}
+	filter = withKeywordConditions(filter, opts.ForwardedOnly, opts.ExcludeDrafts)
 
-	req := &jmap.Request{}
-	queryCallID := req.Invoke(&email.Query{
-		Account:        c.accountID,
-		Filter:         filter,
-		Sort:           []*email.SortComparator{{Property: opts.SortField, IsAscending: opts.SortAsc}},
-		Position:       opts.Offset,
-		Limit:          opts.Limit,
-		CalculateTotal: true,
-	})
+	result := types.EmailListResult{Offset: opts.Offset, Emails: []types.EmailSummary{}}
+	err = c.windows(opts.Offset, opts.Limit, func(page *queryPage, limit uint64) ([]jmap.ID, error) {
+		req := &jmap.Request{}
+		queryCallID := req.Invoke(&email.Query{
+			Account:        c.accountID,
+			Filter:         filter,
+			Sort:           []*email.SortComparator{{Property: opts.SortField, IsAscending: opts.SortAsc}},
+			Position:       page.position,
+			Anchor:         page.anchor,
+			AnchorOffset:   page.anchorOffset(),
+			Limit:          limit,
+			CalculateTotal: true,
+		})
 
-	req.Invoke(&email.Get{
-		Account:    c.accountID,
-		Properties: summaryProperties,
-		ReferenceIDs: &jmap.ResultReference{
-			ResultOf: queryCallID,
-			Name:     "Email/query",
-			Path:     "/ids",
-		},
-	})
+		req.Invoke(&email.Get{
+			Account:    c.accountID,
+			Properties: summaryProperties,
+			ReferenceIDs: &jmap.ResultReference{
+				ResultOf: queryCallID,
+				Name:     "Email/query",
+				Path:     "/ids",
+			},
+		})
 
-	resp, err := c.Do(req)
-	if err != nil {
-		return types.EmailListResult{}, fmt.Errorf("email query: %w", err)
-	}
+		resp, err := c.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("email query: %w", err)
+		}
 
-	result := types.EmailListResult{Offset: opts.Offset}
-	for _, inv := range resp.Responses {
-		switch r := inv.Args.(type) {
-		case *email.QueryResponse:
-			result.Total = r.Total
-		case *email.GetResponse:
-			result.Emails = convertSummaries(r.List)
-		case *jmap.MethodError:
-			return types.EmailListResult{}, fmt.Errorf("email query: %s", r.Error())
+		var ids []jmap.ID
+		for _, inv := range resp.Responses {
+			switch r := inv.Args.(type) {
+			case *email.QueryResponse:
+				result.Total = r.Total
+				ids = r.IDs
+			case *email.GetResponse:
+				c.fillMissingPreviews(r.List)
+				result.Emails = append(result.Emails, convertSummaries(r.List)...)
+			case *jmap.MethodError:
+				if page.anchorLost(r) {
+					return nil, errAnchorLost
+				}
+				return nil, fmt.Errorf("email query: %s", r.Error())
+			}
 		}
+		return ids, nil
+	})
+	if err != nil {
+		return types.EmailListResult{}, err
 	}
 
 	return result, nil
@@ -205,12 +256,13 @@ func (c *Client) ReadEmail(emailID string, preferHTML bool, rawHeaders bool) (ty
 		IDs:        []jmap.ID{jmap.ID(emailID)},
 		Properties: props,
 		BodyProperties: []string{
-			"partId", "blobId", "size", "name", "type", "charset", "disposition",
+			"partId", "blobId", "size", "name", "type", "charset", "disposition", "cid",
 		},
 	}
 	// Always fetch both so extractBody can fall back between HTML and text.
 	get.FetchHTMLBodyValues = true
 	get.FetchTextBodyValues = true
+	get.MaxBodyValueBytes = c.maxBodyBytes
 	req.Invoke(get)
 
 	resp, err := c.Do(req)
@@ -227,6 +279,7 @@ func (c *Client) ReadEmail(emailID string, preferHTML bool, rawHeaders bool) (ty
 			if len(r.List) == 0 {
 				return types.EmailDetail{}, fmt.Errorf("email %s: %w", emailID, ErrNotFound)
 			}
+			c.repairCharsets(r.List[0])
 			return convertDetail(r.List[0], preferHTML, rawHeaders), nil
 		case *jmap.MethodError:
 			return types.EmailDetail{}, fmt.Errorf("email/get: %s", r.Error())
@@ -244,10 +297,7 @@ func (c *Client) ReadThread(emailID string, preferHTML bool, rawHeaders bool) (t
 	}
 
 	if detail.ThreadID == "" {
-		return types.ThreadView{
-			Email:  detail,
-			Thread: []types.ThreadEmail{singleThreadEntry(detail)},
-		}, nil
+		return newThreadView(detail, []types.ThreadEmail{singleThreadEntry(detail)}), nil
 	}
 
 	req := &jmap.Request{}
@@ -267,10 +317,7 @@ func (c *Client) ReadThread(emailID string, preferHTML bool, rawHeaders bool) (t
 		switch r := inv.Args.(type) {
 		case *thread.GetResponse:
 			if len(r.NotFound) > 0 || len(r.List) == 0 {
-				return types.ThreadView{
-					Email:  detail,
-					Thread: []types.ThreadEmail{singleThreadEntry(detail)},
-				}, nil
+				return newThreadView(detail, []types.ThreadEmail{singleThreadEntry(detail)}), nil
 			}
 			threadEmailIDs = r.List[0].EmailIDs
 		case *jmap.MethodError:
@@ -279,17 +326,15 @@ func (c *Client) ReadThread(emailID string, preferHTML bool, rawHeaders bool) (t
 	}
 
 	if len(threadEmailIDs) == 0 {
-		return types.ThreadView{
-			Email:  detail,
-			Thread: []types.ThreadEmail{singleThreadEntry(detail)},
-		}, nil
+		return newThreadView(detail, []types.ThreadEmail{singleThreadEntry(detail)}), nil
 	}
 
 	req = &jmap.Request{}
 	req.Invoke(&email.Get{
-		Account:    c.accountID,
-		IDs:        threadEmailIDs,
-		Properties: []string{"id", "threadId", "from", "to", "subject", "receivedAt", "preview", "keywords"},
+		Account:        c.accountID,
+		IDs:            threadEmailIDs,
+		Properties:     threadEmailProperties,
+		BodyProperties: []string{"partId", "size", "type", "cid", "disposition"},
 	})
 
 	resp, err = c.Do(req)
@@ -301,15 +346,18 @@ func (c *Client) ReadThread(emailID string, preferHTML bool, rawHeaders bool) (t
 	for _, inv := range resp.Responses {
 		switch r := inv.Args.(type) {
 		case *email.GetResponse:
+			c.fillMissingPreviews(r.List)
 			for _, e := range r.List {
 				threadEmails = append(threadEmails, types.ThreadEmail{
-					ID:         string(e.ID),
-					From:       convertAddresses(e.From),
-					To:         convertAddresses(e.To),
-					Subject:    e.Subject,
-					ReceivedAt: safeTime(e.ReceivedAt),
-					Preview:    e.Preview,
-					IsUnread:   !e.Keywords["$seen"],
+					ID:              string(e.ID),
+					From:            convertAddresses(e.From),
+					To:              convertAddresses(e.To),
+					CC:              convertAddresses(e.CC),
+					Subject:         mimeword.Decode(e.Subject),
+					ReceivedAt:      safeTime(e.ReceivedAt),
+					Preview:         e.Preview,
+					IsUnread:        !e.Keywords["$seen"],
+					AttachmentBytes: attachmentBytes(e.Attachments),
 				})
 			}
 		case *jmap.MethodError:
@@ -325,27 +373,88 @@ func (c *Client) ReadThread(emailID string, preferHTML bool, rawHeaders bool) (t
 		return threadEmails[i].ReceivedAt.Before(threadEmails[j].ReceivedAt)
 	})
 
-	return types.ThreadView{
-		Email:  detail,
-		Thread: threadEmails,
-	}, nil
+	return newThreadView(detail, threadEmails), nil
 }
 
 func singleThreadEntry(d types.EmailDetail) types.ThreadEmail {
+	var attachmentBytes uint64
+	for _, a := range d.Attachments {
+		if !a.Inline {
+			attachmentBytes += a.Size
+		}
+	}
 	return types.ThreadEmail{
-		ID:         d.ID,
-		From:       d.From,
-		To:         d.To,
-		Subject:    d.Subject,
-		ReceivedAt: d.ReceivedAt,
-		IsUnread:   d.IsUnread,
+		ID:              d.ID,
+		From:            d.From,
+		To:              d.To,
+		CC:              d.CC,
+		Subject:         d.Subject,
+		ReceivedAt:      d.ReceivedAt,
+		IsUnread:        d.IsUnread,
+		AttachmentBytes: attachmentBytes,
+	}
+}
+
+func newThreadView(detail types.EmailDetail, thread []types.ThreadEmail) types.ThreadView {
+	return types.ThreadView{
+		Email:  detail,
+		Stats:  summarizeThread(thread),
+		Thread: thread,
+	}
+}
+
+// summarizeThread computes participation stats for thread, which must be
+// sorted oldest first.
+func summarizeThread(thread []types.ThreadEmail) types.ThreadStats {
+	stats := types.ThreadStats{
+		Messages:     len(thread),
+		Participants: []types.ThreadParticipant{},
+	}
+	index := make(map[string]int)
+	add := func(a types.Address, sent bool) {
+		key := strings.ToLower(a.Email)
+		i, ok := index[key]
+		if !ok {
+			i = len(stats.Participants)
+			index[key] = i
+			stats.Participants = append(stats.Participants, types.ThreadParticipant{Name: a.Name, Email: a.Email})
+		}
+		if stats.Participants[i].Name == "" {
+			stats.Participants[i].Name = a.Name
+		}
+		if sent {
+			stats.Participants[i].Sent++
+		}
 	}
+
+	for i, e := range thread {
+		if i == 0 {
+			stats.FirstAt = e.ReceivedAt
+		}
+		stats.LastAt = e.ReceivedAt
+		if e.IsUnread {
+			stats.Unread++
+		}
+		stats.AttachmentBytes += e.AttachmentBytes
+		for _, a := range e.From {
+			add(a, true)
+		}
+		for _, a := range e.To {
+			add(a, false)
+		}
+		for _, a := range e.CC {
+			add(a, false)
+		}
+	}
+	return stats
 }
 
 // buildSearchFilter constructs an email.Filter from SearchOptions.
 func buildSearchFilter(opts SearchOptions) email.Filter {
 	fc := &email.FilterCondition{}
-	if opts.Text != "" {
+	if opts.Text != "" && opts.TextHeader != "" {
+		fc.Header = []string{opts.TextHeader, opts.Text}
+	} else if opts.Text != "" {
 		fc.Text = opts.Text
 	}
 	if opts.From != "" {
@@ -371,10 +480,17 @@ func buildSearchFilter(opts SearchOptions) email.Filter {
 	}
 	if opts.MailboxID != "" {
 		fc.InMailbox = jmap.ID(opts.MailboxID)
+	} else {
+		for _, id := range opts.ExcludeMailboxIDs {
+			fc.InMailboxOtherThan = append(fc.InMailboxOtherThan, jmap.ID(id))
+		}
 	}
 	if opts.FlaggedOnly {
 		fc.HasKeyword = "$flagged"
 	}
+	if opts.ExcludeMuted {
+		fc.NoneInThreadHaveKeyword = MutedKeyword
+	}
 
 	// When both UnflaggedOnly and UnreadOnly are set, they each need a
 	// separate NotKeyword field, so we must use a compound FilterOperator
@@ -391,108 +507,178 @@ func buildSearchFilter(opts SearchOptions) email.Filter {
 		}
 	}
 
-	return filter
+	// ReadOnly needs a HasKeyword of its own when FlaggedOnly has taken it.
+	if opts.ReadOnly {
+		if fc.HasKeyword == "" {
+			fc.HasKeyword = "$seen"
+		} else {
+			filter = &email.FilterOperator{
+				Operator:   jmap.OperatorAND,
+				Conditions: []email.Filter{filter, &email.FilterCondition{HasKeyword: "$seen"}},
+			}
+		}
+	}
+
+	if opts.Keyword != "" {
+		filter = &email.FilterOperator{
+			Operator:   jmap.OperatorAND,
+			Conditions: []email.Filter{filter, &email.FilterCondition{HasKeyword: opts.Keyword}},
+		}
+	}
+
+	return withKeywordConditions(filter, opts.ForwardedOnly, opts.ExcludeDrafts)
+}
+
+// withKeywordConditions narrows filter to forwarded emails, to emails that
+// are not drafts, or both. The keyword fields of the first condition are
+// usually taken by the read and flag filters, so each gets a condition of
+// its own, joined with AND.
+func withKeywordConditions(filter email.Filter, forwardedOnly, excludeDrafts bool) email.Filter {
+	conditions := []email.Filter{filter}
+	if forwardedOnly {
+		conditions = append(conditions, &email.FilterCondition{HasKeyword: "$forwarded"})
+	}
+	if excludeDrafts {
+		conditions = append(conditions, &email.FilterCondition{NotKeyword: "$draft"})
+	}
+	if len(conditions) == 1 {
+		return filter
+	}
+	return &email.FilterOperator{Operator: jmap.OperatorAND, Conditions: conditions}
 }
 
 // SearchEmails performs a filtered search across emails.
 func (c *Client) SearchEmails(opts SearchOptions) (types.EmailListResult, error) {
+	if opts.TextHeader != "" && !c.headerFilter() {
+		return types.EmailListResult{}, &unsupportedError{feature: "searching within a header"}
+	}
 	filter := buildSearchFilter(opts)
 
 	sortField := opts.SortField
 	if sortField == "" {
 		sortField = "receivedAt"
 	}
+	if err := c.requireSort(sortField); err != nil {
+		return types.EmailListResult{}, err
+	}
 
-	// Track call IDs so we can identify which method failed in errors.
-	callMethods := make(map[string]string) // callID -> method name
+	hasTextSearch := opts.Text != ""
+	result := types.EmailListResult{Offset: opts.Offset, Emails: []types.EmailSummary{}}
+	snippets := make(map[string]string)
+	subjectSnippets := make(map[string]string)
 
-	req := &jmap.Request{}
-	queryCallID := req.Invoke(&email.Query{
-		Account:        c.accountID,
-		Filter:         filter,
-		Sort:           []*email.SortComparator{{Property: sortField, IsAscending: opts.SortAsc}},
-		Position:       opts.Offset,
-		Limit:          opts.Limit,
-		CalculateTotal: true,
-	})
-	callMethods[queryCallID] = "Email/query"
+	err := c.windows(opts.Offset, opts.Limit, func(page *queryPage, limit uint64) ([]jmap.ID, error) {
+		// Track call IDs so we can identify which method failed in errors.
+		callMethods := make(map[string]string) // callID -> method name
 
-	getCallID := req.Invoke(&email.Get{
-		Account:    c.accountID,
-		Properties: summaryProperties,
-		ReferenceIDs: &jmap.ResultReference{
-			ResultOf: queryCallID,
-			Name:     "Email/query",
-			Path:     "/ids",
-		},
-	})
-	callMethods[getCallID] = "Email/get"
+		req := &jmap.Request{}
+		queryCallID := req.Invoke(&email.Query{
+			Account:        c.accountID,
+			Filter:         filter,
+			Sort:           []*email.SortComparator{{Property: sortField, IsAscending: opts.SortAsc}},
+			Position:       page.position,
+			Anchor:         page.anchor,
+			AnchorOffset:   page.anchorOffset(),
+			Limit:          limit,
+			CalculateTotal: true,
+		})
+		callMethods[queryCallID] = "Email/query"
 
-	// Request search snippets if doing a text search.
-	hasTextSearch := opts.Text != ""
-	var snippetCallID string
-	if hasTextSearch {
-		snippetCallID = req.Invoke(&searchSnippetGet{searchsnippet.Get{
-			Account: c.accountID,
-			Filter:  filter,
+		getCallID := req.Invoke(&email.Get{
+			Account:    c.accountID,
+			Properties: summaryProperties,
 			ReferenceIDs: &jmap.ResultReference{
 				ResultOf: queryCallID,
 				Name:     "Email/query",
 				Path:     "/ids",
 			},
-		}})
-		callMethods[snippetCallID] = "SearchSnippet/get"
-	}
-
-	resp, err := c.Do(req)
-	if err != nil {
-		return types.EmailListResult{}, fmt.Errorf("search: %w", err)
-	}
+		})
+		callMethods[getCallID] = "Email/get"
+
+		// Request search snippets if doing a text search.
+		var snippetCallID string
+		if hasTextSearch {
+			snippetCallID = req.Invoke(&searchSnippetGet{searchsnippet.Get{
+				Account: c.accountID,
+				Filter:  filter,
+				ReferenceIDs: &jmap.ResultReference{
+					ResultOf: queryCallID,
+					Name:     "Email/query",
+					Path:     "/ids",
+				},
+			}})
+			callMethods[snippetCallID] = "SearchSnippet/get"
+		}
 
-	result := types.EmailListResult{Offset: opts.Offset}
-	snippets := make(map[string]string)
+		resp, err := c.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("search: %w", err)
+		}
 
-	for i, inv := range resp.Responses {
-		switch r := inv.Args.(type) {
-		case *email.QueryResponse:
-			result.Total = r.Total
-		case *email.GetResponse:
-			result.Emails = convertSummaries(r.List)
-		case *searchsnippet.GetResponse:
-			for _, s := range r.List {
-				if s.Preview != "" {
-					snippets[string(s.Email)] = s.Preview
+		var ids []jmap.ID
+		for i, inv := range resp.Responses {
+			switch r := inv.Args.(type) {
+			case *email.QueryResponse:
+				result.Total = r.Total
+				ids = r.IDs
+			case *email.GetResponse:
+				c.fillMissingPreviews(r.List)
+				result.Emails = append(result.Emails, convertSummaries(r.List)...)
+			case *searchsnippet.GetResponse:
+				for _, s := range r.List {
+					if s.Preview != "" {
+						snippets[string(s.Email)] = s.Preview
+					}
+					if s.Subject != "" {
+						subjectSnippets[string(s.Email)] = s.Subject
+					}
+				}
+			case *jmap.MethodError:
+				if page.anchorLost(r) {
+					return nil, errAnchorLost
+				}
+				method := callMethods[inv.CallID]
+				if method == "" {
+					method = inv.Name
+				}
+				// If snippets fail but query+get succeeded, degrade gracefully.
+				if hasTextSearch && (method == "SearchSnippet/get" || inv.CallID == snippetCallID) {
+					continue
+				}
+				if method == "" || method == "error" {
+					method = "unknown"
 				}
-			}
-		case *jmap.MethodError:
-			method := callMethods[inv.CallID]
-			if method == "" {
-				method = inv.Name
-			}
-			// If snippets fail but query+get succeeded, degrade gracefully.
-			if hasTextSearch && (method == "SearchSnippet/get" || inv.CallID == snippetCallID) {
-				continue
-			}
-			if method == "" || method == "error" {
-				method = "unknown"
-			}
 
-			callRef := inv.CallID
-			if callRef == "" {
-				callRef = fmt.Sprintf("%d", i)
-			}
+				callRef := inv.CallID
+				if callRef == "" {
+					callRef = fmt.Sprintf("%d", i)
+				}
 
-			if method == "unknown" {
-				return types.EmailListResult{}, fmt.Errorf("search: call %s returned %s", callRef, r.Error())
+				if method == "unknown" {
+					return nil, fmt.Errorf("search: call %s returned %s", callRef, r.Error())
+				}
+				return nil, fmt.Errorf("search: %s (call %s) returned %s", method, callRef, r.Error())
 			}
-			return types.EmailListResult{}, fmt.Errorf("search: %s (call %s) returned %s", method, callRef, r.Error())
 		}
+		return ids, nil
+	})
+	if err != nil {
+		return types.EmailListResult{}, err
 	}
 
 	// Attach snippets to emails.
+	var terms []string
+	if opts.TextHeader == "" {
+		terms = strings.Fields(opts.Text)
+	}
+	terms = append(terms, strings.Fields(opts.Subject)...)
 	for i := range result.Emails {
-		if s, ok := snippets[result.Emails[i].ID]; ok {
-			result.Emails[i].Snippet = s
+		e := &result.Emails[i]
+		if s, ok := snippets[e.ID]; ok {
+			e.Snippet = s
+		}
+		if opts.Highlights {
+			e.Highlights = searchHighlights(e.Subject, subjectSnippets[e.ID], snippets[e.ID], terms)
 		}
 	}
 
@@ -501,7 +687,10 @@ func (c *Client) SearchEmails(opts SearchOptions) (types.EmailListResult, error)
 
 // SearchOptions holds search filter parameters.
 type SearchOptions struct {
-	Text          string
+	Text string
+	// TextHeader, when set, scopes Text to the named header instead of the
+	// subject, addresses, and body.
+	TextHeader    string
 	MailboxID     string
 	From          string
 	To            string
@@ -510,12 +699,24 @@ type SearchOptions struct {
 	After         *time.Time
 	HasAttachment bool
 	UnreadOnly    bool
+	ReadOnly      bool
 	FlaggedOnly   bool
 	UnflaggedOnly bool
-	Limit         uint64
-	Offset        int64
-	SortField     string
-	SortAsc       bool
+	ForwardedOnly bool
+	ExcludeDrafts bool
+	ExcludeMuted  bool
+	// Keyword, when set, matches only emails that have it.
+	Keyword string
+	// Highlights adds the offsets of matches in each result's subject and
+	// snippet to the results.
+	Highlights bool
+	// ExcludeMailboxIDs leaves out emails in any of these mailboxes. It is
+	// ignored when MailboxID is set.
+	ExcludeMailboxIDs []string
+	Limit             uint64
+	Offset            int64
+	SortField         string
+	SortAsc           bool
 }
 
 const defaultQueryPageSize = 250
@@ -524,46 +725,24 @@ const defaultQueryPageSize = 250
 // through the full result set, returning all matching email IDs.
 // It ignores Limit, Offset, SortField, and SortAsc from opts.
 func (c *Client) QueryEmailIDs(opts SearchOptions) ([]string, error) {
-	filter := buildSearchFilter(opts)
+	ids, _, err := c.queryAllIDs(buildSearchFilter(opts))
+	return ids, err
+}
 
+// queryAllIDs pages through every ID matching filter. It also returns the
+// query state of the first page when the server can calculate changes from
+// it, or "" otherwise.
+func (c *Client) queryAllIDs(filter email.Filter) ([]string, string, error) {
 	var collected []string
-	for {
-		req := &jmap.Request{}
-		req.Invoke(&email.Query{
-			Account:        c.accountID,
-			Filter:         filter,
-			Position:       int64(len(collected)),
-			Limit:          defaultQueryPageSize,
-			CalculateTotal: true,
-		})
-
-		resp, err := c.Do(req)
-		if err != nil {
-			return nil, fmt.Errorf("email/query: %w", err)
-		}
-
-		var pageIDs []jmap.ID
-		var total uint64
-		for _, inv := range resp.Responses {
-			switch r := inv.Args.(type) {
-			case *email.QueryResponse:
-				pageIDs = r.IDs
-				total = r.Total
-			case *jmap.MethodError:
-				return nil, fmt.Errorf("email/query: %s", r.Error())
-			}
-		}
-
-		for _, id := range pageIDs {
+	scan, err := c.scanQuery(filter, nil, nil, func(ids []jmap.ID, _ []*email.Email) {
+		for _, id := range ids {
 			collected = append(collected, string(id))
 		}
-
-		if uint64(len(collected)) >= total || len(pageIDs) == 0 {
-			break
-		}
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("email/query: %w", err)
 	}
-
-	return collected, nil
+	return collected, scan.queryState, nil
 }
 
 // QueryFirstEmailID runs Email/query with Limit 1 and returns the most recent
@@ -600,6 +779,35 @@ func (c *Client) QueryFirstEmailID(opts SearchOptions) (string, error) {
 	return "", fmt.Errorf("email/query: unexpected or empty response")
 }
 
+// CountEmails returns the number of emails matching opts. It asks for a
+// single ID (a zero limit would be omitted and mean the server default) and
+// ignores Limit, Offset, SortField, and SortAsc from opts.
+func (c *Client) CountEmails(opts SearchOptions) (uint64, error) {
+	req := &jmap.Request{}
+	req.Invoke(&email.Query{
+		Account:        c.accountID,
+		Filter:         buildSearchFilter(opts),
+		Limit:          1,
+		CalculateTotal: true,
+	})
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("email/query: %w", err)
+	}
+
+	for _, inv := range resp.Responses {
+		switch r := inv.Args.(type) {
+		case *email.QueryResponse:
+			return r.Total, nil
+		case *jmap.MethodError:
+			return 0, fmt.Errorf("email/query: %s", r.Error())
+		}
+	}
+
+	return 0, fmt.Errorf("email/query: unexpected or empty response")
+}
+
 // StatsOptions holds parameters for sender aggregation.
 type StatsOptions struct {
 	MailboxID     string
@@ -609,8 +817,66 @@ type StatsOptions struct {
 	Subjects      bool
 }
 
-// statsProperties are the minimal Email/get properties for aggregation.
-var statsProperties = []string{"id", "from", "subject"}
+// statsProperties are the minimal Email/get properties for sender aggregation.
+var statsProperties = []string{"id", "from", "subject", "keywords", "receivedAt"}
+
+// senderTally is what a sender aggregation learns about one sender.
+type senderTally struct {
+	email    string
+	name     string
+	count    int
+	unread   int
+	lastSeen time.Time
+	subjects subjectGroups
+}
+
+// tallySenders queries all emails matching filter and tallies them by
+// lower-cased sender address, most emails first. Emails without a sender
+// are skipped. It also returns how many emails matched.
+func (c *Client) tallySenders(filter email.Filter) ([]*senderTally, uint64, error) {
+	accum := make(map[string]*senderTally)
+	scan, err := c.scanQuery(filter, []*email.SortComparator{{Property: "receivedAt", IsAscending: false}}, statsProperties, func(_ []jmap.ID, emails []*email.Email) {
+		for _, e := range emails {
+			if len(e.From) == 0 || e.From[0].Email == "" {
+				continue
+			}
+			key := strings.ToLower(e.From[0].Email)
+			acc, ok := accum[key]
+			if !ok {
+				acc = &senderTally{email: key, subjects: make(subjectGroups)}
+				accum[key] = acc
+			}
+			acc.count++
+			if !e.Keywords["$seen"] {
+				acc.unread++
+			}
+			if e.From[0].Name != "" && acc.name == "" {
+				acc.name = mimeword.Decode(e.From[0].Name)
+			}
+			if e.Subject != "" {
+				acc.subjects.add(mimeword.Decode(e.Subject))
+			}
+			if received := safeTime(e.ReceivedAt); received.After(acc.lastSeen) {
+				acc.lastSeen = received
+			}
+		}
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	tallies := make([]*senderTally, 0, len(accum))
+	for _, acc := range accum {
+		tallies = append(tallies, acc)
+	}
+	sort.Slice(tallies, func(i, j int) bool {
+		if tallies[i].count != tallies[j].count {
+			return tallies[i].count > tallies[j].count
+		}
+		return tallies[i].email < tallies[j].email
+	})
+	return tallies, scan.total, nil
+}
 
 // AggregateEmailsBySender queries all matching emails and returns per-sender counts.
 func (c *Client) AggregateEmailsBySender(opts StatsOptions) (types.StatsResult, error) {
@@ -636,109 +902,69 @@ func (c *Client) AggregateEmailsBySender(opts StatsOptions) (types.StatsResult,
 		}
 	}
 
-	type senderAcc struct {
-		count    int
-		name     string
-		subjects map[string]bool
+	tallies, total, err := c.tallySenders(filter)
+	if err != nil {
+		return types.StatsResult{}, fmt.Errorf("stats query: %w", err)
 	}
-	accum := make(map[string]*senderAcc)
-	var total uint64
-	var position int64
-
-	for {
-		req := &jmap.Request{}
-		queryCallID := req.Invoke(&email.Query{
-			Account:        c.accountID,
-			Filter:         filter,
-			Sort:           []*email.SortComparator{{Property: "receivedAt", IsAscending: false}},
-			Position:       position,
-			Limit:          500,
-			CalculateTotal: true,
-		})
-
-		req.Invoke(&email.Get{
-			Account:    c.accountID,
-			Properties: statsProperties,
-			ReferenceIDs: &jmap.ResultReference{
-				ResultOf: queryCallID,
-				Name:     "Email/query",
-				Path:     "/ids",
-			},
-		})
 
-		resp, err := c.Do(req)
-		if err != nil {
-			return types.StatsResult{}, fmt.Errorf("stats query: %w", err)
+	senders := make([]types.SenderStat, 0, len(tallies))
+	for _, t := range tallies {
+		stat := types.SenderStat{
+			Email: t.email,
+			Name:  t.name,
+			Count: t.count,
 		}
-
-		var pageIDs []jmap.ID
-		var emails []*email.Email
-		for _, inv := range resp.Responses {
-			switch r := inv.Args.(type) {
-			case *email.QueryResponse:
-				if position == 0 {
-					total = r.Total
-				}
-				pageIDs = r.IDs
-			case *email.GetResponse:
-				emails = r.List
-			case *jmap.MethodError:
-				return types.StatsResult{}, fmt.Errorf("stats query: %s", r.Error())
-			}
+		if opts.Subjects {
+			stat.Subjects = t.subjects.sorted()
 		}
+		senders = append(senders, stat)
+	}
 
-		for _, e := range emails {
-			if len(e.From) == 0 || e.From[0].Email == "" {
-				continue
-			}
-			key := strings.ToLower(e.From[0].Email)
-			acc, ok := accum[key]
-			if !ok {
-				acc = &senderAcc{subjects: make(map[string]bool)}
-				accum[key] = acc
-			}
-			acc.count++
-			if e.From[0].Name != "" && acc.name == "" {
-				acc.name = e.From[0].Name
-			}
-			if opts.Subjects && e.Subject != "" {
-				acc.subjects[e.Subject] = true
-			}
-		}
+	return types.StatsResult{
+		Total:   total,
+		Senders: senders,
+	}, nil
+}
 
-		position += int64(len(pageIDs))
-		if uint64(position) >= total || len(pageIDs) == 0 {
-			break
-		}
+// SendersOptions holds parameters for the correspondent frequency report.
+type SendersOptions struct {
+	MailboxID string
+	After     *time.Time
+	Limit     int
+}
+
+// AggregateSenders queries all matching emails and returns per-sender counts,
+// unread ratios, and last-seen dates, sorted by volume descending. A Limit of
+// zero returns every sender.
+func (c *Client) AggregateSenders(opts SendersOptions) (types.SendersResult, error) {
+	fc := &email.FilterCondition{
+		InMailbox: jmap.ID(opts.MailboxID),
+		After:     opts.After,
 	}
 
-	senders := make([]types.SenderStat, 0, len(accum))
-	for addr, acc := range accum {
-		stat := types.SenderStat{
-			Email: addr,
-			Name:  acc.name,
-			Count: acc.count,
-		}
-		if opts.Subjects && len(acc.subjects) > 0 {
-			subjects := make([]string, 0, len(acc.subjects))
-			for s := range acc.subjects {
-				subjects = append(subjects, s)
-			}
-			sort.Strings(subjects)
-			stat.Subjects = subjects
-		}
-		senders = append(senders, stat)
+	tallies, total, err := c.tallySenders(fc)
+	if err != nil {
+		return types.SendersResult{}, fmt.Errorf("senders query: %w", err)
+	}
+	if opts.Limit > 0 && len(tallies) > opts.Limit {
+		tallies = tallies[:opts.Limit]
 	}
 
-	sort.Slice(senders, func(i, j int) bool {
-		if senders[i].Count != senders[j].Count {
-			return senders[i].Count > senders[j].Count
-		}
-		return senders[i].Email < senders[j].Email
-	})
+	senders := make([]types.SenderActivity, 0, len(tallies))
+	for _, t := range tallies {
+		senders = append(senders, types.SenderActivity{
+			Email:       t.email,
+			Name:        t.name,
+			Count:       t.count,
+			Unread:      t.unread,
+			UnreadRatio: float64(t.unread) / float64(t.count),
+			LastSeen:    t.lastSeen,
+		})
+	}
 
-	return types.StatsResult{
+	return types.SendersResult{
 		Total:   total,
+		After:   opts.After,
 		Senders: senders,
 	}, nil
 }
@@ -795,57 +1021,13 @@ func (c *Client) AggregateSummary(opts SummaryOptions) (types.SummaryResult, err
 	type senderAcc struct {
 		count        int
 		name         string
-		subjects     map[string]bool
+		subjects     subjectGroups
 		isNewsletter bool
 	}
 	senders := make(map[string]*senderAcc)
 	domains := make(map[string]int)
-	var total uint64
 	var unread uint64
-	var position int64
-
-	for {
-		req := &jmap.Request{}
-		queryCallID := req.Invoke(&email.Query{
-			Account:        c.accountID,
-			Filter:         filter,
-			Sort:           []*email.SortComparator{{Property: "receivedAt", IsAscending: false}},
-			Position:       position,
-			Limit:          500,
-			CalculateTotal: true,
-		})
-
-		req.Invoke(&email.Get{
-			Account:    c.accountID,
-			Properties: props,
-			ReferenceIDs: &jmap.ResultReference{
-				ResultOf: queryCallID,
-				Name:     "Email/query",
-				Path:     "/ids",
-			},
-		})
-
-		resp, err := c.Do(req)
-		if err != nil {
-			return types.SummaryResult{}, fmt.Errorf("summary query: %w", err)
-		}
-
-		var pageIDs []jmap.ID
-		var emails []*email.Email
-		for _, inv := range resp.Responses {
-			switch r := inv.Args.(type) {
-			case *email.QueryResponse:
-				if position == 0 {
-					total = r.Total
-				}
-				pageIDs = r.IDs
-			case *email.GetResponse:
-				emails = r.List
-			case *jmap.MethodError:
-				return types.SummaryResult{}, fmt.Errorf("summary query: %s", r.Error())
-			}
-		}
-
+	scan, err := c.scanQuery(filter, []*email.SortComparator{{Property: "receivedAt", IsAscending: false}}, props, func(_ []jmap.ID, emails []*email.Email) {
 		for _, e := range emails {
 			if !e.Keywords["$seen"] {
 				unread++
@@ -858,15 +1040,15 @@ func (c *Client) AggregateSummary(opts SummaryOptions) (types.SummaryResult, err
 			key := strings.ToLower(e.From[0].Email)
 			acc, ok := senders[key]
 			if !ok {
-				acc = &senderAcc{subjects: make(map[string]bool)}
+				acc = &senderAcc{subjects: make(subjectGroups)}
 				senders[key] = acc
 			}
 			acc.count++
 			if e.From[0].Name != "" && acc.name == "" {
-				acc.name = e.From[0].Name
+				acc.name = mimeword.Decode(e.From[0].Name)
 			}
 			if opts.Subjects && e.Subject != "" {
-				acc.subjects[e.Subject] = true
+				acc.subjects.add(mimeword.Decode(e.Subject))
 			}
 			if opts.Newsletters && hasListHeaders(e.Headers) {
 				acc.isNewsletter = true
@@ -877,11 +1059,9 @@ func (c *Client) AggregateSummary(opts SummaryOptions) (types.SummaryResult, err
 				domains[domain]++
 			}
 		}
-
-		position += int64(len(pageIDs))
-		if uint64(position) >= total || len(pageIDs) == 0 {
-			break
-		}
+	})
+	if err != nil {
+		return types.SummaryResult{}, fmt.Errorf("summary query: %w", err)
 	}
 
 	// Build top senders.
@@ -892,13 +1072,8 @@ func (c *Client) AggregateSummary(opts SummaryOptions) (types.SummaryResult, err
 			Name:  acc.name,
 			Count: acc.count,
 		}
-		if opts.Subjects && len(acc.subjects) > 0 {
-			subjects := make([]string, 0, len(acc.subjects))
-			for s := range acc.subjects {
-				subjects = append(subjects, s)
-			}
-			sort.Strings(subjects)
-			stat.Subjects = subjects
+		if opts.Subjects {
+			stat.Subjects = acc.subjects.sorted()
 		}
 		topSenders = append(topSenders, stat)
 	}
@@ -928,7 +1103,7 @@ func (c *Client) AggregateSummary(opts SummaryOptions) (types.SummaryResult, err
 	}
 
 	result := types.SummaryResult{
-		Total:      total,
+		Total:      scan.total,
 		Unread:     unread,
 		TopSenders: topSenders,
 		TopDomains: topDomains,
@@ -946,13 +1121,8 @@ func (c *Client) AggregateSummary(opts SummaryOptions) (types.SummaryResult, err
 				Name:  acc.name,
 				Count: acc.count,
 			}
-			if opts.Subjects && len(acc.subjects) > 0 {
-				subjects := make([]string, 0, len(acc.subjects))
-				for s := range acc.subjects {
-					subjects = append(subjects, s)
-				}
-				sort.Strings(subjects)
-				stat.Subjects = subjects
+			if opts.Subjects {
+				stat.Subjects = acc.subjects.sorted()
 			}
 			newsletters = append(newsletters, stat)
 		}
@@ -996,6 +1166,22 @@ func (c *Client) MoveEmails(emailIDs []string, targetMailboxID jmap.ID) ([]strin
 	})
 }
 
+// UpdateMailboxes adds emails to the mailboxes in add and removes them from
+// the mailboxes in remove, leaving any other mailboxes they are in alone,
+// so that mailboxes can be used like labels.
+func (c *Client) UpdateMailboxes(emailIDs []string, add, remove []jmap.ID) ([]string, []string) {
+	return c.batchSetEmails(emailIDs, func(_ string) jmap.Patch {
+		patch := jmap.Patch{}
+		for _, id := range add {
+			patch["mailboxIds/"+string(id)] = true
+		}
+		for _, id := range remove {
+			patch["mailboxIds/"+string(id)] = nil
+		}
+		return patch
+	})
+}
+
 // MarkAsSpam moves emails to junk and sets the $junk keyword.
 func (c *Client) MarkAsSpam(emailIDs []string, junkMailboxID jmap.ID) ([]string, []string) {
 	return c.batchSetEmails(emailIDs, func(_ string) jmap.Patch {
@@ -1006,18 +1192,60 @@ func (c *Client) MarkAsSpam(emailIDs []string, junkMailboxID jmap.ID) ([]string,
 	})
 }
 
-// MarkAsRead sets the $seen keyword on emails.
-func (c *Client) MarkAsRead(emailIDs []string) ([]string, []string) {
+// ReportPhishing moves emails to the Junk mailbox and sets both the $junk and
+// $phishing keywords. It returns succeeded and failed email IDs.
+func (c *Client) ReportPhishing(emailIDs []string, junkMailboxID jmap.ID) ([]string, []string) {
 	return c.batchSetEmails(emailIDs, func(_ string) jmap.Patch {
-		return jmap.Patch{"keywords/$seen": true}
+		return jmap.Patch{
+			"mailboxIds":         map[jmap.ID]bool{junkMailboxID: true},
+			"keywords/$junk":     true,
+			"keywords/$phishing": true,
+		}
 	})
 }
 
+// DownloadRawEmail returns the raw RFC 5322 message for an email. The caller
+// must close the returned reader.
+func (c *Client) DownloadRawEmail(emailID string) (io.ReadCloser, error) {
+	req := &jmap.Request{}
+	req.Invoke(&email.Get{
+		Account:    c.accountID,
+		IDs:        []jmap.ID{jmap.ID(emailID)},
+		Properties: []string{"id", "blobId"},
+	})
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("email/get: %w", err)
+	}
+
+	for _, inv := range resp.Responses {
+		switch r := inv.Args.(type) {
+		case *email.GetResponse:
+			if len(r.NotFound) > 0 || len(r.List) == 0 {
+				return nil, fmt.Errorf("email %s: %w", emailID, ErrNotFound)
+			}
+			body, err := c.Download(c.accountID, r.List[0].BlobID)
+			if err != nil {
+				return nil, fmt.Errorf("downloading email %s: %w", emailID, err)
+			}
+			return body, nil
+		case *jmap.MethodError:
+			return nil, fmt.Errorf("email/get: %s", r.Error())
+		}
+	}
+
+	return nil, fmt.Errorf("email/get: unexpected response")
+}
+
+// MarkAsRead sets the $seen keyword on emails.
+func (c *Client) MarkAsRead(emailIDs []string) ([]string, []string) {
+	return c.SetKeyword(emailIDs, "$seen")
+}
+
 // SetFlagged sets the $flagged keyword on emails.
 func (c *Client) SetFlagged(emailIDs []string) ([]string, []string) {
-	return c.batchSetEmails(emailIDs, func(_ string) jmap.Patch {
-		return jmap.Patch{"keywords/$flagged": true}
-	})
+	return c.SetKeyword(emailIDs, "$flagged")
 }
 
 // SetFlaggedWithColor sets the $flagged keyword and the color bits on emails.
@@ -1056,7 +1284,7 @@ func (c *Client) GetEmailSummaries(ids []string) ([]types.EmailSummary, []string
 	var allSummaries []types.EmailSummary
 	var allNotFound []string
 
-	size := c.maxBatchSize()
+	size := c.maxGetSize()
 	for start := 0; start < len(ids); start += size {
 		end := start + size
 		if end > len(ids) {
@@ -1097,6 +1325,69 @@ func (c *Client) GetEmailSummaries(ids []string) ([]types.EmailSummary, []string
 	return allSummaries, allNotFound, nil
 }
 
+// EmailHeaders pairs an email summary with its raw header fields, in message
+// order.
+type EmailHeaders struct {
+	Summary types.EmailSummary
+	Headers []types.Header
+}
+
+// GetEmailHeaders fetches summaries and raw headers for the given email IDs
+// in batches. It returns the found emails and the IDs that were not found.
+func (c *Client) GetEmailHeaders(ids []string) ([]EmailHeaders, []string, error) {
+	var all []EmailHeaders
+	var allNotFound []string
+
+	props := append(append([]string{}, summaryProperties...), "headers")
+
+	size := c.maxGetSize()
+	for start := 0; start < len(ids); start += size {
+		end := start + size
+		if end > len(ids) {
+			end = len(ids)
+		}
+		batch := ids[start:end]
+
+		jmapIDs := make([]jmap.ID, len(batch))
+		for i, id := range batch {
+			jmapIDs[i] = jmap.ID(id)
+		}
+
+		req := &jmap.Request{}
+		req.Invoke(&email.Get{
+			Account:    c.accountID,
+			IDs:        jmapIDs,
+			Properties: props,
+		})
+
+		resp, err := c.Do(req)
+		if err != nil {
+			return nil, nil, fmt.Errorf("email/get: %w", err)
+		}
+
+		for _, inv := range resp.Responses {
+			switch r := inv.Args.(type) {
+			case *email.GetResponse:
+				summaries := convertSummaries(r.List)
+				for i, e := range r.List {
+					entry := EmailHeaders{Summary: summaries[i]}
+					for _, h := range e.Headers {
+						entry.Headers = append(entry.Headers, types.Header{Name: h.Name, Value: mimeword.Decode(h.Value)})
+					}
+					all = append(all, entry)
+				}
+				for _, nf := range r.NotFound {
+					allNotFound = append(allNotFound, string(nf))
+				}
+			case *jmap.MethodError:
+				return nil, nil, fmt.Errorf("email/get: %s", r.Error())
+			}
+		}
+	}
+
+	return all, allNotFound, nil
+}
+
 // --- Conversion helpers ---
 
 func convertAddresses(addrs []*mail.Address) []types.Address {
@@ -1105,7 +1396,7 @@ func convertAddresses(addrs []*mail.Address) []types.Address {
 	}
 	out := make([]types.Address, len(addrs))
 	for i, a := range addrs {
-		out[i] = types.Address{Name: a.Name, Email: a.Email}
+		out[i] = types.Address{Name: mimeword.Decode(a.Name), Email: a.Email}
 	}
 	return out
 }
@@ -1114,31 +1405,35 @@ func convertSummaries(emails []*email.Email) []types.EmailSummary {
 	out := make([]types.EmailSummary, len(emails))
 	for i, e := range emails {
 		out[i] = types.EmailSummary{
-			ID:         string(e.ID),
-			ThreadID:   string(e.ThreadID),
-			From:       convertAddresses(e.From),
-			To:         convertAddresses(e.To),
-			CC:         convertAddresses(e.CC),
-			Subject:    e.Subject,
-			ReceivedAt: safeTime(e.ReceivedAt),
-			Size:       e.Size,
-			IsUnread:   !e.Keywords["$seen"],
-			IsFlagged:  e.Keywords["$flagged"],
-			Preview:    e.Preview,
+			ID:          string(e.ID),
+			ThreadID:    string(e.ThreadID),
+			From:        convertAddresses(e.From),
+			To:          convertAddresses(e.To),
+			CC:          convertAddresses(e.CC),
+			Subject:     mimeword.Decode(e.Subject),
+			ReceivedAt:  safeTime(e.ReceivedAt),
+			Size:        e.Size,
+			IsUnread:    !e.Keywords["$seen"],
+			IsFlagged:   e.Keywords["$flagged"],
+			IsForwarded: e.Keywords["$forwarded"],
+			IsDraft:     e.Keywords["$draft"],
+			Preview:     e.Preview,
 		}
 	}
 	return out
 }
 
 func convertDetail(e *email.Email, preferHTML bool, rawHeaders bool) types.EmailDetail {
-	body := extractBody(e, preferHTML)
+	body, truncated := extractBodyValue(e, preferHTML)
 
 	var attachments []types.Attachment
 	for _, a := range e.Attachments {
 		attachments = append(attachments, types.Attachment{
-			Name: a.Name,
-			Type: a.Type,
-			Size: a.Size,
+			Name:   mimeword.Decode(a.Name),
+			Type:   a.Type,
+			Size:   a.Size,
+			BlobID: string(a.BlobID),
+			Inline: isInlinePart(a),
 		})
 	}
 	if attachments == nil {
@@ -1146,20 +1441,22 @@ func convertDetail(e *email.Email, preferHTML bool, rawHeaders bool) types.Email
 	}
 
 	detail := types.EmailDetail{
-		ID:          string(e.ID),
-		ThreadID:    string(e.ThreadID),
-		From:        convertAddresses(e.From),
-		To:          convertAddresses(e.To),
-		CC:          convertAddresses(e.CC),
-		BCC:         convertAddresses(e.BCC),
-		ReplyTo:     convertAddresses(e.ReplyTo),
-		Subject:     e.Subject,
-		SentAt:      e.SentAt,
-		ReceivedAt:  safeTime(e.ReceivedAt),
-		IsUnread:    !e.Keywords["$seen"],
-		IsFlagged:   e.Keywords["$flagged"],
-		Body:        body,
-		Attachments: attachments,
+		ID:            string(e.ID),
+		ThreadID:      string(e.ThreadID),
+		From:          convertAddresses(e.From),
+		To:            convertAddresses(e.To),
+		CC:            convertAddresses(e.CC),
+		BCC:           convertAddresses(e.BCC),
+		ReplyTo:       convertAddresses(e.ReplyTo),
+		Subject:       mimeword.Decode(e.Subject),
+		SentAt:        e.SentAt,
+		ReceivedAt:    safeTime(e.ReceivedAt),
+		IsUnread:      !e.Keywords["$seen"],
+		IsFlagged:     e.Keywords["$flagged"],
+		IsDraft:       e.Keywords["$draft"],
+		Body:          body,
+		BodyTruncated: truncated,
+		Attachments:   attachments,
 	}
 
 	for _, h := range e.Headers {
@@ -1171,7 +1468,7 @@ func convertDetail(e *email.Email, preferHTML bool, rawHeaders bool) types.Email
 		if rawHeaders {
 			detail.Headers = append(detail.Headers, types.Header{
 				Name:  h.Name,
-				Value: h.Value,
+				Value: mimeword.Decode(h.Value),
 			})
 		}
 	}
@@ -1179,26 +1476,39 @@ func convertDetail(e *email.Email, preferHTML bool, rawHeaders bool) types.Email
 	return detail
 }
 
+// extractBody returns the text body of e, or its HTML body as sent when
+// preferHTML is set. HTML-only emails are rendered to text, since servers
+// list the HTML part as the text body when there is no plain alternative.
 func extractBody(e *email.Email, preferHTML bool) string {
+	body, _ := extractBodyValue(e, preferHTML)
+	return body
+}
+
+// extractBodyValue is extractBody that also reports whether the server
+// truncated the body value it used.
+func extractBodyValue(e *email.Email, preferHTML bool) (string, bool) {
 	if preferHTML {
 		for _, part := range e.HTMLBody {
 			if bv, ok := e.BodyValues[part.PartID]; ok {
-				return bv.Value
+				return bv.Value, bv.IsTruncated
 			}
 		}
 	}
 	for _, part := range e.TextBody {
 		if bv, ok := e.BodyValues[part.PartID]; ok {
-			return bv.Value
+			if part.Type == "text/html" {
+				return htmltext.Render(bv.Value), bv.IsTruncated
+			}
+			return bv.Value, bv.IsTruncated
 		}
 	}
 	// Fall back to HTML if text is empty.
 	for _, part := range e.HTMLBody {
 		if bv, ok := e.BodyValues[part.PartID]; ok {
-			return bv.Value
+			return htmltext.Render(bv.Value), bv.IsTruncated
 		}
 	}
-	return ""
+	return "", false
 }
 
 func safeTime(t *time.Time) time.Time {
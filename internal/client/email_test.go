@@ -1,7 +1,10 @@
 package client
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -127,8 +130,24 @@ func TestExtractBody_FallbackToHTML(t *testing.T) {
 		},
 	}
 	body := extractBody(e, false)
-	if body != "<p>only html</p>" {
-		t.Errorf("expected HTML fallback, got: %s", body)
+	if body != "only html\n" {
+		t.Errorf("expected HTML fallback rendered as text, got: %q", body)
+	}
+}
+
+func TestExtractBody_HTMLTextBodyRendered(t *testing.T) {
+	e := &email.Email{
+		TextBody: []*email.BodyPart{{PartID: "1", Type: "text/html"}},
+		HTMLBody: []*email.BodyPart{{PartID: "1", Type: "text/html"}},
+		BodyValues: map[string]*email.BodyValue{
+			"1": {Value: `<p>Your <a href="https://example.com/t">ticket</a></p>`},
+		},
+	}
+	if body := extractBody(e, false); body != "Your ticket[1]\n\n[1] https://example.com/t\n" {
+		t.Errorf("expected rendered HTML, got: %q", body)
+	}
+	if body := extractBody(e, true); body != `<p>Your <a href="https://example.com/t">ticket</a></p>` {
+		t.Errorf("expected HTML as sent with preferHTML, got: %q", body)
 	}
 }
 
@@ -238,6 +257,21 @@ func TestConvertSummaries_NilKeywords(t *testing.T) {
 	}
 }
 
+func TestConvertSummaries_DecodesEncodedWords(t *testing.T) {
+	emails := []*email.Email{{
+		ID:      "M1",
+		From:    []*mail.Address{{Name: "=?UTF-8?B?SsO8cmdlbiBNw7xsbGVy?=", Email: "j@test.com"}},
+		Subject: "Re: =?UTF-8?Q?r=C3=A9union?=",
+	}}
+	got := convertSummaries(emails)[0]
+	if got.From[0].Name != "Jürgen Müller" {
+		t.Errorf("expected decoded sender name, got %q", got.From[0].Name)
+	}
+	if got.Subject != "Re: réunion" {
+		t.Errorf("expected decoded subject, got %q", got.Subject)
+	}
+}
+
 // --- convertDetail tests ---
 
 func TestConvertDetail_Basic(t *testing.T) {
@@ -428,6 +462,35 @@ func TestConvertDetail_EmptyAddresses(t *testing.T) {
 	}
 }
 
+func TestReadEmail_MaxBodyBytes(t *testing.T) {
+	var gotGet *email.Get
+	c := &Client{
+		accountID: "test-account",
+		doFunc: func(req *jmap.Request) (*jmap.Response, error) {
+			gotGet = req.Calls[0].Args.(*email.Get)
+			return &jmap.Response{Responses: []*jmap.Invocation{
+				{Name: "Email/get", CallID: "0", Args: &email.GetResponse{List: []*email.Email{{
+					ID:         "M1",
+					TextBody:   []*email.BodyPart{{PartID: "1", Type: "text/plain"}},
+					BodyValues: map[string]*email.BodyValue{"1": {Value: "The start", IsTruncated: true}},
+				}}}},
+			}}, nil
+		},
+	}
+	c.SetMaxBodyBytes(1024)
+
+	detail, err := c.ReadEmail("M1", false, false)
+	if err != nil {
+		t.Fatalf("ReadEmail returned error: %v", err)
+	}
+	if gotGet.MaxBodyValueBytes != 1024 {
+		t.Errorf("expected maxBodyValueBytes 1024, got %d", gotGet.MaxBodyValueBytes)
+	}
+	if detail.Body != "The start" || !detail.BodyTruncated {
+		t.Errorf("expected a truncated body, got %q (truncated=%v)", detail.Body, detail.BodyTruncated)
+	}
+}
+
 // --- singleThreadEntry tests ---
 
 func TestSingleThreadEntry(t *testing.T) {
@@ -456,6 +519,51 @@ func TestSingleThreadEntry(t *testing.T) {
 	}
 }
 
+// --- summarizeThread tests ---
+
+func TestSummarizeThread(t *testing.T) {
+	first := time.Date(2026, 2, 1, 9, 0, 0, 0, time.UTC)
+	last := time.Date(2026, 2, 4, 10, 30, 0, 0, time.UTC)
+	thread := []types.ThreadEmail{
+		{
+			ID:              "M1",
+			From:            []types.Address{{Name: "Alice", Email: "alice@test.com"}},
+			To:              []types.Address{{Email: "bob@test.com"}},
+			CC:              []types.Address{{Name: "Carol", Email: "carol@test.com"}},
+			ReceivedAt:      first,
+			AttachmentBytes: 1000,
+		},
+		{
+			ID:         "M2",
+			From:       []types.Address{{Name: "Bob", Email: "Bob@test.com"}},
+			To:         []types.Address{{Name: "Alice", Email: "alice@test.com"}},
+			ReceivedAt: last,
+			IsUnread:   true,
+		},
+	}
+
+	stats := summarizeThread(thread)
+	if stats.Messages != 2 || stats.Unread != 1 || stats.AttachmentBytes != 1000 {
+		t.Errorf("unexpected counts: %+v", stats)
+	}
+	if !stats.FirstAt.Equal(first) || !stats.LastAt.Equal(last) {
+		t.Errorf("expected span %v to %v, got %v to %v", first, last, stats.FirstAt, stats.LastAt)
+	}
+	want := []types.ThreadParticipant{
+		{Name: "Alice", Email: "alice@test.com", Sent: 1},
+		{Name: "Bob", Email: "bob@test.com", Sent: 1},
+		{Name: "Carol", Email: "carol@test.com", Sent: 0},
+	}
+	if len(stats.Participants) != len(want) {
+		t.Fatalf("expected %d participants, got %+v", len(want), stats.Participants)
+	}
+	for i, p := range want {
+		if stats.Participants[i] != p {
+			t.Errorf("participant %d: expected %+v, got %+v", i, p, stats.Participants[i])
+		}
+	}
+}
+
 // --- batchSetEmails tests ---
 
 func TestBatchSetEmails_UsesServerMaxObjectsInSet(t *testing.T) {
@@ -663,6 +771,42 @@ func TestBatchSetEmails_MixedPerIDErrors(t *testing.T) {
 	}
 }
 
+func TestBatchSetEmails_ReportsOutcomes(t *testing.T) {
+	desc := "email not found"
+	c := &Client{
+		accountID: "test-account",
+		doFunc: func(req *jmap.Request) (*jmap.Response, error) {
+			return &jmap.Response{Responses: []*jmap.Invocation{
+				{
+					Name:   "Email/set",
+					CallID: "0",
+					Args: &email.SetResponse{
+						Updated: map[jmap.ID]*email.Email{"M1": {}},
+						NotUpdated: map[jmap.ID]*jmap.SetError{
+							"M2": {Type: "notFound", Description: &desc},
+						},
+					},
+				},
+			}}, nil
+		},
+	}
+	var got []types.MessageOutcome
+	c.SetOutcomeObserver(func(o types.MessageOutcome) { got = append(got, o) })
+
+	c.batchSetEmails([]string{"M1", "M2", "M3"}, func(_ string) jmap.Patch {
+		return jmap.Patch{"keywords/$seen": true}
+	})
+
+	want := []types.MessageOutcome{
+		{ID: "M1", Status: "ok"},
+		{ID: "M2", Status: "notFound", Reason: "email not found"},
+		{ID: "M3", Status: "serverError", Reason: "no status returned by server"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("outcomes = %+v, want %+v", got, want)
+	}
+}
+
 func TestBatchSetEmails_UnaccountedID(t *testing.T) {
 	c := &Client{
 		accountID: "test-account",
@@ -715,6 +859,15 @@ func TestSummaryPropertiesContainsRequired(t *testing.T) {
 	}
 }
 
+func TestSummaryPropertiesExcludeBodies(t *testing.T) {
+	for _, p := range summaryProperties {
+		switch p {
+		case "bodyValues", "textBody", "htmlBody", "attachments", "bodyStructure", "headers":
+			t.Errorf("summaryProperties should not fetch %s", p)
+		}
+	}
+}
+
 func TestDetailPropertiesContainsRequired(t *testing.T) {
 	required := []string{"id", "threadId", "from", "to", "cc", "bcc", "subject", "sentAt", "receivedAt", "bodyValues", "textBody", "htmlBody", "attachments"}
 	for _, r := range required {
@@ -768,7 +921,7 @@ func TestSearchSnippetReference(t *testing.T) {
 			captured = req
 			return &jmap.Response{Responses: []*jmap.Invocation{
 				{Name: "Email/query", CallID: "0", Args: &email.QueryResponse{Total: 1, IDs: []jmap.ID{"M1"}}},
-				{Name: "Email/get", CallID: "1", Args: &email.GetResponse{List: []*email.Email{{ID: "M1", Subject: "meeting"}}}},
+				{Name: "Email/get", CallID: "1", Args: &email.GetResponse{List: []*email.Email{{ID: "M1", Subject: "meeting", Preview: "Agenda for the meeting"}}}},
 				{Name: "SearchSnippet/get", CallID: "2", Args: &searchsnippet.GetResponse{List: []*searchsnippet.SearchSnippet{{Email: "M1", Preview: "...<mark>meeting</mark>..."}}}},
 			}}, nil
 		},
@@ -2332,6 +2485,80 @@ func TestSpamPatchStructure(t *testing.T) {
 	}
 }
 
+func TestReportPhishing_SetsJunkAndPhishingKeywords(t *testing.T) {
+	var patch jmap.Patch
+	c := &Client{
+		accountID: "test-account",
+		doFunc: func(req *jmap.Request) (*jmap.Response, error) {
+			setReq := req.Calls[0].Args.(*email.Set)
+			patch = setReq.Update["M1"]
+			return &jmap.Response{Responses: []*jmap.Invocation{
+				{Name: "Email/set", CallID: "0", Args: &email.SetResponse{
+					Updated: map[jmap.ID]*email.Email{"M1": {}},
+				}},
+			}}, nil
+		},
+	}
+
+	succeeded, errs := c.ReportPhishing([]string{"M1"}, "junk-mb")
+	if len(succeeded) != 1 || len(errs) != 0 {
+		t.Fatalf("expected 1 success and no errors, got %v / %v", succeeded, errs)
+	}
+	if patch["keywords/$junk"] != true || patch["keywords/$phishing"] != true {
+		t.Errorf("expected $junk and $phishing keywords, got %v", patch)
+	}
+	if mbs, ok := patch["mailboxIds"].(map[jmap.ID]bool); !ok || !mbs["junk-mb"] || len(mbs) != 1 {
+		t.Errorf("expected mailboxIds to be exactly the junk mailbox, got %v", patch["mailboxIds"])
+	}
+}
+
+func TestDownloadRawEmail(t *testing.T) {
+	var downloaded jmap.ID
+	c := &Client{
+		accountID: "test-account",
+		doFunc: func(req *jmap.Request) (*jmap.Response, error) {
+			return &jmap.Response{Responses: []*jmap.Invocation{
+				{Name: "Email/get", CallID: "0", Args: &email.GetResponse{
+					List: []*email.Email{{ID: "M1", BlobID: "B1"}},
+				}},
+			}}, nil
+		},
+		downloadFunc: func(_ jmap.ID, blobID jmap.ID) (io.ReadCloser, error) {
+			downloaded = blobID
+			return io.NopCloser(strings.NewReader("Subject: hi\r\n\r\nbody")), nil
+		},
+	}
+
+	body, err := c.DownloadRawEmail("M1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer body.Close()
+
+	data, _ := io.ReadAll(body)
+	if downloaded != "B1" {
+		t.Errorf("expected blob B1 to be downloaded, got %q", downloaded)
+	}
+	if !strings.HasPrefix(string(data), "Subject: hi") {
+		t.Errorf("expected raw message, got %q", data)
+	}
+}
+
+func TestDownloadRawEmail_NotFound(t *testing.T) {
+	c := &Client{
+		accountID: "test-account",
+		doFunc: func(req *jmap.Request) (*jmap.Response, error) {
+			return &jmap.Response{Responses: []*jmap.Invocation{
+				{Name: "Email/get", CallID: "0", Args: &email.GetResponse{NotFound: []jmap.ID{"M9"}}},
+			}}, nil
+		},
+	}
+
+	if _, err := c.DownloadRawEmail("M9"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
 // --- buildSearchFilter tests ---
 
 func TestBuildSearchFilter_Basic(t *testing.T) {
@@ -2420,6 +2647,77 @@ func TestBuildSearchFilter_UnflaggedAndUnread(t *testing.T) {
 	}
 }
 
+func TestBuildSearchFilter_Read(t *testing.T) {
+	fc, ok := buildSearchFilter(SearchOptions{ReadOnly: true}).(*email.FilterCondition)
+	if !ok || fc.HasKeyword != "$seen" {
+		t.Fatalf("expected a condition with HasKeyword=$seen, got %+v", fc)
+	}
+
+	op, ok := buildSearchFilter(SearchOptions{ReadOnly: true, FlaggedOnly: true}).(*email.FilterOperator)
+	if !ok {
+		t.Fatal("expected an AND operator when --read is combined with --flagged")
+	}
+	if len(op.Conditions) != 2 {
+		t.Fatalf("expected 2 conditions, got %d", len(op.Conditions))
+	}
+	first, _ := op.Conditions[0].(*email.FilterCondition)
+	second, _ := op.Conditions[1].(*email.FilterCondition)
+	if first == nil || first.HasKeyword != "$flagged" || second == nil || second.HasKeyword != "$seen" {
+		t.Errorf("expected HasKeyword $flagged AND $seen, got %+v and %+v", first, second)
+	}
+}
+
+func TestBuildSearchFilter_TextHeader(t *testing.T) {
+	filter := buildSearchFilter(SearchOptions{Text: "acme", TextHeader: "Received"})
+	fc, ok := filter.(*email.FilterCondition)
+	if !ok {
+		t.Fatalf("expected *email.FilterCondition, got %T", filter)
+	}
+	if fc.Text != "" {
+		t.Errorf("expected Text to be unset when scoped to a header, got %q", fc.Text)
+	}
+	if len(fc.Header) != 2 || fc.Header[0] != "Received" || fc.Header[1] != "acme" {
+		t.Errorf("expected Header=[Received acme], got %v", fc.Header)
+	}
+}
+
+func TestBuildSearchFilter_ExcludeMailboxes(t *testing.T) {
+	fc, ok := buildSearchFilter(SearchOptions{ExcludeMailboxIDs: []string{"mb-trash", "mb-junk"}}).(*email.FilterCondition)
+	if !ok {
+		t.Fatal("expected *email.FilterCondition")
+	}
+	if len(fc.InMailboxOtherThan) != 2 || fc.InMailboxOtherThan[0] != "mb-trash" || fc.InMailboxOtherThan[1] != "mb-junk" {
+		t.Errorf("expected InMailboxOtherThan=[mb-trash mb-junk], got %v", fc.InMailboxOtherThan)
+	}
+
+	fc, _ = buildSearchFilter(SearchOptions{MailboxID: "mb-trash", ExcludeMailboxIDs: []string{"mb-trash"}}).(*email.FilterCondition)
+	if fc.InMailbox != "mb-trash" || len(fc.InMailboxOtherThan) != 0 {
+		t.Errorf("expected an explicit mailbox to override exclusions, got %+v", fc)
+	}
+}
+
+func TestBuildSearchFilter_ForwardedAndNoDrafts(t *testing.T) {
+	op, ok := buildSearchFilter(SearchOptions{UnreadOnly: true, ForwardedOnly: true, ExcludeDrafts: true}).(*email.FilterOperator)
+	if !ok {
+		t.Fatal("expected an AND operator for --forwarded and --no-drafts")
+	}
+	if op.Operator != jmap.OperatorAND || len(op.Conditions) != 3 {
+		t.Fatalf("expected 3 conditions joined with AND, got %+v", op)
+	}
+	first, _ := op.Conditions[0].(*email.FilterCondition)
+	second, _ := op.Conditions[1].(*email.FilterCondition)
+	third, _ := op.Conditions[2].(*email.FilterCondition)
+	if first == nil || first.NotKeyword != "$seen" {
+		t.Errorf("expected the first condition to keep NotKeyword=$seen, got %+v", first)
+	}
+	if second == nil || second.HasKeyword != "$forwarded" {
+		t.Errorf("expected HasKeyword=$forwarded, got %+v", second)
+	}
+	if third == nil || third.NotKeyword != "$draft" {
+		t.Errorf("expected NotKeyword=$draft, got %+v", third)
+	}
+}
+
 func TestBuildSearchFilter_Empty(t *testing.T) {
 	filter := buildSearchFilter(SearchOptions{})
 	fc, ok := filter.(*email.FilterCondition)
@@ -2515,6 +2813,84 @@ func TestQueryEmailIDs_MultiplePages(t *testing.T) {
 	}
 }
 
+func TestQueryEmailIDs_AnchorsLaterPages(t *testing.T) {
+	var queries []*email.Query
+
+	c := &Client{
+		accountID: "test-account",
+		doFunc: func(req *jmap.Request) (*jmap.Response, error) {
+			query := req.Calls[0].Args.(*email.Query)
+			queries = append(queries, query)
+
+			var pageIDs []jmap.ID
+			switch {
+			case query.Anchor == "":
+				for i := 0; i < 250; i++ {
+					pageIDs = append(pageIDs, jmap.ID(fmt.Sprintf("M%d", i)))
+				}
+			case query.Anchor == "M249" && query.AnchorOffset == 1:
+				pageIDs = []jmap.ID{"M250", "M251"}
+			}
+
+			return &jmap.Response{Responses: []*jmap.Invocation{
+				{Name: "Email/query", CallID: "0", Args: &email.QueryResponse{Total: 252, IDs: pageIDs}},
+			}}, nil
+		},
+	}
+
+	ids, err := c.QueryEmailIDs(SearchOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ids) != 252 {
+		t.Fatalf("expected 252 IDs, got %d", len(ids))
+	}
+	if len(queries) != 2 || queries[1].Anchor != "M249" {
+		t.Fatalf("expected the second page to be anchored on M249, got %+v", queries)
+	}
+}
+
+func TestQueryEmailIDs_AnchorNotFoundFallsBackToPosition(t *testing.T) {
+	var queries []email.Query
+
+	c := &Client{
+		accountID: "test-account",
+		doFunc: func(req *jmap.Request) (*jmap.Response, error) {
+			query := req.Calls[0].Args.(*email.Query)
+			queries = append(queries, *query)
+
+			var args any
+			switch {
+			case query.Anchor != "":
+				args = &jmap.MethodError{Type: "anchorNotFound"}
+			case query.Position == 0:
+				var pageIDs []jmap.ID
+				for i := 0; i < 250; i++ {
+					pageIDs = append(pageIDs, jmap.ID(fmt.Sprintf("M%d", i)))
+				}
+				args = &email.QueryResponse{Total: 251, IDs: pageIDs}
+			default:
+				args = &email.QueryResponse{Total: 251, IDs: []jmap.ID{"M250"}}
+			}
+
+			return &jmap.Response{Responses: []*jmap.Invocation{
+				{Name: "Email/query", CallID: "0", Args: args},
+			}}, nil
+		},
+	}
+
+	ids, err := c.QueryEmailIDs(SearchOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ids) != 251 {
+		t.Fatalf("expected 251 IDs, got %d", len(ids))
+	}
+	if len(queries) != 3 || queries[2].Anchor != "" || queries[2].Position != 250 {
+		t.Fatalf("expected a position-based retry after anchorNotFound, got %+v", queries)
+	}
+}
+
 func TestQueryEmailIDs_EmptyResult(t *testing.T) {
 	c := &Client{
 		accountID: "test-account",
@@ -3069,3 +3445,278 @@ func TestAggregateSummary_SubjectFilter(t *testing.T) {
 		t.Errorf("expected Subject=%q, got %q", "Daily Digest", fc.Subject)
 	}
 }
+
+// --- AggregateSenders tests ---
+
+func TestAggregateSenders_CountsUnreadAndLastSeen(t *testing.T) {
+	newer := time.Date(2026, 10, 14, 8, 0, 0, 0, time.UTC)
+	older := time.Date(2026, 9, 1, 8, 0, 0, 0, time.UTC)
+	after := time.Date(2026, 7, 17, 0, 0, 0, 0, time.UTC)
+
+	var gotFilter *email.FilterCondition
+	c := &Client{
+		accountID: "test-account",
+		doFunc: func(req *jmap.Request) (*jmap.Response, error) {
+			gotFilter, _ = req.Calls[0].Args.(*email.Query).Filter.(*email.FilterCondition)
+			return &jmap.Response{Responses: []*jmap.Invocation{
+				{Name: "Email/query", CallID: "0", Args: &email.QueryResponse{
+					Total: 3,
+					IDs:   []jmap.ID{"M1", "M2", "M3"},
+				}},
+				{Name: "Email/get", CallID: "1", Args: &email.GetResponse{
+					List: []*email.Email{
+						{ID: "M1", From: []*mail.Address{{Name: "News", Email: "News@Example.com"}}, Keywords: map[string]bool{}, ReceivedAt: &newer},
+						{ID: "M2", From: []*mail.Address{{Email: "news@example.com"}}, Keywords: map[string]bool{"$seen": true}, ReceivedAt: &older},
+						{ID: "M3", From: []*mail.Address{{Email: "bob@example.com"}}, Keywords: map[string]bool{"$seen": true}, ReceivedAt: &older},
+					},
+				}},
+			}}, nil
+		},
+	}
+
+	result, err := c.AggregateSenders(SendersOptions{MailboxID: "mb-inbox", After: &after})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotFilter == nil || gotFilter.After == nil || !gotFilter.After.Equal(after) {
+		t.Errorf("expected After filter %v, got %+v", after, gotFilter)
+	}
+	if len(result.Senders) != 2 {
+		t.Fatalf("expected 2 senders, got %d", len(result.Senders))
+	}
+	news := result.Senders[0]
+	if news.Email != "news@example.com" || news.Count != 2 || news.Unread != 1 {
+		t.Errorf("expected news@example.com count=2 unread=1, got %+v", news)
+	}
+	if news.UnreadRatio != 0.5 {
+		t.Errorf("expected unread ratio 0.5, got %v", news.UnreadRatio)
+	}
+	if !news.LastSeen.Equal(newer) {
+		t.Errorf("expected last seen %v, got %v", newer, news.LastSeen)
+	}
+	if result.Senders[1].UnreadRatio != 0 {
+		t.Errorf("expected bob unread ratio 0, got %v", result.Senders[1].UnreadRatio)
+	}
+}
+
+func TestAggregateSenders_Limit(t *testing.T) {
+	c := &Client{
+		accountID: "test-account",
+		doFunc: func(req *jmap.Request) (*jmap.Response, error) {
+			return &jmap.Response{Responses: []*jmap.Invocation{
+				{Name: "Email/query", CallID: "0", Args: &email.QueryResponse{
+					Total: 2,
+					IDs:   []jmap.ID{"M1", "M2"},
+				}},
+				{Name: "Email/get", CallID: "1", Args: &email.GetResponse{
+					List: []*email.Email{
+						{ID: "M1", From: []*mail.Address{{Email: "a@test.com"}}},
+						{ID: "M2", From: []*mail.Address{{Email: "b@test.com"}}},
+					},
+				}},
+			}}, nil
+		},
+	}
+
+	result, err := c.AggregateSenders(SendersOptions{MailboxID: "mb-inbox", Limit: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Senders) != 1 || result.Total != 2 {
+		t.Errorf("expected 1 sender of 2 total, got %d of %d", len(result.Senders), result.Total)
+	}
+}
+
+func TestCountEmails(t *testing.T) {
+	var gotQuery *email.Query
+	c := &Client{
+		accountID: "test-account",
+		doFunc: func(req *jmap.Request) (*jmap.Response, error) {
+			gotQuery = req.Calls[0].Args.(*email.Query)
+			return &jmap.Response{Responses: []*jmap.Invocation{
+				{Name: "Email/query", Args: &email.QueryResponse{Total: 17, IDs: []jmap.ID{"M1"}}},
+			}}, nil
+		},
+	}
+
+	n, err := c.CountEmails(SearchOptions{MailboxID: "mb-inbox", UnreadOnly: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 17 {
+		t.Errorf("expected 17, got %d", n)
+	}
+	if !gotQuery.CalculateTotal || gotQuery.Limit != 1 {
+		t.Errorf("expected a total-only query, got %+v", gotQuery)
+	}
+}
+
+// --- aggregateParticipants tests ---
+
+func TestAggregateParticipants(t *testing.T) {
+	alice := types.Address{Name: "Alice", Email: "Alice@Example.com"}
+	bob := types.Address{Name: "Bob", Email: "bob@example.com"}
+	carol := types.Address{Email: "carol@example.com"}
+	summaries := []types.EmailSummary{
+		{ID: "M1", From: []types.Address{alice}, To: []types.Address{bob}, CC: []types.Address{carol}},
+		{ID: "M2", From: []types.Address{bob}, To: []types.Address{alice, bob}},
+		{ID: "M3", From: []types.Address{{Email: "alice@example.com"}}, To: []types.Address{bob}},
+	}
+
+	got := aggregateParticipants(summaries)
+	want := []types.Participant{
+		{Email: "alice@example.com", Name: "Alice", Count: 3, From: 2, To: 1},
+		{Email: "bob@example.com", Name: "Bob", Count: 3, From: 1, To: 3},
+		{Email: "carol@example.com", Count: 1, CC: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("aggregateParticipants():\ngot  %+v\nwant %+v", got, want)
+	}
+}
+
+func TestSelectAddresses(t *testing.T) {
+	participants := []types.Participant{
+		{Email: "alice@example.com", Name: "Alice", Count: 3, From: 2, To: 1},
+		{Email: "bob@example.com", Name: "Bob", Count: 3, From: 1, To: 3},
+		{Email: "carol@example.com", Count: 1, CC: 1},
+	}
+
+	tests := []struct {
+		field string
+		want  []types.AddressCount
+	}{
+		{"all", []types.AddressCount{
+			{Email: "alice@example.com", Name: "Alice", Count: 3},
+			{Email: "bob@example.com", Name: "Bob", Count: 3},
+			{Email: "carol@example.com", Count: 1},
+		}},
+		{"from", []types.AddressCount{
+			{Email: "alice@example.com", Name: "Alice", Count: 2},
+			{Email: "bob@example.com", Name: "Bob", Count: 1},
+		}},
+		{"to", []types.AddressCount{
+			{Email: "bob@example.com", Name: "Bob", Count: 3},
+			{Email: "alice@example.com", Name: "Alice", Count: 1},
+		}},
+		{"cc", []types.AddressCount{
+			{Email: "carol@example.com", Count: 1},
+		}},
+	}
+	for _, tt := range tests {
+		if got := selectAddresses(participants, tt.field); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("selectAddresses(%q):\ngot  %+v\nwant %+v", tt.field, got, tt.want)
+		}
+	}
+}
+
+func TestSearchEmails_WindowsLimitsAboveMaxObjectsInGet(t *testing.T) {
+	const matching = 450
+	var windows [][2]int64
+	var anchors []jmap.ID
+	c := stalwartLikeClient()
+	c.accountID = "test-account"
+	c.doFunc = func(req *jmap.Request) (*jmap.Response, error) {
+		query := req.Calls[0].Args.(*email.Query)
+		windows = append(windows, [2]int64{query.Position, int64(query.Limit)})
+		anchors = append(anchors, query.Anchor)
+		start := query.Position
+		if query.Anchor != "" {
+			fmt.Sscanf(string(query.Anchor), "M%d", &start)
+			start += query.AnchorOffset
+		}
+		var ids []jmap.ID
+		var list []*email.Email
+		for i := start; i < min(start+int64(query.Limit), matching); i++ {
+			id := jmap.ID(fmt.Sprintf("M%d", i))
+			ids = append(ids, id)
+			list = append(list, &email.Email{ID: id, Preview: "Hello"})
+		}
+		return &jmap.Response{Responses: []*jmap.Invocation{
+			{Name: "Email/query", CallID: "0", Args: &email.QueryResponse{Total: matching, IDs: ids}},
+			{Name: "Email/get", CallID: "1", Args: &email.GetResponse{List: list}},
+		}}, nil
+	}
+
+	result, err := c.SearchEmails(SearchOptions{Offset: 10, Limit: 1000})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := [][2]int64{{10, 200}, {210, 200}, {410, 200}}
+	if !reflect.DeepEqual(windows, want) {
+		t.Errorf("expected windows (position, limit) %v, got %v", want, windows)
+	}
+	if wantAnchors := []jmap.ID{"", "M209", "M409"}; !reflect.DeepEqual(anchors, wantAnchors) {
+		t.Errorf("expected windows after the first to be anchored on %v, got %v", wantAnchors, anchors)
+	}
+	if result.Total != matching || len(result.Emails) != matching-10 || result.Offset != 10 {
+		t.Fatalf("expected %d of %d emails from offset 10, got %d of %d", matching-10, matching, len(result.Emails), result.Total)
+	}
+	if result.Emails[0].ID != "M10" || result.Emails[len(result.Emails)-1].ID != "M449" {
+		t.Errorf("expected emails M10 to M449 in order, got %s to %s", result.Emails[0].ID, result.Emails[len(result.Emails)-1].ID)
+	}
+}
+
+func TestSearchEmails_WindowAnchorNotFoundFallsBackToPosition(t *testing.T) {
+	const matching = 300
+	var queries []email.Query
+	c := stalwartLikeClient()
+	c.accountID = "test-account"
+	c.doFunc = func(req *jmap.Request) (*jmap.Response, error) {
+		query := req.Calls[0].Args.(*email.Query)
+		queries = append(queries, *query)
+		if query.Anchor != "" {
+			return &jmap.Response{Responses: []*jmap.Invocation{
+				{Name: "Email/query", CallID: "0", Args: &jmap.MethodError{Type: "anchorNotFound"}},
+				{Name: "error", CallID: "1", Args: &jmap.MethodError{Type: "invalidResultReference"}},
+			}}, nil
+		}
+		var ids []jmap.ID
+		var list []*email.Email
+		for i := query.Position; i < min(query.Position+int64(query.Limit), matching); i++ {
+			id := jmap.ID(fmt.Sprintf("M%d", i))
+			ids = append(ids, id)
+			list = append(list, &email.Email{ID: id, Preview: "Hello"})
+		}
+		return &jmap.Response{Responses: []*jmap.Invocation{
+			{Name: "Email/query", CallID: "0", Args: &email.QueryResponse{Total: matching, IDs: ids}},
+			{Name: "Email/get", CallID: "1", Args: &email.GetResponse{List: list}},
+		}}, nil
+	}
+
+	result, err := c.SearchEmails(SearchOptions{Limit: 1000})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(queries) != 3 || queries[1].Anchor != "M199" || queries[2].Anchor != "" || queries[2].Position != 200 {
+		t.Fatalf("expected a position-based retry after anchorNotFound, got %+v", queries)
+	}
+	if len(result.Emails) != matching || result.Emails[matching-1].ID != "M299" {
+		t.Errorf("expected all %d emails once, got %d", matching, len(result.Emails))
+	}
+}
+
+func TestListEmails_SingleRequestWithinMaxObjectsInGet(t *testing.T) {
+	var requests int
+	c := stalwartLikeClient()
+	c.accountID = "test-account"
+	c.mailboxCache = []*mailbox.Mailbox{{ID: "mb-inbox", Name: "Inbox", Role: mailbox.RoleInbox}}
+	c.doFunc = func(req *jmap.Request) (*jmap.Response, error) {
+		requests++
+		if limit := req.Calls[0].Args.(*email.Query).Limit; limit != 200 {
+			t.Errorf("expected limit 200, got %d", limit)
+		}
+		return &jmap.Response{Responses: []*jmap.Invocation{
+			{Name: "Email/query", CallID: "0", Args: &email.QueryResponse{Total: 0, IDs: []jmap.ID{}}},
+			{Name: "Email/get", CallID: "1", Args: &email.GetResponse{List: []*email.Email{}}},
+		}}, nil
+	}
+
+	result, err := c.ListEmails(ListOptions{MailboxNameOrID: "inbox", Limit: 200})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requests != 1 || result.Emails == nil {
+		t.Errorf("expected one request and an empty list, got %d requests and %#v", requests, result.Emails)
+	}
+}
@@ -0,0 +1,335 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+
+	"github.com/cboone/fm/internal/mimeword"
+	"github.com/cboone/fm/internal/types"
+)
+
+// ExportTagsOptions selects the emails covered by ExportTags. An empty
+// MailboxID covers the whole account.
+type ExportTagsOptions struct {
+	MailboxID string
+}
+
+var exportTagsProperties = []string{"id", "messageId", "keywords", "mailboxIds"}
+
+// ExportTags returns the Message-ID, keywords, and mailboxes of every matching
+// email, for syncing local indexers with server state. Mailboxes with a role
+// are reported by role; others by name. Emails without a Message-ID are
+// skipped since local tools cannot match them.
+func (c *Client) ExportTags(opts ExportTagsOptions) ([]types.EmailTags, error) {
+	mailboxes, err := c.GetAllMailboxes()
+	if err != nil {
+		return nil, err
+	}
+	labels := make(map[jmap.ID]string, len(mailboxes))
+	for _, mb := range mailboxes {
+		if mb.Role != "" {
+			labels[mb.ID] = string(mb.Role)
+		} else {
+			labels[mb.ID] = mb.Name
+		}
+	}
+
+	var filter email.Filter
+	if opts.MailboxID != "" {
+		filter = &email.FilterCondition{InMailbox: jmap.ID(opts.MailboxID)}
+	}
+
+	var result []types.EmailTags
+	_, err = c.scanQuery(filter, []*email.SortComparator{{Property: "receivedAt", IsAscending: true}}, exportTagsProperties, func(_ []jmap.ID, emails []*email.Email) {
+		for _, e := range emails {
+			if len(e.MessageID) == 0 {
+				continue
+			}
+			tags := types.EmailTags{
+				ID:        string(e.ID),
+				MessageID: strings.Trim(e.MessageID[0], "<>"),
+				Keywords:  []string{},
+				Mailboxes: []string{},
+			}
+			for kw, set := range e.Keywords {
+				if set {
+					tags.Keywords = append(tags.Keywords, kw)
+				}
+			}
+			for id, in := range e.MailboxIDs {
+				if label, ok := labels[id]; ok && in {
+					tags.Mailboxes = append(tags.Mailboxes, label)
+				}
+			}
+			sort.Strings(tags.Keywords)
+			sort.Strings(tags.Mailboxes)
+			result = append(result, tags)
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("export tags: %w", err)
+	}
+
+	return result, nil
+}
+
+// ExportEmail identifies an email's raw message for mbox export.
+type ExportEmail struct {
+	ID         string
+	BlobID     string
+	From       string
+	ReceivedAt time.Time
+}
+
+var exportMboxProperties = []string{"id", "blobId", "from", "receivedAt", "mailboxIds"}
+
+// ListExportEmails returns every email in the mailbox (or the whole account
+// when mailboxID is empty), oldest first, along with the Email state observed
+// at the start of the listing for later ExportEmailChanges calls.
+func (c *Client) ListExportEmails(mailboxID string) ([]ExportEmail, string, error) {
+	var filter email.Filter
+	if mailboxID != "" {
+		filter = &email.FilterCondition{InMailbox: jmap.ID(mailboxID)}
+	}
+
+	var result []ExportEmail
+	scan, err := c.scanQuery(filter, []*email.SortComparator{{Property: "receivedAt", IsAscending: true}}, exportMboxProperties, func(_ []jmap.ID, emails []*email.Email) {
+		for _, e := range emails {
+			result = append(result, convertExportEmail(e))
+		}
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("export query: %w", err)
+	}
+
+	return result, scan.state, nil
+}
+
+// ExportEmailChanges returns emails created since sinceState that are in the
+// mailbox (or anywhere when mailboxID is empty), oldest first, and the new
+// Email state. With a mailbox and exported, the IDs already in the archive,
+// updated emails now in the mailbox are returned too, since moving an email
+// in only updates it. Emails in exported are never returned again, and
+// destroyed emails are ignored since an mbox archive is append-only.
+func (c *Client) ExportEmailChanges(sinceState, mailboxID string, exported map[string]bool) ([]ExportEmail, string, error) {
+	var changed []string
+	state := sinceState
+
+	for {
+		req := &jmap.Request{}
+		req.Invoke(&email.Changes{
+			Account:    c.accountID,
+			SinceState: state,
+		})
+
+		resp, err := c.Do(req)
+		if err != nil {
+			return nil, "", fmt.Errorf("export changes: %w", err)
+		}
+
+		more := false
+		for _, inv := range resp.Responses {
+			switch r := inv.Args.(type) {
+			case *email.ChangesResponse:
+				changed = append(changed, idStrings(r.Created)...)
+				if mailboxID != "" && exported != nil {
+					changed = append(changed, idStrings(r.Updated)...)
+				}
+				state = r.NewState
+				more = r.HasMoreChanges
+			case *jmap.MethodError:
+				return nil, "", fmt.Errorf("export changes: %s", r.Error())
+			}
+		}
+		if !more {
+			break
+		}
+	}
+
+	var candidates []jmap.ID
+	for _, id := range dedup(changed) {
+		if !exported[id] {
+			candidates = append(candidates, jmap.ID(id))
+		}
+	}
+
+	var result []ExportEmail
+	batchSize := c.maxGetSize()
+	for start := 0; start < len(candidates); start += batchSize {
+		end := min(start+batchSize, len(candidates))
+
+		req := &jmap.Request{}
+		req.Invoke(&email.Get{
+			Account:    c.accountID,
+			IDs:        candidates[start:end],
+			Properties: exportMboxProperties,
+		})
+
+		resp, err := c.Do(req)
+		if err != nil {
+			return nil, "", fmt.Errorf("export changes: %w", err)
+		}
+
+		for _, inv := range resp.Responses {
+			switch r := inv.Args.(type) {
+			case *email.GetResponse:
+				for _, e := range r.List {
+					if mailboxID != "" && !e.MailboxIDs[jmap.ID(mailboxID)] {
+						continue
+					}
+					result = append(result, convertExportEmail(e))
+				}
+			case *jmap.MethodError:
+				return nil, "", fmt.Errorf("export changes: %s", r.Error())
+			}
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].ReceivedAt.Before(result[j].ReceivedAt)
+	})
+	return result, state, nil
+}
+
+// OpenBlob downloads a blob from the current account.
+func (c *Client) OpenBlob(blobID string) (io.ReadCloser, error) {
+	return c.Download(c.accountID, jmap.ID(blobID))
+}
+
+func convertExportEmail(e *email.Email) ExportEmail {
+	out := ExportEmail{
+		ID:         string(e.ID),
+		BlobID:     string(e.BlobID),
+		ReceivedAt: safeTime(e.ReceivedAt),
+	}
+	if len(e.From) > 0 {
+		out.From = e.From[0].Email
+	}
+	return out
+}
+
+// ExportMessage is the per-email data written by fm export sqlite. Body is
+// nil unless bodies were requested.
+type ExportMessage struct {
+	ID         string
+	ThreadID   string
+	MessageID  string
+	Subject    string
+	From       []types.Address
+	To         []types.Address
+	CC         []types.Address
+	BCC        []types.Address
+	SentAt     *time.Time
+	ReceivedAt time.Time
+	Size       uint64
+	Keywords   []string
+	MailboxIDs []string
+	Preview    string
+	Body       *string
+}
+
+var exportMessageProperties = []string{
+	"id", "threadId", "messageId", "subject", "from", "to", "cc", "bcc",
+	"sentAt", "receivedAt", "size", "keywords", "mailboxIds", "preview",
+}
+
+// GetExportMessages fetches export data for ids in batches. With withBodies,
+// the text body (or HTML when there is no text part) is included.
+func (c *Client) GetExportMessages(ids []string, withBodies bool) ([]ExportMessage, error) {
+	props := exportMessageProperties
+	if withBodies {
+		props = append(slices.Clone(props), "bodyValues", "textBody", "htmlBody")
+	}
+
+	var result []ExportMessage
+	size := c.maxGetSize()
+	for start := 0; start < len(ids); start += size {
+		end := min(start+size, len(ids))
+
+		jmapIDs := make([]jmap.ID, end-start)
+		for i, id := range ids[start:end] {
+			jmapIDs[i] = jmap.ID(id)
+		}
+
+		get := &email.Get{
+			Account:    c.accountID,
+			IDs:        jmapIDs,
+			Properties: props,
+		}
+		if withBodies {
+			get.FetchTextBodyValues = true
+			get.FetchHTMLBodyValues = true
+		}
+		req := &jmap.Request{}
+		req.Invoke(get)
+
+		resp, err := c.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("email/get: %w", err)
+		}
+
+		for _, inv := range resp.Responses {
+			switch r := inv.Args.(type) {
+			case *email.GetResponse:
+				if !withBodies {
+					c.fillMissingPreviews(r.List)
+				}
+				for _, e := range r.List {
+					result = append(result, convertExportMessage(e, withBodies))
+				}
+			case *jmap.MethodError:
+				return nil, fmt.Errorf("email/get: %s", r.Error())
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func convertExportMessage(e *email.Email, withBody bool) ExportMessage {
+	m := ExportMessage{
+		ID:         string(e.ID),
+		ThreadID:   string(e.ThreadID),
+		Subject:    mimeword.Decode(e.Subject),
+		From:       convertAddresses(e.From),
+		To:         convertAddresses(e.To),
+		CC:         convertAddresses(e.CC),
+		BCC:        convertAddresses(e.BCC),
+		SentAt:     e.SentAt,
+		ReceivedAt: safeTime(e.ReceivedAt),
+		Size:       e.Size,
+		Keywords:   []string{},
+		MailboxIDs: []string{},
+		Preview:    e.Preview,
+	}
+	if len(e.MessageID) > 0 {
+		m.MessageID = strings.Trim(e.MessageID[0], "<>")
+	}
+	for kw, set := range e.Keywords {
+		if set {
+			m.Keywords = append(m.Keywords, kw)
+		}
+	}
+	for id, in := range e.MailboxIDs {
+		if in {
+			m.MailboxIDs = append(m.MailboxIDs, string(id))
+		}
+	}
+	sort.Strings(m.Keywords)
+	sort.Strings(m.MailboxIDs)
+	if withBody {
+		body := extractBody(e, false)
+		m.Body = &body
+		if m.Preview == "" {
+			m.Preview = makePreview(body)
+		}
+	}
+	return m
+}
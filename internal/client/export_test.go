@@ -0,0 +1,199 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+	"git.sr.ht/~rockorager/go-jmap/mail/mailbox"
+)
+
+func TestExportTags(t *testing.T) {
+	var gotFilter email.Filter
+	c := &Client{
+		accountID: "test-account",
+		mailboxCache: []*mailbox.Mailbox{
+			{ID: "mb-inbox", Name: "Inbox", Role: mailbox.RoleInbox},
+			{ID: "mb-receipts", Name: "Receipts"},
+		},
+		doFunc: func(req *jmap.Request) (*jmap.Response, error) {
+			gotFilter = req.Calls[0].Args.(*email.Query).Filter
+			return &jmap.Response{Responses: []*jmap.Invocation{
+				{Name: "Email/query", CallID: "0", Args: &email.QueryResponse{
+					Total: 2,
+					IDs:   []jmap.ID{"M1", "M2"},
+				}},
+				{Name: "Email/get", CallID: "1", Args: &email.GetResponse{
+					List: []*email.Email{
+						{
+							ID:         "M1",
+							MessageID:  []string{"<one@example.com>"},
+							Keywords:   map[string]bool{"$seen": true, "$flagged": true},
+							MailboxIDs: map[jmap.ID]bool{"mb-inbox": true, "mb-receipts": true},
+						},
+						{ID: "M2", MailboxIDs: map[jmap.ID]bool{"mb-inbox": true}},
+					},
+				}},
+			}}, nil
+		},
+	}
+
+	tags, err := c.ExportTags(ExportTagsOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotFilter != nil {
+		t.Errorf("expected no filter for whole-account export, got %+v", gotFilter)
+	}
+	if len(tags) != 1 {
+		t.Fatalf("expected email without Message-ID to be skipped, got %d entries", len(tags))
+	}
+	if tags[0].MessageID != "one@example.com" {
+		t.Errorf("expected bare message ID, got %q", tags[0].MessageID)
+	}
+	if !reflect.DeepEqual(tags[0].Keywords, []string{"$flagged", "$seen"}) {
+		t.Errorf("unexpected keywords %v", tags[0].Keywords)
+	}
+	if !reflect.DeepEqual(tags[0].Mailboxes, []string{"Receipts", "inbox"}) {
+		t.Errorf("unexpected mailboxes %v", tags[0].Mailboxes)
+	}
+}
+
+func TestListExportEmails(t *testing.T) {
+	received := time.Date(2026, 2, 4, 9, 0, 0, 0, time.UTC)
+	c := &Client{
+		accountID: "test-account",
+		doFunc: func(req *jmap.Request) (*jmap.Response, error) {
+			return &jmap.Response{Responses: []*jmap.Invocation{
+				{Name: "Email/query", CallID: "0", Args: &email.QueryResponse{
+					Total: 1,
+					IDs:   []jmap.ID{"M1"},
+				}},
+				{Name: "Email/get", CallID: "1", Args: &email.GetResponse{
+					State: "s10",
+					List: []*email.Email{
+						{ID: "M1", BlobID: "B1", From: []*mail.Address{{Email: "alice@example.com"}}, ReceivedAt: &received},
+					},
+				}},
+			}}, nil
+		},
+	}
+
+	emails, state, err := c.ListExportEmails("mb-archive")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state != "s10" {
+		t.Errorf("expected state s10, got %q", state)
+	}
+	want := []ExportEmail{{ID: "M1", BlobID: "B1", From: "alice@example.com", ReceivedAt: received}}
+	if !reflect.DeepEqual(emails, want) {
+		t.Errorf("expected %+v, got %+v", want, emails)
+	}
+}
+
+func TestExportEmailChanges(t *testing.T) {
+	older := time.Date(2026, 2, 4, 9, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+
+	var sinceStates []string
+	c := &Client{
+		accountID: "test-account",
+		doFunc: func(req *jmap.Request) (*jmap.Response, error) {
+			switch args := req.Calls[0].Args.(type) {
+			case *email.Changes:
+				sinceStates = append(sinceStates, args.SinceState)
+				if args.SinceState == "s10" {
+					return &jmap.Response{Responses: []*jmap.Invocation{
+						{Name: "Email/changes", Args: &email.ChangesResponse{
+							NewState: "s11", HasMoreChanges: true,
+							Created: []jmap.ID{"M2"}, Updated: []jmap.ID{"M1"},
+						}},
+					}}, nil
+				}
+				return &jmap.Response{Responses: []*jmap.Invocation{
+					{Name: "Email/changes", Args: &email.ChangesResponse{
+						NewState: "s12", Created: []jmap.ID{"M3", "M4"},
+					}},
+				}}, nil
+			case *email.Get:
+				if len(args.IDs) != 3 {
+					t.Errorf("expected 3 created IDs to be fetched, got %v", args.IDs)
+				}
+				return &jmap.Response{Responses: []*jmap.Invocation{
+					{Name: "Email/get", Args: &email.GetResponse{List: []*email.Email{
+						{ID: "M2", BlobID: "B2", ReceivedAt: &newer, MailboxIDs: map[jmap.ID]bool{"mb-archive": true}},
+						{ID: "M3", BlobID: "B3", ReceivedAt: &older, MailboxIDs: map[jmap.ID]bool{"mb-archive": true}},
+						{ID: "M4", BlobID: "B4", ReceivedAt: &older, MailboxIDs: map[jmap.ID]bool{"mb-inbox": true}},
+					}}},
+				}}, nil
+			}
+			t.Fatalf("unexpected request %T", req.Calls[0].Args)
+			return nil, nil
+		},
+	}
+
+	emails, state, err := c.ExportEmailChanges("s10", "mb-archive", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(sinceStates, []string{"s10", "s11"}) {
+		t.Errorf("expected changes since s10 then s11, got %v", sinceStates)
+	}
+	if state != "s12" {
+		t.Errorf("expected new state s12, got %q", state)
+	}
+	if len(emails) != 2 || emails[0].ID != "M3" || emails[1].ID != "M2" {
+		t.Errorf("expected archive emails M3, M2 oldest first, got %+v", emails)
+	}
+}
+
+func TestExportEmailChanges_MovedIntoMailbox(t *testing.T) {
+	received := time.Date(2026, 2, 4, 9, 0, 0, 0, time.UTC)
+
+	c := &Client{
+		accountID: "test-account",
+		doFunc: func(req *jmap.Request) (*jmap.Response, error) {
+			switch args := req.Calls[0].Args.(type) {
+			case *email.Changes:
+				return &jmap.Response{Responses: []*jmap.Invocation{
+					{Name: "Email/changes", Args: &email.ChangesResponse{
+						NewState: "s11",
+						Created:  []jmap.ID{"M3"},
+						Updated:  []jmap.ID{"M1", "M2", "M3", "M4"},
+					}},
+				}}, nil
+			case *email.Get:
+				if !reflect.DeepEqual(args.IDs, []jmap.ID{"M3", "M2", "M4"}) {
+					t.Errorf("expected the changes not yet exported, each once, got %v", args.IDs)
+				}
+				return &jmap.Response{Responses: []*jmap.Invocation{
+					{Name: "Email/get", Args: &email.GetResponse{List: []*email.Email{
+						{ID: "M2", BlobID: "B2", ReceivedAt: &received, MailboxIDs: map[jmap.ID]bool{"mb-archive": true}},
+						{ID: "M3", BlobID: "B3", ReceivedAt: &received, MailboxIDs: map[jmap.ID]bool{"mb-archive": true}},
+						{ID: "M4", BlobID: "B4", ReceivedAt: &received, MailboxIDs: map[jmap.ID]bool{"mb-inbox": true}},
+					}}},
+				}}, nil
+			}
+			t.Fatalf("unexpected request %T", req.Calls[0].Args)
+			return nil, nil
+		},
+	}
+
+	// M1 is already in the archive and only had its keywords changed; M2
+	// was moved into the mailbox; M4 was updated elsewhere.
+	emails, _, err := c.ExportEmailChanges("s10", "mb-archive", map[string]bool{"M1": true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var ids []string
+	for _, e := range emails {
+		ids = append(ids, e.ID)
+	}
+	if !reflect.DeepEqual(ids, []string{"M2", "M3"}) {
+		t.Errorf("expected the moved and created emails once each, got %v", ids)
+	}
+}
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"html"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+// markedRanges strips the <mark> tags a server puts around matches in a
+// search snippet and returns the plain text with the offsets of the marked
+// spans, counted in Unicode code points. HTML entities in the snippet are
+// decoded, so the offsets index the text a user would see.
+func markedRanges(s string) (string, []types.MatchRange) {
+	var b strings.Builder
+	var ranges []types.MatchRange
+	pos := 0
+	for {
+		before, rest, found := strings.Cut(s, "<mark>")
+		text := html.UnescapeString(before)
+		b.WriteString(text)
+		pos += utf8.RuneCountInString(text)
+		if !found {
+			break
+		}
+
+		marked, after, _ := strings.Cut(rest, "</mark>")
+		text = html.UnescapeString(marked)
+		b.WriteString(text)
+		start := pos
+		pos += utf8.RuneCountInString(text)
+		ranges = append(ranges, types.MatchRange{Start: start, End: pos})
+		s = after
+	}
+	return b.String(), ranges
+}
+
+// termRanges returns the offsets, in Unicode code points, of every
+// case-insensitive occurrence of terms in s, in order and without overlaps.
+func termRanges(s string, terms []string) []types.MatchRange {
+	lower := []rune(strings.ToLower(s))
+	if len(lower) != utf8.RuneCountInString(s) {
+		// Case folding changed the length, so offsets would not line up.
+		return nil
+	}
+
+	var ranges []types.MatchRange
+	for i := 0; i < len(lower); {
+		end := 0
+		for _, term := range terms {
+			t := []rune(strings.ToLower(term))
+			if len(t) > 0 && i+len(t) <= len(lower) && string(lower[i:i+len(t)]) == string(t) {
+				end = max(end, i+len(t))
+			}
+		}
+		if end == 0 {
+			i++
+			continue
+		}
+		ranges = append(ranges, types.MatchRange{Start: i, End: end})
+		i = end
+	}
+	return ranges
+}
+
+// searchHighlights builds the highlights of one search result. The
+// server's marked subject and snippet are used where it sent them; the
+// subject falls back to matching terms locally.
+func searchHighlights(subject, markedSubject, markedPreview string, terms []string) []types.HighlightField {
+	var fields []types.HighlightField
+	if markedSubject != "" {
+		text, ranges := markedRanges(markedSubject)
+		if len(ranges) > 0 {
+			fields = append(fields, types.HighlightField{Field: "subject", Text: text, Matches: ranges})
+		}
+	} else if ranges := termRanges(subject, terms); len(ranges) > 0 {
+		fields = append(fields, types.HighlightField{Field: "subject", Text: subject, Matches: ranges})
+	}
+	if markedPreview != "" {
+		text, ranges := markedRanges(markedPreview)
+		if len(ranges) > 0 {
+			fields = append(fields, types.HighlightField{Field: "snippet", Text: text, Matches: ranges})
+		}
+	}
+	return fields
+}
@@ -0,0 +1,47 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+func TestMarkedRanges(t *testing.T) {
+	text, ranges := markedRanges("Caf&eacute; <mark>meeting</mark> &amp; <mark>café</mark> notes")
+	if text != "Café meeting & café notes" {
+		t.Errorf("unexpected text %q", text)
+	}
+	want := []types.MatchRange{{Start: 5, End: 12}, {Start: 15, End: 19}}
+	if !reflect.DeepEqual(ranges, want) {
+		t.Errorf("expected %v, got %v", want, ranges)
+	}
+
+	text, ranges = markedRanges("no matches")
+	if text != "no matches" || ranges != nil {
+		t.Errorf("expected plain text and no ranges, got %q %v", text, ranges)
+	}
+}
+
+func TestTermRanges(t *testing.T) {
+	got := termRanges("Budget review: BUDGET for Zürich", []string{"budget", "zürich", "get"})
+	want := []types.MatchRange{{Start: 0, End: 6}, {Start: 15, End: 21}, {Start: 26, End: 32}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	if got := termRanges("Budget", nil); got != nil {
+		t.Errorf("expected no ranges without terms, got %v", got)
+	}
+}
+
+func TestSearchHighlights_FallsBackToTermsForSubject(t *testing.T) {
+	got := searchHighlights("Quarterly report", "", "the <mark>report</mark> is attached", []string{"report"})
+	want := []types.HighlightField{
+		{Field: "subject", Text: "Quarterly report", Matches: []types.MatchRange{{Start: 10, End: 16}}},
+		{Field: "snippet", Text: "the report is attached", Matches: []types.MatchRange{{Start: 4, End: 10}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
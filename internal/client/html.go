@@ -0,0 +1,159 @@
+package client
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+)
+
+// ErrNoHTMLBody indicates that an email has no HTML body part.
+var ErrNoHTMLBody = errors.New("email has no HTML body")
+
+// ReadHTMLBody returns the HTML body of an email as sent, without the
+// plain-text fallback ReadEmail applies. With inlineImages, cid: references
+// to the email's inline images are replaced with data: URIs so the HTML
+// renders on its own.
+func (c *Client) ReadHTMLBody(emailID string, inlineImages bool) (string, error) {
+	req := &jmap.Request{}
+	req.Invoke(&email.Get{
+		Account:             c.accountID,
+		IDs:                 []jmap.ID{jmap.ID(emailID)},
+		Properties:          []string{"id", "htmlBody", "bodyValues", "attachments"},
+		BodyProperties:      []string{"partId", "blobId", "type", "cid"},
+		FetchHTMLBodyValues: true,
+	})
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("email/get: %w", err)
+	}
+
+	for _, inv := range resp.Responses {
+		switch r := inv.Args.(type) {
+		case *email.GetResponse:
+			if len(r.NotFound) > 0 || len(r.List) == 0 {
+				return "", fmt.Errorf("email %s: %w", emailID, ErrNotFound)
+			}
+			e := r.List[0]
+			var b strings.Builder
+			for _, part := range e.HTMLBody {
+				if part.Type != "text/html" {
+					continue
+				}
+				if bv, ok := e.BodyValues[part.PartID]; ok {
+					b.WriteString(bv.Value)
+				}
+			}
+			if b.Len() == 0 {
+				return "", fmt.Errorf("email %s: %w", emailID, ErrNoHTMLBody)
+			}
+			html := b.String()
+			if inlineImages {
+				return c.inlineCIDImages(html, e.Attachments)
+			}
+			return html, nil
+		case *jmap.MethodError:
+			return "", fmt.Errorf("email/get: %s", r.Error())
+		}
+	}
+
+	return "", fmt.Errorf("email/get: unexpected response")
+}
+
+// GetHTMLBodies returns the HTML body of each of ids that has one, keyed by
+// email ID, fetched in batches. Emails that are not found or have no HTML
+// body are left out.
+func (c *Client) GetHTMLBodies(ids []string) (map[string]string, error) {
+	bodies := make(map[string]string, len(ids))
+	size := c.maxGetSize()
+	for start := 0; start < len(ids); start += size {
+		batch := ids[start:min(start+size, len(ids))]
+		jmapIDs := make([]jmap.ID, len(batch))
+		for i, id := range batch {
+			jmapIDs[i] = jmap.ID(id)
+		}
+
+		req := &jmap.Request{}
+		req.Invoke(&email.Get{
+			Account:             c.accountID,
+			IDs:                 jmapIDs,
+			Properties:          []string{"id", "htmlBody", "bodyValues"},
+			BodyProperties:      []string{"partId", "type"},
+			FetchHTMLBodyValues: true,
+			MaxBodyValueBytes:   c.maxBodyBytes,
+		})
+
+		resp, err := c.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("email/get: %w", err)
+		}
+
+		for _, inv := range resp.Responses {
+			switch r := inv.Args.(type) {
+			case *email.GetResponse:
+				for _, e := range r.List {
+					var b strings.Builder
+					for _, part := range e.HTMLBody {
+						if part.Type != "text/html" {
+							continue
+						}
+						if bv, ok := e.BodyValues[part.PartID]; ok {
+							b.WriteString(bv.Value)
+						}
+					}
+					if b.Len() > 0 {
+						bodies[string(e.ID)] = b.String()
+					}
+				}
+			case *jmap.MethodError:
+				return nil, fmt.Errorf("email/get: %s", r.Error())
+			}
+		}
+	}
+	return bodies, nil
+}
+
+// inlineCIDImages replaces cid: references in html with data: URIs built
+// from the matching image parts. Parts the HTML does not reference are not
+// downloaded.
+func (c *Client) inlineCIDImages(html string, parts []*email.BodyPart) (string, error) {
+	for _, part := range inlineImageParts(parts) {
+		ref := "cid:" + contentID(part)
+		if !strings.Contains(html, ref) {
+			continue
+		}
+		body, err := c.Download(c.accountID, part.BlobID)
+		if err != nil {
+			return "", fmt.Errorf("downloading inline image %s: %w", contentID(part), err)
+		}
+		data, err := io.ReadAll(body)
+		_ = body.Close()
+		if err != nil {
+			return "", fmt.Errorf("downloading inline image %s: %w", contentID(part), err)
+		}
+		uri := "data:" + part.Type + ";base64," + base64.StdEncoding.EncodeToString(data)
+		html = strings.ReplaceAll(html, ref, uri)
+	}
+	return html, nil
+}
+
+// inlineImageParts returns the inline images among parts.
+func inlineImageParts(parts []*email.BodyPart) []*email.BodyPart {
+	var images []*email.BodyPart
+	for _, part := range parts {
+		if isInlineImage(part) {
+			images = append(images, part)
+		}
+	}
+	return images
+}
+
+// contentID returns a part's Content-ID without its angle brackets.
+func contentID(part *email.BodyPart) string {
+	return strings.TrimSuffix(strings.TrimPrefix(part.CID, "<"), ">")
+}
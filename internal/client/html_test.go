@@ -0,0 +1,75 @@
+package client
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+)
+
+func htmlTestClient(e *email.Email, downloads *[]jmap.ID) *Client {
+	return &Client{
+		accountID: "test-account",
+		doFunc: func(req *jmap.Request) (*jmap.Response, error) {
+			return &jmap.Response{Responses: []*jmap.Invocation{
+				{Name: "Email/get", CallID: "0", Args: &email.GetResponse{List: []*email.Email{e}}},
+			}}, nil
+		},
+		downloadFunc: func(_ jmap.ID, blobID jmap.ID) (io.ReadCloser, error) {
+			*downloads = append(*downloads, blobID)
+			return io.NopCloser(strings.NewReader("PNG")), nil
+		},
+	}
+}
+
+func TestReadHTMLBody(t *testing.T) {
+	e := &email.Email{
+		ID:         "M1",
+		HTMLBody:   []*email.BodyPart{{PartID: "1", Type: "text/html"}},
+		BodyValues: map[string]*email.BodyValue{"1": {Value: `<p>Gate 12</p><img src="cid:logo@x">`}},
+		Attachments: []*email.BodyPart{
+			{PartID: "2", BlobID: "B-logo", Type: "image/png", CID: "<logo@x>"},
+			{PartID: "3", BlobID: "B-unused", Type: "image/gif", CID: "spacer@x"},
+			{PartID: "4", BlobID: "B-pdf", Type: "application/pdf"},
+		},
+	}
+
+	var downloads []jmap.ID
+	html, err := htmlTestClient(e, &downloads).ReadHTMLBody("M1", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if html != `<p>Gate 12</p><img src="cid:logo@x">` {
+		t.Errorf("expected untouched HTML, got %q", html)
+	}
+	if len(downloads) != 0 {
+		t.Errorf("expected no downloads without inlining, got %v", downloads)
+	}
+
+	html, err = htmlTestClient(e, &downloads).ReadHTMLBody("M1", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if html != `<p>Gate 12</p><img src="data:image/png;base64,UE5H">` {
+		t.Errorf("expected cid reference inlined, got %q", html)
+	}
+	if len(downloads) != 1 || downloads[0] != "B-logo" {
+		t.Errorf("expected only the referenced image downloaded, got %v", downloads)
+	}
+}
+
+func TestReadHTMLBody_NoHTML(t *testing.T) {
+	e := &email.Email{
+		ID:         "M1",
+		HTMLBody:   []*email.BodyPart{{PartID: "1", Type: "text/plain"}},
+		BodyValues: map[string]*email.BodyValue{"1": {Value: "plain only"}},
+	}
+
+	var downloads []jmap.ID
+	if _, err := htmlTestClient(e, &downloads).ReadHTMLBody("M1", false); !errors.Is(err, ErrNoHTMLBody) {
+		t.Errorf("expected ErrNoHTMLBody, got %v", err)
+	}
+}
@@ -0,0 +1,257 @@
+package client
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+
+	"github.com/cboone/fm/internal/ics"
+	"github.com/cboone/fm/internal/types"
+)
+
+// invitesScanLimit bounds how many of a mailbox's most recent emails
+// Invitations looks at.
+const invitesScanLimit = 500
+
+// maxCalendarPartSize is the largest calendar part Invitations downloads;
+// larger ones are skipped.
+const maxCalendarPartSize = 1 << 20
+
+// calendarMessage is a calendar part of an email, parsed.
+type calendarMessage struct {
+	email    EmailAttachments
+	answered bool
+	calendar ics.Calendar
+}
+
+// Invitations returns the calendar invitations among the most recent emails
+// in mailboxID that list one of the account's own addresses as an
+// attendee, with the account's response, soonest first. Of several emails
+// about the same event, the latest version counts, and events cancelled by
+// a later email are left out. An invitation still needing action counts as
+// answered when a calendar reply to it is found in sentID, which may be
+// empty, or when its email is marked $answered.
+func (c *Client) Invitations(mailboxID, sentID jmap.ID) ([]types.Invitation, error) {
+	messages, err := c.calendarMessages(mailboxID)
+	if err != nil {
+		return nil, err
+	}
+	own := c.ownAddresses()
+	isOwn := func(addr string) bool { return isOwnAddress(addr, own) }
+
+	var replies map[string]string
+	if sentID != "" {
+		sent, err := c.calendarMessages(sentID)
+		if err != nil {
+			return nil, err
+		}
+		replies = calendarReplies(sent, isOwn)
+	}
+
+	latest := make(map[string]types.Invitation)
+	sequence := make(map[string]int)
+	cancelled := make(map[string]int)
+	for _, m := range messages {
+		for _, e := range m.calendar.Events {
+			if e.UID == "" {
+				continue
+			}
+			switch m.calendar.Method {
+			case "CANCEL":
+				cancelled[e.UID] = max(cancelled[e.UID], e.Sequence)
+			case "REQUEST":
+				me, ok := e.Attendee(isOwn)
+				if !ok {
+					continue
+				}
+				if prev, ok := latest[e.UID]; ok && (e.Sequence < sequence[e.UID] ||
+					e.Sequence == sequence[e.UID] && !m.email.ReceivedAt.After(prev.ReceivedAt)) {
+					continue
+				}
+				inv := invitation(m, e, me)
+				if inv.Status == "needs-action" {
+					if partStat, ok := replies[e.UID]; ok {
+						inv.Status = strings.ToLower(partStat)
+					} else if m.answered {
+						inv.Status = "replied"
+					}
+				}
+				latest[e.UID] = inv
+				sequence[e.UID] = e.Sequence
+			}
+		}
+	}
+
+	invitations := []types.Invitation{}
+	for uid, inv := range latest {
+		if seq, ok := cancelled[uid]; ok && seq >= sequence[uid] {
+			continue
+		}
+		invitations = append(invitations, inv)
+	}
+	sort.Slice(invitations, func(i, j int) bool {
+		if !invitations[i].Start.Equal(invitations[j].Start) {
+			return invitations[i].Start.Before(invitations[j].Start)
+		}
+		return invitations[i].UID < invitations[j].UID
+	})
+	return invitations, nil
+}
+
+// invitation describes the event e of an invitation m, where me is the
+// account's attendee entry.
+func invitation(m calendarMessage, e ics.Event, me ics.Person) types.Invitation {
+	inv := types.Invitation{
+		EmailID:    m.email.ID,
+		UID:        e.UID,
+		Summary:    e.Summary,
+		Start:      e.Start,
+		AllDay:     e.AllDay,
+		Location:   e.Location,
+		Organizer:  types.Address{Name: e.Organizer.Name, Email: e.Organizer.Email},
+		Status:     strings.ToLower(me.PartStat),
+		ReceivedAt: m.email.ReceivedAt,
+	}
+	if inv.Summary == "" {
+		inv.Summary = m.email.Subject
+	}
+	if !e.End.IsZero() {
+		end := e.End
+		inv.End = &end
+	}
+	return inv
+}
+
+// calendarReplies returns the participation status the account sent for
+// each event UID in the calendar replies among messages, the latest reply
+// winning.
+func calendarReplies(messages []calendarMessage, isOwn func(string) bool) map[string]string {
+	replies := make(map[string]string)
+	at := make(map[string]time.Time)
+	for _, m := range messages {
+		if m.calendar.Method != "REPLY" {
+			continue
+		}
+		for _, e := range m.calendar.Events {
+			me, ok := e.Attendee(isOwn)
+			if !ok || me.PartStat == ics.NeedsAction {
+				continue
+			}
+			if t, ok := at[e.UID]; ok && !m.email.ReceivedAt.After(t) {
+				continue
+			}
+			replies[e.UID] = me.PartStat
+			at[e.UID] = m.email.ReceivedAt
+		}
+	}
+	return replies
+}
+
+// calendarMessages returns the parsed calendar parts of the most recent
+// emails in mailboxID.
+func (c *Client) calendarMessages(mailboxID jmap.ID) ([]calendarMessage, error) {
+	req := &jmap.Request{}
+	req.Invoke(&email.Query{
+		Account: c.accountID,
+		Filter:  &email.FilterCondition{InMailbox: mailboxID},
+		Sort:    []*email.SortComparator{{Property: "receivedAt", IsAscending: false}},
+		Limit:   invitesScanLimit,
+	})
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("email/query: %w", err)
+	}
+
+	var ids []string
+	for _, inv := range resp.Responses {
+		switch r := inv.Args.(type) {
+		case *email.QueryResponse:
+			ids = idStrings(r.IDs)
+		case *jmap.MethodError:
+			return nil, fmt.Errorf("email/query: %s", r.Error())
+		}
+	}
+
+	emails, err := c.GetAttachments(ids)
+	if err != nil {
+		return nil, err
+	}
+	answered, err := c.answeredEmails(emails)
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []calendarMessage
+	for _, e := range emails {
+		for _, a := range e.Attachments {
+			if !isCalendarPart(a) {
+				continue
+			}
+			cal, err := c.downloadCalendar(a)
+			if err != nil {
+				return nil, err
+			}
+			messages = append(messages, calendarMessage{email: e, answered: answered[e.ID], calendar: cal})
+		}
+	}
+	return messages, nil
+}
+
+// answeredEmails returns which of the emails with a calendar part are
+// marked $answered.
+func (c *Client) answeredEmails(emails []EmailAttachments) (map[string]bool, error) {
+	var ids []string
+	for _, e := range emails {
+		for _, a := range e.Attachments {
+			if isCalendarPart(a) {
+				ids = append(ids, e.ID)
+				break
+			}
+		}
+	}
+	answered := make(map[string]bool)
+	if len(ids) == 0 {
+		return answered, nil
+	}
+	states, err := c.GetEmailStates(ids)
+	if err != nil {
+		return nil, err
+	}
+	for id, s := range states {
+		answered[id] = s.Keywords["$answered"]
+	}
+	return answered, nil
+}
+
+// downloadCalendar downloads and parses the calendar part a.
+func (c *Client) downloadCalendar(a Attachment) (ics.Calendar, error) {
+	body, err := c.DownloadAttachment(a)
+	if err != nil {
+		return ics.Calendar{}, err
+	}
+	defer func() { _ = body.Close() }()
+	cal, err := ics.Parse(body)
+	if err != nil {
+		return ics.Calendar{}, fmt.Errorf("reading part %s: %w", a.PartID, err)
+	}
+	return cal, nil
+}
+
+// isCalendarPart reports whether a is iCalendar data small enough to read,
+// by its type or, for a generic type, its file name.
+func isCalendarPart(a Attachment) bool {
+	if a.Size > maxCalendarPartSize {
+		return false
+	}
+	mediaType, _, _ := strings.Cut(a.Type, ";")
+	switch strings.ToLower(strings.TrimSpace(mediaType)) {
+	case "text/calendar", "application/ics":
+		return true
+	}
+	return strings.EqualFold(filepath.Ext(a.Name), ".ics")
+}
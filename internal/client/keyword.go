@@ -0,0 +1,199 @@
+package client
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+// ValidateKeyword checks a keyword against the RFC 8621 section 4.1.1
+// syntax: 1 to 255 printable ASCII characters, excluding the IMAP atom
+// specials ( ) { ] % * " and \.
+func ValidateKeyword(kw string) error {
+	if kw == "" {
+		return fmt.Errorf("keyword must not be empty")
+	}
+	if len(kw) > 255 {
+		return fmt.Errorf("keyword %q is longer than 255 characters", kw)
+	}
+	for _, r := range kw {
+		if r < 0x21 || r > 0x7e {
+			return fmt.Errorf("keyword %q contains %q; only printable ASCII without spaces is allowed", kw, r)
+		}
+		if strings.ContainsRune(`(){]%*"\`, r) {
+			return fmt.Errorf("keyword %q contains the forbidden character %q", kw, r)
+		}
+	}
+	return nil
+}
+
+// SetKeyword sets a keyword on emails. It is the primitive behind mark-read,
+// flag, and mute; callers are expected to have validated kw.
+func (c *Client) SetKeyword(emailIDs []string, kw string) ([]string, []string) {
+	return c.batchSetEmails(emailIDs, func(_ string) jmap.Patch {
+		return jmap.Patch{"keywords/" + kw: true}
+	})
+}
+
+// ClearKeyword removes a keyword from emails.
+func (c *Client) ClearKeyword(emailIDs []string, kw string) ([]string, []string) {
+	return c.batchSetEmails(emailIDs, func(_ string) jmap.Patch {
+		return jmap.Patch{"keywords/" + kw: nil}
+	})
+}
+
+// MigrateKeyword replaces the keyword from with the keyword to on emails,
+// removing one and setting the other in the same update so that no email is
+// left with neither. Callers are expected to have validated both keywords.
+func (c *Client) MigrateKeyword(emailIDs []string, from, to string) ([]string, []string) {
+	return c.batchSetEmails(emailIDs, func(_ string) jmap.Patch {
+		return jmap.Patch{"keywords/" + from: nil, "keywords/" + to: true}
+	})
+}
+
+// CountKeywords scans every email in the mailbox (or the whole account when
+// mailboxID is empty) and returns how many carry each keyword, along with the
+// number of emails scanned.
+func (c *Client) CountKeywords(mailboxID string) (map[string]int, int, error) {
+	var filter email.Filter
+	if mailboxID != "" {
+		filter = &email.FilterCondition{InMailbox: jmap.ID(mailboxID)}
+	}
+
+	counts := make(map[string]int)
+	var scanned int
+	_, err := c.scanQuery(filter, []*email.SortComparator{{Property: "receivedAt", IsAscending: true}}, []string{"id", "keywords"}, func(_ []jmap.ID, emails []*email.Email) {
+		for _, e := range emails {
+			scanned++
+			for kw, set := range e.Keywords {
+				if set {
+					counts[kw]++
+				}
+			}
+		}
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("keyword scan: %w", err)
+	}
+
+	return counts, scanned, nil
+}
+
+// systemKeywords are the keywords RFC 8621 and the IMAP keyword registry
+// define. KeywordSummary counts every other keyword as custom.
+var systemKeywords = map[string]bool{
+	"$seen": true, "$flagged": true, "$answered": true, "$draft": true, "$forwarded": true,
+	"$junk": true, "$notjunk": true, "$phishing": true, "$mdnsent": true,
+}
+
+// KeywordSummary scans every email in the account, or in mailboxID when it
+// is not empty, and counts the flagged, answered, forwarded, and custom
+// keyword emails in each mailbox and overall.
+func (c *Client) KeywordSummary(mailboxID jmap.ID) (types.KeywordSummaryResult, error) {
+	var filter email.Filter
+	if mailboxID != "" {
+		filter = &email.FilterCondition{InMailbox: mailboxID}
+	}
+
+	account := newKeywordTally()
+	byMailbox := make(map[jmap.ID]*keywordTally)
+	var scanned int
+	_, err := c.scanQuery(filter, []*email.SortComparator{{Property: "receivedAt", IsAscending: true}}, []string{"id", "keywords", "mailboxIds"}, func(_ []jmap.ID, emails []*email.Email) {
+		for _, e := range emails {
+			scanned++
+			account.add(e.Keywords)
+			for id, in := range e.MailboxIDs {
+				if !in || mailboxID != "" && id != mailboxID {
+					continue
+				}
+				if byMailbox[id] == nil {
+					byMailbox[id] = newKeywordTally()
+				}
+				byMailbox[id].add(e.Keywords)
+			}
+		}
+	})
+	if err != nil {
+		return types.KeywordSummaryResult{}, fmt.Errorf("keyword scan: %w", err)
+	}
+
+	mailboxes, err := c.GetAllMailboxes()
+	if err != nil {
+		return types.KeywordSummaryResult{}, err
+	}
+	names := make(map[jmap.ID]string, len(mailboxes))
+	for _, mb := range mailboxes {
+		names[mb.ID] = mb.Name
+	}
+
+	result := types.KeywordSummaryResult{
+		Scanned:   scanned,
+		Account:   account.stats(""),
+		Mailboxes: []types.KeywordStats{},
+	}
+	for id, tally := range byMailbox {
+		name := names[id]
+		if name == "" {
+			name = string(id)
+		}
+		result.Mailboxes = append(result.Mailboxes, tally.stats(name))
+	}
+	sort.Slice(result.Mailboxes, func(i, j int) bool {
+		return result.Mailboxes[i].Mailbox < result.Mailboxes[j].Mailbox
+	})
+	return result, nil
+}
+
+// keywordTally accumulates KeywordStats.
+type keywordTally struct {
+	types.KeywordStats
+	custom map[string]int
+}
+
+func newKeywordTally() *keywordTally {
+	return &keywordTally{custom: make(map[string]int)}
+}
+
+// add counts an email with keywords.
+func (t *keywordTally) add(keywords map[string]bool) {
+	t.Total++
+	if keywords["$flagged"] {
+		t.Flagged++
+		if !keywords["$answered"] {
+			t.FlaggedUnanswered++
+		}
+	}
+	if keywords["$answered"] {
+		t.Answered++
+	}
+	if keywords["$forwarded"] {
+		t.Forwarded++
+	}
+	for kw, set := range keywords {
+		if set && !systemKeywords[strings.ToLower(kw)] {
+			t.custom[kw]++
+		}
+	}
+}
+
+// stats returns the counts for the mailbox named name.
+func (t *keywordTally) stats(name string) types.KeywordStats {
+	s := t.KeywordStats
+	s.Mailbox = name
+	s.Keywords = []types.KeywordCount{}
+	for kw, n := range t.custom {
+		s.Keywords = append(s.Keywords, types.KeywordCount{Keyword: kw, Count: n})
+	}
+	sort.Slice(s.Keywords, func(i, j int) bool {
+		if s.Keywords[i].Count != s.Keywords[j].Count {
+			return s.Keywords[i].Count > s.Keywords[j].Count
+		}
+		return s.Keywords[i].Keyword < s.Keywords[j].Keyword
+	})
+	return s
+}
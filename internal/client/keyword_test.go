@@ -0,0 +1,22 @@
+package client
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateKeyword(t *testing.T) {
+	valid := []string{"$seen", "$MailFlagBit0", "project-x", "a"}
+	for _, kw := range valid {
+		if err := ValidateKeyword(kw); err != nil {
+			t.Errorf("ValidateKeyword(%q) = %v, want nil", kw, err)
+		}
+	}
+
+	invalid := []string{"", "two words", "tab\tbed", "paren(", "brace{", "bracket]", "100%", "star*", `quote"`, `back\slash`, "café", strings.Repeat("k", 256)}
+	for _, kw := range invalid {
+		if err := ValidateKeyword(kw); err == nil {
+			t.Errorf("ValidateKeyword(%q) = nil, want error", kw)
+		}
+	}
+}
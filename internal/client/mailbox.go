@@ -1,12 +1,16 @@
 package client
 
 import (
+	"errors"
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
 
 	"git.sr.ht/~rockorager/go-jmap"
 	"git.sr.ht/~rockorager/go-jmap/mail/mailbox"
 
+	"github.com/cboone/fm/internal/textdiff"
 	"github.com/cboone/fm/internal/types"
 )
 
@@ -54,39 +58,146 @@ func (c *Client) GetMailboxByRole(role mailbox.Role) (*mailbox.Mailbox, error) {
 	return nil, fmt.Errorf("no mailbox found with role %q", role)
 }
 
-// GetMailboxByNameOrID finds a mailbox by name (case-insensitive) or by ID.
+// MailboxIDsByRole returns the IDs of the mailboxes that have any of roles.
+// Roles that no mailbox has are skipped.
+func (c *Client) MailboxIDsByRole(roles ...mailbox.Role) ([]string, error) {
+	mailboxes, err := c.GetAllMailboxes()
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, mb := range mailboxes {
+		if slices.Contains(roles, mb.Role) {
+			ids = append(ids, string(mb.ID))
+		}
+	}
+	return ids, nil
+}
+
+// ErrMailboxNotFound indicates that no mailbox has the given name or ID.
+var ErrMailboxNotFound = errors.New("mailbox not found")
+
+// maxMailboxSuggestions caps the names offered when a mailbox is not found.
+const maxMailboxSuggestions = 3
+
+// mailboxNotFoundError reports an unknown mailbox along with the names of
+// mailboxes that were probably meant. It matches ErrMailboxNotFound.
+type mailboxNotFoundError struct {
+	name        string
+	suggestions []string
+}
+
+func (e *mailboxNotFoundError) Error() string {
+	return fmt.Sprintf("mailbox not found: %q", e.name)
+}
+
+func (e *mailboxNotFoundError) Is(target error) bool { return target == ErrMailboxNotFound }
+
+// MailboxSuggestions returns the mailbox names suggested by a not-found
+// error from GetMailboxByNameOrID or ResolveMailboxID, or nil.
+func MailboxSuggestions(err error) []string {
+	var nf *mailboxNotFoundError
+	if errors.As(err, &nf) {
+		return nf.suggestions
+	}
+	return nil
+}
+
+// GetMailboxByNameOrID finds a mailbox by ID or by name. An exact name
+// match wins; otherwise a case-insensitive match is accepted when it is the
+// only one. When nothing matches, the error suggests similar names.
 func (c *Client) GetMailboxByNameOrID(nameOrID string) (*mailbox.Mailbox, error) {
 	mailboxes, err := c.GetAllMailboxes()
 	if err != nil {
 		return nil, err
 	}
-	lower := strings.ToLower(nameOrID)
+	var folded []*mailbox.Mailbox
 	for _, mb := range mailboxes {
-		if string(mb.ID) == nameOrID {
+		if string(mb.ID) == nameOrID || mb.Name == nameOrID {
 			return mb, nil
 		}
-		if strings.ToLower(mb.Name) == lower {
-			return mb, nil
+		if strings.EqualFold(mb.Name, nameOrID) {
+			folded = append(folded, mb)
 		}
 	}
-	return nil, fmt.Errorf("mailbox not found: %q", nameOrID)
+	if len(folded) == 1 {
+		return folded[0], nil
+	}
+	return nil, &mailboxNotFoundError{name: nameOrID, suggestions: suggestMailboxNames(nameOrID, mailboxes)}
 }
 
-// ResolveMailboxID resolves "inbox", other role names, a mailbox name, or a
-// raw mailbox ID to a JMAP mailbox ID.
-func (c *Client) ResolveMailboxID(nameOrID string) (jmap.ID, error) {
-	// Check if it's a well-known role name first.
-	role := mailbox.Role(strings.ToLower(nameOrID))
-	switch role {
-	case mailbox.RoleInbox, mailbox.RoleArchive, mailbox.RoleJunk,
-		mailbox.RoleDrafts, mailbox.RoleSent, mailbox.RoleTrash:
-		mb, err := c.GetMailboxByRole(role)
-		if err == nil {
-			return mb.ID, nil
+// suggestMailboxNames returns up to maxMailboxSuggestions mailbox names
+// close to name: within a third of its length in edits, ignoring case, or
+// containing it. The closest come first.
+func suggestMailboxNames(name string, mailboxes []*mailbox.Mailbox) []string {
+	lower := strings.ToLower(name)
+	maxDist := max(1, len([]rune(lower))/3)
+
+	type candidate struct {
+		name string
+		dist int
+	}
+	var candidates []candidate
+	seen := make(map[string]bool)
+	for _, mb := range mailboxes {
+		if seen[mb.Name] {
+			continue
+		}
+		seen[mb.Name] = true
+		mbLower := strings.ToLower(mb.Name)
+		dist := textdiff.Distance(lower, mbLower)
+		if dist <= maxDist || (len(lower) >= 3 && strings.Contains(mbLower, lower)) {
+			candidates = append(candidates, candidate{mb.Name, dist})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].dist != candidates[j].dist {
+			return candidates[i].dist < candidates[j].dist
 		}
+		return candidates[i].name < candidates[j].name
+	})
+
+	var names []string
+	for _, cand := range candidates[:min(len(candidates), maxMailboxSuggestions)] {
+		names = append(names, cand.name)
 	}
+	return names
+}
 
-	mb, err := c.GetMailboxByNameOrID(nameOrID)
+// roleAliases maps the names accepted for well-known mailbox roles, in
+// lower case, to the role. They work whatever the mailbox is called, so a
+// localized or renamed Inbox is still "inbox".
+var roleAliases = map[string]mailbox.Role{
+	"inbox":     mailbox.RoleInbox,
+	"archive":   mailbox.RoleArchive,
+	"drafts":    mailbox.RoleDrafts,
+	"sent":      mailbox.RoleSent,
+	"junk":      mailbox.RoleJunk,
+	"spam":      mailbox.RoleJunk,
+	"trash":     mailbox.RoleTrash,
+	"all":       mailbox.RoleAll,
+	"flagged":   mailbox.RoleFlagged,
+	"important": mailbox.RoleImportant,
+	"snoozed":   RoleSnoozed,
+}
+
+// ResolveMailbox resolves a role alias such as "inbox" or "spam", a mailbox
+// ID, or a mailbox name to a mailbox. A role alias wins over a mailbox of
+// the same name; when no mailbox has the role, the alias is looked up as a
+// name.
+func (c *Client) ResolveMailbox(nameOrID string) (*mailbox.Mailbox, error) {
+	if role, ok := roleAliases[strings.ToLower(nameOrID)]; ok {
+		if mb, err := c.GetMailboxByRole(role); err == nil {
+			return mb, nil
+		}
+	}
+	return c.GetMailboxByNameOrID(nameOrID)
+}
+
+// ResolveMailboxID resolves a role alias, a mailbox name, or a raw mailbox
+// ID to a JMAP mailbox ID, as ResolveMailbox does.
+func (c *Client) ResolveMailboxID(nameOrID string) (jmap.ID, error) {
+	mb, err := c.ResolveMailbox(nameOrID)
 	if err != nil {
 		return "", err
 	}
@@ -116,3 +227,231 @@ func (c *Client) ListMailboxes(rolesOnly bool) ([]types.MailboxInfo, error) {
 	}
 	return result, nil
 }
+
+// ResolveMailboxPath walks path, a "/"-separated list of mailbox names from
+// the top level, matching each name case-insensitively among the children
+// of the one before. It returns the deepest mailbox found (nil when not even
+// the first name exists) and the names below it that do not exist.
+func (c *Client) ResolveMailboxPath(path string) (*mailbox.Mailbox, []string, error) {
+	names := strings.Split(path, "/")
+	for _, name := range names {
+		if strings.TrimSpace(name) == "" {
+			return nil, nil, fmt.Errorf("invalid mailbox path %q: empty name", path)
+		}
+	}
+
+	mailboxes, err := c.GetAllMailboxes()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var found *mailbox.Mailbox
+	for i, name := range names {
+		var parentID jmap.ID
+		if found != nil {
+			parentID = found.ID
+		}
+		var next *mailbox.Mailbox
+		for _, mb := range mailboxes {
+			if mb.ParentID == parentID && strings.EqualFold(mb.Name, name) {
+				next = mb
+				break
+			}
+		}
+		if next == nil {
+			return found, names[i:], nil
+		}
+		found = next
+	}
+	return found, nil, nil
+}
+
+// CreateMailboxPath returns the mailbox at path, creating it and any
+// missing parents first. It also returns the paths of the mailboxes it
+// created, outermost first. Nothing is created under a trash mailbox.
+func (c *Client) CreateMailboxPath(path string) (*mailbox.Mailbox, []string, error) {
+	parent, missing, err := c.ResolveMailboxPath(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if parent != nil && len(missing) > 0 {
+		if err := ValidateTargetMailbox(parent); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	names := strings.Split(path, "/")
+	depth := len(names) - len(missing)
+	created := []string{}
+	for i, name := range missing {
+		mb := &mailbox.Mailbox{Name: name}
+		if parent != nil {
+			mb.ParentID = parent.ID
+		}
+		if parent, err = c.createMailbox(mb); err != nil {
+			return nil, created, err
+		}
+		created = append(created, strings.Join(names[:depth+i+1], "/"))
+	}
+	return parent, created, nil
+}
+
+// createMailbox creates mb and adds it to the mailbox cache.
+func (c *Client) createMailbox(mb *mailbox.Mailbox) (*mailbox.Mailbox, error) {
+	createID := jmap.ID("mailbox-0")
+	req := &jmap.Request{}
+	req.Invoke(&mailbox.Set{
+		Account: c.accountID,
+		Create:  map[jmap.ID]*mailbox.Mailbox{createID: mb},
+	})
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("mailbox creation: %w", err)
+	}
+
+	for _, inv := range resp.Responses {
+		switch r := inv.Args.(type) {
+		case *mailbox.SetResponse:
+			if created, ok := r.Created[createID]; ok {
+				result := &mailbox.Mailbox{ID: created.ID, Name: mb.Name, ParentID: mb.ParentID}
+				c.mailboxCache = append(c.mailboxCache, result)
+				return result, nil
+			}
+			if setErr, ok := r.NotCreated[createID]; ok {
+				desc := "unknown error"
+				if setErr.Description != nil {
+					desc = *setErr.Description
+				}
+				return nil, fmt.Errorf("creating mailbox %q failed: %s", mb.Name, desc)
+			}
+		case *jmap.MethodError:
+			return nil, fmt.Errorf("mailbox creation: %s", r.Error())
+		}
+	}
+
+	return nil, fmt.Errorf("mailbox creation: unexpected response")
+}
+
+// MailboxPath returns the "/"-separated names of mb and its parents, from
+// the top level. When the mailboxes cannot be fetched it returns mb's name.
+func (c *Client) MailboxPath(mb *mailbox.Mailbox) string {
+	mailboxes, err := c.GetAllMailboxes()
+	if err != nil {
+		return mb.Name
+	}
+	byID := make(map[jmap.ID]*mailbox.Mailbox, len(mailboxes))
+	for _, m := range mailboxes {
+		byID[m.ID] = m
+	}
+
+	names := []string{mb.Name}
+	seen := map[jmap.ID]bool{mb.ID: true}
+	for id := mb.ParentID; id != "" && !seen[id]; {
+		parent, ok := byID[id]
+		if !ok {
+			break
+		}
+		names = append([]string{parent.Name}, names...)
+		seen[id] = true
+		id = parent.ParentID
+	}
+	return strings.Join(names, "/")
+}
+
+// ValidateMailboxRename checks that mb can be renamed to name and placed
+// under parent, or at the top level when parent is nil. Mailboxes with a
+// role, such as the inbox, keep their names; nothing is moved into a trash
+// mailbox or into itself; and the new name must not clash with another
+// mailbox beside it.
+func (c *Client) ValidateMailboxRename(mb *mailbox.Mailbox, name string, parent *mailbox.Mailbox) error {
+	if mb.Role != "" {
+		return &ErrForbidden{
+			Operation: "rename",
+			Reason:    fmt.Sprintf("mailbox %q has role %q; system mailboxes cannot be renamed or moved", mb.Name, mb.Role),
+		}
+	}
+
+	mailboxes, err := c.GetAllMailboxes()
+	if err != nil {
+		return err
+	}
+
+	var parentID jmap.ID
+	if parent != nil {
+		if err := ValidateTargetMailbox(parent); err != nil {
+			return err
+		}
+		parentID = parent.ID
+		byID := make(map[jmap.ID]*mailbox.Mailbox, len(mailboxes))
+		for _, m := range mailboxes {
+			byID[m.ID] = m
+		}
+		seen := map[jmap.ID]bool{}
+		for id := parentID; id != "" && byID[id] != nil && !seen[id]; id = byID[id].ParentID {
+			if id == mb.ID {
+				return fmt.Errorf("cannot move mailbox %q inside itself", mb.Name)
+			}
+			seen[id] = true
+		}
+	}
+
+	if name == mb.Name && parentID == mb.ParentID {
+		return fmt.Errorf("mailbox %q already has that name and parent", mb.Name)
+	}
+	for _, m := range mailboxes {
+		if m.ID != mb.ID && m.ParentID == parentID && strings.EqualFold(m.Name, name) {
+			return fmt.Errorf("a mailbox named %q already exists there", m.Name)
+		}
+	}
+	return nil
+}
+
+// RenameMailbox renames mb to name and places it under parent, or at the
+// top level when parent is nil, after checking ValidateMailboxRename. The
+// mailbox cache is updated to match.
+func (c *Client) RenameMailbox(mb *mailbox.Mailbox, name string, parent *mailbox.Mailbox) error {
+	if err := c.ValidateMailboxRename(mb, name, parent); err != nil {
+		return err
+	}
+
+	var parentID jmap.ID
+	patch := jmap.Patch{"name": name, "parentId": nil}
+	if parent != nil {
+		parentID = parent.ID
+		patch["parentId"] = parentID
+	}
+
+	req := &jmap.Request{}
+	req.Invoke(&mailbox.Set{
+		Account: c.accountID,
+		Update:  map[jmap.ID]jmap.Patch{mb.ID: patch},
+	})
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return fmt.Errorf("mailbox rename: %w", err)
+	}
+
+	for _, inv := range resp.Responses {
+		switch r := inv.Args.(type) {
+		case *mailbox.SetResponse:
+			if setErr, ok := r.NotUpdated[mb.ID]; ok {
+				desc := "unknown error"
+				if setErr.Description != nil {
+					desc = *setErr.Description
+				}
+				return fmt.Errorf("renaming mailbox %q failed: %s", mb.Name, desc)
+			}
+			if _, ok := r.Updated[mb.ID]; ok {
+				mb.Name = name
+				mb.ParentID = parentID
+				return nil
+			}
+		case *jmap.MethodError:
+			return fmt.Errorf("mailbox rename: %s", r.Error())
+		}
+	}
+
+	return fmt.Errorf("mailbox rename: unexpected response")
+}
@@ -0,0 +1,59 @@
+package client
+
+import (
+	"fmt"
+	"sort"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+// MailboxSizes scans every email in the account, fetching only its size
+// and mailboxes, and returns how many emails each mailbox holds and their
+// total size, largest mailbox first. Mailboxes without emails are listed
+// last.
+func (c *Client) MailboxSizes() (types.MailboxStatsResult, error) {
+	mailboxes, err := c.GetAllMailboxes()
+	if err != nil {
+		return types.MailboxStatsResult{}, err
+	}
+	sizes := make(map[jmap.ID]*types.MailboxSize, len(mailboxes))
+	result := types.MailboxStatsResult{Mailboxes: make([]types.MailboxSize, 0, len(mailboxes))}
+	for _, mb := range mailboxes {
+		sizes[mb.ID] = &types.MailboxSize{ID: string(mb.ID), Name: mb.Name, Role: string(mb.Role)}
+	}
+
+	_, err = c.scanQuery(nil, []*email.SortComparator{{Property: "receivedAt", IsAscending: true}}, []string{"id", "size", "mailboxIds"}, func(_ []jmap.ID, emails []*email.Email) {
+		for _, e := range emails {
+			result.Scanned++
+			result.TotalSize += e.Size
+			for id, in := range e.MailboxIDs {
+				if !in {
+					continue
+				}
+				if sizes[id] == nil {
+					sizes[id] = &types.MailboxSize{ID: string(id), Name: string(id)}
+				}
+				sizes[id].Emails++
+				sizes[id].Size += e.Size
+			}
+		}
+	})
+	if err != nil {
+		return types.MailboxStatsResult{}, fmt.Errorf("size scan: %w", err)
+	}
+
+	for _, s := range sizes {
+		result.Mailboxes = append(result.Mailboxes, *s)
+	}
+	sort.Slice(result.Mailboxes, func(i, j int) bool {
+		a, b := result.Mailboxes[i], result.Mailboxes[j]
+		if a.Size != b.Size {
+			return a.Size > b.Size
+		}
+		return a.Name < b.Name
+	})
+	return result, nil
+}
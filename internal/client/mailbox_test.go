@@ -0,0 +1,143 @@
+package client
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/mailbox"
+)
+
+func TestResolveMailboxPath(t *testing.T) {
+	c := &Client{mailboxCache: []*mailbox.Mailbox{
+		{ID: "mb-clients", Name: "Clients"},
+		{ID: "mb-acme", Name: "Acme 2025", ParentID: "mb-clients"},
+		{ID: "mb-other-acme", Name: "Acme 2025"},
+	}}
+
+	mb, missing, err := c.ResolveMailboxPath("clients/acme 2025")
+	if err != nil || mb == nil || mb.ID != "mb-acme" || len(missing) != 0 {
+		t.Fatalf("expected mb-acme with nothing missing, got %v, %v, %v", mb, missing, err)
+	}
+
+	mb, missing, err = c.ResolveMailboxPath("Clients/Globex/Invoices")
+	if err != nil || mb == nil || mb.ID != "mb-clients" || !reflect.DeepEqual(missing, []string{"Globex", "Invoices"}) {
+		t.Fatalf("expected Clients with Globex/Invoices missing, got %v, %v, %v", mb, missing, err)
+	}
+
+	mb, missing, err = c.ResolveMailboxPath("Projects")
+	if err != nil || mb != nil || !reflect.DeepEqual(missing, []string{"Projects"}) {
+		t.Fatalf("expected nothing found, got %v, %v, %v", mb, missing, err)
+	}
+
+	if _, _, err := c.ResolveMailboxPath("Clients//Acme"); err == nil {
+		t.Fatal("expected an error for an empty path segment")
+	}
+}
+
+func TestGetMailboxByNameOrID_CaseAndSuggestions(t *testing.T) {
+	c := &Client{mailboxCache: []*mailbox.Mailbox{
+		{ID: "mb-receipts", Name: "Receipts"},
+		{ID: "mb-recipes", Name: "Recipes"},
+		{ID: "mb-news", Name: "News"},
+		{ID: "mb-news-lower", Name: "news"},
+	}}
+
+	if mb, err := c.GetMailboxByNameOrID("receipts"); err != nil || mb.ID != "mb-receipts" {
+		t.Errorf("expected a unique case-insensitive match to be accepted, got %v, %v", mb, err)
+	}
+	if mb, err := c.GetMailboxByNameOrID("news"); err != nil || mb.ID != "mb-news-lower" {
+		t.Errorf("expected the exact name to win, got %v, %v", mb, err)
+	}
+
+	_, err := c.GetMailboxByNameOrID("NEWS")
+	if !errors.Is(err, ErrMailboxNotFound) {
+		t.Fatalf("expected an ambiguous case-insensitive name to be not found, got %v", err)
+	}
+	if got := MailboxSuggestions(err); !reflect.DeepEqual(got, []string{"News", "news"}) {
+		t.Errorf("expected both spellings to be suggested, got %v", got)
+	}
+
+	_, err = c.GetMailboxByNameOrID("Recipts")
+	if got := MailboxSuggestions(err); !reflect.DeepEqual(got, []string{"Receipts", "Recipes"}) {
+		t.Errorf("expected Receipts and Recipes to be suggested, got %v", got)
+	}
+
+	_, err = c.GetMailboxByNameOrID("Invoices")
+	if got := MailboxSuggestions(err); got != nil {
+		t.Errorf("expected no suggestions, got %v", got)
+	}
+}
+
+func TestResolveMailbox_RoleAliases(t *testing.T) {
+	c := &Client{mailboxCache: []*mailbox.Mailbox{
+		{ID: "mb-inbox", Name: "Posteingang", Role: mailbox.RoleInbox},
+		{ID: "mb-junk", Name: "Unerwünscht", Role: mailbox.RoleJunk},
+		{ID: "mb-inbox-folder", Name: "Inbox"},
+		{ID: "mb-sent-folder", Name: "Sent"},
+	}}
+
+	for alias, want := range map[string]string{
+		"inbox":       "mb-inbox",
+		"INBOX":       "mb-inbox",
+		"spam":        "mb-junk",
+		"junk":        "mb-junk",
+		"sent":        "mb-sent-folder",
+		"Posteingang": "mb-inbox",
+	} {
+		mb, err := c.ResolveMailbox(alias)
+		if err != nil || mb.ID != jmap.ID(want) {
+			t.Errorf("ResolveMailbox(%q) = %v, %v; want %s", alias, mb, err, want)
+		}
+	}
+}
+
+func TestMailboxPath(t *testing.T) {
+	c := &Client{mailboxCache: []*mailbox.Mailbox{
+		{ID: "mb-clients", Name: "Clients"},
+		{ID: "mb-acme", Name: "Acme 2025", ParentID: "mb-clients"},
+		{ID: "mb-invoices", Name: "Invoices", ParentID: "mb-acme"},
+	}}
+	if got := c.MailboxPath(c.mailboxCache[2]); got != "Clients/Acme 2025/Invoices" {
+		t.Errorf("MailboxPath = %q", got)
+	}
+	if got := c.MailboxPath(c.mailboxCache[0]); got != "Clients" {
+		t.Errorf("MailboxPath = %q", got)
+	}
+}
+
+func TestValidateMailboxRename(t *testing.T) {
+	c := &Client{mailboxCache: []*mailbox.Mailbox{
+		{ID: "mb-inbox", Name: "Inbox", Role: mailbox.RoleInbox},
+		{ID: "mb-trash", Name: "Trash", Role: mailbox.RoleTrash},
+		{ID: "mb-clients", Name: "Clients"},
+		{ID: "mb-acme", Name: "Acme", ParentID: "mb-clients"},
+		{ID: "mb-invoices", Name: "Invoices", ParentID: "mb-acme"},
+		{ID: "mb-globex", Name: "Globex", ParentID: "mb-clients"},
+	}}
+	inbox, trash, clients, acme, invoices := c.mailboxCache[0], c.mailboxCache[1], c.mailboxCache[2], c.mailboxCache[3], c.mailboxCache[4]
+
+	var forbidden *ErrForbidden
+	if err := c.ValidateMailboxRename(inbox, "Incoming", nil); !errors.As(err, &forbidden) {
+		t.Errorf("expected renaming the inbox to be forbidden, got %v", err)
+	}
+	if err := c.ValidateMailboxRename(acme, "Acme", trash); !errors.As(err, &forbidden) {
+		t.Errorf("expected moving into trash to be forbidden, got %v", err)
+	}
+	if err := c.ValidateMailboxRename(acme, "Acme", invoices); err == nil {
+		t.Error("expected an error moving a mailbox inside its own child")
+	}
+	if err := c.ValidateMailboxRename(acme, "globex", clients); err == nil {
+		t.Error("expected an error for a name clash with a sibling")
+	}
+	if err := c.ValidateMailboxRename(acme, "Acme", clients); err == nil {
+		t.Error("expected an error when nothing changes")
+	}
+	if err := c.ValidateMailboxRename(acme, "Acme Corp", clients); err != nil {
+		t.Errorf("expected a rename to be allowed, got %v", err)
+	}
+	if err := c.ValidateMailboxRename(acme, "Acme", nil); err != nil {
+		t.Errorf("expected a move to the top level to be allowed, got %v", err)
+	}
+}
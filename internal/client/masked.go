@@ -0,0 +1,212 @@
+package client
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"git.sr.ht/~rockorager/go-jmap"
+
+	"github.com/cboone/fm/internal/jmap/maskedemail"
+	"github.com/cboone/fm/internal/types"
+)
+
+// requireMaskedEmail returns an error if the server does not support masked
+// email management.
+func (c *Client) requireMaskedEmail() error {
+	return c.requireCapability("masked email", maskedemail.URI)
+}
+
+// getAllMaskedEmails returns every masked email in the account.
+func (c *Client) getAllMaskedEmails() ([]*maskedemail.MaskedEmail, error) {
+	if err := c.requireMaskedEmail(); err != nil {
+		return nil, err
+	}
+
+	req := &jmap.Request{}
+	req.Invoke(&maskedemail.Get{Account: c.accountID})
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("maskedemail/get: %w", err)
+	}
+
+	for _, inv := range resp.Responses {
+		switch r := inv.Args.(type) {
+		case *maskedemail.GetResponse:
+			return r.List, nil
+		case *jmap.MethodError:
+			return nil, fmt.Errorf("maskedemail/get: %s", r.Error())
+		}
+	}
+
+	return nil, fmt.Errorf("maskedemail/get: unexpected response")
+}
+
+// FindMaskedEmails returns the masked emails whose forDomain is domain or one
+// of its subdomains. Deleted masked emails are skipped.
+func (c *Client) FindMaskedEmails(domain string) (types.MaskedEmailListResult, error) {
+	all, err := c.getAllMaskedEmails()
+	if err != nil {
+		return types.MaskedEmailListResult{}, err
+	}
+
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	result := types.MaskedEmailListResult{
+		Domain:       domain,
+		MaskedEmails: []types.MaskedEmailInfo{},
+	}
+	for _, m := range all {
+		if m.State == maskedemail.StateDeleted || !matchesDomain(m.ForDomain, domain) {
+			continue
+		}
+		result.MaskedEmails = append(result.MaskedEmails, convertMaskedEmail(m))
+	}
+	result.Total = len(result.MaskedEmails)
+	return result, nil
+}
+
+// FindMaskedEmailByAddress returns the masked email with the given address.
+func (c *Client) FindMaskedEmailByAddress(address string) (types.MaskedEmailInfo, error) {
+	m, err := c.maskedEmailByAddress(address)
+	if err != nil {
+		return types.MaskedEmailInfo{}, err
+	}
+	return convertMaskedEmail(m), nil
+}
+
+func (c *Client) maskedEmailByAddress(address string) (*maskedemail.MaskedEmail, error) {
+	all, err := c.getAllMaskedEmails()
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range all {
+		if strings.EqualFold(m.Email, address) && m.State != maskedemail.StateDeleted {
+			return m, nil
+		}
+	}
+	return nil, fmt.Errorf("masked email %s: %w", address, ErrNotFound)
+}
+
+// RotateMaskedEmail creates an enabled replacement for the masked email with
+// the given address, copying its domain and description, then disables the
+// old address. The replacement is created first so that a failure never
+// leaves the site without a working address.
+func (c *Client) RotateMaskedEmail(address string) (types.MaskedEmailRotateResult, error) {
+	old, err := c.maskedEmailByAddress(address)
+	if err != nil {
+		return types.MaskedEmailRotateResult{}, err
+	}
+
+	replacement, err := c.createMaskedEmail(&maskedemail.MaskedEmail{
+		State:       maskedemail.StateEnabled,
+		ForDomain:   old.ForDomain,
+		Description: old.Description,
+	})
+	if err != nil {
+		return types.MaskedEmailRotateResult{}, err
+	}
+
+	if err := c.setMaskedEmailState(old.ID, maskedemail.StateDisabled); err != nil {
+		return types.MaskedEmailRotateResult{}, fmt.Errorf("replacement %s was created, but disabling %s failed: %w",
+			replacement.Email, old.Email, err)
+	}
+	old.State = maskedemail.StateDisabled
+
+	return types.MaskedEmailRotateResult{
+		Disabled:    convertMaskedEmail(old),
+		Replacement: convertMaskedEmail(replacement),
+	}, nil
+}
+
+func (c *Client) createMaskedEmail(m *maskedemail.MaskedEmail) (*maskedemail.MaskedEmail, error) {
+	createID := jmap.ID("create0")
+	req := &jmap.Request{}
+	req.Invoke(&maskedemail.Set{
+		Account: c.accountID,
+		Create:  map[jmap.ID]*maskedemail.MaskedEmail{createID: m},
+	})
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("creating masked email: %w", err)
+	}
+
+	for _, inv := range resp.Responses {
+		switch r := inv.Args.(type) {
+		case *maskedemail.SetResponse:
+			if created, ok := r.Created[createID]; ok {
+				// The server returns only server-set properties.
+				created.State = m.State
+				created.ForDomain = m.ForDomain
+				created.Description = m.Description
+				return created, nil
+			}
+			if setErr, ok := r.NotCreated[createID]; ok {
+				return nil, fmt.Errorf("creating masked email: %s", setErrorDescription(setErr))
+			}
+		case *jmap.MethodError:
+			return nil, fmt.Errorf("creating masked email: %s", r.Error())
+		}
+	}
+
+	return nil, fmt.Errorf("creating masked email: unexpected response")
+}
+
+func (c *Client) setMaskedEmailState(id jmap.ID, state string) error {
+	req := &jmap.Request{}
+	req.Invoke(&maskedemail.Set{
+		Account: c.accountID,
+		Update:  map[jmap.ID]jmap.Patch{id: {"state": state}},
+	})
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return fmt.Errorf("updating masked email: %w", err)
+	}
+
+	for _, inv := range resp.Responses {
+		switch r := inv.Args.(type) {
+		case *maskedemail.SetResponse:
+			if _, ok := r.Updated[id]; ok {
+				return nil
+			}
+			if setErr, ok := r.NotUpdated[id]; ok {
+				return fmt.Errorf("updating masked email: %s", setErrorDescription(setErr))
+			}
+		case *jmap.MethodError:
+			return fmt.Errorf("updating masked email: %s", r.Error())
+		}
+	}
+
+	return fmt.Errorf("updating masked email: unexpected response")
+}
+
+// matchesDomain reports whether forDomain (an origin such as
+// "https://shop.example" or a bare host) is domain or a subdomain of it.
+func matchesDomain(forDomain, domain string) bool {
+	host := strings.ToLower(strings.TrimSpace(forDomain))
+	if u, err := url.Parse(host); err == nil && u.Host != "" {
+		host = u.Hostname()
+	}
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+func setErrorDescription(e *jmap.SetError) string {
+	if e.Description != nil {
+		return *e.Description
+	}
+	return e.Type
+}
+
+func convertMaskedEmail(m *maskedemail.MaskedEmail) types.MaskedEmailInfo {
+	return types.MaskedEmailInfo{
+		ID:            string(m.ID),
+		Email:         m.Email,
+		State:         m.State,
+		ForDomain:     m.ForDomain,
+		Description:   m.Description,
+		LastMessageAt: m.LastMessageAt,
+		CreatedAt:     m.CreatedAt,
+	}
+}
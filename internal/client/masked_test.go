@@ -0,0 +1,111 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"git.sr.ht/~rockorager/go-jmap"
+
+	"github.com/cboone/fm/internal/jmap/maskedemail"
+)
+
+func maskedTestClient(doFunc func(*jmap.Request) (*jmap.Response, error)) *Client {
+	return &Client{
+		jmap: &jmap.Client{
+			Session: &jmap.Session{
+				RawCapabilities: map[jmap.URI]json.RawMessage{
+					maskedemail.URI: json.RawMessage("{}"),
+				},
+			},
+		},
+		accountID: "acct-1",
+		doFunc:    doFunc,
+	}
+}
+
+func maskedGetResponse(list ...*maskedemail.MaskedEmail) *jmap.Response {
+	return &jmap.Response{Responses: []*jmap.Invocation{
+		{Name: "MaskedEmail/get", Args: &maskedemail.GetResponse{List: list}},
+	}}
+}
+
+func TestFindMaskedEmails_MatchesDomainAndSubdomains(t *testing.T) {
+	c := maskedTestClient(func(req *jmap.Request) (*jmap.Response, error) {
+		return maskedGetResponse(
+			&maskedemail.MaskedEmail{ID: "ME1", Email: "a1@fastmail.com", State: "enabled", ForDomain: "https://shop.example"},
+			&maskedemail.MaskedEmail{ID: "ME2", Email: "a2@fastmail.com", State: "disabled", ForDomain: "https://www.shop.example:443"},
+			&maskedemail.MaskedEmail{ID: "ME3", Email: "a3@fastmail.com", State: "enabled", ForDomain: "https://notshop.example"},
+			&maskedemail.MaskedEmail{ID: "ME4", Email: "a4@fastmail.com", State: "deleted", ForDomain: "https://shop.example"},
+		), nil
+	})
+
+	result, err := c.FindMaskedEmails("Shop.Example")
+	if err != nil {
+		t.Fatalf("FindMaskedEmails() error: %v", err)
+	}
+	if result.Total != 2 {
+		t.Fatalf("FindMaskedEmails() total = %d, want 2: %+v", result.Total, result.MaskedEmails)
+	}
+	if result.MaskedEmails[0].ID != "ME1" || result.MaskedEmails[1].ID != "ME2" {
+		t.Errorf("FindMaskedEmails() = %+v, want ME1 and ME2", result.MaskedEmails)
+	}
+}
+
+func TestFindMaskedEmails_RequiresCapability(t *testing.T) {
+	c := &Client{accountID: "acct-1"}
+	if _, err := c.FindMaskedEmails("shop.example"); err == nil {
+		t.Fatal("FindMaskedEmails() expected capability error")
+	}
+}
+
+func TestRotateMaskedEmail_CreatesThenDisables(t *testing.T) {
+	var calls []*maskedemail.Set
+	c := maskedTestClient(func(req *jmap.Request) (*jmap.Response, error) {
+		switch args := req.Calls[0].Args.(type) {
+		case *maskedemail.Get:
+			return maskedGetResponse(
+				&maskedemail.MaskedEmail{ID: "ME1", Email: "old@fastmail.com", State: "enabled", ForDomain: "https://shop.example", Description: "Shop"},
+			), nil
+		case *maskedemail.Set:
+			calls = append(calls, args)
+			resp := &maskedemail.SetResponse{}
+			if len(args.Create) > 0 {
+				resp.Created = map[jmap.ID]*maskedemail.MaskedEmail{"create0": {ID: "ME2", Email: "new@fastmail.com"}}
+			} else {
+				resp.Updated = map[jmap.ID]*maskedemail.MaskedEmail{"ME1": nil}
+			}
+			return &jmap.Response{Responses: []*jmap.Invocation{{Name: "MaskedEmail/set", Args: resp}}}, nil
+		}
+		t.Fatalf("unexpected call %T", req.Calls[0].Args)
+		return nil, nil
+	})
+
+	result, err := c.RotateMaskedEmail("OLD@fastmail.com")
+	if err != nil {
+		t.Fatalf("RotateMaskedEmail() error: %v", err)
+	}
+	if len(calls) != 2 || len(calls[0].Create) != 1 || len(calls[1].Update) != 1 {
+		t.Fatalf("expected create then update, got %+v", calls)
+	}
+	created := calls[0].Create["create0"]
+	if created.ForDomain != "https://shop.example" || created.Description != "Shop" || created.State != "enabled" {
+		t.Errorf("replacement = %+v, want same domain and description, enabled", created)
+	}
+	if calls[1].Update["ME1"]["state"] != "disabled" {
+		t.Errorf("update = %v, want state disabled", calls[1].Update["ME1"])
+	}
+	if result.Disabled.State != "disabled" || result.Replacement.Email != "new@fastmail.com" {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestRotateMaskedEmail_UnknownAddress(t *testing.T) {
+	c := maskedTestClient(func(req *jmap.Request) (*jmap.Response, error) {
+		return maskedGetResponse(), nil
+	})
+
+	if _, err := c.RotateMaskedEmail("missing@fastmail.com"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("RotateMaskedEmail() error = %v, want ErrNotFound", err)
+	}
+}
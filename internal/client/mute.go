@@ -0,0 +1,102 @@
+package client
+
+import (
+	"fmt"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+	"git.sr.ht/~rockorager/go-jmap/mail/thread"
+)
+
+// MutedKeyword marks the emails of a muted thread. List and search skip
+// threads in which any email carries it.
+const MutedKeyword = "$muted"
+
+// ThreadMemberIDs resolves each of ids, which may be email or thread IDs, to
+// the IDs of every email in the thread. IDs that match neither are returned
+// in notFound.
+func (c *Client) ThreadMemberIDs(ids []string) (emailIDs []string, notFound []string, err error) {
+	jids := make([]jmap.ID, len(ids))
+	for i, id := range ids {
+		jids[i] = jmap.ID(id)
+	}
+
+	req := &jmap.Request{}
+	req.Invoke(&email.Get{
+		Account:    c.accountID,
+		IDs:        jids,
+		Properties: []string{"id", "threadId"},
+	})
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("email/get: %w", err)
+	}
+
+	var threadIDs []jmap.ID
+	seenThread := make(map[jmap.ID]bool)
+	addThread := func(id jmap.ID) {
+		if !seenThread[id] {
+			seenThread[id] = true
+			threadIDs = append(threadIDs, id)
+		}
+	}
+	for _, inv := range resp.Responses {
+		switch r := inv.Args.(type) {
+		case *email.GetResponse:
+			for _, e := range r.List {
+				addThread(e.ThreadID)
+			}
+			// IDs that are not emails may be threads.
+			for _, id := range r.NotFound {
+				addThread(id)
+			}
+		case *jmap.MethodError:
+			return nil, nil, fmt.Errorf("email/get: %s", r.Error())
+		}
+	}
+	if len(threadIDs) == 0 {
+		return nil, nil, nil
+	}
+
+	req = &jmap.Request{}
+	req.Invoke(&thread.Get{
+		Account:    c.accountID,
+		IDs:        threadIDs,
+		Properties: []string{"id", "emailIds"},
+	})
+	resp, err = c.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("thread/get: %w", err)
+	}
+
+	seenEmail := make(map[jmap.ID]bool)
+	for _, inv := range resp.Responses {
+		switch r := inv.Args.(type) {
+		case *thread.GetResponse:
+			for _, t := range r.List {
+				for _, id := range t.EmailIDs {
+					if !seenEmail[id] {
+						seenEmail[id] = true
+						emailIDs = append(emailIDs, string(id))
+					}
+				}
+			}
+			for _, id := range r.NotFound {
+				notFound = append(notFound, string(id))
+			}
+		case *jmap.MethodError:
+			return nil, nil, fmt.Errorf("thread/get: %s", r.Error())
+		}
+	}
+	return emailIDs, notFound, nil
+}
+
+// SetMuted sets the $muted keyword on emails.
+func (c *Client) SetMuted(emailIDs []string) ([]string, []string) {
+	return c.SetKeyword(emailIDs, MutedKeyword)
+}
+
+// SetUnmuted removes the $muted keyword from emails.
+func (c *Client) SetUnmuted(emailIDs []string) ([]string, []string) {
+	return c.ClearKeyword(emailIDs, MutedKeyword)
+}
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+)
+
+// EmailState is the mailboxes and keywords of an email: what a move or a
+// keyword change would alter.
+type EmailState struct {
+	MailboxIDs []string
+	Keywords   map[string]bool
+}
+
+// OnlyIn reports whether the email is in mailboxID and no other mailbox,
+// which is where a move to mailboxID would leave it.
+func (s EmailState) OnlyIn(mailboxID jmap.ID) bool {
+	return len(s.MailboxIDs) == 1 && s.MailboxIDs[0] == string(mailboxID)
+}
+
+// In reports whether the email is in mailboxID, whatever other mailboxes
+// it is in.
+func (s EmailState) In(mailboxID jmap.ID) bool {
+	return slices.Contains(s.MailboxIDs, string(mailboxID))
+}
+
+// GetEmailStates returns the mailboxes and keywords of each of the given
+// emails, keyed by email ID. Emails that are not found are left out.
+func (c *Client) GetEmailStates(ids []string) (map[string]EmailState, error) {
+	result := make(map[string]EmailState, len(ids))
+
+	size := c.maxGetSize()
+	for start := 0; start < len(ids); start += size {
+		end := min(start+size, len(ids))
+
+		jmapIDs := make([]jmap.ID, 0, end-start)
+		for _, id := range ids[start:end] {
+			jmapIDs = append(jmapIDs, jmap.ID(id))
+		}
+
+		req := &jmap.Request{}
+		req.Invoke(&email.Get{
+			Account:    c.accountID,
+			IDs:        jmapIDs,
+			Properties: []string{"id", "mailboxIds", "keywords"},
+		})
+
+		resp, err := c.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("email/get: %w", err)
+		}
+
+		for _, inv := range resp.Responses {
+			switch r := inv.Args.(type) {
+			case *email.GetResponse:
+				for _, e := range r.List {
+					mailboxIDs := make([]string, 0, len(e.MailboxIDs))
+					for id, in := range e.MailboxIDs {
+						if in {
+							mailboxIDs = append(mailboxIDs, string(id))
+						}
+					}
+					sort.Strings(mailboxIDs)
+					result[string(e.ID)] = EmailState{MailboxIDs: mailboxIDs, Keywords: e.Keywords}
+				}
+			case *jmap.MethodError:
+				return nil, fmt.Errorf("email/get: %s", r.Error())
+			}
+		}
+	}
+	return result, nil
+}
+
+// SplitNoOps separates ids into the emails an action still has to change
+// and those done reports are already in its end state, so that re-running
+// an action sends nothing for emails an earlier run handled. Emails missing
+// from states are kept as pending, so that the action reports them.
+func SplitNoOps(ids []string, states map[string]EmailState, done func(EmailState) bool) (pending, noOps []string) {
+	for _, id := range ids {
+		if s, ok := states[id]; ok && done(s) {
+			noOps = append(noOps, id)
+		} else {
+			pending = append(pending, id)
+		}
+	}
+	return pending, noOps
+}
+
+// HasKeywords returns a done function for SplitNoOps that is true when an
+// email has every one of keywords.
+func HasKeywords(keywords ...string) func(EmailState) bool {
+	return func(s EmailState) bool {
+		return !slices.ContainsFunc(keywords, func(k string) bool { return !s.Keywords[k] })
+	}
+}
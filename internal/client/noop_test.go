@@ -0,0 +1,33 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitNoOps(t *testing.T) {
+	states := map[string]EmailState{
+		"M1": {MailboxIDs: []string{"mb-archive"}},
+		"M2": {MailboxIDs: []string{"mb-archive", "mb-inbox"}},
+		"M3": {MailboxIDs: []string{"mb-inbox"}},
+	}
+	pending, noOps := SplitNoOps([]string{"M1", "M2", "M3", "M4"}, states, func(s EmailState) bool {
+		return s.OnlyIn("mb-archive")
+	})
+	if want := []string{"M2", "M3", "M4"}; !reflect.DeepEqual(pending, want) {
+		t.Errorf("pending = %v, want %v", pending, want)
+	}
+	if want := []string{"M1"}; !reflect.DeepEqual(noOps, want) {
+		t.Errorf("noOps = %v, want %v", noOps, want)
+	}
+}
+
+func TestHasKeywords(t *testing.T) {
+	isSpam := HasKeywords("$junk", "$seen")
+	if !isSpam(EmailState{Keywords: map[string]bool{"$junk": true, "$seen": true}}) {
+		t.Error("expected an email with both keywords to match")
+	}
+	if isSpam(EmailState{Keywords: map[string]bool{"$junk": true}}) {
+		t.Error("expected an email missing a keyword not to match")
+	}
+}
@@ -0,0 +1,170 @@
+package client
+
+import (
+	"errors"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+)
+
+// queryPage tracks progress through a multi-page Email/query. After the first
+// page, each request is anchored on the last ID seen rather than a numeric
+// position, so emails arriving or disappearing ahead of the anchor
+// mid-iteration do not shift results into pages already read (skipping
+// emails) or pages not yet read (duplicating them).
+type queryPage struct {
+	position int64
+	anchor   jmap.ID
+}
+
+// anchorOffset is the Email/query anchorOffset to send: 1 starts the page
+// just after the anchor. Without an anchor the server uses position.
+func (p *queryPage) anchorOffset() int64 {
+	if p.anchor == "" {
+		return 0
+	}
+	return 1
+}
+
+// advance records a page of results.
+func (p *queryPage) advance(ids []jmap.ID) {
+	p.position += int64(len(ids))
+	if len(ids) > 0 {
+		p.anchor = ids[len(ids)-1]
+	}
+}
+
+// anchorLost reports whether err is an anchorNotFound error for the current
+// anchor, which happens when the anchor email was destroyed or moved out of
+// the results. The anchor is dropped so the page is retried by position.
+func (p *queryPage) anchorLost(err *jmap.MethodError) bool {
+	if err.Type != "anchorNotFound" || p.anchor == "" {
+		return false
+	}
+	p.anchor = ""
+	return true
+}
+
+// errAnchorLost is returned by a windows fetch when the window's anchor was
+// not found, after anchorLost has dropped it; the window is then retried by
+// position.
+var errAnchorLost = errors.New("query anchor not found")
+
+// windows fetches up to limit query results starting at offset in windows
+// of at most the server's MaxObjectsInGet, so that the Email/get reading
+// each window's IDs stays within what the server allows in one call. fetch
+// is called for each window in turn with the page to query and its limit,
+// and returns the IDs it got; a short window ends the results. As in scans,
+// each window after the first is anchored on the last ID of the one before.
+// With no limit, or one the server allows, fetch is called once.
+func (c *Client) windows(offset int64, limit uint64, fetch func(page *queryPage, limit uint64) ([]jmap.ID, error)) error {
+	page := queryPage{position: offset}
+	size := uint64(c.MaxObjectsInGet())
+	if limit == 0 || size == 0 || limit <= size {
+		_, err := fetch(&page, limit)
+		return err
+	}
+	for fetched := uint64(0); fetched < limit; {
+		n := min(size, limit-fetched)
+		ids, err := fetch(&page, n)
+		if errors.Is(err, errAnchorLost) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		page.advance(ids)
+		fetched += uint64(len(ids))
+		if uint64(len(ids)) < n {
+			break
+		}
+	}
+	return nil
+}
+
+// queryScan is what scanQuery learns from the first page of a scan: the
+// total number of matching emails, the query state when the server can
+// calculate changes from it, and the Email state of the first Email/get.
+type queryScan struct {
+	total      uint64
+	queryState string
+	state      string
+}
+
+// scanQuery pages through every email matching filter in sort order, each
+// page after the first anchored on the last ID of the one before. With
+// props, each page's emails are fetched with those properties in the same
+// request, scanPageSize at a time; with none, only IDs are queried,
+// defaultQueryPageSize at a time, and visit gets a nil list. visit is
+// called once for each page.
+func (c *Client) scanQuery(filter email.Filter, sort []*email.SortComparator, props []string, visit func(ids []jmap.ID, list []*email.Email)) (queryScan, error) {
+	var scan queryScan
+	var page queryPage
+	limit := uint64(defaultQueryPageSize)
+	if props != nil {
+		limit = c.scanPageSize()
+	}
+
+pages:
+	for {
+		req := &jmap.Request{}
+		queryCallID := req.Invoke(&email.Query{
+			Account:        c.accountID,
+			Filter:         filter,
+			Sort:           sort,
+			Position:       page.position,
+			Anchor:         page.anchor,
+			AnchorOffset:   page.anchorOffset(),
+			Limit:          limit,
+			CalculateTotal: true,
+		})
+		if props != nil {
+			req.Invoke(&email.Get{
+				Account:    c.accountID,
+				Properties: props,
+				ReferenceIDs: &jmap.ResultReference{
+					ResultOf: queryCallID,
+					Name:     "Email/query",
+					Path:     "/ids",
+				},
+			})
+		}
+
+		resp, err := c.Do(req)
+		if err != nil {
+			return queryScan{}, err
+		}
+
+		var pageIDs []jmap.ID
+		var list []*email.Email
+		for _, inv := range resp.Responses {
+			switch r := inv.Args.(type) {
+			case *email.QueryResponse:
+				if page.position == 0 {
+					scan.total = r.Total
+					if r.CanCalculateChanges {
+						scan.queryState = r.QueryState
+					}
+				}
+				pageIDs = r.IDs
+			case *email.GetResponse:
+				if page.position == 0 {
+					scan.state = r.State
+				}
+				list = r.List
+			case *jmap.MethodError:
+				if page.anchorLost(r) {
+					continue pages
+				}
+				return queryScan{}, r
+			}
+		}
+
+		visit(pageIDs, list)
+		page.advance(pageIDs)
+		if uint64(page.position) >= scan.total || len(pageIDs) == 0 {
+			break
+		}
+	}
+	return scan, nil
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"fmt"
+	"testing"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+)
+
+func TestScanQuery_AnchorsPagesAndKeepsFirstPageState(t *testing.T) {
+	const matching = 250
+	var queries []email.Query
+	c := stalwartLikeClient()
+	c.accountID = "test-account"
+	c.doFunc = func(req *jmap.Request) (*jmap.Response, error) {
+		query := req.Calls[0].Args.(*email.Query)
+		queries = append(queries, *query)
+		start := query.Position
+		if query.Anchor != "" {
+			fmt.Sscanf(string(query.Anchor), "M%d", &start)
+			start += query.AnchorOffset
+		}
+		var ids []jmap.ID
+		var list []*email.Email
+		for i := start; i < min(start+int64(query.Limit), matching); i++ {
+			ids = append(ids, jmap.ID(fmt.Sprintf("M%d", i)))
+			list = append(list, &email.Email{ID: ids[len(ids)-1]})
+		}
+		return &jmap.Response{Responses: []*jmap.Invocation{
+			{Name: "Email/query", CallID: "0", Args: &email.QueryResponse{Total: matching, IDs: ids, QueryState: fmt.Sprintf("q%d", len(queries))}},
+			{Name: "Email/get", CallID: "1", Args: &email.GetResponse{List: list, State: fmt.Sprintf("s%d", len(queries))}},
+		}}, nil
+	}
+
+	var visited []int
+	scan, err := c.scanQuery(nil, nil, []string{"id"}, func(ids []jmap.ID, emails []*email.Email) {
+		if len(ids) != len(emails) {
+			t.Errorf("expected one email per ID, got %d IDs and %d emails", len(ids), len(emails))
+		}
+		visited = append(visited, len(emails))
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(queries) != 2 || queries[0].Limit != 200 || queries[1].Anchor != "M199" || queries[1].AnchorOffset != 1 {
+		t.Fatalf("expected a second page anchored on M199, got %+v", queries)
+	}
+	if len(visited) != 2 || visited[0] != 200 || visited[1] != 50 {
+		t.Errorf("expected pages of 200 and 50 emails, got %v", visited)
+	}
+	if scan.total != matching || scan.state != "s1" || scan.queryState != "" {
+		t.Errorf("expected the first page's total and state, got %+v", scan)
+	}
+}
+
+func TestScanQuery_ReturnsMethodErrors(t *testing.T) {
+	c := &Client{
+		accountID: "test-account",
+		doFunc: func(req *jmap.Request) (*jmap.Response, error) {
+			return &jmap.Response{Responses: []*jmap.Invocation{
+				{Name: "error", CallID: "0", Args: &jmap.MethodError{Type: "unsupportedFilter"}},
+			}}, nil
+		},
+	}
+
+	_, err := c.scanQuery(nil, nil, nil, func([]jmap.ID, []*email.Email) {
+		t.Error("expected no page to be visited")
+	})
+	if err == nil || err.Error() != "unsupportedFilter" {
+		t.Errorf("expected the method error, got %v", err)
+	}
+}
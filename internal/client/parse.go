@@ -0,0 +1,73 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+// ErrNotParsable indicates that the server could not parse an uploaded blob
+// as an RFC 5322 message.
+var ErrNotParsable = errors.New("not an RFC 5322 message")
+
+// parseProperties are the Email/parse properties for a local message. The
+// id, threadId, keywords, and receivedAt of a parsed message are null or
+// meaningless, so they are not requested.
+var parseProperties = []string{
+	"from", "to", "cc", "bcc", "replyTo", "subject", "sentAt",
+	"bodyValues", "textBody", "htmlBody", "attachments", "headers",
+}
+
+// ParseEmail uploads an RFC 5322 message and parses it with Email/parse,
+// returning it in the same form as ReadEmail. The message is not imported
+// into any mailbox; the upload expires unused. A parsed message has no ID,
+// and its date is its Date header.
+func (c *Client) ParseEmail(message io.Reader, preferHTML bool, rawHeaders bool) (types.EmailDetail, error) {
+	upload, err := c.Upload(c.accountID, message)
+	if err != nil {
+		return types.EmailDetail{}, fmt.Errorf("upload: %w", err)
+	}
+
+	req := &jmap.Request{}
+	req.Invoke(&email.Parse{
+		Account:    c.accountID,
+		BlobIDs:    []jmap.ID{upload.ID},
+		Properties: parseProperties,
+		BodyProperties: []string{
+			"partId", "blobId", "size", "name", "type", "charset", "disposition", "cid",
+		},
+		FetchTextBodyValues: true,
+		FetchHTMLBodyValues: true,
+	})
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return types.EmailDetail{}, fmt.Errorf("email/parse: %w", err)
+	}
+
+	for _, inv := range resp.Responses {
+		switch r := inv.Args.(type) {
+		case *email.ParseResponse:
+			e, ok := r.Parsed[upload.ID]
+			if !ok {
+				return types.EmailDetail{}, ErrNotParsable
+			}
+			c.repairCharsets(e)
+			detail := convertDetail(e, preferHTML, rawHeaders)
+			detail.IsUnread = false
+			if e.SentAt != nil {
+				detail.ReceivedAt = *e.SentAt
+			}
+			return detail, nil
+		case *jmap.MethodError:
+			return types.EmailDetail{}, fmt.Errorf("email/parse: %s", r.Error())
+		}
+	}
+
+	return types.EmailDetail{}, fmt.Errorf("email/parse: unexpected response")
+}
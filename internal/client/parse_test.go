@@ -0,0 +1,76 @@
+package client
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+)
+
+func TestParseEmail(t *testing.T) {
+	sent := time.Date(2026, 9, 30, 14, 0, 0, 0, time.UTC)
+	var uploaded string
+	var parse *email.Parse
+	c := &Client{
+		accountID: "test-account",
+		uploadFunc: func(accountID jmap.ID, blob io.Reader) (*jmap.UploadResponse, error) {
+			data, _ := io.ReadAll(blob)
+			uploaded = string(data)
+			return &jmap.UploadResponse{ID: "B1"}, nil
+		},
+		doFunc: func(req *jmap.Request) (*jmap.Response, error) {
+			parse = req.Calls[0].Args.(*email.Parse)
+			return &jmap.Response{Responses: []*jmap.Invocation{
+				{Name: "Email/parse", CallID: "0", Args: &email.ParseResponse{Parsed: map[jmap.ID]*email.Email{
+					"B1": {
+						From:       []*mail.Address{{Name: "Alice", Email: "alice@example.com"}},
+						Subject:    "Original",
+						SentAt:     &sent,
+						TextBody:   []*email.BodyPart{{PartID: "1", Type: "text/plain"}},
+						BodyValues: map[string]*email.BodyValue{"1": {Value: "Hello"}},
+					},
+				}}},
+			}}, nil
+		},
+	}
+
+	detail, err := c.ParseEmail(strings.NewReader("Subject: Original\r\n\r\nHello"), false, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(uploaded, "Subject: Original") {
+		t.Errorf("expected the message to be uploaded, got %q", uploaded)
+	}
+	if len(parse.BlobIDs) != 1 || parse.BlobIDs[0] != "B1" || !parse.FetchTextBodyValues {
+		t.Errorf("unexpected Email/parse call: %+v", parse)
+	}
+	if detail.ID != "" || detail.Subject != "Original" || detail.Body != "Hello" || detail.IsUnread {
+		t.Errorf("unexpected detail: %+v", detail)
+	}
+	if !detail.ReceivedAt.Equal(sent) {
+		t.Errorf("expected the date from the Date header, got %v", detail.ReceivedAt)
+	}
+}
+
+func TestParseEmail_NotParsable(t *testing.T) {
+	c := &Client{
+		accountID: "test-account",
+		uploadFunc: func(accountID jmap.ID, blob io.Reader) (*jmap.UploadResponse, error) {
+			return &jmap.UploadResponse{ID: "B1"}, nil
+		},
+		doFunc: func(req *jmap.Request) (*jmap.Response, error) {
+			return &jmap.Response{Responses: []*jmap.Invocation{
+				{Name: "Email/parse", CallID: "0", Args: &email.ParseResponse{NotParsable: []jmap.ID{"B1"}}},
+			}}, nil
+		},
+	}
+
+	if _, err := c.ParseEmail(strings.NewReader("not mail"), false, false); !errors.Is(err, ErrNotParsable) {
+		t.Fatalf("expected ErrNotParsable, got %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package client
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+// AggregateParticipants returns every address in the From, To, and Cc
+// fields of the given emails, with how often each appears, sorted by count
+// descending. IDs that match no email are returned in notFound.
+func (c *Client) AggregateParticipants(ids []string) (types.ParticipantsResult, []string, error) {
+	summaries, notFound, err := c.GetEmailSummaries(ids)
+	if err != nil {
+		return types.ParticipantsResult{}, nil, err
+	}
+	return types.ParticipantsResult{
+		Emails:       len(summaries),
+		Participants: aggregateParticipants(summaries),
+	}, notFound, nil
+}
+
+func aggregateParticipants(summaries []types.EmailSummary) []types.Participant {
+	accum := make(map[string]*types.Participant)
+	var order []string
+
+	for _, s := range summaries {
+		// An address counts once per email however many fields it is in.
+		seen := make(map[string]bool)
+		add := func(addrs []types.Address, role func(*types.Participant)) {
+			for _, a := range addrs {
+				if a.Email == "" {
+					continue
+				}
+				key := strings.ToLower(a.Email)
+				p, ok := accum[key]
+				if !ok {
+					p = &types.Participant{Email: key}
+					accum[key] = p
+					order = append(order, key)
+				}
+				if p.Name == "" {
+					p.Name = a.Name
+				}
+				if !seen[key] {
+					seen[key] = true
+					p.Count++
+				}
+				role(p)
+			}
+		}
+		add(s.From, func(p *types.Participant) { p.From++ })
+		add(s.To, func(p *types.Participant) { p.To++ })
+		add(s.CC, func(p *types.Participant) { p.CC++ })
+	}
+
+	participants := make([]types.Participant, 0, len(order))
+	for _, key := range order {
+		participants = append(participants, *accum[key])
+	}
+	sort.SliceStable(participants, func(i, j int) bool {
+		if participants[i].Count != participants[j].Count {
+			return participants[i].Count > participants[j].Count
+		}
+		return participants[i].Email < participants[j].Email
+	})
+	return participants
+}
+
+// AggregateAddresses returns the distinct addresses in field ("from", "to",
+// "cc", or "all") of the emails with the given IDs. It also returns the IDs
+// that were not found.
+func (c *Client) AggregateAddresses(ids []string, field string) (types.AddressesResult, []string, error) {
+	summaries, notFound, err := c.GetEmailSummaries(ids)
+	if err != nil {
+		return types.AddressesResult{}, nil, err
+	}
+	return types.AddressesResult{
+		Field:     field,
+		Emails:    len(summaries),
+		Addresses: selectAddresses(aggregateParticipants(summaries), field),
+	}, notFound, nil
+}
+
+// selectAddresses keeps the participants that appear in field, counting
+// only their appearances there, sorted by that count descending.
+func selectAddresses(participants []types.Participant, field string) []types.AddressCount {
+	addresses := make([]types.AddressCount, 0, len(participants))
+	for _, p := range participants {
+		count := p.Count
+		switch field {
+		case "from":
+			count = p.From
+		case "to":
+			count = p.To
+		case "cc":
+			count = p.CC
+		}
+		if count > 0 {
+			addresses = append(addresses, types.AddressCount{Email: p.Email, Name: p.Name, Count: count})
+		}
+	}
+	sort.SliceStable(addresses, func(i, j int) bool {
+		if addresses[i].Count != addresses[j].Count {
+			return addresses[i].Count > addresses[j].Count
+		}
+		return addresses[i].Email < addresses[j].Email
+	})
+	return addresses
+}
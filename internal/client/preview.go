@@ -0,0 +1,72 @@
+package client
+
+import (
+	"strings"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+)
+
+// maxPreviewRunes is the length of a preview built by fm, matching the 256
+// characters RFC 8621 allows a server-computed preview.
+const maxPreviewRunes = 256
+
+// previewBodyBytes caps the body text fetched to build a missing preview.
+// A preview needs only the start of the body, so this stays small.
+const previewBodyBytes = 1024
+
+// fillMissingPreviews builds a preview from the start of the text body for
+// emails the server returned without one. Listings rely on the server's
+// preview property, which costs nothing extra; only servers that leave it
+// empty pay for a body fetch, and only for the emails missing it. Previews
+// are cosmetic, so a failed fetch leaves them empty.
+func (c *Client) fillMissingPreviews(emails []*email.Email) {
+	missing := make(map[jmap.ID]*email.Email)
+	var ids []jmap.ID
+	for _, e := range emails {
+		if e != nil && e.Preview == "" {
+			missing[e.ID] = e
+			ids = append(ids, e.ID)
+		}
+	}
+
+	size := c.maxGetSize()
+	for start := 0; start < len(ids); start += size {
+		end := min(start+size, len(ids))
+
+		req := &jmap.Request{}
+		req.Invoke(&email.Get{
+			Account:             c.accountID,
+			IDs:                 ids[start:end],
+			Properties:          []string{"id", "textBody", "htmlBody", "bodyValues"},
+			FetchTextBodyValues: true,
+			MaxBodyValueBytes:   previewBodyBytes,
+		})
+
+		resp, err := c.Do(req)
+		if err != nil {
+			return
+		}
+		for _, inv := range resp.Responses {
+			r, ok := inv.Args.(*email.GetResponse)
+			if !ok {
+				continue
+			}
+			for _, body := range r.List {
+				if e, ok := missing[body.ID]; ok {
+					e.Preview = makePreview(extractBody(body, false))
+				}
+			}
+		}
+	}
+}
+
+// makePreview collapses the whitespace in body and truncates it to
+// maxPreviewRunes.
+func makePreview(body string) string {
+	preview := strings.Join(strings.Fields(body), " ")
+	if runes := []rune(preview); len(runes) > maxPreviewRunes {
+		preview = string(runes[:maxPreviewRunes])
+	}
+	return preview
+}
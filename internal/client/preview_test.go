@@ -0,0 +1,63 @@
+package client
+
+import (
+	"strings"
+	"testing"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+)
+
+func TestFillMissingPreviews_FetchesOnlyMissing(t *testing.T) {
+	var gets []*email.Get
+	c := &Client{
+		accountID: "test-account",
+		doFunc: func(req *jmap.Request) (*jmap.Response, error) {
+			get := req.Calls[0].Args.(*email.Get)
+			gets = append(gets, get)
+			return &jmap.Response{Responses: []*jmap.Invocation{
+				{Name: "Email/get", CallID: "0", Args: &email.GetResponse{List: []*email.Email{{
+					ID:         "M2",
+					TextBody:   []*email.BodyPart{{PartID: "1", Type: "text/plain"}},
+					BodyValues: map[string]*email.BodyValue{"1": {Value: "Hi Bob,\n\n  the invoice\tis attached.\n"}},
+				}}}},
+			}}, nil
+		},
+	}
+
+	emails := []*email.Email{
+		{ID: "M1", Preview: "Server preview"},
+		{ID: "M2"},
+	}
+	c.fillMissingPreviews(emails)
+
+	if len(gets) != 1 {
+		t.Fatalf("expected one Email/get, got %d", len(gets))
+	}
+	if len(gets[0].IDs) != 1 || gets[0].IDs[0] != "M2" {
+		t.Errorf("expected only M2 to be fetched, got %v", gets[0].IDs)
+	}
+	if !gets[0].FetchTextBodyValues || gets[0].MaxBodyValueBytes != previewBodyBytes {
+		t.Errorf("expected a capped text body fetch, got %+v", gets[0])
+	}
+	if emails[0].Preview != "Server preview" {
+		t.Errorf("expected the server preview to be kept, got %q", emails[0].Preview)
+	}
+	if emails[1].Preview != "Hi Bob, the invoice is attached." {
+		t.Errorf("expected a preview built from the body, got %q", emails[1].Preview)
+	}
+}
+
+func TestFillMissingPreviews_NoneMissing(t *testing.T) {
+	c := &Client{doFunc: func(req *jmap.Request) (*jmap.Response, error) {
+		t.Fatal("expected no request when every email has a preview")
+		return nil, nil
+	}}
+	c.fillMissingPreviews([]*email.Email{{ID: "M1", Preview: "Server preview"}})
+}
+
+func TestMakePreview_Truncates(t *testing.T) {
+	if got := makePreview(strings.Repeat("é", maxPreviewRunes+10)); len([]rune(got)) != maxPreviewRunes {
+		t.Errorf("expected %d runes, got %d", maxPreviewRunes, len([]rune(got)))
+	}
+}
@@ -0,0 +1,115 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+)
+
+// errQueryChangesUnavailable means the server cannot bring a saved query
+// state up to date, so the query has to be run again in full.
+var errQueryChangesUnavailable = errors.New("query changes unavailable")
+
+// StandingQuery is an Email/query whose results are kept current with
+// Email/queryChanges, so each refresh transfers only the IDs that changed.
+// When the server cannot calculate changes (it does not support them for
+// the filter, or the saved state is too old), the full query is run again.
+type StandingQuery struct {
+	c      *Client
+	filter email.Filter
+	state  string
+	ids    map[string]bool
+}
+
+// NewStandingQuery prepares a standing query for opts. It ignores Limit,
+// Offset, SortField, and SortAsc. Nothing is sent until Refresh.
+func (c *Client) NewStandingQuery(opts SearchOptions) *StandingQuery {
+	return &StandingQuery{
+		c:      c,
+		filter: buildSearchFilter(opts),
+		ids:    make(map[string]bool),
+	}
+}
+
+// Refresh brings the results up to date and returns the IDs that joined
+// them since the previous refresh. The first call returns every match.
+func (q *StandingQuery) Refresh() ([]string, error) {
+	if q.state != "" {
+		added, err := q.changes()
+		if !errors.Is(err, errQueryChangesUnavailable) {
+			return added, err
+		}
+	}
+	return q.reload()
+}
+
+// reload runs the full query and diffs it against the previous results.
+func (q *StandingQuery) reload() ([]string, error) {
+	ids, state, err := q.c.queryAllIDs(q.filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var added []string
+	current := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		current[id] = true
+		if !q.ids[id] {
+			added = append(added, id)
+		}
+	}
+	q.ids = current
+	q.state = state
+	return added, nil
+}
+
+// changes applies Email/queryChanges since the saved state.
+func (q *StandingQuery) changes() ([]string, error) {
+	req := &jmap.Request{}
+	req.Invoke(&email.QueryChanges{
+		Account:         q.c.accountID,
+		Filter:          q.filter,
+		SinceQueryState: q.state,
+	})
+
+	resp, err := q.c.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("email/queryChanges: %w", err)
+	}
+
+	for _, inv := range resp.Responses {
+		switch r := inv.Args.(type) {
+		case *email.QueryChangesResponse:
+			// An email whose position changed is both removed and added, so
+			// membership is checked before removals are applied.
+			var added []string
+			readded := make(map[string]bool, len(r.Added))
+			for _, item := range r.Added {
+				id := string(item.ID)
+				if !q.ids[id] {
+					added = append(added, id)
+				}
+				readded[id] = true
+			}
+			for _, id := range r.Removed {
+				if !readded[string(id)] {
+					delete(q.ids, string(id))
+				}
+			}
+			for id := range readded {
+				q.ids[id] = true
+			}
+			q.state = r.NewQueryState
+			return added, nil
+		case *jmap.MethodError:
+			switch r.Type {
+			case "cannotCalculateChanges", "tooManyChanges", "unknownMethod":
+				return nil, errQueryChangesUnavailable
+			}
+			return nil, fmt.Errorf("email/queryChanges: %s", r.Error())
+		}
+	}
+	return nil, fmt.Errorf("email/queryChanges: unexpected response")
+}
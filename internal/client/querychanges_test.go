@@ -0,0 +1,85 @@
+package client
+
+import (
+	"testing"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+)
+
+func TestStandingQuery_UsesQueryChanges(t *testing.T) {
+	var methods []string
+	changesErr := ""
+
+	c := &Client{
+		accountID: "test-account",
+		doFunc: func(req *jmap.Request) (*jmap.Response, error) {
+			call := req.Calls[0]
+			methods = append(methods, call.Name)
+
+			var args any
+			switch a := call.Args.(type) {
+			case *email.Query:
+				args = &email.QueryResponse{
+					QueryState:          "s1",
+					CanCalculateChanges: true,
+					Total:               2,
+					IDs:                 []jmap.ID{"M1", "M2"},
+				}
+			case *email.QueryChanges:
+				if a.SinceQueryState != "s1" {
+					t.Errorf("expected sinceQueryState s1, got %q", a.SinceQueryState)
+				}
+				if changesErr != "" {
+					args = &jmap.MethodError{Type: changesErr}
+					break
+				}
+				// M1 moved (removed and re-added), M2 left, M3 joined.
+				args = &email.QueryChangesResponse{
+					OldQueryState: "s1",
+					NewQueryState: "s1",
+					Removed:       []jmap.ID{"M1", "M2"},
+					Added:         []jmap.AddedItem{{ID: "M3", Index: 0}, {ID: "M1", Index: 1}},
+				}
+			}
+			return &jmap.Response{Responses: []*jmap.Invocation{
+				{Name: call.Name, CallID: "0", Args: args},
+			}}, nil
+		},
+	}
+
+	q := c.NewStandingQuery(SearchOptions{})
+	added, err := q.Refresh()
+	if err != nil {
+		t.Fatalf("first Refresh: %v", err)
+	}
+	if len(added) != 2 {
+		t.Fatalf("expected every match on the first refresh, got %v", added)
+	}
+
+	added, err = q.Refresh()
+	if err != nil {
+		t.Fatalf("second Refresh: %v", err)
+	}
+	if len(added) != 1 || added[0] != "M3" {
+		t.Fatalf("expected only M3 to be new, got %v", added)
+	}
+	if methods[1] != "Email/queryChanges" {
+		t.Fatalf("expected Email/queryChanges on refresh, got %v", methods)
+	}
+	if !q.ids["M1"] || q.ids["M2"] || !q.ids["M3"] {
+		t.Errorf("expected results M1 and M3, got %v", q.ids)
+	}
+
+	changesErr = "cannotCalculateChanges"
+	added, err = q.Refresh()
+	if err != nil {
+		t.Fatalf("third Refresh: %v", err)
+	}
+	if methods[len(methods)-1] != "Email/query" {
+		t.Fatalf("expected a full query after cannotCalculateChanges, got %v", methods)
+	}
+	if len(added) != 1 || added[0] != "M2" {
+		t.Errorf("expected M2 to rejoin after the full query, got %v", added)
+	}
+}
@@ -0,0 +1,151 @@
+package client
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail"
+)
+
+// Server implementations with known quirks. ServerAuto asks for detection.
+const (
+	ServerAuto     = "auto"
+	ServerFastmail = "fastmail"
+	ServerCyrus    = "cyrus"
+	ServerStalwart = "stalwart"
+	ServerGeneric  = "generic"
+)
+
+// ServerKinds lists the values accepted by SetServer.
+var ServerKinds = []string{ServerAuto, ServerFastmail, ServerCyrus, ServerStalwart, ServerGeneric}
+
+// serverQuirks records where a server departs from what fm otherwise
+// assumes of a JMAP server.
+type serverQuirks struct {
+	// noHeaderFilter is set for servers whose Email/query rejects or
+	// ignores header conditions on arbitrary headers. fm then filters on
+	// the fetched headers instead.
+	noHeaderFilter bool
+}
+
+// knownQuirks is keyed by server kind. Kinds without an entry have none.
+var knownQuirks = map[string]serverQuirks{
+	ServerStalwart: {noHeaderFilter: true},
+}
+
+// identifyServer guesses the server implementation from its session and
+// the Server header of the session response.
+func identifyServer(s *jmap.Session, serverHeader string) string {
+	if s != nil {
+		for uri := range s.RawCapabilities {
+			switch {
+			case strings.HasPrefix(string(uri), "https://www.fastmail.com/dev/"):
+				return ServerFastmail
+			case strings.HasPrefix(string(uri), "https://cyrusimap.org/ns/"):
+				return ServerCyrus
+			}
+		}
+		if u, err := url.Parse(s.APIURL); err == nil && strings.HasSuffix(u.Hostname(), "fastmail.com") {
+			return ServerFastmail
+		}
+	}
+
+	header := strings.ToLower(serverHeader)
+	switch {
+	case strings.Contains(header, "stalwart"):
+		return ServerStalwart
+	case strings.Contains(header, "cyrus"):
+		return ServerCyrus
+	}
+	return ServerGeneric
+}
+
+// SetServer overrides server identification with one of ServerKinds.
+// ServerAuto and the empty string keep the detected kind.
+func (c *Client) SetServer(kind string) error {
+	if !slices.Contains(ServerKinds, kind) && kind != "" {
+		return fmt.Errorf("unknown server %q (supported: %s)", kind, strings.Join(ServerKinds, ", "))
+	}
+	if kind != ServerAuto && kind != "" {
+		c.server = kind
+	}
+	return nil
+}
+
+// Server returns the identified server kind.
+func (c *Client) Server() string {
+	if c.server == "" {
+		return ServerGeneric
+	}
+	return c.server
+}
+
+func (c *Client) quirks() serverQuirks {
+	return knownQuirks[c.Server()]
+}
+
+// headerFilter reports whether Email/query header conditions can be used.
+func (c *Client) headerFilter() bool {
+	return !c.quirks().noHeaderFilter
+}
+
+// sortOptions returns the Email/query sort properties the account
+// advertises, or nil when it does not say.
+func (c *Client) sortOptions() []string {
+	if c.jmap == nil || c.jmap.Session == nil {
+		return nil
+	}
+	acct, ok := c.jmap.Session.Accounts[c.accountID]
+	if !ok {
+		return nil
+	}
+	m, _ := acct.Capabilities[mail.URI].(*mail.Mail)
+	if m == nil {
+		return nil
+	}
+	return m.EmailQuerySortOptions
+}
+
+// requireSort returns an error matching ErrUnsupported when the account
+// advertises its sort options and property is not among them.
+func (c *Client) requireSort(property string) error {
+	opts := c.sortOptions()
+	if len(opts) == 0 || slices.Contains(opts, property) {
+		return nil
+	}
+	return &unsupportedError{feature: "sorting by " + property}
+}
+
+// normalizeSessionURLs makes the session's URLs usable as given: relative
+// URLs are resolved against the session endpoint, and template variables
+// whose braces were percent-encoded are restored so that downloads and
+// uploads expand them.
+func normalizeSessionURLs(s *jmap.Session, endpoint string) {
+	base, err := url.Parse(endpoint)
+	if err != nil {
+		return
+	}
+	unescape := strings.NewReplacer("%7B", "{", "%7b", "{", "%7D", "}", "%7d", "}")
+	fix := func(raw string) string {
+		if raw == "" {
+			return raw
+		}
+		raw = unescape.Replace(raw)
+		if strings.HasPrefix(raw, "http://") || strings.HasPrefix(raw, "https://") {
+			return raw
+		}
+		// Resolve by hand: url.Parse would escape the template braces.
+		if strings.HasPrefix(raw, "/") {
+			return base.Scheme + "://" + base.Host + raw
+		}
+		dir := base.Path[:strings.LastIndex(base.Path, "/")+1]
+		return base.Scheme + "://" + base.Host + dir + raw
+	}
+	s.APIURL = fix(s.APIURL)
+	s.DownloadURL = fix(s.DownloadURL)
+	s.UploadURL = fix(s.UploadURL)
+	s.EventSourceURL = fix(s.EventSourceURL)
+}
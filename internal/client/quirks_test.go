@@ -0,0 +1,128 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail"
+
+	"github.com/cboone/fm/internal/jmap/maskedemail"
+)
+
+func TestIdentifyServer(t *testing.T) {
+	tests := []struct {
+		name    string
+		session *jmap.Session
+		header  string
+		want    string
+	}{
+		{
+			name: "fastmail capability",
+			session: &jmap.Session{RawCapabilities: map[jmap.URI]json.RawMessage{
+				maskedemail.URI: json.RawMessage("{}"),
+			}},
+			want: ServerFastmail,
+		},
+		{
+			name:    "fastmail api host",
+			session: &jmap.Session{APIURL: "https://api.fastmail.com/jmap/api/"},
+			want:    ServerFastmail,
+		},
+		{
+			name: "cyrus capability",
+			session: &jmap.Session{RawCapabilities: map[jmap.URI]json.RawMessage{
+				"https://cyrusimap.org/ns/jmap/mail": json.RawMessage("{}"),
+			}},
+			want: ServerCyrus,
+		},
+		{name: "stalwart header", session: &jmap.Session{}, header: "Stalwart", want: ServerStalwart},
+		{name: "cyrus header", session: &jmap.Session{}, header: "Cyrus-HTTP/3.8", want: ServerCyrus},
+		{name: "unknown", session: &jmap.Session{}, header: "nginx", want: ServerGeneric},
+		{name: "no session", want: ServerGeneric},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := identifyServer(tt.session, tt.header); got != tt.want {
+				t.Errorf("identifyServer() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetServer(t *testing.T) {
+	c := &Client{server: ServerStalwart}
+	if err := c.SetServer(ServerAuto); err != nil || c.Server() != ServerStalwart {
+		t.Errorf("auto should keep the detected server, got %q (err %v)", c.Server(), err)
+	}
+	if err := c.SetServer(ServerCyrus); err != nil || c.Server() != ServerCyrus {
+		t.Errorf("expected cyrus, got %q (err %v)", c.Server(), err)
+	}
+	if err := c.SetServer("exchange"); err == nil {
+		t.Error("expected an error for an unknown server")
+	}
+	if got := (&Client{}).Server(); got != ServerGeneric {
+		t.Errorf("unidentified server = %q, want generic", got)
+	}
+}
+
+func TestHeaderFilterQuirk(t *testing.T) {
+	if !(&Client{server: ServerFastmail}).headerFilter() {
+		t.Error("fastmail should support header filters")
+	}
+	if (&Client{server: ServerStalwart}).headerFilter() {
+		t.Error("stalwart should filter headers client-side")
+	}
+}
+
+func TestRequireSort(t *testing.T) {
+	c := &Client{
+		accountID: "acct-1",
+		jmap: &jmap.Client{Session: &jmap.Session{Accounts: map[jmap.ID]jmap.Account{
+			"acct-1": {Capabilities: map[jmap.URI]jmap.Capability{
+				mail.URI: &mail.Mail{EmailQuerySortOptions: []string{"receivedAt", "from"}},
+			}},
+		}}},
+	}
+	if err := c.requireSort("from"); err != nil {
+		t.Errorf("requireSort(from) = %v, want nil", err)
+	}
+	err := c.requireSort("size")
+	if !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("requireSort(size) = %v, want ErrUnsupported", err)
+	}
+	if err.Error() != "server does not support sorting by size" {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+
+	// Without advertised options every sort is attempted.
+	if err := (&Client{}).requireSort("size"); err != nil {
+		t.Errorf("requireSort without a session = %v, want nil", err)
+	}
+}
+
+func TestNormalizeSessionURLs(t *testing.T) {
+	s := &jmap.Session{
+		APIURL:      "/jmap/",
+		DownloadURL: "/jmap/download/%7BaccountId%7D/%7BblobId%7D/%7Bname%7D?accept=%7Btype%7D",
+		UploadURL:   "upload/{accountId}/",
+	}
+	normalizeSessionURLs(s, "https://mail.example.com/.well-known/jmap")
+
+	if s.APIURL != "https://mail.example.com/jmap/" {
+		t.Errorf("APIURL = %q", s.APIURL)
+	}
+	if want := "https://mail.example.com/jmap/download/{accountId}/{blobId}/{name}?accept={type}"; s.DownloadURL != want {
+		t.Errorf("DownloadURL = %q, want %q", s.DownloadURL, want)
+	}
+	if want := "https://mail.example.com/.well-known/upload/{accountId}/"; s.UploadURL != want {
+		t.Errorf("UploadURL = %q, want %q", s.UploadURL, want)
+	}
+
+	abs := &jmap.Session{DownloadURL: "https://api.fastmail.com/jmap/download/{accountId}/{blobId}/{name}?type={type}"}
+	normalizeSessionURLs(abs, "https://api.fastmail.com/jmap/session")
+	if abs.DownloadURL != "https://api.fastmail.com/jmap/download/{accountId}/{blobId}/{name}?type={type}" {
+		t.Errorf("absolute URL changed: %q", abs.DownloadURL)
+	}
+}
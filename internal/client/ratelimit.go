@@ -0,0 +1,118 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// rateLimitTransport wraps an http.RoundTripper to bound how many requests
+// are in flight at once and how fast they start, so that parallel work
+// cannot trip the server's abuse detection. A request holds its slot until
+// its response body is closed, which covers streamed downloads. The zero
+// value sends requests without limits.
+type rateLimitTransport struct {
+	base http.RoundTripper
+
+	mu       sync.Mutex
+	slots    chan struct{}
+	interval time.Duration
+	next     time.Time
+}
+
+// setLimits allows at most maxConcurrent requests in flight and perSecond
+// requests started each second. Zero disables either limit.
+func (t *rateLimitTransport) setLimits(maxConcurrent int, perSecond float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.slots = nil
+	if maxConcurrent > 0 {
+		t.slots = make(chan struct{}, maxConcurrent)
+	}
+	t.interval = 0
+	if perSecond > 0 {
+		t.interval = time.Duration(float64(time.Second) / perSecond)
+	}
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	slots := t.slots
+	var wait time.Duration
+	if t.interval > 0 {
+		now := time.Now()
+		start := now
+		if t.next.After(now) {
+			start = t.next
+		}
+		t.next = start.Add(t.interval)
+		wait = start.Sub(now)
+	}
+	t.mu.Unlock()
+
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+
+	if slots == nil {
+		return t.base.RoundTrip(req)
+	}
+	select {
+	case slots <- struct{}{}:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+	release := func() { <-slots }
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// releasingBody frees a request slot when the response body is closed.
+type releasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+// SetRateLimit bounds the requests the client has in flight at once and
+// the requests it starts each second. A maxConcurrent of zero keeps the
+// server's advertised maxConcurrentRequests, and a perSecond of zero leaves
+// the rate unlimited.
+func (c *Client) SetRateLimit(maxConcurrent int, perSecond float64) error {
+	if maxConcurrent < 0 {
+		return fmt.Errorf("max_concurrent_requests must be zero or greater, got %d", maxConcurrent)
+	}
+	if perSecond < 0 {
+		return fmt.Errorf("requests_per_second must be zero or greater, got %g", perSecond)
+	}
+	if c.limiter == nil {
+		return nil
+	}
+	if maxConcurrent == 0 {
+		if l := c.coreLimits(); l != nil {
+			maxConcurrent = int(l.MaxConcurrentRequests)
+		}
+	}
+	c.limiter.setLimits(maxConcurrent, perSecond)
+	return nil
+}
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimitTransport_BoundsConcurrency(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		inFlight.Add(-1)
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+
+	limiter := &rateLimitTransport{base: http.DefaultTransport}
+	limiter.setLimits(2, 0)
+	httpClient := &http.Client{Transport: limiter}
+
+	var wg sync.WaitGroup
+	for range 6 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := httpClient.Get(srv.URL)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	if got := peak.Load(); got > 2 {
+		t.Errorf("expected at most 2 requests in flight, saw %d", got)
+	}
+}
+
+func TestRateLimitTransport_SpacesRequests(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	limiter := &rateLimitTransport{base: http.DefaultTransport}
+	limiter.setLimits(0, 50)
+	httpClient := &http.Client{Transport: limiter}
+
+	start := time.Now()
+	for range 4 {
+		resp, err := httpClient.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+	}
+	// The first request starts at once and each later one 20ms after the
+	// one before it.
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Errorf("expected 4 requests at 50/s to take at least 60ms, took %v", elapsed)
+	}
+}
+
+func TestSetRateLimit_RejectsNegative(t *testing.T) {
+	c := &Client{limiter: &rateLimitTransport{}}
+	if err := c.SetRateLimit(-1, 0); err == nil {
+		t.Error("expected an error for a negative max_concurrent_requests")
+	}
+	if err := c.SetRateLimit(0, -0.5); err == nil {
+		t.Error("expected an error for a negative requests_per_second")
+	}
+}
@@ -14,21 +14,9 @@ import (
 // Blank import triggers sieve capability and method registration.
 var _ = sieve.URI
 
-// hasSieveCapability checks whether the server advertises sieve support.
-func (c *Client) hasSieveCapability() bool {
-	if c.jmap == nil || c.jmap.Session == nil {
-		return false
-	}
-	_, ok := c.jmap.Session.RawCapabilities[sieve.URI]
-	return ok
-}
-
 // requireSieve returns an error if the server does not support sieve.
 func (c *Client) requireSieve() error {
-	if !c.hasSieveCapability() {
-		return fmt.Errorf("server does not support sieve scripts (missing %s capability)", sieve.URI)
-	}
-	return nil
+	return c.requireCapability("sieve scripts", sieve.URI)
 }
 
 // ListSieveScripts returns all sieve scripts in the account.
@@ -126,6 +114,46 @@ func (c *Client) getSieveScriptContent(blobID jmap.ID) (string, error) {
 	return string(data), nil
 }
 
+// GetAllSieveScripts returns every sieve script in the account with its
+// content, in the order the server lists them.
+func (c *Client) GetAllSieveScripts() ([]types.SieveScriptDetail, error) {
+	if err := c.requireSieve(); err != nil {
+		return nil, err
+	}
+
+	req := &jmap.Request{}
+	req.Invoke(&sieve.Get{Account: c.accountID})
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("getting sieve scripts: %w", err)
+	}
+
+	var scripts []types.SieveScriptDetail
+	for _, inv := range resp.Responses {
+		switch r := inv.Args.(type) {
+		case *sieve.GetResponse:
+			for _, s := range r.List {
+				content, err := c.getSieveScriptContent(s.BlobID)
+				if err != nil {
+					return nil, fmt.Errorf("sieve script %s: %w", s.Name, err)
+				}
+				scripts = append(scripts, types.SieveScriptDetail{
+					ID:       string(s.ID),
+					Name:     s.Name,
+					BlobID:   string(s.BlobID),
+					IsActive: s.IsActive,
+					Content:  content,
+				})
+			}
+		case *jmap.MethodError:
+			return nil, fmt.Errorf("getting sieve scripts: %s", r.Error())
+		}
+	}
+
+	return scripts, nil
+}
+
 // CreateSieveScript uploads script content as a blob and creates a SieveScript.
 // When activate is true, the script is activated upon creation.
 func (c *Client) CreateSieveScript(name, content string, activate bool) (types.SieveCreateResult, error) {
@@ -175,6 +175,41 @@ func TestGetSieveScript_NotFound(t *testing.T) {
 	}
 }
 
+func TestGetAllSieveScripts(t *testing.T) {
+	c := sieveTestClient(func(req *jmap.Request) (*jmap.Response, error) {
+		return &jmap.Response{
+			Responses: []*jmap.Invocation{
+				{
+					Name: "SieveScript/get",
+					Args: &sieve.GetResponse{
+						List: []*sieve.SieveScript{
+							{ID: "S1", Name: "Block spam", BlobID: "B1", IsActive: true},
+							{ID: "S2", Name: "Custom filter", BlobID: "B2"},
+						},
+					},
+				},
+			},
+		}, nil
+	})
+	c.downloadFunc = func(accountID, blobID jmap.ID) (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader("# " + string(blobID) + "\nkeep;\n")), nil
+	}
+
+	scripts, err := c.GetAllSieveScripts()
+	if err != nil {
+		t.Fatalf("GetAllSieveScripts() error: %v", err)
+	}
+	if len(scripts) != 2 {
+		t.Fatalf("GetAllSieveScripts() returned %d scripts, want 2", len(scripts))
+	}
+	if scripts[1].Name != "Custom filter" || scripts[1].Content != "# B2\nkeep;\n" {
+		t.Errorf("GetAllSieveScripts() scripts[1] = %+v, want Custom filter with B2 content", scripts[1])
+	}
+	if !scripts[0].IsActive {
+		t.Error("GetAllSieveScripts() scripts[0].IsActive = false, want true")
+	}
+}
+
 func TestCreateSieveScript(t *testing.T) {
 	c := sieveTestClient(func(req *jmap.Request) (*jmap.Response, error) {
 		return &jmap.Response{
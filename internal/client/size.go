@@ -0,0 +1,95 @@
+package client
+
+import (
+	"fmt"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+
+	"github.com/cboone/fm/internal/mimeword"
+	"github.com/cboone/fm/internal/types"
+)
+
+// SizeOptions holds parameters for the largest-messages report.
+type SizeOptions struct {
+	MailboxID string
+	Limit     uint64
+}
+
+// sizeProperties are the Email/get properties for the largest-messages
+// report. Attachments are fetched only to be counted.
+var sizeProperties = []string{"id", "threadId", "from", "subject", "receivedAt", "size", "attachments"}
+
+// LargestEmails returns the largest emails, optionally within one mailbox,
+// using a server-side sort by size.
+func (c *Client) LargestEmails(opts SizeOptions) (types.SizeResult, error) {
+	if err := c.requireSort("size"); err != nil {
+		return types.SizeResult{}, err
+	}
+	var filter email.Filter
+	if opts.MailboxID != "" {
+		filter = &email.FilterCondition{InMailbox: jmap.ID(opts.MailboxID)}
+	}
+
+	result := types.SizeResult{Emails: []types.SizedEmail{}}
+	err := c.windows(0, opts.Limit, func(page *queryPage, limit uint64) ([]jmap.ID, error) {
+		req := &jmap.Request{}
+		queryCallID := req.Invoke(&email.Query{
+			Account:        c.accountID,
+			Filter:         filter,
+			Sort:           []*email.SortComparator{{Property: "size", IsAscending: false}},
+			Position:       page.position,
+			Anchor:         page.anchor,
+			AnchorOffset:   page.anchorOffset(),
+			Limit:          limit,
+			CalculateTotal: true,
+		})
+		req.Invoke(&email.Get{
+			Account:        c.accountID,
+			Properties:     sizeProperties,
+			BodyProperties: []string{"partId", "type", "cid", "disposition"},
+			ReferenceIDs: &jmap.ResultReference{
+				ResultOf: queryCallID,
+				Name:     "Email/query",
+				Path:     "/ids",
+			},
+		})
+
+		resp, err := c.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("size query: %w", err)
+		}
+
+		var ids []jmap.ID
+		for _, inv := range resp.Responses {
+			switch r := inv.Args.(type) {
+			case *email.QueryResponse:
+				result.Total = r.Total
+				ids = r.IDs
+			case *email.GetResponse:
+				for _, e := range r.List {
+					result.Emails = append(result.Emails, types.SizedEmail{
+						ID:          string(e.ID),
+						ThreadID:    string(e.ThreadID),
+						From:        convertAddresses(e.From),
+						Subject:     mimeword.Decode(e.Subject),
+						ReceivedAt:  safeTime(e.ReceivedAt),
+						Size:        e.Size,
+						Attachments: countAttachments(e.Attachments),
+					})
+					result.ListedSize += e.Size
+				}
+			case *jmap.MethodError:
+				if page.anchorLost(r) {
+					return nil, errAnchorLost
+				}
+				return nil, fmt.Errorf("size query: %s", r.Error())
+			}
+		}
+		return ids, nil
+	})
+	if err != nil {
+		return types.SizeResult{}, err
+	}
+	return result, nil
+}
@@ -0,0 +1,50 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+)
+
+func TestLargestEmails(t *testing.T) {
+	received := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	var query *email.Query
+	c := &Client{
+		accountID: "test-account",
+		doFunc: func(req *jmap.Request) (*jmap.Response, error) {
+			query = req.Calls[0].Args.(*email.Query)
+			return &jmap.Response{Responses: []*jmap.Invocation{
+				{Name: "Email/query", CallID: "0", Args: &email.QueryResponse{Total: 40, IDs: []jmap.ID{"M1", "M2"}}},
+				{Name: "Email/get", CallID: "1", Args: &email.GetResponse{List: []*email.Email{
+					{ID: "M1", ThreadID: "T1", Subject: "Photos", Size: 9000000, ReceivedAt: &received,
+						Attachments: []*email.BodyPart{{PartID: "2"}, {PartID: "3"}}},
+					{ID: "M2", ThreadID: "T2", Subject: "Slides", Size: 4000000, ReceivedAt: &received},
+				}}},
+			}}, nil
+		},
+	}
+
+	result, err := c.LargestEmails(SizeOptions{MailboxID: "mb-archive", Limit: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(query.Sort) != 1 || query.Sort[0].Property != "size" || query.Sort[0].IsAscending {
+		t.Errorf("expected a descending size sort, got %+v", query.Sort)
+	}
+	if query.Limit != 2 {
+		t.Errorf("expected limit 2, got %d", query.Limit)
+	}
+	if fc, ok := query.Filter.(*email.FilterCondition); !ok || fc.InMailbox != "mb-archive" {
+		t.Errorf("expected an inMailbox filter, got %+v", query.Filter)
+	}
+
+	if result.Total != 40 || result.ListedSize != 13000000 || len(result.Emails) != 2 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if result.Emails[0].Attachments != 2 || result.Emails[1].Attachments != 0 {
+		t.Errorf("expected attachment counts 2 and 0, got %+v", result.Emails)
+	}
+}
@@ -0,0 +1,125 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+	"git.sr.ht/~rockorager/go-jmap/mail/mailbox"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+// RoleSnoozed is the mailbox role Fastmail gives the mailbox holding snoozed
+// mail. go-jmap does not define it.
+const RoleSnoozed mailbox.Role = "snoozed"
+
+// ListSnoozedEmails lists emails in the snoozed mailbox and fills in each
+// email's wake-up time from its snoozed property.
+func (c *Client) ListSnoozedEmails(opts ListOptions) (types.EmailListResult, error) {
+	mb, err := c.GetMailboxByRole(RoleSnoozed)
+	if err != nil {
+		return types.EmailListResult{}, err
+	}
+	opts.MailboxNameOrID = string(mb.ID)
+
+	result, err := c.ListEmails(opts)
+	if err != nil {
+		return types.EmailListResult{}, err
+	}
+	if len(result.Emails) == 0 {
+		return result, nil
+	}
+
+	ids := make([]string, len(result.Emails))
+	for i, e := range result.Emails {
+		ids[i] = e.ID
+	}
+	until, err := c.fetchSnoozeTimes(ids)
+	if err != nil {
+		return types.EmailListResult{}, err
+	}
+	for i := range result.Emails {
+		if t, ok := until[result.Emails[i].ID]; ok {
+			result.Emails[i].SnoozedUntil = &t
+		}
+	}
+	return result, nil
+}
+
+// CountSnoozed returns the number of emails in the snoozed mailbox, or zero
+// if the account has none.
+func (c *Client) CountSnoozed() (uint64, error) {
+	mailboxes, err := c.GetAllMailboxes()
+	if err != nil {
+		return 0, err
+	}
+	for _, mb := range mailboxes {
+		if mb.Role == RoleSnoozed {
+			return mb.TotalEmails, nil
+		}
+	}
+	return 0, nil
+}
+
+// fetchSnoozeTimes returns the wake-up time of each snoozed email in ids.
+// Emails without a snoozed property are omitted. The IDs are fetched in
+// batches of at most the server's MaxObjectsInGet.
+func (c *Client) fetchSnoozeTimes(ids []string) (map[string]time.Time, error) {
+	until := make(map[string]time.Time)
+
+	size := c.maxGetSize()
+	for start := 0; start < len(ids); start += size {
+		end := min(start+size, len(ids))
+
+		jmapIDs := make([]jmap.ID, 0, end-start)
+		for _, id := range ids[start:end] {
+			jmapIDs = append(jmapIDs, jmap.ID(id))
+		}
+
+		req := &jmap.Request{}
+		req.Invoke(&email.Get{
+			Account:    c.accountID,
+			IDs:        jmapIDs,
+			Properties: []string{"id", "snoozed"},
+		})
+
+		responses, err := c.doRaw(req)
+		if err != nil {
+			return nil, fmt.Errorf("email/get snoozed: %w", err)
+		}
+
+		for _, r := range responses {
+			var name string
+			if err := json.Unmarshal(r[0], &name); err != nil {
+				return nil, fmt.Errorf("email/get snoozed: %w", err)
+			}
+			switch name {
+			case "Email/get":
+				var args struct {
+					List []struct {
+						ID      string `json:"id"`
+						Snoozed *struct {
+							Until time.Time `json:"until"`
+						} `json:"snoozed"`
+					} `json:"list"`
+				}
+				if err := json.Unmarshal(r[1], &args); err != nil {
+					return nil, fmt.Errorf("email/get snoozed: %w", err)
+				}
+				for _, e := range args.List {
+					if e.Snoozed != nil {
+						until[e.ID] = e.Snoozed.Until
+					}
+				}
+			case "error":
+				var methodErr jmap.MethodError
+				_ = json.Unmarshal(r[1], &methodErr)
+				return nil, fmt.Errorf("email/get snoozed: %s", methodErr.Error())
+			}
+		}
+	}
+	return until, nil
+}
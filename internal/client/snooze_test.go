@@ -0,0 +1,57 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestFetchSnoozeTimes_BatchesByMaxObjectsInGet(t *testing.T) {
+	var batches []int
+	srv := newTestServer(t, "", nil, func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			MethodCalls [][3]json.RawMessage `json:"methodCalls"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decoding request: %v", err)
+		}
+		var args struct {
+			IDs []string `json:"ids"`
+		}
+		if err := json.Unmarshal(req.MethodCalls[0][1], &args); err != nil {
+			t.Fatalf("decoding Email/get: %v", err)
+		}
+		batches = append(batches, len(args.IDs))
+
+		list := make([]map[string]any, 0, len(args.IDs))
+		for _, id := range args.IDs {
+			list = append(list, map[string]any{"id": id, "snoozed": map[string]any{"until": "2026-02-20T09:00:00Z"}})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"methodResponses": []any{[]any{"Email/get", map[string]any{"list": list}, "0"}},
+			"sessionState":    "s1",
+		})
+	})
+
+	c, err := New(srv.URL+"/session", "test-token", "")
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	ids := make([]string, defaultBatchSize+1)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("M%d", i)
+	}
+
+	until, err := c.fetchSnoozeTimes(ids)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(batches) != 2 || batches[0] != defaultBatchSize || batches[1] != 1 {
+		t.Errorf("expected Email/get batches of %d and 1, got %v", defaultBatchSize, batches)
+	}
+	if len(until) != len(ids) {
+		t.Errorf("expected a wake-up time for each of %d emails, got %d", len(ids), len(until))
+	}
+}
@@ -0,0 +1,66 @@
+package client
+
+import (
+	"fmt"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+	"git.sr.ht/~rockorager/go-jmap/mail/mailbox"
+	"git.sr.ht/~rockorager/go-jmap/mail/thread"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+// States returns the current session state and the Email, Mailbox, and
+// Thread state strings of the account. The Email and Thread states come
+// from getting the newest email and its thread, since a get with no IDs
+// would return every object.
+func (c *Client) States() (types.StateResult, error) {
+	req := &jmap.Request{}
+	queryCallID := req.Invoke(&email.Query{
+		Account: c.accountID,
+		Sort:    []*email.SortComparator{{Property: "receivedAt", IsAscending: false}},
+		Limit:   1,
+	})
+	getCallID := req.Invoke(&email.Get{
+		Account:    c.accountID,
+		Properties: []string{"id", "threadId"},
+		ReferenceIDs: &jmap.ResultReference{
+			ResultOf: queryCallID,
+			Name:     "Email/query",
+			Path:     "/ids",
+		},
+	})
+	req.Invoke(&thread.Get{
+		Account: c.accountID,
+		ReferenceIDs: &jmap.ResultReference{
+			ResultOf: getCallID,
+			Name:     "Email/get",
+			Path:     "/list/*/threadId",
+		},
+	})
+	req.Invoke(&mailbox.Get{
+		Account:    c.accountID,
+		Properties: []string{"id"},
+	})
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return types.StateResult{}, fmt.Errorf("state: %w", err)
+	}
+
+	result := types.StateResult{Session: c.Session().State}
+	for _, inv := range resp.Responses {
+		switch r := inv.Args.(type) {
+		case *email.GetResponse:
+			result.Email = r.State
+		case *thread.GetResponse:
+			result.Thread = r.State
+		case *mailbox.GetResponse:
+			result.Mailbox = r.State
+		case *jmap.MethodError:
+			return types.StateResult{}, fmt.Errorf("state: %s", r.Error())
+		}
+	}
+	return result, nil
+}
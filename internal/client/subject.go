@@ -0,0 +1,68 @@
+package client
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// subjectPrefix matches one reply or forward prefix at the start of a
+// subject: Re, Fwd, and their equivalents in the languages mail clients
+// commonly localize them into, with an optional counter ("Re[2]:",
+// "Re^3:") and a space before the colon as Outlook writes in French
+// ("RE :"). The colon may be full-width, as in Chinese and Japanese.
+var subjectPrefix = regexp.MustCompile(`(?i)^\s*(?:re|fwd?|aw|wg|sv|vs|vb|antw|doorst|rif|r|res|enc|tr|rv|réf|odp|pd|ynt|ilt|atb|vl|fs|回复|回覆|答复|转发|轉寄)\s*(?:\[\d+\]|\^\d+)?\s*[:：]\s*`)
+
+// ticketNoise matches ticket and case numbers that helpdesks add to
+// subjects, such as "[#12345]", "(Case 00123)", or "[Ticket #ABC-42]", and
+// that change from one message of a conversation to the next.
+var ticketNoise = regexp.MustCompile(`(?i)[\[(]\s*(?:(?:ticket|case|request|issue|incident|req)\s*)?#?\s*(?:[a-z]+-)?\d+\s*[\])]|\b(?:ticket|case|request)\s*#\s*\d+`)
+
+// stripSubjectPrefixes removes any run of reply and forward prefixes from
+// subject, so that "Re: AW: Fwd: Budget" becomes "Budget".
+func stripSubjectPrefixes(subject string) string {
+	s := strings.TrimSpace(subject)
+	for {
+		loc := subjectPrefix.FindStringIndex(s)
+		if loc == nil || loc[1] == len(s) {
+			return s
+		}
+		s = s[loc[1]:]
+	}
+}
+
+// subjectKey normalizes subject for grouping: prefixes and ticket numbers
+// are removed, whitespace is collapsed, and case is folded.
+func subjectKey(subject string) string {
+	s := ticketNoise.ReplaceAllString(stripSubjectPrefixes(subject), " ")
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+// subjectGroups collects the distinct conversations among a set of
+// subjects. Each group is shown by the first subject added to it, without
+// its prefixes.
+type subjectGroups map[string]string
+
+func (g subjectGroups) add(subject string) {
+	key := subjectKey(subject)
+	if key == "" {
+		return
+	}
+	if _, ok := g[key]; !ok {
+		g[key] = stripSubjectPrefixes(subject)
+	}
+}
+
+// sorted returns the subject shown for each group in alphabetical order,
+// or nil if there are none.
+func (g subjectGroups) sorted() []string {
+	if len(g) == 0 {
+		return nil
+	}
+	subjects := make([]string, 0, len(g))
+	for _, s := range g {
+		subjects = append(subjects, s)
+	}
+	sort.Strings(subjects)
+	return subjects
+}
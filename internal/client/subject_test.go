@@ -0,0 +1,62 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStripSubjectPrefixes(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Budget", "Budget"},
+		{"Re: Budget", "Budget"},
+		{"RE : Budget", "Budget"},
+		{"Re: AW: Fwd: Budget", "Budget"},
+		{"SV: VS: Budget", "Budget"},
+		{"Re[2]: Budget", "Budget"},
+		{"WG: Antw: Budget", "Budget"},
+		{"回复：预算", "预算"},
+		{"Re:", "Re:"},
+		{"PR: fix the build", "PR: fix the build"},
+		{"Regarding: Budget", "Regarding: Budget"},
+	}
+	for _, tt := range tests {
+		if got := stripSubjectPrefixes(tt.in); got != tt.want {
+			t.Errorf("stripSubjectPrefixes(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSubjectKey(t *testing.T) {
+	same := []string{
+		"Printer is broken",
+		"Re: Printer is broken [#12345]",
+		"AW: [Ticket #12346] Printer  is broken",
+		"RE : printer is broken (Case 00123)",
+		"Fwd: Printer is broken [HELP-42]",
+	}
+	want := "printer is broken"
+	for _, s := range same {
+		if got := subjectKey(s); got != want {
+			t.Errorf("subjectKey(%q) = %q, want %q", s, got, want)
+		}
+	}
+	if got := subjectKey("Invoice 1042"); got != "invoice 1042" {
+		t.Errorf("subjectKey kept a bare number as noise: %q", got)
+	}
+}
+
+func TestSubjectGroups(t *testing.T) {
+	g := make(subjectGroups)
+	for _, s := range []string{"Re: Budget", "Budget", "AW: budget", "Offsite", "", "Re: "} {
+		g.add(s)
+	}
+	want := []string{"Budget", "Offsite", "Re:"}
+	if got := g.sorted(); !reflect.DeepEqual(got, want) {
+		t.Errorf("sorted() = %q, want %q", got, want)
+	}
+	if got := make(subjectGroups).sorted(); got != nil {
+		t.Errorf("sorted() of no subjects = %q, want nil", got)
+	}
+}
@@ -0,0 +1,111 @@
+package client
+
+import (
+	"fmt"
+	"time"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+
+	"github.com/cboone/fm/internal/travel"
+	"github.com/cboone/fm/internal/types"
+)
+
+// travelScanLimit bounds how many booking emails TravelReservations looks
+// at.
+const travelScanLimit = 200
+
+// travelTerms are the words that a booking email is searched for by; an
+// email matching any of them is read.
+var travelTerms = []string{"reservation", "booking", "itinerary", "flight", "hotel", "check-in"}
+
+// TravelReservations returns the flight and hotel reservations described
+// by the markup of the most recent booking emails received since since,
+// newest email first. Of several emails about the same booking, the newest
+// counts, and bookings it marks as cancelled are left out.
+func (c *Client) TravelReservations(since time.Time) ([]types.TravelReservation, error) {
+	conditions := make([]email.Filter, len(travelTerms))
+	for i, term := range travelTerms {
+		conditions[i] = &email.FilterCondition{Text: term}
+	}
+	req := &jmap.Request{}
+	req.Invoke(&email.Query{
+		Account: c.accountID,
+		Filter: &email.FilterOperator{
+			Operator: jmap.OperatorAND,
+			Conditions: []email.Filter{
+				&email.FilterCondition{After: &since},
+				&email.FilterOperator{Operator: jmap.OperatorOR, Conditions: conditions},
+			},
+		},
+		Sort:  []*email.SortComparator{{Property: "receivedAt", IsAscending: false}},
+		Limit: travelScanLimit,
+	})
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("email/query: %w", err)
+	}
+
+	var ids []string
+	for _, inv := range resp.Responses {
+		switch r := inv.Args.(type) {
+		case *email.QueryResponse:
+			ids = idStrings(r.IDs)
+		case *jmap.MethodError:
+			return nil, fmt.Errorf("email/query: %s", r.Error())
+		}
+	}
+
+	bodies, err := c.GetHTMLBodies(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	reservations := []types.TravelReservation{}
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		for _, r := range travel.Parse(bodies[id]) {
+			key := reservationKey(r)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			if r.Cancelled {
+				continue
+			}
+			reservations = append(reservations, travelReservation(id, r))
+		}
+	}
+	return reservations, nil
+}
+
+// reservationKey identifies the booking r describes across the emails
+// about it: by reservation number and flight, or, without a number, by
+// what was booked and when.
+func reservationKey(r travel.Reservation) string {
+	if r.ReservationID != "" {
+		return r.Kind + "\x00" + r.ReservationID + "\x00" + r.FlightNumber
+	}
+	return r.Kind + "\x00" + r.FlightNumber + "\x00" + r.Name + "\x00" + r.Start.UTC().Format(time.RFC3339)
+}
+
+// travelReservation converts r, found in the email emailID.
+func travelReservation(emailID string, r travel.Reservation) types.TravelReservation {
+	tr := types.TravelReservation{
+		EmailID:       emailID,
+		Kind:          r.Kind,
+		ReservationID: r.ReservationID,
+		Provider:      r.Provider,
+		Start:         r.Start,
+		FlightNumber:  r.FlightNumber,
+		From:          r.From,
+		To:            r.To,
+		Name:          r.Name,
+		Address:       r.Address,
+	}
+	if !r.End.IsZero() {
+		end := r.End
+		tr.End = &end
+	}
+	return tr
+}
@@ -0,0 +1,210 @@
+package client
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+	"git.sr.ht/~rockorager/go-jmap/mail/thread"
+
+	"github.com/cboone/fm/internal/mimeword"
+	"github.com/cboone/fm/internal/types"
+)
+
+// unansweredScanLimit bounds how many of a mailbox's most recent
+// conversations UnansweredThreads looks at.
+const unansweredScanLimit = 500
+
+// UnansweredThreads finds the conversations in mailboxID that are waiting on
+// a reply: the last message, leaving out drafts, came from someone other
+// than the account's own addresses, arrived before cutoff, and is not
+// marked $answered. Muted threads are skipped. The threads are returned
+// longest waiting first, with their wait measured up to now.
+func (c *Client) UnansweredThreads(mailboxID jmap.ID, cutoff, now time.Time) ([]types.UnansweredThread, error) {
+	threadIDs, err := c.threadsReceivedBefore(mailboxID, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	if len(threadIDs) == 0 {
+		return []types.UnansweredThread{}, nil
+	}
+
+	size := c.maxGetSize()
+	var emailIDs []jmap.ID
+	for start := 0; start < len(threadIDs); start += size {
+		req := &jmap.Request{}
+		req.Invoke(&thread.Get{
+			Account:    c.accountID,
+			IDs:        threadIDs[start:min(start+size, len(threadIDs))],
+			Properties: []string{"id", "emailIds"},
+		})
+		resp, err := c.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("thread/get: %w", err)
+		}
+		for _, inv := range resp.Responses {
+			switch r := inv.Args.(type) {
+			case *thread.GetResponse:
+				for _, t := range r.List {
+					emailIDs = append(emailIDs, t.EmailIDs...)
+				}
+			case *jmap.MethodError:
+				return nil, fmt.Errorf("thread/get: %s", r.Error())
+			}
+		}
+	}
+
+	members := make(map[jmap.ID][]*email.Email)
+	for start := 0; start < len(emailIDs); start += size {
+		req := &jmap.Request{}
+		req.Invoke(&email.Get{
+			Account:    c.accountID,
+			IDs:        emailIDs[start:min(start+size, len(emailIDs))],
+			Properties: []string{"id", "threadId", "from", "subject", "receivedAt", "keywords"},
+		})
+		resp, err := c.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("email/get: %w", err)
+		}
+		for _, inv := range resp.Responses {
+			switch r := inv.Args.(type) {
+			case *email.GetResponse:
+				for _, e := range r.List {
+					members[e.ThreadID] = append(members[e.ThreadID], e)
+				}
+			case *jmap.MethodError:
+				return nil, fmt.Errorf("email/get: %s", r.Error())
+			}
+		}
+	}
+
+	own := c.ownAddresses()
+	threads := []types.UnansweredThread{}
+	for _, id := range threadIDs {
+		if t, ok := unansweredThread(members[id], own, cutoff, now); ok {
+			threads = append(threads, t)
+		}
+	}
+	sort.SliceStable(threads, func(i, j int) bool {
+		return threads[i].ReceivedAt.Before(threads[j].ReceivedAt)
+	})
+	return threads, nil
+}
+
+// threadsReceivedBefore returns the threads of the most recent
+// conversations in mailboxID with an email received before cutoff.
+func (c *Client) threadsReceivedBefore(mailboxID jmap.ID, cutoff time.Time) ([]jmap.ID, error) {
+	req := &jmap.Request{}
+	queryCallID := req.Invoke(&email.Query{
+		Account:         c.accountID,
+		Filter:          &email.FilterCondition{InMailbox: mailboxID, Before: &cutoff},
+		Sort:            []*email.SortComparator{{Property: "receivedAt", IsAscending: false}},
+		CollapseThreads: true,
+		Limit:           unansweredScanLimit,
+	})
+	req.Invoke(&email.Get{
+		Account:    c.accountID,
+		Properties: []string{"id", "threadId"},
+		ReferenceIDs: &jmap.ResultReference{
+			ResultOf: queryCallID,
+			Name:     "Email/query",
+			Path:     "/ids",
+		},
+	})
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unanswered query: %w", err)
+	}
+
+	var threadIDs []jmap.ID
+	seen := make(map[jmap.ID]bool)
+	for _, inv := range resp.Responses {
+		switch r := inv.Args.(type) {
+		case *email.GetResponse:
+			for _, e := range r.List {
+				if !seen[e.ThreadID] {
+					seen[e.ThreadID] = true
+					threadIDs = append(threadIDs, e.ThreadID)
+				}
+			}
+		case *jmap.MethodError:
+			return nil, fmt.Errorf("unanswered query: %s", r.Error())
+		}
+	}
+	return threadIDs, nil
+}
+
+// unansweredThread reports whether the thread made of emails is waiting on
+// a reply, and describes it if so.
+func unansweredThread(emails []*email.Email, own []string, cutoff, now time.Time) (types.UnansweredThread, bool) {
+	var last *email.Email
+	messages := 0
+	for _, e := range emails {
+		if e.Keywords[MutedKeyword] {
+			return types.UnansweredThread{}, false
+		}
+		if e.Keywords["$draft"] {
+			continue
+		}
+		messages++
+		if last == nil || safeTime(e.ReceivedAt).After(safeTime(last.ReceivedAt)) {
+			last = e
+		}
+	}
+	if last == nil || last.Keywords["$answered"] || !safeTime(last.ReceivedAt).Before(cutoff) {
+		return types.UnansweredThread{}, false
+	}
+	for _, a := range last.From {
+		if isOwnAddress(a.Email, own) {
+			return types.UnansweredThread{}, false
+		}
+	}
+
+	receivedAt := safeTime(last.ReceivedAt)
+	return types.UnansweredThread{
+		ThreadID:    string(last.ThreadID),
+		EmailID:     string(last.ID),
+		From:        convertAddresses(last.From),
+		Subject:     mimeword.Decode(last.Subject),
+		ReceivedAt:  receivedAt,
+		WaitingDays: int(now.Sub(receivedAt).Hours() / 24),
+		Messages:    messages,
+	}, true
+}
+
+// ownAddresses returns the account's own addresses in lower case: the
+// session username and the email of each identity. An identity for a
+// whole domain is kept as "*@domain". Identities are left out when the
+// server does not offer them.
+func (c *Client) ownAddresses() []string {
+	var own []string
+	if c.jmap != nil && c.jmap.Session != nil && strings.Contains(c.jmap.Session.Username, "@") {
+		own = append(own, strings.ToLower(c.jmap.Session.Username))
+	}
+	if identities, err := c.GetAllIdentities(); err == nil {
+		for _, id := range identities {
+			own = append(own, strings.ToLower(id.Email))
+		}
+	}
+	return own
+}
+
+// isOwnAddress reports whether addr is one of own, counting a "*@domain"
+// entry as every address at the domain.
+func isOwnAddress(addr string, own []string) bool {
+	addr = strings.ToLower(addr)
+	for _, o := range own {
+		if domain, ok := strings.CutPrefix(o, "*@"); ok {
+			if strings.HasSuffix(addr, "@"+domain) {
+				return true
+			}
+		} else if addr == o {
+			return true
+		}
+	}
+	return false
+}
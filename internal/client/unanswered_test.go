@@ -0,0 +1,72 @@
+package client
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cboone/fm/internal/mockserver"
+)
+
+func TestUnansweredThreads(t *testing.T) {
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	daysAgo := func(n int) string { return now.AddDate(0, 0, -n).Format(time.RFC3339) }
+	from := func(addr string) []any { return []any{map[string]any{"email": addr}} }
+	inbox := map[string]any{"mb-inbox": true}
+	msg := func(id, thread, sender string, age int, keywords map[string]any) map[string]any {
+		return map[string]any{
+			"id": id, "threadId": thread, "subject": "About " + thread, "receivedAt": daysAgo(age),
+			"from": from(sender), "mailboxIds": inbox, "keywords": keywords,
+		}
+	}
+
+	mock := mockserver.New(
+		[]map[string]any{{"id": "mb-inbox", "name": "Inbox", "role": "inbox"}},
+		[]map[string]any{
+			msg("M1", "T1", "alice@example.com", 10, map[string]any{}),
+			msg("M2", "T2", "bob@example.com", 10, map[string]any{}),
+			msg("M3", "T2", "Mock@Example.com", 9, map[string]any{"$seen": true}),
+			msg("M4", "T3", "carol@example.com", 1, map[string]any{}),
+			msg("M5", "T4", "dave@example.com", 20, map[string]any{}),
+			msg("M6", "T4", "mock@example.com", 5, map[string]any{"$draft": true}),
+			msg("M7", "T5", "erin@example.com", 8, map[string]any{"$answered": true}),
+			msg("M8", "T6", "frank@example.com", 8, map[string]any{MutedKeyword: true}),
+		},
+	)
+	server := httptest.NewServer(mock)
+	defer server.Close()
+
+	c, err := New(server.URL+mockserver.SessionPath, "mock-token", "")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	threads, err := c.UnansweredThreads("mb-inbox", now.AddDate(0, 0, -3), now)
+	if err != nil {
+		t.Fatalf("UnansweredThreads: %v", err)
+	}
+	if len(threads) != 2 {
+		t.Fatalf("expected two unanswered threads, got %+v", threads)
+	}
+	if threads[0].ThreadID != "T4" || threads[0].EmailID != "M5" || threads[0].WaitingDays != 20 || threads[0].Messages != 1 {
+		t.Errorf("expected T4 waiting 20 days without its draft, got %+v", threads[0])
+	}
+	if threads[1].ThreadID != "T1" || threads[1].From[0].Email != "alice@example.com" || threads[1].WaitingDays != 10 {
+		t.Errorf("expected T1 from alice waiting 10 days, got %+v", threads[1])
+	}
+}
+
+func TestIsOwnAddress(t *testing.T) {
+	own := []string{"me@example.com", "*@me.dev"}
+	for addr, want := range map[string]any{
+		"ME@example.com":     true,
+		"anything@me.dev":    true,
+		"me@example.org":     false,
+		"someone@notme.dev":  false,
+		"someone@sub.me.dev": false,
+	} {
+		if got := isOwnAddress(addr, own); got != want {
+			t.Errorf("isOwnAddress(%q) = %v, want %v", addr, got, want)
+		}
+	}
+}
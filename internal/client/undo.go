@@ -0,0 +1,85 @@
+package client
+
+import (
+	"fmt"
+	"sort"
+
+	"git.sr.ht/~rockorager/go-jmap"
+)
+
+// GetMailboxIDs returns the mailbox IDs each of the given emails is in,
+// keyed by email ID, for recording in the undo journal before a move.
+// Emails that are not found are left out.
+func (c *Client) GetMailboxIDs(ids []string) (map[string][]string, error) {
+	states, err := c.GetEmailStates(ids)
+	if err != nil {
+		return nil, err
+	}
+	return MailboxIDsOf(states), nil
+}
+
+// MailboxIDsOf returns the mailbox IDs of each email in states, as
+// GetMailboxIDs does.
+func MailboxIDsOf(states map[string]EmailState) map[string][]string {
+	result := make(map[string][]string, len(states))
+	for id, s := range states {
+		result[id] = s.MailboxIDs
+	}
+	return result
+}
+
+// RestoreMailboxes puts each email back in the mailboxes recorded for it.
+// It refuses to restore any email into a trash mailbox, reporting those as
+// failed, so undo cannot be used to delete. It returns succeeded and failed
+// email IDs.
+func (c *Client) RestoreMailboxes(previous map[string][]string) ([]string, []string) {
+	mailboxes, err := c.GetAllMailboxes()
+	if err != nil {
+		failed := make([]string, 0, len(previous))
+		for id := range previous {
+			failed = append(failed, fmt.Sprintf("%s: %v", id, err))
+		}
+		sort.Strings(failed)
+		return []string{}, failed
+	}
+	forbidden := make(map[string]error)
+	for _, mb := range mailboxes {
+		if err := ValidateTargetMailbox(mb); err != nil {
+			forbidden[string(mb.ID)] = err
+		}
+	}
+
+	ids := make([]string, 0, len(previous))
+	failed := []string{}
+	for id, mailboxIDs := range previous {
+		if blocked := firstForbidden(mailboxIDs, forbidden); blocked != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", id, blocked))
+			continue
+		}
+		if len(mailboxIDs) == 0 {
+			failed = append(failed, fmt.Sprintf("%s: no mailboxes recorded", id))
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	sort.Strings(failed)
+
+	succeeded, errors := c.batchSetEmails(ids, func(id string) jmap.Patch {
+		mailboxIDs := make(map[jmap.ID]bool, len(previous[id]))
+		for _, mb := range previous[id] {
+			mailboxIDs[jmap.ID(mb)] = true
+		}
+		return jmap.Patch{"mailboxIds": mailboxIDs}
+	})
+	return succeeded, append(failed, errors...)
+}
+
+func firstForbidden(mailboxIDs []string, forbidden map[string]error) error {
+	for _, id := range mailboxIDs {
+		if err, ok := forbidden[id]; ok {
+			return err
+		}
+	}
+	return nil
+}
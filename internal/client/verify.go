@@ -0,0 +1,116 @@
+package client
+
+import (
+	"fmt"
+
+	"git.sr.ht/~rockorager/go-jmap"
+	"git.sr.ht/~rockorager/go-jmap/mail/email"
+	"git.sr.ht/~rockorager/go-jmap/mail/mailbox"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+// VerifyMailboxCounts compares the totalEmails and unreadEmails the server
+// reports for each of ids (every mailbox when ids is empty) with the number
+// of emails Email/query counts in it. Unread follows RFC 8621: emails with
+// neither $seen nor $draft. Each mailbox's counts are fetched in the same
+// request as its queries, so mail arriving meanwhile does not show up as a
+// discrepancy.
+func (c *Client) VerifyMailboxCounts(ids []jmap.ID) ([]types.MailboxCountCheck, error) {
+	if len(ids) == 0 {
+		mailboxes, err := c.GetAllMailboxes()
+		if err != nil {
+			return nil, err
+		}
+		for _, mb := range mailboxes {
+			ids = append(ids, mb.ID)
+		}
+	}
+
+	// One Mailbox/get plus two queries per mailbox in each request.
+	perRequest := max((c.maxCallsInRequest()-1)/2, 1)
+	checks := make([]types.MailboxCountCheck, 0, len(ids))
+	for start := 0; start < len(ids); start += perRequest {
+		chunk, err := c.verifyMailboxChunk(ids[start:min(start+perRequest, len(ids))])
+		if err != nil {
+			return nil, err
+		}
+		checks = append(checks, chunk...)
+	}
+	return checks, nil
+}
+
+func (c *Client) verifyMailboxChunk(ids []jmap.ID) ([]types.MailboxCountCheck, error) {
+	req := &jmap.Request{}
+	getCallID := req.Invoke(&mailbox.Get{
+		Account:    c.accountID,
+		IDs:        ids,
+		Properties: []string{"id", "name", "totalEmails", "unreadEmails"},
+	})
+	totalCallIDs := make(map[string]jmap.ID, len(ids))
+	unreadCallIDs := make(map[string]jmap.ID, len(ids))
+	for _, id := range ids {
+		totalCallIDs[req.Invoke(&email.Query{
+			Account:        c.accountID,
+			Filter:         &email.FilterCondition{InMailbox: id},
+			Limit:          1,
+			CalculateTotal: true,
+		})] = id
+		unreadCallIDs[req.Invoke(&email.Query{
+			Account: c.accountID,
+			Filter: &email.FilterOperator{
+				Operator: jmap.OperatorAND,
+				Conditions: []email.Filter{
+					&email.FilterCondition{InMailbox: id, NotKeyword: "$seen"},
+					&email.FilterCondition{NotKeyword: "$draft"},
+				},
+			},
+			Limit:          1,
+			CalculateTotal: true,
+		})] = id
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("mailbox verify: %w", err)
+	}
+
+	byID := make(map[jmap.ID]*types.MailboxCountCheck, len(ids))
+	checks := make([]types.MailboxCountCheck, len(ids))
+	for i, id := range ids {
+		checks[i].ID = string(id)
+		byID[id] = &checks[i]
+	}
+	for _, inv := range resp.Responses {
+		switch r := inv.Args.(type) {
+		case *mailbox.GetResponse:
+			if inv.CallID != getCallID {
+				continue
+			}
+			for _, mb := range r.List {
+				if check := byID[mb.ID]; check != nil {
+					check.Name = mb.Name
+					check.ReportedTotal = mb.TotalEmails
+					check.ReportedUnread = mb.UnreadEmails
+				}
+			}
+			if len(r.NotFound) > 0 {
+				return nil, fmt.Errorf("mailbox verify: mailbox %s not found", r.NotFound[0])
+			}
+		case *email.QueryResponse:
+			if id, ok := totalCallIDs[inv.CallID]; ok {
+				byID[id].ActualTotal = r.Total
+			} else if id, ok := unreadCallIDs[inv.CallID]; ok {
+				byID[id].ActualUnread = r.Total
+			}
+		case *jmap.MethodError:
+			return nil, fmt.Errorf("mailbox verify: %s", r.Error())
+		}
+	}
+
+	for i := range checks {
+		checks[i].Match = checks[i].ReportedTotal == checks[i].ActualTotal &&
+			checks[i].ReportedUnread == checks[i].ActualUnread
+	}
+	return checks, nil
+}
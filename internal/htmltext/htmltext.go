@@ -0,0 +1,473 @@
+// Package htmltext renders HTML email bodies as plain text for reading in a
+// terminal. It handles the markup emails actually use rather than full HTML:
+// block elements become line breaks, lists keep their markers, table rows are
+// flattened to single lines, and links become numbered footnotes listed after
+// the text. Scripts, styles, and comments are dropped.
+package htmltext
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// Render converts an HTML document or fragment to plain text.
+func Render(s string) string {
+	r := &renderer{footnotes: make(map[string]int)}
+	r.run(s)
+	return r.finish()
+}
+
+// rawTextTags hold content that is never displayed.
+var rawTextTags = map[string]bool{"script": true, "style": true, "title": true}
+
+// paragraphTags are separated from surrounding text by a blank line.
+var paragraphTags = map[string]bool{
+	"p": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"table": true, "blockquote": true, "pre": true, "ul": true, "ol": true, "dl": true,
+}
+
+// lineTags start and end on their own line.
+var lineTags = map[string]bool{
+	"div": true, "tr": true, "li": true, "dt": true, "dd": true, "section": true,
+	"article": true, "header": true, "footer": true, "center": true, "address": true,
+	"tbody": true, "thead": true, "tfoot": true, "caption": true, "figure": true,
+}
+
+// invisible are characters used in email preheaders to pad previews; they
+// carry no text.
+var invisible = strings.NewReplacer(
+	"\u200b", "", "\u200c", "", "\ufeff", "", "\u034f", "", "\u00ad", "",
+	"\u00a0", " ",
+)
+
+type list struct {
+	ordered bool
+	n       int
+	hang    string // indentation of continuation lines in the current item
+}
+
+type renderer struct {
+	lines   []string
+	cur     strings.Builder
+	started bool   // cur holds the start of a line
+	space   bool   // a space is due before the next word
+	sep     string // a cell separator is due before the next word
+	blank   bool   // a blank line is due before the next line
+
+	quote  int
+	pre    int
+	lists  []*list
+	marker string // list marker for the next line
+
+	href       string // target of the open link, if any
+	anchorText strings.Builder
+	links      []string
+	footnotes  map[string]int
+}
+
+func (r *renderer) run(s string) {
+	for len(s) > 0 {
+		i := strings.IndexByte(s, '<')
+		if i < 0 {
+			r.text(s)
+			return
+		}
+		if i > 0 {
+			r.text(s[:i])
+			s = s[i:]
+		}
+
+		switch {
+		case strings.HasPrefix(s, "<!--"):
+			s = skipPast(s, "-->")
+			continue
+		case strings.HasPrefix(s, "<!") || strings.HasPrefix(s, "<?"):
+			s = skipPast(s, ">")
+			continue
+		}
+
+		end := tagEnd(s)
+		if end < 0 {
+			// A lone '<' is text.
+			r.text(s[:1])
+			s = s[1:]
+			continue
+		}
+		name, closing, attrs := parseTag(s[1:end])
+		s = s[end+1:]
+		if name == "" {
+			r.text("<")
+			continue
+		}
+
+		if closing {
+			r.close(name)
+			continue
+		}
+		if rawTextTags[name] {
+			s = skipRawText(s, name)
+			continue
+		}
+		r.open(name, attrs)
+	}
+}
+
+func (r *renderer) open(name string, attrs map[string]string) {
+	switch {
+	case name == "br":
+		if r.started {
+			r.breakLine()
+		} else {
+			r.emptyLine()
+		}
+		return
+	case name == "hr":
+		r.paragraph()
+		r.write("---")
+		r.paragraph()
+		return
+	case name == "img":
+		if alt := strings.TrimSpace(attrs["alt"]); alt != "" {
+			r.word("[" + strings.Join(strings.Fields(alt), " ") + "]")
+		}
+		return
+	case name == "a":
+		r.closeLink()
+		r.href = strings.TrimSpace(attrs["href"])
+		r.anchorText.Reset()
+		return
+	case name == "td" || name == "th":
+		if r.started {
+			r.sep = " | "
+		}
+		return
+	}
+
+	if paragraphTags[name] {
+		r.paragraph()
+	} else if lineTags[name] {
+		r.breakLine()
+	}
+
+	switch name {
+	case "blockquote":
+		r.quote++
+	case "pre":
+		r.pre++
+	case "ul", "ol":
+		r.lists = append(r.lists, &list{ordered: name == "ol"})
+		if len(r.lists) > 1 {
+			// Nested lists sit directly under their parent item.
+			r.blank = false
+		}
+	case "li":
+		if len(r.lists) == 0 {
+			r.lists = append(r.lists, &list{})
+		}
+		l := r.lists[len(r.lists)-1]
+		l.n++
+		indent := strings.Repeat("  ", len(r.lists)-1)
+		m := "- "
+		if l.ordered {
+			m = fmt.Sprintf("%d. ", l.n)
+		}
+		r.marker = indent + m
+		l.hang = indent + strings.Repeat(" ", len(m))
+	}
+}
+
+func (r *renderer) close(name string) {
+	switch name {
+	case "a":
+		r.closeLink()
+		return
+	case "blockquote":
+		if r.quote > 0 {
+			r.breakLine()
+			r.quote--
+		}
+	case "pre":
+		if r.pre > 0 {
+			r.pre--
+		}
+	case "ul", "ol":
+		if len(r.lists) > 0 {
+			r.breakLine()
+			r.lists = r.lists[:len(r.lists)-1]
+			if len(r.lists) > 0 {
+				return
+			}
+		}
+	}
+
+	if paragraphTags[name] {
+		r.paragraph()
+	} else if lineTags[name] {
+		r.breakLine()
+	}
+}
+
+// closeLink ends the open link, adding a footnote unless the link text is
+// already its target.
+func (r *renderer) closeLink() {
+	href := r.href
+	r.href = ""
+	if !linkable(href) {
+		return
+	}
+	text := strings.TrimSpace(r.anchorText.String())
+	if text == href || "mailto:"+text == href {
+		return
+	}
+	n, ok := r.footnotes[href]
+	if !ok {
+		r.links = append(r.links, href)
+		n = len(r.links)
+		r.footnotes[href] = n
+	}
+	ref := fmt.Sprintf("[%d]", n)
+	if r.started && !r.space {
+		r.cur.WriteString(ref)
+		return
+	}
+	r.word(ref)
+}
+
+func linkable(href string) bool {
+	lower := strings.ToLower(href)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") ||
+		strings.HasPrefix(lower, "mailto:")
+}
+
+// text adds character data, collapsing whitespace outside <pre>.
+func (r *renderer) text(s string) {
+	s = invisible.Replace(html.UnescapeString(s))
+	if r.pre > 0 {
+		for i, line := range strings.Split(s, "\n") {
+			if i > 0 {
+				if r.started {
+					r.breakLine()
+				} else {
+					r.emptyLine()
+				}
+			}
+			if line != "" {
+				r.write(strings.TrimRight(line, "\r"))
+			}
+		}
+		return
+	}
+
+	if s != "" && isSpace(s[0]) {
+		r.space = true
+	}
+	for i, w := range strings.Fields(s) {
+		if i > 0 {
+			r.space = true
+		}
+		r.word(w)
+	}
+	if s != "" && isSpace(s[len(s)-1]) {
+		r.space = true
+	}
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r' || b == '\f'
+}
+
+// word writes w after any pending separator.
+func (r *renderer) word(w string) {
+	if r.href != "" {
+		if r.space && r.anchorText.Len() > 0 {
+			r.anchorText.WriteByte(' ')
+		}
+		r.anchorText.WriteString(w)
+	}
+	r.write(w)
+}
+
+// write adds s to the current line, starting a new one if needed.
+func (r *renderer) write(s string) {
+	if !r.started {
+		if r.blank && len(r.lines) > 0 && r.lines[len(r.lines)-1] != "" {
+			r.lines = append(r.lines, "")
+		}
+		r.blank = false
+		r.cur.WriteString(r.prefix())
+		r.started = true
+	} else if r.sep != "" {
+		r.cur.WriteString(r.sep)
+	} else if r.space {
+		r.cur.WriteByte(' ')
+	}
+	r.sep = ""
+	r.space = false
+	r.cur.WriteString(s)
+}
+
+// prefix returns the quoting and list indentation for a new line.
+func (r *renderer) prefix() string {
+	p := strings.Repeat("> ", r.quote)
+	if r.marker != "" {
+		p += r.marker
+		r.marker = ""
+	} else if len(r.lists) > 0 {
+		p += r.lists[len(r.lists)-1].hang
+	}
+	return p
+}
+
+// breakLine ends the current line, if any.
+func (r *renderer) breakLine() {
+	if r.started {
+		r.lines = append(r.lines, strings.TrimRight(r.cur.String(), " "))
+		r.cur.Reset()
+		r.started = false
+	}
+	r.space = false
+	r.sep = ""
+}
+
+// emptyLine adds an empty line, as for consecutive <br>s.
+func (r *renderer) emptyLine() {
+	if len(r.lines) > 0 {
+		r.lines = append(r.lines, strings.TrimRight(strings.Repeat("> ", r.quote), " "))
+	}
+}
+
+// paragraph ends the current line and asks for a blank line before the next.
+func (r *renderer) paragraph() {
+	r.breakLine()
+	r.blank = true
+}
+
+func (r *renderer) finish() string {
+	r.closeLink()
+	r.breakLine()
+
+	// Collapse runs of blank lines left by nested blocks and <br>s.
+	var out []string
+	for _, line := range r.lines {
+		if line == "" && (len(out) == 0 || out[len(out)-1] == "") {
+			continue
+		}
+		out = append(out, line)
+	}
+	for len(out) > 0 && out[len(out)-1] == "" {
+		out = out[:len(out)-1]
+	}
+
+	if len(r.links) > 0 {
+		if len(out) > 0 {
+			out = append(out, "")
+		}
+		for i, href := range r.links {
+			out = append(out, fmt.Sprintf("[%d] %s", i+1, href))
+		}
+	}
+	if len(out) == 0 {
+		return ""
+	}
+	return strings.Join(out, "\n") + "\n"
+}
+
+// skipPast returns s after the first occurrence of end, or "" if there is
+// none.
+func skipPast(s, end string) string {
+	if i := strings.Index(s, end); i >= 0 {
+		return s[i+len(end):]
+	}
+	return ""
+}
+
+// skipRawText returns s after the closing tag of a raw text element.
+func skipRawText(s, name string) string {
+	i := strings.Index(strings.ToLower(s), "</"+name)
+	if i < 0 {
+		return ""
+	}
+	return skipPast(s[i:], ">")
+}
+
+// tagEnd returns the index of the '>' ending the tag at the start of s,
+// skipping quoted attribute values, or -1 if s does not start a tag.
+func tagEnd(s string) int {
+	if len(s) < 2 || !(isLetter(s[1]) || s[1] == '/') {
+		return -1
+	}
+	var quote byte
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return i
+		}
+	}
+	return -1
+}
+
+func isLetter(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+// parseTag splits the inside of a tag into its lower-cased name, whether it
+// is a closing tag, and its attributes.
+func parseTag(s string) (name string, closing bool, attrs map[string]string) {
+	if strings.HasPrefix(s, "/") {
+		closing = true
+		s = s[1:]
+	}
+	s = strings.TrimSuffix(s, "/")
+	i := 0
+	for i < len(s) && !isSpace(s[i]) && s[i] != '/' {
+		i++
+	}
+	name = strings.ToLower(s[:i])
+	if closing {
+		return name, true, nil
+	}
+
+	attrs = make(map[string]string)
+	s = s[i:]
+	for {
+		s = strings.TrimLeft(s, " \t\n\r\f/")
+		if s == "" {
+			return name, false, attrs
+		}
+		j := 0
+		for j < len(s) && !isSpace(s[j]) && s[j] != '=' && s[j] != '/' {
+			j++
+		}
+		key := strings.ToLower(s[:j])
+		s = strings.TrimLeft(s[j:], " \t\n\r\f")
+		if !strings.HasPrefix(s, "=") {
+			attrs[key] = ""
+			continue
+		}
+		s = strings.TrimLeft(s[1:], " \t\n\r\f")
+		var val string
+		if s != "" && (s[0] == '"' || s[0] == '\'') {
+			q := s[0]
+			end := strings.IndexByte(s[1:], q)
+			if end < 0 {
+				val, s = s[1:], ""
+			} else {
+				val, s = s[1:end+1], s[end+2:]
+			}
+		} else {
+			k := 0
+			for k < len(s) && !isSpace(s[k]) {
+				k++
+			}
+			val, s = s[:k], s[k:]
+		}
+		attrs[key] = html.UnescapeString(val)
+	}
+}
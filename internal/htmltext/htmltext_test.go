@@ -0,0 +1,74 @@
+package htmltext
+
+import "testing"
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "paragraphs and breaks",
+			in:   "<p>Hello   there,\n world.</p><p>Line one<br>Line two</p>",
+			want: "Hello there, world.\n\nLine one\nLine two\n",
+		},
+		{
+			name: "entities and invisible preheader padding",
+			in:   "<div>Fish &amp; chips&nbsp;&mdash; &#163;5&zwnj;&zwnj;&#8203;</div>",
+			want: "Fish & chips — £5\n",
+		},
+		{
+			name: "scripts styles comments and head",
+			in:   "<html><head><title>T</title><style>p{color:red}</style></head><body><!-- hi --><script>x<1</script><p>Body</p></body></html>",
+			want: "Body\n",
+		},
+		{
+			name: "links become footnotes",
+			in:   `<p>See <a href="https://example.com/a">the docs</a> and <a href="https://example.com/b">more</a>, again <a href="https://example.com/a">docs</a>.</p>`,
+			want: "See the docs[1] and more[2], again docs[1].\n\n[1] https://example.com/a\n[2] https://example.com/b\n",
+		},
+		{
+			name: "links showing their target are not repeated",
+			in:   `<a href="https://example.com">https://example.com</a> <a href="mailto:a@example.com">a@example.com</a> <a href="#top">top</a>`,
+			want: "https://example.com a@example.com top\n",
+		},
+		{
+			name: "lists",
+			in:   "<p>Steps:</p><ol><li>Open</li><li>Pack<ul><li>socks</li><li>shirts</li></ul></li><li>Go</li></ol><p>Done</p>",
+			want: "Steps:\n\n1. Open\n2. Pack\n  - socks\n  - shirts\n3. Go\n\nDone\n",
+		},
+		{
+			name: "tables flatten to rows",
+			in:   "<table><tr><th>Flight</th><th>Gate</th></tr><tr><td>BA 117</td><td></td><td>B12</td></tr></table>",
+			want: "Flight | Gate\nBA 117 | B12\n",
+		},
+		{
+			name: "image alt text",
+			in:   `<p><img src="cid:logo" alt="ACME  logo"> Welcome<img src="pixel.gif" alt=""></p>`,
+			want: "[ACME logo] Welcome\n",
+		},
+		{
+			name: "blockquote and pre",
+			in:   "<p>Agreed.</p><blockquote><p>Ship it?</p></blockquote><pre>a  b\n  c</pre>",
+			want: "Agreed.\n\n> Ship it?\n\na  b\n  c\n",
+		},
+		{
+			name: "stray angle brackets",
+			in:   "1 < 2 and <3",
+			want: "1 < 2 and <3\n",
+		},
+		{
+			name: "empty",
+			in:   "<div> </div>",
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Render(tt.in); got != tt.want {
+				t.Errorf("Render():\ngot  %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+// Package ics reads the scheduling parts of iCalendar (RFC 5545) data sent
+// by email (iMIP, RFC 6047): the method and, for each event, its times,
+// organizer, and attendees with their participation status.
+package ics
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Participation statuses of an attendee (the PARTSTAT parameter).
+const (
+	NeedsAction = "NEEDS-ACTION"
+	Accepted    = "ACCEPTED"
+	Declined    = "DECLINED"
+	Tentative   = "TENTATIVE"
+)
+
+// Calendar is a VCALENDAR object.
+type Calendar struct {
+	// Method is the iTIP method, such as REQUEST, REPLY, or CANCEL, in
+	// upper case. It is empty for calendar data that is not a scheduling
+	// message.
+	Method string
+	Events []Event
+}
+
+// Event is a VEVENT.
+type Event struct {
+	UID      string
+	Sequence int
+	Summary  string
+	Location string
+	Start    time.Time
+	End      time.Time
+	// AllDay is set when the event starts on a date rather than a time.
+	AllDay    bool
+	Organizer Person
+	Attendees []Person
+}
+
+// Person is an ORGANIZER or ATTENDEE.
+type Person struct {
+	Email string
+	Name  string
+	// PartStat is the attendee's participation status in upper case,
+	// NEEDS-ACTION when the attendee has none.
+	PartStat string
+}
+
+// Attendee returns the attendee whose email address matches is, and false
+// if there is none.
+func (e Event) Attendee(is func(email string) bool) (Person, bool) {
+	for _, a := range e.Attendees {
+		if is(a.Email) {
+			return a, true
+		}
+	}
+	return Person{}, false
+}
+
+// Parse reads calendar data from r. Properties it does not use, and
+// components other than VEVENT, are skipped, as are times it cannot read.
+func Parse(r io.Reader) (Calendar, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return Calendar{}, err
+	}
+
+	var cal Calendar
+	var event *Event
+	depth := 0 // components nested inside the current VEVENT, such as VALARM
+	for _, line := range lines {
+		name, params, value, ok := parseLine(line)
+		if !ok {
+			continue
+		}
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT") && event == nil:
+			event = &Event{}
+			continue
+		case name == "BEGIN" && event != nil:
+			depth++
+			continue
+		case name == "END" && event != nil && depth > 0:
+			depth--
+			continue
+		case name == "END" && event != nil && strings.EqualFold(value, "VEVENT"):
+			cal.Events = append(cal.Events, *event)
+			event = nil
+			continue
+		}
+
+		if event == nil {
+			if name == "METHOD" {
+				cal.Method = strings.ToUpper(value)
+			}
+			continue
+		}
+		if depth > 0 {
+			continue
+		}
+		switch name {
+		case "UID":
+			event.UID = value
+		case "SEQUENCE":
+			event.Sequence, _ = strconv.Atoi(value)
+		case "SUMMARY":
+			event.Summary = unescape(value)
+		case "LOCATION":
+			event.Location = unescape(value)
+		case "DTSTART":
+			if t, allDay, ok := parseTime(value, params); ok {
+				event.Start, event.AllDay = t, allDay
+			}
+		case "DTEND":
+			if t, _, ok := parseTime(value, params); ok {
+				event.End = t
+			}
+		case "ORGANIZER":
+			event.Organizer = person(value, params)
+		case "ATTENDEE":
+			event.Attendees = append(event.Attendees, person(value, params))
+		}
+	}
+	return cal, nil
+}
+
+// unfold reads the content lines of r, joining lines continued with a
+// leading space or tab.
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// parseLine splits a content line into its upper-case name, its
+// parameters, and its value.
+func parseLine(line string) (name string, params map[string]string, value string, ok bool) {
+	head, value, ok := cutOutsideQuotes(line, ':')
+	if !ok {
+		return "", nil, "", false
+	}
+	parts := splitOutsideQuotes(head, ';')
+	name = strings.ToUpper(parts[0])
+	params = make(map[string]string, len(parts)-1)
+	for _, p := range parts[1:] {
+		k, v, _ := strings.Cut(p, "=")
+		params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+	}
+	return name, params, value, true
+}
+
+// cutOutsideQuotes is strings.Cut at the first sep that is not inside
+// double quotes, as in a quoted CN parameter.
+func cutOutsideQuotes(s string, sep byte) (before, after string, found bool) {
+	quoted := false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '"':
+			quoted = !quoted
+		case s[i] == sep && !quoted:
+			return s[:i], s[i+1:], true
+		}
+	}
+	return s, "", false
+}
+
+func splitOutsideQuotes(s string, sep byte) []string {
+	var parts []string
+	for {
+		before, after, found := cutOutsideQuotes(s, sep)
+		parts = append(parts, before)
+		if !found {
+			return parts
+		}
+		s = after
+	}
+}
+
+// parseTime reads a DATE or DATE-TIME value. Times in UTC end in Z; others
+// are in their TZID's zone, or local time when the zone has no IANA name
+// (Outlook sends Windows zone names) or is not given.
+func parseTime(value string, params map[string]string) (t time.Time, allDay, ok bool) {
+	loc := time.Local
+	if tzid := params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(strings.TrimPrefix(tzid, "/")); err == nil {
+			loc = l
+		}
+	}
+	if params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		t, err := time.ParseInLocation("20060102", value, loc)
+		return t, true, err == nil
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err == nil
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	return t, false, err == nil
+}
+
+// person reads an ORGANIZER or ATTENDEE from its mailto: value and its
+// CN and PARTSTAT parameters.
+func person(value string, params map[string]string) Person {
+	addr := value
+	if len(addr) >= len("mailto:") && strings.EqualFold(addr[:len("mailto:")], "mailto:") {
+		addr = addr[len("mailto:"):]
+	}
+	partStat := strings.ToUpper(params["PARTSTAT"])
+	if partStat == "" {
+		partStat = NeedsAction
+	}
+	return Person{Email: addr, Name: params["CN"], PartStat: partStat}
+}
+
+// unescape undoes the backslash escapes of a TEXT value.
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
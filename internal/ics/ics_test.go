@@ -0,0 +1,93 @@
+package ics
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const invite = "BEGIN:VCALENDAR\r\n" +
+	"PRODID:-//Example//EN\r\n" +
+	"METHOD:REQUEST\r\n" +
+	"BEGIN:VTIMEZONE\r\n" +
+	"TZID:Europe/Paris\r\n" +
+	"END:VTIMEZONE\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:evt-1@example.com\r\n" +
+	"SEQUENCE:2\r\n" +
+	"SUMMARY:Planning\\, Q3\r\n" +
+	"LOCATION:Room 4\r\n" +
+	"DTSTART;TZID=Europe/Paris:20260310T090000\r\n" +
+	"DTEND;TZID=Europe/Paris:20260310T100000\r\n" +
+	"ORGANIZER;CN=\"Alice: Ops\":mailto:alice@example.com\r\n" +
+	"ATTENDEE;CN=Me;PARTSTAT=NEEDS-ACTION;RSVP=TRUE:mailto:me@exam\r\n" +
+	" ple.com\r\n" +
+	"ATTENDEE;PARTSTAT=ACCEPTED:MAILTO:bob@example.com\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"SUMMARY:Reminder\r\n" +
+	"END:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParse(t *testing.T) {
+	cal, err := Parse(strings.NewReader(invite))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cal.Method != "REQUEST" {
+		t.Errorf("expected method REQUEST, got %q", cal.Method)
+	}
+	if len(cal.Events) != 1 {
+		t.Fatalf("expected one event, got %d", len(cal.Events))
+	}
+
+	e := cal.Events[0]
+	if e.UID != "evt-1@example.com" || e.Sequence != 2 {
+		t.Errorf("expected UID evt-1@example.com at sequence 2, got %q at %d", e.UID, e.Sequence)
+	}
+	if e.Summary != "Planning, Q3" {
+		t.Errorf("expected the summary unescaped, got %q", e.Summary)
+	}
+	if want := time.Date(2026, 3, 10, 8, 0, 0, 0, time.UTC); !e.Start.Equal(want) || e.AllDay {
+		t.Errorf("expected start %v, got %v (all day %v)", want, e.Start, e.AllDay)
+	}
+	if want := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC); !e.End.Equal(want) {
+		t.Errorf("expected end %v, got %v", want, e.End)
+	}
+	if e.Organizer.Email != "alice@example.com" || e.Organizer.Name != "Alice: Ops" {
+		t.Errorf("unexpected organizer: %+v", e.Organizer)
+	}
+	if len(e.Attendees) != 2 {
+		t.Fatalf("expected two attendees, got %+v", e.Attendees)
+	}
+
+	me, ok := e.Attendee(func(addr string) bool { return addr == "me@example.com" })
+	if !ok || me.PartStat != NeedsAction || me.Name != "Me" {
+		t.Errorf("expected the folded attendee me@example.com needing action, got %+v (found %v)", me, ok)
+	}
+	bob, ok := e.Attendee(func(addr string) bool { return addr == "bob@example.com" })
+	if !ok || bob.PartStat != Accepted {
+		t.Errorf("expected bob@example.com accepted, got %+v (found %v)", bob, ok)
+	}
+}
+
+func TestParse_AllDayAndUTC(t *testing.T) {
+	cal, err := Parse(strings.NewReader("BEGIN:VCALENDAR\nMETHOD:reply\n" +
+		"BEGIN:VEVENT\nDTSTART;VALUE=DATE:20260401\nATTENDEE:mailto:me@example.com\nEND:VEVENT\n" +
+		"BEGIN:VEVENT\nDTSTART:20260402T130000Z\nEND:VEVENT\nEND:VCALENDAR\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cal.Method != "REPLY" || len(cal.Events) != 2 {
+		t.Fatalf("expected a REPLY with two events, got %+v", cal)
+	}
+	if e := cal.Events[0]; !e.AllDay || e.Start.Day() != 1 {
+		t.Errorf("expected an all-day event on the 1st, got %+v", e)
+	}
+	if e := cal.Events[0]; e.Attendees[0].PartStat != NeedsAction {
+		t.Errorf("expected a missing PARTSTAT to read as NEEDS-ACTION, got %q", e.Attendees[0].PartStat)
+	}
+	if want := time.Date(2026, 4, 2, 13, 0, 0, 0, time.UTC); !cal.Events[1].Start.Equal(want) {
+		t.Errorf("expected start %v, got %v", want, cal.Events[1].Start)
+	}
+}
@@ -0,0 +1,26 @@
+package maskedemail
+
+import "git.sr.ht/~rockorager/go-jmap"
+
+// Get retrieves masked emails by ID, or all masked emails when IDs is nil.
+type Get struct {
+	Account    jmap.ID   `json:"accountId,omitempty"`
+	IDs        []jmap.ID `json:"ids,omitempty"`
+	Properties []string  `json:"properties,omitempty"`
+}
+
+// Name returns the JMAP method name.
+func (m *Get) Name() string { return "MaskedEmail/get" }
+
+// Requires returns the capability URIs this method depends on.
+func (m *Get) Requires() []jmap.URI { return []jmap.URI{URI} }
+
+// GetResponse is the server response to a MaskedEmail/get request.
+type GetResponse struct {
+	Account  jmap.ID        `json:"accountId,omitempty"`
+	State    string         `json:"state,omitempty"`
+	List     []*MaskedEmail `json:"list,omitempty"`
+	NotFound []jmap.ID      `json:"notFound,omitempty"`
+}
+
+func newGetResponse() jmap.MethodResponse { return &GetResponse{} }
@@ -0,0 +1,49 @@
+// Package maskedemail implements Fastmail's JMAP MaskedEmail extension.
+// https://www.fastmail.com/dev/maskedemail
+package maskedemail
+
+import (
+	"time"
+
+	"git.sr.ht/~rockorager/go-jmap"
+)
+
+// URI is the capability identifier for Fastmail masked email management.
+const URI jmap.URI = "https://www.fastmail.com/dev/maskedemail"
+
+// Masked email states.
+const (
+	StatePending  = "pending"
+	StateEnabled  = "enabled"
+	StateDisabled = "disabled"
+	StateDeleted  = "deleted"
+)
+
+func init() {
+	jmap.RegisterCapability(&Capability{})
+	jmap.RegisterMethod("MaskedEmail/get", newGetResponse)
+	jmap.RegisterMethod("MaskedEmail/set", newSetResponse)
+}
+
+// Capability is the JMAP capability object for the masked email extension.
+type Capability struct{}
+
+// URI returns the masked email capability URI.
+func (c *Capability) URI() jmap.URI { return URI }
+
+// New returns a new empty Capability instance.
+func (c *Capability) New() jmap.Capability { return &Capability{} }
+
+// MaskedEmail is a forwarding address that delivers to the account.
+type MaskedEmail struct {
+	ID            jmap.ID    `json:"id,omitempty"`
+	Email         string     `json:"email,omitempty"`
+	State         string     `json:"state,omitempty"`
+	ForDomain     string     `json:"forDomain,omitempty"`
+	Description   string     `json:"description,omitempty"`
+	LastMessageAt *time.Time `json:"lastMessageAt,omitempty"`
+	CreatedAt     *time.Time `json:"createdAt,omitempty"`
+	CreatedBy     string     `json:"createdBy,omitempty"`
+	URL           string     `json:"url,omitempty"`
+	EmailPrefix   string     `json:"emailPrefix,omitempty"`
+}
@@ -0,0 +1,34 @@
+package maskedemail
+
+import "git.sr.ht/~rockorager/go-jmap"
+
+// Set creates or updates masked emails. fm never destroys masked emails, so
+// Destroy is intentionally not exposed.
+type Set struct {
+	Account jmap.ID `json:"accountId,omitempty"`
+
+	Create map[jmap.ID]*MaskedEmail `json:"create,omitempty"`
+
+	Update map[jmap.ID]jmap.Patch `json:"update,omitempty"`
+}
+
+// Name returns the JMAP method name.
+func (m *Set) Name() string { return "MaskedEmail/set" }
+
+// Requires returns the capability URIs this method depends on.
+func (m *Set) Requires() []jmap.URI { return []jmap.URI{URI} }
+
+// SetResponse is the server response to a MaskedEmail/set request.
+type SetResponse struct {
+	Account  jmap.ID `json:"accountId,omitempty"`
+	OldState string  `json:"oldState,omitempty"`
+	NewState string  `json:"newState,omitempty"`
+
+	Created map[jmap.ID]*MaskedEmail `json:"created,omitempty"`
+	Updated map[jmap.ID]*MaskedEmail `json:"updated,omitempty"`
+
+	NotCreated map[jmap.ID]*jmap.SetError `json:"notCreated,omitempty"`
+	NotUpdated map[jmap.ID]*jmap.SetError `json:"notUpdated,omitempty"`
+}
+
+func newSetResponse() jmap.MethodResponse { return &SetResponse{} }
@@ -0,0 +1,145 @@
+// Package jsonld reads the schema.org JSON-LD that senders embed in the
+// HTML of emails (<script type="application/ld+json">), such as the order
+// data of receipts and the reservations of travel bookings.
+package jsonld
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Node is a JSON-LD object.
+type Node map[string]any
+
+// script matches the JSON-LD script elements of an HTML document.
+var script = regexp.MustCompile(`(?is)<script[^>]*type\s*=\s*["']?application/ld\+json["']?[^>]*>(.*?)</script>`)
+
+// Nodes returns the top-level objects of the JSON-LD in html, in document
+// order, taking the items of lists and @graph arrays as objects of their
+// own. Script elements that are not valid JSON are skipped. Numbers are
+// kept as json.Number, so Text returns them as written.
+func Nodes(html string) []Node {
+	var nodes []Node
+	for _, m := range script.FindAllStringSubmatch(html, -1) {
+		dec := json.NewDecoder(strings.NewReader(unescapeHTML(m[1])))
+		dec.UseNumber()
+		var doc any
+		if err := dec.Decode(&doc); err != nil {
+			continue
+		}
+		nodes = appendNodes(nodes, doc)
+	}
+	return nodes
+}
+
+func appendNodes(nodes []Node, doc any) []Node {
+	switch v := doc.(type) {
+	case []any:
+		for _, item := range v {
+			nodes = appendNodes(nodes, item)
+		}
+	case map[string]any:
+		if graph, ok := v["@graph"]; ok {
+			return appendNodes(nodes, graph)
+		}
+		nodes = append(nodes, Node(v))
+	}
+	return nodes
+}
+
+// Is reports whether n's @type, a name or a list of names, with or without
+// the schema.org prefix, includes typ.
+func (n Node) Is(typ string) bool {
+	var types []any
+	switch t := n["@type"].(type) {
+	case string:
+		types = []any{t}
+	case []any:
+		types = t
+	}
+	for _, t := range types {
+		if s, ok := t.(string); ok {
+			s = strings.TrimPrefix(strings.TrimPrefix(s, "http://schema.org/"), "https://schema.org/")
+			if s == typ {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Value returns the value of the first of keys that n has.
+func (n Node) Value(keys ...string) any {
+	for _, k := range keys {
+		if v, ok := n[k]; ok && v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+// Text returns the first of keys that n has, as text.
+func (n Node) Text(keys ...string) string {
+	return text(n.Value(keys...))
+}
+
+// Node returns the object under the first of keys that n has, or nil.
+func (n Node) Node(keys ...string) Node {
+	if v, ok := n.Value(keys...).(map[string]any); ok {
+		return Node(v)
+	}
+	return nil
+}
+
+// Nodes returns the objects under key, which may hold one object or a
+// list of them.
+func (n Node) Nodes(key string) []Node {
+	switch v := n[key].(type) {
+	case map[string]any:
+		return []Node{Node(v)}
+	case []any:
+		var nodes []Node
+		for _, item := range v {
+			if m, ok := item.(map[string]any); ok {
+				nodes = append(nodes, Node(m))
+			}
+		}
+		return nodes
+	}
+	return nil
+}
+
+// Name returns the name of the thing under the first of keys that n has,
+// such as an Organization, or the value itself when it is plain text.
+func (n Node) Name(keys ...string) string {
+	if thing := n.Node(keys...); thing != nil {
+		return thing.Text("name")
+	}
+	return n.Text(keys...)
+}
+
+// text reads a string or number as text.
+func text(v any) string {
+	switch t := v.(type) {
+	case string:
+		return strings.TrimSpace(t)
+	case json.Number:
+		return t.String()
+	case nil, map[string]any, []any:
+		return ""
+	default:
+		return fmt.Sprint(t)
+	}
+}
+
+// unescapeHTML undoes the entity escaping some mailers apply to the
+// contents of script elements.
+func unescapeHTML(s string) string {
+	if !strings.Contains(s, "&") || json.Valid(bytes.TrimSpace([]byte(s))) {
+		return s
+	}
+	return strings.NewReplacer("&quot;", `"`, "&#34;", `"`, "&amp;", "&", "&lt;", "<", "&gt;", ">").Replace(s)
+}
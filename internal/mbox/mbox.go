@@ -0,0 +1,54 @@
+// Package mbox writes messages in the mboxrd format, in which any body line
+// matching ^>*From  is quoted with an extra > so it can be reversed exactly.
+package mbox
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+)
+
+// fromLineLayout is the asctime layout used in mbox separator lines.
+const fromLineLayout = "Mon Jan _2 15:04:05 2006"
+
+// WriteMessage appends one RFC 5322 message read from r to w, preceded by a
+// "From " separator line and followed by a blank line. CRLF line endings are
+// converted to LF. An empty sender is written as MAILER-DAEMON.
+func WriteMessage(w io.Writer, sender string, date time.Time, r io.Reader) error {
+	if sender == "" {
+		sender = "MAILER-DAEMON"
+	}
+	bw := bufio.NewWriter(w)
+	if _, err := fmt.Fprintf(bw, "From %s %s\n", sender, date.UTC().Format(fromLineLayout)); err != nil {
+		return err
+	}
+
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 {
+			line = bytes.TrimSuffix(bytes.TrimSuffix(line, []byte("\n")), []byte("\r"))
+			if isFromLine(line) {
+				_ = bw.WriteByte('>')
+			}
+			_, _ = bw.Write(line)
+			_ = bw.WriteByte('\n')
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	_ = bw.WriteByte('\n')
+	return bw.Flush()
+}
+
+// isFromLine reports whether line matches ^>*From .
+func isFromLine(line []byte) bool {
+	return bytes.HasPrefix(bytes.TrimLeft(line, ">"), []byte("From "))
+}
@@ -0,0 +1,40 @@
+package mbox
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteMessage(t *testing.T) {
+	msg := "From: alice@example.com\r\nSubject: Hi\r\n\r\nFrom here on\r\n>From quoted\r\nbye"
+	date := time.Date(2026, 2, 4, 9, 5, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	if err := WriteMessage(&buf, "alice@example.com", date, strings.NewReader(msg)); err != nil {
+		t.Fatalf("WriteMessage: %v", err)
+	}
+
+	want := "From alice@example.com Wed Feb  4 09:05:00 2026\n" +
+		"From: alice@example.com\n" +
+		"Subject: Hi\n" +
+		"\n" +
+		">From here on\n" +
+		">>From quoted\n" +
+		"bye\n" +
+		"\n"
+	if buf.String() != want {
+		t.Errorf("unexpected mbox output:\n%q\nwant:\n%q", buf.String(), want)
+	}
+}
+
+func TestWriteMessage_EmptySender(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteMessage(&buf, "", time.Unix(0, 0), strings.NewReader("Subject: x\n\nbody\n")); err != nil {
+		t.Fatalf("WriteMessage: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "From MAILER-DAEMON Thu Jan  1 00:00:00 1970\n") {
+		t.Errorf("unexpected separator line: %q", buf.String())
+	}
+}
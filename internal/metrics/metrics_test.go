@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistry_Write(t *testing.T) {
+	r := NewRegistry()
+	events := r.Counter("fm_events_total", "Events seen.")
+	unread := r.Gauge("fm_unread", "Unread emails.")
+	latency := r.Histogram("fm_latency_seconds", "Latency.", []float64{0.1, 1})
+
+	events.Inc()
+	events.Add(2)
+	unread.Set(7)
+	latency.Observe(0.05)
+	latency.Observe(0.5)
+	latency.Observe(3)
+
+	var buf bytes.Buffer
+	if err := r.Write(&buf); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	want := `# HELP fm_events_total Events seen.
+# TYPE fm_events_total counter
+fm_events_total 3
+# HELP fm_unread Unread emails.
+# TYPE fm_unread gauge
+fm_unread 7
+# HELP fm_latency_seconds Latency.
+# TYPE fm_latency_seconds histogram
+fm_latency_seconds_bucket{le="0.1"} 1
+fm_latency_seconds_bucket{le="1"} 2
+fm_latency_seconds_bucket{le="+Inf"} 3
+fm_latency_seconds_sum 3.55
+fm_latency_seconds_count 3
+`
+	if buf.String() != want {
+		t.Errorf("unexpected exposition:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("fm_polls_total", "Polls.").Inc()
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Errorf("unexpected content type %q", rec.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(rec.Body.String(), "fm_polls_total 1\n") {
+		t.Errorf("expected counter in body, got:\n%s", rec.Body.String())
+	}
+}
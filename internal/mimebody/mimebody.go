@@ -0,0 +1,155 @@
+// Package mimebody extracts the readable body and the attachments of a MIME
+// entity parsed locally, for content the server never sees in the clear,
+// such as a decrypted PGP/MIME message. It picks parts the way fm read
+// does for server-parsed email: the first text/plain part, else the HTML
+// part rendered to text, or the HTML as sent when HTML is preferred.
+package mimebody
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+
+	"golang.org/x/text/encoding/htmlindex"
+
+	"github.com/cboone/fm/internal/htmltext"
+	"github.com/cboone/fm/internal/mimeword"
+	"github.com/cboone/fm/internal/types"
+)
+
+// maxDepth bounds multipart nesting.
+const maxDepth = 20
+
+// Body is the readable content of a MIME entity.
+type Body struct {
+	Text        string
+	Attachments []types.Attachment
+}
+
+// Render parses entity, a MIME entity with its headers, and returns its
+// body text and attachments.
+func Render(entity []byte, preferHTML bool) (Body, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(entity))
+	if err != nil {
+		return Body{}, fmt.Errorf("parsing MIME entity: %w", err)
+	}
+	w := &walker{}
+	if err := w.walk(textproto.MIMEHeader(msg.Header), msg.Body, 0); err != nil {
+		return Body{}, err
+	}
+
+	body := Body{Attachments: w.attachments}
+	switch {
+	case preferHTML && w.html != "":
+		body.Text = w.html
+	case w.haveText:
+		body.Text = w.text
+	case w.html != "":
+		body.Text = htmltext.Render(w.html)
+	}
+	if body.Attachments == nil {
+		body.Attachments = []types.Attachment{}
+	}
+	return body, nil
+}
+
+type walker struct {
+	text        string
+	haveText    bool
+	html        string
+	attachments []types.Attachment
+}
+
+func (w *walker) walk(header textproto.MIMEHeader, r io.Reader, depth int) error {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		if depth >= maxDepth {
+			return fmt.Errorf("MIME parts nested more than %d deep", maxDepth)
+		}
+		mr := multipart.NewReader(r, params["boundary"])
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("reading MIME part: %w", err)
+			}
+			if err := w.walk(part.Header, part, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	data, err := io.ReadAll(transferDecoder(header, r))
+	if err != nil {
+		return fmt.Errorf("decoding MIME part: %w", err)
+	}
+
+	disposition, dispParams, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	name := dispParams["filename"]
+	if name == "" {
+		name = params["name"]
+	}
+	isText := mediaType == "text/plain" || mediaType == "text/html"
+	if isText && disposition != "attachment" && name == "" {
+		text := decodeCharset(data, params["charset"])
+		if mediaType == "text/plain" && !w.haveText {
+			w.text, w.haveText = text, true
+		} else if mediaType == "text/html" && w.html == "" {
+			w.html = text
+		}
+		return nil
+	}
+
+	cid := strings.Trim(header.Get("Content-ID"), "<> ")
+	w.attachments = append(w.attachments, types.Attachment{
+		Name:   mimeword.Decode(name),
+		Type:   mediaType,
+		Size:   uint64(len(data)),
+		Inline: cid != "" && (strings.HasPrefix(mediaType, "image/") || disposition == "inline"),
+	})
+	return nil
+}
+
+// transferDecoder undoes a part's Content-Transfer-Encoding. Parts read
+// through multipart.Reader arrive with quoted-printable already decoded
+// and the header removed.
+func transferDecoder(header textproto.MIMEHeader, r io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(header.Get("Content-Transfer-Encoding"))) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, r)
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	}
+	return r
+}
+
+// decodeCharset converts text from its declared charset to UTF-8, leaving
+// it as is when the charset is UTF-8 or unknown.
+func decodeCharset(data []byte, charset string) string {
+	switch strings.ToLower(charset) {
+	case "", "utf-8", "utf8", "us-ascii", "ascii":
+		return string(data)
+	}
+	enc, err := htmlindex.Get(charset)
+	if err != nil {
+		return string(data)
+	}
+	out, err := enc.NewDecoder().Bytes(data)
+	if err != nil {
+		return string(data)
+	}
+	return string(out)
+}
@@ -0,0 +1,79 @@
+package mimebody
+
+import (
+	"strings"
+	"testing"
+)
+
+const alternative = "Content-Type: multipart/mixed; boundary=outer\r\n" +
+	"\r\n" +
+	"--outer\r\n" +
+	"Content-Type: multipart/alternative; boundary=inner\r\n" +
+	"\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/plain; charset=iso-8859-1\r\n" +
+	"Content-Transfer-Encoding: quoted-printable\r\n" +
+	"\r\n" +
+	"Caf=E9 at noon\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/html; charset=utf-8\r\n" +
+	"\r\n" +
+	"<p>Caf\xc3\xa9 at <b>noon</b></p>\r\n" +
+	"--inner--\r\n" +
+	"--outer\r\n" +
+	"Content-Type: image/png\r\n" +
+	"Content-ID: <logo>\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"iVBORw0K\r\n" +
+	"--outer\r\n" +
+	"Content-Type: application/pdf; name=\"=?UTF-8?Q?r=C3=A9sum=C3=A9.pdf?=\"\r\n" +
+	"Content-Disposition: attachment\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"JVBE\r\n" +
+	"Rg==\r\n" +
+	"--outer--\r\n"
+
+func TestRender_PrefersPlainText(t *testing.T) {
+	body, err := Render([]byte(alternative), false)
+	if err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	if strings.TrimSpace(body.Text) != "Café at noon" {
+		t.Errorf("expected the decoded plain-text part, got %q", body.Text)
+	}
+	if len(body.Attachments) != 2 {
+		t.Fatalf("expected 2 attachments, got %+v", body.Attachments)
+	}
+	logo, pdf := body.Attachments[0], body.Attachments[1]
+	if !logo.Inline || logo.Type != "image/png" || logo.Size != 6 {
+		t.Errorf("expected an inline 6-byte PNG, got %+v", logo)
+	}
+	if pdf.Inline || pdf.Name != "résumé.pdf" || pdf.Size != 4 {
+		t.Errorf("expected a 4-byte résumé.pdf attachment, got %+v", pdf)
+	}
+}
+
+func TestRender_PreferHTML(t *testing.T) {
+	body, err := Render([]byte(alternative), true)
+	if err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	if !strings.Contains(body.Text, "<b>noon</b>") {
+		t.Errorf("expected the HTML part as sent, got %q", body.Text)
+	}
+}
+
+func TestRender_HTMLOnly(t *testing.T) {
+	body, err := Render([]byte("Content-Type: text/html\r\n\r\n<p>Hello <b>there</b></p>\r\n"), false)
+	if err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	if strings.TrimSpace(body.Text) != "Hello there" {
+		t.Errorf("expected the HTML rendered to text, got %q", body.Text)
+	}
+	if body.Attachments == nil {
+		t.Error("expected an empty attachments slice, got nil")
+	}
+}
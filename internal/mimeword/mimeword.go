@@ -0,0 +1,186 @@
+// Package mimeword decodes RFC 2047 encoded-words such as
+// =?UTF-8?B?SsO8cmdlbg==?= in header text.
+//
+// Servers normally decode headers before returning them, but raw header
+// values and some sloppy servers hand them back encoded. Decode also copes
+// with the common encoder bug of splitting a multibyte character across two
+// encoded-words, by joining adjacent words in the same charset before
+// converting them to UTF-8.
+package mimeword
+
+import (
+	"encoding/base64"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+var wordRe = regexp.MustCompile(`=\?([^?\s]+)\?([bBqQ])\?([^?\s]*)\?=`)
+
+// word is one encoded-word: its charset, encoding letter, and encoded text.
+type word struct {
+	start, end int
+	charset    string
+	enc        byte
+	text       string
+}
+
+// Decode returns s with its encoded-words decoded to UTF-8. Whitespace
+// between adjacent encoded-words is dropped, as RFC 2047 requires. Words in
+// an unknown charset, or that fail to decode, are left as they are.
+func Decode(s string) string {
+	if !strings.Contains(s, "=?") {
+		return s
+	}
+	matches := wordRe.FindAllStringSubmatchIndex(s, -1)
+	if matches == nil {
+		return s
+	}
+
+	var b strings.Builder
+	pos := 0
+	var run []word
+	flush := func() {
+		if len(run) == 0 {
+			return
+		}
+		if text, ok := decodeRun(run); ok {
+			b.WriteString(text)
+		} else {
+			b.WriteString(s[run[0].start:run[len(run)-1].end])
+		}
+		run = nil
+	}
+
+	for _, m := range matches {
+		w := word{
+			start:   m[0],
+			end:     m[1],
+			charset: strings.ToLower(s[m[2]:m[3]]),
+			enc:     s[m[4]] | 0x20,
+			text:    s[m[6]:m[7]],
+		}
+		// Drop any RFC 2231 language suffix, as in "utf-8*en".
+		if i := strings.IndexByte(w.charset, '*'); i >= 0 {
+			w.charset = w.charset[:i]
+		}
+
+		gap := s[pos:w.start]
+		adjacent := len(run) > 0 && strings.TrimSpace(gap) == ""
+		if !adjacent {
+			flush()
+			b.WriteString(gap)
+		} else if last := run[len(run)-1]; last.charset != w.charset || last.enc != w.enc {
+			flush()
+		}
+		run = append(run, w)
+		pos = w.end
+	}
+	flush()
+	b.WriteString(s[pos:])
+	return b.String()
+}
+
+// decodeRun decodes adjacent encoded-words sharing a charset and encoding.
+func decodeRun(run []word) (string, bool) {
+	var raw []byte
+	if run[0].enc == 'b' {
+		data, ok := decodeB(run)
+		if !ok {
+			return "", false
+		}
+		raw = data
+	} else {
+		for _, w := range run {
+			raw = append(raw, decodeQ(w.text)...)
+		}
+	}
+	return toUTF8(raw, run[0].charset)
+}
+
+// decodeB decodes the base64 text of a run of B words. Properly padded
+// words are decoded one by one; encoders that split the base64 itself
+// across words get the text joined and decoded as a whole.
+func decodeB(run []word) ([]byte, bool) {
+	padded := true
+	for _, w := range run {
+		if len(w.text)%4 != 0 {
+			padded = false
+			break
+		}
+	}
+	if padded {
+		var out []byte
+		for _, w := range run {
+			data, err := base64.StdEncoding.DecodeString(w.text)
+			if err != nil {
+				return nil, false
+			}
+			out = append(out, data...)
+		}
+		return out, true
+	}
+	var joined strings.Builder
+	for _, w := range run {
+		joined.WriteString(strings.TrimRight(w.text, "="))
+	}
+	data, err := base64.RawStdEncoding.DecodeString(joined.String())
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// decodeQ decodes Q-encoded text. Malformed escapes are kept literally.
+func decodeQ(text string) []byte {
+	out := make([]byte, 0, len(text))
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case c == '_':
+			out = append(out, ' ')
+		case c == '=' && i+2 < len(text) && isHex(text[i+1]) && isHex(text[i+2]):
+			out = append(out, unhex(text[i+1])<<4|unhex(text[i+2]))
+			i += 2
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// toUTF8 converts raw from charset to UTF-8.
+func toUTF8(raw []byte, charset string) (string, bool) {
+	switch charset {
+	case "utf-8", "utf8", "us-ascii", "ascii":
+		if !utf8.Valid(raw) {
+			return strings.ToValidUTF8(string(raw), "�"), true
+		}
+		return string(raw), true
+	}
+	enc, err := htmlindex.Get(charset)
+	if err != nil {
+		return "", false
+	}
+	out, err := enc.NewDecoder().Bytes(raw)
+	if err != nil {
+		return "", false
+	}
+	return string(out), true
+}
+
+func isHex(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
@@ -0,0 +1,32 @@
+package mimeword
+
+import "testing"
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "Hello there", "Hello there"},
+		{"base64", "=?UTF-8?B?SsO8cmdlbiBNw7xsbGVy?= <j@example.com>", "Jürgen Müller <j@example.com>"},
+		{"quoted-printable", "=?utf-8?q?Caf=C3=A9_au_lait?=", "Café au lait"},
+		{"legacy charset", "=?ISO-8859-1?B?R3L832U=?=", "Grüße"},
+		{"text around words", "Re: =?UTF-8?Q?r=C3=A9union?= tomorrow", "Re: réunion tomorrow"},
+		{"whitespace between words dropped", "=?UTF-8?Q?a?= \r\n =?UTF-8?Q?b?=", "ab"},
+		{"character split across words", "=?UTF-8?B?SsM=?= =?UTF-8?B?vHJnZW4=?=", "Jürgen"},
+		{"base64 split across words", "=?UTF-8?B?5pel5p?= =?UTF-8?B?ys6Kqe?=", "日本語"},
+		{"different charsets adjacent", "=?ISO-8859-1?Q?caf=E9?= =?UTF-8?Q?_=C3=A0?=", "café à"},
+		{"language suffix", "=?UTF-8*en?Q?Hello?=", "Hello"},
+		{"unknown charset kept", "=?x-bogus?Q?abc?=", "=?x-bogus?Q?abc?="},
+		{"bad base64 kept", "=?UTF-8?B?!!!!?=", "=?UTF-8?B?!!!!?="},
+		{"not a word", "a =? b ?= c", "a =? b ?= c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Decode(tt.in); got != tt.want {
+				t.Errorf("Decode(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
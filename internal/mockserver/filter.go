@@ -0,0 +1,224 @@
+package mockserver
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// matchFilter evaluates a JMAP Email/query filter against an email. Text
+// conditions are case-insensitive substring matches; thread keyword
+// conditions consult the other emails in all.
+func matchFilter(e map[string]any, filter map[string]any, all []map[string]any) (bool, error) {
+	if filter == nil {
+		return true, nil
+	}
+
+	if op, ok := filter["operator"].(string); ok {
+		conditions, _ := filter["conditions"].([]any)
+		for _, raw := range conditions {
+			cond, _ := raw.(map[string]any)
+			matched, err := matchFilter(e, cond, all)
+			if err != nil {
+				return false, err
+			}
+			switch op {
+			case "AND":
+				if !matched {
+					return false, nil
+				}
+			case "OR":
+				if matched {
+					return true, nil
+				}
+			case "NOT":
+				if matched {
+					return false, nil
+				}
+			default:
+				return false, fmt.Errorf("unknown filter operator %q", op)
+			}
+		}
+		return op != "OR", nil
+	}
+
+	for key, value := range filter {
+		matched, err := matchCondition(e, key, value, all)
+		if err != nil {
+			return false, err
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func matchCondition(e map[string]any, key string, value any, all []map[string]any) (bool, error) {
+	switch key {
+	case "inMailbox":
+		id, _ := value.(string)
+		return inMailbox(e, id), nil
+	case "inMailboxOtherThan":
+		ids, _ := value.([]any)
+		mailboxIDs, _ := e["mailboxIds"].(map[string]any)
+		for id := range mailboxIDs {
+			excluded := false
+			for _, raw := range ids {
+				if raw == id {
+					excluded = true
+				}
+			}
+			if !excluded {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "hasKeyword":
+		kw, _ := value.(string)
+		return hasKeyword(e, kw), nil
+	case "notKeyword":
+		kw, _ := value.(string)
+		return !hasKeyword(e, kw), nil
+	case "someInThreadHaveKeyword", "allInThreadHaveKeyword", "noneInThreadHaveKeyword":
+		kw, _ := value.(string)
+		some, every := false, true
+		for _, other := range all {
+			if other["threadId"] != e["threadId"] {
+				continue
+			}
+			if hasKeyword(other, kw) {
+				some = true
+			} else {
+				every = false
+			}
+		}
+		switch key {
+		case "someInThreadHaveKeyword":
+			return some, nil
+		case "allInThreadHaveKeyword":
+			return every, nil
+		}
+		return !some, nil
+	case "hasAttachment":
+		want, _ := value.(bool)
+		has, _ := e["hasAttachment"].(bool)
+		return has == want, nil
+	case "before", "after":
+		bound, err := time.Parse(time.RFC3339, fmt.Sprint(value))
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", key, err)
+		}
+		received, err := time.Parse(time.RFC3339, fmt.Sprint(e["receivedAt"]))
+		if err != nil {
+			return false, nil
+		}
+		if key == "before" {
+			return received.Before(bound), nil
+		}
+		return !received.Before(bound), nil
+	case "minSize", "maxSize":
+		bound, _ := value.(float64)
+		size, _ := e["size"].(float64)
+		if key == "minSize" {
+			return size >= bound, nil
+		}
+		return size < bound, nil
+	case "from", "to", "cc", "bcc":
+		return containsFold(addressText(e[key]), value), nil
+	case "subject":
+		return containsFold(fmt.Sprint(e["subject"]), value), nil
+	case "body":
+		return containsFold(bodyText(e), value), nil
+	case "header":
+		parts, _ := value.([]any)
+		if len(parts) == 0 {
+			return false, fmt.Errorf("header: expected [name] or [name, value]")
+		}
+		name := fmt.Sprint(parts[0])
+		headers, _ := e["headers"].([]any)
+		for _, raw := range headers {
+			h, _ := raw.(map[string]any)
+			if !strings.EqualFold(fmt.Sprint(h["name"]), name) {
+				continue
+			}
+			if len(parts) == 1 || containsFold(fmt.Sprint(h["value"]), parts[1]) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "text":
+		all := strings.Join([]string{
+			fmt.Sprint(e["subject"]), addressText(e["from"]), addressText(e["to"]),
+			addressText(e["cc"]), fmt.Sprint(e["preview"]), bodyText(e),
+		}, "\n")
+		return containsFold(all, value), nil
+	default:
+		return false, fmt.Errorf("filter condition %q is not supported by the mock server", key)
+	}
+}
+
+func inMailbox(e map[string]any, id string) bool {
+	mailboxIDs, _ := e["mailboxIds"].(map[string]any)
+	included, _ := mailboxIDs[id].(bool)
+	return included
+}
+
+func hasKeyword(e map[string]any, kw string) bool {
+	keywords, _ := e["keywords"].(map[string]any)
+	set, _ := keywords[kw].(bool)
+	return set
+}
+
+func containsFold(haystack string, needle any) bool {
+	return strings.Contains(strings.ToLower(haystack), strings.ToLower(fmt.Sprint(needle)))
+}
+
+// addressText flattens a JMAP address list into "Name <email>" lines.
+func addressText(v any) string {
+	list, _ := v.([]any)
+	var parts []string
+	for _, raw := range list {
+		a, _ := raw.(map[string]any)
+		parts = append(parts, fmt.Sprintf("%v <%v>", a["name"], a["email"]))
+	}
+	return strings.Join(parts, "\n")
+}
+
+func bodyText(e map[string]any) string {
+	values, _ := e["bodyValues"].(map[string]any)
+	var parts []string
+	for _, raw := range values {
+		v, _ := raw.(map[string]any)
+		if s, ok := v["value"].(string); ok {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// compareProperty orders two emails by a sort property, comparing dates as
+// times and other values as numbers or text.
+func compareProperty(a, b map[string]any, property string) int {
+	switch av := a[property].(type) {
+	case float64:
+		bv, _ := b[property].(float64)
+		switch {
+		case av < bv:
+			return -1
+		case av > bv:
+			return 1
+		}
+		return 0
+	default:
+		as, bs := fmt.Sprint(a[property]), fmt.Sprint(b[property])
+		if property == "receivedAt" || property == "sentAt" {
+			at, aErr := time.Parse(time.RFC3339, as)
+			bt, bErr := time.Parse(time.RFC3339, bs)
+			if aErr == nil && bErr == nil {
+				return at.Compare(bt)
+			}
+		}
+		return strings.Compare(as, bs)
+	}
+}
@@ -0,0 +1,560 @@
+// Package mockserver implements an in-memory JMAP server backed by fixture
+// files, for testing fm and scripts built on it without a real account.
+//
+// It supports the session resource and the Mailbox/get, Email/query,
+// Email/get, Email/set, Thread/get, and SearchSnippet/get methods, including
+// result references between calls. Changes made through Email/set are kept in
+// memory and never written back to the fixtures.
+package mockserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AccountID is the single account served by the mock server.
+const AccountID = "mock"
+
+const (
+	// SessionPath is the path of the JMAP session resource.
+	SessionPath = "/jmap/session"
+	apiPath     = "/jmap/api"
+
+	mailboxesFile = "mailboxes.json"
+	emailsFile    = "emails.json"
+)
+
+// Server is an in-memory JMAP server. Fixture objects use JMAP property
+// names; an Email needs at least id, threadId, mailboxIds, and receivedAt.
+type Server struct {
+	mu        sync.Mutex
+	mailboxes []map[string]any
+	emails    []map[string]any
+	state     int
+	nextID    int
+}
+
+// New returns a server holding the given mailboxes and emails.
+func New(mailboxes, emails []map[string]any) *Server {
+	return &Server{mailboxes: mailboxes, emails: emails, state: 1, nextID: 1}
+}
+
+// LoadFixtures reads mailboxes.json and emails.json from dir. Each holds a
+// JSON array of JMAP objects.
+func LoadFixtures(dir string) (*Server, error) {
+	var mailboxes, emails []map[string]any
+	for name, dst := range map[string]*[]map[string]any{mailboxesFile: &mailboxes, emailsFile: &emails} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("reading fixtures: %w", err)
+		}
+		if err := json.Unmarshal(data, dst); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", name, err)
+		}
+	}
+	for i, e := range emails {
+		for _, prop := range []string{"id", "threadId", "receivedAt"} {
+			if _, ok := e[prop].(string); !ok {
+				return nil, fmt.Errorf("%s: email %d has no %s", emailsFile, i, prop)
+			}
+		}
+	}
+	return New(mailboxes, emails), nil
+}
+
+// Counts returns the number of mailboxes and emails held.
+func (s *Server) Counts() (mailboxes, emails int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.mailboxes), len(s.emails)
+}
+
+// ServeHTTP serves the session resource and API endpoint.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == SessionPath:
+		base := "http://" + r.Host
+		writeJSON(w, map[string]any{
+			"capabilities": map[string]any{
+				"urn:ietf:params:jmap:core": map[string]any{},
+				"urn:ietf:params:jmap:mail": map[string]any{},
+			},
+			"accounts": map[string]any{
+				AccountID: map[string]any{"name": "mock@example.com", "isPersonal": true},
+			},
+			"primaryAccounts": map[string]any{"urn:ietf:params:jmap:mail": AccountID},
+			"username":        "mock@example.com",
+			"apiUrl":          base + apiPath,
+			"downloadUrl":     base + "/jmap/download/{accountId}/{blobId}/{name}?type={type}",
+			"uploadUrl":       base + "/jmap/upload/{accountId}",
+			"eventSourceUrl":  base + "/jmap/events",
+			"state":           "session-1",
+		})
+	case r.Method == http.MethodPost && r.URL.Path == apiPath:
+		var req struct {
+			MethodCalls [][3]json.RawMessage `json:"methodCalls"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, map[string]any{
+			"methodResponses": s.handle(req.MethodCalls),
+			"sessionState":    "session-1",
+		})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) handle(calls [][3]json.RawMessage) [][]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var responses [][]any
+	results := make(map[string]any)
+	for _, call := range calls {
+		var name, callID string
+		var args map[string]any
+		if json.Unmarshal(call[0], &name) != nil || json.Unmarshal(call[2], &callID) != nil ||
+			json.Unmarshal(call[1], &args) != nil {
+			responses = append(responses, methodError("invalidArguments", "malformed invocation", callID))
+			continue
+		}
+		if err := resolveReferences(args, results); err != nil {
+			responses = append(responses, methodError("invalidResultReference", err.Error(), callID))
+			continue
+		}
+
+		var result map[string]any
+		var err error
+		switch name {
+		case "Mailbox/get":
+			result = s.mailboxGet(args)
+		case "Email/query":
+			result, err = s.emailQuery(args)
+		case "Email/get":
+			result = s.emailGet(args)
+		case "Email/set":
+			result = s.emailSet(args)
+		case "Thread/get":
+			result = s.threadGet(args)
+		case "SearchSnippet/get":
+			result = searchSnippetGet(args)
+		default:
+			responses = append(responses, methodError("unknownMethod", name+" is not supported by the mock server", callID))
+			continue
+		}
+		if err != nil {
+			errType := "invalidArguments"
+			if errors.Is(err, errAnchorNotFound) {
+				errType = "anchorNotFound"
+			}
+			responses = append(responses, methodError(errType, err.Error(), callID))
+			continue
+		}
+		result["accountId"] = AccountID
+
+		// Round-trip through JSON so later references see plain values.
+		data, _ := json.Marshal(result)
+		var plain any
+		_ = json.Unmarshal(data, &plain)
+		results[callID] = plain
+		responses = append(responses, []any{name, result, callID})
+	}
+	return responses
+}
+
+// errAnchorNotFound is returned by Email/query when its anchor is not in the
+// results.
+var errAnchorNotFound = errors.New("anchor not found")
+
+func methodError(errType, description, callID string) []any {
+	return []any{"error", map[string]any{"type": errType, "description": description}, callID}
+}
+
+// resolveReferences replaces "#name" arguments with the values they point
+// to in earlier results.
+func resolveReferences(args map[string]any, results map[string]any) error {
+	for key, value := range args {
+		if !strings.HasPrefix(key, "#") {
+			continue
+		}
+		ref, _ := value.(map[string]any)
+		resultOf, _ := ref["resultOf"].(string)
+		path, _ := ref["path"].(string)
+		prior, ok := results[resultOf]
+		if !ok {
+			return fmt.Errorf("no result for call %q", resultOf)
+		}
+		resolved, err := evalPointer(prior, strings.Split(strings.TrimPrefix(path, "/"), "/"))
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		delete(args, key)
+		args[strings.TrimPrefix(key, "#")] = resolved
+	}
+	return nil
+}
+
+// evalPointer walks a JSON pointer with the JMAP "*" extension, which maps
+// over arrays and flattens nested arrays.
+func evalPointer(value any, parts []string) (any, error) {
+	if len(parts) == 0 || (len(parts) == 1 && parts[0] == "") {
+		return value, nil
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		child, ok := v[parts[0]]
+		if !ok {
+			return nil, fmt.Errorf("no property %q", parts[0])
+		}
+		return evalPointer(child, parts[1:])
+	case []any:
+		if parts[0] != "*" {
+			return nil, fmt.Errorf("unsupported array index %q", parts[0])
+		}
+		out := []any{}
+		for _, item := range v {
+			r, err := evalPointer(item, parts[1:])
+			if err != nil {
+				return nil, err
+			}
+			if arr, ok := r.([]any); ok {
+				out = append(out, arr...)
+			} else {
+				out = append(out, r)
+			}
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("cannot descend into %T", value)
+	}
+}
+
+func (s *Server) stateString() string {
+	return fmt.Sprintf("s%d", s.state)
+}
+
+func (s *Server) mailboxGet(args map[string]any) map[string]any {
+	ids := stringSet(args["ids"])
+	list := []map[string]any{}
+	for _, mb := range s.mailboxes {
+		id, _ := mb["id"].(string)
+		if ids != nil && !ids[id] {
+			continue
+		}
+		out := copyObject(mb)
+		var total, unread int
+		for _, e := range s.emails {
+			if inMailbox(e, id) {
+				total++
+				if !hasKeyword(e, "$seen") {
+					unread++
+				}
+			}
+		}
+		out["totalEmails"] = total
+		out["unreadEmails"] = unread
+		list = append(list, out)
+	}
+	return map[string]any{"state": s.stateString(), "list": list, "notFound": []string{}}
+}
+
+func (s *Server) emailQuery(args map[string]any) (map[string]any, error) {
+	filter, _ := args["filter"].(map[string]any)
+	var matched []map[string]any
+	for _, e := range s.emails {
+		ok, err := matchFilter(e, filter, s.emails)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matched = append(matched, e)
+		}
+	}
+
+	property, ascending := "receivedAt", false
+	if comparators, ok := args["sort"].([]any); ok && len(comparators) > 0 {
+		c, _ := comparators[0].(map[string]any)
+		if p, ok := c["property"].(string); ok {
+			property = p
+		}
+		ascending, _ = c["isAscending"].(bool)
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		less := compareProperty(matched[i], matched[j], property)
+		if ascending {
+			return less < 0
+		}
+		return less > 0
+	})
+
+	if collapse, _ := args["collapseThreads"].(bool); collapse {
+		seen := make(map[string]bool)
+		kept := matched[:0]
+		for _, e := range matched {
+			thread, _ := e["threadId"].(string)
+			if !seen[thread] {
+				seen[thread] = true
+				kept = append(kept, e)
+			}
+		}
+		matched = kept
+	}
+
+	position := intArg(args["position"])
+	if position < 0 {
+		position = max(len(matched)+position, 0)
+	}
+	if anchor, ok := args["anchor"].(string); ok && anchor != "" {
+		index := slices.IndexFunc(matched, func(e map[string]any) bool { return e["id"] == anchor })
+		if index < 0 {
+			return nil, fmt.Errorf("%w: %s", errAnchorNotFound, anchor)
+		}
+		position = max(index+intArg(args["anchorOffset"]), 0)
+	}
+	ids := []string{}
+	limit := intArg(args["limit"])
+	for i := position; i < len(matched); i++ {
+		if limit > 0 && len(ids) >= limit {
+			break
+		}
+		ids = append(ids, matched[i]["id"].(string))
+	}
+
+	return map[string]any{
+		"queryState":          s.stateString(),
+		"canCalculateChanges": false,
+		"position":            position,
+		"ids":                 ids,
+		"total":               len(matched),
+	}, nil
+}
+
+func (s *Server) emailGet(args map[string]any) map[string]any {
+	props := stringSet(args["properties"])
+	list := []map[string]any{}
+	notFound := []string{}
+
+	var selected []map[string]any
+	if ids, ok := args["ids"].([]any); ok {
+		for _, raw := range ids {
+			id, _ := raw.(string)
+			if e := s.findEmail(id); e != nil {
+				selected = append(selected, e)
+			} else {
+				notFound = append(notFound, id)
+			}
+		}
+	} else {
+		selected = s.emails
+	}
+
+	for _, e := range selected {
+		out := copyObject(e)
+		if props != nil {
+			for key := range out {
+				if key != "id" && !props[key] {
+					delete(out, key)
+				}
+			}
+		}
+		list = append(list, out)
+	}
+	return map[string]any{"state": s.stateString(), "list": list, "notFound": notFound}
+}
+
+func (s *Server) emailSet(args map[string]any) map[string]any {
+	oldState := s.stateString()
+	result := map[string]any{"oldState": oldState}
+	changed := false
+
+	if create, ok := args["create"].(map[string]any); ok {
+		created := map[string]any{}
+		for key, raw := range create {
+			obj, _ := raw.(map[string]any)
+			e := copyObject(obj)
+			id := fmt.Sprintf("mock-%d", s.nextID)
+			s.nextID++
+			e["id"] = id
+			if _, ok := e["threadId"]; !ok {
+				e["threadId"] = "thread-" + id
+			}
+			if _, ok := e["receivedAt"]; !ok {
+				e["receivedAt"] = time.Now().UTC().Format(time.RFC3339)
+			}
+			e["blobId"] = "blob-" + id
+			s.emails = append(s.emails, e)
+			created[key] = map[string]any{"id": id, "threadId": e["threadId"], "blobId": e["blobId"]}
+			changed = true
+		}
+		result["created"] = created
+	}
+
+	if update, ok := args["update"].(map[string]any); ok {
+		updated := map[string]any{}
+		notUpdated := map[string]any{}
+		for id, raw := range update {
+			e := s.findEmail(id)
+			if e == nil {
+				notUpdated[id] = map[string]any{"type": "notFound"}
+				continue
+			}
+			patch, _ := raw.(map[string]any)
+			applyPatch(e, patch)
+			updated[id] = nil
+			changed = true
+		}
+		result["updated"] = updated
+		if len(notUpdated) > 0 {
+			result["notUpdated"] = notUpdated
+		}
+	}
+
+	if destroy, ok := args["destroy"].([]any); ok {
+		destroyed := []string{}
+		notDestroyed := map[string]any{}
+		for _, raw := range destroy {
+			id, _ := raw.(string)
+			if s.removeEmail(id) {
+				destroyed = append(destroyed, id)
+				changed = true
+			} else {
+				notDestroyed[id] = map[string]any{"type": "notFound"}
+			}
+		}
+		result["destroyed"] = destroyed
+		if len(notDestroyed) > 0 {
+			result["notDestroyed"] = notDestroyed
+		}
+	}
+
+	if changed {
+		s.state++
+	}
+	result["newState"] = s.stateString()
+	return result
+}
+
+func (s *Server) threadGet(args map[string]any) map[string]any {
+	list := []map[string]any{}
+	notFound := []string{}
+	ids, _ := args["ids"].([]any)
+	for _, raw := range ids {
+		id, _ := raw.(string)
+		var members []map[string]any
+		for _, e := range s.emails {
+			if e["threadId"] == id {
+				members = append(members, e)
+			}
+		}
+		if len(members) == 0 {
+			notFound = append(notFound, id)
+			continue
+		}
+		sort.SliceStable(members, func(i, j int) bool {
+			return compareProperty(members[i], members[j], "receivedAt") < 0
+		})
+		emailIDs := make([]string, len(members))
+		for i, e := range members {
+			emailIDs[i] = e["id"].(string)
+		}
+		list = append(list, map[string]any{"id": id, "emailIds": emailIDs})
+	}
+	return map[string]any{"state": s.stateString(), "list": list, "notFound": notFound}
+}
+
+func searchSnippetGet(args map[string]any) map[string]any {
+	list := []map[string]any{}
+	ids, _ := args["emailIds"].([]any)
+	for _, raw := range ids {
+		list = append(list, map[string]any{"emailId": raw, "subject": nil, "preview": nil})
+	}
+	return map[string]any{"list": list, "notFound": []string{}}
+}
+
+func (s *Server) findEmail(id string) map[string]any {
+	for _, e := range s.emails {
+		if e["id"] == id {
+			return e
+		}
+	}
+	return nil
+}
+
+func (s *Server) removeEmail(id string) bool {
+	for i, e := range s.emails {
+		if e["id"] == id {
+			s.emails = append(s.emails[:i], s.emails[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// applyPatch applies a JMAP PatchObject: keys are property paths, and null
+// removes the property.
+func applyPatch(obj map[string]any, patch map[string]any) {
+	for path, value := range patch {
+		parts := strings.Split(path, "/")
+		target := obj
+		for _, p := range parts[:len(parts)-1] {
+			child, ok := target[p].(map[string]any)
+			if !ok {
+				child = map[string]any{}
+				target[p] = child
+			}
+			target = child
+		}
+		last := parts[len(parts)-1]
+		if value == nil {
+			delete(target, last)
+		} else {
+			target[last] = value
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func copyObject(obj map[string]any) map[string]any {
+	out := make(map[string]any, len(obj))
+	for k, v := range obj {
+		out[k] = v
+	}
+	return out
+}
+
+func stringSet(v any) map[string]bool {
+	list, ok := v.([]any)
+	if !ok {
+		return nil
+	}
+	set := make(map[string]bool, len(list))
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			set[s] = true
+		}
+	}
+	return set
+}
+
+func intArg(v any) int {
+	if f, ok := v.(float64); ok {
+		return int(f)
+	}
+	return 0
+}
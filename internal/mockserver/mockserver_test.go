@@ -0,0 +1,135 @@
+package mockserver
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cboone/fm/internal/client"
+)
+
+const testMailboxes = `[
+  {"id": "mb-inbox", "name": "Inbox", "role": "inbox"},
+  {"id": "mb-archive", "name": "Archive", "role": "archive"}
+]`
+
+const testEmails = `[
+  {"id": "M1", "threadId": "T1", "subject": "Invoice 42", "receivedAt": "2026-02-14T10:00:00Z",
+   "from": [{"name": "Billing", "email": "billing@example.com"}],
+   "headers": [{"name": "Received", "value": "from mx.acme.example by mail.example.com"}],
+   "mailboxIds": {"mb-inbox": true}, "keywords": {}},
+  {"id": "M2", "threadId": "T2", "subject": "Lunch?", "receivedAt": "2026-02-14T11:00:00Z",
+   "from": [{"name": "Alice", "email": "alice@example.com"}],
+   "mailboxIds": {"mb-inbox": true}, "keywords": {"$seen": true}},
+  {"id": "M3", "threadId": "T1", "subject": "Re: Invoice 42", "receivedAt": "2026-02-13T09:00:00Z",
+   "from": [{"name": "Billing", "email": "billing@example.com"}],
+   "mailboxIds": {"mb-archive": true}, "keywords": {"$seen": true}}
+]`
+
+func newTestClient(t *testing.T) *client.Client {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, data := range map[string]string{mailboxesFile: testMailboxes, emailsFile: testEmails} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o600); err != nil {
+			t.Fatalf("write fixture: %v", err)
+		}
+	}
+	srv, err := LoadFixtures(dir)
+	if err != nil {
+		t.Fatalf("LoadFixtures: %v", err)
+	}
+
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+
+	c, err := client.New(ts.URL+SessionPath, "mock-token", "")
+	if err != nil {
+		t.Fatalf("client.New: %v", err)
+	}
+	return c
+}
+
+func TestQueryFiltersAndSorts(t *testing.T) {
+	c := newTestClient(t)
+
+	result, err := c.SearchEmails(client.SearchOptions{MailboxID: "mb-inbox", Limit: 10})
+	if err != nil {
+		t.Fatalf("SearchEmails: %v", err)
+	}
+	if result.Total != 2 || len(result.Emails) != 2 {
+		t.Fatalf("expected 2 inbox emails, got total %d, %d emails", result.Total, len(result.Emails))
+	}
+	if result.Emails[0].ID != "M2" || result.Emails[1].ID != "M1" {
+		t.Errorf("expected newest first, got %s, %s", result.Emails[0].ID, result.Emails[1].ID)
+	}
+
+	ids, err := c.QueryEmailIDs(client.SearchOptions{From: "billing", UnreadOnly: true})
+	if err != nil {
+		t.Fatalf("QueryEmailIDs: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != "M1" {
+		t.Errorf("expected [M1], got %v", ids)
+	}
+
+	ids, err = c.QueryEmailIDs(client.SearchOptions{Text: "ACME", TextHeader: "received"})
+	if err != nil {
+		t.Fatalf("QueryEmailIDs: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != "M1" {
+		t.Errorf("expected header match [M1], got %v", ids)
+	}
+}
+
+func TestEmailSetUpdatesState(t *testing.T) {
+	c := newTestClient(t)
+
+	succeeded, errs := c.MarkAsRead([]string{"M1", "missing"})
+	if len(succeeded) != 1 || succeeded[0] != "M1" {
+		t.Errorf("expected M1 to be marked read, got %v", succeeded)
+	}
+	if len(errs) != 1 {
+		t.Errorf("expected one error for the missing email, got %v", errs)
+	}
+
+	ids, err := c.QueryEmailIDs(client.SearchOptions{UnreadOnly: true})
+	if err != nil {
+		t.Fatalf("QueryEmailIDs: %v", err)
+	}
+	if len(ids) != 0 {
+		t.Errorf("expected no unread emails after mark-read, got %v", ids)
+	}
+}
+
+func TestMutedThreadsAreExcluded(t *testing.T) {
+	c := newTestClient(t)
+
+	ids, notFound, err := c.ThreadMemberIDs([]string{"M3", "T9"})
+	if err != nil {
+		t.Fatalf("ThreadMemberIDs: %v", err)
+	}
+	if len(ids) != 2 || len(notFound) != 1 || notFound[0] != "T9" {
+		t.Fatalf("expected both T1 emails and T9 not found, got %v, %v", ids, notFound)
+	}
+	if succeeded, errs := c.SetMuted(ids); len(succeeded) != 2 || len(errs) != 0 {
+		t.Fatalf("SetMuted: %v, %v", succeeded, errs)
+	}
+
+	result, err := c.SearchEmails(client.SearchOptions{MailboxID: "mb-inbox", ExcludeMuted: true, Limit: 10})
+	if err != nil {
+		t.Fatalf("SearchEmails: %v", err)
+	}
+	if len(result.Emails) != 1 || result.Emails[0].ID != "M2" {
+		t.Errorf("expected only M2 outside the muted thread, got %v", result.Emails)
+	}
+
+	c.SetUnmuted(ids)
+	result, err = c.SearchEmails(client.SearchOptions{MailboxID: "mb-inbox", ExcludeMuted: true, Limit: 10})
+	if err != nil {
+		t.Fatalf("SearchEmails: %v", err)
+	}
+	if len(result.Emails) != 2 {
+		t.Errorf("expected both inbox emails after unmute, got %d", len(result.Emails))
+	}
+}
@@ -0,0 +1,110 @@
+// Package notification reads the repository, item, and reason of GitHub and
+// GitLab notification emails from their headers: X-GitHub-Reason, List-Id,
+// and Message-ID for GitHub, and the X-GitLab-* headers for GitLab.
+package notification
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Providers of notification emails.
+const (
+	GitHub = "github"
+	GitLab = "gitlab"
+)
+
+// Header is a single raw header field.
+type Header struct {
+	Name  string
+	Value string
+}
+
+// Notification describes what a notification email is about. Kind is the
+// sort of item, such as issue, pull_request, merge_request, commit, or
+// pipeline, and Number its number within the repository; either may be
+// empty when the headers do not say. Reason is why the notification was
+// sent, such as mention or review_requested.
+type Notification struct {
+	Provider string
+	Repo     string
+	Kind     string
+	Number   int
+	Reason   string
+}
+
+// githubKinds maps the path segments of GitHub Message-IDs to kinds.
+var githubKinds = map[string]string{
+	"issues":      "issue",
+	"pull":        "pull_request",
+	"commit":      "commit",
+	"discussions": "discussion",
+	"releases":    "release",
+	"actions":     "workflow_run",
+}
+
+// Parse returns the notification described by headers, and false when the
+// email is not a GitHub or GitLab notification.
+func Parse(headers []Header) (Notification, bool) {
+	get := func(name string) string {
+		for _, h := range headers {
+			if strings.EqualFold(h.Name, name) {
+				return strings.TrimSpace(h.Value)
+			}
+		}
+		return ""
+	}
+
+	if project := get("X-GitLab-Project-Path"); project != "" {
+		return parseGitLab(project, get), true
+	}
+
+	listID := get("List-Id")
+	reason := get("X-GitHub-Reason")
+	if reason == "" && !strings.HasSuffix(strings.TrimRight(listID, "> "), ".github.com") {
+		return Notification{}, false
+	}
+	n := Notification{Provider: GitHub, Reason: reason}
+
+	// Message-IDs look like <owner/repo/pull/123/c456@github.com>.
+	messageID := strings.Trim(get("Message-ID"), "<> ")
+	if path, host, ok := strings.Cut(messageID, "@"); ok && strings.EqualFold(host, "github.com") {
+		parts := strings.Split(path, "/")
+		if len(parts) >= 2 {
+			n.Repo = parts[0] + "/" + parts[1]
+		}
+		if len(parts) >= 3 {
+			n.Kind = githubKinds[parts[2]]
+		}
+		if len(parts) >= 4 && (n.Kind == "issue" || n.Kind == "pull_request" || n.Kind == "discussion") {
+			n.Number, _ = strconv.Atoi(parts[3])
+		}
+	}
+	// List-Id is "owner/repo <repo.owner.github.com>".
+	if n.Repo == "" {
+		if name, _, ok := strings.Cut(listID, "<"); ok {
+			n.Repo = strings.TrimSpace(name)
+		}
+	}
+	return n, true
+}
+
+// parseGitLab reads a GitLab notification for project.
+func parseGitLab(project string, get func(string) string) Notification {
+	n := Notification{Provider: GitLab, Repo: project, Reason: get("X-GitLab-NotificationReason")}
+	for _, item := range []struct{ header, kind string }{
+		{"X-GitLab-MergeRequest-IID", "merge_request"},
+		{"X-GitLab-Issue-IID", "issue"},
+		{"X-GitLab-Pipeline-Id", "pipeline"},
+	} {
+		if v := get(item.header); v != "" {
+			n.Kind = item.kind
+			n.Number, _ = strconv.Atoi(v)
+			return n
+		}
+	}
+	if get("X-GitLab-Commit-ID") != "" {
+		n.Kind = "commit"
+	}
+	return n
+}
@@ -0,0 +1,72 @@
+package notification
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers []Header
+		want    Notification
+		ok      bool
+	}{
+		{
+			name: "github pull request comment",
+			headers: []Header{
+				{"List-ID", "acme/widgets <widgets.acme.github.com>"},
+				{"X-GitHub-Reason", "review_requested"},
+				{"Message-ID", "<acme/widgets/pull/1234/c98765@github.com>"},
+			},
+			want: Notification{Provider: GitHub, Repo: "acme/widgets", Kind: "pull_request", Number: 1234, Reason: "review_requested"},
+			ok:   true,
+		},
+		{
+			name: "github issue",
+			headers: []Header{
+				{"X-GitHub-Reason", "mention"},
+				{"Message-ID", " <acme/widgets/issues/7@github.com>"},
+			},
+			want: Notification{Provider: GitHub, Repo: "acme/widgets", Kind: "issue", Number: 7, Reason: "mention"},
+			ok:   true,
+		},
+		{
+			name: "github workflow run from the list id",
+			headers: []Header{
+				{"List-Id", "acme/widgets <widgets.acme.github.com>"},
+				{"Message-ID", "<abc123@mail.example>"},
+			},
+			want: Notification{Provider: GitHub, Repo: "acme/widgets"},
+			ok:   true,
+		},
+		{
+			name: "gitlab merge request",
+			headers: []Header{
+				{"X-GitLab-Project-Path", "group/sub/project"},
+				{"X-GitLab-MergeRequest-IID", "42"},
+				{"X-GitLab-NotificationReason", "assigned"},
+			},
+			want: Notification{Provider: GitLab, Repo: "group/sub/project", Kind: "merge_request", Number: 42, Reason: "assigned"},
+			ok:   true,
+		},
+		{
+			name: "gitlab pipeline",
+			headers: []Header{
+				{"X-GitLab-Project-Path", "group/project"},
+				{"X-GitLab-Pipeline-Id", "9001"},
+			},
+			want: Notification{Provider: GitLab, Repo: "group/project", Kind: "pipeline", Number: 9001},
+			ok:   true,
+		},
+		{
+			name:    "other mail",
+			headers: []Header{{"List-Id", "Announcements <announce.example.com>"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Parse(tt.headers)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("Parse() = %+v, %v; want %+v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+// Package notmuch writes tag dumps in the batch-tag format read by
+// `notmuch restore`.
+package notmuch
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+// Header is the first line of a tag-only batch-tag dump.
+const Header = "#notmuch-dump batch-tag:3 tags"
+
+// keywordTags maps JMAP system keywords to notmuch's conventional tags.
+// An empty tag means the keyword has no notmuch equivalent.
+var keywordTags = map[string]string{
+	"$seen":      "",
+	"$notjunk":   "",
+	"$flagged":   "flagged",
+	"$answered":  "replied",
+	"$draft":     "draft",
+	"$forwarded": "passed",
+	"$junk":      "spam",
+	"$phishing":  "phishing",
+}
+
+// roleTags maps mailbox roles to notmuch's conventional tags.
+var roleTags = map[string]string{
+	"inbox":  "inbox",
+	"sent":   "sent",
+	"drafts": "draft",
+	"junk":   "spam",
+	"trash":  "deleted",
+}
+
+// Tags returns the sorted notmuch tags for an email. Unread emails are tagged
+// unread, keywords map to their notmuch equivalents (custom keywords pass
+// through without a leading $), and mailboxes become tags by role or name.
+func Tags(e types.EmailTags) []string {
+	set := make(map[string]bool)
+	seen := false
+	for _, kw := range e.Keywords {
+		if kw == "$seen" {
+			seen = true
+		}
+		tag, known := keywordTags[kw]
+		if !known {
+			tag = strings.TrimPrefix(kw, "$")
+		}
+		if tag != "" {
+			set[tag] = true
+		}
+	}
+	if !seen {
+		set["unread"] = true
+	}
+	for _, mb := range e.Mailboxes {
+		if tag, ok := roleTags[mb]; ok {
+			set[tag] = true
+		} else if mb != "" {
+			set[mb] = true
+		}
+	}
+
+	tags := make([]string, 0, len(set))
+	for tag := range set {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// WriteDump writes a batch-tag dump for emails. `notmuch restore` replaces
+// each message's tags with the listed ones.
+func WriteDump(w io.Writer, emails []types.EmailTags) error {
+	if _, err := fmt.Fprintln(w, Header); err != nil {
+		return err
+	}
+	for _, e := range emails {
+		var b strings.Builder
+		for _, tag := range Tags(e) {
+			b.WriteString("+")
+			b.WriteString(encode(tag))
+			b.WriteString(" ")
+		}
+		b.WriteString("-- id:")
+		b.WriteString(encode(e.MessageID))
+		if _, err := fmt.Fprintln(w, b.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encode applies notmuch's hex encoding: bytes outside [A-Za-z0-9@=.,_+-]
+// are written as %XX.
+func encode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9',
+			strings.IndexByte("@=.,_+-", c) >= 0:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02x", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,65 @@
+package notmuch
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+func TestTags(t *testing.T) {
+	tests := []struct {
+		name  string
+		email types.EmailTags
+		want  []string
+	}{
+		{
+			name:  "unread inbox",
+			email: types.EmailTags{Mailboxes: []string{"inbox"}},
+			want:  []string{"inbox", "unread"},
+		},
+		{
+			name:  "read flagged reply",
+			email: types.EmailTags{Keywords: []string{"$answered", "$flagged", "$seen"}, Mailboxes: []string{"archive"}},
+			want:  []string{"archive", "flagged", "replied"},
+		},
+		{
+			name:  "junk and custom keywords",
+			email: types.EmailTags{Keywords: []string{"$junk", "$seen", "$label_work", "todo"}, Mailboxes: []string{"junk"}},
+			want:  []string{"label_work", "spam", "todo"},
+		},
+		{
+			name:  "named mailbox",
+			email: types.EmailTags{Keywords: []string{"$seen"}, Mailboxes: []string{"Receipts", "trash"}},
+			want:  []string{"Receipts", "deleted"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Tags(tt.email); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWriteDump(t *testing.T) {
+	emails := []types.EmailTags{
+		{MessageID: "abc@example.com", Keywords: []string{"$seen"}, Mailboxes: []string{"inbox"}},
+		{MessageID: "x y/z@example.com", Keywords: []string{"$seen"}, Mailboxes: []string{"Paid Bills"}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteDump(&buf, emails); err != nil {
+		t.Fatalf("WriteDump: %v", err)
+	}
+
+	want := Header + "\n" +
+		"+inbox -- id:abc@example.com\n" +
+		"+Paid%20Bills -- id:x%20y%2fz@example.com\n"
+	if buf.String() != want {
+		t.Errorf("unexpected dump:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,62 @@
+package output
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/text/unicode/norm"
+)
+
+// asciiReplacements spells common typographic characters in ASCII.
+var asciiReplacements = map[rune]string{
+	'‘': "'", '’': "'", '‚': "'", '′': "'",
+	'“': `"`, '”': `"`, '„': `"`, '″': `"`,
+	'–': "-", '—': "-", '‐': "-", '−': "-",
+	'…': "...", '•': "*", '·': "*",
+	'\u00a0': " ", '\u200b': "", '\ufe0f': "",
+	'«': "<<", '»': ">>", '×': "x",
+}
+
+// toASCII transliterates s to plain ASCII for dumb terminals, logs, and
+// screen readers. Accents are dropped from letters, common punctuation is
+// spelled out, and anything else becomes "?", padded to the character's
+// display width so columns stay aligned.
+func toASCII(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r <= unicode.MaxASCII {
+			b.WriteRune(r)
+			continue
+		}
+		if repl, ok := asciiReplacements[r]; ok {
+			b.WriteString(repl)
+			continue
+		}
+		if base := stripMarks(r); base != "" {
+			b.WriteString(base)
+			continue
+		}
+		b.WriteString("?")
+		if w := runewidth.RuneWidth(r); w > 1 {
+			b.WriteString(strings.Repeat(" ", w-1))
+		}
+	}
+	return b.String()
+}
+
+// stripMarks returns r's ASCII base letter when r decomposes to one plus
+// combining marks (é to e), or "" otherwise.
+func stripMarks(r rune) string {
+	var base []rune
+	for _, d := range norm.NFD.String(string(r)) {
+		switch {
+		case unicode.Is(unicode.Mn, d):
+		case d <= unicode.MaxASCII:
+			base = append(base, d)
+		default:
+			return ""
+		}
+	}
+	return string(base)
+}
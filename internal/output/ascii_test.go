@@ -0,0 +1,39 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+func TestToASCII(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"plain text", "plain text"},
+		{"Café déjà vu", "Cafe deja vu"},
+		{"“Quoted” — it’s…", `"Quoted" - it's...`},
+		{"Launch 🚀 now", "Launch ?  now"},
+		{"日本", "? ? "},
+	}
+	for _, tt := range tests {
+		if got := toASCII(tt.in); got != tt.want {
+			t.Errorf("toASCII(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTextFormatter_ASCII(t *testing.T) {
+	var buf bytes.Buffer
+	f := &TextFormatter{ASCII: true}
+	if err := f.Format(&buf, types.MoveResult{Matched: 1, Errors: []string{"M1: déjà traité"}}); err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range buf.String() {
+		if r > 127 {
+			t.Fatalf("expected ASCII-only output, got %q", buf.String())
+		}
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("deja traite")) {
+		t.Errorf("expected transliterated error text, got %q", buf.String())
+	}
+}
@@ -0,0 +1,54 @@
+package output
+
+import (
+	"io"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+// Envelope is a Formatter that holds on to a command's result and error
+// instead of writing them, so that Write can report both as one
+// types.Envelope document. The writers passed to Format and FormatError
+// are ignored.
+type Envelope struct {
+	f        Formatter
+	result   any
+	err      *types.AppError
+	warnings []types.AppError
+}
+
+// NewEnvelope returns an Envelope that writes its document with f.
+func NewEnvelope(f Formatter) *Envelope {
+	return &Envelope{f: f}
+}
+
+// Format records v as the result. A command that formats more than one
+// value reports the last.
+func (e *Envelope) Format(_ io.Writer, v any) error {
+	e.result = v
+	return nil
+}
+
+// FormatError records the error. The first error is kept, since later
+// ones are usually consequences of it.
+func (e *Envelope) FormatError(_ io.Writer, code string, message string, hint string) error {
+	if e.err == nil {
+		e.err = &types.AppError{Error: code, Message: message, Hint: hint}
+	}
+	return nil
+}
+
+// Warn records a warning, which does not make the command fail.
+func (e *Envelope) Warn(code string, message string, hint string) {
+	e.warnings = append(e.warnings, types.AppError{Error: code, Message: message, Hint: hint})
+}
+
+// Write writes the envelope to w.
+func (e *Envelope) Write(w io.Writer) error {
+	return e.f.Format(w, types.Envelope{
+		OK:       e.err == nil,
+		Result:   e.result,
+		Error:    e.err,
+		Warnings: e.warnings,
+	})
+}
@@ -0,0 +1,77 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+func TestEnvelope_Success(t *testing.T) {
+	e := NewEnvelope(&JSONFormatter{})
+	if err := e.Format(nil, types.MailboxInfo{ID: "mb-1", Name: "Inbox"}); err != nil {
+		t.Fatal(err)
+	}
+	e.Warn("large_result", "fetched 300 of 450 emails in 2 requests", "")
+
+	var buf bytes.Buffer
+	if err := e.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, buf.String())
+	}
+	if got["ok"] != true || got["error"] != nil {
+		t.Errorf("expected ok with a null error, got %s", buf.String())
+	}
+	if result, _ := got["result"].(map[string]any); result["name"] != "Inbox" {
+		t.Errorf("expected the result, got %s", buf.String())
+	}
+	if warnings, _ := got["warnings"].([]any); len(warnings) != 1 {
+		t.Errorf("expected one warning, got %s", buf.String())
+	}
+}
+
+func TestEnvelope_Failure(t *testing.T) {
+	e := NewEnvelope(&JSONFormatter{})
+	_ = e.Format(nil, types.MoveResult{Matched: 2, Failed: 1})
+	_ = e.FormatError(nil, "partial_failure", "one or more emails failed to move", "Retry")
+	_ = e.FormatError(nil, "general_error", "later error", "")
+
+	var buf bytes.Buffer
+	if err := e.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var got types.Envelope
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.OK || got.Error == nil || got.Error.Error != "partial_failure" {
+		t.Errorf("expected the first error, got %s", buf.String())
+	}
+	if got.Result == nil {
+		t.Errorf("expected the partial result to be kept, got %s", buf.String())
+	}
+	if bytes.Contains(buf.Bytes(), []byte(`"warnings"`)) {
+		t.Errorf("expected no warnings field, got %s", buf.String())
+	}
+}
+
+func TestEnvelope_NoResult(t *testing.T) {
+	e := NewEnvelope(&JSONFormatter{})
+	_ = e.FormatError(nil, "not_found", "no emails matched the given filters", "")
+
+	var buf bytes.Buffer
+	if err := e.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got["result"]; !ok || got["result"] != nil {
+		t.Errorf("expected a null result, got %s", buf.String())
+	}
+}
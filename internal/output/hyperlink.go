@@ -0,0 +1,33 @@
+package output
+
+import (
+	"net/url"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+// fastmailWebURL is the base of links into the Fastmail web app.
+const fastmailWebURL = "https://app.fastmail.com"
+
+// hyperlink wraps text in an OSC 8 terminal hyperlink to target. Without
+// Hyperlinks, or with no target, text is returned unchanged.
+func (f *TextFormatter) hyperlink(text, target string) string {
+	if !f.Hyperlinks || target == "" {
+		return text
+	}
+	return "\x1b]8;;" + target + "\x1b\\" + text + "\x1b]8;;\x1b\\"
+}
+
+// emailWebURL links to an email in the Fastmail web app, which opens a
+// message by its thread and email IDs.
+func emailWebURL(e types.EmailSummary) string {
+	if e.ThreadID == "" {
+		return ""
+	}
+	return fastmailWebURL + "/mail/Inbox/" + url.PathEscape(e.ThreadID) + "." + url.PathEscape(e.ID)
+}
+
+// mailboxWebURL links to a mailbox in the Fastmail web app.
+func mailboxWebURL(mb types.MailboxInfo) string {
+	return fastmailWebURL + "/mail/" + url.PathEscape(mb.Name) + "/"
+}
@@ -0,0 +1,152 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var (
+	redactEmailRe = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}`)
+	redactTokenRe = regexp.MustCompile(`[A-Za-z0-9_\-]{20,}`)
+	redactPhoneRe = regexp.MustCompile(`(?:\+\d{1,3}[\s.\-]?)?(?:\(\d{2,4}\)|\d{2,4})[\s.\-]\d{3,4}[\s.\-]\d{3,4}`)
+)
+
+// Redactor masks email addresses, phone numbers, and long tokens or IDs
+// in output so it can be shared. Each distinct value gets a numbered
+// placeholder such as [email-2], the same one every time it appears, so
+// redacted output still shows which entries share a sender or an ID.
+type Redactor struct {
+	mu    sync.Mutex
+	seen  map[string]string
+	count map[string]int
+}
+
+// NewRedactor returns a Redactor with no placeholders assigned yet.
+func NewRedactor() *Redactor {
+	return &Redactor{seen: map[string]string{}, count: map[string]int{}}
+}
+
+// Redact returns s with personal data replaced by placeholders.
+func (r *Redactor) Redact(s string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s = redactEmailRe.ReplaceAllStringFunc(s, func(m string) string {
+		return r.placeholder("email", strings.ToLower(m))
+	})
+	s = redactTokenRe.ReplaceAllStringFunc(s, func(m string) string {
+		if !strings.ContainsAny(m, "0123456789") || !strings.ContainsFunc(m, isLetter) {
+			return m
+		}
+		return r.placeholder("token", m)
+	})
+	return redactPhoneRe.ReplaceAllStringFunc(s, func(m string) string {
+		return r.placeholder("phone", strings.Map(keepDigit, m))
+	})
+}
+
+func (r *Redactor) placeholder(kind, value string) string {
+	key := kind + "\x00" + value
+	if p, ok := r.seen[key]; ok {
+		return p
+	}
+	r.count[kind]++
+	p := fmt.Sprintf("[%s-%d]", kind, r.count[kind])
+	r.seen[key] = p
+	return p
+}
+
+func isLetter(c rune) bool { return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') }
+
+func keepDigit(c rune) rune {
+	if '0' <= c && c <= '9' {
+		return c
+	}
+	return -1
+}
+
+// redactJSON returns the JSON document data with every string in it
+// redacted. Each string is decoded before it is redacted and encoded again
+// after, so a match cannot swallow part of an escape sequence such as \n
+// and the document stays valid.
+func (r *Redactor) redactJSON(data []byte) []byte {
+	var out bytes.Buffer
+	for i := 0; i < len(data); {
+		if data[i] != '"' {
+			out.WriteByte(data[i])
+			i++
+			continue
+		}
+		end := i + 1
+		for end < len(data) && data[end] != '"' {
+			if data[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(data) {
+			out.Write(data[i:])
+			break
+		}
+		end++
+
+		var s string
+		if err := json.Unmarshal(data[i:end], &s); err != nil {
+			out.Write(data[i:end])
+		} else {
+			out.Write(encodeJSONString(r.Redact(s)))
+		}
+		i = end
+	}
+	return out.Bytes()
+}
+
+// encodeJSONString encodes s as JSONFormatter does, without escaping HTML.
+func encodeJSONString(s string) []byte {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+}
+
+// redactingFormatter redacts everything another Formatter writes.
+type redactingFormatter struct {
+	f Formatter
+	r *Redactor
+}
+
+// Redacted wraps f so that its output passes through r. JSON output is
+// redacted string by string, so it stays valid.
+func Redacted(f Formatter, r *Redactor) Formatter {
+	return &redactingFormatter{f: f, r: r}
+}
+
+func (rf *redactingFormatter) Format(w io.Writer, v any) error {
+	var buf bytes.Buffer
+	if err := rf.f.Format(&buf, v); err != nil {
+		return err
+	}
+	return rf.write(w, buf.Bytes())
+}
+
+func (rf *redactingFormatter) FormatError(w io.Writer, code string, message string, hint string) error {
+	var buf bytes.Buffer
+	if err := rf.f.FormatError(&buf, code, message, hint); err != nil {
+		return err
+	}
+	return rf.write(w, buf.Bytes())
+}
+
+func (rf *redactingFormatter) write(w io.Writer, out []byte) error {
+	if _, ok := rf.f.(*JSONFormatter); ok {
+		_, err := w.Write(rf.r.redactJSON(out))
+		return err
+	}
+	_, err := io.WriteString(w, rf.r.Redact(string(out)))
+	return err
+}
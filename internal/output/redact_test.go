@@ -0,0 +1,91 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+func TestRedactor_Redact(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"From: alice@example.com", "From: [email-1]"},
+		{"Call +1 (555) 123-4567 or 555.123.4567", "Call [phone-1] or [phone-2]"},
+		{"token fmu1-3f9a8c2e7d6b5a4f9e8d7c6b5a", "token [token-1]"},
+		{"id Mf8e1b6c4a2d9e7f3c5b1a0d2", "id [token-1]"},
+		{"received 2026-10-15T09:30:00Z, 24000000 bytes", "received 2026-10-15T09:30:00Z, 24000000 bytes"},
+		{"internationalization is a long word", "internationalization is a long word"},
+		{"short IDs like M123 stay", "short IDs like M123 stay"},
+	}
+	for _, tt := range tests {
+		if got := NewRedactor().Redact(tt.in); got != tt.want {
+			t.Errorf("Redact(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRedactor_StablePlaceholders(t *testing.T) {
+	r := NewRedactor()
+	got := r.Redact("alice@example.com, bob@example.com, Alice@Example.com")
+	if got != "[email-1], [email-2], [email-1]" {
+		t.Errorf("unexpected placeholders: %q", got)
+	}
+	if got := r.Redact("again bob@example.com"); got != "again [email-2]" {
+		t.Errorf("expected placeholders to persist across calls, got %q", got)
+	}
+}
+
+func TestRedacted_JSONStaysValid(t *testing.T) {
+	var buf bytes.Buffer
+	f := Redacted(&JSONFormatter{}, NewRedactor())
+	summary := types.EmailSummary{
+		ID:         "Mf8e1b6c4a2d9e7f3c5b1a0d2",
+		From:       []types.Address{{Name: "Alice", Email: "alice@example.com"}},
+		Subject:    "Call me at 555-123-4567",
+		ReceivedAt: time.Date(2026, 10, 15, 9, 30, 0, 0, time.UTC),
+	}
+	if err := f.Format(&buf, summary); err != nil {
+		t.Fatal(err)
+	}
+	var got types.EmailSummary
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("redacted JSON does not parse: %v\n%s", err, buf.String())
+	}
+	if got.ID != "[token-1]" || got.From[0].Email != "[email-1]" || got.Subject != "Call me at [phone-1]" {
+		t.Errorf("unexpected redacted summary: %+v", got)
+	}
+	if got.From[0].Name != "Alice" {
+		t.Errorf("expected names to be kept, got %q", got.From[0].Name)
+	}
+}
+
+func TestRedacted_JSONMatchesAfterEscapes(t *testing.T) {
+	var buf bytes.Buffer
+	f := Redacted(&JSONFormatter{}, NewRedactor())
+	body := "Thanks,\njohn@example.com\nRef\tABCD1234efgh5678ijkl9012\r\n<tag> & \"quoted\""
+	if err := f.Format(&buf, map[string]string{"body": body}); err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]string
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("redacted JSON does not parse: %v\n%s", err, buf.String())
+	}
+	want := "Thanks,\n[email-1]\nRef\t[token-1]\r\n<tag> & \"quoted\""
+	if got["body"] != want {
+		t.Errorf("expected %q, got %q", want, got["body"])
+	}
+}
+
+func TestRedacted_FormatError(t *testing.T) {
+	var buf bytes.Buffer
+	f := Redacted(&TextFormatter{}, NewRedactor())
+	if err := f.FormatError(&buf, "not_found", "no account matches alice@example.com", ""); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "alice@") || !strings.Contains(buf.String(), "[email-1]") {
+		t.Errorf("expected the address to be redacted, got %q", buf.String())
+	}
+}
@@ -0,0 +1,106 @@
+package output
+
+import (
+	"html/template"
+	"io"
+	"time"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+// SummaryReport is the content of the HTML summary report.
+type SummaryReport struct {
+	Mailbox     string
+	GeneratedAt time.Time
+	Summary     types.SummaryResult
+	// Notable lists the messages called out below the counts: the
+	// flagged emails in the mailbox, newest first.
+	Notable []types.EmailSummary
+}
+
+// WriteSummaryHTML renders r as a self-contained HTML document, styled
+// inline so that it displays the same when sent as an email body.
+func WriteSummaryHTML(w io.Writer, r SummaryReport) error {
+	return summaryTemplate.Execute(w, r)
+}
+
+var summaryTemplate = template.Must(template.New("summary").Funcs(template.FuncMap{
+	"date": func(t time.Time) string { return t.Format("Jan 2, 2006 15:04") },
+	"from": func(addrs []types.Address) string {
+		if len(addrs) == 0 {
+			return ""
+		}
+		return formatAddr(addrs[0])
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Mailbox}} summary</title>
+</head>
+<body style="margin:0;padding:24px;background:#f4f5f7;font-family:-apple-system,BlinkMacSystemFont,'Segoe UI',Helvetica,Arial,sans-serif;color:#1f2328;">
+<div style="max-width:640px;margin:0 auto;background:#ffffff;border-radius:8px;padding:24px;">
+<h1 style="margin:0 0 4px;font-size:22px;">{{.Mailbox}} summary</h1>
+<p style="margin:0 0 20px;color:#656d76;font-size:13px;">Generated {{date .GeneratedAt}}</p>
+<table role="presentation" style="width:100%;border-collapse:collapse;margin-bottom:24px;">
+<tr>
+<td style="padding:12px;background:#f6f8fa;border-radius:6px;text-align:center;"><div style="font-size:26px;font-weight:600;">{{.Summary.Total}}</div><div style="color:#656d76;font-size:13px;">emails</div></td>
+<td style="width:12px;"></td>
+<td style="padding:12px;background:#f6f8fa;border-radius:6px;text-align:center;"><div style="font-size:26px;font-weight:600;">{{.Summary.Unread}}</div><div style="color:#656d76;font-size:13px;">unread</div></td>
+{{- if .Summary.Snoozed}}
+<td style="width:12px;"></td>
+<td style="padding:12px;background:#f6f8fa;border-radius:6px;text-align:center;"><div style="font-size:26px;font-weight:600;">{{.Summary.Snoozed}}</div><div style="color:#656d76;font-size:13px;">snoozed</div></td>
+{{- end}}
+</tr>
+</table>
+{{- if .Notable}}
+<h2 style="font-size:16px;margin:0 0 8px;">Notable messages</h2>
+<table style="width:100%;border-collapse:collapse;margin-bottom:24px;font-size:14px;">
+{{- range .Notable}}
+<tr>
+<td style="padding:6px 0;border-bottom:1px solid #eaeef2;">{{if .IsUnread}}<strong>{{.Subject}}</strong>{{else}}{{.Subject}}{{end}}<div style="color:#656d76;font-size:12px;">{{from .From}}</div></td>
+<td style="padding:6px 0 6px 12px;border-bottom:1px solid #eaeef2;color:#656d76;font-size:12px;white-space:nowrap;text-align:right;">{{date .ReceivedAt}}</td>
+</tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Summary.TopSenders}}
+<h2 style="font-size:16px;margin:0 0 8px;">Top senders</h2>
+<table style="width:100%;border-collapse:collapse;margin-bottom:24px;font-size:14px;">
+{{- range .Summary.TopSenders}}
+<tr>
+<td style="padding:6px 0;border-bottom:1px solid #eaeef2;">{{if .Name}}{{.Name}} <span style="color:#656d76;">&lt;{{.Email}}&gt;</span>{{else}}{{.Email}}{{end}}
+{{- range .Subjects}}<div style="color:#656d76;font-size:12px;">{{.}}</div>{{end}}</td>
+<td style="padding:6px 0 6px 12px;border-bottom:1px solid #eaeef2;text-align:right;font-weight:600;">{{.Count}}</td>
+</tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Summary.TopDomains}}
+<h2 style="font-size:16px;margin:0 0 8px;">Top domains</h2>
+<table style="width:100%;border-collapse:collapse;margin-bottom:24px;font-size:14px;">
+{{- range .Summary.TopDomains}}
+<tr>
+<td style="padding:6px 0;border-bottom:1px solid #eaeef2;">{{.Domain}}</td>
+<td style="padding:6px 0 6px 12px;border-bottom:1px solid #eaeef2;text-align:right;font-weight:600;">{{.Count}}</td>
+</tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Summary.Newsletters}}
+<h2 style="font-size:16px;margin:0 0 8px;">Newsletters and mailing lists</h2>
+<table style="width:100%;border-collapse:collapse;margin-bottom:24px;font-size:14px;">
+{{- range .Summary.Newsletters}}
+<tr>
+<td style="padding:6px 0;border-bottom:1px solid #eaeef2;">{{if .Name}}{{.Name}} <span style="color:#656d76;">&lt;{{.Email}}&gt;</span>{{else}}{{.Email}}{{end}}</td>
+<td style="padding:6px 0 6px 12px;border-bottom:1px solid #eaeef2;text-align:right;font-weight:600;">{{.Count}}</td>
+</tr>
+{{- end}}
+</table>
+{{- end}}
+<p style="margin:0;color:#8c959f;font-size:12px;">Generated by fm summary --html</p>
+</div>
+</body>
+</html>
+`))
@@ -0,0 +1,57 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+func TestWriteSummaryHTML(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteSummaryHTML(&buf, SummaryReport{
+		Mailbox:     "Inbox",
+		GeneratedAt: time.Date(2026, 10, 15, 7, 0, 0, 0, time.UTC),
+		Summary: types.SummaryResult{
+			Total:      42,
+			Unread:     7,
+			TopSenders: []types.SenderStat{{Email: "alice@example.com", Name: "Alice", Count: 5}},
+			TopDomains: []types.DomainStat{{Domain: "example.com", Count: 9}},
+		},
+		Notable: []types.EmailSummary{{
+			ID:         "M1",
+			Subject:    "Contract <draft> & notes",
+			From:       []types.Address{{Name: "Bob", Email: "bob@example.com"}},
+			ReceivedAt: time.Date(2026, 10, 14, 16, 30, 0, 0, time.UTC),
+			IsUnread:   true,
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		"<title>Inbox summary</title>",
+		"Generated Oct 15, 2026 07:00",
+		`<div style="font-size:26px;font-weight:600;">42</div>`,
+		`<div style="font-size:26px;font-weight:600;">7</div>`,
+		"<strong>Contract &lt;draft&gt; &amp; notes</strong>",
+		"Bob &lt;bob@example.com&gt;",
+		"Alice <span",
+		"example.com</td>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in the report:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "snoozed") || strings.Contains(out, "Newsletters") {
+		t.Errorf("expected empty sections to be left out:\n%s", out)
+	}
+	if strings.Contains(out, "<link") || strings.Contains(out, "<script") {
+		t.Errorf("expected a self-contained report:\n%s", out)
+	}
+}
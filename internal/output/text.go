@@ -1,11 +1,14 @@
 package output
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"sort"
 	"strings"
 	"text/tabwriter"
+	"time"
+	"unicode/utf8"
 
 	"github.com/cboone/fm/internal/types"
 	"github.com/mattn/go-runewidth"
@@ -18,13 +21,39 @@ const (
 	maxSubjectWidth = 80
 )
 
-// TextFormatter outputs data as human-readable text.
-type TextFormatter struct{}
+// TextFormatter outputs data as human-readable text. With Color set, search
+// terms are highlighted in email lists using ANSI escapes. With Hyperlinks
+// set, subjects and mailbox names link to the Fastmail web app using OSC 8
+// escapes. With ASCII set, output is transliterated to plain ASCII.
+type TextFormatter struct {
+	Color      bool
+	Hyperlinks bool
+	ASCII      bool
+}
+
+const (
+	ansiHighlight = "\x1b[1;33m"
+	ansiReset     = "\x1b[0m"
+)
 
 func (f *TextFormatter) Format(w io.Writer, v any) error {
+	if f.ASCII {
+		var buf bytes.Buffer
+		plain := &TextFormatter{Color: f.Color, Hyperlinks: f.Hyperlinks}
+		if err := plain.Format(&buf, v); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, toASCII(buf.String()))
+		return err
+	}
+
 	switch val := v.(type) {
 	case types.SessionInfo:
 		return f.formatSession(w, val)
+	case types.CapabilitiesResult:
+		return f.formatCapabilities(w, val)
+	case []types.AccountEntry:
+		return f.formatAccounts(w, val)
 	case []types.MailboxInfo:
 		return f.formatMailboxes(w, val)
 	case types.EmailListResult:
@@ -39,6 +68,24 @@ func (f *TextFormatter) Format(w io.Writer, v any) error {
 		return f.formatStats(w, val)
 	case types.SummaryResult:
 		return f.formatSummary(w, val)
+	case types.UnansweredThreadsResult:
+		return f.formatUnansweredThreads(w, val)
+	case types.InvitesResult:
+		return f.formatInvites(w, val)
+	case types.TravelResult:
+		return f.formatTravel(w, val)
+	case types.BlobInfo:
+		return f.formatBlobInfo(w, val)
+	case types.AuthStatus:
+		return f.formatAuthStatus(w, val)
+	case types.KeywordSummaryResult:
+		return f.formatKeywordSummary(w, val)
+	case types.SearchCountResult:
+		return f.formatSearchCount(w, val)
+	case types.MailboxStatsResult:
+		return f.formatMailboxStats(w, val)
+	case types.SendersResult:
+		return f.formatSenders(w, val)
 	case types.DryRunResult:
 		return f.formatDryRunResult(w, val)
 	case types.DraftResult:
@@ -51,14 +98,78 @@ func (f *TextFormatter) Format(w io.Writer, v any) error {
 		return f.formatSieveCreateResult(w, val)
 	case types.SieveDeleteResult:
 		return f.formatSieveDeleteResult(w, val)
+	case types.MailboxRenameResult:
+		return f.formatMailboxRenameResult(w, val)
+	case types.MailboxVerifyResult:
+		return f.formatMailboxVerifyResult(w, val)
+	case types.AttachmentGrepResult:
+		return f.formatAttachmentGrepResult(w, val)
 	case types.SieveActivateResult:
 		return f.formatSieveActivateResult(w, val)
 	case types.SieveValidateResult:
 		return f.formatSieveValidateResult(w, val)
 	case types.SieveDryRunResult:
 		return f.formatSieveDryRunResult(w, val)
+	case types.SieveDiffResult:
+		return f.formatSieveDiffResult(w, val)
+	case types.SieveExportResult:
+		return f.formatSieveExportResult(w, val)
+	case types.ExportResult:
+		return f.formatExportResult(w, val)
+	case types.SizeResult:
+		return f.formatSize(w, val)
+	case types.AgingResult:
+		return f.formatAging(w, val)
+	case types.InitResult:
+		return f.formatInitResult(w, val)
+	case types.ParticipantsResult:
+		return f.formatParticipants(w, val)
+	case types.AddressesResult:
+		return f.formatAddresses(w, val)
+	case types.StateResult:
+		return f.formatState(w, val)
+	case types.PlanVerifyResult:
+		return f.formatPlanVerifyResult(w, val)
+	case types.DownloadResult:
+		return f.formatDownloadResult(w, val)
+	case types.HTMLBodyResult:
+		return f.formatHTMLBodyResult(w, val)
+	case types.DecryptedMessageResult:
+		return f.formatDecryptedMessageResult(w, val)
+	case types.DaemonStatus:
+		return f.formatDaemonStatus(w, val)
+	case types.SavedImagesResult:
+		return f.formatSavedImagesResult(w, val)
+	case types.CleanSuggestResult:
+		return f.formatCleanSuggest(w, val)
+	case types.SQLiteExportResult:
+		return f.formatSQLiteExportResult(w, val)
+	case types.ContactsExtractResult:
+		return f.formatContactsExtractResult(w, val)
+	case types.ExecResult:
+		return f.formatExecResult(w, val)
+	case types.WatchEvent:
+		return f.formatWatchEvent(w, val)
+	case types.ConfigPathsResult:
+		return f.formatConfigPaths(w, val)
+	case types.ConfigEnvResult:
+		return f.formatConfigEnv(w, val)
+	case types.ConfigCheckResult:
+		return f.formatConfigCheck(w, val)
+	case types.MockServerInfo:
+		return f.formatMockServerInfo(w, val)
+	case types.KeywordListResult:
+		return f.formatKeywordList(w, val)
 	case types.UnsubscribeResult:
 		return f.formatUnsubscribeResult(w, val)
+	case types.AuthCheckResult:
+		return f.formatAuthCheckResult(w, val)
+	case types.MaskedEmailListResult:
+		return f.formatMaskedEmailList(w, val)
+	case types.MaskedEmailRotateResult:
+		return f.formatMaskedEmailRotateResult(w, val)
+	case types.MaskedEmailDryRunResult:
+		return f.formatMaskedEmailDryRunResult(w, val)
 	default:
 		// Fall back to JSON formatter for unknown types.
 		return (&JSONFormatter{}).Format(w, v)
@@ -92,8 +203,124 @@ func (f *TextFormatter) formatSession(w io.Writer, s types.SessionInfo) error {
 	return nil
 }
 
-func (f *TextFormatter) formatMailboxes(w io.Writer, mailboxes []types.MailboxInfo) error {
+func (f *TextFormatter) formatAuthStatus(w io.Writer, s types.AuthStatus) error {
+	_, _ = fmt.Fprintf(w, "Token valid for %s\n", s.SessionURL)
+	_, _ = fmt.Fprintf(w, "Username: %s\n", s.Username)
+	account := s.AccountID
+	if s.AccountName != "" {
+		account += " - " + s.AccountName
+	}
+	if s.ReadOnly {
+		account += " (read-only)"
+	}
+	_, _ = fmt.Fprintf(w, "Account: %s\n", account)
+	_, _ = fmt.Fprintf(w, "Capabilities: %s\n", strings.Join(s.Capabilities, ", "))
+	_, _ = fmt.Fprintf(w, "Account capabilities: %s\n", strings.Join(s.AccountCapabilities, ", "))
+	if s.TokenIssuedAt != nil {
+		age := ""
+		if s.TokenAgeSeconds != nil {
+			age = fmt.Sprintf(" (%s ago)", time.Duration(*s.TokenAgeSeconds)*time.Second)
+		}
+		_, _ = fmt.Fprintf(w, "Token issued: %s%s\n", s.TokenIssuedAt.Local().Format("2006-01-02 15:04"), age)
+	}
+	if s.TokenExpiresAt != nil {
+		_, _ = fmt.Fprintf(w, "Token expires: %s\n", s.TokenExpiresAt.Local().Format("2006-01-02 15:04"))
+	}
+	return nil
+}
+
+func (f *TextFormatter) formatCapabilities(w io.Writer, r types.CapabilitiesResult) error {
+	_, _ = fmt.Fprintf(w, "Server: %s\n", r.Server)
+	_, _ = fmt.Fprintln(w, "Capabilities:")
+	for _, c := range r.Capabilities {
+		_, _ = fmt.Fprintf(w, "  %s\n", c)
+	}
+
+	limit := func(n uint64) string {
+		if n == 0 {
+			return "(not advertised)"
+		}
+		return fmt.Sprintf("%d", n)
+	}
+	_, _ = fmt.Fprintln(w, "Limits:")
 	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "  maxSizeUpload:\t%s\n", limit(r.Limits.MaxSizeUpload))
+	_, _ = fmt.Fprintf(tw, "  maxConcurrentUpload:\t%s\n", limit(r.Limits.MaxConcurrentUpload))
+	_, _ = fmt.Fprintf(tw, "  maxSizeRequest:\t%s\n", limit(r.Limits.MaxSizeRequest))
+	_, _ = fmt.Fprintf(tw, "  maxConcurrentRequests:\t%s\n", limit(r.Limits.MaxConcurrentRequests))
+	_, _ = fmt.Fprintf(tw, "  maxCallsInRequest:\t%s\n", limit(r.Limits.MaxCallsInRequest))
+	_, _ = fmt.Fprintf(tw, "  maxObjectsInGet:\t%s\n", limit(r.Limits.MaxObjectsInGet))
+	_, _ = fmt.Fprintf(tw, "  maxObjectsInSet:\t%s\n", limit(r.Limits.MaxObjectsInSet))
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintln(w, "Features:")
+	for _, feat := range r.Features {
+		status := "available"
+		if !feat.Available {
+			status = "unavailable"
+		}
+		_, _ = fmt.Fprintf(w, "  %s: %s (%s; fm %s)\n",
+			feat.Feature, status, feat.Capability, strings.Join(feat.Commands, ", "))
+	}
+
+	sorts := "(not advertised)"
+	if len(r.SortOptions) > 0 {
+		sorts = strings.Join(r.SortOptions, ", ")
+	}
+	_, _ = fmt.Fprintf(w, "Sort options: %s\n", sorts)
+	headerFilter := "yes"
+	if !r.HeaderFilter {
+		headerFilter = "no (filtered client-side)"
+	}
+	_, _ = fmt.Fprintf(w, "Header filter: %s\n", headerFilter)
+	return nil
+}
+
+func (f *TextFormatter) formatAccounts(w io.Writer, accounts []types.AccountEntry) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, a := range accounts {
+		marker := " "
+		if a.Active {
+			marker = "*"
+		}
+		kind := "shared"
+		if a.IsPersonal {
+			kind = "personal"
+		}
+		if a.IsReadOnly {
+			kind += ", read-only"
+		}
+		primary := make([]string, len(a.PrimaryFor))
+		for i, uri := range a.PrimaryFor {
+			primary[i] = capabilityShortName(uri)
+		}
+		roles := ""
+		if len(primary) > 0 {
+			roles = "primary: " + strings.Join(primary, ", ")
+		}
+		_, _ = fmt.Fprintf(tw, "%s %s\t%s\t%s\t%s\n", marker, a.Name, a.ID, kind, roles)
+	}
+	return tw.Flush()
+}
+
+// capabilityShortName shortens a capability URI for display:
+// urn:ietf:params:jmap:mail becomes mail, and vendor URIs keep their last
+// path segment.
+func capabilityShortName(uri string) string {
+	if name, ok := strings.CutPrefix(uri, "urn:ietf:params:jmap:"); ok {
+		return name
+	}
+	if i := strings.LastIndex(uri, "/"); i >= 0 && i < len(uri)-1 {
+		return uri[i+1:]
+	}
+	return uri
+}
+
+func (f *TextFormatter) formatMailboxes(w io.Writer, mailboxes []types.MailboxInfo) error {
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
 	for _, mb := range mailboxes {
 		role := ""
 		if mb.Role != "" {
@@ -102,7 +329,20 @@ func (f *TextFormatter) formatMailboxes(w io.Writer, mailboxes []types.MailboxIn
 		_, _ = fmt.Fprintf(tw, "%s\t%s\ttotal:%d\tunread:%d\t%s\n",
 			mb.Name, mb.ID, mb.TotalEmails, mb.UnreadEmails, role)
 	}
-	return tw.Flush()
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	// Links are added after alignment since tabwriter would count the
+	// escapes as columns. Each line starts with its mailbox's name.
+	lines := strings.SplitAfter(buf.String(), "\n")
+	for i, mb := range mailboxes {
+		if rest, ok := strings.CutPrefix(lines[i], mb.Name); ok {
+			lines[i] = f.hyperlink(mb.Name, mailboxWebURL(mb)) + rest
+		}
+	}
+	_, err := io.WriteString(w, strings.Join(lines, ""))
+	return err
 }
 
 func (f *TextFormatter) formatEmailList(w io.Writer, result types.EmailListResult) error {
@@ -110,28 +350,31 @@ func (f *TextFormatter) formatEmailList(w io.Writer, result types.EmailListResul
 
 	// First pass: build display strings with truncation and track max column widths.
 	type displayRow struct {
-		unread  string
+		marks   string
 		from    string
 		subject string
 		date    string
 	}
 
 	rows := make([]displayRow, len(result.Emails))
+	maxMarks := 1
 	maxFrom := 0
 	maxSubject := 0
 
 	for i, e := range result.Emails {
-		unread := " "
-		if e.IsUnread {
-			unread = "*"
-		}
+		marks := emailMarks(e)
+		maxMarks = max(maxMarks, len(marks))
 		from := ""
-		if len(e.From) > 0 {
+		if result.ShowRecipients {
+			if len(e.To) > 0 {
+				from = truncate("To: "+formatAddr(e.To[0]), maxFromWidth)
+			}
+		} else if len(e.From) > 0 {
 			from = truncate(formatAddr(e.From[0]), maxFromWidth)
 		}
 		subject := truncate(e.Subject, maxSubjectWidth)
 
-		rows[i] = displayRow{unread, from, subject, e.ReceivedAt.Format("2006-01-02 15:04")}
+		rows[i] = displayRow{marks, from, subject, e.ReceivedAt.Format("2006-01-02 15:04")}
 
 		fromWidth := runewidth.StringWidth(from)
 		if fromWidth > maxFrom {
@@ -143,26 +386,98 @@ func (f *TextFormatter) formatEmailList(w io.Writer, result types.EmailListResul
 		}
 	}
 
-	// Second pass: print with computed widths for aligned columns.
+	// Second pass: print with computed widths for aligned columns. Padding
+	// is measured on the plain subject since escapes take no columns.
 	for i, r := range rows {
-		_, _ = fmt.Fprintf(w, "%s %s  %s  %s\n", r.unread,
+		subject := f.hyperlink(f.highlight(r.subject, result.Highlight), emailWebURL(result.Emails[i]))
+		padding := strings.Repeat(" ", maxSubject-runewidth.StringWidth(r.subject))
+		_, _ = fmt.Fprintf(w, "%-*s %s  %s%s  %s\n", maxMarks, r.marks,
 			runewidth.FillRight(r.from, maxFrom),
-			runewidth.FillRight(r.subject, maxSubject),
+			subject, padding,
 			r.date)
-		if len(result.Emails[i].To) > 0 {
-			_, _ = fmt.Fprintf(w, "  To: %s\n", formatAddrs(result.Emails[i].To))
+		// With ShowRecipients a single recipient is already in the first column.
+		if to := result.Emails[i].To; len(to) > 1 || (len(to) == 1 && !result.ShowRecipients) {
+			_, _ = fmt.Fprintf(w, "  To: %s\n", formatAddrs(to))
 		}
 		if len(result.Emails[i].CC) > 0 {
 			_, _ = fmt.Fprintf(w, "  CC: %s\n", formatAddrs(result.Emails[i].CC))
 		}
-		_, _ = fmt.Fprintf(w, "  ID: %s\n", result.Emails[i].ID)
+		if until := result.Emails[i].SnoozedUntil; until != nil {
+			_, _ = fmt.Fprintf(w, "  Snoozed until: %s\n", until.Format("2006-01-02 15:04"))
+		}
+		_, _ = fmt.Fprintf(w, "  ID: %s (%%%d)\n", result.Emails[i].ID, i+1)
 		if result.Emails[i].Snippet != "" {
-			_, _ = fmt.Fprintf(w, "  ...%s\n", result.Emails[i].Snippet)
+			_, _ = fmt.Fprintf(w, "  ...%s\n", f.highlightSnippet(result.Emails[i].Snippet, result.Highlight))
 		}
 	}
+	if len(result.Destroyed) > 0 {
+		_, _ = fmt.Fprintf(w, "\nDestroyed: %s\n", strings.Join(result.Destroyed, ", "))
+	}
+	if result.State != "" {
+		_, _ = fmt.Fprintf(w, "\nState: %s\n", result.State)
+	}
 	return nil
 }
 
+// emailMarks returns the status column of an email in a listing: * when
+// unread, then ! when flagged, F when forwarded, and D for a draft.
+func emailMarks(e types.EmailSummary) string {
+	marks := " "
+	if e.IsUnread {
+		marks = "*"
+	}
+	if e.IsFlagged {
+		marks += "!"
+	}
+	if e.IsForwarded {
+		marks += "F"
+	}
+	if e.IsDraft {
+		marks += "D"
+	}
+	return marks
+}
+
+// highlight wraps case-insensitive occurrences of terms in s with ANSI
+// highlight escapes. Without Color, s is returned unchanged.
+func (f *TextFormatter) highlight(s string, terms []string) string {
+	if !f.Color || len(terms) == 0 {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		match := 0
+		for _, t := range terms {
+			if len(t) > match && i+len(t) <= len(s) && strings.EqualFold(s[i:i+len(t)], t) {
+				match = len(t)
+			}
+		}
+		if match > 0 {
+			b.WriteString(ansiHighlight + s[i:i+match] + ansiReset)
+			i += match
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		b.WriteString(s[i : i+size])
+		i += size
+	}
+	return b.String()
+}
+
+// highlightSnippet highlights a server search snippet. The server marks its
+// own hits with <mark> tags; with Color those become highlights, otherwise
+// the snippet is printed as received.
+func (f *TextFormatter) highlightSnippet(s string, terms []string) string {
+	if !f.Color {
+		return s
+	}
+	if strings.Contains(s, "<mark>") {
+		return strings.NewReplacer("<mark>", ansiHighlight, "</mark>", ansiReset).Replace(s)
+	}
+	return f.highlight(s, terms)
+}
+
 func (f *TextFormatter) formatEmailDetail(w io.Writer, e types.EmailDetail) error {
 	_, _ = fmt.Fprintf(w, "Subject: %s\n", e.Subject)
 	_, _ = fmt.Fprintf(w, "From: %s\n", formatAddrs(e.From))
@@ -177,21 +492,69 @@ func (f *TextFormatter) formatEmailDetail(w io.Writer, e types.EmailDetail) erro
 	if e.ListUnsubscribePost != "" {
 		_, _ = fmt.Fprintf(w, "List-Unsubscribe-Post: %s\n", e.ListUnsubscribePost)
 	}
-	_, _ = fmt.Fprintf(w, "ID: %s\n", e.ID)
+	if e.ID != "" {
+		_, _ = fmt.Fprintf(w, "ID: %s\n", e.ID)
+	}
+	if e.Signature != nil {
+		_, _ = fmt.Fprintf(w, "Signature: %s\n", formatSignature(*e.Signature))
+	}
+	if e.Decrypted {
+		_, _ = fmt.Fprintln(w, "Encryption: PGP/MIME, decrypted")
+	}
+	if e.IsDraft {
+		_, _ = fmt.Fprintln(w, "Status: draft, not sent")
+	}
 	_, _ = fmt.Fprintln(w, strings.Repeat("-", 72))
 	_, _ = fmt.Fprintln(w, e.Body)
+	if e.BodyTruncated {
+		_, _ = fmt.Fprintln(w, "[Body truncated; use --full to read all of it]")
+	}
 	if len(e.Attachments) > 0 {
 		_, _ = fmt.Fprintln(w, strings.Repeat("-", 72))
 		_, _ = fmt.Fprintf(w, "Attachments (%d):\n", len(e.Attachments))
 		for _, a := range e.Attachments {
-			_, _ = fmt.Fprintf(w, "  - %s (%s, %d bytes)\n", a.Name, a.Type, a.Size)
+			inline := ""
+			if a.Inline {
+				inline = ", inline"
+			}
+			_, _ = fmt.Fprintf(w, "  - %s (%s, %d bytes%s)\n", a.Name, a.Type, a.Size, inline)
 		}
 	}
+	if e.HiddenInline > 0 {
+		_, _ = fmt.Fprintf(w, "(%d inline part(s) not listed; use --include-inline to show them)\n", e.HiddenInline)
+	}
 	return nil
 }
 
+// formatSignature describes a signature check as, for example,
+// "valid (PGP, Bob <bob@example.com>)".
+func formatSignature(s types.Signature) string {
+	var about []string
+	switch s.Type {
+	case "smime":
+		about = append(about, "S/MIME")
+	case "pgp":
+		about = append(about, "PGP")
+	}
+	if s.Signer != "" {
+		about = append(about, s.Signer)
+	}
+	line := strings.ReplaceAll(s.Status, "_", " ")
+	if len(about) > 0 {
+		line += " (" + strings.Join(about, ", ") + ")"
+	}
+	if s.Detail != "" {
+		line += ": " + s.Detail
+	}
+	return line
+}
+
 func (f *TextFormatter) formatThreadView(w io.Writer, tv types.ThreadView) error {
-	_, _ = fmt.Fprintf(w, "Thread (%d messages):\n\n", len(tv.Thread))
+	_, _ = fmt.Fprintf(w, "Thread (%d messages):\n", len(tv.Thread))
+	if tv.Stats.Messages > 0 {
+		formatThreadStats(w, tv.Stats)
+	}
+	_, _ = fmt.Fprintln(w)
 	for i, te := range tv.Thread {
 		marker := "  "
 		if te.ID == tv.Email.ID {
@@ -210,10 +573,35 @@ func (f *TextFormatter) formatThreadView(w io.Writer, tv types.ThreadView) error
 	return f.formatEmailDetail(w, tv.Email)
 }
 
+func formatThreadStats(w io.Writer, s types.ThreadStats) {
+	participants := make([]string, len(s.Participants))
+	for i, p := range s.Participants {
+		participants[i] = fmt.Sprintf("%s (%d sent)", formatAddr(types.Address{Name: p.Name, Email: p.Email}), p.Sent)
+	}
+	_, _ = fmt.Fprintf(w, "  Participants: %s\n", strings.Join(participants, ", "))
+	_, _ = fmt.Fprintf(w, "  Span: %s to %s (%s)\n",
+		s.FirstAt.Format("2006-01-02 15:04"), s.LastAt.Format("2006-01-02 15:04"), formatSpan(s.LastAt.Sub(s.FirstAt)))
+	_, _ = fmt.Fprintf(w, "  Unread: %d  Attachments: %d bytes\n", s.Unread, s.AttachmentBytes)
+}
+
+// formatSpan renders d in days, hours, or minutes, whichever reads best.
+func formatSpan(d time.Duration) string {
+	switch {
+	case d >= 48*time.Hour:
+		return fmt.Sprintf("%d days", int(d/(24*time.Hour)))
+	case d >= 2*time.Hour:
+		return fmt.Sprintf("%d hours", int(d/time.Hour))
+	default:
+		return fmt.Sprintf("%d minutes", int(d/time.Minute))
+	}
+}
+
 func actionVerb(r types.MoveResult) (string, int) {
 	switch {
 	case r.Archived != nil:
 		return "Archived", len(r.Archived)
+	case r.Phishing != nil:
+		return "Reported as phishing", len(r.Phishing)
 	case r.MarkedSpam != nil:
 		return "Marked as spam", len(r.MarkedSpam)
 	case r.MarkedAsRead != nil:
@@ -222,6 +610,18 @@ func actionVerb(r types.MoveResult) (string, int) {
 		return "Flagged", len(r.Flagged)
 	case r.Unflagged != nil:
 		return "Unflagged", len(r.Unflagged)
+	case r.Muted != nil:
+		return "Muted", len(r.Muted)
+	case r.Unmuted != nil:
+		return "Unmuted", len(r.Unmuted)
+	case r.KeywordSet != nil:
+		return "Set keyword " + r.Keyword + " on", len(r.KeywordSet)
+	case r.KeywordClear != nil:
+		return "Cleared keyword " + r.Keyword + " from", len(r.KeywordClear)
+	case r.KeywordMigrated != nil:
+		return "Migrated keyword " + r.Keyword + " to " + r.NewKeyword + " on", len(r.KeywordMigrated)
+	case r.Restored != nil:
+		return "Restored", len(r.Restored)
 	case r.Moved != nil:
 		return "Moved", len(r.Moved)
 	default:
@@ -240,6 +640,39 @@ func (f *TextFormatter) formatMoveResult(w io.Writer, r types.MoveResult) error
 			verb, count, r.Matched, r.Failed)
 	}
 
+	if len(r.AddedTo) > 0 {
+		_, _ = fmt.Fprintf(w, "Added to: %s\n", destinationNames(r.AddedTo))
+	}
+	if len(r.RemovedFrom) > 0 {
+		_, _ = fmt.Fprintf(w, "Removed from: %s\n", destinationNames(r.RemovedFrom))
+	}
+	if r.NoOp > 0 {
+		_, _ = fmt.Fprintf(w, "Skipped %d already done\n", r.NoOp)
+	}
+	for _, path := range r.CreatedMailboxes {
+		_, _ = fmt.Fprintf(w, "Created mailbox: %s\n", path)
+	}
+	for _, path := range r.Evidence {
+		_, _ = fmt.Fprintf(w, "Saved evidence: %s\n", path)
+	}
+
+	// Per-email results, from --verbose, already include every error.
+	if r.Results != nil {
+		_, _ = fmt.Fprintf(w, "Results:\n")
+		maxID := 0
+		for _, o := range r.Results {
+			maxID = max(maxID, runewidth.StringWidth(o.ID))
+		}
+		for _, o := range r.Results {
+			line := runewidth.FillRight(o.ID, maxID) + "  " + o.Status
+			if o.Reason != "" {
+				line += ": " + o.Reason
+			}
+			_, _ = fmt.Fprintf(w, "  %s\n", line)
+		}
+		return nil
+	}
+
 	if len(r.Errors) > 0 {
 		_, _ = fmt.Fprintf(w, "Errors:\n")
 		for _, e := range r.Errors {
@@ -249,6 +682,15 @@ func (f *TextFormatter) formatMoveResult(w io.Writer, r types.MoveResult) error
 	return nil
 }
 
+// destinationNames lists mailboxes by name, separated by commas.
+func destinationNames(mailboxes []types.DestinationInfo) string {
+	names := make([]string, len(mailboxes))
+	for i, mb := range mailboxes {
+		names[i] = mb.Name
+	}
+	return strings.Join(names, ", ")
+}
+
 func (f *TextFormatter) formatDryRunResult(w io.Writer, r types.DryRunResult) error {
 	_, _ = fmt.Fprintf(w, "Dry run: would %s %d email(s)\n", r.Operation, r.Count)
 
@@ -300,6 +742,15 @@ func (f *TextFormatter) formatDryRunResult(w io.Writer, r types.DryRunResult) er
 	if r.Destination != nil {
 		_, _ = fmt.Fprintf(w, "\nDestination: %s (%s)\n", r.Destination.Name, r.Destination.ID)
 	}
+	if len(r.AddTo) > 0 || len(r.RemoveFrom) > 0 {
+		_, _ = fmt.Fprintln(w)
+	}
+	if len(r.AddTo) > 0 {
+		_, _ = fmt.Fprintf(w, "Add to: %s\n", destinationNames(r.AddTo))
+	}
+	if len(r.RemoveFrom) > 0 {
+		_, _ = fmt.Fprintf(w, "Remove from: %s\n", destinationNames(r.RemoveFrom))
+	}
 
 	if len(r.NotFound) > 0 {
 		_, _ = fmt.Fprintf(w, "\nNot found: %s\n", strings.Join(r.NotFound, ", "))
@@ -339,8 +790,36 @@ func (f *TextFormatter) formatStats(w io.Writer, r types.StatsResult) error {
 	return nil
 }
 
+func (f *TextFormatter) formatSenders(w io.Writer, r types.SendersResult) error {
+	if r.After != nil {
+		_, _ = fmt.Fprintf(w, "Total: %d emails from %d senders since %s\n", r.Total, len(r.Senders), r.After.Format("2006-01-02"))
+	} else {
+		_, _ = fmt.Fprintf(w, "Total: %d emails from %d senders\n", r.Total, len(r.Senders))
+	}
+
+	if len(r.Senders) == 0 {
+		return nil
+	}
+
+	_, _ = fmt.Fprintln(w)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	_, _ = fmt.Fprintln(tw, "COUNT\tUNREAD\tLAST SEEN\t")
+	for _, s := range r.Senders {
+		sender := s.Email
+		if s.Name != "" {
+			sender += "  " + s.Name
+		}
+		_, _ = fmt.Fprintf(tw, "%d\t%d%%\t%s\t  %s\n", s.Count, int(s.UnreadRatio*100+0.5), s.LastSeen.Format("2006-01-02"), sender)
+	}
+	return tw.Flush()
+}
+
 func (f *TextFormatter) formatSummary(w io.Writer, r types.SummaryResult) error {
 	_, _ = fmt.Fprintf(w, "Total: %d emails (%d unread)\n", r.Total, r.Unread)
+	if r.Snoozed > 0 {
+		_, _ = fmt.Fprintf(w, "Snoozed: %d emails hidden until their wake-up time\n", r.Snoozed)
+	}
 
 	if len(r.TopSenders) > 0 {
 		_, _ = fmt.Fprintln(w, "\nTop senders:")
@@ -401,6 +880,98 @@ func (f *TextFormatter) formatSummary(w io.Writer, r types.SummaryResult) error
 	return nil
 }
 
+func (f *TextFormatter) formatUnansweredThreads(w io.Writer, r types.UnansweredThreadsResult) error {
+	if r.Total == 0 {
+		_, _ = fmt.Fprintf(w, "%s: no conversations waiting on a reply for over %d days\n", r.Mailbox, r.Days)
+		return nil
+	}
+	_, _ = fmt.Fprintf(w, "%s: %d conversation(s) waiting on a reply for over %d days\n\n", r.Mailbox, r.Total, r.Days)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "WAITING\tFROM\tSUBJECT\tID")
+	for _, t := range r.Threads {
+		subject := t.Subject
+		if t.Messages > 1 {
+			subject = fmt.Sprintf("%s (%d)", subject, t.Messages)
+		}
+		_, _ = fmt.Fprintf(tw, "%dd\t%s\t%s\t%s\n", t.WaitingDays, formatAddrs(t.From), subject, t.EmailID)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if len(r.Threads) < r.Total {
+		_, _ = fmt.Fprintf(w, "(%d more; raise --limit to show them)\n", r.Total-len(r.Threads))
+	}
+	return nil
+}
+
+func (f *TextFormatter) formatInvites(w io.Writer, r types.InvitesResult) error {
+	kind := "invitation(s)"
+	if r.Pending {
+		kind = "invitation(s) awaiting your response"
+	}
+	if r.Total == 0 {
+		_, _ = fmt.Fprintf(w, "%s: no %s\n", r.Mailbox, strings.Replace(kind, "(s)", "s", 1))
+		return nil
+	}
+	_, _ = fmt.Fprintf(w, "%s: %d %s\n\n", r.Mailbox, r.Total, kind)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if r.Pending {
+		_, _ = fmt.Fprintln(tw, "START\tEVENT\tORGANIZER\tID")
+	} else {
+		_, _ = fmt.Fprintln(tw, "START\tSTATUS\tEVENT\tORGANIZER\tID")
+	}
+	for _, inv := range r.Invitations {
+		start := inv.Start.Local().Format("2006-01-02 15:04")
+		if inv.AllDay {
+			start = inv.Start.Format("2006-01-02") + " all day"
+		}
+		organizer := formatAddr(inv.Organizer)
+		if r.Pending {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", start, inv.Summary, organizer, inv.EmailID)
+		} else {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", start, inv.Status, inv.Summary, organizer, inv.EmailID)
+		}
+	}
+	return tw.Flush()
+}
+
+func (f *TextFormatter) formatTravel(w io.Writer, r types.TravelResult) error {
+	after := r.After.Local().Format("2006-01-02")
+	if r.Total == 0 {
+		_, _ = fmt.Fprintf(w, "No trips after %s\n", after)
+		return nil
+	}
+	_, _ = fmt.Fprintf(w, "%d trip(s) after %s\n", r.Total, after)
+
+	for _, trip := range r.Trips {
+		_, _ = fmt.Fprintf(w, "\n%s to %s\n", trip.Start.Local().Format("2006-01-02"), trip.End.Local().Format("2006-01-02"))
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, res := range trip.Reservations {
+			var what string
+			switch res.Kind {
+			case "flight":
+				what = res.FlightNumber
+				if res.From != "" && res.To != "" {
+					what += " " + res.From + " -> " + res.To
+				}
+			default:
+				what = res.Name
+				if res.End != nil {
+					what += " until " + res.End.Local().Format("2006-01-02")
+				}
+			}
+			_, _ = fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\t%s\n", res.Start.Local().Format("2006-01-02 15:04"),
+				res.Kind, what, res.Provider, res.ReservationID, res.EmailID)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (f *TextFormatter) formatDraftResult(w io.Writer, r types.DraftResult) error {
 	_, _ = fmt.Fprintf(w, "Draft created: %s\n", r.ID)
 	_, _ = fmt.Fprintf(w, "Mode: %s\n", r.Mode)
@@ -485,6 +1056,53 @@ func (f *TextFormatter) formatSieveDeleteResult(w io.Writer, r types.SieveDelete
 	return nil
 }
 
+func (f *TextFormatter) formatMailboxRenameResult(w io.Writer, r types.MailboxRenameResult) error {
+	if r.DryRun {
+		_, _ = fmt.Fprintf(w, "Dry run: would rename mailbox %s -> %s\n", r.PreviousPath, r.Path)
+		return nil
+	}
+	_, _ = fmt.Fprintf(w, "Renamed mailbox: %s -> %s\n", r.PreviousPath, r.Path)
+	return nil
+}
+
+func (f *TextFormatter) formatMailboxVerifyResult(w io.Writer, r types.MailboxVerifyResult) error {
+	if r.Discrepancies == 0 {
+		_, _ = fmt.Fprintf(w, "Counts match in all %d mailbox(es)\n", r.Checked)
+	} else {
+		_, _ = fmt.Fprintf(w, "Counts differ in %d of %d mailbox(es)\n", r.Discrepancies, r.Checked)
+	}
+	for _, m := range r.Mailboxes {
+		status := "ok"
+		if !m.Match {
+			status = "MISMATCH"
+		}
+		_, _ = fmt.Fprintf(w, "  %-30s total %d reported, %d found; unread %d reported, %d found  %s\n",
+			m.Name, m.ReportedTotal, m.ActualTotal, m.ReportedUnread, m.ActualUnread, status)
+	}
+	return nil
+}
+
+func (f *TextFormatter) formatAttachmentGrepResult(w io.Writer, r types.AttachmentGrepResult) error {
+	lastEmail := ""
+	for _, m := range r.Matches {
+		if m.EmailID != lastEmail {
+			from := ""
+			if len(m.From) > 0 {
+				from = formatAddr(m.From[0])
+			}
+			_, _ = fmt.Fprintf(w, "%s  %s  %s\n", m.EmailID, from, m.Subject)
+			lastEmail = m.EmailID
+		}
+		_, _ = fmt.Fprintf(w, "  %s:%d: %s\n", m.Attachment, m.Line, m.Text)
+	}
+	_, _ = fmt.Fprintf(w, "%d match(es) in %d attachment(s) of %d email(s)", len(r.Matches), r.Searched, r.Emails)
+	if r.Skipped > 0 {
+		_, _ = fmt.Fprintf(w, "; %d attachment(s) skipped", r.Skipped)
+	}
+	_, _ = fmt.Fprintln(w)
+	return nil
+}
+
 func (f *TextFormatter) formatSieveActivateResult(w io.Writer, r types.SieveActivateResult) error {
 	if r.IsActive {
 		_, _ = fmt.Fprintf(w, "Activated sieve script: %s\n", r.ID)
@@ -527,6 +1145,436 @@ func (f *TextFormatter) formatSieveDryRunResult(w io.Writer, r types.SieveDryRun
 	return nil
 }
 
+func (f *TextFormatter) formatSieveDiffResult(w io.Writer, r types.SieveDiffResult) error {
+	if r.Changed == 0 {
+		_, _ = fmt.Fprintf(w, "No differences: %d script(s) match %s\n", len(r.Scripts), r.Local)
+		return nil
+	}
+
+	_, _ = fmt.Fprintf(w, "%d of %d script(s) differ from %s\n", r.Changed, len(r.Scripts), r.Local)
+	for _, s := range r.Scripts {
+		switch s.Status {
+		case "same":
+			continue
+		case "server_only":
+			_, _ = fmt.Fprintf(w, "\nOnly on server: %s\n", s.Name)
+		case "local_only":
+			_, _ = fmt.Fprintf(w, "\nOnly in %s: %s\n", r.Local, s.File)
+		default:
+			_, _ = fmt.Fprintf(w, "\nChanged: %s\n", s.Name)
+		}
+		_, _ = fmt.Fprint(w, s.Diff)
+	}
+	return nil
+}
+
+func (f *TextFormatter) formatSieveExportResult(w io.Writer, r types.SieveExportResult) error {
+	_, _ = fmt.Fprintf(w, "Exported %d script(s) to %s\n", len(r.Scripts), r.Output)
+	for _, s := range r.Scripts {
+		active := ""
+		if s.IsActive {
+			active = " (active)"
+		}
+		_, _ = fmt.Fprintf(w, "  %s%s\n", s.File, active)
+	}
+	return nil
+}
+
+func (f *TextFormatter) formatExportResult(w io.Writer, r types.ExportResult) error {
+	if r.Incremental {
+		_, _ = fmt.Fprintf(w, "Appended %d new email(s) to %s\n", r.Exported, r.Output)
+	} else {
+		_, _ = fmt.Fprintf(w, "Exported %d email(s) to %s\n", r.Exported, r.Output)
+	}
+	return nil
+}
+
+func (f *TextFormatter) formatSize(w io.Writer, r types.SizeResult) error {
+	scope := "all mail"
+	if r.Mailbox != "" {
+		scope = r.Mailbox
+	}
+	_, _ = fmt.Fprintf(w, "Largest %d of %d emails in %s (%s)\n", len(r.Emails), r.Total, scope, humanSize(r.ListedSize))
+	if len(r.Emails) == 0 {
+		return nil
+	}
+
+	_, _ = fmt.Fprintln(w)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "SIZE\tDATE\tATT\tFROM\tSUBJECT\tID")
+	for _, e := range r.Emails {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\n", humanSize(e.Size),
+			e.ReceivedAt.Format("2006-01-02"), e.Attachments,
+			formatAddrs(e.From), truncate(e.Subject, 50), e.ID)
+	}
+	return tw.Flush()
+}
+
+func (f *TextFormatter) formatMailboxStats(w io.Writer, r types.MailboxStatsResult) error {
+	_, _ = fmt.Fprintf(w, "Scanned %d emails (%s)\n", r.Scanned, humanSize(r.TotalSize))
+	if len(r.Mailboxes) == 0 {
+		return nil
+	}
+
+	_, _ = fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "SIZE\tSHARE\tEMAILS\tMAILBOX")
+	for _, m := range r.Mailboxes {
+		share := 0.0
+		if r.TotalSize > 0 {
+			share = float64(m.Size) / float64(r.TotalSize) * 100
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%.1f%%\t%d\t%s\n", humanSize(m.Size), share, m.Emails, m.Name)
+	}
+	return tw.Flush()
+}
+
+func (f *TextFormatter) formatAging(w io.Writer, r types.AgingResult) error {
+	_, _ = fmt.Fprintf(w, "%s: %d emails, %d unread\n\n", r.Mailbox, r.Total, r.Unread)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	_, _ = fmt.Fprintln(tw, "TOTAL\tUNREAD\t  AGE")
+	for _, b := range r.Buckets {
+		_, _ = fmt.Fprintf(tw, "%d\t%d\t  %s\n", b.Total, b.Unread, strings.ReplaceAll(b.Name, "_", " "))
+	}
+	return tw.Flush()
+}
+
+// humanSize formats a byte count with a binary unit, such as 12.4 MB.
+func humanSize(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func (f *TextFormatter) formatInitResult(w io.Writer, r types.InitResult) error {
+	_, _ = fmt.Fprintf(w, "Connected as %s (account %s)\n", r.Username, r.AccountID)
+	if r.Keychain {
+		_, _ = fmt.Fprintln(w, "Token stored in the OS keychain")
+	} else {
+		_, _ = fmt.Fprintf(w, "Token read with: %s\n", r.CredentialCommand)
+	}
+	_, _ = fmt.Fprintf(w, "Wrote %s\n", r.ConfigFile)
+	_, _ = fmt.Fprintf(w, "Inbox: %d emails\n", r.InboxTotal)
+	_, _ = fmt.Fprintln(w, "\nTry: fm list --format text")
+	return nil
+}
+
+func (f *TextFormatter) formatParticipants(w io.Writer, r types.ParticipantsResult) error {
+	_, _ = fmt.Fprintf(w, "%d participants in %d emails\n", len(r.Participants), r.Emails)
+	if len(r.Participants) == 0 {
+		return nil
+	}
+
+	_, _ = fmt.Fprintln(w)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	_, _ = fmt.Fprintln(tw, "COUNT\tFROM\tTO\tCC\t")
+	for _, p := range r.Participants {
+		addr := p.Email
+		if p.Name != "" {
+			addr += "  " + p.Name
+		}
+		_, _ = fmt.Fprintf(tw, "%d\t%d\t%d\t%d\t  %s\n", p.Count, p.From, p.To, p.CC, addr)
+	}
+	return tw.Flush()
+}
+
+func (f *TextFormatter) formatState(w io.Writer, r types.StateResult) error {
+	_, _ = fmt.Fprintf(w, "Session: %s\n", r.Session)
+	_, _ = fmt.Fprintf(w, "Email:   %s\n", r.Email)
+	_, _ = fmt.Fprintf(w, "Mailbox: %s\n", r.Mailbox)
+	_, _ = fmt.Fprintf(w, "Thread:  %s\n", r.Thread)
+	return nil
+}
+
+func (f *TextFormatter) formatPlanVerifyResult(w io.Writer, r types.PlanVerifyResult) error {
+	op := r.Operation
+	if r.Destination != nil {
+		op += " to " + r.Destination.Name
+	}
+	_, _ = fmt.Fprintf(w, "Verified %s: %d of %d planned change(s) applied\n", op, len(r.Applied), r.Checked)
+	if len(r.NotApplied) > 0 {
+		_, _ = fmt.Fprintf(w, "Not applied: %s\n", strings.Join(r.NotApplied, ", "))
+	}
+	if len(r.Missing) > 0 {
+		_, _ = fmt.Fprintf(w, "Missing: %s\n", strings.Join(r.Missing, ", "))
+	}
+	return nil
+}
+
+// formatAddresses prints one address per line with no header, so the
+// output can be piped straight into an allowlist or an import.
+func (f *TextFormatter) formatAddresses(w io.Writer, r types.AddressesResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	for _, a := range r.Addresses {
+		addr := a.Email
+		if r.Names {
+			addr = formatAddr(types.Address{Name: a.Name, Email: a.Email})
+		}
+		if r.Counts {
+			_, _ = fmt.Fprintf(tw, "%d\t  %s\n", a.Count, addr)
+		} else {
+			_, _ = fmt.Fprintln(tw, addr)
+		}
+	}
+	return tw.Flush()
+}
+
+func (f *TextFormatter) formatDownloadResult(w io.Writer, r types.DownloadResult) error {
+	if len(r.Files) == 0 {
+		_, _ = fmt.Fprintf(w, "No attachments in %s\n", r.ID)
+		return nil
+	}
+	for _, file := range r.Files {
+		kind := "attachment"
+		if file.Inline {
+			kind = "inline image"
+		}
+		_, _ = fmt.Fprintf(w, "Saved %s %s (%s, %d bytes)\n", kind, file.File, file.Type, file.Size)
+		if file.TextFile != "" {
+			_, _ = fmt.Fprintf(w, "Saved its text as %s\n", file.TextFile)
+		}
+	}
+	return nil
+}
+
+func (f *TextFormatter) formatBlobInfo(w io.Writer, b types.BlobInfo) error {
+	_, _ = fmt.Fprintf(w, "Blob: %s\n", b.BlobID)
+	_, _ = fmt.Fprintf(w, "Size: %d bytes\n", b.Size)
+	_, _ = fmt.Fprintf(w, "Type: %s\n", b.Type)
+	_, _ = fmt.Fprintf(w, "SHA-256: %s\n", b.SHA256)
+	return nil
+}
+
+func (f *TextFormatter) formatHTMLBodyResult(w io.Writer, r types.HTMLBodyResult) error {
+	_, _ = fmt.Fprintf(w, "Wrote HTML body of %s (%d bytes) to %s\n", r.ID, r.Bytes, r.Output)
+	if r.RemoteBlocked {
+		_, _ = fmt.Fprintln(w, "Remote content is blocked")
+	}
+	return nil
+}
+
+func (f *TextFormatter) formatDaemonStatus(w io.Writer, r types.DaemonStatus) error {
+	_, _ = fmt.Fprintf(w, "Daemon %d for %s (%s) on %s\n", r.PID, r.Username, r.Server, r.Socket)
+	_, _ = fmt.Fprintf(w, "Started: %s\n", r.StartedAt.Format("2006-01-02 15:04:05"))
+	_, _ = fmt.Fprintf(w, "Requests: %d (%d from cache, cached for %gs)\n", r.Requests, r.CacheHits, r.CacheTTLSeconds)
+	u := r.Upstream
+	_, _ = fmt.Fprintf(w, "Upstream: %d requests, %d retries, %d rate limited, %d server errors\n",
+		u.Requests, u.Retries, u.RateLimited, u.ServerErrors)
+	return nil
+}
+
+func (f *TextFormatter) formatSavedImagesResult(w io.Writer, r types.SavedImagesResult) error {
+	_, _ = fmt.Fprintf(w, "Saved %d remote image(s) of %s to %s\n", len(r.Saved), r.ID, r.Dir)
+	for _, img := range r.Saved {
+		_, _ = fmt.Fprintf(w, "  %s (%s)  %s\n", img.File, humanSize(uint64(img.Bytes)), img.URL)
+	}
+	if len(r.Blocked) > 0 {
+		_, _ = fmt.Fprintf(w, "Blocked (not in remote_image_domains): %d\n", len(r.Blocked))
+		for _, u := range r.Blocked {
+			_, _ = fmt.Fprintf(w, "  %s\n", u)
+		}
+	}
+	if len(r.Failed) > 0 {
+		_, _ = fmt.Fprintf(w, "Failed: %d\n", len(r.Failed))
+		for _, fail := range r.Failed {
+			_, _ = fmt.Fprintf(w, "  %s: %s\n", fail.URL, fail.Error)
+		}
+	}
+	return nil
+}
+
+func (f *TextFormatter) formatDecryptedMessageResult(w io.Writer, r types.DecryptedMessageResult) error {
+	_, _ = fmt.Fprintf(w, "Wrote decrypted message of %s (%d bytes) to %s\n", r.ID, r.Bytes, r.Output)
+	return nil
+}
+
+func (f *TextFormatter) formatCleanSuggest(w io.Writer, r types.CleanSuggestResult) error {
+	_, _ = fmt.Fprintf(w, "%d unread mailing-list senders in %s since %s (%d list emails scanned)\n",
+		len(r.Suggestions), r.Mailbox, r.Since.Format("2006-01-02"), r.Scanned)
+	for _, s := range r.Suggestions {
+		sender := s.Email
+		if s.Name != "" {
+			sender += "  " + s.Name
+		}
+		_, _ = fmt.Fprintf(w, "\n%s\n", sender)
+		_, _ = fmt.Fprintf(w, "  %d unread, last %s\n", s.Count, s.LastReceived.Format("2006-01-02"))
+		_, _ = fmt.Fprintf(w, "  unsubscribe:    %s\n", s.Unsubscribe)
+		_, _ = fmt.Fprintf(w, "  archive:        %s\n", s.Archive)
+		_, _ = fmt.Fprintf(w, "  archive filter: %s\n", s.ArchiveFilter)
+	}
+	return nil
+}
+
+func (f *TextFormatter) formatSQLiteExportResult(w io.Writer, r types.SQLiteExportResult) error {
+	_, _ = fmt.Fprintf(w, "Exported %d email(s) to %s\n", r.Exported, r.Output)
+	return nil
+}
+
+func (f *TextFormatter) formatContactsExtractResult(w io.Writer, r types.ContactsExtractResult) error {
+	_, _ = fmt.Fprintf(w, "Wrote %d contact(s) to %s from %d vCard attachment(s) in %d email(s)",
+		r.Contacts, r.Output, r.Attachments, r.Emails)
+	if r.Duplicates > 0 {
+		_, _ = fmt.Fprintf(w, "; %d duplicate(s) left out", r.Duplicates)
+	}
+	_, _ = fmt.Fprintln(w)
+	return nil
+}
+
+func (f *TextFormatter) formatExecResult(w io.Writer, r types.ExecResult) error {
+	_, _ = fmt.Fprintf(w, "Ran %s for %d email(s): %d succeeded, %d failed\n",
+		strings.Join(r.Command, " "), r.Matched, r.Succeeded, r.Failed)
+	for _, run := range r.Runs {
+		status := fmt.Sprintf("exit %d", run.ExitCode)
+		if run.Error != "" {
+			status = run.Error
+		}
+		_, _ = fmt.Fprintf(w, "\n== %s (%s)\n", run.EmailID, status)
+		_, _ = fmt.Fprint(w, run.Stdout)
+		if run.Stderr != "" {
+			_, _ = fmt.Fprint(w, run.Stderr)
+		}
+	}
+	if len(r.NotFound) > 0 {
+		_, _ = fmt.Fprintf(w, "\nNot found: %s\n", strings.Join(r.NotFound, ", "))
+	}
+	return nil
+}
+
+func (f *TextFormatter) formatWatchEvent(w io.Writer, e types.WatchEvent) error {
+	_, _ = fmt.Fprintf(w, "%s  %s  %s  %s\n",
+		e.Email.ReceivedAt.Format("2006-01-02 15:04"), e.Email.ID, formatAddrs(e.Email.From), e.Email.Subject)
+	return nil
+}
+
+func (f *TextFormatter) formatConfigPaths(w io.Writer, r types.ConfigPathsResult) error {
+	configFile := r.ConfigFile
+	if !r.ConfigExists {
+		configFile += " (not found)"
+	}
+	_, _ = fmt.Fprintf(w, "Config file:   %s\n", configFile)
+	_, _ = fmt.Fprintf(w, "Config dir:    %s\n", r.ConfigDir)
+	_, _ = fmt.Fprintf(w, "Cache dir:     %s\n", r.CacheDir)
+	_, _ = fmt.Fprintf(w, "Result cache:  %s\n", r.ResultCache)
+	_, _ = fmt.Fprintf(w, "Export state:  %s\n", r.ExportState)
+	return nil
+}
+
+func (f *TextFormatter) formatConfigEnv(w io.Writer, r types.ConfigEnvResult) error {
+	for _, v := range r.Variables {
+		state := "unset"
+		switch {
+		case v.Value != "":
+			state = "= " + v.Value
+		case v.Set:
+			state = "(set)"
+		}
+		_, _ = fmt.Fprintf(w, "%-22s %s\n", v.Name, state)
+		_, _ = fmt.Fprintf(w, "  %s\n", v.Description)
+	}
+	return nil
+}
+
+func (f *TextFormatter) formatConfigCheck(w io.Writer, r types.ConfigCheckResult) error {
+	configFile := r.ConfigFile
+	if configFile == "" {
+		configFile = "(none)"
+	}
+	_, _ = fmt.Fprintf(w, "Config file: %s\n", configFile)
+	for _, s := range r.Settings {
+		_, _ = fmt.Fprintf(w, "  %-20s %-8s %s\n", s.Key, s.Source, s.Value)
+	}
+	if r.Valid {
+		_, _ = fmt.Fprintln(w, "No problems found")
+		return nil
+	}
+	_, _ = fmt.Fprintf(w, "%d problem(s):\n", len(r.Problems))
+	for _, p := range r.Problems {
+		line := fmt.Sprintf("  %s: %s", p.Key, p.Message)
+		if p.Suggestion != "" {
+			line += fmt.Sprintf(" (did you mean %s?)", p.Suggestion)
+		}
+		_, _ = fmt.Fprintln(w, line)
+	}
+	return nil
+}
+
+func (f *TextFormatter) formatMockServerInfo(w io.Writer, r types.MockServerInfo) error {
+	_, _ = fmt.Fprintf(w, "Serving %d mailbox(es) and %d email(s) at %s\n", r.Mailboxes, r.Emails, r.SessionURL)
+	return nil
+}
+
+func (f *TextFormatter) formatKeywordList(w io.Writer, r types.KeywordListResult) error {
+	_, _ = fmt.Fprintf(w, "Scanned %d emails, %d keywords in use\n", r.Scanned, len(r.Keywords))
+	if len(r.Keywords) == 0 {
+		return nil
+	}
+
+	_, _ = fmt.Fprintln(w)
+	countWidth := len(fmt.Sprintf("%d", r.Keywords[0].Count))
+	for _, k := range r.Keywords {
+		_, _ = fmt.Fprintf(w, "%*d  %s\n", countWidth, k.Count, k.Keyword)
+	}
+	return nil
+}
+
+func (f *TextFormatter) formatKeywordSummary(w io.Writer, r types.KeywordSummaryResult) error {
+	a := r.Account
+	_, _ = fmt.Fprintf(w, "Scanned %d emails: %d flagged, %d flagged without a reply\n", r.Scanned, a.Flagged, a.FlaggedUnanswered)
+	if len(r.Mailboxes) == 0 {
+		return nil
+	}
+
+	_, _ = fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "MAILBOX\tTOTAL\tFLAGGED\tUNANSWERED\tANSWERED\tFORWARDED\tKEYWORDS")
+	for _, s := range r.Mailboxes {
+		keywords := make([]string, len(s.Keywords))
+		for i, k := range s.Keywords {
+			keywords[i] = fmt.Sprintf("%s (%d)", k.Keyword, k.Count)
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%s\n", s.Mailbox, s.Total, s.Flagged,
+			s.FlaggedUnanswered, s.Answered, s.Forwarded, strings.Join(keywords, ", "))
+	}
+	return tw.Flush()
+}
+
+// histogramWidth is the length of the longest bar formatSearchCount draws.
+const histogramWidth = 40
+
+func (f *TextFormatter) formatSearchCount(w io.Writer, r types.SearchCountResult) error {
+	_, _ = fmt.Fprintf(w, "%d emails\n", r.Total)
+	if len(r.Groups) == 0 {
+		return nil
+	}
+
+	peak := 0
+	for _, g := range r.Groups {
+		peak = max(peak, g.Count)
+	}
+	_, _ = fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "%s\tCOUNT\t\n", strings.ToUpper(r.GroupBy))
+	for _, g := range r.Groups {
+		key := g.Key
+		if key == "" {
+			key = "(none)"
+		}
+		bar := max(1, g.Count*histogramWidth/peak)
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%s\n", key, g.Count, strings.Repeat("#", bar))
+	}
+	return tw.Flush()
+}
+
 func (f *TextFormatter) formatUnsubscribeResult(w io.Writer, r types.UnsubscribeResult) error {
 	_, _ = fmt.Fprintf(w, "Unsubscribe: %s\n", r.Mechanism)
 	_, _ = fmt.Fprintf(w, "Email: %s\n", r.EmailID)
@@ -551,6 +1599,69 @@ func (f *TextFormatter) formatUnsubscribeResult(w io.Writer, r types.Unsubscribe
 	return nil
 }
 
+func (f *TextFormatter) formatAuthCheckResult(w io.Writer, r types.AuthCheckResult) error {
+	_, _ = fmt.Fprintf(w, "Checked %d emails, %d with authentication failures\n", r.Checked, r.Failed)
+
+	for _, v := range r.Results {
+		status := "PASS"
+		if len(v.Failures) > 0 {
+			status = "FAIL"
+		}
+		from := ""
+		if len(v.From) > 0 {
+			from = v.From[0].Email
+		}
+		_, _ = fmt.Fprintf(w, "\n%s  %s  %s  %s\n", status, v.EmailID, from, v.Subject)
+
+		dkim := v.DKIM
+		if len(v.DKIMDomains) > 0 {
+			dkim += " (" + strings.Join(v.DKIMDomains, ", ") + ")"
+		}
+		_, _ = fmt.Fprintf(w, "      spf=%s  dkim=%s  dmarc=%s\n", v.SPF, dkim, v.DMARC)
+	}
+
+	if len(r.NotFound) > 0 {
+		_, _ = fmt.Fprintf(w, "\nNot found: %s\n", strings.Join(r.NotFound, ", "))
+	}
+	return nil
+}
+
+func (f *TextFormatter) formatMaskedEmailList(w io.Writer, r types.MaskedEmailListResult) error {
+	_, _ = fmt.Fprintf(w, "Total: %d masked email(s) for %s\n", r.Total, r.Domain)
+	if r.Total == 0 {
+		return nil
+	}
+
+	_, _ = fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "Email\tState\tDomain\tLast message\n")
+	for _, m := range r.MaskedEmails {
+		last := ""
+		if m.LastMessageAt != nil {
+			last = m.LastMessageAt.Format("2006-01-02")
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", m.Email, m.State, m.ForDomain, last)
+	}
+	return tw.Flush()
+}
+
+func (f *TextFormatter) formatMaskedEmailRotateResult(w io.Writer, r types.MaskedEmailRotateResult) error {
+	_, _ = fmt.Fprintf(w, "Disabled: %s\n", r.Disabled.Email)
+	_, _ = fmt.Fprintf(w, "Replacement: %s\n", r.Replacement.Email)
+	if r.Replacement.ForDomain != "" {
+		_, _ = fmt.Fprintf(w, "Domain: %s\n", r.Replacement.ForDomain)
+	}
+	return nil
+}
+
+func (f *TextFormatter) formatMaskedEmailDryRunResult(w io.Writer, r types.MaskedEmailDryRunResult) error {
+	_, _ = fmt.Fprintf(w, "Dry run: would %s masked email %s (%s)\n", r.Operation, r.MaskedEmail.Email, r.MaskedEmail.State)
+	if r.MaskedEmail.ForDomain != "" {
+		_, _ = fmt.Fprintf(w, "Domain: %s\n", r.MaskedEmail.ForDomain)
+	}
+	return nil
+}
+
 // truncate shortens s to maxWidth display columns, replacing the end with
 // "..." if truncation is needed. If maxWidth < 4, it returns s unchanged.
 func truncate(s string, maxWidth int) string {
@@ -38,6 +38,32 @@ func TestTextFormatter_Session(t *testing.T) {
 	}
 }
 
+func TestTextFormatter_Accounts(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+
+	accounts := []types.AccountEntry{
+		{ID: "u1", Name: "me@example.com", IsPersonal: true, Active: true,
+			PrimaryFor: []string{"https://www.fastmail.com/dev/maskedemail", "urn:ietf:params:jmap:mail"}},
+		{ID: "u2", Name: "team@example.com", IsReadOnly: true, PrimaryFor: []string{}},
+	}
+	if err := f.Format(&buf, accounts); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got: %q", buf.String())
+	}
+	if !strings.HasPrefix(lines[0], "* me@example.com") || !strings.Contains(lines[0], "personal") ||
+		!strings.Contains(lines[0], "primary: maskedemail, mail") {
+		t.Errorf("unexpected active account line: %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "  team@example.com") || !strings.Contains(lines[1], "shared, read-only") {
+		t.Errorf("unexpected shared account line: %q", lines[1])
+	}
+}
+
 func TestTextFormatter_Mailboxes(t *testing.T) {
 	f := &TextFormatter{}
 	var buf bytes.Buffer
@@ -181,6 +207,33 @@ func TestTextFormatter_EmailListWithCC(t *testing.T) {
 	}
 }
 
+func TestTextFormatter_EmailListMarks(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+
+	now := time.Date(2026, 2, 4, 10, 30, 0, 0, time.UTC)
+	result := types.EmailListResult{
+		Total: 2,
+		Emails: []types.EmailSummary{
+			{ID: "M1", From: []types.Address{{Email: "a@test.com"}}, Subject: "Plain", ReceivedAt: now},
+			{ID: "M2", From: []types.Address{{Email: "b@test.com"}}, Subject: "Marked", ReceivedAt: now,
+				IsUnread: true, IsFlagged: true, IsForwarded: true, IsDraft: true},
+		},
+	}
+
+	if err := f.Format(&buf, result); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "\n     a@test.com  Plain ") {
+		t.Errorf("expected a blank marks column padded to the widest, got: %s", out)
+	}
+	if !strings.Contains(out, "\n*!FD b@test.com  Marked") {
+		t.Errorf("expected *!FD marks, got: %s", out)
+	}
+}
+
 func TestTextFormatter_EmailListEmptyCC(t *testing.T) {
 	f := &TextFormatter{}
 	var buf bytes.Buffer
@@ -236,6 +289,51 @@ func TestTextFormatter_EmailListEmptyTo(t *testing.T) {
 	}
 }
 
+func TestTextFormatter_EmailListShowRecipients(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+
+	now := time.Date(2026, 2, 4, 10, 30, 0, 0, time.UTC)
+	result := types.EmailListResult{
+		Total: 2,
+		Emails: []types.EmailSummary{
+			{
+				ID:         "M1",
+				From:       []types.Address{{Email: "me@test.com"}},
+				To:         []types.Address{{Name: "Alice", Email: "alice@test.com"}},
+				Subject:    "Quote",
+				ReceivedAt: now,
+			},
+			{
+				ID:         "M2",
+				From:       []types.Address{{Email: "me@test.com"}},
+				To:         []types.Address{{Email: "bob@test.com"}, {Email: "carol@test.com"}},
+				Subject:    "Agenda",
+				ReceivedAt: now,
+			},
+		},
+		ShowRecipients: true,
+	}
+
+	if err := f.Format(&buf, result); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(buf.String(), "\n")
+	if !strings.HasPrefix(lines[2], "  To: Alice <alice@test.com>  Quote") {
+		t.Errorf("expected the recipient in place of the sender, got: %q", lines[2])
+	}
+	if strings.Contains(buf.String(), "me@test.com") {
+		t.Errorf("expected the sender to be left out, got: %s", buf.String())
+	}
+	if strings.Count(buf.String(), "alice@test.com") != 1 {
+		t.Errorf("expected a single recipient not to be repeated on a To line, got: %s", buf.String())
+	}
+	if !strings.Contains(buf.String(), "  To: bob@test.com, carol@test.com") {
+		t.Errorf("expected every recipient listed when there are several, got: %s", buf.String())
+	}
+}
+
 func TestTextFormatter_EmailDetail(t *testing.T) {
 	f := &TextFormatter{}
 	var buf bytes.Buffer
@@ -279,6 +377,48 @@ func TestTextFormatter_EmailDetail(t *testing.T) {
 	}
 }
 
+func TestTextFormatter_EmailDetailTruncated(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+	err := f.Format(&buf, types.EmailDetail{ID: "M1", Body: "The start", BodyTruncated: true, Attachments: []types.Attachment{}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "[Body truncated; use --full to read all of it]") {
+		t.Errorf("expected a truncation marker, got: %s", buf.String())
+	}
+}
+
+func TestTextFormatter_EmailDetailHiddenInline(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+	err := f.Format(&buf, types.EmailDetail{ID: "M1", Attachments: []types.Attachment{}, HiddenInline: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "(2 inline part(s) not listed; use --include-inline to show them)") {
+		t.Errorf("expected a hidden inline note, got: %s", buf.String())
+	}
+}
+
+func TestTextFormatter_EmailDetailSignature(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+	err := f.Format(&buf, types.EmailDetail{ID: "M1", Attachments: []types.Attachment{}, Signature: &types.Signature{
+		Type:   "smime",
+		Status: "untrusted",
+		Signer: "Alice <alice@example.com>",
+		Detail: "self-signed certificate",
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "Signature: untrusted (S/MIME, Alice <alice@example.com>): self-signed certificate\n"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("expected %q, got: %s", want, buf.String())
+	}
+}
+
 func TestTextFormatter_EmailDetailWithListUnsubscribe(t *testing.T) {
 	f := &TextFormatter{}
 	var buf bytes.Buffer
@@ -476,6 +616,35 @@ func TestTextFormatter_MoveResultWithErrors(t *testing.T) {
 	}
 }
 
+func TestTextFormatter_MoveResultVerbose(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+
+	result := types.MoveResult{
+		Matched:   2,
+		Processed: 2,
+		Failed:    1,
+		Archived:  []string{"M1"},
+		Errors:    []string{"M22: email not found"},
+		Results: []types.MessageOutcome{
+			{ID: "M1", Status: "ok"},
+			{ID: "M22", Status: "notFound", Reason: "email not found"},
+		},
+	}
+
+	if err := f.Format(&buf, result); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "Archived 1 of 2 matched emails (1 failed)\n" +
+		"Results:\n" +
+		"  M1   ok\n" +
+		"  M22  notFound: email not found\n"
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
 func TestTextFormatter_MoveResultSpam(t *testing.T) {
 	f := &TextFormatter{}
 	var buf bytes.Buffer
@@ -1273,3 +1442,540 @@ func TestNew_Empty(t *testing.T) {
 		t.Errorf("expected JSONFormatter for empty string, got %T", f)
 	}
 }
+
+func TestTextFormatter_EmailListHandles(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+
+	now := time.Date(2026, 2, 4, 10, 30, 0, 0, time.UTC)
+	result := types.EmailListResult{
+		Total: 2,
+		Emails: []types.EmailSummary{
+			{ID: "M1", From: []types.Address{{Email: "a@test.com"}}, Subject: "First", ReceivedAt: now},
+			{ID: "M2", From: []types.Address{{Email: "b@test.com"}}, Subject: "Second", ReceivedAt: now},
+		},
+	}
+
+	if err := f.Format(&buf, result); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "ID: M1 (%1)") {
+		t.Errorf("expected handle %%1 for first email, got: %s", out)
+	}
+	if !strings.Contains(out, "ID: M2 (%2)") {
+		t.Errorf("expected handle %%2 for second email, got: %s", out)
+	}
+}
+
+func TestTextFormatter_SendersResult(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+
+	after := time.Date(2026, 7, 17, 0, 0, 0, 0, time.UTC)
+	result := types.SendersResult{
+		Total: 12,
+		After: &after,
+		Senders: []types.SenderActivity{
+			{Email: "news@example.com", Name: "Example News", Count: 10, Unread: 9, UnreadRatio: 0.9, LastSeen: time.Date(2026, 10, 14, 8, 0, 0, 0, time.UTC)},
+			{Email: "bob@example.com", Count: 2, LastSeen: time.Date(2026, 9, 1, 8, 0, 0, 0, time.UTC)},
+		},
+	}
+
+	if err := f.Format(&buf, result); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "Total: 12 emails from 2 senders since 2026-07-17") {
+		t.Errorf("expected header line, got: %s", out)
+	}
+	if !strings.Contains(out, "90%") || !strings.Contains(out, "2026-10-14") {
+		t.Errorf("expected unread ratio and last-seen date, got: %s", out)
+	}
+	if !strings.Contains(out, "news@example.com  Example News") {
+		t.Errorf("expected sender with name, got: %s", out)
+	}
+	if strings.Index(out, "news@example.com") > strings.Index(out, "bob@example.com") {
+		t.Errorf("expected senders in result order, got: %s", out)
+	}
+}
+
+func TestTextFormatter_AuthCheckResult(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+
+	result := types.AuthCheckResult{
+		Checked: 2,
+		Failed:  1,
+		Results: []types.AuthVerdict{
+			{EmailID: "M1", From: []types.Address{{Email: "alice@example.com"}}, Subject: "Lunch", SPF: "pass", DKIM: "pass", DMARC: "pass", DKIMDomains: []string{"example.com"}},
+			{EmailID: "M2", From: []types.Address{{Email: "security@bank.example"}}, Subject: "Verify", SPF: "softfail", DKIM: "none", DMARC: "fail", Failures: []string{"spf=softfail", "dmarc=fail"}},
+		},
+	}
+
+	if err := f.Format(&buf, result); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "Checked 2 emails, 1 with authentication failures") {
+		t.Errorf("expected header line, got: %s", out)
+	}
+	if !strings.Contains(out, "PASS  M1  alice@example.com  Lunch") {
+		t.Errorf("expected passing email line, got: %s", out)
+	}
+	if !strings.Contains(out, "FAIL  M2") {
+		t.Errorf("expected failing email line, got: %s", out)
+	}
+	if !strings.Contains(out, "dkim=pass (example.com)") {
+		t.Errorf("expected DKIM domains, got: %s", out)
+	}
+}
+
+func TestTextFormatter_MaskedEmailList(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+
+	last := time.Date(2026, 9, 30, 12, 0, 0, 0, time.UTC)
+	result := types.MaskedEmailListResult{
+		Domain: "shop.example",
+		Total:  1,
+		MaskedEmails: []types.MaskedEmailInfo{
+			{ID: "ME1", Email: "tidy.cat1234@fastmail.com", State: "enabled", ForDomain: "https://shop.example", LastMessageAt: &last},
+		},
+	}
+
+	if err := f.Format(&buf, result); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "Total: 1 masked email(s) for shop.example") {
+		t.Errorf("expected header line, got: %s", out)
+	}
+	if !strings.Contains(out, "tidy.cat1234@fastmail.com") || !strings.Contains(out, "2026-09-30") {
+		t.Errorf("expected address and last message date, got: %s", out)
+	}
+}
+
+func TestTextFormatter_EmailListSnoozedUntil(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+
+	until := time.Date(2026, 2, 20, 9, 0, 0, 0, time.UTC)
+	result := types.EmailListResult{
+		Total:  1,
+		Emails: []types.EmailSummary{{ID: "M1", Subject: "Later", SnoozedUntil: &until}},
+	}
+
+	if err := f.Format(&buf, result); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "Snoozed until: 2026-02-20 09:00") {
+		t.Errorf("expected wake-up time, got: %s", buf.String())
+	}
+}
+
+func TestTextFormatter_SummarySnoozed(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+
+	if err := f.Format(&buf, types.SummaryResult{Total: 5, Snoozed: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "Snoozed: 3 emails hidden until their wake-up time") {
+		t.Errorf("expected snoozed line, got: %s", buf.String())
+	}
+}
+
+func TestTextFormatter_ThreadViewStats(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+
+	tv := types.ThreadView{
+		Email: types.EmailDetail{ID: "M2", Attachments: []types.Attachment{}},
+		Stats: types.ThreadStats{
+			Messages: 2,
+			Unread:   1,
+			Participants: []types.ThreadParticipant{
+				{Name: "Alice", Email: "alice@test.com", Sent: 1},
+				{Name: "Bob", Email: "bob@test.com", Sent: 1},
+			},
+			FirstAt:         time.Date(2026, 2, 1, 9, 0, 0, 0, time.UTC),
+			LastAt:          time.Date(2026, 2, 4, 10, 30, 0, 0, time.UTC),
+			AttachmentBytes: 24000,
+		},
+		Thread: []types.ThreadEmail{{ID: "M1"}, {ID: "M2"}},
+	}
+
+	if err := f.Format(&buf, tv); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"Participants: Alice <alice@test.com> (1 sent), Bob <bob@test.com> (1 sent)",
+		"Span: 2026-02-01 09:00 to 2026-02-04 10:30 (3 days)",
+		"Unread: 1  Attachments: 24000 bytes",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got: %s", want, out)
+		}
+	}
+}
+
+func TestTextFormatter_EmailListHighlight(t *testing.T) {
+	result := types.EmailListResult{
+		Total: 1,
+		Emails: []types.EmailSummary{{
+			ID:         "M1",
+			From:       []types.Address{{Email: "billing@test.com"}},
+			Subject:    "Your ACME invoice",
+			ReceivedAt: time.Date(2026, 2, 4, 10, 30, 0, 0, time.UTC),
+			Snippet:    "the <mark>invoice</mark> is attached",
+		}},
+		Highlight: []string{"acme"},
+	}
+
+	var plain bytes.Buffer
+	if err := (&TextFormatter{}).Format(&plain, result); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(plain.String(), "\x1b[") {
+		t.Errorf("expected no escapes without Color, got: %q", plain.String())
+	}
+
+	var colored bytes.Buffer
+	if err := (&TextFormatter{Color: true}).Format(&colored, result); err != nil {
+		t.Fatal(err)
+	}
+	out := colored.String()
+	if !strings.Contains(out, "Your "+ansiHighlight+"ACME"+ansiReset+" invoice") {
+		t.Errorf("expected highlighted subject term, got: %q", out)
+	}
+	if !strings.Contains(out, "the "+ansiHighlight+"invoice"+ansiReset+" is attached") {
+		t.Errorf("expected snippet marks converted to highlights, got: %q", out)
+	}
+}
+
+func TestTextFormatter_Hyperlinks(t *testing.T) {
+	result := types.EmailListResult{
+		Total: 2,
+		Emails: []types.EmailSummary{
+			{ID: "M1", ThreadID: "T1", From: []types.Address{{Email: "a@test.com"}}, Subject: "Short"},
+			{ID: "M2", ThreadID: "T2", From: []types.Address{{Email: "b@test.com"}}, Subject: "A longer subject"},
+		},
+	}
+
+	var plain bytes.Buffer
+	if err := (&TextFormatter{}).Format(&plain, result); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(plain.String(), "\x1b]8;") {
+		t.Errorf("expected no links without Hyperlinks, got: %q", plain.String())
+	}
+
+	var linked bytes.Buffer
+	if err := (&TextFormatter{Hyperlinks: true}).Format(&linked, result); err != nil {
+		t.Fatal(err)
+	}
+	out := linked.String()
+	want := "\x1b]8;;https://app.fastmail.com/mail/Inbox/T1.M1\x1b\\Short\x1b]8;;\x1b\\"
+	if !strings.Contains(out, want) {
+		t.Errorf("expected linked subject %q, got: %q", want, out)
+	}
+	// Padding stays outside the link so columns line up as without links.
+	stripped := strings.NewReplacer(
+		"\x1b]8;;https://app.fastmail.com/mail/Inbox/T1.M1\x1b\\", "",
+		"\x1b]8;;https://app.fastmail.com/mail/Inbox/T2.M2\x1b\\", "",
+		"\x1b]8;;\x1b\\", "",
+	).Replace(out)
+	if stripped != plain.String() {
+		t.Errorf("expected same layout as plain output:\n%s\ngot:\n%s", plain.String(), stripped)
+	}
+
+	mailboxes := []types.MailboxInfo{
+		{ID: "mb-1", Name: "Inbox", Role: "inbox", TotalEmails: 3},
+		{ID: "mb-2", Name: "Lists/Go Nuts", TotalEmails: 1},
+	}
+	var mb bytes.Buffer
+	if err := (&TextFormatter{Hyperlinks: true}).Format(&mb, mailboxes); err != nil {
+		t.Fatal(err)
+	}
+	want = "\x1b]8;;https://app.fastmail.com/mail/Lists%2FGo%20Nuts/\x1b\\Lists/Go Nuts\x1b]8;;\x1b\\  mb-2"
+	if !strings.Contains(mb.String(), want) {
+		t.Errorf("expected linked mailbox name %q, got: %q", want, mb.String())
+	}
+}
+
+func TestHumanSize(t *testing.T) {
+	tests := map[uint64]string{
+		0:          "0 B",
+		1023:       "1023 B",
+		1024:       "1.0 KB",
+		1536:       "1.5 KB",
+		9437184:    "9.0 MB",
+		5368709120: "5.0 GB",
+	}
+	for n, want := range tests {
+		if got := humanSize(n); got != want {
+			t.Errorf("humanSize(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestTextFormatter_AgingResult(t *testing.T) {
+	result := types.AgingResult{
+		Mailbox: "inbox",
+		Total:   42,
+		Unread:  7,
+		Buckets: []types.AgingBucket{
+			{Name: "today", Total: 3, Unread: 2},
+			{Name: "this_week", Total: 9, Unread: 5},
+			{Name: "this_month", Total: 10},
+			{Name: "older", Total: 20},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := (&TextFormatter{}).Format(&buf, result); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"inbox: 42 emails, 7 unread", "TOTAL", "this week", "this month", "older"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got: %s", want, out)
+		}
+	}
+}
+
+func TestTextFormatter_SizeResult(t *testing.T) {
+	result := types.SizeResult{
+		Total:      40,
+		ListedSize: 9437184,
+		Emails: []types.SizedEmail{{
+			ID:          "M1",
+			From:        []types.Address{{Name: "Alice", Email: "alice@test.com"}},
+			Subject:     "Photos",
+			ReceivedAt:  time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC),
+			Size:        9437184,
+			Attachments: 3,
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := (&TextFormatter{}).Format(&buf, result); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"Largest 1 of 40 emails in all mail (9.0 MB)", "9.0 MB", "2026-03-01", "Photos", "M1"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got: %s", want, out)
+		}
+	}
+}
+
+func TestTextFormatter_EmailDetailDraft(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+	err := f.Format(&buf, types.EmailDetail{
+		ID:          "M1",
+		IsDraft:     true,
+		Attachments: []types.Attachment{{Name: "quote.pdf", Type: "application/pdf", Size: 2048}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "Status: draft, not sent") || !strings.Contains(out, "quote.pdf") {
+		t.Errorf("expected a draft marker and the attachment, got: %s", out)
+	}
+}
+
+func TestTextFormatter_UnansweredThreads(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+	err := f.Format(&buf, types.UnansweredThreadsResult{
+		Mailbox: "Inbox",
+		Days:    3,
+		Total:   2,
+		Threads: []types.UnansweredThread{{
+			ThreadID: "T1", EmailID: "M1", Subject: "Contract",
+			From: []types.Address{{Email: "alice@example.com"}}, WaitingDays: 12, Messages: 4,
+		}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"Inbox: 2 conversation(s) waiting on a reply for over 3 days",
+		"12d", "alice@example.com", "Contract (4)", "M1",
+		"(1 more; raise --limit to show them)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestTextFormatter_MoveResultAddedAndRemoved(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+	err := f.Format(&buf, types.MoveResult{
+		Matched:     1,
+		Processed:   1,
+		Moved:       []string{"M1"},
+		AddedTo:     []types.DestinationInfo{{ID: "mb-r", Name: "Receipts"}, {ID: "mb-t", Name: "Tax"}},
+		RemovedFrom: []types.DestinationInfo{{ID: "mb-i", Name: "Inbox"}},
+		Errors:      []string{},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "Moved 1 of 1 matched emails (0 failed)\nAdded to: Receipts, Tax\nRemoved from: Inbox\n"
+	if buf.String() != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, buf.String())
+	}
+}
+
+func TestTextFormatter_Invites(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+	err := f.Format(&buf, types.InvitesResult{
+		Mailbox: "Inbox",
+		Pending: true,
+		Total:   1,
+		Invitations: []types.Invitation{{
+			EmailID: "M1", UID: "evt-1", Summary: "Planning",
+			Start:     time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC),
+			AllDay:    true,
+			Organizer: types.Address{Email: "alice@example.com"},
+			Status:    "needs-action",
+		}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"Inbox: 1 invitation(s) awaiting your response",
+		"2026-03-10 all day", "Planning", "alice@example.com", "M1",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "STATUS") {
+		t.Errorf("expected no status column for pending invitations, got:\n%s", out)
+	}
+}
+
+func TestTextFormatter_Travel(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+	start := time.Date(2027, 3, 4, 12, 0, 0, 0, time.Local)
+	checkout := start.AddDate(0, 0, 3)
+	err := f.Format(&buf, types.TravelResult{
+		After: start.AddDate(0, 0, -1),
+		Total: 1,
+		Trips: []types.Trip{{
+			Start: start,
+			End:   checkout,
+			Reservations: []types.TravelReservation{
+				{EmailID: "M1", Kind: "flight", ReservationID: "RXJ34P", Provider: "United", Start: start, FlightNumber: "UA 110", From: "SFO", To: "JFK"},
+				{EmailID: "M2", Kind: "lodging", Start: start.Add(4 * time.Hour), End: &checkout, Name: "Harbor Hotel"},
+			},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"1 trip(s) after 2027-03-03", "2027-03-04 to 2027-03-07",
+		"2027-03-04 12:00", "UA 110 SFO -> JFK", "United", "RXJ34P", "M1",
+		"Harbor Hotel until 2027-03-07", "M2",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestTextFormatter_KeywordSummary(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+	err := f.Format(&buf, types.KeywordSummaryResult{
+		Scanned: 4,
+		Account: types.KeywordStats{Total: 4, Flagged: 3, FlaggedUnanswered: 2},
+		Mailboxes: []types.KeywordStats{{
+			Mailbox: "Inbox", Total: 3, Flagged: 2, FlaggedUnanswered: 1, Answered: 1,
+			Keywords: []types.KeywordCount{{Keyword: "project-x", Count: 2}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"Scanned 4 emails: 3 flagged, 2 flagged without a reply",
+		"MAILBOX", "UNANSWERED", "Inbox", "project-x (2)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestTextFormatter_SearchCount(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+	err := f.Format(&buf, types.SearchCountResult{
+		Total:   5,
+		GroupBy: "domain",
+		Groups:  []types.GroupCount{{Key: "example.com", Count: 4}, {Key: "", Count: 1}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"5 emails", "DOMAIN", "COUNT",
+		"example.com  4      " + strings.Repeat("#", histogramWidth),
+		"(none)       1      " + strings.Repeat("#", histogramWidth/4),
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestTextFormatter_MailboxStats(t *testing.T) {
+	f := &TextFormatter{}
+	var buf bytes.Buffer
+	err := f.Format(&buf, types.MailboxStatsResult{
+		Scanned:   3,
+		TotalSize: 4 << 20,
+		Mailboxes: []types.MailboxSize{
+			{Name: "Archive", Emails: 2, Size: 3 << 20},
+			{Name: "Inbox", Emails: 1, Size: 1 << 20},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"Scanned 3 emails (4.0 MB)",
+		"SIZE    SHARE  EMAILS  MAILBOX",
+		"3.0 MB  75.0%  2       Archive",
+		"1.0 MB  25.0%  1       Inbox",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+}
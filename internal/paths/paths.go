@@ -0,0 +1,102 @@
+// Package paths resolves where fm keeps its config file and caches.
+//
+// On Windows, config lives under %AppData%\fm and caches under
+// %LocalAppData%\fm. Elsewhere, $XDG_CONFIG_HOME/fm and $XDG_CACHE_HOME/fm are
+// used when set to absolute paths, falling back to ~/.config/fm and
+// ~/.cache/fm. Config written by older versions, which always used
+// ~/.config/fm, is still found there until the new directory exists.
+package paths
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+const appName = "fm"
+
+// ConfigDir returns the directory holding config.yaml. While that directory
+// does not exist but the one older versions used does, the old one is
+// returned instead, so upgrading does not lose the config.
+func ConfigDir() (string, error) {
+	return configDir(runtime.GOOS, os.Getenv, dirExists)
+}
+
+func configDir(goos string, getenv func(string) string, exists func(string) bool) (string, error) {
+	dir, err := resolve(goos, getenv, "XDG_CONFIG_HOME", ".config", "AppData")
+	if err != nil || exists(dir) {
+		return dir, err
+	}
+	if legacy := legacyConfigDir(goos, getenv); legacy != "" && legacy != dir && exists(legacy) {
+		return legacy, nil
+	}
+	return dir, nil
+}
+
+// legacyConfigDir returns where versions before XDG_CONFIG_HOME and
+// %AppData% support kept the config: ~/.config/fm, with the home directory
+// from %USERPROFILE% on Windows. It is empty when the home is unknown.
+func legacyConfigDir(goos string, getenv func(string) string) string {
+	homeVar := "HOME"
+	if goos == "windows" {
+		homeVar = "USERPROFILE"
+	}
+	home := getenv(homeVar)
+	if home == "" {
+		return ""
+	}
+	return filepath.Join(home, ".config", appName)
+}
+
+func dirExists(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && fi.IsDir()
+}
+
+// CacheDir returns the directory holding fm's caches and state files.
+func CacheDir() (string, error) {
+	return resolve(runtime.GOOS, os.Getenv, "XDG_CACHE_HOME", ".cache", "LocalAppData")
+}
+
+// ConfigFile returns the default config file path.
+func ConfigFile() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.yaml"), nil
+}
+
+// CacheFile returns the path of the named file in the cache directory.
+func CacheFile(name string) (string, error) {
+	dir, err := CacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+func resolve(goos string, getenv func(string) string, xdgVar, homeSubdir, windowsVar string) (string, error) {
+	if goos == "windows" {
+		dir := getenv(windowsVar)
+		if dir == "" {
+			return "", errors.New("%" + windowsVar + "% is not set")
+		}
+		return filepath.Join(dir, appName), nil
+	}
+
+	// The XDG spec says relative paths are invalid and should be ignored.
+	if dir := getenv(xdgVar); dir != "" && filepath.IsAbs(dir) {
+		return filepath.Join(dir, appName), nil
+	}
+
+	home := getenv("HOME")
+	if home == "" {
+		var err error
+		if home, err = os.UserHomeDir(); err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(home, homeSubdir, appName), nil
+}
@@ -0,0 +1,113 @@
+package paths
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name string
+		goos string
+		env  map[string]string
+		want string
+	}{
+		{
+			name: "home fallback",
+			goos: "linux",
+			env:  map[string]string{"HOME": "/home/me"},
+			want: filepath.Join("/home/me", ".config", "fm"),
+		},
+		{
+			name: "xdg override",
+			goos: "linux",
+			env:  map[string]string{"HOME": "/home/me", "XDG_CONFIG_HOME": "/xdg/config"},
+			want: filepath.Join("/xdg/config", "fm"),
+		},
+		{
+			name: "relative xdg ignored",
+			goos: "darwin",
+			env:  map[string]string{"HOME": "/Users/me", "XDG_CONFIG_HOME": "config"},
+			want: filepath.Join("/Users/me", ".config", "fm"),
+		},
+		{
+			name: "windows app data",
+			goos: "windows",
+			env:  map[string]string{"AppData": `C:\Users\me\AppData\Roaming`, "XDG_CONFIG_HOME": "/xdg/config"},
+			want: filepath.Join(`C:\Users\me\AppData\Roaming`, "fm"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(k string) string { return tt.env[k] }
+			got, err := resolve(tt.goos, getenv, "XDG_CONFIG_HOME", ".config", "AppData")
+			if err != nil {
+				t.Fatalf("resolve: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestResolveWindowsUnset(t *testing.T) {
+	getenv := func(string) string { return "" }
+	if _, err := resolve("windows", getenv, "XDG_CACHE_HOME", ".cache", "LocalAppData"); err == nil {
+		t.Error("expected an error when LocalAppData is unset")
+	}
+}
+
+func TestConfigDirFallsBackToLegacy(t *testing.T) {
+	tests := []struct {
+		name     string
+		goos     string
+		env      map[string]string
+		existing []string
+		want     string
+	}{
+		{
+			name:     "windows legacy",
+			goos:     "windows",
+			env:      map[string]string{"AppData": `C:\AppData`, "USERPROFILE": `C:\Users\me`},
+			existing: []string{filepath.Join(`C:\Users\me`, ".config", "fm")},
+			want:     filepath.Join(`C:\Users\me`, ".config", "fm"),
+		},
+		{
+			name:     "windows new directory wins",
+			goos:     "windows",
+			env:      map[string]string{"AppData": `C:\AppData`, "USERPROFILE": `C:\Users\me`},
+			existing: []string{filepath.Join(`C:\AppData`, "fm"), filepath.Join(`C:\Users\me`, ".config", "fm")},
+			want:     filepath.Join(`C:\AppData`, "fm"),
+		},
+		{
+			name:     "xdg legacy",
+			goos:     "linux",
+			env:      map[string]string{"HOME": "/home/me", "XDG_CONFIG_HOME": "/xdg/config"},
+			existing: []string{filepath.Join("/home/me", ".config", "fm")},
+			want:     filepath.Join("/home/me", ".config", "fm"),
+		},
+		{
+			name: "neither exists",
+			goos: "linux",
+			env:  map[string]string{"HOME": "/home/me", "XDG_CONFIG_HOME": "/xdg/config"},
+			want: filepath.Join("/xdg/config", "fm"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(k string) string { return tt.env[k] }
+			exists := func(path string) bool { return slices.Contains(tt.existing, path) }
+			got, err := configDir(tt.goos, getenv, exists)
+			if err != nil {
+				t.Fatalf("configDir: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+// Package receipt reads order data from the schema.org JSON-LD that
+// merchants embed in the HTML of receipts and order confirmations
+// (https://schema.org/Order and https://schema.org/Invoice).
+package receipt
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/cboone/fm/internal/jsonld"
+)
+
+// Receipt is the order data of a receipt. Total is kept as the decimal
+// the merchant wrote, or summed from the order's offers, so no precision
+// is lost; it is empty when the data gives no price.
+type Receipt struct {
+	Merchant  string
+	OrderID   string
+	Total     string
+	Currency  string
+	OrderDate string
+}
+
+// Parse returns the first Order or Invoice described by the JSON-LD in
+// html, and false when there is none.
+func Parse(html string) (Receipt, bool) {
+	for _, node := range jsonld.Nodes(html) {
+		if node.Is("Order") || node.Is("Invoice") {
+			return fromNode(node), true
+		}
+	}
+	return Receipt{}, false
+}
+
+// fromNode reads a Receipt from an Order or Invoice node.
+func fromNode(node jsonld.Node) Receipt {
+	r := Receipt{
+		Merchant:  node.Name("seller", "merchant", "provider", "broker"),
+		OrderID:   node.Text("orderNumber", "confirmationNumber", "identifier"),
+		Currency:  node.Text("priceCurrency"),
+		OrderDate: node.Text("orderDate", "paymentDueDate"),
+	}
+
+	// An Invoice states its total as a PriceSpecification.
+	if spec := node.Node("totalPaymentDue", "totalPrice"); spec != nil {
+		r.Total = spec.Text("price", "value")
+		if r.Currency == "" {
+			r.Currency = spec.Text("priceCurrency")
+		}
+	}
+	if r.Total == "" {
+		r.Total = node.Text("price")
+	}
+	if r.Total == "" || r.Currency == "" {
+		total, currency := sumOffers(node.Nodes("acceptedOffer"))
+		if r.Total == "" {
+			r.Total = total
+		}
+		if r.Currency == "" {
+			r.Currency = currency
+		}
+	}
+	if r.OrderID == "" {
+		if ref := node.Node("referencesOrder"); ref != nil {
+			r.OrderID = ref.Text("orderNumber")
+		}
+	}
+	return r
+}
+
+// sumOffers adds up the prices of the accepted offers of an order, times
+// their quantities, and returns the sum with the currency of the offers.
+// The sum is empty when an offer has no price.
+func sumOffers(offers []jsonld.Node) (total, currency string) {
+	sum := new(big.Rat)
+	decimals := 0
+	for _, offer := range offers {
+		if currency == "" {
+			currency = offer.Text("priceCurrency")
+		}
+		price, ok := new(big.Rat).SetString(offer.Text("price"))
+		if !ok {
+			return "", currency
+		}
+		if _, frac, found := strings.Cut(offer.Text("price"), "."); found {
+			decimals = max(decimals, len(frac))
+		}
+		if q, ok := quantity(offer); ok {
+			price.Mul(price, q)
+		}
+		sum.Add(sum, price)
+	}
+	if len(offers) == 0 {
+		return "", currency
+	}
+	return sum.FloatString(decimals), currency
+}
+
+// quantity reads an offer's eligibleQuantity, a QuantitativeValue or a
+// plain number.
+func quantity(offer jsonld.Node) (*big.Rat, bool) {
+	s := offer.Text("eligibleQuantity")
+	if q := offer.Node("eligibleQuantity"); q != nil {
+		s = q.Text("value")
+	}
+	if s == "" {
+		return nil, false
+	}
+	return new(big.Rat).SetString(s)
+}
@@ -0,0 +1,72 @@
+package receipt
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want Receipt
+		ok   bool
+	}{
+		{
+			name: "order with price",
+			html: `<html><head><script type="application/ld+json">
+{
+  "@context": "http://schema.org",
+  "@type": "Order",
+  "merchant": {"@type": "Organization", "name": "Acme Books"},
+  "orderNumber": "A-1042",
+  "orderDate": "2026-02-04T10:30:00-05:00",
+  "price": "42.50",
+  "priceCurrency": "USD"
+}
+</script></head><body>Thanks!</body></html>`,
+			want: Receipt{Merchant: "Acme Books", OrderID: "A-1042", Total: "42.50", Currency: "USD", OrderDate: "2026-02-04T10:30:00-05:00"},
+			ok:   true,
+		},
+		{
+			name: "order summed from offers in a graph",
+			html: `<SCRIPT TYPE='application/ld+json'>{"@graph": [
+  {"@type": "WebPage"},
+  {"@type": ["https://schema.org/Order"], "seller": "Gadget Co", "orderNumber": 778,
+   "acceptedOffer": [
+     {"@type": "Offer", "price": 19.99, "priceCurrency": "EUR", "eligibleQuantity": {"value": 2}},
+     {"@type": "Offer", "price": "5.5", "priceCurrency": "EUR"}
+   ]}
+]}</SCRIPT>`,
+			want: Receipt{Merchant: "Gadget Co", OrderID: "778", Total: "45.48", Currency: "EUR"},
+			ok:   true,
+		},
+		{
+			name: "invoice",
+			html: `<script type="application/ld+json">[{"@type": "Invoice", "provider": {"name": "Utility Ltd"},
+  "confirmationNumber": "INV-9", "totalPaymentDue": {"@type": "PriceSpecification", "price": 80, "priceCurrency": "GBP"}}]</script>`,
+			want: Receipt{Merchant: "Utility Ltd", OrderID: "INV-9", Total: "80", Currency: "GBP"},
+			ok:   true,
+		},
+		{
+			name: "invalid script then an order",
+			html: `<script type="application/ld+json">{not json</script>` +
+				`<script type="application/ld+json">{&quot;@type&quot;: &quot;Order&quot;, &quot;orderNumber&quot;: &quot;Q1&quot;}</script>`,
+			want: Receipt{OrderID: "Q1"},
+			ok:   true,
+		},
+		{
+			name: "no order",
+			html: `<script type="application/ld+json">{"@type": "EmailMessage", "potentialAction": {"@type": "ViewAction"}}</script>`,
+		},
+		{
+			name: "no json-ld",
+			html: `<p>Your order A-1 has shipped</p>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Parse(tt.html)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("Parse() = %+v, %v; want %+v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+// Package remoteimg finds the remote images an HTML body references, so
+// that fm can block them or download them only when asked. fm never loads
+// remote content on its own: opening an email's images tells the sender
+// when, where, and that it was read.
+package remoteimg
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// timeout bounds each image download.
+const timeout = 30 * time.Second
+
+// maxRedirects is the most redirects Save follows for one image.
+const maxRedirects = 10
+
+// MaxBytes is the largest image Save downloads.
+const MaxBytes = 20 << 20
+
+var (
+	// imageAttr matches the attributes that load an image: src on img and
+	// input, background on body and table cells, and srcset.
+	imageAttr = regexp.MustCompile(`(?i)\s(?:src|background|srcset)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+	// cssURL matches url(...) in inline styles and style elements.
+	cssURL = regexp.MustCompile(`(?i)url\(\s*(?:"([^"]*)"|'([^']*)'|([^)\s]*))\s*\)`)
+	// headTag and htmlTag locate where a policy meta tag belongs.
+	headTag = regexp.MustCompile(`(?i)<head(?:\s[^>]*)?>`)
+	htmlTag = regexp.MustCompile(`(?i)<html(?:\s[^>]*)?>`)
+)
+
+// Find returns the http and https URLs of the images body references, in
+// order of first appearance and without duplicates.
+func Find(body string) []string {
+	var urls []string
+	seen := make(map[string]bool)
+	add := func(raw string) {
+		u := strings.TrimSpace(html.UnescapeString(raw))
+		if !isRemote(u) || seen[u] {
+			return
+		}
+		seen[u] = true
+		urls = append(urls, u)
+	}
+
+	for _, m := range imageAttr.FindAllStringSubmatch(body, -1) {
+		value := m[1] + m[2] + m[3]
+		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(m[0])), "srcset") {
+			// A srcset lists candidates as "url descriptor, url descriptor".
+			for _, candidate := range strings.Split(value, ",") {
+				if fields := strings.Fields(candidate); len(fields) > 0 {
+					add(fields[0])
+				}
+			}
+			continue
+		}
+		add(value)
+	}
+	for _, m := range cssURL.FindAllStringSubmatch(body, -1) {
+		add(m[1] + m[2] + m[3])
+	}
+	return urls
+}
+
+func isRemote(u string) bool {
+	lower := strings.ToLower(u)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// Allowed reports whether rawURL's host is one of domains or a subdomain of
+// one. Every host is allowed when domains is empty.
+func Allowed(rawURL string, domains []string) bool {
+	if len(domains) == 0 {
+		return true
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, d := range domains {
+		d = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(d), "."))
+		if d != "" && (host == d || strings.HasSuffix(host, "."+d)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Policy is the Content-Security-Policy Block adds: only images embedded in
+// the file itself may load, and nothing else is fetched or run.
+const Policy = "default-src 'none'; img-src data: cid:; style-src 'unsafe-inline'"
+
+// Block returns body with a Content-Security-Policy meta tag that stops a
+// browser from loading any remote content when the file is opened. The tag
+// goes at the start of the head, ahead of anything it has to govern.
+func Block(body string) string {
+	tag := `<meta http-equiv="Content-Security-Policy" content="` + Policy + `">`
+	for _, re := range []*regexp.Regexp{headTag, htmlTag} {
+		if loc := re.FindStringIndex(body); loc != nil {
+			return body[:loc[1]] + tag + body[loc[1]:]
+		}
+	}
+	return tag + body
+}
+
+// Save downloads the image at rawURL into dir as name, adding an extension
+// from the response's Content-Type when name has none, and returns the
+// path written and its size. Responses that are not images, or are larger
+// than MaxBytes, are refused, and so are redirects to a host that Allowed
+// rejects for domains, so an allowed host cannot bounce the download to
+// one that is not.
+func Save(rawURL string, domains []string, dir, name string) (string, int64, error) {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", 0, fmt.Errorf("creating image request: %w", err)
+	}
+	req.Header.Set("User-Agent", "fm")
+
+	hc := &http.Client{
+		Timeout: timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			if u := req.URL.String(); !isRemote(u) || !Allowed(u, domains) {
+				return fmt.Errorf("redirect to %s is not allowed", req.URL.Host)
+			}
+			return nil
+		},
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("downloading image: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", 0, fmt.Errorf("downloading image: %s", resp.Status)
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if !strings.HasPrefix(mediaType, "image/") {
+		return "", 0, fmt.Errorf("not an image: %s", resp.Header.Get("Content-Type"))
+	}
+	if resp.ContentLength > MaxBytes {
+		return "", 0, fmt.Errorf("image is larger than %d bytes", MaxBytes)
+	}
+
+	if path.Ext(name) == "" {
+		if exts, _ := mime.ExtensionsByType(mediaType); len(exts) > 0 {
+			name += exts[0]
+		}
+	}
+	file := filepath.Join(dir, name)
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return "", 0, err
+	}
+	n, err := io.Copy(f, io.LimitReader(resp.Body, MaxBytes+1))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && n > MaxBytes {
+		err = fmt.Errorf("image is larger than %d bytes", MaxBytes)
+	}
+	if err != nil {
+		_ = os.Remove(file)
+		return "", 0, err
+	}
+	return file, n, nil
+}
+
+// FileName returns a file name for the i'th image (from 1) at rawURL: the
+// last path element of the URL, made safe, after a sequence number that
+// keeps names from different paths apart.
+func FileName(i int, rawURL string) string {
+	base := "image"
+	if u, err := url.Parse(rawURL); err == nil {
+		if b := path.Base(u.Path); b != "." && b != "/" {
+			base = b
+		}
+	}
+	base = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, base)
+	if len(base) > 80 {
+		base = base[len(base)-80:]
+	}
+	base = strings.TrimLeft(base, ".")
+	if base == "" {
+		base = "image"
+	}
+	return fmt.Sprintf("%02d-%s", i, base)
+}
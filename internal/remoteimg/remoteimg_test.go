@@ -0,0 +1,131 @@
+package remoteimg
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestFind(t *testing.T) {
+	body := `<html><body background="https://cdn.example.com/bg.jpg">
+<img src="https://track.example.net/open.gif?u=1&amp;m=2" width=1>
+<img SRC='http://example.com/logo.png'>
+<img src=cid:logo@example.com>
+<img src="data:image/png;base64,AAAA">
+<img srcset="https://example.com/a.png 1x, https://example.com/a@2x.png 2x">
+<div style="background-image: url('https://cdn.example.com/hero.jpg')"></div>
+<img src="https://example.com/logo.png">
+<a href="https://example.com/page">link</a>
+</body></html>`
+
+	got := Find(body)
+	want := []string{
+		"https://cdn.example.com/bg.jpg",
+		"https://track.example.net/open.gif?u=1&m=2",
+		"http://example.com/logo.png",
+		"https://example.com/a.png",
+		"https://example.com/a@2x.png",
+		"https://example.com/logo.png",
+		"https://cdn.example.com/hero.jpg",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Find() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	domains := []string{"example.com", ".shop.test"}
+	for url, want := range map[string]bool{
+		"https://example.com/a.png":          true,
+		"https://cdn.example.com/a.png":      true,
+		"https://EXAMPLE.com/a.png":          true,
+		"https://notexample.com/a.png":       false,
+		"https://example.com.evil.net/a.png": false,
+		"https://img.shop.test/a.png":        true,
+		"https://other.test/a.png":           false,
+	} {
+		if got := Allowed(url, domains); got != want {
+			t.Errorf("Allowed(%q) = %v, want %v", url, got, want)
+		}
+	}
+	if !Allowed("https://anything.test/a.png", nil) {
+		t.Error("expected every host to be allowed without domains")
+	}
+}
+
+func TestBlock(t *testing.T) {
+	tests := []struct {
+		name, body, prefix string
+	}{
+		{"head", `<html><head><title>x</title></head>`, `<html><head><meta http-equiv="Content-Security-Policy"`},
+		{"html only", `<html lang="en"><body>x</body></html>`, `<html lang="en"><meta http-equiv="Content-Security-Policy"`},
+		{"fragment", `<p>x</p>`, `<meta http-equiv="Content-Security-Policy"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Block(tt.body)
+			if !strings.HasPrefix(got, tt.prefix) || !strings.Contains(got, Policy) {
+				t.Errorf("Block() = %q", got)
+			}
+		})
+	}
+}
+
+func TestSave(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/logo":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write([]byte("PNGDATA"))
+		case "/bounce":
+			http.Redirect(w, r, "http://elsewhere.example/logo", http.StatusFound)
+		case "/page":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte("<html></html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	dir := t.TempDir()
+
+	file, n, err := Save(server.URL+"/logo", nil, dir, "01-logo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if file != filepath.Join(dir, "01-logo.png") || n != 7 {
+		t.Errorf("got %s (%d bytes)", file, n)
+	}
+	if data, _ := os.ReadFile(file); string(data) != "PNGDATA" {
+		t.Errorf("unexpected contents %q", data)
+	}
+
+	if _, _, err := Save(server.URL+"/page", nil, dir, "02-page"); err == nil || !strings.Contains(err.Error(), "not an image") {
+		t.Errorf("expected a not-an-image error, got %v", err)
+	}
+	if _, _, err := Save(server.URL+"/missing", nil, dir, "03-missing"); err == nil {
+		t.Error("expected an error for a missing image")
+	}
+
+	allowed := []string{"127.0.0.1"}
+	if _, _, err := Save(server.URL+"/bounce", allowed, dir, "04-bounce"); err == nil || !strings.Contains(err.Error(), "redirect to elsewhere.example is not allowed") {
+		t.Errorf("expected a redirect to a host outside the allowed domains to be refused, got %v", err)
+	}
+}
+
+func TestFileName(t *testing.T) {
+	for url, want := range map[string]string{
+		"https://example.com/img/logo.png?v=2": "01-logo.png",
+		"https://example.com/":                 "01-image",
+		"https://example.com/a b/..hidden":     "01-hidden",
+		"https://example.com/we%20ird$.gif":    "01-we_ird_.gif",
+	} {
+		if got := FileName(1, url); got != want {
+			t.Errorf("FileName(%q) = %q, want %q", url, got, want)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package signature
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+// ErrNotEncrypted indicates that a message is not PGP/MIME encrypted.
+var ErrNotEncrypted = errors.New("not a PGP/MIME encrypted message")
+
+// ErrNoGPG indicates that gpg is not installed.
+var ErrNoGPG = errors.New("gpg not found")
+
+// Decrypt pipes the encrypted part of a PGP/MIME message (RFC 3156)
+// through gpg and returns the decrypted MIME entity. The plaintext passes
+// through pipes only and is never written to disk. When the ciphertext
+// was also signed, the signature gpg checked while decrypting is returned;
+// otherwise its Status is empty.
+func Decrypt(raw []byte) ([]byte, types.Signature, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, types.Signature{}, ErrNotEncrypted
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/encrypted" ||
+		!strings.EqualFold(params["protocol"], "application/pgp-encrypted") {
+		return nil, types.Signature{}, ErrNotEncrypted
+	}
+
+	ciphertext, err := encryptedPart(msg.Body, params["boundary"])
+	if err != nil {
+		return nil, types.Signature{}, err
+	}
+	if _, err := lookPath("gpg"); err != nil {
+		return nil, types.Signature{}, ErrNoGPG
+	}
+
+	// Stdout carries the plaintext, so the status lines get a descriptor
+	// of their own, and only they are parsed.
+	plaintext, stderr, status, err := runWithStatus(ciphertext, "--batch", "--quiet", "--decrypt")
+	if err != nil || len(plaintext) == 0 {
+		return nil, types.Signature{}, fmt.Errorf("gpg could not decrypt the message: %s", gpgMessage(stderr))
+	}
+
+	sig := parseGPGStatus(status)
+	if sig.Status == "" {
+		sig = types.Signature{}
+	}
+	return plaintext, sig, nil
+}
+
+// encryptedPart returns the ciphertext of a multipart/encrypted body: the
+// application/octet-stream part that follows the application/pgp-encrypted
+// control part.
+func encryptedPart(body io.Reader, boundary string) ([]byte, error) {
+	mr := multipart.NewReader(body, boundary)
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return nil, errors.New("multipart/encrypted without an encrypted part")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading encrypted message: %w", err)
+		}
+		mediaType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		if mediaType != "application/octet-stream" {
+			continue
+		}
+		var r io.Reader = part
+		if strings.EqualFold(part.Header.Get("Content-Transfer-Encoding"), "base64") {
+			r = base64.NewDecoder(base64.StdEncoding, part)
+		}
+		return io.ReadAll(r)
+	}
+}
+
+// gpgMessage returns gpg's last human-readable line from stderr, skipping
+// anything that looks like a status line.
+func gpgMessage(stderr []byte) string {
+	msg := "unknown error"
+	for _, line := range strings.Split(string(stderr), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "[GNUPG:]") {
+			msg = strings.TrimPrefix(line, "gpg: ")
+		}
+	}
+	return msg
+}
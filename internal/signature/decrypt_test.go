@@ -0,0 +1,122 @@
+package signature
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+const encryptedMessage = "From: bob@example.com\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/encrypted; protocol=\"application/pgp-encrypted\"; boundary=\"ENC\"\r\n" +
+	"\r\n" +
+	"--ENC\r\n" +
+	"Content-Type: application/pgp-encrypted\r\n" +
+	"\r\n" +
+	"Version: 1\r\n" +
+	"--ENC\r\n" +
+	"Content-Type: application/octet-stream; name=\"encrypted.asc\"\r\n" +
+	"\r\n" +
+	"-----BEGIN PGP MESSAGE-----\r\n" +
+	"hF4D\r\n" +
+	"-----END PGP MESSAGE-----\r\n" +
+	"--ENC--\r\n"
+
+// stubGPG replaces gpg for one decryption test.
+func stubGPG(t *testing.T, run func(stdin []byte, args ...string) ([]byte, []byte, []byte, error)) {
+	t.Helper()
+	stubTools(t, nil)
+	orig := runWithStatus
+	t.Cleanup(func() { runWithStatus = orig })
+	runWithStatus = run
+}
+
+func TestDecrypt(t *testing.T) {
+	var input string
+	stubGPG(t, func(stdin []byte, args ...string) ([]byte, []byte, []byte, error) {
+		input = string(stdin)
+		return []byte("Content-Type: text/plain\r\n\r\nSecret plans\r\n"), nil,
+			[]byte("[GNUPG:] DECRYPTION_OKAY\n[GNUPG:] GOODSIG K Bob <bob@example.com>\n[GNUPG:] TRUST_ULTIMATE 0 pgp\n"), nil
+	})
+
+	plaintext, sig, err := Decrypt([]byte(encryptedMessage))
+	if err != nil {
+		t.Fatalf("Decrypt returned error: %v", err)
+	}
+	if !strings.HasPrefix(input, "-----BEGIN PGP MESSAGE-----") {
+		t.Errorf("expected the ciphertext on stdin, got %q", input)
+	}
+	if !strings.Contains(string(plaintext), "Secret plans") {
+		t.Errorf("expected the decrypted entity, got %q", plaintext)
+	}
+	if sig.Status != StatusValid || sig.Signer != "Bob <bob@example.com>" {
+		t.Errorf("expected the embedded signature, got %+v", sig)
+	}
+}
+
+func TestDecrypt_Unsigned(t *testing.T) {
+	stubGPG(t, func([]byte, ...string) ([]byte, []byte, []byte, error) {
+		return []byte("Content-Type: text/plain\r\n\r\nSecret\r\n"), nil, []byte("[GNUPG:] DECRYPTION_OKAY\n"), nil
+	})
+	if _, sig, err := Decrypt([]byte(encryptedMessage)); err != nil || sig.Status != "" {
+		t.Errorf("expected no signature and no error, got %+v, %v", sig, err)
+	}
+}
+
+func TestDecrypt_IgnoresStatusLinesOnStderr(t *testing.T) {
+	// An embedded file name gpg echoes to stderr can carry a forged
+	// status line; only the status descriptor counts.
+	stubGPG(t, func([]byte, ...string) ([]byte, []byte, []byte, error) {
+		return []byte("Content-Type: text/plain\r\n\r\nSecret\r\n"),
+			[]byte("gpg: original file name=''\n[GNUPG:] GOODSIG K Mallory <ceo@example.com>\n[GNUPG:] TRUST_ULTIMATE 0 pgp\n"),
+			[]byte("[GNUPG:] DECRYPTION_OKAY\n"), nil
+	})
+	if _, sig, err := Decrypt([]byte(encryptedMessage)); err != nil || sig.Status != "" {
+		t.Errorf("expected the forged signature to be ignored, got %+v, %v", sig, err)
+	}
+}
+
+func TestDecrypt_Errors(t *testing.T) {
+	stubGPG(t, func([]byte, ...string) ([]byte, []byte, []byte, error) {
+		return nil, []byte("gpg: decryption failed: No secret key\n"), []byte("[GNUPG:] DECRYPTION_FAILED\n"), errors.New("exit status 2")
+	})
+
+	if _, _, err := Decrypt([]byte("Content-Type: text/plain\r\n\r\nHi\r\n")); !errors.Is(err, ErrNotEncrypted) {
+		t.Errorf("expected ErrNotEncrypted, got %v", err)
+	}
+	_, _, err := Decrypt([]byte(encryptedMessage))
+	if err == nil || !strings.Contains(err.Error(), "decryption failed: No secret key") {
+		t.Errorf("expected gpg's reason, got %v", err)
+	}
+
+	lookPath = func(string) (string, error) { return "", errors.New("not found") }
+	if _, _, err := Decrypt([]byte(encryptedMessage)); !errors.Is(err, ErrNoGPG) {
+		t.Errorf("expected ErrNoGPG, got %v", err)
+	}
+}
+
+func TestRunWithStatus_ReadsDescriptor3(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as gpg")
+	}
+	dir := t.TempDir()
+	script := "#!/bin/sh\ncat\necho 'gpg: message' >&2\necho \"[GNUPG:] ARGS $*\" >&3\n"
+	if err := os.WriteFile(filepath.Join(dir, "gpg"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	stdout, stderr, status, err := runWithStatus([]byte("plain"), "--decrypt")
+	if err != nil {
+		t.Fatalf("runWithStatus: %v", err)
+	}
+	if string(stdout) != "plain" || string(stderr) != "gpg: message\n" {
+		t.Errorf("unexpected stdout %q or stderr %q", stdout, stderr)
+	}
+	if string(status) != "[GNUPG:] ARGS --status-fd 3 --decrypt\n" {
+		t.Errorf("unexpected status %q", status)
+	}
+}
@@ -0,0 +1,402 @@
+// Package signature checks the S/MIME and PGP signatures of raw RFC 5322
+// messages and decrypts PGP/MIME ones. The cryptography is left to the
+// openssl and gpg command-line tools, so S/MIME signers are checked against
+// the system trust store and PGP keys come from the user's keyring. A
+// missing tool yields the unverified status rather than an error.
+package signature
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/cboone/fm/internal/types"
+)
+
+// Signature types.
+const (
+	TypeSMIME = "smime"
+	TypePGP   = "pgp"
+)
+
+// Signature statuses. See types.Signature.
+const (
+	StatusUnsigned    = "unsigned"
+	StatusValid       = "valid"
+	StatusUntrusted   = "untrusted"
+	StatusInvalid     = "invalid"
+	StatusExpired     = "expired"
+	StatusRevoked     = "revoked"
+	StatusUnknownKey  = "unknown_key"
+	StatusUnsupported = "unsupported"
+	StatusUnverified  = "unverified"
+)
+
+// runCommand runs a tool with stdin as its input. Tests replace it.
+var runCommand = func(stdin []byte, name string, args ...string) (stdout, stderr []byte, err error) {
+	var out, errOut bytes.Buffer
+	c := exec.Command(name, args...)
+	c.Stdin = bytes.NewReader(stdin)
+	c.Stdout = &out
+	c.Stderr = &errOut
+	err = c.Run()
+	return out.Bytes(), errOut.Bytes(), err
+}
+
+// runWithStatus runs gpg with args and stdin like runCommand, adding a
+// status option that sends its status lines to a channel of their own, and
+// returns them apart from stdout and stderr. With the status lines on
+// stderr, text gpg prints there from the message itself, such as an
+// embedded file name, could pass for a status line (CVE-2018-12020). Tests
+// replace it.
+var runWithStatus = func(stdin []byte, args ...string) (stdout, stderr, status []byte, err error) {
+	var out, errOut, statusOut bytes.Buffer
+	c := exec.Command("gpg")
+	c.Stdin = bytes.NewReader(stdin)
+	c.Stdout = &out
+	c.Stderr = &errOut
+
+	if runtime.GOOS == "windows" {
+		// Windows cannot pass gpg a descriptor beyond stderr, so the
+		// status lines, which hold no plaintext, go through a file.
+		dir, err := os.MkdirTemp("", "fm-gpg-")
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		defer os.RemoveAll(dir)
+		statusPath := filepath.Join(dir, "status")
+		c.Args = append(append(c.Args, "--status-file", statusPath), args...)
+		err = c.Run()
+		data, _ := os.ReadFile(statusPath)
+		return out.Bytes(), errOut.Bytes(), data, err
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer r.Close()
+	c.Args = append(append(c.Args, "--status-fd", "3"), args...)
+	c.ExtraFiles = []*os.File{w}
+	done := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(&statusOut, r)
+		close(done)
+	}()
+	err = c.Start()
+	// gpg holds its own copy of the write end; closing ours lets the copy
+	// above end when gpg exits.
+	_ = w.Close()
+	if err == nil {
+		err = c.Wait()
+	}
+	<-done
+	return out.Bytes(), errOut.Bytes(), statusOut.Bytes(), err
+}
+
+// lookPath finds a verification tool. Tests replace it.
+var lookPath = exec.LookPath
+
+// Verify reports whether the raw message is signed and, if it is, whether
+// the signature holds. Only a signature over the whole message is
+// recognized: a top-level multipart/signed part, or opaque S/MIME
+// signed-data.
+func Verify(raw []byte) types.Signature {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return types.Signature{Status: StatusUnsupported, Detail: "cannot parse message: " + err.Error()}
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		return types.Signature{Status: StatusUnsigned}
+	}
+
+	switch mediaType {
+	case "multipart/signed":
+		switch protocol := strings.ToLower(params["protocol"]); protocol {
+		case "application/pkcs7-signature", "application/x-pkcs7-signature":
+			return verifySMIME(raw)
+		case "application/pgp-signature":
+			body, err := io.ReadAll(msg.Body)
+			if err != nil {
+				return types.Signature{Type: TypePGP, Status: StatusInvalid, Detail: err.Error()}
+			}
+			signed, sig, err := splitSigned(body, params["boundary"])
+			if err != nil {
+				return types.Signature{Type: TypePGP, Status: StatusInvalid, Detail: err.Error()}
+			}
+			return verifyPGP(signed, sig)
+		default:
+			return types.Signature{Status: StatusUnsupported, Detail: fmt.Sprintf("unknown signature protocol %q", protocol)}
+		}
+	case "application/pkcs7-mime", "application/x-pkcs7-mime":
+		if strings.EqualFold(params["smime-type"], "signed-data") {
+			return verifySMIME(raw)
+		}
+	}
+	return types.Signature{Status: StatusUnsigned}
+}
+
+// splitSigned returns the signed first part of a multipart/signed body,
+// headers included and with CRLF line endings as RFC 3156 requires, and the
+// decoded content of the signature part.
+func splitSigned(body []byte, boundary string) (signed, sig []byte, err error) {
+	if boundary == "" {
+		return nil, nil, errors.New("multipart/signed without a boundary")
+	}
+	body = bytes.ReplaceAll(bytes.ReplaceAll(body, []byte("\r\n"), []byte("\n")), []byte("\n"), []byte("\r\n"))
+	// A leading CRLF lets a delimiter on the first line match like the rest.
+	body = append([]byte("\r\n"), body...)
+	delim := []byte("\r\n--" + boundary)
+
+	i := bytes.Index(body, delim)
+	if i < 0 {
+		return nil, nil, errors.New("multipart/signed without its boundary")
+	}
+	var parts [][]byte
+	rest := body[i+len(delim):]
+	for !bytes.HasPrefix(rest, []byte("--")) {
+		// Skip transport padding after the delimiter.
+		eol := bytes.Index(rest, []byte("\r\n"))
+		if eol < 0 {
+			break
+		}
+		rest = rest[eol+2:]
+		j := bytes.Index(rest, delim)
+		if j < 0 {
+			break
+		}
+		parts = append(parts, rest[:j])
+		rest = rest[j+len(delim):]
+	}
+	if len(parts) < 2 {
+		return nil, nil, errors.New("multipart/signed without a signature part")
+	}
+
+	sigMsg, err := mail.ReadMessage(bytes.NewReader(parts[1]))
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading signature part: %w", err)
+	}
+	var r io.Reader = sigMsg.Body
+	switch strings.ToLower(sigMsg.Header.Get("Content-Transfer-Encoding")) {
+	case "base64":
+		r = base64.NewDecoder(base64.StdEncoding, newlineStripper{r})
+	case "quoted-printable":
+		r = quotedprintable.NewReader(r)
+	}
+	sig, err = io.ReadAll(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("decoding signature part: %w", err)
+	}
+	return parts[0], sig, nil
+}
+
+// newlineStripper drops line breaks so base64 can be decoded in one piece.
+type newlineStripper struct{ r io.Reader }
+
+func (s newlineStripper) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	out := p[:0]
+	for _, b := range p[:n] {
+		if b != '\r' && b != '\n' {
+			out = append(out, b)
+		}
+	}
+	return len(out), err
+}
+
+// verifyPGP checks a detached PGP signature over signed with gpg.
+func verifyPGP(signed, sig []byte) types.Signature {
+	if _, err := lookPath("gpg"); err != nil {
+		return types.Signature{Type: TypePGP, Status: StatusUnverified, Detail: "gpg not found"}
+	}
+	dir, err := os.MkdirTemp("", "fm-verify-")
+	if err != nil {
+		return types.Signature{Type: TypePGP, Status: StatusUnverified, Detail: err.Error()}
+	}
+	defer os.RemoveAll(dir)
+	dataPath := filepath.Join(dir, "signed")
+	sigPath := filepath.Join(dir, "signature.asc")
+	if err := os.WriteFile(dataPath, signed, 0o600); err != nil {
+		return types.Signature{Type: TypePGP, Status: StatusUnverified, Detail: err.Error()}
+	}
+	if err := os.WriteFile(sigPath, sig, 0o600); err != nil {
+		return types.Signature{Type: TypePGP, Status: StatusUnverified, Detail: err.Error()}
+	}
+
+	// gpg exits non-zero for bad or unverifiable signatures; the status
+	// lines say why.
+	stdout, stderr, _ := runCommand(nil, "gpg", "--batch", "--no-tty", "--status-fd", "1", "--verify", sigPath, dataPath)
+	result := parseGPGStatus(stdout)
+	if result.Status == "" {
+		result.Status = StatusUnverified
+		result.Detail = firstLine(stderr)
+	}
+	return result
+}
+
+// parseGPGStatus interprets the machine-readable output of gpg
+// --status-fd.
+func parseGPGStatus(out []byte) types.Signature {
+	result := types.Signature{Type: TypePGP}
+	trusted := false
+	for _, line := range strings.Split(string(out), "\n") {
+		rest, ok := strings.CutPrefix(strings.TrimSpace(line), "[GNUPG:] ")
+		if !ok {
+			continue
+		}
+		fields := strings.SplitN(rest, " ", 3)
+		uid := ""
+		if len(fields) == 3 {
+			uid = fields[2]
+		}
+		switch fields[0] {
+		case "GOODSIG":
+			result.Status, result.Signer = StatusValid, uid
+		case "BADSIG":
+			result.Status, result.Signer = StatusInvalid, uid
+		case "EXPSIG", "EXPKEYSIG":
+			result.Status, result.Signer = StatusExpired, uid
+		case "REVKEYSIG":
+			result.Status, result.Signer = StatusRevoked, uid
+		case "NO_PUBKEY":
+			result.Status = StatusUnknownKey
+			if len(fields) > 1 {
+				result.Fingerprint = fields[1]
+				result.Detail = "no public key for " + fields[1]
+			}
+		case "ERRSIG":
+			if result.Status == "" {
+				result.Status = StatusInvalid
+			}
+		case "VALIDSIG":
+			if len(fields) > 1 {
+				result.Fingerprint = fields[1]
+			}
+		case "TRUST_FULLY", "TRUST_ULTIMATE":
+			trusted = true
+		}
+	}
+	if result.Status == StatusValid && !trusted {
+		result.Status = StatusUntrusted
+		result.Detail = "the signing key is not certified as trusted"
+	}
+	return result
+}
+
+// verifySMIME checks an S/MIME signature with openssl against the system
+// trust store. A signature that holds but whose certificate does not chain
+// to a trusted root is untrusted, or expired when the certificate has
+// expired.
+func verifySMIME(raw []byte) types.Signature {
+	if _, err := lookPath("openssl"); err != nil {
+		return types.Signature{Type: TypeSMIME, Status: StatusUnverified, Detail: "openssl not found"}
+	}
+	dir, err := os.MkdirTemp("", "fm-verify-")
+	if err != nil {
+		return types.Signature{Type: TypeSMIME, Status: StatusUnverified, Detail: err.Error()}
+	}
+	defer os.RemoveAll(dir)
+	msgPath := filepath.Join(dir, "message.eml")
+	signerPath := filepath.Join(dir, "signer.pem")
+	if err := os.WriteFile(msgPath, raw, 0o600); err != nil {
+		return types.Signature{Type: TypeSMIME, Status: StatusUnverified, Detail: err.Error()}
+	}
+
+	args := []string{"smime", "-verify", "-in", msgPath, "-signer", signerPath, "-out", os.DevNull}
+	_, stderr, err := runCommand(nil, "openssl", args...)
+	if err == nil {
+		return smimeResult(StatusValid, signerPath, "")
+	}
+	chainErr := opensslReason(stderr)
+
+	// Check the signature alone to tell a bad signature from an untrusted
+	// certificate.
+	_, stderr, err = runCommand(nil, "openssl", append(args, "-noverify")...)
+	if err != nil {
+		return types.Signature{Type: TypeSMIME, Status: StatusInvalid, Detail: opensslReason(stderr)}
+	}
+	status := StatusUntrusted
+	if strings.Contains(chainErr, "certificate has expired") {
+		status = StatusExpired
+	}
+	return smimeResult(status, signerPath, chainErr)
+}
+
+// smimeResult builds an S/MIME result, naming the signer from the
+// certificate openssl wrote to signerPath.
+func smimeResult(status, signerPath, detail string) types.Signature {
+	result := types.Signature{Type: TypeSMIME, Status: status, Detail: detail}
+	data, err := os.ReadFile(signerPath)
+	if err != nil {
+		return result
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return result
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return result
+	}
+	result.Signer = certSigner(cert)
+	sum := sha256.Sum256(cert.Raw)
+	result.Fingerprint = strings.ToUpper(hex.EncodeToString(sum[:]))
+	return result
+}
+
+// certSigner names a certificate's subject as "Name <email>".
+func certSigner(cert *x509.Certificate) string {
+	name := cert.Subject.CommonName
+	if len(cert.EmailAddresses) == 0 {
+		return name
+	}
+	if name == "" || name == cert.EmailAddresses[0] {
+		return cert.EmailAddresses[0]
+	}
+	return name + " <" + cert.EmailAddresses[0] + ">"
+}
+
+// opensslReason picks the reason out of openssl's first error line, such
+// as "self-signed certificate" from
+//
+//	...:error:10800075:PKCS7 routines:PKCS7_verify:certificate verify error:pk7_smime.c:295:Verify error: self-signed certificate
+//
+// falling back to the first line of output.
+func opensslReason(stderr []byte) string {
+	for _, line := range strings.Split(string(stderr), "\n") {
+		fields := strings.SplitN(strings.TrimSpace(line), ":", 9)
+		if len(fields) < 6 || fields[1] != "error" {
+			continue
+		}
+		if len(fields) == 9 && strings.TrimSpace(fields[8]) != "" {
+			return strings.TrimPrefix(strings.TrimSpace(fields[8]), "Verify error: ")
+		}
+		return fields[5]
+	}
+	return firstLine(stderr)
+}
+
+// firstLine returns the first non-empty line of b.
+func firstLine(b []byte) string {
+	for _, line := range strings.Split(string(b), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
@@ -0,0 +1,173 @@
+package signature
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const pgpMessage = "From: bob@example.com\n" +
+	"Subject: signed\n" +
+	"MIME-Version: 1.0\n" +
+	"Content-Type: multipart/signed; micalg=pgp-sha256;\n" +
+	" protocol=\"application/pgp-signature\"; boundary=\"XYZ\"\n" +
+	"\n" +
+	"This is an OpenPGP/MIME signed message\n" +
+	"--XYZ\n" +
+	"Content-Type: text/plain\n" +
+	"\n" +
+	"Hello PGP\n" +
+	"--XYZ\n" +
+	"Content-Type: application/pgp-signature; name=\"signature.asc\"\n" +
+	"\n" +
+	"-----BEGIN PGP SIGNATURE-----\n" +
+	"AAAA\n" +
+	"-----END PGP SIGNATURE-----\n" +
+	"--XYZ--\n"
+
+const smimeMessage = "From: alice@example.com\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/signed; protocol=\"application/x-pkcs7-signature\"; micalg=\"sha-256\"; boundary=\"B\"\r\n" +
+	"\r\n" +
+	"--B\r\n" +
+	"Content-Type: text/plain\r\n" +
+	"\r\n" +
+	"Hello\r\n" +
+	"--B\r\n" +
+	"Content-Type: application/x-pkcs7-signature; name=\"smime.p7s\"\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"AAAA\r\n" +
+	"--B--\r\n"
+
+// stubTools replaces the tool lookup and runner for one test.
+func stubTools(t *testing.T, run func(stdin []byte, name string, args ...string) ([]byte, []byte, error)) {
+	t.Helper()
+	origRun, origLook := runCommand, lookPath
+	t.Cleanup(func() { runCommand, lookPath = origRun, origLook })
+	runCommand = run
+	lookPath = func(name string) (string, error) { return "/usr/bin/" + name, nil }
+}
+
+func TestVerify_Unsigned(t *testing.T) {
+	got := Verify([]byte("From: a@example.com\r\nContent-Type: text/plain\r\n\r\nHi\r\n"))
+	if got.Status != StatusUnsigned || got.Type != "" {
+		t.Errorf("expected unsigned, got %+v", got)
+	}
+}
+
+func TestVerify_UnknownProtocol(t *testing.T) {
+	got := Verify([]byte("Content-Type: multipart/signed; protocol=\"application/x-other\"; boundary=B\r\n\r\n--B--\r\n"))
+	if got.Status != StatusUnsupported {
+		t.Errorf("expected unsupported, got %+v", got)
+	}
+}
+
+func TestVerify_PGP(t *testing.T) {
+	var signed, sig string
+	stubTools(t, func(_ []byte, name string, args ...string) ([]byte, []byte, error) {
+		if name != "gpg" || !slices.Contains(args, "--verify") {
+			t.Fatalf("unexpected command %s %v", name, args)
+		}
+		sigData, _ := os.ReadFile(args[len(args)-2])
+		data, _ := os.ReadFile(args[len(args)-1])
+		sig, signed = string(sigData), string(data)
+		return []byte("[GNUPG:] NEWSIG\n" +
+			"[GNUPG:] GOODSIG FB3BD22544178AE5 Bob Example <bob@example.com>\n" +
+			"[GNUPG:] VALIDSIG 96F7A77D14C9B225794A21A7FB3BD22544178AE5 2026-10-15 0 4 0 22 8 00 96F7A77D14C9B225794A21A7FB3BD22544178AE5\n" +
+			"[GNUPG:] TRUST_ULTIMATE 0 pgp\n"), nil, nil
+	})
+
+	got := Verify([]byte(pgpMessage))
+	if got.Type != TypePGP || got.Status != StatusValid || got.Signer != "Bob Example <bob@example.com>" {
+		t.Errorf("expected a valid signature by Bob, got %+v", got)
+	}
+	if got.Fingerprint != "96F7A77D14C9B225794A21A7FB3BD22544178AE5" {
+		t.Errorf("expected the VALIDSIG fingerprint, got %q", got.Fingerprint)
+	}
+	if signed != "Content-Type: text/plain\r\n\r\nHello PGP" {
+		t.Errorf("expected the signed part with CRLF line endings, got %q", signed)
+	}
+	if !strings.HasPrefix(sig, "-----BEGIN PGP SIGNATURE-----") {
+		t.Errorf("expected the armored signature, got %q", sig)
+	}
+}
+
+func TestVerify_ToolMissing(t *testing.T) {
+	stubTools(t, func([]byte, string, ...string) ([]byte, []byte, error) {
+		t.Fatal("expected no command to run")
+		return nil, nil, nil
+	})
+	lookPath = func(name string) (string, error) { return "", errors.New("not found") }
+
+	if got := Verify([]byte(pgpMessage)); got.Status != StatusUnverified || got.Detail != "gpg not found" {
+		t.Errorf("expected unverified PGP, got %+v", got)
+	}
+	if got := Verify([]byte(smimeMessage)); got.Status != StatusUnverified || got.Detail != "openssl not found" {
+		t.Errorf("expected unverified S/MIME, got %+v", got)
+	}
+}
+
+func TestVerify_SMIME(t *testing.T) {
+	chainErr := []byte("Verification failure\n" +
+		"801B0D20687F0000:error:10800075:PKCS7 routines:PKCS7_verify:certificate verify error:../crypto/pkcs7/pk7_smime.c:295:Verify error: self-signed certificate\n")
+	sigErr := []byte("Verification failure\n" +
+		"80CB82B42C7F0000:error:10800065:PKCS7 routines:PKCS7_signatureVerify:digest failure:../crypto/pkcs7/pk7_doit.c:1090:\n")
+	failed := errors.New("exit status 4")
+
+	tests := []struct {
+		name       string
+		chain, sig error
+		wantStatus string
+		wantDetail string
+	}{
+		{"trusted", nil, nil, StatusValid, ""},
+		{"untrusted certificate", failed, nil, StatusUntrusted, "self-signed certificate"},
+		{"bad signature", failed, failed, StatusInvalid, "digest failure"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stubTools(t, func(_ []byte, name string, args ...string) ([]byte, []byte, error) {
+				if slices.Contains(args, "-noverify") {
+					if tt.sig != nil {
+						return nil, sigErr, tt.sig
+					}
+					return nil, nil, nil
+				}
+				if tt.chain != nil {
+					return nil, chainErr, tt.chain
+				}
+				return nil, nil, nil
+			})
+			got := Verify([]byte(smimeMessage))
+			if got.Type != TypeSMIME || got.Status != tt.wantStatus || got.Detail != tt.wantDetail {
+				t.Errorf("got %+v, want status %s and detail %q", got, tt.wantStatus, tt.wantDetail)
+			}
+		})
+	}
+}
+
+func TestParseGPGStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		out    string
+		status string
+	}{
+		{"good and trusted", "[GNUPG:] GOODSIG K Bob\n[GNUPG:] TRUST_FULLY 0 pgp\n", StatusValid},
+		{"good but not certified", "[GNUPG:] GOODSIG K Bob\n[GNUPG:] TRUST_UNDEFINED 0 pgp\n", StatusUntrusted},
+		{"bad", "[GNUPG:] BADSIG K Bob\n", StatusInvalid},
+		{"expired key", "[GNUPG:] EXPKEYSIG K Bob\n", StatusExpired},
+		{"revoked key", "[GNUPG:] REVKEYSIG K Bob\n", StatusRevoked},
+		{"missing key", "[GNUPG:] ERRSIG K 22 8 00 1760000000 9 -\n[GNUPG:] NO_PUBKEY K\n", StatusUnknownKey},
+		{"no status lines", "gpg: no valid OpenPGP data found.\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseGPGStatus([]byte(tt.out)); got.Status != tt.status {
+				t.Errorf("status = %q, want %q", got.Status, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+// Package sqlexport renders emails as a SQL script for the sqlite3 shell.
+// Re-running an export into the same database replaces the rows of emails
+// it already holds.
+package sqlexport
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/cboone/fm/internal/client"
+	"github.com/cboone/fm/internal/types"
+)
+
+// Schema creates the export tables. Timestamps are RFC 3339 UTC strings and
+// booleans are 0 or 1.
+const Schema = `CREATE TABLE IF NOT EXISTS mailboxes (
+  id        TEXT PRIMARY KEY,
+  name      TEXT NOT NULL,
+  role      TEXT,
+  parent_id TEXT
+);
+CREATE TABLE IF NOT EXISTS emails (
+  id          TEXT PRIMARY KEY,
+  thread_id   TEXT NOT NULL,
+  message_id  TEXT,
+  subject     TEXT NOT NULL,
+  from_name   TEXT,
+  from_email  TEXT,
+  sent_at     TEXT,
+  received_at TEXT NOT NULL,
+  size        INTEGER NOT NULL,
+  is_unread   INTEGER NOT NULL,
+  is_flagged  INTEGER NOT NULL,
+  preview     TEXT NOT NULL,
+  body        TEXT
+);
+CREATE TABLE IF NOT EXISTS recipients (
+  email_id TEXT NOT NULL REFERENCES emails(id),
+  kind     TEXT NOT NULL CHECK (kind IN ('to', 'cc', 'bcc')),
+  name     TEXT,
+  address  TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS keywords (
+  email_id TEXT NOT NULL REFERENCES emails(id),
+  keyword  TEXT NOT NULL,
+  PRIMARY KEY (email_id, keyword)
+);
+CREATE TABLE IF NOT EXISTS email_mailboxes (
+  email_id   TEXT NOT NULL REFERENCES emails(id),
+  mailbox_id TEXT NOT NULL REFERENCES mailboxes(id),
+  PRIMARY KEY (email_id, mailbox_id)
+);
+CREATE INDEX IF NOT EXISTS recipients_email_id ON recipients(email_id);
+CREATE INDEX IF NOT EXISTS emails_received_at ON emails(received_at);
+`
+
+// Mailbox is a row of the mailboxes table.
+type Mailbox struct {
+	ID       string
+	Name     string
+	Role     string
+	ParentID string
+}
+
+// Write renders the schema, mailboxes, and messages as one transaction.
+func Write(w io.Writer, mailboxes []Mailbox, messages []client.ExportMessage) error {
+	var b strings.Builder
+	b.WriteString("BEGIN;\n")
+	b.WriteString(Schema)
+
+	for _, mb := range mailboxes {
+		fmt.Fprintf(&b, "INSERT OR REPLACE INTO mailboxes VALUES (%s, %s, %s, %s);\n",
+			quote(mb.ID), quote(mb.Name), nullable(mb.Role), nullable(mb.ParentID))
+	}
+
+	for _, m := range messages {
+		id := quote(m.ID)
+		for _, table := range []string{"recipients", "keywords", "email_mailboxes"} {
+			fmt.Fprintf(&b, "DELETE FROM %s WHERE email_id = %s;\n", table, id)
+		}
+
+		var fromName, fromEmail string
+		if len(m.From) > 0 {
+			fromName, fromEmail = m.From[0].Name, m.From[0].Email
+		}
+		body := "NULL"
+		if m.Body != nil {
+			body = quote(*m.Body)
+		}
+		fmt.Fprintf(&b, "INSERT OR REPLACE INTO emails VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %d, %d, %d, %s, %s);\n",
+			id, quote(m.ThreadID), nullable(m.MessageID), quote(m.Subject),
+			nullable(fromName), nullable(fromEmail), timestamp(m.SentAt),
+			quote(m.ReceivedAt.UTC().Format(time.RFC3339)), m.Size,
+			boolInt(!hasKeyword(m.Keywords, "$seen")), boolInt(hasKeyword(m.Keywords, "$flagged")),
+			quote(m.Preview), body)
+
+		writeRecipients(&b, id, "to", m.To)
+		writeRecipients(&b, id, "cc", m.CC)
+		writeRecipients(&b, id, "bcc", m.BCC)
+		for _, kw := range m.Keywords {
+			fmt.Fprintf(&b, "INSERT INTO keywords VALUES (%s, %s);\n", id, quote(kw))
+		}
+		for _, mb := range m.MailboxIDs {
+			fmt.Fprintf(&b, "INSERT INTO email_mailboxes VALUES (%s, %s);\n", id, quote(mb))
+		}
+	}
+
+	b.WriteString("COMMIT;\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeRecipients(b *strings.Builder, id, kind string, addrs []types.Address) {
+	for _, a := range addrs {
+		fmt.Fprintf(b, "INSERT INTO recipients VALUES (%s, '%s', %s, %s);\n", id, kind, nullable(a.Name), quote(a.Email))
+	}
+}
+
+// quote returns s as a SQL string literal.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// nullable quotes s, or returns NULL when it is empty.
+func nullable(s string) string {
+	if s == "" {
+		return "NULL"
+	}
+	return quote(s)
+}
+
+func timestamp(t *time.Time) string {
+	if t == nil {
+		return "NULL"
+	}
+	return quote(t.UTC().Format(time.RFC3339))
+}
+
+func boolInt(v bool) int {
+	if v {
+		return 1
+	}
+	return 0
+}
+
+func hasKeyword(keywords []string, kw string) bool {
+	for _, k := range keywords {
+		if k == kw {
+			return true
+		}
+	}
+	return false
+}
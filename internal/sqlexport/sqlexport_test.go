@@ -0,0 +1,62 @@
+package sqlexport
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cboone/fm/internal/client"
+	"github.com/cboone/fm/internal/types"
+)
+
+func TestWrite(t *testing.T) {
+	body := "Hi,\nit's here."
+	messages := []client.ExportMessage{
+		{
+			ID:         "M1",
+			ThreadID:   "T1",
+			MessageID:  "abc@example.com",
+			Subject:    "Bob's invoice",
+			From:       []types.Address{{Name: "Bob", Email: "bob@example.com"}},
+			To:         []types.Address{{Email: "me@example.com"}},
+			ReceivedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+			Size:       1024,
+			Keywords:   []string{"$flagged", "$seen"},
+			MailboxIDs: []string{"mb-inbox"},
+			Preview:    "Hi, it's here.",
+			Body:       &body,
+		},
+		{
+			ID:         "M2",
+			ThreadID:   "T2",
+			Subject:    "No body",
+			ReceivedAt: time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	mailboxes := []Mailbox{{ID: "mb-inbox", Name: "Inbox", Role: "inbox"}}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, mailboxes, messages); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	got := buf.String()
+
+	for _, want := range []string{
+		"BEGIN;\n",
+		"INSERT OR REPLACE INTO mailboxes VALUES ('mb-inbox', 'Inbox', 'inbox', NULL);",
+		"DELETE FROM keywords WHERE email_id = 'M1';",
+		"INSERT OR REPLACE INTO emails VALUES ('M1', 'T1', 'abc@example.com', 'Bob''s invoice', 'Bob', 'bob@example.com', NULL, '2026-01-02T03:04:05Z', 1024, 0, 1, 'Hi, it''s here.', 'Hi,\nit''s here.');",
+		"INSERT INTO recipients VALUES ('M1', 'to', NULL, 'me@example.com');",
+		"INSERT INTO keywords VALUES ('M1', '$flagged');",
+		"INSERT INTO email_mailboxes VALUES ('M1', 'mb-inbox');",
+		"INSERT OR REPLACE INTO emails VALUES ('M2', 'T2', NULL, 'No body', NULL, NULL, NULL, '2026-01-03T00:00:00Z', 0, 1, 0, '', NULL);",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected script to contain %q", want)
+		}
+	}
+	if !strings.HasSuffix(got, "COMMIT;\n") {
+		t.Errorf("expected script to end with COMMIT, got %q", got[len(got)-20:])
+	}
+}
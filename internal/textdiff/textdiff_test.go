@@ -0,0 +1,73 @@
+package textdiff
+
+import "testing"
+
+func TestUnified_Identical(t *testing.T) {
+	if got := Unified("a", "b", "keep;\n", "keep;\n"); got != "" {
+		t.Errorf("expected empty diff, got %q", got)
+	}
+}
+
+func TestUnified_SingleChange(t *testing.T) {
+	a := "require \"fileinto\";\nif true {\n  fileinto \"A\";\n}\n"
+	b := "require \"fileinto\";\nif true {\n  fileinto \"B\";\n}\n"
+
+	want := "--- server\n+++ local\n" +
+		"@@ -1,4 +1,4 @@\n" +
+		" require \"fileinto\";\n" +
+		" if true {\n" +
+		"-  fileinto \"A\";\n" +
+		"+  fileinto \"B\";\n" +
+		" }\n"
+	if got := Unified("server", "local", a, b); got != want {
+		t.Errorf("unexpected diff:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUnified_SeparateHunks(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	b := "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ntwelve\n"
+
+	want := "--- a\n+++ b\n" +
+		"@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n" +
+		"@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+twelve\n"
+	if got := Unified("a", "b", a, b); got != want {
+		t.Errorf("unexpected diff:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUnified_NewFile(t *testing.T) {
+	want := "--- a\n+++ b\n@@ -0,0 +1,2 @@\n+keep;\n+stop;\n"
+	if got := Unified("a", "b", "", "keep;\nstop;\n"); got != want {
+		t.Errorf("unexpected diff:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestUnified_MissingNewlineAtEnd(t *testing.T) {
+	want := "--- a\n+++ b\n@@ -1,2 +1,2 @@\n keep;\n-stop;\n+stop;\n\\ No newline at end of file\n"
+	if got := Unified("a", "b", "keep;\nstop;\n", "keep;\nstop;"); got != want {
+		t.Errorf("unexpected diff:\n%q\nwant:\n%q", got, want)
+	}
+
+	want = "--- a\n+++ b\n@@ -1 +1,2 @@\n-x\n\\ No newline at end of file\n+x\n+y\n"
+	if got := Unified("a", "b", "x", "x\ny\n"); got != want {
+		t.Errorf("unexpected diff:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestDistance(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want int
+	}{
+		{"session_url", "session_url", 0},
+		{"sesion_url", "session_url", 1},
+		{"reciepts", "receipts", 2},
+		{"", "inbox", 5},
+		{"café", "cafe", 1},
+	} {
+		if got := Distance(tc.a, tc.b); got != tc.want {
+			t.Errorf("Distance(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+// Package travel reads flight and hotel bookings from the schema.org
+// JSON-LD that airlines, hotels, and travel agents embed in the HTML of
+// their confirmation emails (https://schema.org/FlightReservation and
+// https://schema.org/LodgingReservation).
+package travel
+
+import (
+	"strings"
+	"time"
+
+	"github.com/cboone/fm/internal/jsonld"
+)
+
+// Kinds of reservations.
+const (
+	Flight  = "flight"
+	Lodging = "lodging"
+)
+
+// Reservation is a booked flight or hotel stay. For a flight, Start and
+// End are its departure and arrival, From and To the airports, and
+// FlightNumber includes the airline code; for a hotel stay, Start and End
+// are the check-in and check-out, and Name and Address are the hotel's.
+// Provider is the airline or hotel chain, or the agent the booking was
+// made through. End is zero when the markup does not give it.
+type Reservation struct {
+	Kind          string
+	ReservationID string
+	Provider      string
+	Cancelled     bool
+	Start         time.Time
+	End           time.Time
+	FlightNumber  string
+	From          string
+	To            string
+	Name          string
+	Address       string
+}
+
+// timeLayouts are the date and time forms of schema.org DateTime and Date
+// values. Times without a zone are read in the local time zone.
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// Parse returns the flight and hotel reservations described by the
+// JSON-LD in html, in document order. A reservation covering several
+// flights is returned once per flight. Reservations without a start time
+// are left out.
+func Parse(html string) []Reservation {
+	var reservations []Reservation
+	for _, node := range jsonld.Nodes(html) {
+		switch {
+		case node.Is("FlightReservation"):
+			for _, flight := range node.Nodes("reservationFor") {
+				if r, ok := flightReservation(node, flight); ok {
+					reservations = append(reservations, r)
+				}
+			}
+		case node.Is("LodgingReservation"):
+			if r, ok := lodgingReservation(node); ok {
+				reservations = append(reservations, r)
+			}
+		}
+	}
+	return reservations
+}
+
+// reservation reads what all kinds of reservations have in common.
+func reservation(node jsonld.Node, kind string) Reservation {
+	status := node.Text("reservationStatus")
+	if s := node.Node("reservationStatus"); s != nil {
+		status = s.Text("@id", "name")
+	}
+	return Reservation{
+		Kind:          kind,
+		ReservationID: node.Text("reservationNumber", "reservationId", "confirmationNumber"),
+		Provider:      node.Name("provider", "broker"),
+		Cancelled:     strings.HasSuffix(status, "ReservationCancelled"),
+	}
+}
+
+// flightReservation reads the booking of flight from the
+// FlightReservation node.
+func flightReservation(node, flight jsonld.Node) (Reservation, bool) {
+	r := reservation(node, Flight)
+	start, ok := parseTime(flight.Text("departureTime"))
+	if !ok {
+		return Reservation{}, false
+	}
+	r.Start = start
+	r.End, _ = parseTime(flight.Text("arrivalTime"))
+
+	airline := flight.Node("airline", "provider")
+	code := flight.Text("flightNumber")
+	if airline != nil {
+		if name := airline.Text("name"); name != "" {
+			r.Provider = name
+		}
+		if iata := airline.Text("iataCode"); iata != "" && !strings.HasPrefix(code, iata) {
+			code = iata + " " + code
+		}
+	}
+	r.FlightNumber = code
+	r.From = airport(flight.Node("departureAirport"))
+	r.To = airport(flight.Node("arrivalAirport"))
+	return r, true
+}
+
+// lodgingReservation reads a LodgingReservation node.
+func lodgingReservation(node jsonld.Node) (Reservation, bool) {
+	r := reservation(node, Lodging)
+	start, ok := parseTime(node.Text("checkinTime", "checkinDate"))
+	if !ok {
+		return Reservation{}, false
+	}
+	r.Start = start
+	r.End, _ = parseTime(node.Text("checkoutTime", "checkoutDate"))
+
+	if hotel := node.Node("reservationFor"); hotel != nil {
+		r.Name = hotel.Text("name")
+		r.Address = address(hotel)
+		if r.Provider == "" {
+			r.Provider = hotel.Name("brand", "parentOrganization")
+		}
+	}
+	return r, true
+}
+
+// airport returns the IATA code of an Airport node, or its name.
+func airport(node jsonld.Node) string {
+	if node == nil {
+		return ""
+	}
+	if code := node.Text("iataCode"); code != "" {
+		return code
+	}
+	return node.Text("name")
+}
+
+// address returns the address of a place on one line: a PostalAddress
+// node joined with commas, or the address as written.
+func address(place jsonld.Node) string {
+	addr := place.Node("address")
+	if addr == nil {
+		return place.Text("address")
+	}
+	var parts []string
+	for _, key := range []string{"streetAddress", "addressLocality", "addressRegion", "postalCode", "addressCountry"} {
+		if v := addr.Name(key); v != "" {
+			parts = append(parts, v)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// parseTime reads a schema.org DateTime or Date.
+func parseTime(s string) (time.Time, bool) {
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,100 @@
+package travel
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	date := func(s string) time.Time {
+		t.Helper()
+		v, ok := parseTime(s)
+		if !ok {
+			t.Fatalf("parseTime(%q) failed", s)
+		}
+		return v
+	}
+
+	tests := []struct {
+		name string
+		html string
+		want []Reservation
+	}{
+		{
+			name: "flight",
+			html: `<script type="application/ld+json">{
+  "@context": "http://schema.org",
+  "@type": "FlightReservation",
+  "reservationNumber": "RXJ34P",
+  "reservationStatus": "http://schema.org/ReservationConfirmed",
+  "reservationFor": {
+    "@type": "Flight",
+    "flightNumber": "110",
+    "airline": {"@type": "Airline", "name": "United", "iataCode": "UA"},
+    "departureAirport": {"@type": "Airport", "name": "San Francisco Airport", "iataCode": "SFO"},
+    "departureTime": "2027-03-04T20:15:00-08:00",
+    "arrivalAirport": {"@type": "Airport", "name": "John F. Kennedy International Airport", "iataCode": "JFK"},
+    "arrivalTime": "2027-03-05T06:30:00-05:00"
+  }
+}</script>`,
+			want: []Reservation{{
+				Kind: Flight, ReservationID: "RXJ34P", Provider: "United",
+				Start: date("2027-03-04T20:15:00-08:00"), End: date("2027-03-05T06:30:00-05:00"),
+				FlightNumber: "UA 110", From: "SFO", To: "JFK",
+			}},
+		},
+		{
+			name: "connecting flights and a hotel booked through an agent",
+			html: `<script type="application/ld+json">[
+  {"@type": "FlightReservation", "reservationNumber": "Q7", "broker": {"name": "Trips Inc"},
+   "reservationFor": [
+     {"@type": "Flight", "flightNumber": "BA283", "airline": {"iataCode": "BA"},
+      "departureAirport": {"name": "Heathrow"}, "departureTime": "2027-05-01T09:00:00+01:00"},
+     {"@type": "Flight", "flightNumber": "1200", "departureTime": "bad"}
+   ]},
+  {"@type": "LodgingReservation", "reservationNumber": "H-55",
+   "reservationStatus": {"@id": "https://schema.org/ReservationCancelled"},
+   "reservationFor": {"@type": "LodgingBusiness", "name": "Harbor Hotel",
+     "brand": {"name": "Harbor Group"},
+     "address": {"@type": "PostalAddress", "streetAddress": "1 Pier Rd", "addressLocality": "Boston",
+       "addressRegion": "MA", "addressCountry": {"@type": "Country", "name": "US"}}},
+   "checkinDate": "2027-05-01", "checkoutDate": "2027-05-03"}
+]</script>`,
+			want: []Reservation{
+				{
+					Kind: Flight, ReservationID: "Q7", Provider: "Trips Inc",
+					Start: date("2027-05-01T09:00:00+01:00"), FlightNumber: "BA283", From: "Heathrow",
+				},
+				{
+					Kind: Lodging, ReservationID: "H-55", Provider: "Harbor Group", Cancelled: true,
+					Start: date("2027-05-01"), End: date("2027-05-03"),
+					Name: "Harbor Hotel", Address: "1 Pier Rd, Boston, MA, US",
+				},
+			},
+		},
+		{
+			name: "hotel with local times and a plain address",
+			html: `<script type="application/ld+json">{"@type": "LodgingReservation", "reservationId": "881",
+  "reservationFor": {"name": "Inn at the Park", "address": "12 Elm St, Portland"},
+  "checkinTime": "2027-06-10T15:00", "checkoutTime": "2027-06-12T11:00:00"}</script>`,
+			want: []Reservation{{
+				Kind: Lodging, ReservationID: "881",
+				Start: date("2027-06-10T15:00"), End: date("2027-06-12T11:00:00"),
+				Name: "Inn at the Park", Address: "12 Elm St, Portland",
+			}},
+		},
+		{
+			name: "no reservations",
+			html: `<script type="application/ld+json">{"@type": "Order", "orderNumber": "1"}</script>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Parse(tt.html)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -10,9 +10,11 @@ type Address struct {
 
 // Attachment is a simplified attachment descriptor for output.
 type Attachment struct {
-	Name string `json:"name"`
-	Type string `json:"type"`
-	Size uint64 `json:"size"`
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Size   uint64 `json:"size"`
+	BlobID string `json:"blob_id,omitempty"`
+	Inline bool   `json:"inline,omitempty"`
 }
 
 // MailboxInfo is a simplified mailbox for output.
@@ -27,18 +29,67 @@ type MailboxInfo struct {
 
 // EmailSummary is a brief view of an email for list/search results.
 type EmailSummary struct {
-	ID         string    `json:"id"`
-	ThreadID   string    `json:"thread_id"`
-	From       []Address `json:"from"`
-	To         []Address `json:"to"`
-	CC         []Address `json:"cc,omitempty"`
-	Subject    string    `json:"subject"`
-	ReceivedAt time.Time `json:"received_at"`
-	Size       uint64    `json:"size"`
-	IsUnread   bool      `json:"is_unread"`
-	IsFlagged  bool      `json:"is_flagged"`
-	Preview    string    `json:"preview"`
-	Snippet    string    `json:"snippet,omitempty"`
+	ID          string    `json:"id"`
+	ThreadID    string    `json:"thread_id"`
+	From        []Address `json:"from"`
+	To          []Address `json:"to"`
+	CC          []Address `json:"cc,omitempty"`
+	Subject     string    `json:"subject"`
+	ReceivedAt  time.Time `json:"received_at"`
+	Size        uint64    `json:"size"`
+	IsUnread    bool      `json:"is_unread"`
+	IsFlagged   bool      `json:"is_flagged"`
+	IsForwarded bool      `json:"is_forwarded"`
+	IsDraft     bool      `json:"is_draft"`
+	Preview     string    `json:"preview"`
+	Snippet     string    `json:"snippet,omitempty"`
+
+	SnoozedUntil *time.Time `json:"snoozed_until,omitempty"`
+
+	// Highlights locates the search matches in the subject and snippet,
+	// for fm search --highlight-json.
+	Highlights []HighlightField `json:"highlights,omitempty"`
+
+	// Notification describes a GitHub or GitLab notification, for
+	// --parse notifications.
+	Notification *Notification `json:"notification,omitempty"`
+
+	// Receipt is the order data of a receipt, for --parse receipts.
+	Receipt *Receipt `json:"receipt,omitempty"`
+}
+
+// Receipt is the order data a receipt or order confirmation carries as
+// schema.org JSON-LD. Total is a decimal string, as the merchant wrote it.
+type Receipt struct {
+	Merchant  string `json:"merchant,omitempty"`
+	OrderID   string `json:"order_id,omitempty"`
+	Total     string `json:"total,omitempty"`
+	Currency  string `json:"currency,omitempty"`
+	OrderDate string `json:"order_date,omitempty"`
+}
+
+// Notification is what a GitHub or GitLab notification email is about.
+type Notification struct {
+	Provider string `json:"provider"`
+	Repo     string `json:"repo"`
+	Kind     string `json:"kind,omitempty"`
+	Number   int    `json:"number,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// HighlightField locates search matches in one field of an email. Text is
+// the field as plain text, and each match spans [Start, End) in Unicode
+// code points of Text.
+type HighlightField struct {
+	Field   string       `json:"field"`
+	Text    string       `json:"text"`
+	Matches []MatchRange `json:"matches"`
+}
+
+// MatchRange is the span of one search match in a HighlightField.
+type MatchRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
 }
 
 // EmailListResult wraps a paginated email list.
@@ -46,6 +97,20 @@ type EmailListResult struct {
 	Total  uint64         `json:"total"`
 	Offset int64          `json:"offset"`
 	Emails []EmailSummary `json:"emails"`
+
+	// State and Destroyed are set by fm list --changed-since: the Email
+	// state to pass next time, and the emails destroyed since the old one.
+	State     string   `json:"state,omitempty"`
+	Destroyed []string `json:"destroyed,omitempty"`
+
+	// Highlight lists the search terms the text formatter marks in subjects
+	// and snippets. It is not part of the JSON output.
+	Highlight []string `json:"-"`
+
+	// ShowRecipients makes the text formatter lead with each email's first
+	// recipient instead of its sender, as for the Sent mailbox. It is not
+	// part of the JSON output.
+	ShowRecipients bool `json:"-"`
 }
 
 // EmailDetail is a full view of a single email.
@@ -62,11 +127,28 @@ type EmailDetail struct {
 	ReceivedAt          time.Time    `json:"received_at"`
 	IsUnread            bool         `json:"is_unread"`
 	IsFlagged           bool         `json:"is_flagged"`
+	IsDraft             bool         `json:"is_draft,omitempty"`
 	Body                string       `json:"body"`
+	BodyTruncated       bool         `json:"body_truncated,omitempty"`
 	ListUnsubscribe     string       `json:"list_unsubscribe,omitempty"`
 	ListUnsubscribePost string       `json:"list_unsubscribe_post,omitempty"`
 	Attachments         []Attachment `json:"attachments"`
+	HiddenInline        int          `json:"hidden_inline,omitempty"`
 	Headers             []Header     `json:"headers,omitempty"`
+	Signature           *Signature   `json:"signature,omitempty"`
+	Decrypted           bool         `json:"decrypted,omitempty"`
+}
+
+// Signature is the result of checking an email's S/MIME or PGP signature
+// with read --verify. Status is one of unsigned, valid, untrusted, invalid,
+// expired, revoked, unknown_key, unsupported, or unverified (the tool
+// needed to check it is not installed).
+type Signature struct {
+	Type        string `json:"type,omitempty"`
+	Status      string `json:"status"`
+	Signer      string `json:"signer,omitempty"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+	Detail      string `json:"detail,omitempty"`
 }
 
 // Header is a raw email header.
@@ -77,18 +159,40 @@ type Header struct {
 
 // ThreadEmail is a condensed view of an email within a thread.
 type ThreadEmail struct {
-	ID         string    `json:"id"`
-	From       []Address `json:"from"`
-	To         []Address `json:"to"`
-	Subject    string    `json:"subject"`
-	ReceivedAt time.Time `json:"received_at"`
-	Preview    string    `json:"preview"`
-	IsUnread   bool      `json:"is_unread"`
+	ID              string    `json:"id"`
+	From            []Address `json:"from"`
+	To              []Address `json:"to"`
+	Subject         string    `json:"subject"`
+	ReceivedAt      time.Time `json:"received_at"`
+	Preview         string    `json:"preview"`
+	IsUnread        bool      `json:"is_unread"`
+	CC              []Address `json:"cc,omitempty"`
+	AttachmentBytes uint64    `json:"attachment_bytes"`
+}
+
+// ThreadParticipant is an address seen in a thread. Sent counts the emails
+// it sent; recipients who never replied have Sent 0.
+type ThreadParticipant struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Sent  int    `json:"sent"`
+}
+
+// ThreadStats summarizes a thread. Participants are listed in order of first
+// appearance.
+type ThreadStats struct {
+	Messages        int                 `json:"messages"`
+	Unread          int                 `json:"unread"`
+	Participants    []ThreadParticipant `json:"participants"`
+	FirstAt         time.Time           `json:"first_at"`
+	LastAt          time.Time           `json:"last_at"`
+	AttachmentBytes uint64              `json:"attachment_bytes"`
 }
 
 // ThreadView wraps a full email with surrounding thread context.
 type ThreadView struct {
 	Email  EmailDetail   `json:"email"`
+	Stats  ThreadStats   `json:"stats"`
 	Thread []ThreadEmail `json:"thread"`
 }
 
@@ -99,25 +203,115 @@ type SessionInfo struct {
 	Capabilities []string               `json:"capabilities"`
 }
 
+// AuthStatus reports a successful check of the token against the session
+// endpoint, for fm auth status. Capabilities are those the session grants,
+// which reflect the token's scopes, and AccountCapabilities those of the
+// active account. The token times are known only for tokens that carry
+// them, such as OAuth access tokens in JWT form.
+type AuthStatus struct {
+	Valid               bool       `json:"valid"`
+	SessionURL          string     `json:"session_url"`
+	Username            string     `json:"username"`
+	AccountID           string     `json:"account_id"`
+	AccountName         string     `json:"account_name,omitempty"`
+	ReadOnly            bool       `json:"read_only"`
+	Capabilities        []string   `json:"capabilities"`
+	AccountCapabilities []string   `json:"account_capabilities"`
+	TokenIssuedAt       *time.Time `json:"token_issued_at,omitempty"`
+	TokenExpiresAt      *time.Time `json:"token_expires_at,omitempty"`
+	TokenAgeSeconds     *int64     `json:"token_age_seconds,omitempty"`
+}
+
+// CapabilitiesResult reports what a server advertises in its session, for
+// fm session --capabilities.
+type CapabilitiesResult struct {
+	Server       string           `json:"server"`
+	Capabilities []string         `json:"capabilities"`
+	Limits       ServerLimits     `json:"limits"`
+	Features     []FeatureSupport `json:"features"`
+	SortOptions  []string         `json:"sort_options"`
+	HeaderFilter bool             `json:"header_filter"`
+}
+
+// ServerLimits are the collection limits from the core capability. Zero
+// means the server did not say.
+type ServerLimits struct {
+	MaxSizeUpload         uint64 `json:"max_size_upload"`
+	MaxConcurrentUpload   uint64 `json:"max_concurrent_upload"`
+	MaxSizeRequest        uint64 `json:"max_size_request"`
+	MaxConcurrentRequests uint64 `json:"max_concurrent_requests"`
+	MaxCallsInRequest     uint64 `json:"max_calls_in_request"`
+	MaxObjectsInGet       uint64 `json:"max_objects_in_get"`
+	MaxObjectsInSet       uint64 `json:"max_objects_in_set"`
+}
+
+// FeatureSupport reports whether the fm commands needing a capability can
+// be used with the server.
+type FeatureSupport struct {
+	Feature    string   `json:"feature"`
+	Capability string   `json:"capability"`
+	Available  bool     `json:"available"`
+	Commands   []string `json:"commands"`
+}
+
+// AccountEntry is one account from fm accounts. PrimaryFor lists the
+// capabilities the session makes it the primary account for, and Active
+// marks the account fm is using.
+type AccountEntry struct {
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	IsPersonal   bool     `json:"is_personal"`
+	IsReadOnly   bool     `json:"is_read_only"`
+	Active       bool     `json:"active"`
+	PrimaryFor   []string `json:"primary_for"`
+	Capabilities []string `json:"capabilities"`
+}
+
 // AccountInfo is a simplified account for output.
 type AccountInfo struct {
 	Name       string `json:"name"`
 	IsPersonal bool   `json:"is_personal"`
 }
 
-// MoveResult reports the outcome of a move/archive/spam/mark-read/flag/unflag operation.
+// MoveResult reports the outcome of a move/archive/spam/mark-read/flag/unflag/mute/keyword/undo operation.
 type MoveResult struct {
-	Matched      int              `json:"matched"`
-	Processed    int              `json:"processed"`
-	Failed       int              `json:"failed"`
-	Moved        []string         `json:"moved,omitempty"`
-	Archived     []string         `json:"archived,omitempty"`
-	MarkedSpam   []string         `json:"marked_as_spam,omitempty"`
-	MarkedAsRead []string         `json:"marked_as_read,omitempty"`
-	Flagged      []string         `json:"flagged,omitempty"`
-	Unflagged    []string         `json:"unflagged,omitempty"`
-	Destination  *DestinationInfo `json:"destination,omitempty"`
-	Errors       []string         `json:"errors"`
+	Matched          int               `json:"matched"`
+	Processed        int               `json:"processed"`
+	Failed           int               `json:"failed"`
+	NoOp             int               `json:"no_op,omitempty"`
+	Moved            []string          `json:"moved,omitempty"`
+	Archived         []string          `json:"archived,omitempty"`
+	MarkedSpam       []string          `json:"marked_as_spam,omitempty"`
+	MarkedAsRead     []string          `json:"marked_as_read,omitempty"`
+	Flagged          []string          `json:"flagged,omitempty"`
+	Unflagged        []string          `json:"unflagged,omitempty"`
+	Muted            []string          `json:"muted,omitempty"`
+	Unmuted          []string          `json:"unmuted,omitempty"`
+	Keyword          string            `json:"keyword,omitempty"`
+	KeywordSet       []string          `json:"keyword_set,omitempty"`
+	KeywordClear     []string          `json:"keyword_cleared,omitempty"`
+	NewKeyword       string            `json:"new_keyword,omitempty"`
+	KeywordMigrated  []string          `json:"keyword_migrated,omitempty"`
+	Phishing         []string          `json:"reported_as_phishing,omitempty"`
+	Restored         []string          `json:"restored,omitempty"`
+	Evidence         []string          `json:"evidence,omitempty"`
+	CreatedMailboxes []string          `json:"created_mailboxes,omitempty"`
+	Destination      *DestinationInfo  `json:"destination,omitempty"`
+	AddedTo          []DestinationInfo `json:"added_to,omitempty"`
+	RemovedFrom      []DestinationInfo `json:"removed_from,omitempty"`
+	Errors           []string          `json:"errors"`
+	Results          []MessageOutcome  `json:"results,omitempty"`
+}
+
+// MessageOutcome is what a bulk action did to one email, reported with
+// --verbose. Status is "ok", the JMAP SetError type the server reported
+// (such as "notFound" or "forbidden"), "serverError" when the request
+// failed as a whole, "failed" for emails fm did not send, or "noOp" for
+// emails that were already in the end state and were skipped.
+type MessageOutcome struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
 }
 
 // DestinationInfo identifies the target mailbox of a move.
@@ -128,11 +322,85 @@ type DestinationInfo struct {
 
 // DryRunResult previews the emails that would be affected by a mutating command.
 type DryRunResult struct {
-	Operation   string           `json:"operation"`
-	Count       int              `json:"count"`
-	Emails      []EmailSummary   `json:"emails"`
-	NotFound    []string         `json:"not_found,omitempty"`
-	Destination *DestinationInfo `json:"destination,omitempty"`
+	Operation   string            `json:"operation"`
+	Count       int               `json:"count"`
+	Emails      []EmailSummary    `json:"emails"`
+	NotFound    []string          `json:"not_found,omitempty"`
+	Destination *DestinationInfo  `json:"destination,omitempty"`
+	AddTo       []DestinationInfo `json:"add_to,omitempty"`
+	RemoveFrom  []DestinationInfo `json:"remove_from,omitempty"`
+}
+
+// MailboxRenameResult reports a mailbox renamed or moved by fm mailboxes
+// rename. DryRun is set when nothing was changed.
+type MailboxRenameResult struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Path         string `json:"path"`
+	PreviousPath string `json:"previous_path"`
+	ParentID     string `json:"parent_id,omitempty"`
+	DryRun       bool   `json:"dry_run,omitempty"`
+}
+
+// MailboxCountCheck compares the counts a server reports for a mailbox with
+// the emails an Email/query finds in it.
+type MailboxCountCheck struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	ReportedTotal  uint64 `json:"reported_total"`
+	ActualTotal    uint64 `json:"actual_total"`
+	ReportedUnread uint64 `json:"reported_unread"`
+	ActualUnread   uint64 `json:"actual_unread"`
+	Match          bool   `json:"match"`
+}
+
+// MailboxVerifyResult is the output of fm mailboxes verify. Mailboxes lists
+// only those whose counts differ, unless every mailbox was asked for.
+type MailboxVerifyResult struct {
+	Checked       int                 `json:"checked"`
+	Discrepancies int                 `json:"discrepancies"`
+	Mailboxes     []MailboxCountCheck `json:"mailboxes"`
+}
+
+// MailboxSize is how many emails a mailbox holds and their total size in
+// bytes.
+type MailboxSize struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Role   string `json:"role,omitempty"`
+	Emails int    `json:"emails"`
+	Size   uint64 `json:"size"`
+}
+
+// MailboxStatsResult is the output of fm mailboxes stats. TotalSize counts
+// each email once, though an email in several mailboxes counts toward the
+// size of each.
+type MailboxStatsResult struct {
+	Scanned   int           `json:"scanned"`
+	TotalSize uint64        `json:"total_size"`
+	Mailboxes []MailboxSize `json:"mailboxes"`
+}
+
+// AttachmentMatch is an attachment line that matched fm attachments --grep.
+// Line counts from 1.
+type AttachmentMatch struct {
+	EmailID    string    `json:"email_id"`
+	Subject    string    `json:"subject"`
+	From       []Address `json:"from"`
+	Attachment string    `json:"attachment"`
+	Line       int       `json:"line"`
+	Text       string    `json:"text"`
+}
+
+// AttachmentGrepResult is the output of fm attachments --grep. Skipped
+// counts the attachments that were not searched because they are not of a
+// supported text type or are too large.
+type AttachmentGrepResult struct {
+	Pattern  string            `json:"pattern"`
+	Emails   int               `json:"emails"`
+	Searched int               `json:"searched"`
+	Skipped  int               `json:"skipped"`
+	Matches  []AttachmentMatch `json:"matches"`
 }
 
 // SenderStat is an aggregated count for a single sender address.
@@ -149,6 +417,24 @@ type StatsResult struct {
 	Senders []SenderStat `json:"senders"`
 }
 
+// SenderActivity summarizes correspondence volume and engagement for a single
+// sender address.
+type SenderActivity struct {
+	Email       string    `json:"email"`
+	Name        string    `json:"name"`
+	Count       int       `json:"count"`
+	Unread      int       `json:"unread"`
+	UnreadRatio float64   `json:"unread_ratio"`
+	LastSeen    time.Time `json:"last_seen"`
+}
+
+// SendersResult wraps a correspondent frequency report.
+type SendersResult struct {
+	Total   uint64           `json:"total"`
+	After   *time.Time       `json:"after,omitempty"`
+	Senders []SenderActivity `json:"senders"`
+}
+
 // DomainStat is an aggregated count for a single sender domain.
 type DomainStat struct {
 	Domain string `json:"domain"`
@@ -159,11 +445,105 @@ type DomainStat struct {
 type SummaryResult struct {
 	Total       uint64       `json:"total"`
 	Unread      uint64       `json:"unread"`
+	Snoozed     uint64       `json:"snoozed,omitempty"`
 	TopSenders  []SenderStat `json:"top_senders"`
 	TopDomains  []DomainStat `json:"top_domains"`
 	Newsletters []SenderStat `json:"newsletters,omitempty"`
 }
 
+// UnansweredThread is a conversation waiting on a reply, described by its
+// last message.
+type UnansweredThread struct {
+	ThreadID    string    `json:"thread_id"`
+	EmailID     string    `json:"email_id"`
+	From        []Address `json:"from"`
+	Subject     string    `json:"subject"`
+	ReceivedAt  time.Time `json:"received_at"`
+	WaitingDays int       `json:"waiting_days"`
+	Messages    int       `json:"messages"`
+}
+
+// UnansweredThreadsResult lists the conversations in a mailbox whose last
+// message has waited longer than Days for a reply. Total counts them all;
+// Threads holds up to the requested limit, longest waiting first.
+type UnansweredThreadsResult struct {
+	Mailbox string             `json:"mailbox"`
+	Days    int                `json:"days"`
+	Total   int                `json:"total"`
+	Threads []UnansweredThread `json:"threads"`
+}
+
+// Invitation is a calendar invitation received by email and the account's
+// response to it. Status is the account's participation status in lower
+// case (needs-action, accepted, declined, tentative, or delegated), or
+// "replied" when the invitation email was answered without a calendar
+// reply.
+type Invitation struct {
+	EmailID    string     `json:"email_id"`
+	UID        string     `json:"uid"`
+	Summary    string     `json:"summary"`
+	Start      time.Time  `json:"start"`
+	End        *time.Time `json:"end,omitempty"`
+	AllDay     bool       `json:"all_day"`
+	Location   string     `json:"location,omitempty"`
+	Organizer  Address    `json:"organizer"`
+	Status     string     `json:"status"`
+	ReceivedAt time.Time  `json:"received_at"`
+}
+
+// InvitesResult lists the calendar invitations found in a mailbox, soonest
+// first. With Pending, only those still needing a response are listed.
+type InvitesResult struct {
+	Mailbox     string       `json:"mailbox"`
+	Pending     bool         `json:"pending"`
+	Total       int          `json:"total"`
+	Invitations []Invitation `json:"invitations"`
+}
+
+// TravelReservation is a flight or hotel booking found in a confirmation
+// email. Kind is "flight" or "lodging". For a flight, Start and End are
+// its departure and arrival, and From and To the airports; for a hotel
+// stay, Start and End are the check-in and check-out, and Name and Address
+// the hotel's.
+type TravelReservation struct {
+	EmailID       string     `json:"email_id"`
+	Kind          string     `json:"kind"`
+	ReservationID string     `json:"reservation_id,omitempty"`
+	Provider      string     `json:"provider,omitempty"`
+	Start         time.Time  `json:"start"`
+	End           *time.Time `json:"end,omitempty"`
+	FlightNumber  string     `json:"flight_number,omitempty"`
+	From          string     `json:"from,omitempty"`
+	To            string     `json:"to,omitempty"`
+	Name          string     `json:"name,omitempty"`
+	Address       string     `json:"address,omitempty"`
+}
+
+// Trip is a run of reservations, soonest first, each starting within a day
+// of the end of the ones before it.
+type Trip struct {
+	Start        time.Time           `json:"start"`
+	End          time.Time           `json:"end"`
+	Reservations []TravelReservation `json:"reservations"`
+}
+
+// TravelResult lists the trips booked in email that have not ended by
+// After, soonest first.
+type TravelResult struct {
+	After time.Time `json:"after"`
+	Total int       `json:"total"`
+	Trips []Trip    `json:"trips"`
+}
+
+// BlobInfo describes a blob fetched from the download URL. Type is sniffed
+// from the content.
+type BlobInfo struct {
+	BlobID string `json:"blob_id"`
+	Size   int64  `json:"size"`
+	Type   string `json:"type"`
+	SHA256 string `json:"sha256"`
+}
+
 // DraftResult reports the outcome of a draft creation.
 type DraftResult struct {
 	ID        string           `json:"id"`
@@ -235,6 +615,40 @@ type SieveDryRunResult struct {
 	Valid     *bool  `json:"valid,omitempty"`
 }
 
+// SieveScriptDiff compares one server-side sieve script with its local file.
+// Status is one of same, changed, server_only, or local_only.
+type SieveScriptDiff struct {
+	Name   string `json:"name"`
+	ID     string `json:"id,omitempty"`
+	File   string `json:"file"`
+	Status string `json:"status"`
+	Diff   string `json:"diff,omitempty"`
+}
+
+// SieveDiffResult reports drift between server scripts and a local directory.
+type SieveDiffResult struct {
+	Local   string            `json:"local"`
+	Changed int               `json:"changed"`
+	Scripts []SieveScriptDiff `json:"scripts"`
+}
+
+// SieveExportEntry records one exported sieve script.
+type SieveExportEntry struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	File     string `json:"file"`
+	IsActive bool   `json:"is_active"`
+}
+
+// SieveExportResult reports a sieve export. The same structure is written to
+// manifest.json in the output directory.
+type SieveExportResult struct {
+	Output     string             `json:"output"`
+	ExportedAt time.Time          `json:"exported_at"`
+	Active     string             `json:"active,omitempty"`
+	Scripts    []SieveExportEntry `json:"scripts"`
+}
+
 // UnsubscribeResult reports the outcome of an unsubscribe inspection or draft creation.
 type UnsubscribeResult struct {
 	EmailID   string `json:"email_id"`
@@ -247,9 +661,480 @@ type UnsubscribeResult struct {
 	DraftID   string `json:"draft_id,omitempty"`
 }
 
+// AuthVerdict reports the SPF, DKIM, and DMARC outcome for one email.
+type AuthVerdict struct {
+	EmailID     string    `json:"email_id"`
+	From        []Address `json:"from"`
+	Subject     string    `json:"subject"`
+	ReceivedAt  time.Time `json:"received_at"`
+	AuthServID  string    `json:"authserv_id,omitempty"`
+	SPF         string    `json:"spf"`
+	DKIM        string    `json:"dkim"`
+	DMARC       string    `json:"dmarc"`
+	DKIMDomains []string  `json:"dkim_domains,omitempty"`
+	Failures    []string  `json:"failures,omitempty"`
+}
+
+// AuthCheckResult wraps authentication verdicts for a set of emails.
+type AuthCheckResult struct {
+	Checked  int           `json:"checked"`
+	Failed   int           `json:"failed"`
+	Results  []AuthVerdict `json:"results"`
+	NotFound []string      `json:"not_found,omitempty"`
+}
+
+// MaskedEmailInfo is a view of a Fastmail masked email address.
+type MaskedEmailInfo struct {
+	ID            string     `json:"id"`
+	Email         string     `json:"email"`
+	State         string     `json:"state"`
+	ForDomain     string     `json:"for_domain"`
+	Description   string     `json:"description,omitempty"`
+	LastMessageAt *time.Time `json:"last_message_at,omitempty"`
+	CreatedAt     *time.Time `json:"created_at,omitempty"`
+}
+
+// MaskedEmailListResult wraps the masked emails matching a domain.
+type MaskedEmailListResult struct {
+	Domain       string            `json:"domain"`
+	Total        int               `json:"total"`
+	MaskedEmails []MaskedEmailInfo `json:"masked_emails"`
+}
+
+// MaskedEmailRotateResult reports a masked email replaced by a new address.
+type MaskedEmailRotateResult struct {
+	Disabled    MaskedEmailInfo `json:"disabled"`
+	Replacement MaskedEmailInfo `json:"replacement"`
+}
+
+// MaskedEmailDryRunResult previews a masked email mutation without executing it.
+type MaskedEmailDryRunResult struct {
+	Operation   string          `json:"operation"`
+	MaskedEmail MaskedEmailInfo `json:"masked_email"`
+}
+
+// EmailTags carries the state of one email needed to tag it in a local mail
+// indexer. Mailboxes holds a mailbox's role when it has one, else its name.
+type EmailTags struct {
+	ID        string   `json:"id"`
+	MessageID string   `json:"message_id"`
+	Keywords  []string `json:"keywords"`
+	Mailboxes []string `json:"mailboxes"`
+}
+
+// ExportResult reports an mbox export. Incremental is true when only emails
+// created since the previous export were appended.
+type ExportResult struct {
+	Output      string `json:"output"`
+	Exported    int    `json:"exported"`
+	Incremental bool   `json:"incremental"`
+	State       string `json:"state"`
+}
+
+// SQLiteExportResult reports a SQLite export. Bodies is true when message
+// bodies were included.
+type SQLiteExportResult struct {
+	Output   string `json:"output"`
+	Exported int    `json:"exported"`
+	Bodies   bool   `json:"bodies"`
+}
+
+// ContactsExtractResult reports a contacts extract. Emails counts the
+// emails searched and Attachments the vCard attachments read; Contacts is
+// the number of cards written to Output, after Duplicates were left out.
+type ContactsExtractResult struct {
+	Output      string `json:"output"`
+	Emails      int    `json:"emails"`
+	Attachments int    `json:"attachments"`
+	Contacts    int    `json:"contacts"`
+	Duplicates  int    `json:"duplicates"`
+}
+
+// ExecRun reports one command run by fm exec. Error is set when the command
+// could not be started or did not exit normally.
+type ExecRun struct {
+	EmailID  string `json:"email_id"`
+	ExitCode int    `json:"exit_code"`
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ExecResult reports the outcome of running a command per matched email.
+type ExecResult struct {
+	Command   []string  `json:"command"`
+	Matched   int       `json:"matched"`
+	Succeeded int       `json:"succeeded"`
+	Failed    int       `json:"failed"`
+	Runs      []ExecRun `json:"runs"`
+	NotFound  []string  `json:"not_found,omitempty"`
+}
+
+// WatchEvent reports a new email seen by fm watch. The same structure is the
+// webhook payload.
+type WatchEvent struct {
+	Event      string       `json:"event"`
+	DetectedAt time.Time    `json:"detected_at"`
+	Email      EmailSummary `json:"email"`
+}
+
+// ConfigPathsResult reports where fm reads config and keeps its caches.
+type ConfigPathsResult struct {
+	ConfigFile   string `json:"config_file"`
+	ConfigExists bool   `json:"config_exists"`
+	ConfigDir    string `json:"config_dir"`
+	CacheDir     string `json:"cache_dir"`
+	ResultCache  string `json:"result_cache"`
+	ExportState  string `json:"export_state"`
+}
+
+// EnvVar describes an environment variable fm recognizes. Value is omitted
+// for secrets.
+type EnvVar struct {
+	Name        string `json:"name"`
+	ConfigKey   string `json:"config_key,omitempty"`
+	Description string `json:"description"`
+	Set         bool   `json:"set"`
+	Value       string `json:"value,omitempty"`
+}
+
+// ConfigEnvResult lists the environment variables fm recognizes.
+type ConfigEnvResult struct {
+	Variables []EnvVar `json:"variables"`
+}
+
+// ConfigSetting reports an effective setting and where it came from: flag,
+// env, config, or default. Secret values are reported as "(hidden)".
+type ConfigSetting struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// ConfigProblem reports an unknown key or invalid value in the config file.
+type ConfigProblem struct {
+	Key        string `json:"key"`
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// ConfigCheckResult reports the outcome of fm config check.
+type ConfigCheckResult struct {
+	ConfigFile string          `json:"config_file"`
+	Valid      bool            `json:"valid"`
+	Settings   []ConfigSetting `json:"settings"`
+	Problems   []ConfigProblem `json:"problems"`
+}
+
+// MockServerInfo reports where fm mock-server is listening.
+type MockServerInfo struct {
+	SessionURL string `json:"session_url"`
+	AccountID  string `json:"account_id"`
+	Mailboxes  int    `json:"mailboxes"`
+	Emails     int    `json:"emails"`
+}
+
+// DaemonStatus reports a running fm daemon: where it listens, the account
+// it serves, how many requests it has proxied or answered from its cache,
+// and how the server has responded to the requests it sent on.
+type DaemonStatus struct {
+	Socket          string       `json:"socket"`
+	PID             int          `json:"pid"`
+	Username        string       `json:"username"`
+	Server          string       `json:"server"`
+	StartedAt       time.Time    `json:"started_at"`
+	CacheTTLSeconds float64      `json:"cache_ttl_seconds"`
+	Requests        int          `json:"requests"`
+	CacheHits       int          `json:"cache_hits"`
+	Upstream        RequestStats `json:"upstream"`
+}
+
+// RequestStats counts the HTTP requests a client has made to the server,
+// retries included, and how many were rate limited (429), failed with a
+// server error (5xx), or were retried.
+type RequestStats struct {
+	Requests     int `json:"requests"`
+	Retries      int `json:"retries"`
+	RateLimited  int `json:"rate_limited"`
+	ServerErrors int `json:"server_errors"`
+}
+
+// ProtocolResponse answers one fm serve request, with the request's ID
+// and either Result, shaped like the JSON output of the matching command,
+// or Error.
+type ProtocolResponse struct {
+	ID     any            `json:"id"`
+	Result any            `json:"result,omitempty"`
+	Error  *ProtocolError `json:"error,omitempty"`
+}
+
+// ProtocolError is a failed fm serve request, with the same codes the
+// commands exit with.
+type ProtocolError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Hint    string `json:"hint,omitempty"`
+}
+
+func (e *ProtocolError) Error() string { return e.Message }
+
+// KeywordCount is the number of emails carrying a keyword.
+type KeywordCount struct {
+	Keyword string `json:"keyword"`
+	Count   int    `json:"count"`
+}
+
+// KeywordListResult reports the keywords in use in a mailbox or account.
+type KeywordListResult struct {
+	Mailbox  string         `json:"mailbox,omitempty"`
+	Scanned  int            `json:"scanned"`
+	Keywords []KeywordCount `json:"keywords"`
+}
+
+// KeywordStats counts the emails in a mailbox, or in the whole account when
+// Mailbox is empty, by state keyword. FlaggedUnanswered counts the flagged
+// emails not marked $answered, the flagged-but-never-handled backlog.
+// Keywords counts the custom keywords, those other than the system ones
+// such as $seen and $flagged, most common first.
+type KeywordStats struct {
+	Mailbox           string         `json:"mailbox,omitempty"`
+	Total             int            `json:"total"`
+	Flagged           int            `json:"flagged"`
+	Answered          int            `json:"answered"`
+	Forwarded         int            `json:"forwarded"`
+	FlaggedUnanswered int            `json:"flagged_unanswered"`
+	Keywords          []KeywordCount `json:"keywords"`
+}
+
+// KeywordSummaryResult is the output of fm summary --keywords: the
+// counts for the whole account, or the mailbox given, and for each
+// mailbox holding emails, by name.
+type KeywordSummaryResult struct {
+	Scanned   int            `json:"scanned"`
+	Account   KeywordStats   `json:"account"`
+	Mailboxes []KeywordStats `json:"mailboxes"`
+}
+
+// GroupCount is the number of emails in one group of a grouped count.
+type GroupCount struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+// SearchCountResult is the output of fm search --count-only: the number of
+// matching emails and, with --group-by, how many fall in each group.
+type SearchCountResult struct {
+	Total   uint64       `json:"total"`
+	GroupBy string       `json:"group_by,omitempty"`
+	Groups  []GroupCount `json:"groups,omitempty"`
+}
+
+// SizedEmail is an email in the largest-messages report.
+type SizedEmail struct {
+	ID          string    `json:"id"`
+	ThreadID    string    `json:"thread_id"`
+	From        []Address `json:"from"`
+	Subject     string    `json:"subject"`
+	ReceivedAt  time.Time `json:"received_at"`
+	Size        uint64    `json:"size"`
+	Attachments int       `json:"attachments"`
+}
+
+// SizeResult lists the largest emails, biggest first. Total counts the
+// emails considered; ListedSize sums the sizes of those listed.
+type SizeResult struct {
+	Mailbox    string       `json:"mailbox,omitempty"`
+	Total      uint64       `json:"total"`
+	ListedSize uint64       `json:"listed_size"`
+	Emails     []SizedEmail `json:"emails"`
+}
+
+// AgingBucket counts the emails received within one age range. Since and
+// Before bound the range (receivedAt >= Since, receivedAt < Before); the
+// newest bucket has no Before and the oldest no Since.
+type AgingBucket struct {
+	Name   string     `json:"name"`
+	Since  *time.Time `json:"since,omitempty"`
+	Before *time.Time `json:"before,omitempty"`
+	Total  uint64     `json:"total"`
+	Unread uint64     `json:"unread"`
+}
+
+// AgingResult reports how old the emails in a mailbox are, newest bucket
+// first.
+type AgingResult struct {
+	Mailbox string        `json:"mailbox"`
+	Total   uint64        `json:"total"`
+	Unread  uint64        `json:"unread"`
+	Buckets []AgingBucket `json:"buckets"`
+}
+
+// InitResult reports the setup done by fm init. CredentialCommand is empty
+// when the token was stored in the OS keychain.
+type InitResult struct {
+	ConfigFile        string `json:"config_file"`
+	Username          string `json:"username"`
+	AccountID         string `json:"account_id"`
+	Keychain          bool   `json:"keychain"`
+	CredentialCommand string `json:"credential_command,omitempty"`
+	InboxTotal        uint64 `json:"inbox_total"`
+}
+
+// Participant is an address seen in the From, To, or Cc fields of a set of
+// emails. Count is the number of emails it appears in; From, To, and CC
+// count the appearances in each field.
+type Participant struct {
+	Email string `json:"email"`
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+	From  int    `json:"from"`
+	To    int    `json:"to"`
+	CC    int    `json:"cc"`
+}
+
+// ParticipantsResult lists the participants of a set of emails.
+type ParticipantsResult struct {
+	Emails       int           `json:"emails"`
+	Participants []Participant `json:"participants"`
+}
+
+// StateResult holds the JMAP state strings printed by fm state. Each
+// changes whenever the objects of its type change on the server.
+type StateResult struct {
+	Session string `json:"session"`
+	Email   string `json:"email"`
+	Mailbox string `json:"mailbox"`
+	Thread  string `json:"thread"`
+}
+
+// AddressCount is a distinct address and the number of times it appears
+// in the fields fm addresses was asked for.
+type AddressCount struct {
+	Email string `json:"email"`
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// AddressesResult lists the distinct addresses of a set of emails.
+type AddressesResult struct {
+	Field     string         `json:"field"`
+	Emails    int            `json:"emails"`
+	Addresses []AddressCount `json:"addresses"`
+
+	// Names and Counts make the text formatter add each address's display
+	// name and count. They are not part of the JSON output.
+	Names  bool `json:"-"`
+	Counts bool `json:"-"`
+}
+
+// DownloadedFile is an attachment or inline image saved by fm download.
+type DownloadedFile struct {
+	File      string `json:"file"`
+	Name      string `json:"name,omitempty"`
+	Type      string `json:"type"`
+	Size      uint64 `json:"size"`
+	BlobID    string `json:"blob_id"`
+	ContentID string `json:"content_id,omitempty"`
+	Inline    bool   `json:"inline"`
+	// TextFile is the file holding the text an extractor produced from
+	// this one, with download --extract.
+	TextFile string `json:"text_file,omitempty"`
+}
+
+// DownloadResult reports the files saved by fm download.
+type DownloadResult struct {
+	ID    string           `json:"id"`
+	Dir   string           `json:"dir"`
+	Files []DownloadedFile `json:"files"`
+}
+
+// HTMLBodyResult reports an HTML body written to a file by fm read --output.
+type HTMLBodyResult struct {
+	ID            string `json:"id"`
+	Output        string `json:"output"`
+	Bytes         int    `json:"bytes"`
+	InlineImages  bool   `json:"inline_images"`
+	RemoteBlocked bool   `json:"remote_blocked"`
+}
+
+// SavedImage is a remote image downloaded by fm read --save-images.
+type SavedImage struct {
+	URL   string `json:"url"`
+	File  string `json:"file"`
+	Bytes int64  `json:"bytes"`
+}
+
+// ImageFailure is a remote image that could not be downloaded.
+type ImageFailure struct {
+	URL   string `json:"url"`
+	Error string `json:"error"`
+}
+
+// SavedImagesResult reports the remote images of an email's HTML body
+// downloaded by fm read --save-images. Blocked lists the images on domains
+// outside the remote_image_domains allowlist, which were not fetched.
+type SavedImagesResult struct {
+	ID      string         `json:"id"`
+	Dir     string         `json:"dir"`
+	Saved   []SavedImage   `json:"saved"`
+	Blocked []string       `json:"blocked"`
+	Failed  []ImageFailure `json:"failed"`
+}
+
+// DecryptedMessageResult reports a decrypted message written to a file by
+// fm read --decrypt --output.
+type DecryptedMessageResult struct {
+	ID     string `json:"id"`
+	Output string `json:"output"`
+	Bytes  int    `json:"bytes"`
+}
+
+// CleanSuggestion is a mailing-list sender whose recent messages have all
+// gone unread, with the fm commands that would clean it up.
+type CleanSuggestion struct {
+	Email         string    `json:"email"`
+	Name          string    `json:"name"`
+	Count         int       `json:"count"`
+	LastReceived  time.Time `json:"last_received"`
+	LatestID      string    `json:"latest_id"`
+	Unsubscribe   string    `json:"unsubscribe"`
+	Archive       string    `json:"archive"`
+	ArchiveFilter string    `json:"archive_filter"`
+}
+
+// CleanSuggestResult wraps the senders reported by fm clean suggest.
+type CleanSuggestResult struct {
+	Mailbox     string            `json:"mailbox"`
+	Since       time.Time         `json:"since"`
+	Scanned     uint64            `json:"scanned"`
+	Suggestions []CleanSuggestion `json:"suggestions"`
+}
+
 // AppError is a structured error for JSON output.
 type AppError struct {
 	Error   string `json:"error"`
 	Message string `json:"message"`
 	Hint    string `json:"hint,omitempty"`
 }
+
+// Envelope is the single JSON document written to stdout with
+// --errors-to stdout. Error is null when OK is true; Result is null when
+// the command failed before producing one.
+type Envelope struct {
+	OK       bool       `json:"ok"`
+	Result   any        `json:"result"`
+	Error    *AppError  `json:"error"`
+	Warnings []AppError `json:"warnings,omitempty"`
+}
+
+// PlanVerifyResult is the output of fm verify: the emails of a dry-run plan
+// split by whether the server reflects the planned change. Missing lists
+// emails that are no longer found.
+type PlanVerifyResult struct {
+	Operation   string           `json:"operation"`
+	Destination *DestinationInfo `json:"destination,omitempty"`
+	Checked     int              `json:"checked"`
+	Applied     []string         `json:"applied"`
+	NotApplied  []string         `json:"not_applied"`
+	Missing     []string         `json:"missing,omitempty"`
+}
@@ -0,0 +1,106 @@
+// Package vcard splits vCard (RFC 6350) data into its cards and reads the
+// name and email addresses of each, leaving the cards otherwise as sent.
+package vcard
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// Card is one BEGIN:VCARD ... END:VCARD block.
+type Card struct {
+	// Raw is the card's content lines as sent, folding included, each
+	// ended with CRLF.
+	Raw string
+	// Name is the formatted name (FN), unescaped.
+	Name string
+	// Emails are the card's EMAIL values in the order given.
+	Emails []string
+}
+
+// Split reads the cards in r. Lines outside a card are skipped, as is a
+// card left unterminated at the end of r.
+func Split(r io.Reader) ([]Card, error) {
+	var cards []Card
+	var raw strings.Builder
+	var lines []string // unfolded lines of the current card
+	inCard := false
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		folded := strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")
+
+		if !inCard {
+			if !folded && strings.EqualFold(strings.TrimSpace(line), "BEGIN:VCARD") {
+				inCard = true
+				raw.Reset()
+				lines = lines[:0]
+				raw.WriteString(line + "\r\n")
+			}
+			continue
+		}
+
+		raw.WriteString(line + "\r\n")
+		if folded && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if strings.EqualFold(strings.TrimSpace(line), "END:VCARD") {
+			cards = append(cards, card(raw.String(), lines))
+			inCard = false
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return cards, scanner.Err()
+}
+
+// card builds a Card from its raw text and unfolded content lines.
+func card(raw string, lines []string) Card {
+	c := Card{Raw: raw}
+	for _, line := range lines {
+		head, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		name, _, _ := strings.Cut(head, ";")
+		// Apple groups related properties with a prefix such as item1.
+		if i := strings.LastIndex(name, "."); i >= 0 {
+			name = name[i+1:]
+		}
+		switch strings.ToUpper(name) {
+		case "FN":
+			c.Name = unescape(value)
+		case "EMAIL":
+			if value = strings.TrimSpace(value); value != "" {
+				c.Emails = append(c.Emails, value)
+			}
+		}
+	}
+	return c
+}
+
+// unescape undoes the backslash escapes of a text value.
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,48 @@
+package vcard
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	data := "BEGIN:VCARD\n" +
+		"VERSION:3.0\n" +
+		"FN:Alice Smith\\, PhD\n" +
+		"item1.EMAIL;TYPE=INTERNET:alice@exam\n" +
+		" ple.com\n" +
+		"EMAIL:alice@work.example\n" +
+		"END:VCARD\n" +
+		"stray line\n" +
+		"BEGIN:VCARD\r\n" +
+		"VERSION:4.0\r\n" +
+		"FN:Bob\r\n" +
+		"TEL:+1 555 0100\r\n" +
+		"END:VCARD\r\n" +
+		"BEGIN:VCARD\n" +
+		"FN:Unterminated\n"
+
+	cards, err := Split(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cards) != 2 {
+		t.Fatalf("expected two cards, got %+v", cards)
+	}
+
+	alice := cards[0]
+	if alice.Name != "Alice Smith, PhD" {
+		t.Errorf("expected the name unescaped, got %q", alice.Name)
+	}
+	if len(alice.Emails) != 2 || alice.Emails[0] != "alice@example.com" || alice.Emails[1] != "alice@work.example" {
+		t.Errorf("expected both addresses, the folded one joined, got %v", alice.Emails)
+	}
+	if !strings.HasPrefix(alice.Raw, "BEGIN:VCARD\r\n") || !strings.HasSuffix(alice.Raw, "END:VCARD\r\n") ||
+		!strings.Contains(alice.Raw, "alice@exam\r\n ple.com\r\n") {
+		t.Errorf("expected the raw card as sent with CRLF line ends, got %q", alice.Raw)
+	}
+
+	if bob := cards[1]; bob.Name != "Bob" || len(bob.Emails) != 0 {
+		t.Errorf("expected Bob without addresses, got %+v", bob)
+	}
+}
@@ -0,0 +1,68 @@
+package webhook
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPost_SignsBody(t *testing.T) {
+	var gotBody []byte
+	var gotSig, gotType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+		gotSig = r.Header.Get(SignatureHeader)
+		gotType = r.Header.Get("Content-Type")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	if err := Post(server.URL, "s3cret", map[string]string{"event": "new_email"}); err != nil {
+		t.Fatalf("Post: %v", err)
+	}
+	if string(gotBody) != `{"event":"new_email"}` {
+		t.Errorf("unexpected body %s", gotBody)
+	}
+	if gotType != "application/json" {
+		t.Errorf("expected JSON content type, got %q", gotType)
+	}
+	if want := Sign([]byte("s3cret"), gotBody); gotSig != want {
+		t.Errorf("expected signature %s, got %s", want, gotSig)
+	}
+}
+
+func TestPost_NoSecretNoSignature(t *testing.T) {
+	var hasSig bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, hasSig = r.Header[SignatureHeader]
+	}))
+	defer server.Close()
+
+	if err := Post(server.URL, "", struct{}{}); err != nil {
+		t.Fatalf("Post: %v", err)
+	}
+	if hasSig {
+		t.Error("expected no signature header without a secret")
+	}
+}
+
+func TestPost_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	if err := Post(server.URL, "", struct{}{}); err == nil {
+		t.Fatal("expected error for 500 response")
+	}
+}
+
+func TestSign_KnownVector(t *testing.T) {
+	// RFC 4231 test case 2.
+	got := Sign([]byte("Jefe"), []byte("what do ya want for nothing?"))
+	want := "sha256=5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"
+	if got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
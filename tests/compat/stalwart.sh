@@ -0,0 +1,80 @@
+#!/usr/bin/env bash
+# Run tests/stalwart.md against a throwaway Stalwart container.
+#
+# Starts the container, creates a test account, seeds two emails (one with
+# a List-Unsubscribe header), obtains an OAuth access token for fm, runs the
+# scrut tests, and removes the container. Requires docker, curl, python3,
+# and scrut.
+#
+#   STALWART_IMAGE  image to run (default stalwartlabs/stalwart:latest)
+#   STALWART_PORT   host port for HTTP (default 18080)
+#   FM_STALWART_TOKEN  use this access token instead of requesting one
+set -euo pipefail
+
+image="${STALWART_IMAGE:-stalwartlabs/stalwart:latest}"
+port="${STALWART_PORT:-18080}"
+name="fm-stalwart-$$"
+base="http://localhost:${port}"
+user="fm"
+pass="fm-compat-test"
+here="$(cd "$(dirname "$0")" && pwd)"
+
+cleanup() { docker rm -f "$name" >/dev/null 2>&1 || true; }
+trap cleanup EXIT
+
+docker run -d --name "$name" -p "${port}:8080" "$image" >/dev/null
+
+# The session endpoint answers 401 once the server is up.
+for _ in $(seq 1 60); do
+	code=$(curl -s -o /dev/null -w '%{http_code}' "${base}/.well-known/jmap" || true)
+	test "$code" = "401" && break
+	sleep 1
+done
+
+# Stalwart logs the generated administrator password on first start.
+admin_pass=$(docker logs "$name" 2>&1 | sed -n "s/.*with password '\([^']*\)'.*/\1/p" | head -n 1)
+test -n "$admin_pass" || { echo "could not find the Stalwart admin password in the logs" >&2; exit 1; }
+
+api() {
+	curl -fsS -u "admin:${admin_pass}" -H 'Content-Type: application/json' "$@"
+}
+api -X POST "${base}/api/principal" -d '{"type":"domain","name":"example.org"}' >/dev/null
+api -X POST "${base}/api/principal" -d "{\"type\":\"individual\",\"name\":\"${user}\",\"secrets\":[\"${pass}\"],\"emails\":[\"${user}@example.org\"],\"roles\":[\"user\"]}" >/dev/null
+
+jmap() {
+	curl -fsS -u "${user}:${pass}" -H 'Content-Type: application/json' "$@"
+}
+session=$(jmap "${base}/.well-known/jmap")
+account=$(python3 -c 'import json,sys; print(json.load(sys.stdin)["primaryAccounts"]["urn:ietf:params:jmap:mail"])' <<<"$session")
+api_url=$(python3 -c 'import json,sys; print(json.load(sys.stdin)["apiUrl"])' <<<"$session")
+upload_url=$(python3 -c 'import json,sys; print(json.load(sys.stdin)["uploadUrl"].replace("{accountId}", sys.argv[1]))' "$account" <<<"$session")
+
+inbox=$(jmap -X POST "$api_url" -d "{\"using\":[\"urn:ietf:params:jmap:core\",\"urn:ietf:params:jmap:mail\"],\"methodCalls\":[[\"Mailbox/query\",{\"accountId\":\"${account}\",\"filter\":{\"role\":\"inbox\"}},\"0\"]]}" |
+	python3 -c 'import json,sys; print(json.load(sys.stdin)["methodResponses"][0][1]["ids"][0])')
+
+for eml in "$here"/stalwart/*.eml; do
+	blob=$(jmap -X POST -H 'Content-Type: message/rfc822' --data-binary "@${eml}" "$upload_url" |
+		python3 -c 'import json,sys; print(json.load(sys.stdin)["blobId"])')
+	jmap -X POST "$api_url" -d "{\"using\":[\"urn:ietf:params:jmap:core\",\"urn:ietf:params:jmap:mail\"],\"methodCalls\":[[\"Email/import\",{\"accountId\":\"${account}\",\"emails\":{\"e\":{\"blobId\":\"${blob}\",\"mailboxIds\":{\"${inbox}\":true},\"keywords\":{}}}},\"0\"]]}" >/dev/null
+done
+
+# fm authenticates with a bearer token. Get one through the same OAuth
+# code flow the Stalwart web admin uses.
+token="${FM_STALWART_TOKEN:-}"
+if [ -z "$token" ]; then
+	code=$(jmap -X POST "${base}/api/oauth" -d '{"type":"code","client_id":"fm-compat","redirect_uri":"stalwart://auth"}' |
+		python3 -c 'import json,sys; print(json.load(sys.stdin)["data"]["code"])')
+	token=$(curl -fsS -X POST "${base}/auth/token" \
+		--data-urlencode grant_type=authorization_code \
+		--data-urlencode "code=${code}" \
+		--data-urlencode client_id=fm-compat \
+		--data-urlencode redirect_uri=stalwart://auth |
+		python3 -c 'import json,sys; print(json.load(sys.stdin)["access_token"])')
+fi
+
+export FM_STALWART_TESTS=1
+export FM_SESSION_URL="${base}/.well-known/jmap"
+export FM_CREDENTIAL_COMMAND="printf %s ${token}"
+HOME="$(mktemp -d)"
+export HOME
+scrut test "$here/../stalwart.md"